@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"log"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// renewScript : 仅当锁仍由owner持有时才延长有效期
+var renewScript = redis.NewScript(1, `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("EXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// unlockScript : 仅当锁仍由owner持有时才删除
+var unlockScript = redis.NewScript(1, `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// TryLock : 尝试获取分布式锁, ttl单位为秒
+func TryLock(key, owner string, ttl int) bool {
+	conn := pool.Get()
+	defer conn.Close()
+
+	_, err := redis.String(conn.Do("SET", key, owner, "NX", "EX", ttl))
+	if err != nil && err != redis.ErrNil {
+		log.Println(err.Error())
+	}
+	return err == nil
+}
+
+// RenewLock : 延长owner持有的锁的有效期, 锁已不属于owner时返回false
+func RenewLock(key, owner string, ttl int) bool {
+	conn := pool.Get()
+	defer conn.Close()
+
+	n, err := redis.Int(renewScript.Do(conn, key, owner, ttl))
+	if err != nil {
+		log.Println(err.Error())
+		return false
+	}
+	return n == 1
+}
+
+// Unlock : 释放owner持有的锁
+func Unlock(key, owner string) bool {
+	conn := pool.Get()
+	defer conn.Close()
+
+	n, err := redis.Int(unlockScript.Do(conn, key, owner))
+	if err != nil {
+		log.Println(err.Error())
+		return false
+	}
+	return n == 1
+}
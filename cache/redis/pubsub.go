@@ -0,0 +1,75 @@
+package redis
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// pubsubPingInterval : 订阅连接的心跳间隔, 用于及时发现断开的连接
+const pubsubPingInterval = time.Minute
+
+// Publish : 向频道发布消息
+func Publish(channel string, message []byte) bool {
+	conn := pool.Get()
+	defer conn.Close()
+
+	if _, err := conn.Do("PUBLISH", channel, message); err != nil {
+		log.Println(err.Error())
+		return false
+	}
+	return true
+}
+
+// Subscribe : 订阅频道并对收到的每条消息调用handler, 直到ctx结束(返回nil)或连接出错.
+// onSubscribe不为空时在订阅生效后调用
+func Subscribe(ctx context.Context, channel string, onSubscribe func(), handler func([]byte)) error {
+	psc := redis.PubSubConn{Conn: pool.Get()}
+	defer psc.Close()
+
+	if err := psc.Subscribe(channel); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		for {
+			switch v := psc.Receive().(type) {
+			case redis.Message:
+				handler(v.Data)
+			case redis.Subscription:
+				if v.Kind == "subscribe" && onSubscribe != nil {
+					onSubscribe()
+				}
+				if v.Count == 0 {
+					done <- nil
+					return
+				}
+			case error:
+				done <- v
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(pubsubPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			if err := psc.Unsubscribe(); err != nil {
+				return nil
+			}
+			<-done
+			return nil
+		case <-ticker.C:
+			if err := psc.Ping(""); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cloud/sdk"
+)
+
+var errTokenExpired = errors.New("token expired, run `cloudctl login` again")
+
+// newClient : 以保存的凭证创建客户端. 本地不保存密码, token过期后须重新登录
+func newClient(cred *Credentials) *sdk.Client {
+	return sdk.NewClient(cred.Server, sdk.WithSession(sdk.Session{
+		Username:      cred.Username,
+		Token:         cred.Token,
+		UploadEntry:   cred.UploadEntry,
+		DownloadEntry: cred.DownloadEntry,
+	}))
+}
+
+// signin : 登录并返回新的凭证
+func signin(ctx context.Context, server, username, password string) (*Credentials, error) {
+	sess, err := sdk.NewClient(server).SignIn(ctx, username, password)
+	if err != nil {
+		return nil, err
+	}
+	return &Credentials{
+		Server:        server,
+		Username:      sess.Username,
+		Token:         sess.Token,
+		UploadEntry:   sess.UploadEntry,
+		DownloadEntry: sess.DownloadEntry,
+		LoginAt:       time.Now().Format("2006-01-02 15:04:05"),
+	}, nil
+}
+
+// userError : 将sdk的错误转换为给用户看的提示
+func userError(err error) error {
+	if err == sdk.ErrTokenExpired {
+		return errTokenExpired
+	}
+	return err
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/cloud/sdk"
+)
+
+func newLoginCmd() *cobra.Command {
+	var server, username, password string
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Sign in and store the token locally",
+		Args:  cobra.NoArgs,
+		RunE: runE(func(ctx context.Context, args []string) error {
+			if server == "" {
+				server = "http://127.0.0.1:8080"
+				if cred, err := loadCredentials(opts.configPath); err == nil {
+					server = cred.Server
+				}
+			}
+			if username == "" {
+				return errors.New("--username is required")
+			}
+			if password == "" {
+				password = os.Getenv("CLOUDCTL_PASSWORD")
+			}
+			if password == "" {
+				var err error
+				if password, err = readPassword(); err != nil {
+					return err
+				}
+			}
+
+			cred, err := signin(ctx, server, username, password)
+			if err != nil {
+				return err
+			}
+			if err := saveCredentials(opts.configPath, cred); err != nil {
+				return err
+			}
+			if opts.jsonOutput {
+				printJSON(map[string]string{
+					"Server":   cred.Server,
+					"Username": cred.Username,
+					"LoginAt":  cred.LoginAt,
+				})
+				return nil
+			}
+			fmt.Printf("Logged in to %s as %s\n", cred.Server, cred.Username)
+			return nil
+		}),
+	}
+	cmd.Flags().StringVar(&server, "server", "", "API gateway address (default: last used or http://127.0.0.1:8080)")
+	cmd.Flags().StringVarP(&username, "username", "u", "", "user name")
+	cmd.Flags().StringVarP(&password, "password", "p", "", "password (default: $CLOUDCTL_PASSWORD or prompt)")
+	return cmd
+}
+
+// readPassword : 终端中不回显输入, 否则从stdin读取一行
+func readPassword() (string, error) {
+	return readSecret("Password: ")
+}
+
+// readSecret : 以prompt提示读取不回显的输入
+func readSecret(prompt string) (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprint(os.Stderr, prompt)
+		data, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return string(data), err
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func newLogoutCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout",
+		Short: "Remove the stored token",
+		Args:  cobra.NoArgs,
+		RunE: runE(func(ctx context.Context, args []string) error {
+			if err := os.Remove(opts.configPath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			if opts.jsonOutput {
+				printJSON(map[string]bool{"LoggedOut": true})
+			}
+			return nil
+		}),
+	}
+}
+
+// lsEntry : ls输出的一项, 目录的Name以"/"结尾
+type lsEntry struct {
+	Name     string
+	Size     int64
+	FileHash string `json:",omitempty"`
+	ModTime  string
+	IsDir    bool
+}
+
+func newLsCmd() *cobra.Command {
+	var recursive bool
+	cmd := &cobra.Command{
+		Use:   "ls [path]",
+		Short: "List files",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: runE(func(ctx context.Context, args []string) error {
+			c, err := loggedInClient()
+			if err != nil {
+				return err
+			}
+			prefix := ""
+			if len(args) > 0 {
+				prefix = strings.TrimPrefix(args[0], "/")
+			}
+
+			// 参数为文件时只列出该文件, 否则作为目录
+			entries := []lsEntry{}
+			if prefix != "" && !strings.HasSuffix(prefix, "/") {
+				if f, err := c.Stat(ctx, prefix); err == nil {
+					entries = append(entries, fileEntry(*f, f.FileName))
+					return printEntries(entries)
+				}
+				prefix += "/"
+			}
+			if recursive {
+				err = c.WalkFiles(ctx, prefix, func(f sdk.FileMeta) error {
+					if strings.HasPrefix(f.FileName, prefix) && !strings.HasSuffix(f.FileName, "/") {
+						entries = append(entries, fileEntry(f, f.FileName))
+					}
+					return nil
+				})
+			} else {
+				entries, err = listDir(ctx, c, prefix)
+			}
+			if err != nil {
+				return err
+			}
+			return printEntries(entries)
+		}),
+	}
+	cmd.Flags().BoolVarP(&recursive, "recursive", "R", false, "list all files under the path")
+	return cmd
+}
+
+func fileEntry(f sdk.FileMeta, name string) lsEntry {
+	return lsEntry{
+		Name:     name,
+		Size:     f.FileSize,
+		FileHash: f.FileHash,
+		ModTime:  f.LastUpdated,
+	}
+}
+
+// listDir : 列出目录下的文件及子目录, 遇到子目录时通过marker跳过其中的文件
+func listDir(ctx context.Context, c *sdk.Client, prefix string) ([]lsEntry, error) {
+	entries := []lsEntry{}
+	req := sdk.ListFilesRequest{Prefix: prefix, Marker: strings.TrimSuffix(prefix, "/"), Limit: listPageSize}
+	for {
+		resp, err := c.ListFiles(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		skipped := false
+		for _, f := range resp.Files {
+			req.Marker = f.FileName
+			// like查询不区分大小写, 忽略大小写不同的文件
+			if !strings.HasPrefix(f.FileName, prefix) {
+				continue
+			}
+			rel := f.FileName[len(prefix):]
+			if rel == "" {
+				continue
+			}
+			if i := strings.Index(rel, "/"); i >= 0 {
+				entries = append(entries, lsEntry{Name: rel[:i+1], ModTime: f.LastUpdated, IsDir: true})
+				req.Marker = prefix + rel[:i+1] + "\U0010FFFF"
+				skipped = true
+				break
+			}
+			entries = append(entries, fileEntry(f, rel))
+		}
+		if !skipped && resp.NextMarker == "" {
+			return entries, nil
+		}
+	}
+}
+
+func printEntries(entries []lsEntry) error {
+	if opts.jsonOutput {
+		printJSON(entries)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	for _, e := range entries {
+		size := humanSize(e.Size)
+		if e.IsDir {
+			size = "-"
+		}
+		fmt.Fprintf(w, "%s\t  %s\t  %s\t\n", size, e.ModTime, e.Name)
+	}
+	w.Flush()
+	return nil
+}
+
+func newMvCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "mv <src> <dest>",
+		Short: "Move or rename a file",
+		Args:  cobra.ExactArgs(2),
+		RunE: runE(func(ctx context.Context, args []string) error {
+			c, err := loggedInClient()
+			if err != nil {
+				return err
+			}
+			src := strings.TrimPrefix(args[0], "/")
+			dest := strings.TrimPrefix(args[1], "/")
+			if dest == "" || strings.HasSuffix(dest, "/") {
+				dest += path.Base(src)
+			}
+			if err := c.Move(ctx, src, dest); err != nil {
+				return err
+			}
+			if opts.jsonOutput {
+				printJSON(map[string]string{"Src": src, "Dest": dest})
+			}
+			return nil
+		}),
+	}
+}
+
+func newRmCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rm <path>...",
+		Short: "Delete files",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: runE(func(ctx context.Context, args []string) error {
+			c, err := loggedInClient()
+			if err != nil {
+				return err
+			}
+			removed := []string{}
+			for _, arg := range args {
+				name := strings.TrimPrefix(arg, "/")
+				if err := c.Delete(ctx, name); err != nil {
+					return fmt.Errorf("%s: %s", name, err.Error())
+				}
+				removed = append(removed, name)
+			}
+			if opts.jsonOutput {
+				printJSON(map[string][]string{"Removed": removed})
+			}
+			return nil
+		}),
+	}
+}
+
+func newShareCmd() *cobra.Command {
+	var expires time.Duration
+	cmd := &cobra.Command{
+		Use:   "share <path>",
+		Short: "Create a time-limited public download link",
+		Args:  cobra.ExactArgs(1),
+		RunE: runE(func(ctx context.Context, args []string) error {
+			c, err := loggedInClient()
+			if err != nil {
+				return err
+			}
+			link, err := c.CreateShareLink(ctx, strings.TrimPrefix(args[0], "/"), expires)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput {
+				printJSON(link)
+				return nil
+			}
+			fmt.Println(link.URL)
+			logf("expires at %s", link.ExpireAt)
+			return nil
+		}),
+	}
+	cmd.Flags().DurationVar(&expires, "expires", 24*time.Hour, "link lifetime")
+	return cmd
+}
+
+func newQuotaCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "quota",
+		Short: "Show storage usage",
+		Args:  cobra.NoArgs,
+		RunE: runE(func(ctx context.Context, args []string) error {
+			c, err := loggedInClient()
+			if err != nil {
+				return err
+			}
+			usage, err := c.Usage(ctx)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput {
+				printJSON(usage)
+				return nil
+			}
+			fmt.Printf("Files: %d\nUsed:  %s (%d bytes)\n", usage.FileCount, humanSize(usage.TotalSize), usage.TotalSize)
+			return nil
+		}),
+	}
+}
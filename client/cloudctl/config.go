@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+var errNotLoggedIn = errors.New("not logged in, run `cloudctl login` first")
+
+// Credentials : 登录后保存在本地的凭证
+type Credentials struct {
+	// Server : 网关地址, 如 http://127.0.0.1:8080
+	Server        string
+	Username      string
+	Token         string
+	UploadEntry   string
+	DownloadEntry string
+	LoginAt       string
+}
+
+// defaultConfigPath : 默认的凭证文件路径
+func defaultConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".cloudctl.json"
+	}
+	return filepath.Join(home, ".cloudctl", "credentials.json")
+}
+
+func loadCredentials(path string) (*Credentials, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, errNotLoggedIn
+	} else if err != nil {
+		return nil, err
+	}
+	cred := &Credentials{}
+	if err := json.Unmarshal(data, cred); err != nil {
+		return nil, err
+	}
+	if cred.Token == "" {
+		return nil, errNotLoggedIn
+	}
+	return cred, nil
+}
+
+// saveCredentials : 凭证中包含token, 只允许当前用户读写
+func saveCredentials(path string, cred *Credentials) error {
+	data, err := json.MarshalIndent(cred, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
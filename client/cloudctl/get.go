@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cloud/sdk"
+)
+
+// getResult : get命令的输出
+type getResult struct {
+	Remote   string
+	Local    string
+	FileHash string
+	FileSize int64
+	Ranged   bool
+	Resumed  bool
+}
+
+func newGetCmd() *cobra.Command {
+	var parallel int
+	var partSize int64
+	cmd := &cobra.Command{
+		Use:   "get <remote> [local]",
+		Short: "Download a file (parallel ranged requests, resumable)",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: runE(func(ctx context.Context, args []string) error {
+			c, err := loggedInClient()
+			if err != nil {
+				return err
+			}
+			remote := strings.TrimPrefix(args[0], "/")
+			local := path.Base(remote)
+			if len(args) > 1 {
+				local = args[1]
+				if info, err := os.Stat(local); err == nil && info.IsDir() {
+					local = filepath.Join(local, path.Base(remote))
+				}
+			}
+			if partSize <= 0 {
+				return fmt.Errorf("invalid --part-size: %d", partSize)
+			}
+
+			res, err := getFile(ctx, c, remote, local, parallel, partSize)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput {
+				printJSON(res)
+				return nil
+			}
+			logf("downloaded %s -> %s (%s)", res.Remote, res.Local, humanSize(res.FileSize))
+			return nil
+		}),
+	}
+	cmd.Flags().IntVar(&parallel, "parallel", 4, "concurrent range requests")
+	cmd.Flags().Int64Var(&partSize, "part-size", 8<<20, "bytes per range request")
+	return cmd
+}
+
+// getFile : 下载文件. 服务端支持Range时分段并发下载并记录进度, 中断后重新执行可续传;
+// 不支持时(如文件已转移到OSS)整体下载. 完成后校验sha1再重命名为目标文件
+func getFile(ctx context.Context, c *sdk.Client, remote, local string, parallel int, partSize int64) (*getResult, error) {
+	f, err := c.Stat(ctx, remote)
+	if err == sdk.ErrNotFound {
+		return nil, fmt.Errorf("%s: no such file", remote)
+	} else if err != nil {
+		return nil, err
+	}
+
+	down := sdk.NewDownloader(c)
+	down.Concurrency = parallel
+	down.PartSize = partSize
+	bar := newProgress(f.FileSize, "downloading")
+	down.Progress = func(n int64) { bar.Add64(n) }
+	res, err := down.DownloadToFile(ctx, *f, local)
+	if err != nil {
+		return nil, err
+	}
+	bar.Finish()
+	if res.Resumed {
+		logf("resumed download from %s", local+sdk.PartFileSuffix)
+	}
+	return &getResult{
+		Remote:   remote,
+		Local:    local,
+		FileHash: res.FileHash,
+		FileSize: res.FileSize,
+		Ranged:   res.Ranged,
+		Resumed:  res.Resumed,
+	}, nil
+}
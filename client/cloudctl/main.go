@@ -0,0 +1,24 @@
+package main
+
+import (
+	"os"
+)
+
+// cloudctl : 命令行客户端
+//
+//	cloudctl login --server http://127.0.0.1:8080 -u admin
+//	cloudctl ls [prefix]
+//	cloudctl put <local> [remote]
+//	cloudctl get <remote> [local]
+//	cloudctl mv <src> <dest>
+//	cloudctl rm <remote>...
+//	cloudctl share <remote> [--expires 24h]
+//	cloudctl quota
+//	cloudctl sync <local-dir> [remote-dir] [--interval 1m]
+//
+// 所有命令均支持--json, 以JSON格式输出结果便于脚本处理
+func main() {
+	if err := newRootCmd().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
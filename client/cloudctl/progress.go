@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+	"golang.org/x/term"
+)
+
+// newProgress : 创建以字节为单位的进度条, 输出到stderr.
+// --json、--no-progress或stderr不是终端时不显示
+func newProgress(size int64, description string) *progressbar.ProgressBar {
+	if opts.jsonOutput || opts.noProgress || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return progressbar.DefaultBytesSilent(size, description)
+	}
+	return progressbar.NewOptions64(size,
+		progressbar.OptionSetDescription(description),
+		progressbar.OptionSetWriter(os.Stderr),
+		progressbar.OptionShowBytes(true),
+		progressbar.OptionSetWidth(30),
+		progressbar.OptionThrottle(100*time.Millisecond),
+		progressbar.OptionShowCount(),
+		progressbar.OptionOnCompletion(func() {
+			fmt.Fprintln(os.Stderr)
+		}),
+		progressbar.OptionSetPredictTime(true),
+	)
+}
+
+// logf : 输出给用户看的提示信息, --json时不输出
+func logf(format string, args ...interface{}) {
+	if !opts.jsonOutput {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+}
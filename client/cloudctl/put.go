@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cloud/sdk"
+)
+
+// putResult : put命令的输出
+type putResult struct {
+	Local         string
+	Remote        string
+	FileHash      string
+	FileSize      int64
+	Method        string // fast / multipart
+	ChunksResumed int    `json:",omitempty"`
+}
+
+func newPutCmd() *cobra.Command {
+	var parallel, chunkSize int
+	cmd := &cobra.Command{
+		Use:   "put <local> [remote]",
+		Short: "Upload a file (fast upload, then resumable parallel multipart upload)",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: runE(func(ctx context.Context, args []string) error {
+			c, err := loggedInClient()
+			if err != nil {
+				return err
+			}
+			remote := ""
+			if len(args) > 1 {
+				remote = strings.TrimPrefix(args[1], "/")
+			}
+			if remote == "" || strings.HasSuffix(remote, "/") {
+				remote += filepath.Base(args[0])
+			}
+
+			res, err := putFile(ctx, c, args[0], remote, parallel, chunkSize)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput {
+				printJSON(res)
+				return nil
+			}
+			logf("uploaded %s -> %s (%s, %s)", res.Local, res.Remote, humanSize(res.FileSize), res.Method)
+			return nil
+		}),
+	}
+	cmd.Flags().IntVar(&parallel, "parallel", 0, "concurrent chunk uploads (default: server suggestion)")
+	cmd.Flags().IntVar(&chunkSize, "chunk-size", 0, "chunk size in bytes (default: server default)")
+	return cmd
+}
+
+// putFile : 先尝试秒传, 服务端没有相同内容时分块上传.
+// 服务端按用户及文件hash保存未完成的上传会话, 中断后重新执行即可跳过已上传的分块
+func putFile(ctx context.Context, c *sdk.Client, local, remote string, parallel, chunkSize int) (*putResult, error) {
+	fd, err := os.Open(local)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	info, err := fd.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s: not a regular file", local)
+	}
+
+	bar := newProgress(info.Size(), "hashing")
+	filehash, err := sdk.SHA1(ctx, fd, info.Size(), func(n int64) { bar.Add64(n) })
+	if err != nil {
+		return nil, err
+	}
+	bar.Finish()
+
+	up := sdk.NewUploader(c)
+	up.Concurrency = parallel
+	up.ChunkSize = int64(chunkSize)
+	bar = newProgress(info.Size(), "uploading")
+	up.Progress = func(n int64) { bar.Add64(n) }
+	res, err := up.Upload(ctx, fd, info.Size(), filehash, remote)
+	if err != nil {
+		return nil, err
+	}
+	bar.Finish()
+	if res.ChunksResumed > 0 {
+		logf("resumed upload: %d chunks were already uploaded", res.ChunksResumed)
+	}
+	return &putResult{
+		Local:         local,
+		Remote:        remote,
+		FileHash:      res.FileHash,
+		FileSize:      res.FileSize,
+		Method:        res.Method,
+		ChunksResumed: res.ChunksResumed,
+	}, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cloud/sdk"
+)
+
+const (
+	// listPageSize : 列文件时每页的文件数
+	listPageSize = 1000
+)
+
+// globalOptions : 所有命令共用的参数
+type globalOptions struct {
+	configPath string
+	jsonOutput bool
+	noProgress bool
+}
+
+var opts globalOptions
+
+func newRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "cloudctl",
+		Short:         "Command-line client for the cloud storage service",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.PersistentFlags().StringVar(&opts.configPath, "config", defaultConfigPath(), "credentials file")
+	root.PersistentFlags().BoolVar(&opts.jsonOutput, "json", false, "print results as JSON")
+	root.PersistentFlags().BoolVar(&opts.noProgress, "no-progress", false, "disable progress bars")
+
+	root.AddCommand(
+		newLoginCmd(),
+		newLogoutCmd(),
+		newLsCmd(),
+		newPutCmd(),
+		newGetCmd(),
+		newMvCmd(),
+		newRmCmd(),
+		newShareCmd(),
+		newQuotaCmd(),
+		newSyncCmd(),
+		newVaultCmd(),
+	)
+	return root
+}
+
+// runE : 包装命令的执行函数, 注入可被Ctrl-C取消的context并统一输出错误:
+// --json时以JSON输出到stdout, 否则输出到stderr
+func runE(fn func(ctx context.Context, args []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		err := userError(fn(ctx, args))
+		if err != nil {
+			if opts.jsonOutput {
+				printJSON(map[string]string{"error": err.Error()})
+			} else {
+				fmt.Fprintln(os.Stderr, "Error: "+err.Error())
+			}
+		}
+		return err
+	}
+}
+
+// loggedInClient : 读取本地凭证并创建客户端
+func loggedInClient() (*sdk.Client, error) {
+	cred, err := loadCredentials(opts.configPath)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(cred), nil
+}
+
+func printJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// humanSize : 以1024为单位格式化字节数
+func humanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
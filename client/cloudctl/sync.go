@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cloud/client/syncer"
+)
+
+func newSyncCmd() *cobra.Command {
+	var dbPath string
+	var interval time.Duration
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "sync <local-dir> [remote-dir]",
+		Short: "Two-way sync between a local directory and a remote directory",
+		Long: "Two-way sync between a local directory and a remote directory.\n\n" +
+			"Changes on either side since the last sync are applied to the other side.\n" +
+			"When a file was changed on both sides, the local version is kept as a\n" +
+			"\"conflicted copy\" next to the remote version. Sync state is stored in\n" +
+			"<local-dir>/" + syncer.StateFileName + " unless --db is given.\n" +
+			"With --interval the command keeps running and syncs periodically.",
+		Args: cobra.RangeArgs(1, 2),
+		RunE: runE(func(ctx context.Context, args []string) error {
+			cred, err := loadCredentials(opts.configPath)
+			if err != nil {
+				return err
+			}
+			localDir, err := filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+			if info, err := os.Stat(localDir); err != nil {
+				return err
+			} else if !info.IsDir() {
+				return fmt.Errorf("%s: not a directory", localDir)
+			}
+			remoteDir := ""
+			if len(args) > 1 {
+				remoteDir = strings.Trim(args[1], "/")
+			}
+			if remoteDir != "" {
+				remoteDir += "/"
+			}
+			if dbPath == "" {
+				dbPath = filepath.Join(localDir, syncer.StateFileName)
+			}
+
+			state, err := syncer.OpenState(dbPath, cred.Server+"|"+cred.Username+"|"+remoteDir)
+			if err != nil {
+				return err
+			}
+			defer state.Close()
+			s := &syncer.Syncer{
+				Client:    newClient(cred),
+				State:     state,
+				LocalDir:  localDir,
+				RemoteDir: remoteDir,
+				DryRun:    dryRun,
+				Logf:      logf,
+			}
+
+			for {
+				report, err := s.Sync(ctx)
+				if err != nil && ctx.Err() != nil && interval > 0 {
+					return nil
+				} else if err != nil {
+					return err
+				}
+				if opts.jsonOutput {
+					printJSON(report)
+				} else {
+					logf("synced: %d uploaded, %d downloaded, %d deleted locally, %d deleted remotely, %d conflicts, %d errors",
+						report.Uploaded, report.Downloaded, report.DeletedLocal, report.DeletedRemote,
+						report.Conflicts, len(report.Errors))
+				}
+				if interval <= 0 {
+					if len(report.Errors) > 0 {
+						return fmt.Errorf("%d files failed to sync", len(report.Errors))
+					}
+					return nil
+				}
+				select {
+				case <-time.After(interval):
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}),
+	}
+	cmd.Flags().StringVar(&dbPath, "db", "", "sync state database (default: <local-dir>/"+syncer.StateFileName+")")
+	cmd.Flags().DurationVar(&interval, "interval", 0, "keep running and sync at this interval")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only print what would be done")
+	return cmd
+}
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/cloud/sdk"
+)
+
+// envVaultPassphrase : 加密目录口令的环境变量, 未设置时从终端读取
+const envVaultPassphrase = "CLOUDCTL_VAULT_PASSPHRASE"
+
+// vaultEntry : vault ls命令输出的一项, Size为明文大小
+type vaultEntry struct {
+	Name    string
+	Size    int64
+	ModTime string
+}
+
+func newVaultCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vault",
+		Short: "End-to-end encrypted folders (names and content are encrypted locally)",
+	}
+	cmd.AddCommand(
+		newVaultInitCmd(),
+		newVaultPasswdCmd(),
+		newVaultCreateCmd(),
+		newVaultListCmd(),
+		newVaultLsCmd(),
+		newVaultPutCmd(),
+		newVaultGetCmd(),
+		newVaultRmCmd(),
+		newVaultShareCmd(),
+		newVaultUnshareCmd(),
+		newVaultMembersCmd(),
+	)
+	return cmd
+}
+
+// readPassphrase : 读取加密目录口令, 优先使用环境变量
+func readPassphrase(prompt string) (string, error) {
+	if p := os.Getenv(envVaultPassphrase); p != "" {
+		return p, nil
+	}
+	p, err := readSecret(prompt)
+	if err == nil && p == "" {
+		err = errors.New("empty passphrase")
+	}
+	return p, err
+}
+
+// unlockVault : 以口令解锁密钥对并打开owner的加密目录folder
+func unlockVault(ctx context.Context, c *sdk.Client, owner, folder string) (*sdk.Vault, error) {
+	passphrase, err := readPassphrase("Vault passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	keys, err := c.UnlockVaultKeys(ctx, passphrase)
+	if err == sdk.ErrVaultPassphrase {
+		return nil, errors.New("wrong vault passphrase")
+	} else if err != nil {
+		return nil, err
+	}
+	v, err := c.OpenVault(ctx, keys, owner, folder)
+	if err == sdk.ErrNotFound {
+		return nil, fmt.Errorf("%s: not an encrypted folder", folder)
+	}
+	return v, err
+}
+
+func newVaultInitCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "init",
+		Short: "Generate your vault key pair, protected by a passphrase",
+		Args:  cobra.NoArgs,
+		RunE: runE(func(ctx context.Context, args []string) error {
+			c, err := loggedInClient()
+			if err != nil {
+				return err
+			}
+			passphrase, err := readPassphrase("New vault passphrase: ")
+			if err != nil {
+				return err
+			}
+			if os.Getenv(envVaultPassphrase) == "" && term.IsTerminal(int(os.Stdin.Fd())) {
+				confirm, err := readSecret("Repeat passphrase: ")
+				if err != nil {
+					return err
+				}
+				if confirm != passphrase {
+					return errors.New("passphrases do not match")
+				}
+			}
+			keys, err := c.SetupVaultKeys(ctx, passphrase)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput {
+				printJSON(map[string]string{"PublicKey": keys.PublicKey()})
+				return nil
+			}
+			logf("vault key pair created; the passphrase cannot be recovered, keep it safe")
+			return nil
+		}),
+	}
+}
+
+func newVaultPasswdCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "passwd",
+		Short: "Change the vault passphrase",
+		Args:  cobra.NoArgs,
+		RunE: runE(func(ctx context.Context, args []string) error {
+			c, err := loggedInClient()
+			if err != nil {
+				return err
+			}
+			oldPassphrase, err := readSecret("Current vault passphrase: ")
+			if err != nil {
+				return err
+			}
+			newPassphrase, err := readSecret("New vault passphrase: ")
+			if err != nil {
+				return err
+			}
+			if newPassphrase == "" {
+				return errors.New("empty passphrase")
+			}
+			err = c.ChangeVaultPassphrase(ctx, oldPassphrase, newPassphrase)
+			if err == sdk.ErrVaultPassphrase {
+				return errors.New("wrong vault passphrase")
+			}
+			return err
+		}),
+	}
+}
+
+func newVaultCreateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "create <folder>",
+		Short: "Turn an empty folder into an encrypted folder",
+		Args:  cobra.ExactArgs(1),
+		RunE: runE(func(ctx context.Context, args []string) error {
+			c, err := loggedInClient()
+			if err != nil {
+				return err
+			}
+			passphrase, err := readPassphrase("Vault passphrase: ")
+			if err != nil {
+				return err
+			}
+			keys, err := c.UnlockVaultKeys(ctx, passphrase)
+			if err != nil {
+				return err
+			}
+			v, err := c.CreateVault(ctx, keys, args[0])
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput {
+				printJSON(map[string]string{"Folder": v.Folder})
+			}
+			return nil
+		}),
+	}
+}
+
+func newVaultListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List your encrypted folders and those shared with you",
+		Args:  cobra.NoArgs,
+		RunE: runE(func(ctx context.Context, args []string) error {
+			c, err := loggedInClient()
+			if err != nil {
+				return err
+			}
+			vaults, err := c.ListVaults(ctx)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput {
+				for i := range vaults {
+					vaults[i].WrappedKey = ""
+				}
+				printJSON(vaults)
+				return nil
+			}
+			for _, v := range vaults {
+				fmt.Printf("%s\t%s\n", v.Owner, v.Folder)
+			}
+			return nil
+		}),
+	}
+}
+
+func newVaultLsCmd() *cobra.Command {
+	var owner string
+	cmd := &cobra.Command{
+		Use:   "ls <folder> [path]",
+		Short: "List files in an encrypted folder",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: runE(func(ctx context.Context, args []string) error {
+			c, err := loggedInClient()
+			if err != nil {
+				return err
+			}
+			v, err := unlockVault(ctx, c, owner, args[0])
+			if err != nil {
+				return err
+			}
+			prefix := ""
+			if len(args) > 1 {
+				prefix = strings.Trim(args[1], "/") + "/"
+			}
+			files, err := v.List(ctx, prefix)
+			if err != nil {
+				return err
+			}
+			entries := []vaultEntry{}
+			for _, f := range files {
+				entries = append(entries, vaultEntry{Name: f.Name, Size: f.Size, ModTime: f.LastUpdated})
+			}
+			if opts.jsonOutput {
+				printJSON(entries)
+				return nil
+			}
+			for _, e := range entries {
+				fmt.Printf("%8s  %s  %s\n", humanSize(e.Size), e.ModTime, e.Name)
+			}
+			return nil
+		}),
+	}
+	cmd.Flags().StringVar(&owner, "owner", "", "owner of a folder shared with you")
+	return cmd
+}
+
+func newVaultPutCmd() *cobra.Command {
+	var parallel, chunkSize int
+	cmd := &cobra.Command{
+		Use:   "put <folder> <local> [path]",
+		Short: "Encrypt a local file and upload it into an encrypted folder",
+		Args:  cobra.RangeArgs(2, 3),
+		RunE: runE(func(ctx context.Context, args []string) error {
+			c, err := loggedInClient()
+			if err != nil {
+				return err
+			}
+			v, err := unlockVault(ctx, c, "", args[0])
+			if err != nil {
+				return err
+			}
+			local := args[1]
+			name := ""
+			if len(args) > 2 {
+				name = strings.TrimPrefix(args[2], "/")
+			}
+			if name == "" || strings.HasSuffix(name, "/") {
+				name += filepath.Base(local)
+			}
+			info, err := os.Stat(local)
+			if err != nil {
+				return err
+			}
+
+			up := sdk.NewUploader(c)
+			up.Concurrency = parallel
+			up.ChunkSize = int64(chunkSize)
+			bar := newProgress(sdk.VaultEncryptedSize(info.Size()), "uploading")
+			up.Progress = func(n int64) { bar.Add64(n) }
+			res, err := v.UploadFile(ctx, up, local, name)
+			bar.Finish()
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput {
+				printJSON(map[string]interface{}{
+					"Local":    local,
+					"Name":     name,
+					"FileSize": info.Size(),
+					"Remote":   res.FileName,
+				})
+				return nil
+			}
+			logf("uploaded %s -> %s%s (%s, encrypted)", local, v.Folder, name, humanSize(info.Size()))
+			return nil
+		}),
+	}
+	cmd.Flags().IntVar(&parallel, "parallel", 0, "concurrent chunk uploads (default: server suggestion)")
+	cmd.Flags().IntVar(&chunkSize, "chunk-size", 0, "chunk size in bytes (default: server default)")
+	return cmd
+}
+
+func newVaultGetCmd() *cobra.Command {
+	var owner string
+	cmd := &cobra.Command{
+		Use:   "get <folder> <path> [local]",
+		Short: "Download and decrypt a file from an encrypted folder",
+		Args:  cobra.RangeArgs(2, 3),
+		RunE: runE(func(ctx context.Context, args []string) error {
+			c, err := loggedInClient()
+			if err != nil {
+				return err
+			}
+			v, err := unlockVault(ctx, c, owner, args[0])
+			if err != nil {
+				return err
+			}
+			name := strings.TrimPrefix(args[1], "/")
+			local := path.Base(name)
+			if len(args) > 2 {
+				local = args[2]
+				if info, err := os.Stat(local); err == nil && info.IsDir() {
+					local = filepath.Join(local, path.Base(name))
+				}
+			}
+			n, err := v.DownloadFile(ctx, name, local)
+			if err == sdk.ErrNotFound {
+				return fmt.Errorf("%s: no such file", name)
+			} else if err != nil {
+				return err
+			}
+			if opts.jsonOutput {
+				printJSON(map[string]interface{}{"Name": name, "Local": local, "FileSize": n})
+				return nil
+			}
+			logf("downloaded %s%s -> %s (%s, decrypted)", v.Folder, name, local, humanSize(n))
+			return nil
+		}),
+	}
+	cmd.Flags().StringVar(&owner, "owner", "", "owner of a folder shared with you")
+	return cmd
+}
+
+func newVaultRmCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rm <folder> <path>...",
+		Short: "Delete files from an encrypted folder",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: runE(func(ctx context.Context, args []string) error {
+			c, err := loggedInClient()
+			if err != nil {
+				return err
+			}
+			v, err := unlockVault(ctx, c, "", args[0])
+			if err != nil {
+				return err
+			}
+			for _, arg := range args[1:] {
+				name := strings.TrimPrefix(arg, "/")
+				if err := v.Remove(ctx, name); err != nil {
+					return fmt.Errorf("%s: %s", name, err.Error())
+				}
+			}
+			return nil
+		}),
+	}
+}
+
+func newVaultShareCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "share <folder> <user>",
+		Short: "Share an encrypted folder with a user (wraps the folder key with their public key)",
+		Args:  cobra.ExactArgs(2),
+		RunE: runE(func(ctx context.Context, args []string) error {
+			c, err := loggedInClient()
+			if err != nil {
+				return err
+			}
+			v, err := unlockVault(ctx, c, "", args[0])
+			if err != nil {
+				return err
+			}
+			return v.Share(ctx, args[1])
+		}),
+	}
+}
+
+func newVaultUnshareCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unshare <folder> <user>",
+		Short: "Stop sharing an encrypted folder with a user",
+		Args:  cobra.ExactArgs(2),
+		RunE: runE(func(ctx context.Context, args []string) error {
+			c, err := loggedInClient()
+			if err != nil {
+				return err
+			}
+			v, err := unlockVault(ctx, c, "", args[0])
+			if err != nil {
+				return err
+			}
+			if err := v.Unshare(ctx, args[1]); err != nil {
+				return err
+			}
+			logf("access removed; files %s already downloaded remain readable to them", args[1])
+			return nil
+		}),
+	}
+}
+
+func newVaultMembersCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "members <folder>",
+		Short: "List users who can access an encrypted folder",
+		Args:  cobra.ExactArgs(1),
+		RunE: runE(func(ctx context.Context, args []string) error {
+			c, err := loggedInClient()
+			if err != nil {
+				return err
+			}
+			v, err := unlockVault(ctx, c, "", args[0])
+			if err != nil {
+				return err
+			}
+			members, err := v.Members(ctx)
+			if err != nil {
+				return err
+			}
+			if opts.jsonOutput {
+				printJSON(members)
+				return nil
+			}
+			for _, m := range members {
+				fmt.Println(m)
+			}
+			return nil
+		}),
+	}
+}
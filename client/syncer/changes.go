@@ -0,0 +1,50 @@
+package syncer
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cloud/sdk"
+)
+
+// latestCursor : 列出远端之前取得的最新变更游标. 服务端不支持变更事件时为空, 每轮都列出远端
+func (s *Syncer) latestCursor(ctx context.Context) string {
+	changes, err := s.Client.Changes(ctx, "", 0)
+	if err != nil {
+		return ""
+	}
+	return changes.Cursor
+}
+
+// remoteUnchanged : 游标之后没有涉及远端目录的变更事件.
+// 上一轮同步后远端与基准一致(本轮自身的修改也产生事件), 此时基准即为远端的当前状态
+func (s *Syncer) remoteUnchanged(ctx context.Context, cursor string) bool {
+	for cursor != "" {
+		changes, err := s.Client.Changes(ctx, cursor, 0)
+		if err != nil {
+			return false
+		}
+		// 文件名不区分大小写, 按小写前缀判断以免漏掉变化
+		dir := strings.ToLower(s.RemoteDir)
+		for _, ev := range changes.Events {
+			if strings.HasPrefix(strings.ToLower(ev.FileName), dir) ||
+				(ev.OldName != "" && strings.HasPrefix(strings.ToLower(ev.OldName), dir)) {
+				return false
+			}
+		}
+		if !changes.HasMore {
+			return true
+		}
+		cursor = changes.Cursor
+	}
+	return false
+}
+
+// baseRemotes : 以基准构造远端文件列表, 用于远端没有变化时代替列出
+func (s *Syncer) baseRemotes(base map[string]Entry) map[string]*sdk.FileMeta {
+	remotes := map[string]*sdk.FileMeta{}
+	for rel, e := range base {
+		remotes[rel] = &sdk.FileMeta{FileHash: e.Hash, FileName: s.remoteName(rel), FileSize: e.Size}
+	}
+	return remotes
+}
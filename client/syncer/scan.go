@@ -0,0 +1,95 @@
+package syncer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloud/sdk"
+)
+
+const (
+	// StateFileName : 默认的状态库文件名, 位于同步目录下且不参与同步
+	StateFileName = ".cloudsync.db"
+)
+
+// localFile : 扫描得到的本地文件
+type localFile struct {
+	Size  int64
+	MTime int64
+	Hash  string
+}
+
+// unchangedSince : 大小及修改时间与基准相同
+func (f *localFile) unchangedSince(e Entry) bool {
+	return f.Size == e.Size && f.MTime == e.MTime
+}
+
+// excluded : 不参与同步的文件: 状态库及下载过程中的临时文件
+func excluded(rel string) bool {
+	name := rel[strings.LastIndex(rel, "/")+1:]
+	return strings.HasPrefix(name, StateFileName) ||
+		strings.HasSuffix(name, sdk.PartFileSuffix) ||
+		strings.HasSuffix(name, sdk.StateFileSuffix)
+}
+
+// scanLocal : 遍历本地目录. 大小及修改时间与上次同步时相同的文件沿用记录的hash,
+// 否则(新文件或已修改)重新计算
+func (s *Syncer) scanLocal(ctx context.Context, base map[string]Entry) (map[string]*localFile, error) {
+	files := map[string]*localFile{}
+	err := filepath.Walk(s.LocalDir, func(path string, info os.FileInfo, err error) error {
+		// 遍历过程中被删除的文件留到下一轮处理
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.LocalDir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if excluded(rel) {
+			return nil
+		}
+
+		f := &localFile{Size: info.Size(), MTime: info.ModTime().UnixNano()}
+		if e, ok := base[rel]; ok && f.unchangedSince(e) {
+			f.Hash = e.Hash
+		} else if f.Hash, err = hashLocal(ctx, path, f.Size); os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		files[rel] = f
+		return nil
+	})
+	return files, err
+}
+
+func hashLocal(ctx context.Context, path string, size int64) (string, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer fd.Close()
+	return sdk.SHA1(ctx, fd, size, nil)
+}
+
+// statLocal : 重新读取本地文件的大小及修改时间, 文件不存在时返回nil
+func statLocal(path string) (*localFile, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &localFile{Size: info.Size(), MTime: info.ModTime().UnixNano()}, nil
+}
@@ -0,0 +1,119 @@
+package syncer
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// entriesBucket : 相对路径 -> 上次同步完成时的文件状态
+	entriesBucket = []byte("entries")
+	// metaBucket : 同步目录的配置
+	metaBucket = []byte("meta")
+	// remoteKey : 状态库绑定的远端(服务地址+用户+目录), 防止误用于其他目录
+	remoteKey = []byte("remote")
+	// cursorKey : 上一轮同步开始时远端的变更游标
+	cursorKey = []byte("cursor")
+)
+
+// Entry : 上次同步完成时本地与远端一致的文件状态, 作为判断双方各自变化的基准
+type Entry struct {
+	// Hash : 文件内容的sha1, 本地与远端相同
+	Hash string
+	// Size/MTime : 本地文件的大小及修改时间(纳秒), 两者不变时认为内容未变, 无需重新计算hash
+	Size  int64
+	MTime int64
+}
+
+// State : 保存在本地bolt数据库中的同步状态, 重启后无需重新计算全部文件的hash
+type State struct {
+	db *bolt.DB
+}
+
+// OpenState : 打开(或创建)状态库. remote用于标识同步的远端目录, 与库中记录不一致时返回错误
+func OpenState(path, remote string) (*State, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open sync state %s: %s", path, err.Error())
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(entriesBucket); err != nil {
+			return err
+		}
+		meta, err := tx.CreateBucketIfNotExists(metaBucket)
+		if err != nil {
+			return err
+		}
+		if bound := meta.Get(remoteKey); bound != nil && string(bound) != remote {
+			return fmt.Errorf("sync state %s belongs to %s, not %s", path, string(bound), remote)
+		}
+		return meta.Put(remoteKey, []byte(remote))
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &State{db: db}, nil
+}
+
+// Close : 关闭状态库
+func (s *State) Close() error {
+	return s.db.Close()
+}
+
+// Entries : 读取全部文件的同步状态
+func (s *State) Entries() (map[string]Entry, error) {
+	entries := map[string]Entry{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(entriesBucket).ForEach(func(k, v []byte) error {
+			var e Entry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+			entries[string(k)] = e
+			return nil
+		})
+	})
+	return entries, err
+}
+
+// Put : 记录文件的同步状态
+func (s *State) Put(rel string, e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(entriesBucket).Put([]byte(rel), data)
+	})
+}
+
+// Delete : 删除文件的同步状态
+func (s *State) Delete(rel string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(entriesBucket).Delete([]byte(rel))
+	})
+}
+
+// Cursor : 读取上一轮同步记录的远端变更游标, 没有时为空
+func (s *State) Cursor() (string, error) {
+	var cursor string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor = string(tx.Bucket(metaBucket).Get(cursorKey))
+		return nil
+	})
+	return cursor, err
+}
+
+// SetCursor : 记录远端变更游标, 为空时删除
+func (s *State) SetCursor(cursor string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if cursor == "" {
+			return tx.Bucket(metaBucket).Delete(cursorKey)
+		}
+		return tx.Bucket(metaBucket).Put(cursorKey, []byte(cursor))
+	})
+}
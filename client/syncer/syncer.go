@@ -0,0 +1,353 @@
+// Package syncer : 本地目录与远端目录的双向同步.
+//
+// 每轮同步以状态库中上次同步完成时的文件状态为基准, 分别比较本地及远端的当前状态:
+// 只有一方变化时将变化同步到另一方; 双方都变化且内容不同时保留两份, 本地文件改名为冲突副本后上传,
+// 远端文件下载到原路径. 本地变化按大小+修改时间判断, 不一致时再计算sha1;
+// 远端变化按分页列出的文件hash判断, 服务端的变更事件表明远端目录没有变化时不再列出.
+// 上传时先秒传, 服务端已有相同内容时无需传输数据
+package syncer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloud/sdk"
+)
+
+// 同步动作
+const (
+	actionUpload       = "upload"
+	actionDownload     = "download"
+	actionDeleteLocal  = "delete-local"
+	actionDeleteRemote = "delete-remote"
+	actionConflict     = "conflict"
+	// actionRecord : 双方内容一致, 只需更新基准
+	actionRecord = "record"
+	// actionForget : 双方都已删除, 删除基准
+	actionForget = "forget"
+)
+
+// errChangedDuringSync : 执行动作前发现本地文件在扫描之后又发生了变化, 留到下一轮处理
+var errChangedDuringSync = errors.New("changed during sync, will retry next round")
+
+// Syncer : 将LocalDir与远端RemoteDir下的文件双向同步. 空目录不同步
+type Syncer struct {
+	Client *sdk.Client
+	State  *State
+	// LocalDir : 本地目录
+	LocalDir string
+	// RemoteDir : 远端目录, 为空表示用户的全部文件, 否则以"/"结尾
+	RemoteDir string
+	// DryRun : 只输出计划执行的动作
+	DryRun bool
+	// Logf : 输出每个动作, 为空时不输出
+	Logf func(format string, args ...interface{})
+}
+
+// Report : 一轮同步的结果
+type Report struct {
+	Uploaded      int
+	Downloaded    int
+	DeletedLocal  int
+	DeletedRemote int
+	Conflicts     int
+	Errors        []string `json:",omitempty"`
+}
+
+// action : 针对单个文件的同步动作
+type action struct {
+	kind   string
+	rel    string
+	local  *localFile
+	remote *sdk.FileMeta
+}
+
+// Sync : 执行一轮同步. 单个文件失败不影响其他文件, 失败的文件记录在Report.Errors中并在下一轮重试
+func (s *Syncer) Sync(ctx context.Context) (*Report, error) {
+	base, err := s.State.Entries()
+	if err != nil {
+		return nil, err
+	}
+	locals, err := s.scanLocal(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+	// 先取得游标再列出远端, 列出期间及本轮自身的修改都在游标之后, 下一轮会重新列出
+	lastCursor, err := s.State.Cursor()
+	if err != nil {
+		return nil, err
+	}
+	cursor := s.latestCursor(ctx)
+	var remotes map[string]*sdk.FileMeta
+	if lastCursor != "" && s.remoteUnchanged(ctx, lastCursor) {
+		remotes = s.baseRemotes(base)
+	} else if remotes, err = s.listRemote(ctx); err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	for _, act := range plan(base, locals, remotes) {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if act.kind != actionRecord && act.kind != actionForget {
+			s.logf("%-13s %s", act.kind, act.rel)
+		}
+		if s.DryRun {
+			continue
+		}
+		if err := s.apply(ctx, act, report); err != nil {
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+			s.logf("%-13s %s: %s", "error", act.rel, err.Error())
+			report.Errors = append(report.Errors, act.rel+": "+err.Error())
+		}
+	}
+	if !s.DryRun {
+		if err := s.State.SetCursor(cursor); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// listRemote : 分页列出远端目录下的全部文件(不含目录), 前缀匹配不区分大小写, 需按字节再次过滤
+func (s *Syncer) listRemote(ctx context.Context) (map[string]*sdk.FileMeta, error) {
+	remotes := map[string]*sdk.FileMeta{}
+	err := s.Client.WalkFiles(ctx, s.RemoteDir, func(f sdk.FileMeta) error {
+		if !strings.HasPrefix(f.FileName, s.RemoteDir) || strings.HasSuffix(f.FileName, "/") {
+			return nil
+		}
+		rel := f.FileName[len(s.RemoteDir):]
+		if !excluded(rel) {
+			remotes[rel] = &f
+		}
+		return nil
+	})
+	return remotes, err
+}
+
+// plan : 以基准比较本地及远端, 生成按路径排序的同步动作
+func plan(base map[string]Entry, locals map[string]*localFile, remotes map[string]*sdk.FileMeta) []action {
+	paths := map[string]bool{}
+	for rel := range base {
+		paths[rel] = true
+	}
+	for rel := range locals {
+		paths[rel] = true
+	}
+	for rel := range remotes {
+		paths[rel] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for rel := range paths {
+		sorted = append(sorted, rel)
+	}
+	sort.Strings(sorted)
+
+	actions := []action{}
+	for _, rel := range sorted {
+		b, hasBase := base[rel]
+		l, r := locals[rel], remotes[rel]
+		localChanged := l != nil && (!hasBase || l.Hash != b.Hash)
+		remoteChanged := r != nil && (!hasBase || r.FileHash != b.Hash)
+
+		act := action{rel: rel, local: l, remote: r}
+		switch {
+		case l != nil && r != nil && l.Hash == r.FileHash:
+			// 内容一致(包括双方做了相同的修改), 只在基准过期时更新
+			if !hasBase || b.Hash != l.Hash || !l.unchangedSince(b) {
+				act.kind = actionRecord
+			}
+		case l != nil && r != nil:
+			if localChanged && remoteChanged {
+				act.kind = actionConflict
+			} else if localChanged {
+				act.kind = actionUpload
+			} else {
+				act.kind = actionDownload
+			}
+		case l != nil:
+			// 远端删除而本地未修改时删除本地, 本地有修改时保留修改
+			if hasBase && !localChanged {
+				act.kind = actionDeleteLocal
+			} else {
+				act.kind = actionUpload
+			}
+		case r != nil:
+			if hasBase && !remoteChanged {
+				act.kind = actionDeleteRemote
+			} else {
+				act.kind = actionDownload
+			}
+		default:
+			act.kind = actionForget
+		}
+		if act.kind != "" {
+			actions = append(actions, act)
+		}
+	}
+	return actions
+}
+
+// apply : 执行单个动作并更新基准
+func (s *Syncer) apply(ctx context.Context, act action, report *Report) error {
+	switch act.kind {
+	case actionUpload:
+		if err := s.upload(ctx, act.rel, act.local); err != nil {
+			return err
+		}
+		report.Uploaded++
+	case actionDownload:
+		if err := s.download(ctx, act.rel, act.local, act.remote); err != nil {
+			return err
+		}
+		report.Downloaded++
+	case actionDeleteLocal:
+		if err := s.checkLocal(act.rel, act.local); err != nil {
+			return err
+		}
+		if err := os.Remove(s.localPath(act.rel)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		report.DeletedLocal++
+		return s.State.Delete(act.rel)
+	case actionDeleteRemote:
+		if err := s.checkLocal(act.rel, nil); err != nil {
+			return err
+		}
+		if err := s.Client.Delete(ctx, s.remoteName(act.rel)); err != nil {
+			return err
+		}
+		report.DeletedRemote++
+		return s.State.Delete(act.rel)
+	case actionConflict:
+		if err := s.resolveConflict(ctx, act); err != nil {
+			return err
+		}
+		report.Conflicts++
+	case actionRecord:
+		return s.State.Put(act.rel, Entry{Hash: act.local.Hash, Size: act.local.Size, MTime: act.local.MTime})
+	case actionForget:
+		return s.State.Delete(act.rel)
+	}
+	return nil
+}
+
+// upload : 上传本地文件(优先秒传), 覆盖远端同名文件
+func (s *Syncer) upload(ctx context.Context, rel string, l *localFile) error {
+	if err := s.checkLocal(rel, l); err != nil {
+		return err
+	}
+	fd, err := os.Open(s.localPath(rel))
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	if _, err := sdk.NewUploader(s.Client).Upload(ctx, fd, l.Size, l.Hash, s.remoteName(rel)); err != nil {
+		return err
+	}
+	// 上传过程中本地又被修改时不更新基准, 下一轮按本地变化重新上传
+	if err := s.checkLocal(rel, l); err != nil {
+		return err
+	}
+	return s.State.Put(rel, Entry{Hash: l.Hash, Size: l.Size, MTime: l.MTime})
+}
+
+// download : 下载远端文件覆盖本地文件. 数据先写入临时文件, 校验通过后才替换
+func (s *Syncer) download(ctx context.Context, rel string, l *localFile, r *sdk.FileMeta) error {
+	if err := s.checkLocal(rel, l); err != nil {
+		return err
+	}
+	local := s.localPath(rel)
+	if err := os.MkdirAll(filepath.Dir(local), 0755); err != nil {
+		return err
+	}
+	if _, err := sdk.NewDownloader(s.Client).DownloadToFile(ctx, *r, local); err != nil {
+		return err
+	}
+	info, err := os.Stat(local)
+	if err != nil {
+		return err
+	}
+	return s.State.Put(rel, Entry{Hash: r.FileHash, Size: info.Size(), MTime: info.ModTime().UnixNano()})
+}
+
+// resolveConflict : 双方都修改时保留两份: 本地文件改名为冲突副本并上传, 远端文件下载到原路径
+func (s *Syncer) resolveConflict(ctx context.Context, act action) error {
+	if err := s.checkLocal(act.rel, act.local); err != nil {
+		return err
+	}
+	copyRel, err := s.conflictName(ctx, act.rel)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(s.localPath(act.rel), s.localPath(copyRel)); err != nil {
+		return err
+	}
+	s.logf("%-13s %s -> %s", "keep-both", act.rel, copyRel)
+	// 改名不改变修改时间, 副本的状态与原文件相同
+	if err := s.upload(ctx, copyRel, act.local); err != nil {
+		return err
+	}
+	return s.download(ctx, act.rel, nil, act.remote)
+}
+
+// conflictName : 生成本地及远端都不存在的冲突副本路径, 如 a/report (conflicted copy 2020-05-01 150405).docx
+func (s *Syncer) conflictName(ctx context.Context, rel string) (string, error) {
+	dir, name := path.Split(rel)
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	stamp := time.Now().Format("2006-01-02 150405")
+	for i := 1; ; i++ {
+		suffix := ""
+		if i > 1 {
+			suffix = fmt.Sprintf(" %d", i)
+		}
+		candidate := fmt.Sprintf("%s%s (conflicted copy %s%s)%s", dir, stem, stamp, suffix, ext)
+		if _, err := os.Stat(s.localPath(candidate)); !os.IsNotExist(err) {
+			continue
+		}
+		_, err := s.Client.Stat(ctx, s.remoteName(candidate))
+		if err == sdk.ErrNotFound {
+			return candidate, nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+}
+
+// checkLocal : 确认本地文件与扫描时一致(expect为nil表示不存在), 避免覆盖或删除扫描之后的修改
+func (s *Syncer) checkLocal(rel string, expect *localFile) error {
+	current, err := statLocal(s.localPath(rel))
+	if err != nil {
+		return err
+	}
+	if (current == nil) != (expect == nil) ||
+		(current != nil && (current.Size != expect.Size || current.MTime != expect.MTime)) {
+		return errChangedDuringSync
+	}
+	return nil
+}
+
+func (s *Syncer) localPath(rel string) string {
+	return filepath.Join(s.LocalDir, filepath.FromSlash(rel))
+}
+
+func (s *Syncer) remoteName(rel string) string {
+	return s.RemoteDir + rel
+}
+
+func (s *Syncer) logf(format string, args ...interface{}) {
+	if s.Logf != nil {
+		s.Logf(format, args...)
+	}
+}
@@ -0,0 +1,54 @@
+package common
+
+import "encoding/json"
+
+// 网关及上传服务HTTP接口响应中data的结构, 由接口实现及测试用的模拟服务端共用
+
+// SigninData : /user/signin登录成功时返回的数据
+type SigninData struct {
+	Location string
+	Username string
+	Token    string
+	// UploadEntry/DownloadEntry : 上传/下载服务的地址, 一般为host:port形式
+	UploadEntry   string
+	DownloadEntry string
+}
+
+// UserInfoData : /user/info返回的数据
+type UserInfoData struct {
+	Username   string
+	SignupAt   string
+	LastActive string
+}
+
+// UserUsageData : /user/usage返回的数据
+type UserUsageData struct {
+	FileCount int64
+	TotalSize int64
+}
+
+// FileListData : /file/list返回的数据, Files为用户文件记录(orm.TableUserFile)的列表
+type FileListData struct {
+	Files      json.RawMessage
+	NextMarker string
+}
+
+// FileChangesData : /file/changes返回的数据, Events为变更事件(orm.TableUserFileEvent)的列表
+type FileChangesData struct {
+	Events  []json.RawMessage
+	Cursor  string
+	HasMore bool
+}
+
+// MultipartUploadInfo : /file/mpupload/init返回的分块上传初始化信息
+type MultipartUploadInfo struct {
+	FileHash   string
+	FileSize   int
+	UploadID   string
+	ChunkSize  int
+	ChunkCount int
+	// 已经上传完成的分块索引列表
+	ChunkExists []int
+	// 建议客户端同时上传的最大分块数
+	MaxParallel int
+}
@@ -21,6 +21,10 @@ const (
 	FileAlreadExists
 	// StatusUserNotExists : 10007 用户不存在
 	StatusUserNotExists
+	// StatusFileRestoring : 10008 文件已归档, 正在解冻, 稍后重试
+	StatusFileRestoring
+	// StatusVaultUnsupported : 10009 加密目录不支持该操作(秒传、分享链接、移出或移入加密目录)
+	StatusVaultUnsupported
 )
 
 
@@ -0,0 +1,19 @@
+package common
+
+// 用户文件变更事件类型, 按发生顺序记录在用户的事件日志中
+const (
+	// FileEventUpload : 上传完成(包括秒传及覆盖同名文件)
+	FileEventUpload = "upload"
+	// FileEventRename : 同一目录下重命名
+	FileEventRename = "rename"
+	// FileEventMove : 移动到其他目录
+	FileEventMove = "move"
+	// FileEventDelete : 删除(标记删除)
+	FileEventDelete = "delete"
+	// FileEventRestore : 恢复已删除的文件
+	FileEventRestore = "restore"
+	// FileEventShare : 生成分享链接
+	FileEventShare = "share"
+	// FileEventTransfer : 文件内容已转移到Ceph/OSS
+	FileEventTransfer = "transfer"
+)
@@ -14,4 +14,47 @@ const (
 	StoreMix
 	// StoreAll : 启用所有类型的存储
 	StoreAll
+	// StoreErasure : 本地多磁盘纠删码存储
+	StoreErasure
+)
+
+// LocationStatus : 文件副本(tbl_file_location)的状态
+type LocationStatus int
+
+const (
+	_ LocationStatus = iota
+	// LocationAvailable : 副本可用
+	LocationAvailable
+	// LocationMissing : 副本读取失败或缺失, 等待修复
+	LocationMissing
+	// LocationCorrupt : 副本内容与sha1不一致, 已隔离(不再读取), 等待修复
+	LocationCorrupt
+)
+
+// ScrubResult : 副本完整性检查(tbl_file_scrub)的结果
+type ScrubResult int
+
+const (
+	_ ScrubResult = iota
+	// ScrubOK : 内容与sha1一致
+	ScrubOK
+	// ScrubMismatch : 内容与sha1不一致
+	ScrubMismatch
+	// ScrubUnreadable : 副本读取失败
+	ScrubUnreadable
+	// ScrubSkipped : 副本无法读取且没有可比较的校验值(如未做过完整检查的归档对象)
+	ScrubSkipped
+)
+
+// StorageTier : 文件的存储层级
+type StorageTier int
+
+const (
+	_ StorageTier = iota
+	// TierHot : 热存储, 文件在本地或Ceph上
+	TierHot
+	// TierCold : 低频访问, 文件只在OSS上且存储类型为IA
+	TierCold
+	// TierArchive : 归档, 文件只在OSS上且存储类型为Archive, 读取前需要解冻
+	TierArchive
 )
@@ -3,5 +3,15 @@ package config
 const (
 	CephAccessKey = ""
 	CephSecretKey = ""
+	// CephGWEndpoint : S3兼容网关的地址(host:port), 可以是Ceph RGW、MinIO或SeaweedFS
 	CephGWEndpoint = "127.0.0.1:9080"
+	// CephRegion : SigV4签名使用的region, Ceph RGW默认为"default", MinIO为"us-east-1"
+	CephRegion = "default"
+	// CephUseSSL : 是否以https访问网关
+	CephUseSSL = false
+	// CephPathStyle : 以路径(endpoint/bucket/key)而不是虚拟主机(bucket.endpoint/key)的方式访问,
+	// Ceph RGW及MinIO未配置泛域名时须开启
+	CephPathStyle = true
+	// CephPartSize : 分块上传的块大小(字节, 至少5MB), 超过该大小的对象分块上传, 单个对象最多10000块
+	CephPartSize = 16 << 20
 )
@@ -0,0 +1,17 @@
+package config
+
+const (
+	// CompressionEnable : 是否压缩新写入的存储内容. 关闭后新写入的内容不压缩,
+	// 已压缩的内容仍按压缩记录解压读取
+	CompressionEnable = true
+	// CompressionLevel : zstd压缩等级(1最快, 4压缩率最高)
+	CompressionLevel = 2
+	// CompressionFrameSize : 每个压缩帧的原始长度, range读取以帧为单位解压
+	CompressionFrameSize = 1 << 20
+	// CompressionSampleSize : 判断是否压缩时采样的长度(文件开头)
+	CompressionSampleSize = 128 << 10
+	// CompressionMinRatio : 采样压缩后的长度不超过原长度的该比例时才压缩
+	CompressionMinRatio = 0.8
+	// CompressionMinSize : 小于该长度的文件不压缩
+	CompressionMinSize = 4 << 10
+)
@@ -0,0 +1,18 @@
+package config
+
+const (
+	// EncryptionEnable : 是否加密新写入的存储内容. 关闭后新写入的内容为明文,
+	// 已加密的内容仍按数据密钥记录解密读取
+	EncryptionEnable = true
+	// EncryptionChunkSize : 新数据密钥的加密块长度(明文字节), range读取以块为单位解密
+	EncryptionChunkSize = 64 << 10
+	// KMSLocalKeyFile : 本地KMS的主密钥文件, 不存在时自动生成
+	KMSLocalKeyFile = "./data/kms/master_keys.json"
+	// KeyRewrapInterval : 任务服务以当前主密钥重新加密数据密钥的间隔(秒)
+	KeyRewrapInterval = 600
+	// KeyRewrapBatch : 每次重新加密的数据密钥个数
+	KeyRewrapBatch = 500
+)
+
+// KMSBackend : 主密钥的KMS后端(local)
+var KMSBackend = "local"
@@ -0,0 +1,23 @@
+package config
+
+const (
+	// ErasureRootDir : 纠删码存储路径的prefix, 对象的分片写入ErasureDirs中的各磁盘
+	ErasureRootDir = "erasure/"
+	// ErasureDataShards : 新写入对象的数据分片数
+	ErasureDataShards = 4
+	// ErasureParityShards : 新写入对象的校验分片数, 最多该数量的分片缺失或损坏时仍可读取
+	ErasureParityShards = 2
+	// ErasureChunkSize : 每个分片中一块的长度, 一个条带为数据分片数个块, range读取以条带为单位
+	ErasureChunkSize = 256 << 10
+)
+
+// ErasureDirs : 纠删码存储使用的本地目录, 每个目录应位于不同的磁盘上,
+// 数量不少于数据分片数与校验分片数之和. 更换磁盘后运行ecrebuild重新写入分片
+var ErasureDirs = []string{
+	"./data/disk0/fileserver_ec/",
+	"./data/disk1/fileserver_ec/",
+	"./data/disk2/fileserver_ec/",
+	"./data/disk3/fileserver_ec/",
+	"./data/disk4/fileserver_ec/",
+	"./data/disk5/fileserver_ec/",
+}
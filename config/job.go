@@ -0,0 +1,16 @@
+package config
+
+const (
+	// JobExchangeName : 异步任务使用的交换机, 以任务类型为routing key
+	JobExchangeName = "fileserver.job"
+	// JobQueuePrefix : 任务队列名前缀, 每种任务类型一个队列, 由任务服务启动时声明
+	JobQueuePrefix = "fileserver.job."
+	// JobMaxPriority : 任务的最大优先级, 任务队列声明为该优先级的优先级队列
+	JobMaxPriority = 9
+	// JobQueueLease : 任务入队后未在该时长(秒)内开始执行时重新入队, 用于补偿丢失的消息
+	JobQueueLease = 600
+	// JobTickInterval : 任务服务扫描到期任务的间隔(秒)
+	JobTickInterval = 10
+	// JobListLimit : 每次查询任务的最大条数
+	JobListLimit = 100
+)
@@ -0,0 +1,12 @@
+package config
+
+const (
+	// FileNotifyEnable : 是否在用户文件变更后通过redis发布通知, 供网关向客户端推送实时事件
+	FileNotifyEnable = true
+	// FileNotifyChannel : 用户文件事件通知的redis频道, 消息内容为用户名
+	FileNotifyChannel = "fileserver:fileevent"
+	// FileEventsKeepAlive : 实时事件流的心跳间隔(秒), 同时作为未收到通知时查询新事件的间隔
+	FileEventsKeepAlive = 30
+	// FileEventsRetry : 实时事件流断开后客户端的重连间隔(毫秒)
+	FileEventsRetry = 3000
+)
@@ -0,0 +1,20 @@
+package config
+
+const (
+	// ScrubInterval : 任务服务扫描待检查文件的间隔(秒), 也是检查任务的去重时长
+	ScrubInterval = 3600
+	// ScrubBatch : 每次扫描提交的检查任务数
+	ScrubBatch = 50
+	// ScrubAfterDays : 同一文件两次完整性检查的最小间隔天数
+	ScrubAfterDays = 30
+	// ScrubBytesPerSecond : 任务服务中所有检查任务合计的读取速率上限(字节/秒), 0表示不限
+	ScrubBytesPerSecond = 20 << 20
+	// ScrubTrustOSSChecksum : 是否信任OSS记录的CRC64. 开启后已完整检查过的OSS副本只比较CRC64,
+	// 不再读取内容(节省流量费用); 归档的OSS副本无论是否开启都只比较CRC64
+	ScrubTrustOSSChecksum = false
+	// ScrubQuarantineDir : 内容损坏的本地文件移动到的目录
+	ScrubQuarantineDir = "./data/fileserver_quarantine/"
+)
+
+// ScrubAlertURL : 发现副本损坏时POST告警(JSON)的地址, 为空时只记录日志
+var ScrubAlertURL = ""
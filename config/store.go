@@ -5,14 +5,49 @@ import "github.com/cloud/common"
 const (
 	// TempLocalRootDir : 文件块在本地的临时存储路径
 	TempLocalRootDir = "./data/fileserver_tmp/"
-	// MergeLocalRootDir : 文件在本地的存储路径(普通上传和分块上传)
+	// MergeLocalRootDir : 文件在本地的存储路径(普通上传和分块上传), 文件位于按sha1分级的子目录下(见store/layout)
 	MergeLocalRootDir = "./data/fileserver_merge/"
-	// ChunkLocalRootDir : 文件块在本地的存储路径
+	// ChunkLocalRootDir : 文件块在本地的存储路径, 每个上传会话一个按uploadID分级的目录
 	ChunkLocalRootDir = "./data/fileserver_chunk/"
 	// CephRootDir : Ceph存储路径的prefix
 	CephRootDir = "/ceph"
+	// CephBucket : 文件在Ceph中的bucket
+	CephBucket = "userfile"
 	// OSSRootDir : OSS存储路径的prefix
 	OSSRootDir = "oss/"
-	// CurrentStoreType : 当前的存储类型
-	CurrentStoreType = common.StoreOSS
+	// DefaultStoragePolicy : 没有匹配的规则时使用的存储策略
+	DefaultStoragePolicy = "oss"
+	// RepairWindow : 读取失败后提交修复任务的去重时长(秒), 同一文件在该时长内只提交一次
+	RepairWindow = 600
+	// LocalTempGracePeriod : 服务启动时删除本地存储中修改时间早于该时长(秒)的临时文件(写入中断残留),
+	// 较新的可能仍在被其他服务写入
+	LocalTempGracePeriod = 3600
 )
+
+// ReadPreference : 读取文件时各存储的优先顺序, 靠前的优先, 未列出的排在最后
+var ReadPreference = []common.StoreType{common.StoreLocal, common.StoreErasure, common.StoreCeph, common.StoreOSS}
+
+// StoragePolicies : 存储策略名 -> 文件上传后转移到的存储. 第一个为主存储(文件表中记录的位置),
+// 其余为副本; 为空表示只保存在本地
+var StoragePolicies = map[string][]common.StoreType{
+	"local":    {},
+	"ceph":     {common.StoreCeph},
+	"oss":      {common.StoreOSS},
+	"ceph+oss": {common.StoreCeph, common.StoreOSS},
+	"erasure":  {common.StoreErasure},
+}
+
+// StorageRule : 按用户、目录及文件大小选择存储策略, 为空(0)的条件不作限制
+type StorageRule struct {
+	UserName string
+	// Folder : 用户文件名的前缀, 如"photos/"
+	Folder string
+	// MinSize, MaxSize : 文件大小范围[MinSize, MaxSize)
+	MinSize int64
+	MaxSize int64
+	Policy  string
+}
+
+// StorageRules : 按顺序匹配, 第一个匹配的规则生效,
+// 如 {Folder: "backup/", Policy: "ceph+oss"}, {MinSize: 1 << 30, Policy: "oss"}
+var StorageRules = []StorageRule{}
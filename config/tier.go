@@ -0,0 +1,21 @@
+package config
+
+import "github.com/cloud/common"
+
+const (
+	// TierColdAfterDays : 文件超过该天数未访问时降级为低频访问(OSS IA), 0表示不降级
+	TierColdAfterDays = 30
+	// TierArchiveAfterDays : 文件超过该天数未访问时降级为归档(OSS Archive), 0表示不归档
+	TierArchiveAfterDays = 180
+	// TierScanInterval : 任务服务扫描待降级文件的间隔(秒), 也是降级及升级任务的去重时长
+	TierScanInterval = 3600
+	// TierScanBatch : 每次扫描每个层级提交的降级任务数
+	TierScanBatch = 100
+	// TierRestoreDays : 归档文件解冻后可读取的天数
+	TierRestoreDays = 1
+	// TierHotStore : 低频及归档文件被访问后升级到的存储
+	TierHotStore = common.StoreLocal
+)
+
+// TierCapacity : 各层级的容量(字节), 用于容量报告, 未配置或为0表示不限
+var TierCapacity = map[common.StorageTier]int64{}
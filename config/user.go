@@ -2,4 +2,16 @@ package config
 
 const (
 	Password_salt = "*#890"
+	// AccessKeyLimit : 每个用户最多可持有的S3访问密钥数量
+	AccessKeyLimit = 5
+	// SSHKeyLimit : 每个用户最多可添加的SSH公钥数量
+	SSHKeyLimit = 10
+	// FileListLimit : 分页获取用户文件时每页的最大文件数
+	FileListLimit = 1000
+	// FileChangesLimit : 每次获取用户文件变更事件的最大条数
+	FileChangesLimit = 1000
+	// FileChangesMaxWait : 长轮询等待新变更的最长时间(秒)
+	FileChangesMaxWait = 60
+	// FileChangesPollInterval : 长轮询期间查询新变更的间隔(毫秒)
+	FileChangesPollInterval = 1000
 )
@@ -0,0 +1,16 @@
+package config
+
+const (
+	// WebhookEnable : 是否在用户文件变更后通知webhook服务
+	WebhookEnable = true
+	// WebhookExchangeName : 用户文件事件通知使用的交换机
+	WebhookExchangeName = "fileserver.webhook"
+	// WebhookEventQueueName : webhook服务监听的事件通知queue, 由webhook服务启动时声明并绑定到WebhookExchangeName
+	WebhookEventQueueName = "fileserver.webhook.event"
+	// WebhookEventRoutingKey : 事件通知的routing key
+	WebhookEventRoutingKey = "event"
+	// WebhookLimit : 每个用户最多可注册的webhook数量
+	WebhookLimit = 10
+	// WebhookDeliveriesLimit : 每次查询投递记录的最大条数
+	WebhookDeliveriesLimit = 100
+)
@@ -46,13 +46,219 @@ CREATE TABLE `tbl_user_file` (
   `file_sha1` varchar(64) NOT NULL DEFAULT '' COMMENT '文件hash',
   `file_size` bigint(20) DEFAULT '0' COMMENT '文件大小',
   `file_name` varchar(256) NOT NULL DEFAULT '' COMMENT '文件名',
+  `etag` varchar(48) NOT NULL DEFAULT '' COMMENT 'S3 ETag(不含引号), 不是通过S3网关上传的文件为空',
   `upload_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '上传时间',
   `last_update` datetime DEFAULT CURRENT_TIMESTAMP
           ON UPDATE CURRENT_TIMESTAMP COMMENT '最后修改时间',
   `status` int(11) NOT NULL DEFAULT '0' COMMENT '文件状态(0正常1已删除2禁用)',
-  UNIQUE KEY `idx_user_file` (`user_name`, `file_sha1`),
+  UNIQUE KEY `idx_user_file` (`user_name`, `file_name`),
+  KEY `idx_user_hash` (`user_name`, `file_sha1`),
   KEY `idx_status` (`status`),
   KEY `idx_user_id` (`user_name`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+-- 文件名作为用户目录树(及S3对象key)的唯一标识, 已部署的库需执行:
+-- ALTER TABLE `tbl_user_file` DROP INDEX `idx_user_file`,
+--   ADD UNIQUE KEY `idx_user_file` (`user_name`, `file_name`),
+--   ADD KEY `idx_user_hash` (`user_name`, `file_sha1`);
+-- S3对象的ETag, 已部署的库需执行:
+-- ALTER TABLE `tbl_user_file`
+--   ADD COLUMN `etag` varchar(48) NOT NULL DEFAULT '' COMMENT 'S3 ETag(不含引号), 不是通过S3网关上传的文件为空' AFTER `file_name`;
 
+CREATE TABLE `tbl_user_access_key` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `user_name` varchar(64) NOT NULL DEFAULT '' COMMENT '用户名',
+  `access_key` char(20) NOT NULL DEFAULT '' COMMENT 'S3 Access Key ID',
+  `secret_key` char(40) NOT NULL DEFAULT '' COMMENT 'S3 Secret Access Key',
+  `create_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `status` int(11) NOT NULL DEFAULT '1' COMMENT '状态(1启用2已删除)',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_access_key` (`access_key`),
+  KEY `idx_username` (`user_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `tbl_user_ssh_key` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `user_name` varchar(64) NOT NULL DEFAULT '' COMMENT '用户名',
+  `key_name` varchar(128) NOT NULL DEFAULT '' COMMENT '公钥备注(authorized_keys中的comment)',
+  `fingerprint` varchar(64) NOT NULL DEFAULT '' COMMENT '公钥指纹(SHA256)',
+  `public_key` text NOT NULL COMMENT '公钥(authorized_keys格式)',
+  `create_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `status` int(11) NOT NULL DEFAULT '1' COMMENT '状态(1启用2已删除)',
+  PRIMARY KEY (`id`),
+  KEY `idx_fingerprint` (`fingerprint`),
+  KEY `idx_username` (`user_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
 
+CREATE TABLE `tbl_user_file_event` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT COMMENT '事件序号, 即变更游标',
+  `user_name` varchar(64) NOT NULL DEFAULT '' COMMENT '用户名',
+  `event` varchar(16) NOT NULL DEFAULT '' COMMENT '事件类型(upload/rename/move/delete/restore/share/transfer)',
+  `file_name` varchar(256) NOT NULL DEFAULT '' COMMENT '文件名(重命名/移动后的新文件名)',
+  `old_name` varchar(256) NOT NULL DEFAULT '' COMMENT '重命名/移动前的文件名',
+  `file_sha1` varchar(64) NOT NULL DEFAULT '' COMMENT '文件hash',
+  `file_size` bigint(20) DEFAULT '0' COMMENT '文件大小',
+  `create_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '事件时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_name`, `id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `tbl_user_webhook` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `user_name` varchar(64) NOT NULL DEFAULT '' COMMENT '用户名, 为空表示接收所有用户事件的全局webhook(由管理员直接写入)',
+  `url` varchar(1024) NOT NULL DEFAULT '' COMMENT '接收事件的URL',
+  `secret` varchar(64) NOT NULL DEFAULT '' COMMENT '签名密钥',
+  `events` varchar(256) NOT NULL DEFAULT '' COMMENT '订阅的事件类型(逗号分隔, 为空表示全部)',
+  `event_cursor` bigint(20) NOT NULL DEFAULT '0' COMMENT '已生成投递记录的最后一个事件ID',
+  `create_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `status` int(11) NOT NULL DEFAULT '1' COMMENT '状态(1启用2已删除)',
+  PRIMARY KEY (`id`),
+  KEY `idx_username` (`user_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+-- 全局webhook的event_cursor应设为当前最大的事件ID, 否则会投递全部历史事件:
+-- INSERT INTO tbl_user_webhook (`user_name`,`url`,`secret`,`event_cursor`)
+--   SELECT '', 'https://example.com/hook', '<secret>', ifnull(max(id),0) FROM tbl_user_file_event;
+
+CREATE TABLE `tbl_webhook_delivery` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `hook_id` int(11) NOT NULL COMMENT 'webhook ID',
+  `event_id` bigint(20) NOT NULL COMMENT '事件ID',
+  `user_name` varchar(64) NOT NULL DEFAULT '' COMMENT 'webhook所属用户名',
+  `event` varchar(16) NOT NULL DEFAULT '' COMMENT '事件类型',
+  `payload` text NOT NULL COMMENT '投递的请求体',
+  `status` int(11) NOT NULL DEFAULT '0' COMMENT '状态(0待投递1成功2失败)',
+  `attempts` int(11) NOT NULL DEFAULT '0' COMMENT '已投递次数',
+  `response_code` int(11) NOT NULL DEFAULT '0' COMMENT '最近一次投递的HTTP状态码',
+  `last_error` varchar(256) NOT NULL DEFAULT '' COMMENT '最近一次投递的错误信息',
+  `next_retry_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '下次投递时间',
+  `create_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_at` datetime DEFAULT CURRENT_TIMESTAMP
+          ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_hook_event` (`hook_id`, `event_id`),
+  KEY `idx_status_retry` (`status`, `next_retry_at`),
+  KEY `idx_username` (`user_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建异步任务表
+CREATE TABLE `tbl_job` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `job_type` varchar(64) NOT NULL COMMENT '任务类型',
+  `idem_key` varchar(128) DEFAULT NULL COMMENT '幂等键, 同一类型下唯一, 为NULL时不去重',
+  `payload` text NOT NULL COMMENT '任务参数(JSON)',
+  `priority` tinyint(4) NOT NULL DEFAULT '0' COMMENT '优先级(0-9, 越大越优先)',
+  `status` int(11) NOT NULL DEFAULT '0' COMMENT '状态(0待执行1执行中2成功3失败)',
+  `attempts` int(11) NOT NULL DEFAULT '0' COMMENT '已执行次数',
+  `max_attempts` int(11) NOT NULL DEFAULT '1' COMMENT '最多执行次数',
+  `last_error` varchar(256) NOT NULL DEFAULT '' COMMENT '最近一次执行的错误信息',
+  `next_run_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '待执行时为(重新)入队时间, 执行中为租约到期时间',
+  `create_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_at` datetime DEFAULT CURRENT_TIMESTAMP
+          ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  `finish_at` datetime DEFAULT NULL COMMENT '完成时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_type_key` (`job_type`, `idem_key`),
+  KEY `idx_status_next` (`status`, `next_run_at`),
+  KEY `idx_type_status` (`job_type`, `status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建文件存储位置表, 文件转移到的每个存储(主存储及副本)一行
+CREATE TABLE `tbl_file_location` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `file_sha1` char(40) NOT NULL DEFAULT '' COMMENT '文件hash',
+  `store_type` int(11) NOT NULL DEFAULT '0' COMMENT '存储类型(2 Ceph, 3 OSS)',
+  `location` varchar(1024) NOT NULL DEFAULT '' COMMENT '在该存储中的位置',
+  `status` int(11) NOT NULL DEFAULT '1' COMMENT '副本状态(1可用, 2缺失待修复, 3损坏已隔离)',
+  `verified_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '最后确认可读取的时间',
+  `create_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_at` datetime DEFAULT CURRENT_TIMESTAMP
+          ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_file_store` (`file_sha1`, `store_type`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+-- 副本状态及确认时间, 已部署的库需执行:
+-- ALTER TABLE `tbl_file_location`
+--   ADD COLUMN `status` int(11) NOT NULL DEFAULT '1' COMMENT '副本状态(1可用, 2缺失待修复)' AFTER `location`,
+--   ADD COLUMN `verified_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '最后确认可读取的时间' AFTER `status`;
+-- 之前已转移到Ceph/OSS的文件补充副本记录:
+-- INSERT IGNORE INTO `tbl_file_location` (`file_sha1`, `store_type`, `location`)
+--   SELECT `file_sha1`, IF(`file_addr` LIKE '/ceph%', 2, 3), `file_addr` FROM `tbl_file`
+--   WHERE `file_addr` LIKE '/ceph%' OR `file_addr` LIKE 'oss/%';
+
+-- 创建文件存储层级表, 记录文件的访问情况; 没有记录的文件为热存储, 最后访问时间为上传时间
+CREATE TABLE `tbl_file_tier` (
+  `file_sha1` char(40) NOT NULL DEFAULT '' COMMENT '文件hash',
+  `tier` int(11) NOT NULL DEFAULT '1' COMMENT '存储层级(1热存储, 2低频访问, 3归档)',
+  `access_count` bigint(20) NOT NULL DEFAULT '0' COMMENT '访问次数',
+  `last_access_at` datetime DEFAULT NULL COMMENT '最后访问时间',
+  `update_at` datetime DEFAULT CURRENT_TIMESTAMP
+          ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`file_sha1`),
+  KEY `idx_tier_access` (`tier`, `last_access_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建文件完整性检查表, 文件的每个位置(文件表中的位置及各副本)一行, 记录最近一次检查的结果
+CREATE TABLE `tbl_file_scrub` (
+  `file_sha1` char(40) NOT NULL DEFAULT '' COMMENT '文件hash',
+  `store_type` int(11) NOT NULL DEFAULT '0' COMMENT '存储类型(1本地, 2 Ceph, 3 OSS)',
+  `location` varchar(1024) NOT NULL DEFAULT '' COMMENT '检查的位置',
+  `result` int(11) NOT NULL DEFAULT '0' COMMENT '检查结果(1一致, 2不一致, 3读取失败, 4跳过)',
+  `checksum` char(40) NOT NULL DEFAULT '' COMMENT '读取到的内容的sha1',
+  `crc64` varchar(20) NOT NULL DEFAULT '' COMMENT '内容一致时的CRC64(ECMA), 用于与OSS记录的值比较',
+  `scrub_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '检查时间',
+  PRIMARY KEY (`file_sha1`, `store_type`),
+  KEY `idx_scrub_at` (`scrub_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建数据密钥表, 文件在每个存储中加密的位置一行; 没有记录的位置内容未加密
+CREATE TABLE `tbl_file_key` (
+  `file_sha1` char(40) NOT NULL DEFAULT '' COMMENT '文件hash',
+  `store_type` int(11) NOT NULL DEFAULT '0' COMMENT '存储类型(1本地, 2 Ceph, 3 OSS)',
+  `key_id` varchar(64) NOT NULL DEFAULT '' COMMENT '加密数据密钥的主密钥ID',
+  `wrapped_key` varchar(255) NOT NULL DEFAULT '' COMMENT '主密钥加密后的数据密钥(base64)',
+  `chunk_size` int(11) NOT NULL DEFAULT '0' COMMENT '加密块的明文长度',
+  `create_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_at` datetime DEFAULT CURRENT_TIMESTAMP
+          ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`file_sha1`, `store_type`),
+  KEY `idx_key_id` (`key_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建文件压缩表, 有记录表示文件在该存储中的位置以seekable zstd压缩(压缩后再加密),
+-- file_size(tbl_file)及配额按logical_size计算
+CREATE TABLE `tbl_file_compression` (
+  `file_sha1` char(40) NOT NULL DEFAULT '' COMMENT '文件hash',
+  `store_type` int(11) NOT NULL DEFAULT '0' COMMENT '存储类型(1本地, 2 Ceph, 3 OSS)',
+  `codec` varchar(16) NOT NULL DEFAULT '' COMMENT '压缩算法(zstd)',
+  `level` int(11) NOT NULL DEFAULT '0' COMMENT '压缩等级',
+  `frame_size` int(11) NOT NULL DEFAULT '0' COMMENT '每个压缩帧的原始长度',
+  `logical_size` bigint(20) NOT NULL DEFAULT '0' COMMENT '原始长度',
+  `stored_size` bigint(20) NOT NULL DEFAULT '0' COMMENT '压缩后的长度(含seek table, 不含加密开销)',
+  `create_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_at` datetime DEFAULT CURRENT_TIMESTAMP
+          ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`file_sha1`, `store_type`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建加密目录密钥对表, 用户的X25519密钥对, 私钥由用户口令派生的密钥加密, 服务端无法解密
+CREATE TABLE `tbl_vault_keypair` (
+  `user_name` varchar(64) NOT NULL DEFAULT '' COMMENT '用户名',
+  `public_key` varchar(64) NOT NULL DEFAULT '' COMMENT '公钥(base64)',
+  `secret_key` varchar(255) NOT NULL DEFAULT '' COMMENT '口令派生密钥加密后的私钥(base64)',
+  `kdf_salt` varchar(64) NOT NULL DEFAULT '' COMMENT '口令派生密钥的盐(base64)',
+  `kdf_params` varchar(64) NOT NULL DEFAULT '' COMMENT '口令派生密钥的算法及参数',
+  `create_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_at` datetime DEFAULT CURRENT_TIMESTAMP
+          ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间(修改口令)',
+  PRIMARY KEY (`user_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 创建加密目录表, 每个可访问目录的用户一行(包括所有者), 目录密钥以该用户的公钥加密
+CREATE TABLE `tbl_vault` (
+  `owner` varchar(64) NOT NULL DEFAULT '' COMMENT '目录所有者',
+  `folder` varchar(256) NOT NULL DEFAULT '' COMMENT '目录(以/结尾)',
+  `user_name` varchar(64) NOT NULL DEFAULT '' COMMENT '可访问目录的用户',
+  `wrapped_key` varchar(255) NOT NULL DEFAULT '' COMMENT '以该用户公钥加密的目录密钥(base64)',
+  `create_at` datetime DEFAULT CURRENT_TIMESTAMP COMMENT '创建(分享)时间',
+  PRIMARY KEY (`owner`, `folder`, `user_name`),
+  KEY `idx_username` (`user_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
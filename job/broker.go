@@ -0,0 +1,65 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/cloud/config"
+	"github.com/cloud/mq"
+)
+
+// reconnectInterval : 队列连接断开后重新监听的间隔
+const reconnectInterval = 3 * time.Second
+
+// QueueName : 任务类型对应的队列名
+func QueueName(jobType string) string {
+	return config.JobQueuePrefix + jobType
+}
+
+// BrokerPublisher : 以任务类型为routing key将任务消息发布到broker
+func BrokerPublisher(b mq.Broker) Publisher {
+	return func(j *Job) bool {
+		msg, _ := json.Marshal(Message{ID: j.ID, Type: j.Type})
+		if err := b.Publish(config.JobExchangeName, j.Type, msg, uint8(j.Priority)); err != nil {
+			log.Println(err.Error())
+			return false
+		}
+		return true
+	}
+}
+
+// Consume : 声明并监听worker已注册的各任务类型的队列, 每个类型的并发数为其Concurrency.
+// 连接断开后重新监听, 直到ctx结束
+func Consume(ctx context.Context, b mq.Broker, w *Worker) {
+	wg := sync.WaitGroup{}
+	for _, t := range w.Types() {
+		def, _ := Lookup(t)
+		wg.Add(1)
+		go func(def *Definition) {
+			defer wg.Done()
+			queue := QueueName(def.Name)
+			for {
+				err := b.DeclareQueue(config.JobExchangeName, queue, def.Name, config.JobMaxPriority)
+				if err == nil {
+					err = b.Consume(ctx, queue, "job_"+def.Name, def.Concurrency, mq.AckHandler(w.HandleMessage))
+				}
+				if ctx.Err() != nil || err == mq.ErrClosed {
+					return
+				}
+				if err != nil {
+					log.Println(err.Error())
+				}
+				log.Printf("job queue %s disconnected, reconnecting...\n", queue)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(reconnectInterval):
+				}
+			}
+		}(def)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,97 @@
+package job
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+)
+
+// errPayloadType : 提交的参数类型与定义不一致
+var errPayloadType = errors.New("payload type does not match job definition")
+
+// EnqueueOptions : 提交任务的选项
+type EnqueueOptions struct {
+	// IdempotencyKey : 同一类型下相同键的任务只创建一次, 重复提交时返回已有的任务.
+	// 已有的任务已失败时重新执行
+	IdempotencyKey string
+	// Priority : 覆盖类型的默认优先级, 为0时使用默认优先级
+	Priority int
+}
+
+// Client : 提交任务
+type Client struct {
+	Store   Store
+	Publish Publisher
+	// QueueLease : 任务入队后未在该时长内开始执行时由Tick重新入队
+	QueueLease time.Duration
+	// Now : 测试时可替换
+	Now func() time.Time
+}
+
+// NewClient : 创建提交任务的Client
+func NewClient(store Store, publish Publisher, queueLease time.Duration) *Client {
+	return &Client{
+		Store:      store,
+		Publish:    publish,
+		QueueLease: queueLease,
+		Now:        time.Now,
+	}
+}
+
+// Enqueue : 校验参数后保存并发布任务. payload为任务类型的参数类型(或其指针), 也可以是JSON(json.RawMessage).
+// 发布失败时任务已保存, 会在QueueLease之后重新入队
+func (c *Client) Enqueue(jobType string, payload interface{}, opts EnqueueOptions) (*Job, error) {
+	def, ok := Lookup(jobType)
+	if !ok {
+		return nil, ErrUnknownType
+	}
+	if opts.Priority < 0 || opts.Priority > MaxPriority {
+		return nil, fmt.Errorf("invalid priority %d", opts.Priority)
+	}
+
+	// 1. 按定义校验参数
+	data, ok := payload.(json.RawMessage)
+	if !ok {
+		t := reflect.TypeOf(payload)
+		if t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t != def.schema.typ {
+			return nil, errPayloadType
+		}
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := def.Decode(data); err != nil {
+		return nil, fmt.Errorf("invalid %s job payload: %s", jobType, err.Error())
+	}
+
+	// 2. 保存, 幂等键已存在且任务未失败时返回已有的任务
+	priority := def.Priority
+	if opts.Priority > 0 {
+		priority = opts.Priority
+	}
+	job, created, err := c.Store.Create(&Job{
+		Type:        jobType,
+		IdemKey:     opts.IdempotencyKey,
+		Payload:     string(data),
+		Priority:    priority,
+		Status:      StatusPending,
+		MaxAttempts: def.MaxAttempts,
+		NextRunAt:   c.Now().Add(c.QueueLease),
+	})
+	if err != nil || !created {
+		return job, err
+	}
+
+	// 3. 发布
+	if !c.Publish(job) {
+		log.Printf("publish job %d failed, will be requeued later\n", job.ID)
+	}
+	return job, nil
+}
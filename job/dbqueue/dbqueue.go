@@ -0,0 +1,104 @@
+// Package dbqueue : 通过dbproxy保存任务, 通过消息队列投递任务消息
+package dbqueue
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloud/config"
+	"github.com/cloud/job"
+	"github.com/cloud/mq"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/service/dbproxy/orm"
+)
+
+// QueueLease : 入队后未开始执行的任务重新入队的时长
+const QueueLease = config.JobQueueLease * time.Second
+
+// NewClient : 创建通过dbproxy保存任务、通过broker发布任务消息的Client
+func NewClient(b mq.Broker) *job.Client {
+	return job.NewClient(Store{}, job.BrokerPublisher(b), QueueLease)
+}
+
+// Store : 通过dbproxy读写任务表
+type Store struct{}
+
+// toJob : 转换任务记录
+func toJob(t orm.TableJob) *job.Job {
+	return &job.Job{
+		ID:          t.ID,
+		Type:        t.Type,
+		IdemKey:     t.IdemKey,
+		Payload:     t.Payload,
+		Priority:    t.Priority,
+		Status:      t.Status,
+		Attempts:    t.Attempts,
+		MaxAttempts: t.MaxAttempts,
+		LastError:   t.LastError,
+		NextRunAt:   time.Unix(t.NextRunAt, 0),
+		CreateAt:    t.CreateAt,
+		UpdateAt:    t.UpdateAt,
+		FinishAt:    t.FinishAt,
+	}
+}
+
+// execResult : 将dbproxy的执行结果转换为错误
+func execResult(res *orm.ExecResult, err error) (*orm.ExecResult, error) {
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, errors.New("dbproxy: empty result")
+	}
+	if !res.Suc {
+		return nil, errors.New("dbproxy: " + res.Msg)
+	}
+	return res, nil
+}
+
+// Create : 实现job.Store
+func (Store) Create(j *job.Job) (*job.Job, bool, error) {
+	saved, created, err := dbcli.CreateJob(j.Type, j.IdemKey, j.Payload, j.Priority,
+		j.MaxAttempts, j.NextRunAt.Unix())
+	if err != nil {
+		return nil, false, err
+	}
+	return toJob(saved), created, nil
+}
+
+// Get : 实现job.Store
+func (Store) Get(id int64) (*job.Job, error) {
+	res, err := execResult(dbcli.GetJob(id))
+	if err != nil {
+		return nil, err
+	}
+	return toJob(dbcli.ToTableJob(res.Data)), nil
+}
+
+// Transit : 实现job.Store
+func (Store) Transit(j *job.Job, status int, nextRunAt time.Time, lastError string) (bool, error) {
+	updated, err := dbcli.TransitJob(j.ID, j.Status, j.NextRunAt.Unix(), status, nextRunAt.Unix(), lastError)
+	if err != nil || !updated {
+		return false, err
+	}
+	if status == job.StatusRunning {
+		j.Attempts++
+	}
+	j.Status = status
+	j.NextRunAt = nextRunAt
+	j.LastError = lastError
+	return true, nil
+}
+
+// Due : 实现job.Store
+func (Store) Due(now time.Time, limit int) ([]*job.Job, error) {
+	res, err := execResult(dbcli.ListDueJobs(now.Unix(), limit))
+	if err != nil {
+		return nil, err
+	}
+	jobs := []*job.Job{}
+	for _, t := range dbcli.ToTableJobs(res.Data) {
+		jobs = append(jobs, toJob(t))
+	}
+	return jobs, nil
+}
@@ -0,0 +1,156 @@
+// Package job : 基于消息队列的异步任务框架.
+//
+// 任务类型通过Define注册名称、参数类型及执行选项, 提交方与执行方共享同一定义.
+// 任务先持久化再按类型发布到各自的队列(以优先级投递), 执行方认领后执行, 失败时按退避计划重试,
+// 状态保存在任务表中可随时查询. 发布丢失或执行进程退出的任务由Tick在租约到期后重新入队.
+// 同一类型下幂等键相同的任务只执行一次, 已失败(执行次数用完)的任务可以以相同的幂等键重新提交
+package job
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 任务状态
+const (
+	StatusPending   = 0
+	StatusRunning   = 1
+	StatusSucceeded = 2
+	StatusFailed    = 3
+)
+
+// MaxPriority : 任务的最大优先级, 与config.JobMaxPriority一致
+const MaxPriority = 9
+
+// ErrUnknownType : 任务类型未定义
+var ErrUnknownType = errors.New("unknown job type")
+
+// Job : 任务记录
+type Job struct {
+	ID          int64
+	Type        string
+	IdemKey     string
+	Payload     string
+	Priority    int
+	Status      int
+	Attempts    int
+	MaxAttempts int
+	LastError   string
+	// NextRunAt : 待执行任务的(重新)入队时间, 执行中任务的租约到期时间
+	NextRunAt time.Time
+	CreateAt  string
+	UpdateAt  string
+	FinishAt  string
+}
+
+// Message : 队列中的任务消息, 执行方按ID读取任务
+type Message struct {
+	ID   int64
+	Type string
+}
+
+// Handler : 任务的执行函数, payload为按任务类型的参数类型解码后的指针
+type Handler func(ctx context.Context, payload interface{}) error
+
+// Options : 任务类型的执行选项
+type Options struct {
+	// Priority : 默认优先级(0-MaxPriority), 越大越优先
+	Priority int
+	// Concurrency : 每个执行实例同时执行该类型任务的上限, 默认为1
+	Concurrency int
+	// MaxAttempts : 最多执行次数(含第一次), 默认为1
+	MaxAttempts int
+	// Timeout : 单次执行的超时时间, 默认为10分钟
+	Timeout time.Duration
+	// Backoff : 第n次执行失败后等待Backoff[n-1]再重试, 不足时使用最后一项, 默认为1分钟
+	Backoff []time.Duration
+}
+
+// Definition : 已注册的任务类型
+type Definition struct {
+	Name string
+	Options
+	schema *schema
+}
+
+// Backoff : 第attempts次执行失败后的重试等待时间
+func (d *Definition) Backoff(attempts int) time.Duration {
+	if attempts > len(d.Options.Backoff) {
+		attempts = len(d.Options.Backoff)
+	}
+	return d.Options.Backoff[attempts-1]
+}
+
+var (
+	defMu       sync.RWMutex
+	definitions = map[string]*Definition{}
+)
+
+// Define : 注册任务类型, payload为参数类型的零值(结构体), 提交时按其校验参数.
+// 名称重复或参数类型不是结构体时panic, 应在init中调用
+func Define(name string, payload interface{}, opts Options) *Definition {
+	if opts.Priority < 0 || opts.Priority > MaxPriority {
+		panic("job: invalid priority for " + name)
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 1
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Minute
+	}
+	if len(opts.Backoff) == 0 {
+		opts.Backoff = []time.Duration{time.Minute}
+	}
+	def := &Definition{Name: name, Options: opts, schema: newSchema(payload)}
+
+	defMu.Lock()
+	defer defMu.Unlock()
+	if _, ok := definitions[name]; ok {
+		panic("job: duplicate job type " + name)
+	}
+	definitions[name] = def
+	return def
+}
+
+// Lookup : 查询已注册的任务类型
+func Lookup(name string) (*Definition, bool) {
+	defMu.RLock()
+	defer defMu.RUnlock()
+	def, ok := definitions[name]
+	return def, ok
+}
+
+// Definitions : 按名称排序的全部任务类型
+func Definitions() []*Definition {
+	defMu.RLock()
+	defer defMu.RUnlock()
+	defs := []*Definition{}
+	for _, def := range definitions {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs
+}
+
+// Store : 任务的持久化操作
+type Store interface {
+	// Create : 保存新任务并返回; 幂等键已存在时不保存, 返回已有的任务及false.
+	// 已有的任务已失败时以新任务的参数重置该任务(保留ID), 返回重置后的任务及true
+	Create(job *Job) (*Job, bool, error)
+	// Get : 按ID查询任务
+	Get(id int64) (*Job, error)
+	// Transit : 仅当任务仍为job.Status及job.NextRunAt时更新状态, 成功时同步更新job.
+	// 转为执行中时执行次数加1
+	Transit(job *Job, status int, nextRunAt time.Time, lastError string) (bool, error)
+	// Due : 到期的任务: 待执行且到了入队时间的, 及执行中但租约已过期的
+	Due(now time.Time, limit int) ([]*Job, error)
+}
+
+// Publisher : 将任务发布到其类型的队列
+type Publisher func(job *Job) bool
@@ -0,0 +1,102 @@
+package job
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MemoryStore : 内存中的Store实现, 用于测试及不需要持久化任务的单进程部署
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs []*Job
+}
+
+// NewMemoryStore : 创建空的内存任务表
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Insert : 直接保存任务并设置其ID, 不检查幂等键, 可用于构造异常状态的任务
+func (s *MemoryStore) Insert(j *Job) *Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j.ID = int64(len(s.jobs) + 1)
+	saved := *j
+	s.jobs = append(s.jobs, &saved)
+	return j
+}
+
+// Create : 实现Store
+func (s *MemoryStore) Create(j *Job) (*Job, bool, error) {
+	s.mu.Lock()
+	if j.IdemKey != "" {
+		for _, old := range s.jobs {
+			if old.Type == j.Type && old.IdemKey == j.IdemKey {
+				if old.Status == StatusFailed {
+					id := old.ID
+					*old = *j
+					old.ID = id
+					saved := *old
+					s.mu.Unlock()
+					return &saved, true, nil
+				}
+				saved := *old
+				s.mu.Unlock()
+				return &saved, false, nil
+			}
+		}
+	}
+	s.mu.Unlock()
+	saved := *s.Insert(j)
+	return &saved, true, nil
+}
+
+// Get : 实现Store, 返回任务的副本
+func (s *MemoryStore) Get(id int64) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id < 1 || id > int64(len(s.jobs)) {
+		return nil, fmt.Errorf("job %d not found", id)
+	}
+	saved := *s.jobs[id-1]
+	return &saved, nil
+}
+
+// Transit : 实现Store
+func (s *MemoryStore) Transit(j *Job, status int, nextRunAt time.Time, lastError string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if j.ID < 1 || j.ID > int64(len(s.jobs)) {
+		return false, fmt.Errorf("job %d not found", j.ID)
+	}
+	saved := s.jobs[j.ID-1]
+	if saved.Status != j.Status || !saved.NextRunAt.Equal(j.NextRunAt) {
+		return false, nil
+	}
+	if status == StatusRunning {
+		saved.Attempts++
+	}
+	saved.Status = status
+	saved.NextRunAt = nextRunAt
+	saved.LastError = lastError
+	*j = *saved
+	return true, nil
+}
+
+// Due : 实现Store
+func (s *MemoryStore) Due(now time.Time, limit int) ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dues := []*Job{}
+	for _, j := range s.jobs {
+		if (j.Status == StatusPending || j.Status == StatusRunning) && !j.NextRunAt.After(now) {
+			due := *j
+			dues = append(dues, &due)
+			if len(dues) >= limit {
+				break
+			}
+		}
+	}
+	return dues, nil
+}
@@ -0,0 +1,129 @@
+package job
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Validator : 参数类型可实现Validate进行字段之外的校验
+type Validator interface {
+	Validate() error
+}
+
+// Schema : 参数的JSON Schema描述, 用于对外展示任务类型
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+// schema : 任务类型的参数类型
+type schema struct {
+	typ reflect.Type
+	// required : 带有`job:"required"`标签的字段的JSON名称
+	required []string
+}
+
+// newSchema : 解析参数类型, 参数须为结构体
+func newSchema(payload interface{}) *schema {
+	t := reflect.TypeOf(payload)
+	if t == nil || t.Kind() != reflect.Struct {
+		panic("job: payload must be a struct")
+	}
+	s := &schema{typ: t}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if name, ok := jsonName(f); ok && f.Tag.Get("job") == "required" {
+			s.required = append(s.required, name)
+		}
+	}
+	return s
+}
+
+// jsonName : 字段在JSON中的名称, 不参与JSON编码的字段返回false
+func jsonName(f reflect.StructField) (string, bool) {
+	if f.PkgPath != "" {
+		return "", false
+	}
+	tag := strings.Split(f.Tag.Get("json"), ",")[0]
+	if tag == "-" {
+		return "", false
+	}
+	if tag == "" {
+		return f.Name, true
+	}
+	return tag, true
+}
+
+// Decode : 按参数类型严格解码: 不允许未知字段, 必填字段须存在且不为null或空字符串, 并调用Validate
+func (d *Definition) Decode(data []byte) (interface{}, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for _, name := range d.schema.required {
+		if v, ok := fields[name]; !ok || string(v) == "null" || string(v) == `""` {
+			return nil, fmt.Errorf("missing required field %s", name)
+		}
+	}
+
+	payload := reflect.New(d.schema.typ).Interface()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(payload); err != nil {
+		return nil, err
+	}
+	if v, ok := payload.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return payload, nil
+}
+
+// Schema : 参数的JSON Schema
+func (d *Definition) Schema() *Schema {
+	s := typeSchema(d.schema.typ)
+	s.Required = d.schema.required
+	return s
+}
+
+// typeSchema : 生成类型的JSON Schema, 无法描述的类型不限制
+func typeSchema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string"}
+		}
+		return &Schema{Type: "array", Items: typeSchema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		closed := false
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: &closed}
+		for i := 0; i < t.NumField(); i++ {
+			if name, ok := jsonName(t.Field(i)); ok {
+				s.Properties[name] = typeSchema(t.Field(i).Type)
+			}
+		}
+		return s
+	}
+	return &Schema{}
+}
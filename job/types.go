@@ -0,0 +1,130 @@
+package job
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/cloud/common"
+)
+
+// TypeTransfer : 将本地文件转移到Ceph、OSS或纠删码存储, 取代原transfer服务监听的转移队列
+const TypeTransfer = "transfer"
+
+// TransferPayload : TypeTransfer的参数. 按存储策略每个目标存储一个任务,
+// 主存储的任务完成后更新文件表中的存储位置, 副本只记录位置
+type TransferPayload struct {
+	FileHash      string `job:"required"`
+	Location      string `job:"required"`
+	DestLocation  string `job:"required"`
+	DestStoreType common.StoreType
+	// Replica : 是否为副本
+	Replica bool `json:",omitempty"`
+}
+
+// Validate : 只能转移到Ceph、OSS或纠删码存储
+func (p *TransferPayload) Validate() error {
+	switch p.DestStoreType {
+	case common.StoreCeph, common.StoreOSS, common.StoreErasure:
+		return nil
+	}
+	return errors.New("unsupported destination store type " + strconv.Itoa(int(p.DestStoreType)))
+}
+
+// IdempotencyKey : 同一文件到同一存储只转移一次, 重试次数用完失败后可重新提交
+func (p TransferPayload) IdempotencyKey() string {
+	return p.FileHash + ":" + strconv.Itoa(int(p.DestStoreType))
+}
+
+// TypeRepair : 从可用的副本重新写入缺失的副本
+const TypeRepair = "repair"
+
+// RepairPayload : TypeRepair的参数. 修复文件已记录的缺失副本,
+// 指定Policy时还会补齐该存储策略中尚未记录的副本
+type RepairPayload struct {
+	FileHash string `job:"required"`
+	// Policy : 副本应满足的存储策略, 为空时只修复已记录的副本
+	Policy string `json:",omitempty"`
+}
+
+// TypeTier : 将文件降级到低频访问/归档(只保留OSS上的副本), 或将被访问的文件升级回热存储
+const TypeTier = "tier"
+
+// TierPayload : TypeTier的参数
+type TierPayload struct {
+	FileHash string `job:"required"`
+	// Tier : 目标层级
+	Tier common.StorageTier `job:"required"`
+}
+
+// Validate : 目标层级须为已定义的层级
+func (p *TierPayload) Validate() error {
+	if p.Tier < common.TierHot || p.Tier > common.TierArchive {
+		return errors.New("unsupported storage tier " + strconv.Itoa(int(p.Tier)))
+	}
+	return nil
+}
+
+// TypeScrub : 检查文件各位置的内容与sha1是否一致, 隔离损坏的副本并提交修复
+const TypeScrub = "scrub"
+
+// ScrubPayload : TypeScrub的参数
+type ScrubPayload struct {
+	FileHash string `job:"required"`
+}
+
+// TypeWebhook : 将用户文件事件投递到webhook, 投递记录由webhook服务在生成时提交
+const TypeWebhook = "webhook"
+
+// WebhookPayload : TypeWebhook的参数, 投递记录以webhook及事件唯一确定
+type WebhookPayload struct {
+	HookID  int64 `job:"required"`
+	EventID int64 `job:"required"`
+}
+
+// IdempotencyKey : 同一事件到同一webhook只投递一次
+func (p WebhookPayload) IdempotencyKey() string {
+	return strconv.FormatInt(p.HookID, 10) + ":" + strconv.FormatInt(p.EventID, 10)
+}
+
+func init() {
+	Define(TypeTransfer, TransferPayload{}, Options{
+		Priority:    5,
+		Concurrency: 4,
+		MaxAttempts: 5,
+		Timeout:     30 * time.Minute,
+		Backoff:     []time.Duration{time.Minute, 5 * time.Minute, 30 * time.Minute, 2 * time.Hour},
+	})
+	Define(TypeRepair, RepairPayload{}, Options{
+		Priority:    3,
+		Concurrency: 2,
+		MaxAttempts: 5,
+		Timeout:     30 * time.Minute,
+		Backoff:     []time.Duration{time.Minute, 10 * time.Minute, time.Hour, 6 * time.Hour},
+	})
+	// 升级时归档文件解冻需要数分钟到数小时, 解冻完成前任务失败并等待重试
+	Define(TypeTier, TierPayload{}, Options{
+		Priority:    2,
+		Concurrency: 2,
+		MaxAttempts: 10,
+		Timeout:     30 * time.Minute,
+		Backoff:     []time.Duration{5 * time.Minute, 15 * time.Minute, 30 * time.Minute, time.Hour},
+	})
+	// 检查会读取文件的全部内容, 以最低优先级单个执行, 读取速率另由config.ScrubBytesPerSecond限制
+	Define(TypeScrub, ScrubPayload{}, Options{
+		Priority:    1,
+		Concurrency: 1,
+		MaxAttempts: 3,
+		Timeout:     2 * time.Hour,
+		Backoff:     []time.Duration{10 * time.Minute, time.Hour},
+	})
+	// 投递只是一次HTTP请求, 接收方可能长时间不可用, 重试间隔逐步拉长到数小时
+	Define(TypeWebhook, WebhookPayload{}, Options{
+		Priority:    4,
+		Concurrency: 16,
+		MaxAttempts: 7,
+		Timeout:     time.Minute,
+		Backoff: []time.Duration{10 * time.Second, time.Minute, 5 * time.Minute,
+			30 * time.Minute, 2 * time.Hour, 6 * time.Hour},
+	})
+}
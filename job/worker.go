@@ -0,0 +1,188 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// leaseGrace : 执行中任务的租约比执行超时多出的时长, 超时的执行有机会先记录结果
+const leaseGrace = 30 * time.Second
+
+// dueBatchSize : 每次Tick处理的到期任务数
+const dueBatchSize = 100
+
+// maxErrorLen : 记录的错误信息的最大长度
+const maxErrorLen = 256
+
+// errLeaseExpired : 执行中的任务超过租约仍未记录结果(执行进程已退出)
+var errLeaseExpired = errors.New("job lease expired")
+
+// Worker : 执行任务
+type Worker struct {
+	Store   Store
+	Publish Publisher
+	// QueueLease : 重新入队的任务未在该时长内开始执行时再次入队
+	QueueLease time.Duration
+	// Now : 测试时可替换
+	Now func() time.Time
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	sems     map[string]chan struct{}
+}
+
+// NewWorker : 创建Worker
+func NewWorker(store Store, publish Publisher, queueLease time.Duration) *Worker {
+	return &Worker{
+		Store:      store,
+		Publish:    publish,
+		QueueLease: queueLease,
+		Now:        time.Now,
+		handlers:   map[string]Handler{},
+		sems:       map[string]chan struct{}{},
+	}
+}
+
+// Handle : 注册任务类型的执行函数, 同一类型同时执行的任务数不超过其Concurrency.
+// 任务类型未定义时panic
+func (w *Worker) Handle(jobType string, h Handler) {
+	def, ok := Lookup(jobType)
+	if !ok {
+		panic("job: handle undefined job type " + jobType)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers[jobType] = h
+	w.sems[jobType] = make(chan struct{}, def.Concurrency)
+}
+
+// Types : 已注册执行函数的任务类型
+func (w *Worker) Types() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	types := []string{}
+	for t := range w.handlers {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// HandleMessage : 处理队列中的任务消息, 返回false时消息应被拒绝
+func (w *Worker) HandleMessage(msg []byte) bool {
+	m := Message{}
+	if err := json.Unmarshal(msg, &m); err != nil {
+		log.Println(err.Error())
+		return false
+	}
+	if err := w.Process(m.ID); err != nil {
+		log.Printf("job %d: %s\n", m.ID, err.Error())
+		return false
+	}
+	return true
+}
+
+// Process : 认领并执行待执行的任务; 任务已被认领或已完成时直接返回
+func (w *Worker) Process(id int64) error {
+	job, err := w.Store.Get(id)
+	if err != nil {
+		return err
+	}
+	if job.Status != StatusPending {
+		return nil
+	}
+	def, ok := Lookup(job.Type)
+	w.mu.Lock()
+	h, handled := w.handlers[job.Type]
+	sem := w.sems[job.Type]
+	w.mu.Unlock()
+	if !ok || !handled {
+		return fmt.Errorf("no handler for job type %s", job.Type)
+	}
+
+	// 1. 等待该类型的并发额度后认领
+	sem <- struct{}{}
+	defer func() { <-sem }()
+	claimed, err := w.Store.Transit(job, StatusRunning, w.Now().Add(def.Timeout+leaseGrace), job.LastError)
+	if err != nil || !claimed {
+		return err
+	}
+
+	// 2. 执行, 参数无效时不重试
+	payload, err := def.Decode([]byte(job.Payload))
+	if err != nil {
+		return w.finish(def, job, fmt.Errorf("invalid payload: %s", err.Error()), false)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), def.Timeout)
+	defer cancel()
+	return w.finish(def, job, run(ctx, h, payload), true)
+}
+
+// run : 执行任务, 将panic转为错误
+func run(ctx context.Context, h Handler, payload interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("job panic: %v\n%s", r, debug.Stack())
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return h(ctx, payload)
+}
+
+// finish : 记录执行结果, 失败且可重试时按退避计划等待重新入队
+func (w *Worker) finish(def *Definition, job *Job, err error, retry bool) error {
+	now := w.Now()
+	status, next, lastError := StatusSucceeded, now, ""
+	if err != nil {
+		lastError = err.Error()
+		if len(lastError) > maxErrorLen {
+			lastError = lastError[:maxErrorLen]
+		}
+		status = StatusFailed
+		if retry && job.Attempts < job.MaxAttempts {
+			status, next = StatusPending, now.Add(def.Backoff(job.Attempts))
+		}
+	}
+	updated, terr := w.Store.Transit(job, status, next, lastError)
+	if terr != nil {
+		return terr
+	}
+	if !updated {
+		log.Printf("job %d: result discarded, lease expired\n", job.ID)
+	}
+	return nil
+}
+
+// Tick : 重新入队到期的任务: 到了重试时间或发布丢失的待执行任务, 及租约过期的执行中任务, 应定期调用.
+// 多个实例同时调用时每个任务只会被其中一个重新入队
+func (w *Worker) Tick() error {
+	now := w.Now()
+	dues, err := w.Store.Due(now, dueBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, job := range dues {
+		status, next, lastError := StatusPending, now.Add(w.QueueLease), job.LastError
+		if job.Status == StatusRunning {
+			lastError = errLeaseExpired.Error()
+			if job.Attempts >= job.MaxAttempts {
+				status, next = StatusFailed, now
+			}
+		}
+		updated, err := w.Store.Transit(job, status, next, lastError)
+		if err != nil {
+			return err
+		}
+		if updated && status == StatusPending && !w.Publish(job) {
+			log.Printf("publish job %d failed, will be requeued later\n", job.ID)
+		}
+	}
+	return nil
+}
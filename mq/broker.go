@@ -0,0 +1,111 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/cloud/config"
+)
+
+// 消息队列后端, 见config.MQBackend
+const (
+	BackendRabbitMQ = "rabbitmq"
+	BackendRedis    = "redis"
+	BackendMemory   = "memory"
+)
+
+// ErrClosed : Broker已关闭
+var ErrClosed = errors.New("mq: broker closed")
+
+// Delivery : 消费到的一条消息, 处理完成后须调用Ack或Nack之一
+type Delivery interface {
+	Body() []byte
+	// Ack : 确认消息已处理
+	Ack() error
+	// Nack : 拒绝消息, requeue为true时重新入队, 否则丢弃
+	Nack(requeue bool) error
+}
+
+// Handler : 消息处理函数, 返回前须确认或拒绝消息
+type Handler func(d Delivery)
+
+// Broker : 消息队列后端. exchange按routing key将消息路由到绑定的队列(direct),
+// 同一队列的多个消费者竞争消费, 消息在确认前不会丢失
+type Broker interface {
+	// DeclareQueue : 声明exchange及以routingKey绑定到它的队列, maxPriority大于0时按优先级投递.
+	// 消息只投递到发布时已声明的队列
+	DeclareQueue(exchange, queue, routingKey string, maxPriority uint8) error
+	// Publish : 发布消息到exchange with routingKey, 没有绑定的队列时丢弃
+	Publish(exchange, routingKey string, body []byte, priority uint8) error
+	// Consume : 以concurrency个并发消费队列, 未确认的消息最多concurrency条.
+	// 阻塞直到ctx结束(返回nil)、Broker关闭或连接断开
+	Consume(ctx context.Context, queue, consumer string, concurrency int, handler Handler) error
+	// Close : 关闭连接, 进行中的Consume返回
+	Close() error
+}
+
+// NewBroker : 创建指定后端的Broker
+func NewBroker(backend string) (Broker, error) {
+	switch backend {
+	case BackendRabbitMQ:
+		return NewRabbitMQ(config.RabbitURL), nil
+	case BackendRedis:
+		return NewRedisStreams(nil, config.MQRedisClaimIdle*time.Second), nil
+	case BackendMemory:
+		return NewMemory(), nil
+	}
+	return nil, errors.New("mq: unknown backend " + backend)
+}
+
+var (
+	defaultMu     sync.Mutex
+	defaultBroker Broker
+)
+
+// Default : 按config.MQBackend创建的默认Broker, 首次使用时创建(不立即连接)
+func Default() Broker {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultBroker == nil {
+		b, err := NewBroker(config.MQBackend)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		defaultBroker = b
+	}
+	return defaultBroker
+}
+
+// SetDefault : 替换默认Broker, 应在使用Default之前调用(如测试时使用内存队列)
+func SetDefault(b Broker) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultBroker = b
+}
+
+// Publish : 通过默认Broker发布消息到指定exchange with rk
+func Publish(exchange, routingKey string, msg []byte) bool {
+	if err := Default().Publish(exchange, routingKey, msg, 0); err != nil {
+		log.Println(err.Error())
+		return false
+	}
+	return true
+}
+
+// AckHandler : 将返回处理结果的callback转为Handler, 返回true时确认, false时拒绝(不重新入队)
+func AckHandler(callback func(msg []byte) bool) Handler {
+	return func(d Delivery) {
+		var err error
+		if callback(d.Body()) {
+			err = d.Ack()
+		} else {
+			err = d.Nack(false)
+		}
+		if err != nil {
+			log.Println(err.Error())
+		}
+	}
+}
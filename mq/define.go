@@ -10,5 +10,10 @@ type TransferData struct {
 	DestStoreType common.StoreType
 }
 
+// FileEventData : 用户文件事件通知的消息体, 事件内容从事件日志读取
+type FileEventData struct {
+	UserName string
+}
+
 
 
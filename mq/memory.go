@@ -0,0 +1,199 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// errSettled : 消息已确认或拒绝
+var errSettled = errors.New("mq: delivery already acknowledged")
+
+// memoryBroker : 进程内的Broker, 消息不持久化, 用于单进程部署及测试
+type memoryBroker struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	bindings map[string][]string // exchange + "\x00" + routingKey -> 队列名
+	queues   map[string]*memoryQueue
+	closed   bool
+}
+
+// memoryQueue : 内存队列, 优先级相同的消息先进先出
+type memoryQueue struct {
+	maxPriority uint8
+	msgs        []memoryMessage
+}
+
+type memoryMessage struct {
+	body     []byte
+	priority uint8
+}
+
+// NewMemory : 创建进程内的Broker
+func NewMemory() Broker {
+	b := &memoryBroker{
+		bindings: map[string][]string{},
+		queues:   map[string]*memoryQueue{},
+	}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// push : 入队, front为true时排在同优先级消息的最前面(重新入队的消息)
+func (q *memoryQueue) push(m memoryMessage, front bool) {
+	if m.priority > q.maxPriority {
+		m.priority = q.maxPriority
+	}
+	if front {
+		q.msgs = append([]memoryMessage{m}, q.msgs...)
+	} else {
+		q.msgs = append(q.msgs, m)
+	}
+}
+
+// pop : 取出优先级最高的第一条消息
+func (q *memoryQueue) pop() memoryMessage {
+	idx := 0
+	for i, m := range q.msgs {
+		if m.priority > q.msgs[idx].priority {
+			idx = i
+		}
+	}
+	m := q.msgs[idx]
+	q.msgs = append(q.msgs[:idx], q.msgs[idx+1:]...)
+	return m
+}
+
+func (b *memoryBroker) DeclareQueue(exchange, queue, routingKey string, maxPriority uint8) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+
+	q, ok := b.queues[queue]
+	if !ok {
+		q = &memoryQueue{maxPriority: maxPriority}
+		b.queues[queue] = q
+	} else if q.maxPriority != maxPriority {
+		return errors.New("mq: queue " + queue + " already declared with different priority")
+	}
+	key := exchange + "\x00" + routingKey
+	for _, name := range b.bindings[key] {
+		if name == queue {
+			return nil
+		}
+	}
+	b.bindings[key] = append(b.bindings[key], queue)
+	return nil
+}
+
+func (b *memoryBroker) Publish(exchange, routingKey string, body []byte, priority uint8) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+
+	for _, name := range b.bindings[exchange+"\x00"+routingKey] {
+		msg := memoryMessage{body: append([]byte(nil), body...), priority: priority}
+		b.queues[name].push(msg, false)
+	}
+	b.cond.Broadcast()
+	return nil
+}
+
+func (b *memoryBroker) Consume(ctx context.Context, queue, consumer string, concurrency int, handler Handler) error {
+	b.mu.Lock()
+	q, ok := b.queues[queue]
+	b.mu.Unlock()
+	if !ok {
+		return errors.New("mq: queue " + queue + " not declared")
+	}
+
+	// ctx结束时唤醒等待消息的消费者
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			b.mu.Lock()
+			b.cond.Broadcast()
+			b.mu.Unlock()
+		case <-stop:
+		}
+	}()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				b.mu.Lock()
+				for len(q.msgs) == 0 && !b.closed && ctx.Err() == nil {
+					b.cond.Wait()
+				}
+				if b.closed || ctx.Err() != nil {
+					b.mu.Unlock()
+					return
+				}
+				msg := q.pop()
+				b.mu.Unlock()
+
+				handler(&memoryDelivery{broker: b, queue: q, msg: msg})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	return ErrClosed
+}
+
+// Close : 关闭Broker, 队列中的消息被丢弃
+func (b *memoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	b.cond.Broadcast()
+	return nil
+}
+
+// memoryDelivery : 内存队列的消息
+type memoryDelivery struct {
+	broker  *memoryBroker
+	queue   *memoryQueue
+	msg     memoryMessage
+	settled bool
+}
+
+func (d *memoryDelivery) Body() []byte {
+	return d.msg.body
+}
+
+func (d *memoryDelivery) Ack() error {
+	d.broker.mu.Lock()
+	defer d.broker.mu.Unlock()
+	if d.settled {
+		return errSettled
+	}
+	d.settled = true
+	return nil
+}
+
+func (d *memoryDelivery) Nack(requeue bool) error {
+	d.broker.mu.Lock()
+	defer d.broker.mu.Unlock()
+	if d.settled {
+		return errSettled
+	}
+	d.settled = true
+	if requeue && !d.broker.closed {
+		d.queue.push(d.msg, true)
+		d.broker.cond.Broadcast()
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/streadway/amqp"
+)
+
+// rabbitBroker : 基于rabbitmq的Broker, 首次使用时连接, 连接或channel断开后下次使用时重新建立
+type rabbitBroker struct {
+	url string
+
+	mu     sync.Mutex
+	conn   *amqp.Connection
+	ch     *amqp.Channel // 发布消息及声明队列使用的channel
+	closed bool
+}
+
+// NewRabbitMQ : 创建连接到url的Broker
+func NewRabbitMQ(url string) Broker {
+	return &rabbitBroker{url: url}
+}
+
+// connection : 获取连接, 须持有mu
+func (b *rabbitBroker) connection() (*amqp.Connection, error) {
+	if b.closed {
+		return nil, ErrClosed
+	}
+	if b.conn == nil || b.conn.IsClosed() {
+		conn, err := amqp.Dial(b.url)
+		if err != nil {
+			return nil, err
+		}
+		b.conn, b.ch = conn, nil
+	}
+	return b.conn, nil
+}
+
+// channel : 获取发布使用的channel, 须持有mu. channel因错误(如声明参数不一致)被关闭后重新创建
+func (b *rabbitBroker) channel() (*amqp.Channel, error) {
+	conn, err := b.connection()
+	if err != nil {
+		return nil, err
+	}
+	if b.ch != nil {
+		return b.ch, nil
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+	closes := ch.NotifyClose(make(chan *amqp.Error, 1))
+	go func() {
+		<-closes
+		b.mu.Lock()
+		if b.ch == ch {
+			b.ch = nil
+		}
+		b.mu.Unlock()
+	}()
+	b.ch = ch
+	return ch, nil
+}
+
+// DeclareQueue : 声明持久化的direct交换机及队列. 已存在时参数须与原声明一致
+func (b *rabbitBroker) DeclareQueue(exchange, queue, routingKey string, maxPriority uint8) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch, err := b.channel()
+	if err != nil {
+		return err
+	}
+
+	if err = ch.ExchangeDeclare(exchange, "direct", true, false, false, false, nil); err != nil {
+		return err
+	}
+	var args amqp.Table
+	if maxPriority > 0 {
+		args = amqp.Table{"x-max-priority": int32(maxPriority)}
+	}
+	if _, err = ch.QueueDeclare(queue, true, false, false, false, args); err != nil {
+		return err
+	}
+	return ch.QueueBind(queue, routingKey, exchange, false, nil)
+}
+
+// Publish : 发布持久化消息
+func (b *rabbitBroker) Publish(exchange, routingKey string, body []byte, priority uint8) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch, err := b.channel()
+	if err != nil {
+		return err
+	}
+
+	return ch.Publish(exchange, routingKey, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Priority:     priority,
+		Body:         body,
+	})
+}
+
+// Consume : 每个消费者使用独立的channel, 预取数量只作用于该消费者
+func (b *rabbitBroker) Consume(ctx context.Context, queue, consumer string, concurrency int, handler Handler) error {
+	b.mu.Lock()
+	conn, err := b.connection()
+	b.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+	if err = ch.Qos(concurrency, 0, false); err != nil {
+		return err
+	}
+	msgs, err := ch.Consume(queue, consumer, false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	// ctx结束时关闭channel, 未确认的消息由rabbitmq重新入队
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			ch.Close()
+		case <-stop:
+		}
+	}()
+
+	// 并发处理, channel或连接关闭时msgs被关闭
+	wg := sync.WaitGroup{}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range msgs {
+				handler(rabbitDelivery{msg})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	return errors.New("mq: rabbitmq consumer closed: " + queue)
+}
+
+// Close : 关闭连接
+func (b *rabbitBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	if b.conn == nil || b.conn.IsClosed() {
+		return nil
+	}
+	return b.conn.Close()
+}
+
+// rabbitDelivery : rabbitmq的消息
+type rabbitDelivery struct {
+	msg amqp.Delivery
+}
+
+func (d rabbitDelivery) Body() []byte {
+	return d.msg.Body
+}
+
+func (d rabbitDelivery) Ack() error {
+	return d.msg.Ack(false)
+}
+
+func (d rabbitDelivery) Nack(requeue bool) error {
+	return d.msg.Nack(false, requeue)
+}
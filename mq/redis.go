@@ -0,0 +1,298 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+
+	rPool "github.com/cloud/cache/redis"
+)
+
+const (
+	// redisGroup : 每个队列(stream)的消费组, 同一队列的消费者竞争消费
+	redisGroup = "mq"
+	// redisBlock : 读取消息的阻塞时长, 也是ctx结束或Broker关闭后消费者退出的最大延迟
+	redisBlock = time.Second
+	// redisQueuePrefix : 队列对应的stream的key前缀
+	redisQueuePrefix = "mq:queue:"
+	// redisBindPrefix : exchange + routing key绑定的队列集合的key前缀
+	redisBindPrefix = "mq:bind:"
+)
+
+// redisPublishScript : 将消息追加到绑定的每个队列
+var redisPublishScript = redis.NewScript(1, `
+local queues = redis.call('SMEMBERS', KEYS[1])
+for _, q in ipairs(queues) do
+	redis.call('XADD', ARGV[1] .. q, '*', 'body', ARGV[2])
+end
+return #queues`)
+
+// redisSettleScript : 确认消息并从stream删除, requeue时将消息追加到队尾.
+// 消息已被确认(如超时后被其他消费者认领并确认)时不再重新入队
+var redisSettleScript = redis.NewScript(1, `
+if redis.call('XACK', KEYS[1], ARGV[1], ARGV[2]) == 1 and ARGV[3] == '1' then
+	redis.call('XADD', KEYS[1], '*', 'body', ARGV[4])
+end
+redis.call('XDEL', KEYS[1], ARGV[2])
+return 1`)
+
+// redisConsumerSeq : 区分同一进程中的消费者
+var redisConsumerSeq int64
+
+// redisBroker : 基于Redis Streams(5.0+, 认领超时消息需要6.2+)的Broker.
+// 每个队列是一个stream, 消息确认后删除; 不支持优先级, 按发布顺序投递
+type redisBroker struct {
+	pool      *redis.Pool
+	claimIdle time.Duration
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewRedisStreams : 创建基于Redis Streams的Broker, pool为空时使用cache/redis的连接池.
+// 已投递超过claimIdle仍未确认的消息(如消费者进程退出)重新投递给其他消费者
+func NewRedisStreams(pool *redis.Pool, claimIdle time.Duration) Broker {
+	if pool == nil {
+		pool = rPool.Pool()
+	}
+	return &redisBroker{pool: pool, claimIdle: claimIdle}
+}
+
+func redisQueueKey(queue string) string {
+	return redisQueuePrefix + queue
+}
+
+func redisBindKey(exchange, routingKey string) string {
+	return redisBindPrefix + exchange + ":" + routingKey
+}
+
+func (b *redisBroker) isClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+// DeclareQueue : 绑定队列并创建其消费组, maxPriority被忽略
+func (b *redisBroker) DeclareQueue(exchange, queue, routingKey string, maxPriority uint8) error {
+	if b.isClosed() {
+		return ErrClosed
+	}
+	conn := b.pool.Get()
+	defer conn.Close()
+
+	if _, err := conn.Do("SADD", redisBindKey(exchange, routingKey), queue); err != nil {
+		return err
+	}
+	_, err := conn.Do("XGROUP", "CREATE", redisQueueKey(queue), redisGroup, "0", "MKSTREAM")
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	return nil
+}
+
+// Publish : priority被忽略
+func (b *redisBroker) Publish(exchange, routingKey string, body []byte, priority uint8) error {
+	if b.isClosed() {
+		return ErrClosed
+	}
+	conn := b.pool.Get()
+	defer conn.Close()
+
+	_, err := redisPublishScript.Do(conn, redisBindKey(exchange, routingKey), redisQueuePrefix, body)
+	return err
+}
+
+// Consume : 每个并发使用一个连接阻塞读取消息, 并定期认领超时未确认的消息
+func (b *redisBroker) Consume(ctx context.Context, queue, consumer string, concurrency int, handler Handler) error {
+	host, _ := os.Hostname()
+	name := fmt.Sprintf("%s@%s:%d:%d", consumer, host, os.Getpid(), atomic.AddInt64(&redisConsumerSeq, 1))
+	key := redisQueueKey(queue)
+
+	// 任一连接出错时其余消费者也退出
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var once sync.Once
+	var consumeErr error
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := b.consume(ctx, key, name, handler); err != nil {
+				once.Do(func() {
+					consumeErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if consumeErr != nil {
+		return consumeErr
+	}
+	if b.isClosed() {
+		return ErrClosed
+	}
+	return nil
+}
+
+// consume : 逐条读取并处理消息, 直到ctx结束或Broker关闭
+func (b *redisBroker) consume(ctx context.Context, key, name string, handler Handler) error {
+	conn := b.pool.Get()
+	defer conn.Close()
+
+	var lastClaim time.Time
+	for ctx.Err() == nil && !b.isClosed() {
+		var entries []redisEntry
+		var err error
+		// 1 定期认领超时未确认的消息, 认领到时继续认领直到没有
+		if b.claimIdle > 0 && time.Since(lastClaim) >= b.claimIdle/2 {
+			entries, err = redisClaimEntries(conn.Do("XAUTOCLAIM", key, redisGroup, name,
+				b.claimIdle.Milliseconds(), "0-0", "COUNT", 1))
+			if err == nil && len(entries) == 0 {
+				lastClaim = time.Now()
+			}
+		}
+		// 2 读取新消息
+		if err == nil && len(entries) == 0 {
+			entries, err = redisReadEntries(conn.Do("XREADGROUP", "GROUP", redisGroup, name,
+				"COUNT", 1, "BLOCK", redisBlock.Milliseconds(), "STREAMS", key, ">"))
+		}
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			d := &redisDelivery{broker: b, key: key, entry: e}
+			if e.body == nil {
+				// 已删除的消息
+				d.Ack()
+				continue
+			}
+			handler(d)
+		}
+	}
+	return nil
+}
+
+// Close : 进行中的Consume在redisBlock内返回, 连接池由调用方管理
+func (b *redisBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
+
+// redisEntry : stream中的一条消息, body为nil表示消息已被删除
+type redisEntry struct {
+	id   string
+	body []byte
+}
+
+// errRedisReply : 无法解析的stream返回
+var errRedisReply = errors.New("mq: unexpected redis stream reply")
+
+// redisReadEntries : 解析XREADGROUP的返回[[key, entries]], 超时时返回nil
+func redisReadEntries(reply interface{}, err error) ([]redisEntry, error) {
+	streams, err := redis.Values(reply, err)
+	if err == redis.ErrNil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(streams) == 0 {
+		return nil, nil
+	}
+	stream, err := redis.Values(streams[0], nil)
+	if err != nil || len(stream) != 2 {
+		return nil, errRedisReply
+	}
+	return parseRedisEntries(stream[1])
+}
+
+// redisClaimEntries : 解析XAUTOCLAIM的返回[cursor, entries, ...]
+func redisClaimEntries(reply interface{}, err error) ([]redisEntry, error) {
+	values, err := redis.Values(reply, err)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) < 2 {
+		return nil, errRedisReply
+	}
+	return parseRedisEntries(values[1])
+}
+
+// parseRedisEntries : 解析[[id, [field, value, ...]], ...], 已删除的消息在6.2中为nil或没有字段
+func parseRedisEntries(reply interface{}) ([]redisEntry, error) {
+	items, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, err
+	}
+	entries := []redisEntry{}
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		parts, err := redis.Values(item, nil)
+		if err != nil || len(parts) != 2 {
+			return nil, errRedisReply
+		}
+		id, err := redis.String(parts[0], nil)
+		if err != nil {
+			return nil, err
+		}
+		e := redisEntry{id: id}
+		fields, _ := redis.ByteSlices(parts[1], nil)
+		for i := 0; i+1 < len(fields); i += 2 {
+			if string(fields[i]) == "body" {
+				e.body = fields[i+1]
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// redisDelivery : Redis Streams的消息
+type redisDelivery struct {
+	broker  *redisBroker
+	key     string
+	entry   redisEntry
+	settled int32
+}
+
+func (d *redisDelivery) Body() []byte {
+	return d.entry.body
+}
+
+func (d *redisDelivery) Ack() error {
+	return d.settle(false)
+}
+
+func (d *redisDelivery) Nack(requeue bool) error {
+	return d.settle(requeue)
+}
+
+func (d *redisDelivery) settle(requeue bool) error {
+	if !atomic.CompareAndSwapInt32(&d.settled, 0, 1) {
+		return errSettled
+	}
+	conn := d.broker.pool.Get()
+	defer conn.Close()
+
+	flag := "0"
+	if requeue {
+		flag = "1"
+	}
+	_, err := redisSettleScript.Do(conn, d.key, redisGroup, d.entry.id, flag, d.entry.body)
+	return err
+}
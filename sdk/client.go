@@ -0,0 +1,411 @@
+// Package sdk : 网关及上传/下载服务对外HTTP接口的Go客户端
+//
+//	c := sdk.NewClient("http://127.0.0.1:8080")
+//	if _, err := c.SignIn(ctx, "admin", "admin123"); err != nil { ... }
+//	res, err := sdk.NewUploader(c).UploadFile(ctx, "./a.mp4", "video/a.mp4")
+//	_, err = sdk.NewDownloader(c).DownloadFile(ctx, "video/a.mp4", "./b.mp4")
+//
+// 使用密码登录的客户端在token过期时自动重新登录; 也可通过WithSession复用已保存的token
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// TokenTTL : 服务端token的有效期
+	TokenTTL = 24 * time.Hour
+	// tokenRefreshAhead : token到期前提前重新登录的时间
+	tokenRefreshAhead = 5 * time.Minute
+	// codeGatewayOK : 网关接口表示成功的code(common.StatusOK), 上传/下载服务以0表示成功
+	codeGatewayOK = 10000
+	// msgInvalidToken : token校验失败时拦截器返回的msg
+	msgInvalidToken = "Invalid Token"
+)
+
+// Session : 登录后获得的会话信息
+type Session struct {
+	Username string
+	Token    string
+	// UploadEntry/DownloadEntry : 上传/下载服务的地址, 一般为host:port形式
+	UploadEntry   string
+	DownloadEntry string
+}
+
+// ExpireAt : token的过期时间, 由token末尾8位的16进制时间戳推算
+func (s Session) ExpireAt() time.Time {
+	if len(s.Token) != 40 {
+		return time.Time{}
+	}
+	ts, err := strconv.ParseInt(s.Token[32:], 16, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(ts, 0).Add(TokenTTL)
+}
+
+// Client : 对外HTTP接口的客户端, 可被多个goroutine并发使用
+type Client struct {
+	server     string
+	httpClient *http.Client
+	onRefresh  func(Session)
+
+	mu       sync.Mutex
+	session  Session
+	password string
+}
+
+// Option : 创建Client时的可选配置
+type Option func(*Client)
+
+// WithHTTPClient : 使用自定义的http.Client
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithSession : 复用已保存的会话, 无需再次登录
+func WithSession(s Session) Option {
+	return func(c *Client) {
+		c.session = s
+	}
+}
+
+// WithPassword : 设置密码, token过期时用于自动重新登录
+func WithPassword(username, password string) Option {
+	return func(c *Client) {
+		c.session.Username = username
+		c.password = password
+	}
+}
+
+// OnSessionRefresh : token自动刷新后的回调, 可用于持久化新的会话
+func OnSessionRefresh(fn func(Session)) Option {
+	return func(c *Client) {
+		c.onRefresh = fn
+	}
+}
+
+// NewClient : 创建客户端, server为网关地址, 如 http://127.0.0.1:8080
+func NewClient(server string, opts ...Option) *Client {
+	c := &Client{
+		server: strings.TrimRight(server, "/"),
+		// 上传下载的耗时取决于数据量, 只限制等待响应头的时间; 调用方可通过context控制整体超时
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				MaxIdleConnsPerHost:   32,
+				ResponseHeaderTimeout: 5 * time.Minute,
+			},
+		},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Session : 当前会话
+func (c *Client) Session() Session {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session
+}
+
+// SignUp : 注册用户
+func (c *Client) SignUp(ctx context.Context, username, password string) error {
+	req, err := newFormRequest(c.server+"/user/signup", url.Values{
+		"username": {username},
+		"password": {password},
+	})
+	if err != nil {
+		return err
+	}
+	return c.doJSON(ctx, req, nil)
+}
+
+// SignIn : 登录, 之后的请求均使用新的token, 并在token过期时使用该密码自动重新登录
+func (c *Client) SignIn(ctx context.Context, username, password string) (*Session, error) {
+	sess, err := c.signin(ctx, username, password)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.session = *sess
+	c.password = password
+	c.mu.Unlock()
+	return sess, nil
+}
+
+func (c *Client) signin(ctx context.Context, username, password string) (*Session, error) {
+	req, err := newFormRequest(c.server+"/user/signin", url.Values{
+		"username": {username},
+		"password": {password},
+	})
+	if err != nil {
+		return nil, err
+	}
+	sess := &Session{}
+	if err := c.doJSON(ctx, req, sess); err != nil {
+		return nil, err
+	}
+	if sess.Token == "" {
+		return nil, &APIError{Method: req.Method, Path: req.URL.Path, Msg: "empty token"}
+	}
+	sess.Username = username
+	return sess, nil
+}
+
+// currentSession : 返回可用的会话, token即将过期且设置了密码时先重新登录
+func (c *Client) currentSession(ctx context.Context) (Session, error) {
+	c.mu.Lock()
+	sess, password := c.session, c.password
+	c.mu.Unlock()
+	if sess.Token == "" && password == "" {
+		return sess, ErrNotSignedIn
+	}
+	if password != "" && (sess.Token == "" || time.Until(sess.ExpireAt()) < tokenRefreshAhead) {
+		return c.refresh(ctx, sess.Token)
+	}
+	return sess, nil
+}
+
+// refresh : 重新登录. 并发请求同时发现token失效时只登录一次
+func (c *Client) refresh(ctx context.Context, staleToken string) (Session, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.session.Token != staleToken {
+		return c.session, nil
+	}
+	if c.password == "" {
+		return c.session, ErrTokenExpired
+	}
+	sess, err := c.signin(ctx, c.session.Username, c.password)
+	if err != nil {
+		return c.session, err
+	}
+	c.session = *sess
+	if c.onRefresh != nil {
+		c.onRefresh(*sess)
+	}
+	return *sess, nil
+}
+
+// apiURL/uploadURL/downloadURL : 网关及上传/下载服务的接口地址
+func (c *Client) apiURL(s Session, path string) string {
+	return c.server + path
+}
+
+func (c *Client) uploadURL(s Session, path string) string {
+	return entryURL(s.UploadEntry) + path
+}
+
+func (c *Client) downloadURL(s Session, path string) string {
+	return entryURL(s.DownloadEntry) + path
+}
+
+// entryURL : 登录时返回的上传/下载入口为host:port形式
+func entryURL(entry string) string {
+	if strings.HasPrefix(entry, "http://") || strings.HasPrefix(entry, "https://") {
+		return strings.TrimRight(entry, "/")
+	}
+	return "http://" + entry
+}
+
+// authValues : 携带用户名及token的请求参数
+func authValues(s Session) url.Values {
+	return url.Values{
+		"username": {s.Username},
+		"token":    {s.Token},
+	}
+}
+
+// requestFunc : 根据会话生成请求, token刷新后重试时重新调用
+type requestFunc func(s Session) (*http.Request, error)
+
+// newFormRequest : 表单方式提交的请求
+func newFormRequest(rawURL string, form url.Values) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodPost, rawURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req, nil
+}
+
+// authForm : 表单方式提交, 表单中附带用户名及token
+func authForm(endpoint func(Session, string) string, path string, form url.Values) requestFunc {
+	return func(s Session) (*http.Request, error) {
+		values := authValues(s)
+		for k, v := range form {
+			values[k] = v
+		}
+		return newFormRequest(endpoint(s, path), values)
+	}
+}
+
+// authBody : 以原始请求体提交(如上传分块), 用户名及token放在查询参数中
+func authBody(endpoint func(Session, string) string, path string, query url.Values,
+	contentType string, body []byte) requestFunc {
+	return func(s Session) (*http.Request, error) {
+		values := authValues(s)
+		for k, v := range query {
+			values[k] = v
+		}
+		req, err := http.NewRequest(http.MethodPost, endpoint(s, path)+"?"+values.Encode(), bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", contentType)
+		return req, nil
+	}
+}
+
+// authGet : GET请求, 用户名及token放在查询参数中
+func authGet(endpoint func(Session, string) string, path string, query url.Values,
+	header http.Header) requestFunc {
+	return func(s Session) (*http.Request, error) {
+		values := authValues(s)
+		for k, v := range query {
+			values[k] = v
+		}
+		req, err := http.NewRequest(http.MethodGet, endpoint(s, path)+"?"+values.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		return req, nil
+	}
+}
+
+// call : 以当前会话调用需要登录的接口, token失效时重新登录并重试一次
+func (c *Client) call(ctx context.Context, build requestFunc, out interface{}) error {
+	sess, err := c.currentSession(ctx)
+	if err != nil {
+		return err
+	}
+	for attempt := 0; ; attempt++ {
+		req, err := build(sess)
+		if err != nil {
+			return err
+		}
+		err = c.doJSON(ctx, req, out)
+		if err != ErrTokenExpired || attempt > 0 {
+			return err
+		}
+		if sess, err = c.refresh(ctx, sess.Token); err != nil {
+			return err
+		}
+	}
+}
+
+// apiResponse : 接口统一的{code,msg,data}响应
+type apiResponse struct {
+	Code int             `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// doJSON : 发送请求并解析{code,msg,data}格式的响应, 成功时将data解析到out
+func (c *Client) doJSON(ctx context.Context, req *http.Request, out interface{}) error {
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return newHTTPError(req, resp.StatusCode, body)
+	}
+
+	var apiResp apiResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return newHTTPError(req, resp.StatusCode, body)
+	}
+	if apiResp.Msg == msgInvalidToken {
+		return ErrTokenExpired
+	}
+	if apiResp.Code != 0 && apiResp.Code != codeGatewayOK {
+		return &APIError{Method: req.Method, Path: req.URL.Path, Code: apiResp.Code, Msg: apiResp.Msg}
+	}
+	if out != nil && len(apiResp.Data) > 0 && string(apiResp.Data) != "null" {
+		return json.Unmarshal(apiResp.Data, out)
+	}
+	return nil
+}
+
+// doRaw : 发送请求并返回原始响应(如下载文件内容), token失效时重新登录并重试一次.
+// 响应为{code,msg,data}格式的JSON时转换为error
+func (c *Client) doRaw(ctx context.Context, build requestFunc) (*http.Response, error) {
+	sess, err := c.currentSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 0; ; attempt++ {
+		req, err := build(sess)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.httpClient.Do(req.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+			return resp, nil
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		// 不是{code,msg,data}格式的JSON(如/file/query返回的文件列表)原样返回
+		if trimmed := bytes.TrimSpace(body); len(trimmed) == 0 || trimmed[0] != '{' {
+			resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+			return resp, nil
+		}
+		var apiResp apiResponse
+		if json.Unmarshal(body, &apiResp) != nil {
+			return nil, newHTTPError(req, resp.StatusCode, body)
+		}
+		if apiResp.Msg == msgInvalidToken && attempt == 0 {
+			if sess, err = c.refresh(ctx, sess.Token); err != nil {
+				return nil, err
+			}
+			continue
+		} else if apiResp.Msg == msgInvalidToken {
+			return nil, ErrTokenExpired
+		}
+		if apiResp.Code == 0 || apiResp.Code == codeGatewayOK {
+			resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+			return resp, nil
+		}
+		return nil, &APIError{Method: req.Method, Path: req.URL.Path, Code: apiResp.Code, Msg: apiResp.Msg}
+	}
+}
+
+func newHTTPError(req *http.Request, statusCode int, body []byte) error {
+	if len(body) > 512 {
+		body = body[:512]
+	}
+	return &HTTPError{
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		StatusCode: statusCode,
+		Body:       strings.TrimSpace(string(body)),
+	}
+}
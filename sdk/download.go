@@ -0,0 +1,325 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+)
+
+const (
+	// PartFileSuffix : 下载过程中数据文件的后缀
+	PartFileSuffix = ".part"
+	// StateFileSuffix : 下载进度文件的后缀, 用于断点续传
+	StateFileSuffix = ".part.json"
+	// defaultPartSize : 分段下载时每段的默认大小
+	defaultPartSize = 8 << 20
+	// defaultDownloadConcurrency : 分段下载的默认并发数
+	defaultDownloadConcurrency = 4
+)
+
+// DownloadResult : 下载结果
+type DownloadResult struct {
+	FileMeta
+	Local string
+	// Ranged : 是否以Range请求分段下载; 服务端不支持(如文件已转移到Ceph/OSS)时整体下载
+	Ranged bool
+	// Resumed : 是否从上次中断处续传
+	Resumed bool
+}
+
+// Open : 读取文件内容中从offset开始的length字节, length小于0时读到文件结尾.
+// 服务端不支持Range时跳过前offset字节, 对调用方透明
+func (c *Client) Open(ctx context.Context, filehash string, offset, length int64) (io.ReadCloser, error) {
+	header := http.Header{}
+	if length >= 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	} else if offset > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := c.doRaw(ctx, authGet(c.downloadURL, "/file/download", url.Values{
+		"filehash": {filehash},
+	}, header))
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return resp.Body, nil
+	case http.StatusOK:
+		if _, err := io.CopyN(ioutil.Discard, resp.Body, offset); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		if length < 0 {
+			return resp.Body, nil
+		}
+		return limitReadCloser{io.LimitReader(resp.Body, length), resp.Body}, nil
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+	return nil, newHTTPError(resp.Request, resp.StatusCode, body)
+}
+
+type limitReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// supportsRange : 请求第一个字节, 响应206表示支持Range
+func (c *Client) supportsRange(ctx context.Context, filehash string) (bool, error) {
+	header := http.Header{}
+	header.Set("Range", "bytes=0-0")
+	resp, err := c.doRaw(ctx, authGet(c.downloadURL, "/file/download", url.Values{
+		"filehash": {filehash},
+	}, header))
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return true, nil
+	case http.StatusOK:
+		return false, nil
+	}
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+	return false, newHTTPError(resp.Request, resp.StatusCode, body)
+}
+
+// Downloader : 服务端支持Range时分段并发下载, 否则整体下载
+type Downloader struct {
+	Client *Client
+	// PartSize : 每个Range请求的大小
+	PartSize int64
+	// Concurrency : 并发的Range请求数
+	Concurrency int
+	// Retries : 单个分段失败时的重试次数
+	Retries int
+	// Progress : 每下载完成一部分数据时回调(含续传时跳过的分段), 回调不会并发执行
+	Progress func(n int64)
+
+	progressMu sync.Mutex
+}
+
+// NewDownloader : 使用默认配置创建下载器
+func NewDownloader(c *Client) *Downloader {
+	return &Downloader{
+		Client:      c,
+		PartSize:    defaultPartSize,
+		Concurrency: defaultDownloadConcurrency,
+		Retries:     defaultRetries,
+	}
+}
+
+// downloadState : 分段下载的进度, 与数据文件一起保存在目标路径旁
+type downloadState struct {
+	FileHash string
+	FileSize int64
+	PartSize int64
+	Done     []int
+}
+
+// Download : 下载文件内容写入w, 返回是否以Range分段下载
+func (d *Downloader) Download(ctx context.Context, f FileMeta, w io.WriterAt) (bool, error) {
+	state := &downloadState{FileHash: f.FileHash, FileSize: f.FileSize, PartSize: d.PartSize}
+	return d.download(ctx, w, state, nil)
+}
+
+// DownloadFile : 下载文件name到本地路径local, 见DownloadToFile
+func (d *Downloader) DownloadFile(ctx context.Context, name, local string) (*DownloadResult, error) {
+	f, err := d.Client.Stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return d.DownloadToFile(ctx, *f, local)
+}
+
+// DownloadToFile : 下载文件f到本地路径local. 数据先写入local+PartFileSuffix并记录进度,
+// 中断后重新执行可续传; 完成后校验sha1再重命名为local
+func (d *Downloader) DownloadToFile(ctx context.Context, f FileMeta, local string) (*DownloadResult, error) {
+	res := &DownloadResult{FileMeta: f, Local: local}
+
+	partPath, statePath := local+PartFileSuffix, local+StateFileSuffix
+	state := d.loadState(statePath, &f)
+	res.Resumed = len(state.Done) > 0
+	flag := os.O_RDWR | os.O_CREATE
+	if !res.Resumed {
+		flag |= os.O_TRUNC
+	}
+	fd, err := os.OpenFile(partPath, flag, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	res.Ranged, err = d.download(ctx, fd, state, func() error {
+		data, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(statePath, data, 0644)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !res.Ranged {
+		res.Resumed = false
+	}
+	if err := fd.Truncate(f.FileSize); err != nil {
+		return nil, err
+	}
+
+	// 校验完整文件的sha1
+	filehash, err := SHA1(ctx, fd, f.FileSize, nil)
+	if err != nil {
+		return nil, err
+	}
+	if filehash != f.FileHash {
+		os.Remove(statePath)
+		return nil, fmt.Errorf("sdk: sha1 mismatch: expected %s, got %s", f.FileHash, filehash)
+	}
+	fd.Close()
+	if err := os.Rename(partPath, local); err != nil {
+		return nil, err
+	}
+	os.Remove(statePath)
+	return res, nil
+}
+
+// loadState : 读取与远端文件一致的下载进度, 否则从头开始
+func (d *Downloader) loadState(statePath string, f *FileMeta) *downloadState {
+	state := &downloadState{FileHash: f.FileHash, FileSize: f.FileSize, PartSize: d.PartSize}
+	data, err := ioutil.ReadFile(statePath)
+	if err != nil {
+		return state
+	}
+	saved := downloadState{}
+	if json.Unmarshal(data, &saved) != nil || saved.FileHash != f.FileHash ||
+		saved.FileSize != f.FileSize || saved.PartSize <= 0 {
+		return state
+	}
+	return &saved
+}
+
+// download : 按state下载尚未完成的分段, 每完成一段后调用checkpoint保存进度(调用不会并发)
+func (d *Downloader) download(ctx context.Context, w io.WriterAt, state *downloadState,
+	checkpoint func() error) (bool, error) {
+	if state.FileSize == 0 {
+		return false, nil
+	}
+	if state.PartSize <= 0 {
+		state.PartSize = defaultPartSize
+	}
+	ranged, err := d.Client.supportsRange(ctx, state.FileHash)
+	if err != nil {
+		return false, err
+	}
+	if !ranged {
+		state.Done = nil
+		return false, d.stream(ctx, w, state)
+	}
+
+	partCount := int((state.FileSize + state.PartSize - 1) / state.PartSize)
+	done := map[int]bool{}
+	var downloaded int64
+	for _, idx := range state.Done {
+		done[idx] = true
+		downloaded += partLength(idx, state.PartSize, state.FileSize)
+	}
+	d.progress(downloaded)
+
+	var mu sync.Mutex
+	err = runParts(ctx, partCount, done, d.Concurrency, func(ctx context.Context, idx int) error {
+		offset := int64(idx) * state.PartSize
+		length := partLength(idx, state.PartSize, state.FileSize)
+		err := retry(ctx, d.Retries, func() error {
+			return d.downloadPart(ctx, w, state.FileHash, offset, length)
+		})
+		if err != nil {
+			return fmt.Errorf("range %d-%d: %s", offset, offset+length-1, err.Error())
+		}
+		d.progress(length)
+
+		mu.Lock()
+		defer mu.Unlock()
+		state.Done = append(state.Done, idx)
+		if checkpoint != nil {
+			return checkpoint()
+		}
+		return nil
+	})
+	return true, err
+}
+
+// downloadPart : 下载[offset, offset+length)并写入w的相同位置
+func (d *Downloader) downloadPart(ctx context.Context, w io.WriterAt, filehash string, offset, length int64) error {
+	r, err := d.Client.Open(ctx, filehash, offset, length)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	n, err := io.Copy(&offsetWriter{w, offset}, r)
+	if err != nil {
+		return err
+	}
+	if n != length {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// stream : 整体下载
+func (d *Downloader) stream(ctx context.Context, w io.WriterAt, state *downloadState) error {
+	r, err := d.Client.Open(ctx, state.FileHash, 0, -1)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	ow := &offsetWriter{w: w}
+	buf := make([]byte, 256<<10)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if _, werr := ow.Write(buf[:n]); werr != nil {
+				return werr
+			}
+			d.progress(int64(n))
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	if ow.offset != state.FileSize {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func (d *Downloader) progress(n int64) {
+	if d.Progress == nil || n == 0 {
+		return
+	}
+	d.progressMu.Lock()
+	defer d.progressMu.Unlock()
+	d.Progress(n)
+}
+
+// offsetWriter : 从指定位置开始顺序写入, 多个offsetWriter可并发写入同一文件的不同位置
+type offsetWriter struct {
+	w      io.WriterAt
+	offset int64
+}
+
+func (ow *offsetWriter) Write(p []byte) (int, error) {
+	n, err := ow.w.WriteAt(p, ow.offset)
+	ow.offset += int64(n)
+	return n, err
+}
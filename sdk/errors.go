@@ -0,0 +1,48 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrTokenExpired : token无效或已过期, 且客户端没有可用于重新登录的密码
+	ErrTokenExpired = errors.New("sdk: token expired, sign in again")
+	// ErrNotSignedIn : 调用需要登录的接口前未登录
+	ErrNotSignedIn = errors.New("sdk: not signed in")
+	// ErrNotFound : 文件不存在
+	ErrNotFound = errors.New("sdk: file not found")
+)
+
+// APIError : 接口返回的业务错误, Code为响应中的code字段
+type APIError struct {
+	Method string
+	Path   string
+	Code   int
+	Msg    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: code %d: %s", e.Method, e.Path, e.Code, e.Msg)
+}
+
+// IsCode : 判断err是否为指定code的APIError
+func IsCode(err error, code int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// HTTPError : 接口返回了非预期的HTTP状态码
+type HTTPError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("%s %s: HTTP %d", e.Method, e.Path, e.StatusCode)
+	}
+	return fmt.Sprintf("%s %s: HTTP %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
@@ -0,0 +1,232 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UserInfo : 用户信息
+type UserInfo struct {
+	Username   string
+	SignupAt   string
+	LastActive string
+}
+
+// FileMeta : 用户文件, 目录以"/"结尾的空文件表示
+type FileMeta struct {
+	FileHash    string
+	FileName    string
+	FileSize    int64
+	UploadAt    string
+	LastUpdated string
+}
+
+// ListFilesRequest : 按前缀分页列文件的请求参数
+type ListFilesRequest struct {
+	Prefix string
+	// Marker : 从文件名(按字节序)大于Marker的文件开始返回
+	Marker string
+	// Limit : 每页文件数, 0表示服务端默认值
+	Limit int
+}
+
+// ListFilesResponse : 按前缀分页列文件的结果, NextMarker非空时表示还有下一页
+type ListFilesResponse struct {
+	Files      []FileMeta
+	NextMarker string
+}
+
+// Usage : 用户的文件数及占用空间
+type Usage struct {
+	FileCount int64
+	TotalSize int64
+}
+
+// ShareLink : 分享链接
+type ShareLink struct {
+	URL      string
+	ExpireAt string
+}
+
+// 文件变更事件类型
+const (
+	EventUpload  = "upload"
+	EventRename  = "rename"
+	EventMove    = "move"
+	EventDelete  = "delete"
+	EventRestore = "restore"
+	EventShare   = "share"
+)
+
+// FileEvent : 文件变更事件. 重命名及移动时OldName为原文件名
+type FileEvent struct {
+	ID       int64
+	Event    string
+	FileName string
+	OldName  string
+	FileHash string
+	FileSize int64
+	CreateAt string
+}
+
+// Changes : 变更事件查询结果. Cursor用于下次查询, HasMore为true时还有未返回的事件
+type Changes struct {
+	Events  []FileEvent
+	Cursor  string
+	HasMore bool
+}
+
+// UserInfo : 查询当前用户信息
+func (c *Client) UserInfo(ctx context.Context) (*UserInfo, error) {
+	info := &UserInfo{}
+	if err := c.call(ctx, authForm(c.apiURL, "/user/info", nil), info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Usage : 查询当前用户的文件数及占用空间
+func (c *Client) Usage(ctx context.Context) (*Usage, error) {
+	usage := &Usage{}
+	if err := c.call(ctx, authForm(c.apiURL, "/user/usage", nil), usage); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// QueryFiles : 查询最近上传的limit个文件
+func (c *Client) QueryFiles(ctx context.Context, limit int) ([]FileMeta, error) {
+	// 该接口直接返回文件列表, 不是{code,msg,data}格式
+	resp, err := c.doRaw(ctx, authForm(c.apiURL, "/file/query", url.Values{
+		"limit": {strconv.Itoa(limit)},
+	}))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, newHTTPError(resp.Request, resp.StatusCode, nil)
+	}
+	files := []FileMeta{}
+	if err := json.NewDecoder(resp.Body).Decode(&files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// ListFiles : 按前缀分页列出文件. 前缀匹配不区分大小写, 调用方需要时自行过滤
+func (c *Client) ListFiles(ctx context.Context, req ListFilesRequest) (*ListFilesResponse, error) {
+	form := url.Values{
+		"prefix": {req.Prefix},
+		"marker": {req.Marker},
+	}
+	if req.Limit > 0 {
+		form.Set("limit", strconv.Itoa(req.Limit))
+	}
+	resp := &ListFilesResponse{}
+	if err := c.call(ctx, authForm(c.apiURL, "/file/list", form), resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// WalkFiles : 按文件名顺序遍历以prefix开头的全部文件, visit返回错误时停止遍历并返回该错误
+func (c *Client) WalkFiles(ctx context.Context, prefix string, visit func(FileMeta) error) error {
+	req := ListFilesRequest{Prefix: prefix}
+	for {
+		resp, err := c.ListFiles(ctx, req)
+		if err != nil {
+			return err
+		}
+		for _, f := range resp.Files {
+			if err := visit(f); err != nil {
+				return err
+			}
+		}
+		if resp.NextMarker == "" {
+			return nil
+		}
+		req.Marker = resp.NextMarker
+	}
+}
+
+// errStopWalk : 用于提前结束WalkFiles
+type errStopWalk struct{}
+
+func (errStopWalk) Error() string { return "stop walk" }
+
+// Stat : 按文件名查询单个文件, 文件名区分大小写; 不存在时返回ErrNotFound
+func (c *Client) Stat(ctx context.Context, name string) (*FileMeta, error) {
+	var found *FileMeta
+	err := c.WalkFiles(ctx, name, func(f FileMeta) error {
+		if f.FileName == name {
+			found = &f
+			return errStopWalk{}
+		}
+		// 按字节序遍历, 大小写完全匹配的文件名之后不会再出现
+		if strings.HasPrefix(f.FileName, name) {
+			return errStopWalk{}
+		}
+		return nil
+	})
+	if _, ok := err.(errStopWalk); err != nil && !ok {
+		return nil, err
+	}
+	if found == nil {
+		return nil, ErrNotFound
+	}
+	return found, nil
+}
+
+// Delete : 删除文件
+func (c *Client) Delete(ctx context.Context, name string) error {
+	return c.call(ctx, authForm(c.apiURL, "/file/delete", url.Values{
+		"filename": {name},
+	}), nil)
+}
+
+// Move : 移动或重命名文件, 目标已存在时失败
+func (c *Client) Move(ctx context.Context, src, dest string) error {
+	return c.call(ctx, authForm(c.apiURL, "/file/move", url.Values{
+		"srcname":  {src},
+		"destname": {dest},
+	}), nil)
+}
+
+// CreateShareLink : 为文件生成有效期为expires的公开下载链接, 0表示服务端默认有效期
+func (c *Client) CreateShareLink(ctx context.Context, name string, expires time.Duration) (*ShareLink, error) {
+	form := url.Values{"filename": {name}}
+	if expires > 0 {
+		form.Set("expires", strconv.Itoa(int(expires.Seconds())))
+	}
+	link := &ShareLink{}
+	if err := c.call(ctx, authForm(c.downloadURL, "/file/sharelink", form), link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// Restore : 恢复已删除的文件
+func (c *Client) Restore(ctx context.Context, name string) error {
+	return c.call(ctx, authForm(c.apiURL, "/file/restore", url.Values{
+		"filename": {name},
+	}), nil)
+}
+
+// Changes : 按顺序获取cursor之后的文件变更事件. cursor为空时不返回事件, 只返回最新游标;
+// wait大于0且暂无新事件时服务端最多等待wait(上限60秒)
+func (c *Client) Changes(ctx context.Context, cursor string, wait time.Duration) (*Changes, error) {
+	form := url.Values{"cursor": {cursor}}
+	if wait > 0 {
+		form.Set("timeout", strconv.Itoa(int(wait.Seconds())))
+	}
+	changes := &Changes{}
+	if err := c.call(ctx, authForm(c.apiURL, "/file/changes", form), changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
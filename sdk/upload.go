@@ -0,0 +1,366 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// codeFastUploadMiss : 秒传接口在服务端没有相同内容时返回的code
+	codeFastUploadMiss = -1
+	// defaultRetries : 分块上传/分段下载失败时的默认重试次数
+	defaultRetries = 3
+)
+
+// MultipartUploadInfo : 分块上传初始化接口返回的数据
+type MultipartUploadInfo struct {
+	FileHash   string
+	FileSize   int64
+	UploadID   string
+	ChunkSize  int64
+	ChunkCount int
+	// ChunkExists : 续传时已上传完成的分块
+	ChunkExists []int
+	// MaxParallel : 服务端建议的最大并发上传分块数
+	MaxParallel int
+}
+
+// UploadResult : 上传结果
+type UploadResult struct {
+	FileName string
+	FileHash string
+	FileSize int64
+	// Method : fast(秒传) / multipart(分块上传)
+	Method string
+	// ChunksResumed : 续传时跳过的已上传分块数
+	ChunksResumed int
+}
+
+// FastUpload : 秒传, 服务端没有相同内容的文件时返回false
+func (c *Client) FastUpload(ctx context.Context, filehash, name string) (bool, error) {
+	err := c.call(ctx, authForm(c.uploadURL, "/file/fastupload", url.Values{
+		"filehash": {filehash},
+		"filename": {name},
+	}), nil)
+	if IsCode(err, codeFastUploadMiss) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// InitMultipartUpload : 初始化(或按用户+文件hash恢复)分块上传会话. chunkSize为0时使用服务端默认值
+func (c *Client) InitMultipartUpload(ctx context.Context, filehash string, size, chunkSize int64) (*MultipartUploadInfo, error) {
+	form := url.Values{
+		"filehash": {filehash},
+		"filesize": {strconv.FormatInt(size, 10)},
+	}
+	if chunkSize > 0 {
+		form.Set("chunksize", strconv.FormatInt(chunkSize, 10))
+	}
+	info := &MultipartUploadInfo{}
+	if err := c.call(ctx, authForm(c.uploadURL, "/file/mpupload/init", form), info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// UploadPart : 上传单个分块, 附带分块sha1供服务端校验
+func (c *Client) UploadPart(ctx context.Context, uploadID string, index int, data []byte) error {
+	sum := sha1.Sum(data)
+	return c.call(ctx, authBody(c.uploadURL, "/file/mpupload/uppart", url.Values{
+		"uploadid": {uploadID},
+		"index":    {strconv.Itoa(index)},
+		"chkhash":  {hex.EncodeToString(sum[:])},
+	}, "application/octet-stream", data), nil)
+}
+
+// CompleteMultipartUpload : 通知服务端合并分块并写入用户文件表
+func (c *Client) CompleteMultipartUpload(ctx context.Context, uploadID, filehash string, size int64, name string) error {
+	return c.call(ctx, authForm(c.uploadURL, "/file/mpupload/complete", url.Values{
+		"uploadid": {uploadID},
+		"filehash": {filehash},
+		"filesize": {strconv.FormatInt(size, 10)},
+		"filename": {name},
+	}), nil)
+}
+
+// Uploader : 先秒传、再分块并发上传的上传器, 服务端保留未完成的上传会话, 中断后重新上传即可续传
+type Uploader struct {
+	Client *Client
+	// ChunkSize : 分块大小, 0表示服务端默认值
+	ChunkSize int64
+	// Concurrency : 并发上传的分块数, 0表示服务端建议值
+	Concurrency int
+	// Retries : 单个分块失败时的重试次数
+	Retries int
+	// Progress : 每上传完成一部分数据时回调(含续传时跳过的分块), 回调不会并发执行
+	Progress func(n int64)
+
+	progressMu sync.Mutex
+}
+
+// NewUploader : 使用默认配置创建上传器
+func NewUploader(c *Client) *Uploader {
+	return &Uploader{Client: c, Retries: defaultRetries}
+}
+
+// UploadFile : 上传本地文件为name
+func (u *Uploader) UploadFile(ctx context.Context, local, name string) (*UploadResult, error) {
+	fd, err := os.Open(local)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	info, err := fd.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s: not a regular file", local)
+	}
+	filehash, err := SHA1(ctx, fd, info.Size(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return u.Upload(ctx, fd, info.Size(), filehash, name)
+}
+
+// Upload : 上传r中大小为size、sha1为filehash的内容为name
+func (u *Uploader) Upload(ctx context.Context, r io.ReaderAt, size int64, filehash, name string) (*UploadResult, error) {
+	return u.upload(ctx, r, size, filehash, name, true)
+}
+
+// upload : fast为false时不尝试秒传(如加密目录, 服务端不支持秒传)
+func (u *Uploader) upload(ctx context.Context, r io.ReaderAt, size int64, filehash, name string,
+	fast bool) (*UploadResult, error) {
+	res := &UploadResult{
+		FileName: name,
+		FileHash: filehash,
+		FileSize: size,
+		Method:   "fast",
+	}
+
+	// 1. 秒传
+	if fast {
+		ok, err := u.Client.FastUpload(ctx, filehash, name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			u.progress(size)
+			return res, nil
+		}
+	}
+
+	// 2. 空文件无法分块上传, 以普通上传方式写入文件表后再秒传
+	if size == 0 {
+		if err := u.uploadEmpty(ctx, filehash, name); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	// 3. 分块上传
+	res.Method = "multipart"
+	chunksResumed, err := u.multipartUpload(ctx, r, size, filehash, name)
+	if err != nil {
+		return nil, err
+	}
+	res.ChunksResumed = chunksResumed
+	return res, nil
+}
+
+// uploadEmpty : 以临时文件名普通上传空文件, 再秒传到目标位置.
+// 普通上传接口只使用文件名的最后一部分, 不能直接上传到子目录
+func (u *Uploader) uploadEmpty(ctx context.Context, filehash, name string) error {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	tmpName := ".sdk-empty-" + hex.EncodeToString(suffix)
+
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	if _, err := mw.CreateFormFile("file", tmpName); err != nil {
+		return err
+	}
+	mw.Close()
+	err := u.Client.call(ctx, authBody(u.Client.uploadURL, "/file/upload", nil,
+		mw.FormDataContentType(), body.Bytes()), nil)
+	if err != nil {
+		return err
+	}
+	defer u.Client.Delete(ctx, tmpName)
+
+	ok, err := u.Client.FastUpload(ctx, filehash, name)
+	if err == nil && !ok {
+		err = errors.New("sdk: upload of empty file failed")
+	}
+	return err
+}
+
+// multipartUpload : 并发上传尚未上传的分块并通知合并, 返回续传时跳过的分块数
+func (u *Uploader) multipartUpload(ctx context.Context, r io.ReaderAt, size int64, filehash, name string) (int, error) {
+	upInfo, err := u.Client.InitMultipartUpload(ctx, filehash, size, u.ChunkSize)
+	if err != nil {
+		return 0, err
+	}
+
+	done := map[int]bool{}
+	var uploaded int64
+	for _, idx := range upInfo.ChunkExists {
+		done[idx] = true
+		uploaded += partLength(idx, upInfo.ChunkSize, size)
+	}
+	u.progress(uploaded)
+
+	concurrency := u.Concurrency
+	if concurrency <= 0 {
+		concurrency = upInfo.MaxParallel
+	}
+	err = runParts(ctx, upInfo.ChunkCount, done, concurrency, func(ctx context.Context, idx int) error {
+		n := partLength(idx, upInfo.ChunkSize, size)
+		buf := make([]byte, n)
+		if _, err := r.ReadAt(buf, int64(idx)*upInfo.ChunkSize); err != nil && err != io.EOF {
+			return err
+		}
+		err := retry(ctx, u.Retries, func() error {
+			return u.Client.UploadPart(ctx, upInfo.UploadID, idx, buf)
+		})
+		if err != nil {
+			return fmt.Errorf("chunk %d: %s", idx, err.Error())
+		}
+		u.progress(n)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = u.Client.CompleteMultipartUpload(ctx, upInfo.UploadID, filehash, size, name)
+	if err != nil {
+		return 0, err
+	}
+	return len(upInfo.ChunkExists), nil
+}
+
+func (u *Uploader) progress(n int64) {
+	if u.Progress == nil || n == 0 {
+		return
+	}
+	u.progressMu.Lock()
+	defer u.progressMu.Unlock()
+	u.Progress(n)
+}
+
+// SHA1 : 计算r中前size字节的sha1, progress非空时按读取进度回调
+func SHA1(ctx context.Context, r io.ReaderAt, size int64, progress func(n int64)) (string, error) {
+	hash := sha1.New()
+	buf := make([]byte, 1<<20)
+	for offset := int64(0); offset < size; {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		n := int64(len(buf))
+		if size-offset < n {
+			n = size - offset
+		}
+		read, err := r.ReadAt(buf[:n], offset)
+		if int64(read) < n {
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return "", err
+		}
+		hash.Write(buf[:n])
+		offset += n
+		if progress != nil {
+			progress(n)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// partLength : 第idx个分块的长度, 最后一块可能不足partSize
+func partLength(idx int, partSize, size int64) int64 {
+	if rest := size - int64(idx)*partSize; rest < partSize {
+		return rest
+	}
+	return partSize
+}
+
+// runParts : 以concurrency个goroutine并发处理[0, count)中不在done内的分块,
+// 任一分块失败时取消其余分块并返回第一个错误
+func runParts(ctx context.Context, count int, done map[int]bool, concurrency int,
+	fn func(ctx context.Context, idx int) error) error {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	indexes := make(chan int)
+	errs := make(chan error, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				if err := fn(ctx, idx); err != nil {
+					errs <- err
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+feed:
+	for idx := 0; idx < count; idx++ {
+		if done[idx] {
+			continue
+		}
+		select {
+		case indexes <- idx:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	select {
+	case err := <-errs:
+		return err
+	default:
+	}
+	return ctx.Err()
+}
+
+// retry : 失败时按1s, 2s, 4s...间隔重试, token失效或取消时不重试
+func retry(ctx context.Context, retries int, fn func() error) error {
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(time.Second << uint(attempt-1)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err = fn(); err == nil || err == ErrTokenExpired || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
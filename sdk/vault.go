@@ -0,0 +1,375 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrVaultReadOnly : 分享给自己的加密目录只能读取
+var ErrVaultReadOnly = errors.New("sdk: vault shared with you is read-only")
+
+// VaultInfo : 可访问的加密目录, Owner不是自己时为他人分享的目录
+type VaultInfo struct {
+	Owner      string
+	Folder     string
+	UserName   string
+	WrappedKey string
+	CreateAt   string
+}
+
+// VaultFile : 加密目录中的文件, Name为解密后相对于目录的路径, Size为明文大小
+type VaultFile struct {
+	Name string
+	Size int64
+	FileMeta
+}
+
+// Vault : 已解锁的加密目录. 文件名及内容在客户端加密, 服务端只保存密文
+type Vault struct {
+	Client *Client
+	Owner  string
+	Folder string
+	cipher *VaultCipher
+	keys   *VaultKeys
+	folder *[32]byte
+}
+
+// GetVaultKeyPair : 获取自己的密钥对(私钥为密文), user非空时获取该用户的公钥
+func (c *Client) GetVaultKeyPair(ctx context.Context, user string) (*VaultKeyPair, error) {
+	kp := &VaultKeyPair{}
+	err := c.call(ctx, authForm(c.apiURL, "/vault/keypair/get", url.Values{"user": {user}}), kp)
+	if err != nil {
+		return nil, err
+	}
+	return kp, nil
+}
+
+// SetVaultKeyPair : 保存密钥对. 已设置过时公钥须不变, 只能更新加密的私钥(修改口令)
+func (c *Client) SetVaultKeyPair(ctx context.Context, kp *VaultKeyPair) error {
+	return c.call(ctx, authForm(c.apiURL, "/vault/keypair/set", url.Values{
+		"publickey": {kp.PublicKey},
+		"secretkey": {kp.SecretKey},
+		"kdfsalt":   {kp.KDFSalt},
+		"kdfparams": {kp.KDFParams},
+	}), nil)
+}
+
+// SetupVaultKeys : 首次使用加密目录时生成密钥对, 私钥以口令加密后保存到服务端
+func (c *Client) SetupVaultKeys(ctx context.Context, passphrase string) (*VaultKeys, error) {
+	keys, err := NewVaultKeys()
+	if err != nil {
+		return nil, err
+	}
+	kp, err := keys.Seal(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.SetVaultKeyPair(ctx, kp); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// UnlockVaultKeys : 从服务端获取密钥对并以口令解密私钥
+func (c *Client) UnlockVaultKeys(ctx context.Context, passphrase string) (*VaultKeys, error) {
+	kp, err := c.GetVaultKeyPair(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return OpenVaultKeys(kp, passphrase)
+}
+
+// ChangeVaultPassphrase : 修改口令, 密钥对不变, 已有的目录及分享不受影响
+func (c *Client) ChangeVaultPassphrase(ctx context.Context, oldPassphrase, newPassphrase string) error {
+	keys, err := c.UnlockVaultKeys(ctx, oldPassphrase)
+	if err != nil {
+		return err
+	}
+	kp, err := keys.Seal(newPassphrase)
+	if err != nil {
+		return err
+	}
+	return c.SetVaultKeyPair(ctx, kp)
+}
+
+// ListVaults : 获取自己的及他人分享给自己的加密目录
+func (c *Client) ListVaults(ctx context.Context) ([]VaultInfo, error) {
+	vaults := []VaultInfo{}
+	if err := c.call(ctx, authForm(c.apiURL, "/vault/list", nil), &vaults); err != nil {
+		return nil, err
+	}
+	return vaults, nil
+}
+
+// CreateVault : 将空目录folder设为加密目录, 生成目录密钥并以自己的公钥加密后保存
+func (c *Client) CreateVault(ctx context.Context, keys *VaultKeys, folder string) (*Vault, error) {
+	folder = vaultFolder(folder)
+	folderKey, err := NewFolderKey()
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := WrapFolderKey(folderKey, keys.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+	err = c.call(ctx, authForm(c.apiURL, "/vault/create", url.Values{
+		"folder":     {folder},
+		"wrappedkey": {wrapped},
+	}), nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.newVault(keys, c.Session().Username, folder, folderKey), nil
+}
+
+// OpenVault : 打开owner的加密目录folder, owner为空表示自己
+func (c *Client) OpenVault(ctx context.Context, keys *VaultKeys, owner, folder string) (*Vault, error) {
+	if owner == "" {
+		owner = c.Session().Username
+	}
+	folder = vaultFolder(folder)
+	vaults, err := c.ListVaults(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range vaults {
+		if v.Owner != owner || v.Folder != folder {
+			continue
+		}
+		folderKey, err := keys.UnwrapFolderKey(v.WrappedKey)
+		if err != nil {
+			return nil, err
+		}
+		return c.newVault(keys, owner, folder, folderKey), nil
+	}
+	return nil, ErrNotFound
+}
+
+func (c *Client) newVault(keys *VaultKeys, owner, folder string, folderKey *[32]byte) *Vault {
+	return &Vault{
+		Client: c,
+		Owner:  owner,
+		Folder: folder,
+		cipher: NewVaultCipher(folderKey),
+		keys:   keys,
+		folder: folderKey,
+	}
+}
+
+// vaultFolder : 加密目录的格式为不以"/"开头、以"/"结尾
+func vaultFolder(folder string) string {
+	return strings.Trim(folder, "/") + "/"
+}
+
+// shared : 是否为他人分享给自己的目录
+func (v *Vault) shared() bool {
+	return v.Owner != v.Client.Session().Username
+}
+
+// Path : 目录中相对路径name对应的服务端文件名(逐级加密)
+func (v *Vault) Path(name string) string {
+	return v.Folder + v.cipher.EncryptPath(strings.TrimPrefix(name, "/"))
+}
+
+// Name : 服务端文件名对应的相对路径(解密)
+func (v *Vault) Name(serverPath string) (string, error) {
+	if !strings.HasPrefix(serverPath, v.Folder) {
+		return "", fmt.Errorf("sdk: %s is not in vault %s", serverPath, v.Folder)
+	}
+	return v.cipher.DecryptPath(strings.TrimPrefix(serverPath, v.Folder))
+}
+
+// Share : 以user的公钥加密目录密钥, 分享目录给user
+func (v *Vault) Share(ctx context.Context, user string) error {
+	if v.shared() {
+		return ErrVaultReadOnly
+	}
+	kp, err := v.Client.GetVaultKeyPair(ctx, user)
+	if err != nil {
+		return err
+	}
+	wrapped, err := WrapFolderKey(v.folder, kp.PublicKey)
+	if err != nil {
+		return err
+	}
+	return v.Client.call(ctx, authForm(v.Client.apiURL, "/vault/share", url.Values{
+		"folder":     {v.Folder},
+		"user":       {user},
+		"wrappedkey": {wrapped},
+	}), nil)
+}
+
+// Unshare : 取消对user的分享. 对方已下载的数据及已知的目录密钥无法收回
+func (v *Vault) Unshare(ctx context.Context, user string) error {
+	if v.shared() {
+		return ErrVaultReadOnly
+	}
+	return v.Client.call(ctx, authForm(v.Client.apiURL, "/vault/unshare", url.Values{
+		"folder": {v.Folder},
+		"user":   {user},
+	}), nil)
+}
+
+// Members : 可访问该目录的用户(含自己)
+func (v *Vault) Members(ctx context.Context) ([]string, error) {
+	members := []VaultInfo{}
+	err := v.Client.call(ctx, authForm(v.Client.apiURL, "/vault/members", url.Values{
+		"folder": {v.Folder},
+	}), &members)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]string, 0, len(members))
+	for _, m := range members {
+		users = append(users, m.UserName)
+	}
+	return users, nil
+}
+
+// List : 按文件名顺序列出目录中以相对路径prefix(须为目录, 空为整个目录)开头的全部文件
+func (v *Vault) List(ctx context.Context, prefix string) ([]VaultFile, error) {
+	serverPrefix := v.Path(prefix)
+	files := []VaultFile{}
+	form := url.Values{
+		"owner":  {v.Owner},
+		"prefix": {serverPrefix},
+	}
+	for {
+		resp := &ListFilesResponse{}
+		if err := v.Client.call(ctx, authForm(v.Client.apiURL, "/vault/files", form), resp); err != nil {
+			return nil, err
+		}
+		for _, f := range resp.Files {
+			// 前缀匹配不区分大小写, 只保留密文完全匹配的文件; 加密目录自身的目录记录不返回
+			if !strings.HasPrefix(f.FileName, serverPrefix) || f.FileName == v.Folder {
+				continue
+			}
+			name, err := v.Name(f.FileName)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", f.FileName, err.Error())
+			}
+			files = append(files, VaultFile{Name: name, Size: VaultPlainSize(f.FileSize), FileMeta: f})
+		}
+		if resp.NextMarker == "" {
+			return files, nil
+		}
+		form.Set("marker", resp.NextMarker)
+	}
+}
+
+// Stat : 按相对路径查询文件, 不存在时返回ErrNotFound
+func (v *Vault) Stat(ctx context.Context, name string) (*VaultFile, error) {
+	serverName := v.Path(name)
+	dir := ""
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		dir = name[:i+1]
+	}
+	files, err := v.List(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
+		if files[i].FileName == serverName {
+			return &files[i], nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// UploadFile : 加密本地文件local并上传为目录中的name. 密文先写入临时文件, 不尝试秒传
+func (v *Vault) UploadFile(ctx context.Context, u *Uploader, local, name string) (*UploadResult, error) {
+	if v.shared() {
+		return nil, ErrVaultReadOnly
+	}
+	src, err := os.Open(local)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	tmp, err := ioutil.TempFile(filepath.Dir(local), "."+filepath.Base(local)+".vault-")
+	if err != nil {
+		tmp, err = ioutil.TempFile("", "vault-")
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := v.cipher.Encrypt(tmp, src)
+	if err != nil {
+		return nil, err
+	}
+	filehash, err := SHA1(ctx, tmp, size, nil)
+	if err != nil {
+		return nil, err
+	}
+	return u.upload(ctx, tmp, size, filehash, v.Path(name), false)
+}
+
+// Open : 读取并解密目录中的文件name, 数据被截断或篡改时读取返回ErrVaultCorrupt
+func (v *Vault) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	f, err := v.Stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.Client.doRaw(ctx, authGet(v.Client.downloadURL, "/file/download", url.Values{
+		"filehash": {f.FileHash},
+		"owner":    {v.Owner},
+	}, nil))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, newHTTPError(resp.Request, resp.StatusCode, body)
+	}
+	r, err := v.cipher.NewDecryptReader(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return limitReadCloser{r, resp.Body}, nil
+}
+
+// DownloadFile : 下载并解密目录中的文件name到本地路径local, 返回明文大小.
+// 数据先写入local+PartFileSuffix, 全部通过认证后再重命名为local
+func (v *Vault) DownloadFile(ctx context.Context, name, local string) (int64, error) {
+	r, err := v.Open(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	partPath := local + PartFileSuffix
+	fd, err := os.OpenFile(partPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(fd, r)
+	if cerr := fd.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(partPath)
+		return n, err
+	}
+	return n, os.Rename(partPath, local)
+}
+
+// Remove : 删除目录中的文件name
+func (v *Vault) Remove(ctx context.Context, name string) error {
+	if v.shared() {
+		return ErrVaultReadOnly
+	}
+	return v.Client.Delete(ctx, v.Path(name))
+}
@@ -0,0 +1,435 @@
+package sdk
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// 加密目录的客户端加密:
+//   - 用户口令经scrypt派生密钥, 用于加密用户的X25519私钥; 服务端只保存公钥及加密后的私钥
+//   - 每个加密目录有随机的目录密钥, 以各可访问用户的公钥加密(匿名box)后保存在服务端
+//   - 目录中的文件名逐级以目录密钥确定性加密(相同的名字密文相同, 以便按名字查找)
+//   - 文件内容按块以AES-256-GCM加密, 每个文件使用由目录密钥及随机salt派生的密钥
+
+const (
+	// VaultChunkSize : 内容加密的明文分块大小
+	VaultChunkSize = 64 << 10
+	// vaultMagic/vaultVersion : 加密内容的文件头
+	vaultMagic   = "CVLT"
+	vaultVersion = 1
+	// vaultSaltSize : 文件头中用于派生内容密钥的随机salt长度
+	vaultSaltSize   = 16
+	vaultHeaderSize = len(vaultMagic) + 1 + vaultSaltSize
+	// vaultTagSize : GCM认证标签长度
+	vaultTagSize = 16
+	// scrypt参数, 保存在密钥对中以便日后调整
+	vaultScryptN = 1 << 15
+	vaultScryptR = 8
+	vaultScryptP = 1
+)
+
+var (
+	// ErrVaultPassphrase : 口令错误, 无法解密私钥
+	ErrVaultPassphrase = errors.New("sdk: wrong vault passphrase")
+	// ErrVaultCorrupt : 加密的数据被截断或篡改, 或不是以该目录密钥加密的
+	ErrVaultCorrupt = errors.New("sdk: vault data corrupt or tampered")
+)
+
+// vaultNameEncoding : 文件名密文的编码. 服务端文件名比较不区分大小写, 使用小写的base32
+var vaultNameEncoding = base32.HexEncoding.WithPadding(base32.NoPadding)
+
+// VaultKeyPair : 服务端保存的加密目录密钥对, SecretKey为以口令派生的密钥加密的私钥
+type VaultKeyPair struct {
+	UserName  string
+	PublicKey string
+	SecretKey string
+	KDFSalt   string
+	KDFParams string
+}
+
+// VaultKeys : 解锁后的密钥对, 用于解密分享给自己的目录密钥
+type VaultKeys struct {
+	publicKey [32]byte
+	secretKey [32]byte
+}
+
+// NewVaultKeys : 生成新的密钥对
+func NewVaultKeys() (*VaultKeys, error) {
+	pub, priv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &VaultKeys{publicKey: *pub, secretKey: *priv}, nil
+}
+
+// PublicKey : base64编码的公钥
+func (k *VaultKeys) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(k.publicKey[:])
+}
+
+// Seal : 以口令派生的密钥加密私钥, 返回可保存到服务端的密钥对
+func (k *VaultKeys) Seal(passphrase string) (*VaultKeyPair, error) {
+	salt := make([]byte, vaultSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	params := fmt.Sprintf("scrypt:N=%d,r=%d,p=%d", vaultScryptN, vaultScryptR, vaultScryptP)
+	kek, err := deriveVaultKEK(passphrase, salt, params)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	sealed := secretbox.Seal(nonce[:], k.secretKey[:], &nonce, kek)
+	return &VaultKeyPair{
+		PublicKey: k.PublicKey(),
+		SecretKey: base64.StdEncoding.EncodeToString(sealed),
+		KDFSalt:   base64.StdEncoding.EncodeToString(salt),
+		KDFParams: params,
+	}, nil
+}
+
+// OpenVaultKeys : 以口令解密密钥对中的私钥, 口令错误时返回ErrVaultPassphrase
+func OpenVaultKeys(kp *VaultKeyPair, passphrase string) (*VaultKeys, error) {
+	pub, err := decodeKey(kp.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	salt, err := base64.StdEncoding.DecodeString(kp.KDFSalt)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(kp.SecretKey)
+	if err != nil || len(sealed) < 24 {
+		return nil, ErrVaultCorrupt
+	}
+	kek, err := deriveVaultKEK(passphrase, salt, kp.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	copy(nonce[:], sealed[:24])
+	priv, ok := secretbox.Open(nil, sealed[24:], &nonce, kek)
+	if !ok || len(priv) != 32 {
+		return nil, ErrVaultPassphrase
+	}
+
+	// 私钥须与公钥对应, 防止服务端替换公钥
+	keys := &VaultKeys{publicKey: *pub}
+	copy(keys.secretKey[:], priv)
+	derived, err := curve25519.X25519(keys.secretKey[:], curve25519.Basepoint)
+	if err != nil || !hmac.Equal(derived, pub[:]) {
+		return nil, ErrVaultCorrupt
+	}
+	return keys, nil
+}
+
+// deriveVaultKEK : 按params(scrypt:N=..,r=..,p=..)由口令派生加密私钥的密钥
+func deriveVaultKEK(passphrase string, salt []byte, params string) (*[32]byte, error) {
+	var n, r, p int
+	if _, err := fmt.Sscanf(params, "scrypt:N=%d,r=%d,p=%d", &n, &r, &p); err != nil {
+		return nil, fmt.Errorf("sdk: unsupported kdf params %q", params)
+	}
+	dk, err := scrypt.Key([]byte(passphrase), salt, n, r, p, 32)
+	if err != nil {
+		return nil, err
+	}
+	kek := &[32]byte{}
+	copy(kek[:], dk)
+	return kek, nil
+}
+
+// NewFolderKey : 生成新的目录密钥
+func NewFolderKey() (*[32]byte, error) {
+	key := &[32]byte{}
+	if _, err := rand.Read(key[:]); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// WrapFolderKey : 以base64编码的公钥加密目录密钥, 只有对应私钥的持有者可以解密
+func WrapFolderKey(folderKey *[32]byte, publicKey string) (string, error) {
+	pub, err := decodeKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := box.SealAnonymous(nil, folderKey[:], pub, rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(wrapped), nil
+}
+
+// UnwrapFolderKey : 以自己的私钥解密目录密钥
+func (k *VaultKeys) UnwrapFolderKey(wrapped string) (*[32]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, ErrVaultCorrupt
+	}
+	key, ok := box.OpenAnonymous(nil, data, &k.publicKey, &k.secretKey)
+	if !ok || len(key) != 32 {
+		return nil, ErrVaultCorrupt
+	}
+	folderKey := &[32]byte{}
+	copy(folderKey[:], key)
+	return folderKey, nil
+}
+
+func decodeKey(s string) (*[32]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(data) != 32 {
+		return nil, errors.New("sdk: invalid vault public key")
+	}
+	key := &[32]byte{}
+	copy(key[:], data)
+	return key, nil
+}
+
+// VaultCipher : 以目录密钥加密文件名及文件内容
+type VaultCipher struct {
+	nameAEAD   cipher.AEAD
+	nameIVKey  []byte
+	contentKey []byte
+}
+
+// NewVaultCipher : 由目录密钥派生文件名及内容的密钥
+func NewVaultCipher(folderKey *[32]byte) *VaultCipher {
+	nameAEAD, _ := newGCM(subKey(folderKey[:], "name"))
+	return &VaultCipher{
+		nameAEAD:   nameAEAD,
+		nameIVKey:  subKey(folderKey[:], "name-iv"),
+		contentKey: subKey(folderKey[:], "content"),
+	}
+}
+
+// subKey : HMAC-SHA256(key, label)
+func subKey(key []byte, label string, extra ...[]byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(label))
+	for _, e := range extra {
+		mac.Write(e)
+	}
+	return mac.Sum(nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptName : 确定性加密一级文件名, nonce由文件名的HMAC得到, 相同的名字密文相同
+func (vc *VaultCipher) EncryptName(name string) string {
+	nonce := subKey(vc.nameIVKey, name)[:vc.nameAEAD.NonceSize()]
+	sealed := vc.nameAEAD.Seal(nonce, nonce, []byte(name), nil)
+	return strings.ToLower(vaultNameEncoding.EncodeToString(sealed))
+}
+
+// DecryptName : 解密一级文件名
+func (vc *VaultCipher) DecryptName(enc string) (string, error) {
+	sealed, err := vaultNameEncoding.DecodeString(strings.ToUpper(enc))
+	if err != nil || len(sealed) < vc.nameAEAD.NonceSize() {
+		return "", ErrVaultCorrupt
+	}
+	size := vc.nameAEAD.NonceSize()
+	name, err := vc.nameAEAD.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return "", ErrVaultCorrupt
+	}
+	return string(name), nil
+}
+
+// EncryptPath : 逐级加密相对路径, 目录保留末尾的"/"
+func (vc *VaultCipher) EncryptPath(rel string) string {
+	parts := strings.Split(rel, "/")
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = vc.EncryptName(p)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// DecryptPath : 逐级解密相对路径
+func (vc *VaultCipher) DecryptPath(enc string) (string, error) {
+	parts := strings.Split(enc, "/")
+	for i, p := range parts {
+		if p == "" {
+			continue
+		}
+		name, err := vc.DecryptName(p)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = name
+	}
+	return strings.Join(parts, "/"), nil
+}
+
+// chunkNonce : 第index块的nonce, 最后一块的末字节为1, 以发现截断或块被重排
+func chunkNonce(index uint64, final bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], index)
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// Encrypt : 加密src写入dst, 返回写入的密文长度. 空内容也会写入一个最后块
+func (vc *VaultCipher) Encrypt(dst io.Writer, src io.Reader) (int64, error) {
+	header := make([]byte, vaultHeaderSize)
+	copy(header, vaultMagic)
+	header[len(vaultMagic)] = vaultVersion
+	if _, err := rand.Read(header[len(vaultMagic)+1:]); err != nil {
+		return 0, err
+	}
+	aead, err := newGCM(subKey(vc.contentKey, "file", header[len(vaultMagic)+1:]))
+	if err != nil {
+		return 0, err
+	}
+	if _, err := dst.Write(header); err != nil {
+		return 0, err
+	}
+	written := int64(len(header))
+
+	br := bufio.NewReaderSize(src, VaultChunkSize)
+	plain := make([]byte, VaultChunkSize)
+	sealed := make([]byte, 0, VaultChunkSize+vaultTagSize)
+	for index := uint64(0); ; index++ {
+		n, err := io.ReadFull(br, plain)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return written, err
+		}
+		final := n < len(plain)
+		if !final {
+			// 恰好读满一块时, 后面没有数据则为最后一块
+			if _, perr := br.Peek(1); perr == io.EOF {
+				final = true
+			} else if perr != nil {
+				return written, perr
+			}
+		}
+		sealed = aead.Seal(sealed[:0], chunkNonce(index, final), plain[:n], header)
+		if _, err := dst.Write(sealed); err != nil {
+			return written, err
+		}
+		written += int64(len(sealed))
+		if final {
+			return written, nil
+		}
+	}
+}
+
+// VaultEncryptedSize : 明文长度对应的密文长度
+func VaultEncryptedSize(size int64) int64 {
+	chunks := (size + VaultChunkSize - 1) / VaultChunkSize
+	if chunks == 0 {
+		chunks = 1
+	}
+	return int64(vaultHeaderSize) + size + chunks*vaultTagSize
+}
+
+// VaultPlainSize : 密文长度对应的明文长度, 用于显示加密目录中的文件大小
+func VaultPlainSize(size int64) int64 {
+	body := size - int64(vaultHeaderSize)
+	if body < vaultTagSize {
+		return 0
+	}
+	block := int64(VaultChunkSize + vaultTagSize)
+	chunks := (body + block - 1) / block
+	return body - chunks*vaultTagSize
+}
+
+// NewDecryptReader : 解密以Encrypt加密的内容, 数据被截断或篡改时读取返回ErrVaultCorrupt
+func (vc *VaultCipher) NewDecryptReader(src io.Reader) (io.Reader, error) {
+	header := make([]byte, vaultHeaderSize)
+	if _, err := io.ReadFull(src, header); err != nil {
+		return nil, ErrVaultCorrupt
+	}
+	if string(header[:len(vaultMagic)]) != vaultMagic || header[len(vaultMagic)] != vaultVersion {
+		return nil, ErrVaultCorrupt
+	}
+	aead, err := newGCM(subKey(vc.contentKey, "file", header[len(vaultMagic)+1:]))
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{
+		src:    bufio.NewReaderSize(src, VaultChunkSize+vaultTagSize),
+		aead:   aead,
+		header: header,
+		sealed: make([]byte, VaultChunkSize+vaultTagSize),
+	}, nil
+}
+
+// decryptReader : 逐块解密, 只返回已通过认证的数据
+type decryptReader struct {
+	src    *bufio.Reader
+	aead   cipher.AEAD
+	header []byte
+	sealed []byte
+	plain  []byte
+	index  uint64
+	done   bool
+	err    error
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		if d.done {
+			return 0, io.EOF
+		}
+		d.err = d.next()
+	}
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+// next : 读取并解密下一块, 没有后续数据的块须以最后块的nonce加密
+func (d *decryptReader) next() error {
+	n, err := io.ReadFull(d.src, d.sealed)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	final := n < len(d.sealed)
+	if !final {
+		if _, perr := d.src.Peek(1); perr == io.EOF {
+			final = true
+		} else if perr != nil {
+			return perr
+		}
+	}
+	plain, err := d.aead.Open(d.sealed[:0], chunkNonce(d.index, final), d.sealed[:n], d.header)
+	if err != nil {
+		return ErrVaultCorrupt
+	}
+	d.plain = plain
+	d.index++
+	d.done = final
+	return nil
+}
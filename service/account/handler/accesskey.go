@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	proto "github.com/cloud/service/account/proto"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/util"
+)
+
+// GenAccessKey : 生成S3访问密钥对, AccessKey为20位大写字符, SecretKey为40位字符
+func GenAccessKey() (string, string, error) {
+	accessKey, err := util.RandomHex(10)
+	if err != nil {
+		return "", "", err
+	}
+	secretKey, err := util.RandomHex(20)
+	if err != nil {
+		return "", "", err
+	}
+	return strings.ToUpper(accessKey), secretKey, nil
+}
+
+// CreateAccessKey : 为用户签发新的S3访问密钥
+func (user *User) CreateAccessKey(ctx context.Context, req *proto.ReqCreateAccessKey, res *proto.RespCreateAccessKey) error {
+	// 1. 检查用户已有的密钥数量
+	dbResp, err := dbcli.ListAccessKeys(req.Username)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if len(dbcli.ToTableAccessKeys(dbResp.Data)) >= config.AccessKeyLimit {
+		res.Code = common.StatusParamInvalid
+		res.Message = "密钥数量已达上限"
+		return nil
+	}
+
+	// 2. 生成并保存密钥
+	accessKey, secretKey, err := GenAccessKey()
+	if err != nil {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	dbResp, err = dbcli.CreateAccessKey(req.Username, accessKey, secretKey)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+
+	// 3. SecretKey只在创建时返回一次
+	res.Code = common.StatusOK
+	res.AccessKey = accessKey
+	res.SecretKey = secretKey
+	return nil
+}
+
+// ListAccessKeys : 获取用户的S3访问密钥列表(不含SecretKey)
+func (user *User) ListAccessKeys(ctx context.Context, req *proto.ReqListAccessKeys, res *proto.RespListAccessKeys) error {
+	dbResp, err := dbcli.ListAccessKeys(req.Username)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+
+	keys := dbcli.ToTableAccessKeys(dbResp.Data)
+	data, err := json.Marshal(keys)
+	if err != nil {
+		res.Code = common.StatusServerError
+		return nil
+	}
+
+	res.Code = common.StatusOK
+	res.KeyData = data
+	return nil
+}
+
+// DeleteAccessKey : 删除用户的S3访问密钥
+func (user *User) DeleteAccessKey(ctx context.Context, req *proto.ReqDeleteAccessKey, res *proto.RespDeleteAccessKey) error {
+	dbResp, err := dbcli.DeleteAccessKey(req.Username, req.AccessKey)
+	if err != nil {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if !dbResp.Suc {
+		res.Code = common.StatusParamInvalid
+		res.Message = dbResp.Msg
+		return nil
+	}
+	res.Code = common.StatusOK
+	return nil
+}
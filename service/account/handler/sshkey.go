@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	proto "github.com/cloud/service/account/proto"
+	dbcli "github.com/cloud/service/dbproxy/client"
+)
+
+// AddSSHKey : 为用户添加SSH公钥(authorized_keys格式), 用于SFTP公钥登录
+func (user *User) AddSSHKey(ctx context.Context, req *proto.ReqAddSSHKey, res *proto.RespAddSSHKey) error {
+	// 1. 解析公钥, 统一保存为"类型 base64"格式
+	pubKey, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(req.PublicKey))
+	if err != nil {
+		res.Code = common.StatusParamInvalid
+		res.Message = "公钥格式无效"
+		return nil
+	}
+	fingerprint := ssh.FingerprintSHA256(pubKey)
+	authorizedKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pubKey)))
+
+	// 2. 检查公钥数量及是否重复添加
+	dbResp, err := dbcli.ListSSHKeys(req.Username)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	keys := dbcli.ToTableSSHKeys(dbResp.Data)
+	if len(keys) >= config.SSHKeyLimit {
+		res.Code = common.StatusParamInvalid
+		res.Message = "公钥数量已达上限"
+		return nil
+	}
+	for _, key := range keys {
+		if key.Fingerprint == fingerprint {
+			res.Code = common.StatusParamInvalid
+			res.Message = "公钥已存在"
+			return nil
+		}
+	}
+
+	// 3. 保存公钥
+	dbResp, err = dbcli.AddSSHKey(req.Username, comment, fingerprint, authorizedKey)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	res.Code = common.StatusOK
+	res.Fingerprint = fingerprint
+	return nil
+}
+
+// ListSSHKeys : 获取用户的SSH公钥列表
+func (user *User) ListSSHKeys(ctx context.Context, req *proto.ReqListSSHKeys, res *proto.RespListSSHKeys) error {
+	dbResp, err := dbcli.ListSSHKeys(req.Username)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+
+	keys := dbcli.ToTableSSHKeys(dbResp.Data)
+	data, err := json.Marshal(keys)
+	if err != nil {
+		res.Code = common.StatusServerError
+		return nil
+	}
+
+	res.Code = common.StatusOK
+	res.KeyData = data
+	return nil
+}
+
+// DeleteSSHKey : 按指纹删除用户的SSH公钥
+func (user *User) DeleteSSHKey(ctx context.Context, req *proto.ReqDeleteSSHKey, res *proto.RespDeleteSSHKey) error {
+	dbResp, err := dbcli.DeleteSSHKey(req.Username, req.Fingerprint)
+	if err != nil {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if !dbResp.Suc {
+		res.Code = common.StatusParamInvalid
+		res.Message = dbResp.Msg
+		return nil
+	}
+	res.Code = common.StatusOK
+	return nil
+}
@@ -77,6 +77,25 @@ func (user *User) Signin(ctx context.Context, req *proto.ReqSignin, res *proto.R
 	return nil
 }
 
+// Authenticate : 校验用户名及密码, 与Signin不同的是不会生成新的token, 避免使网页端登录失效
+func (user *User) Authenticate(ctx context.Context, req *proto.ReqAuthenticate, res *proto.RespAuthenticate) error {
+	encPasswd := util.Sha1([]byte(req.Password + config.Password_salt))
+
+	dbResp, err := DBcli.UserSignin(req.Username, encPasswd)
+	if err != nil {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if !dbResp.Suc {
+		res.Code = common.StatusLoginFailed
+		res.Message = "用户名/密码不匹配"
+		return nil
+	}
+	res.Code = common.StatusOK
+	return nil
+}
+
 // UserInfo ： 查询用户信息
 func (user *User) UserInfo(ctx context.Context, req *proto.ReqUserInfo, res *proto.RespUserInfo) error {
 	// 查询用户信息
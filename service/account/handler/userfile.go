@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"github.com/cloud/common"
+	"github.com/cloud/config"
 	proto "github.com/cloud/service/account/proto"
 	dbcli "github.com/cloud/service/dbproxy/client"
 )
@@ -29,11 +30,53 @@ func (user *User) UserFiles(ctx context.Context, req *proto.ReqUserFile, res *pr
 
 // UserFiles : 用户文件重命名
 func (user *User) UserFileRename(ctx context.Context, req *proto.ReqUserFileRename, res *proto.RespUserFileRename) error {
-	dbResp, err := dbcli.RenameFileName(req.Username, req.Filehash, req.NewFileName)
+	dbResp, err := dbcli.QueryUserFileMeta(req.Username, req.Filehash)
 	if err != nil || !dbResp.Suc {
 		res.Code = common.StatusServerError
 		return err
 	}
+	if dbResp.Data != nil {
+		same, err := sameVault(req.Username, dbcli.ToTableUserFile(dbResp.Data).FileName, req.NewFileName)
+		if err != nil {
+			res.Code = common.StatusServerError
+			return nil
+		}
+		if !same {
+			res.Code = common.StatusVaultUnsupported
+			res.Message = "不能移入或移出加密目录"
+			return nil
+		}
+	}
+
+	dbResp, err = dbcli.RenameFileName(req.Username, req.Filehash, req.NewFileName)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		return err
+	}
+
+	userFiles := dbcli.ToTableUserFiles(dbResp.Data)
+	data, err := json.Marshal(userFiles)
+	if err != nil {
+		res.Code = common.StatusServerError
+		return nil
+	}
+
+	res.FileData = data
+	return nil
+}
+
+// UserFileList : 按目录前缀分页获取用户文件, 按文件名字节序返回marker之后的文件
+func (user *User) UserFileList(ctx context.Context, req *proto.ReqUserFileList, res *proto.RespUserFileList) error {
+	limit := int(req.Limit)
+	if limit <= 0 || limit > config.FileListLimit {
+		limit = config.FileListLimit
+	}
+	dbResp, err := dbcli.ListUserFilesByPrefix(req.Username, req.Prefix, req.Marker, limit)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
 
 	userFiles := dbcli.ToTableUserFiles(dbResp.Data)
 	data, err := json.Marshal(userFiles)
@@ -42,7 +85,153 @@ func (user *User) UserFileRename(ctx context.Context, req *proto.ReqUserFileRena
 		return nil
 	}
 
+	res.Code = common.StatusOK
 	res.FileData = data
 	return nil
 }
 
+// UserFileDelete : 按文件名删除用户文件
+func (user *User) UserFileDelete(ctx context.Context, req *proto.ReqUserFileDelete, res *proto.RespUserFileDelete) error {
+	dbResp, err := dbcli.QueryUserFileByName(req.Username, req.FileName)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if dbResp.Data == nil {
+		res.Code = common.StatusParamInvalid
+		res.Message = "文件不存在"
+		return nil
+	}
+
+	dbResp, err = dbcli.DeleteUserFileByName(req.Username, req.FileName)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	res.Code = common.StatusOK
+	return nil
+}
+
+// UserFileMove : 按文件名移动(重命名)用户文件, 目标文件须不存在
+func (user *User) UserFileMove(ctx context.Context, req *proto.ReqUserFileMove, res *proto.RespUserFileMove) error {
+	if req.DestName == "" || req.DestName == req.SrcName {
+		res.Code = common.StatusParamInvalid
+		res.Message = "目标文件名无效"
+		return nil
+	}
+	dbResp, err := dbcli.QueryUserFileByName(req.Username, req.DestName)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if dbResp.Data != nil {
+		res.Code = common.FileAlreadExists
+		res.Message = "目标文件已存在"
+		return nil
+	}
+
+	// 加密目录中的文件名为密文, 不能与明文目录之间移动
+	same, err := sameVault(req.Username, req.SrcName, req.DestName)
+	if err != nil {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if !same {
+		res.Code = common.StatusVaultUnsupported
+		res.Message = "不能移入或移出加密目录"
+		return nil
+	}
+
+	dbResp, err = dbcli.RenameUserFileByName(req.Username, req.SrcName, req.DestName)
+	if err != nil {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if !dbResp.Suc {
+		res.Code = common.StatusParamInvalid
+		res.Message = dbResp.Msg
+		return nil
+	}
+	res.Code = common.StatusOK
+	return nil
+}
+
+// UserUsage : 获取用户的文件数及占用空间
+func (user *User) UserUsage(ctx context.Context, req *proto.ReqUserUsage, res *proto.RespUserUsage) error {
+	dbResp, err := dbcli.GetUserFileUsage(req.Username)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+
+	usage := dbcli.ToTableUserUsage(dbResp.Data)
+	res.Code = common.StatusOK
+	res.FileCount = usage.FileCount
+	res.TotalSize = usage.TotalSize
+	return nil
+}
+
+// UserFileRestore : 按文件名恢复已删除的用户文件
+func (user *User) UserFileRestore(ctx context.Context, req *proto.ReqUserFileRestore, res *proto.RespUserFileRestore) error {
+	dbResp, err := dbcli.RestoreUserFile(req.Username, req.FileName)
+	if err != nil {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if !dbResp.Suc {
+		res.Code = common.StatusParamInvalid
+		res.Message = dbResp.Msg
+		return nil
+	}
+	res.Code = common.StatusOK
+	return nil
+}
+
+// UserFileChanges : 按顺序获取游标之后的用户文件变更事件, 游标小于0时只返回最新游标
+func (user *User) UserFileChanges(ctx context.Context, req *proto.ReqUserFileChanges, res *proto.RespUserFileChanges) error {
+	if req.Cursor < 0 {
+		cursor, err := dbcli.GetUserFileEventCursor(req.Username)
+		if err != nil {
+			res.Code = common.StatusServerError
+			res.Message = "服务错误"
+			return nil
+		}
+		res.Code = common.StatusOK
+		res.Events = []byte("[]")
+		res.Cursor = cursor
+		return nil
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 || limit > config.FileChangesLimit {
+		limit = config.FileChangesLimit
+	}
+	dbResp, err := dbcli.ListUserFileEvents(req.Username, req.Cursor, limit)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+
+	events := dbcli.ToTableUserFileEvents(dbResp.Data)
+	data, err := json.Marshal(events)
+	if err != nil {
+		res.Code = common.StatusServerError
+		return nil
+	}
+
+	res.Code = common.StatusOK
+	res.Events = data
+	res.Cursor = req.Cursor
+	if len(events) > 0 {
+		res.Cursor = events[len(events)-1].ID
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	proto "github.com/cloud/service/account/proto"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/service/dbproxy/orm"
+)
+
+// 加密目录中的文件内容及文件名由客户端以目录密钥加密, 服务端只保存密文;
+// 目录密钥以各可访问用户的公钥加密后保存, 用户私钥以其口令派生的密钥加密后保存
+
+// maxWrappedKeyLen : 加密后的密钥(base64)的最大长度, 与表字段一致
+const maxWrappedKeyLen = 255
+
+// SetVaultKeyPair : 首次设置加密目录密钥对; 已设置时只允许公钥不变地更新加密的私钥(修改口令)
+func (user *User) SetVaultKeyPair(ctx context.Context, req *proto.ReqSetVaultKeyPair, res *proto.RespSetVaultKeyPair) error {
+	if req.PublicKey == "" || req.SecretKey == "" || len(req.SecretKey) > maxWrappedKeyLen {
+		res.Code = common.StatusParamInvalid
+		res.Message = "密钥无效"
+		return nil
+	}
+
+	dbResp, err := dbcli.GetVaultKeyPair(req.Username)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if dbResp.Data == nil {
+		dbResp, err = dbcli.CreateVaultKeyPair(req.Username, req.PublicKey, req.SecretKey, req.KdfSalt, req.KdfParams)
+	} else {
+		// 更换公钥会使已分享的目录密钥无法解密
+		dbResp, err = dbcli.UpdateVaultSecretKey(req.Username, req.PublicKey, req.SecretKey, req.KdfSalt, req.KdfParams)
+	}
+	if err != nil {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if !dbResp.Suc {
+		res.Code = common.StatusParamInvalid
+		res.Message = dbResp.Msg
+		return nil
+	}
+	res.Code = common.StatusOK
+	return nil
+}
+
+// GetVaultKeyPair : 获取自己的密钥对(私钥为密文), 或其他用户的公钥(用于分享)
+func (user *User) GetVaultKeyPair(ctx context.Context, req *proto.ReqGetVaultKeyPair, res *proto.RespGetVaultKeyPair) error {
+	target := req.Target
+	if target == "" {
+		target = req.Username
+	}
+	dbResp, err := dbcli.GetVaultKeyPair(target)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if dbResp.Data == nil {
+		res.Code = common.StatusParamInvalid
+		res.Message = "用户未设置加密目录密钥"
+		return nil
+	}
+
+	kp := dbcli.ToTableVaultKeyPair(dbResp.Data)
+	if target != req.Username {
+		kp = orm.TableVaultKeyPair{UserName: kp.UserName, PublicKey: kp.PublicKey}
+	}
+	res.Code = common.StatusOK
+	res.KeyPairData, _ = json.Marshal(kp)
+	return nil
+}
+
+// CreateVault : 将空目录设为加密目录. 不能与已有的加密目录嵌套
+func (user *User) CreateVault(ctx context.Context, req *proto.ReqCreateVault, res *proto.RespCreateVault) error {
+	folder := req.Folder
+	if folder == "" || folder == "/" || !strings.HasSuffix(folder, "/") || strings.HasPrefix(folder, "/") {
+		res.Code = common.StatusParamInvalid
+		res.Message = "目录无效"
+		return nil
+	}
+	if req.WrappedKey == "" || len(req.WrappedKey) > maxWrappedKeyLen {
+		res.Code = common.StatusParamInvalid
+		res.Message = "目录密钥无效"
+		return nil
+	}
+
+	// 1. 须先设置密钥对, 目录密钥以自己的公钥加密
+	dbResp, err := dbcli.GetVaultKeyPair(req.Username)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if dbResp.Data == nil {
+		res.Code = common.StatusParamInvalid
+		res.Message = "请先设置加密目录密钥"
+		return nil
+	}
+
+	// 2. 不能与已有的加密目录嵌套
+	dbResp, err = dbcli.ListVaults(req.Username)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	for _, v := range dbcli.ToTableVaults(dbResp.Data) {
+		if v.Owner == req.Username && (strings.HasPrefix(folder, v.Folder) || strings.HasPrefix(v.Folder, folder)) {
+			res.Code = common.StatusParamInvalid
+			res.Message = "不能与已有的加密目录嵌套: " + v.Folder
+			return nil
+		}
+	}
+
+	// 3. 目录中不能已有明文文件(只允许目录本身)
+	dbResp, err = dbcli.ListUserFilesByPrefix(req.Username, folder, "", config.FileListLimit)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	for _, f := range dbcli.ToTableUserFiles(dbResp.Data) {
+		if strings.HasPrefix(f.FileName, folder) && f.FileName != folder {
+			res.Code = common.StatusParamInvalid
+			res.Message = "目录不为空"
+			return nil
+		}
+	}
+
+	dbResp, err = dbcli.AddVaultMember(req.Username, folder, req.Username, req.WrappedKey)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	res.Code = common.StatusOK
+	return nil
+}
+
+// ListVaults : 获取用户可访问的加密目录, 包括以该用户公钥加密的目录密钥
+func (user *User) ListVaults(ctx context.Context, req *proto.ReqListVaults, res *proto.RespListVaults) error {
+	dbResp, err := dbcli.ListVaults(req.Username)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	res.Code = common.StatusOK
+	res.VaultData, _ = json.Marshal(dbcli.ToTableVaults(dbResp.Data))
+	return nil
+}
+
+// ownVault : 用户自己的加密目录folder是否存在
+func ownVault(username, folder string) (bool, error) {
+	found, err := dbcli.VaultFolder(username, folder)
+	return found == folder, err
+}
+
+// sameVault : 用户的两个文件是否在同一加密目录中(或都不在加密目录中)
+func sameVault(username, a, b string) (bool, error) {
+	va, err := dbcli.VaultFolder(username, a)
+	if err != nil {
+		return false, err
+	}
+	vb, err := dbcli.VaultFolder(username, b)
+	if err != nil {
+		return false, err
+	}
+	return va == vb, nil
+}
+
+// ShareVault : 分享加密目录, 对方须已设置密钥对. 重复分享时更新目录密钥
+func (user *User) ShareVault(ctx context.Context, req *proto.ReqShareVault, res *proto.RespShareVault) error {
+	if req.Grantee == "" || req.Grantee == req.Username ||
+		req.WrappedKey == "" || len(req.WrappedKey) > maxWrappedKeyLen {
+		res.Code = common.StatusParamInvalid
+		res.Message = "参数无效"
+		return nil
+	}
+	own, err := ownVault(req.Username, req.Folder)
+	if err != nil {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if !own {
+		res.Code = common.StatusParamInvalid
+		res.Message = "加密目录不存在"
+		return nil
+	}
+
+	dbResp, err := dbcli.GetVaultKeyPair(req.Grantee)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if dbResp.Data == nil {
+		res.Code = common.StatusParamInvalid
+		res.Message = "对方未设置加密目录密钥"
+		return nil
+	}
+
+	dbResp, err = dbcli.AddVaultMember(req.Username, req.Folder, req.Grantee, req.WrappedKey)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	res.Code = common.StatusOK
+	return nil
+}
+
+// UnshareVault : 取消分享. 对方可能已保存目录密钥, 需要时应将文件重新加密到新的加密目录
+func (user *User) UnshareVault(ctx context.Context, req *proto.ReqUnshareVault, res *proto.RespUnshareVault) error {
+	dbResp, err := dbcli.RemoveVaultMember(req.Username, req.Folder, req.Grantee)
+	if err != nil {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if !dbResp.Suc {
+		res.Code = common.StatusParamInvalid
+		res.Message = dbResp.Msg
+		return nil
+	}
+	res.Code = common.StatusOK
+	return nil
+}
+
+// ListVaultMembers : 获取可访问自己的加密目录的用户
+func (user *User) ListVaultMembers(ctx context.Context, req *proto.ReqListVaultMembers, res *proto.RespListVaultMembers) error {
+	dbResp, err := dbcli.ListVaultMembers(req.Username, req.Folder)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	members := dbcli.ToTableVaults(dbResp.Data)
+	if len(members) == 0 {
+		res.Code = common.StatusParamInvalid
+		res.Message = "加密目录不存在"
+		return nil
+	}
+	for i := range members {
+		members[i].WrappedKey = ""
+	}
+	res.Code = common.StatusOK
+	res.MemberData, _ = json.Marshal(members)
+	return nil
+}
+
+// ListVaultFiles : 按前缀分页获取加密目录中的文件, owner不是自己时须已被分享该目录
+func (user *User) ListVaultFiles(ctx context.Context, req *proto.ReqListVaultFiles, res *proto.RespListVaultFiles) error {
+	owner := req.Owner
+	if owner == "" {
+		owner = req.Username
+	}
+	dbResp, err := dbcli.GetVaultByPath(owner, req.Username, req.Prefix)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if dbResp.Data == nil {
+		res.Code = common.StatusParamInvalid
+		res.Message = "无权访问该目录"
+		return nil
+	}
+	folder := dbcli.ToTableVault(dbResp.Data).Folder
+
+	limit := int(req.Limit)
+	if limit <= 0 || limit > config.FileListLimit {
+		limit = config.FileListLimit
+	}
+	dbResp, err = dbcli.ListUserFilesByPrefix(owner, req.Prefix, req.Marker, limit)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	// 前缀匹配不区分大小写, 只返回加密目录中的文件
+	userFiles := dbcli.ToTableUserFiles(dbResp.Data)
+	files := []orm.TableUserFile{}
+	for _, f := range userFiles {
+		if strings.HasPrefix(f.FileName, folder) {
+			files = append(files, f)
+		}
+	}
+	res.Code = common.StatusOK
+	res.FileData, _ = json.Marshal(files)
+	if len(userFiles) == limit {
+		res.NextMarker = userFiles[len(userFiles)-1].FileName
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	proto "github.com/cloud/service/account/proto"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/service/webhook/dispatcher"
+	"github.com/cloud/util"
+)
+
+// webhookEvents : 可订阅的事件类型
+var webhookEvents = map[string]bool{
+	common.FileEventUpload:   true,
+	common.FileEventRename:   true,
+	common.FileEventMove:     true,
+	common.FileEventDelete:   true,
+	common.FileEventRestore:  true,
+	common.FileEventShare:    true,
+	common.FileEventTransfer: true,
+}
+
+// normalizeWebhookEvents : 校验并去重订阅的事件类型, 为空表示全部
+func normalizeWebhookEvents(events string) (string, bool) {
+	seen := map[string]bool{}
+	list := []string{}
+	for _, e := range strings.Split(events, ",") {
+		e = strings.TrimSpace(e)
+		if e == "" || seen[e] {
+			continue
+		}
+		if !webhookEvents[e] {
+			return "", false
+		}
+		seen[e] = true
+		list = append(list, e)
+	}
+	return strings.Join(list, ","), true
+}
+
+// CreateWebhook : 为用户注册webhook, 注册后的文件事件将签名后POST到该地址
+func (user *User) CreateWebhook(ctx context.Context, req *proto.ReqCreateWebhook, res *proto.RespCreateWebhook) error {
+	// 1. 校验参数
+	// webhook地址须为http(s)绝对地址, 且不能指向本机或内网
+	if err := dispatcher.CheckURL(ctx, req.Url); err != nil {
+		res.Code = common.StatusParamInvalid
+		res.Message = "webhook地址无效: " + err.Error()
+		return nil
+	}
+	events, ok := normalizeWebhookEvents(req.Events)
+	if !ok {
+		res.Code = common.StatusParamInvalid
+		res.Message = "事件类型无效"
+		return nil
+	}
+
+	// 2. 检查用户已有的webhook数量
+	dbResp, err := dbcli.ListWebhooks(req.Username)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if len(dbcli.ToTableWebhooks(dbResp.Data)) >= config.WebhookLimit {
+		res.Code = common.StatusParamInvalid
+		res.Message = "webhook数量已达上限"
+		return nil
+	}
+
+	// 3. 生成签名密钥并保存
+	secret, err := util.RandomHex(20)
+	if err != nil {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	dbResp, err = dbcli.CreateWebhook(req.Username, req.Url, secret, events)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	data, err := json.Marshal(dbcli.ToTableWebhook(dbResp.Data))
+	if err != nil {
+		res.Code = common.StatusServerError
+		return nil
+	}
+
+	// 4. 签名密钥只在创建时返回一次
+	res.Code = common.StatusOK
+	res.WebhookData = data
+	res.Secret = secret
+	return nil
+}
+
+// ListWebhooks : 获取用户的webhook列表(不含签名密钥)
+func (user *User) ListWebhooks(ctx context.Context, req *proto.ReqListWebhooks, res *proto.RespListWebhooks) error {
+	dbResp, err := dbcli.ListWebhooks(req.Username)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+
+	data, err := json.Marshal(dbcli.ToTableWebhooks(dbResp.Data))
+	if err != nil {
+		res.Code = common.StatusServerError
+		return nil
+	}
+
+	res.Code = common.StatusOK
+	res.WebhookData = data
+	return nil
+}
+
+// DeleteWebhook : 删除用户的webhook
+func (user *User) DeleteWebhook(ctx context.Context, req *proto.ReqDeleteWebhook, res *proto.RespDeleteWebhook) error {
+	dbResp, err := dbcli.DeleteWebhook(req.Username, req.Id)
+	if err != nil {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if !dbResp.Suc {
+		res.Code = common.StatusParamInvalid
+		res.Message = dbResp.Msg
+		return nil
+	}
+	res.Code = common.StatusOK
+	return nil
+}
+
+// ListWebhookDeliveries : 按时间倒序获取用户webhook的投递记录
+func (user *User) ListWebhookDeliveries(ctx context.Context, req *proto.ReqListWebhookDeliveries, res *proto.RespListWebhookDeliveries) error {
+	limit := int(req.Limit)
+	if limit <= 0 || limit > config.WebhookDeliveriesLimit {
+		limit = config.WebhookDeliveriesLimit
+	}
+	dbResp, err := dbcli.ListWebhookDeliveries(req.Username, req.HookId, limit)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+
+	data, err := json.Marshal(dbcli.ToTableWebhookDeliveries(dbResp.Data))
+	if err != nil {
+		res.Code = common.StatusServerError
+		return nil
+	}
+
+	res.Code = common.StatusOK
+	res.DeliveryData = data
+	return nil
+}
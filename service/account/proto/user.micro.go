@@ -44,6 +44,56 @@ type UserService interface {
 	UserFiles(ctx context.Context, in *ReqUserFile, opts ...client.CallOption) (*RespUserFile, error)
 	// 获取用户文件
 	UserFileRename(ctx context.Context, in *ReqUserFileRename, opts ...client.CallOption) (*RespUserFileRename, error)
+	// 创建S3访问密钥
+	CreateAccessKey(ctx context.Context, in *ReqCreateAccessKey, opts ...client.CallOption) (*RespCreateAccessKey, error)
+	// 获取用户的S3访问密钥列表
+	ListAccessKeys(ctx context.Context, in *ReqListAccessKeys, opts ...client.CallOption) (*RespListAccessKeys, error)
+	// 删除S3访问密钥
+	DeleteAccessKey(ctx context.Context, in *ReqDeleteAccessKey, opts ...client.CallOption) (*RespDeleteAccessKey, error)
+	// 校验用户名及密码(不生成token), 供WebDAV等使用基本认证的服务调用
+	Authenticate(ctx context.Context, in *ReqAuthenticate, opts ...client.CallOption) (*RespAuthenticate, error)
+	// 添加SSH公钥
+	AddSSHKey(ctx context.Context, in *ReqAddSSHKey, opts ...client.CallOption) (*RespAddSSHKey, error)
+	// 获取用户的SSH公钥列表
+	ListSSHKeys(ctx context.Context, in *ReqListSSHKeys, opts ...client.CallOption) (*RespListSSHKeys, error)
+	// 删除SSH公钥
+	DeleteSSHKey(ctx context.Context, in *ReqDeleteSSHKey, opts ...client.CallOption) (*RespDeleteSSHKey, error)
+	// 按目录前缀分页获取用户文件
+	UserFileList(ctx context.Context, in *ReqUserFileList, opts ...client.CallOption) (*RespUserFileList, error)
+	// 按文件名删除用户文件
+	UserFileDelete(ctx context.Context, in *ReqUserFileDelete, opts ...client.CallOption) (*RespUserFileDelete, error)
+	// 按文件名移动(重命名)用户文件
+	UserFileMove(ctx context.Context, in *ReqUserFileMove, opts ...client.CallOption) (*RespUserFileMove, error)
+	// 获取用户的空间用量
+	UserUsage(ctx context.Context, in *ReqUserUsage, opts ...client.CallOption) (*RespUserUsage, error)
+	// 按文件名恢复已删除的用户文件
+	UserFileRestore(ctx context.Context, in *ReqUserFileRestore, opts ...client.CallOption) (*RespUserFileRestore, error)
+	// 获取游标之后的用户文件变更事件
+	UserFileChanges(ctx context.Context, in *ReqUserFileChanges, opts ...client.CallOption) (*RespUserFileChanges, error)
+	// 注册webhook
+	CreateWebhook(ctx context.Context, in *ReqCreateWebhook, opts ...client.CallOption) (*RespCreateWebhook, error)
+	// 获取用户的webhook列表
+	ListWebhooks(ctx context.Context, in *ReqListWebhooks, opts ...client.CallOption) (*RespListWebhooks, error)
+	// 删除webhook
+	DeleteWebhook(ctx context.Context, in *ReqDeleteWebhook, opts ...client.CallOption) (*RespDeleteWebhook, error)
+	// 获取webhook的投递记录
+	ListWebhookDeliveries(ctx context.Context, in *ReqListWebhookDeliveries, opts ...client.CallOption) (*RespListWebhookDeliveries, error)
+	// 设置加密目录密钥对(首次创建, 或公钥不变时更新以新口令加密的私钥)
+	SetVaultKeyPair(ctx context.Context, in *ReqSetVaultKeyPair, opts ...client.CallOption) (*RespSetVaultKeyPair, error)
+	// 获取加密目录密钥对, 查询其他用户时只返回公钥
+	GetVaultKeyPair(ctx context.Context, in *ReqGetVaultKeyPair, opts ...client.CallOption) (*RespGetVaultKeyPair, error)
+	// 将空目录设为加密目录
+	CreateVault(ctx context.Context, in *ReqCreateVault, opts ...client.CallOption) (*RespCreateVault, error)
+	// 获取用户可访问的加密目录(自己的及分享给该用户的)
+	ListVaults(ctx context.Context, in *ReqListVaults, opts ...client.CallOption) (*RespListVaults, error)
+	// 分享加密目录, 目录密钥由客户端以对方公钥加密
+	ShareVault(ctx context.Context, in *ReqShareVault, opts ...client.CallOption) (*RespShareVault, error)
+	// 取消加密目录的分享
+	UnshareVault(ctx context.Context, in *ReqUnshareVault, opts ...client.CallOption) (*RespUnshareVault, error)
+	// 获取可访问加密目录的用户
+	ListVaultMembers(ctx context.Context, in *ReqListVaultMembers, opts ...client.CallOption) (*RespListVaultMembers, error)
+	// 按前缀分页获取可访问的加密目录中的文件(包括分享给该用户的)
+	ListVaultFiles(ctx context.Context, in *ReqListVaultFiles, opts ...client.CallOption) (*RespListVaultFiles, error)
 }
 
 type userService struct {
@@ -114,6 +164,256 @@ func (c *userService) UserFileRename(ctx context.Context, in *ReqUserFileRename,
 	return out, nil
 }
 
+func (c *userService) CreateAccessKey(ctx context.Context, in *ReqCreateAccessKey, opts ...client.CallOption) (*RespCreateAccessKey, error) {
+	req := c.c.NewRequest(c.name, "UserService.CreateAccessKey", in)
+	out := new(RespCreateAccessKey)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) ListAccessKeys(ctx context.Context, in *ReqListAccessKeys, opts ...client.CallOption) (*RespListAccessKeys, error) {
+	req := c.c.NewRequest(c.name, "UserService.ListAccessKeys", in)
+	out := new(RespListAccessKeys)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) DeleteAccessKey(ctx context.Context, in *ReqDeleteAccessKey, opts ...client.CallOption) (*RespDeleteAccessKey, error) {
+	req := c.c.NewRequest(c.name, "UserService.DeleteAccessKey", in)
+	out := new(RespDeleteAccessKey)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) Authenticate(ctx context.Context, in *ReqAuthenticate, opts ...client.CallOption) (*RespAuthenticate, error) {
+	req := c.c.NewRequest(c.name, "UserService.Authenticate", in)
+	out := new(RespAuthenticate)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) AddSSHKey(ctx context.Context, in *ReqAddSSHKey, opts ...client.CallOption) (*RespAddSSHKey, error) {
+	req := c.c.NewRequest(c.name, "UserService.AddSSHKey", in)
+	out := new(RespAddSSHKey)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) ListSSHKeys(ctx context.Context, in *ReqListSSHKeys, opts ...client.CallOption) (*RespListSSHKeys, error) {
+	req := c.c.NewRequest(c.name, "UserService.ListSSHKeys", in)
+	out := new(RespListSSHKeys)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) DeleteSSHKey(ctx context.Context, in *ReqDeleteSSHKey, opts ...client.CallOption) (*RespDeleteSSHKey, error) {
+	req := c.c.NewRequest(c.name, "UserService.DeleteSSHKey", in)
+	out := new(RespDeleteSSHKey)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) UserFileList(ctx context.Context, in *ReqUserFileList, opts ...client.CallOption) (*RespUserFileList, error) {
+	req := c.c.NewRequest(c.name, "UserService.UserFileList", in)
+	out := new(RespUserFileList)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) UserFileDelete(ctx context.Context, in *ReqUserFileDelete, opts ...client.CallOption) (*RespUserFileDelete, error) {
+	req := c.c.NewRequest(c.name, "UserService.UserFileDelete", in)
+	out := new(RespUserFileDelete)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) UserFileMove(ctx context.Context, in *ReqUserFileMove, opts ...client.CallOption) (*RespUserFileMove, error) {
+	req := c.c.NewRequest(c.name, "UserService.UserFileMove", in)
+	out := new(RespUserFileMove)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) UserUsage(ctx context.Context, in *ReqUserUsage, opts ...client.CallOption) (*RespUserUsage, error) {
+	req := c.c.NewRequest(c.name, "UserService.UserUsage", in)
+	out := new(RespUserUsage)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) UserFileRestore(ctx context.Context, in *ReqUserFileRestore, opts ...client.CallOption) (*RespUserFileRestore, error) {
+	req := c.c.NewRequest(c.name, "UserService.UserFileRestore", in)
+	out := new(RespUserFileRestore)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) UserFileChanges(ctx context.Context, in *ReqUserFileChanges, opts ...client.CallOption) (*RespUserFileChanges, error) {
+	req := c.c.NewRequest(c.name, "UserService.UserFileChanges", in)
+	out := new(RespUserFileChanges)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) CreateWebhook(ctx context.Context, in *ReqCreateWebhook, opts ...client.CallOption) (*RespCreateWebhook, error) {
+	req := c.c.NewRequest(c.name, "UserService.CreateWebhook", in)
+	out := new(RespCreateWebhook)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) ListWebhooks(ctx context.Context, in *ReqListWebhooks, opts ...client.CallOption) (*RespListWebhooks, error) {
+	req := c.c.NewRequest(c.name, "UserService.ListWebhooks", in)
+	out := new(RespListWebhooks)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) DeleteWebhook(ctx context.Context, in *ReqDeleteWebhook, opts ...client.CallOption) (*RespDeleteWebhook, error) {
+	req := c.c.NewRequest(c.name, "UserService.DeleteWebhook", in)
+	out := new(RespDeleteWebhook)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) ListWebhookDeliveries(ctx context.Context, in *ReqListWebhookDeliveries, opts ...client.CallOption) (*RespListWebhookDeliveries, error) {
+	req := c.c.NewRequest(c.name, "UserService.ListWebhookDeliveries", in)
+	out := new(RespListWebhookDeliveries)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) SetVaultKeyPair(ctx context.Context, in *ReqSetVaultKeyPair, opts ...client.CallOption) (*RespSetVaultKeyPair, error) {
+	req := c.c.NewRequest(c.name, "UserService.SetVaultKeyPair", in)
+	out := new(RespSetVaultKeyPair)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) GetVaultKeyPair(ctx context.Context, in *ReqGetVaultKeyPair, opts ...client.CallOption) (*RespGetVaultKeyPair, error) {
+	req := c.c.NewRequest(c.name, "UserService.GetVaultKeyPair", in)
+	out := new(RespGetVaultKeyPair)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) CreateVault(ctx context.Context, in *ReqCreateVault, opts ...client.CallOption) (*RespCreateVault, error) {
+	req := c.c.NewRequest(c.name, "UserService.CreateVault", in)
+	out := new(RespCreateVault)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) ListVaults(ctx context.Context, in *ReqListVaults, opts ...client.CallOption) (*RespListVaults, error) {
+	req := c.c.NewRequest(c.name, "UserService.ListVaults", in)
+	out := new(RespListVaults)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) ShareVault(ctx context.Context, in *ReqShareVault, opts ...client.CallOption) (*RespShareVault, error) {
+	req := c.c.NewRequest(c.name, "UserService.ShareVault", in)
+	out := new(RespShareVault)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) UnshareVault(ctx context.Context, in *ReqUnshareVault, opts ...client.CallOption) (*RespUnshareVault, error) {
+	req := c.c.NewRequest(c.name, "UserService.UnshareVault", in)
+	out := new(RespUnshareVault)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) ListVaultMembers(ctx context.Context, in *ReqListVaultMembers, opts ...client.CallOption) (*RespListVaultMembers, error) {
+	req := c.c.NewRequest(c.name, "UserService.ListVaultMembers", in)
+	out := new(RespListVaultMembers)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userService) ListVaultFiles(ctx context.Context, in *ReqListVaultFiles, opts ...client.CallOption) (*RespListVaultFiles, error) {
+	req := c.c.NewRequest(c.name, "UserService.ListVaultFiles", in)
+	out := new(RespListVaultFiles)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for UserService service
 
 type UserServiceHandler interface {
@@ -127,6 +427,56 @@ type UserServiceHandler interface {
 	UserFiles(context.Context, *ReqUserFile, *RespUserFile) error
 	// 获取用户文件
 	UserFileRename(context.Context, *ReqUserFileRename, *RespUserFileRename) error
+	// 创建S3访问密钥
+	CreateAccessKey(context.Context, *ReqCreateAccessKey, *RespCreateAccessKey) error
+	// 获取用户的S3访问密钥列表
+	ListAccessKeys(context.Context, *ReqListAccessKeys, *RespListAccessKeys) error
+	// 删除S3访问密钥
+	DeleteAccessKey(context.Context, *ReqDeleteAccessKey, *RespDeleteAccessKey) error
+	// 校验用户名及密码(不生成token), 供WebDAV等使用基本认证的服务调用
+	Authenticate(context.Context, *ReqAuthenticate, *RespAuthenticate) error
+	// 添加SSH公钥
+	AddSSHKey(context.Context, *ReqAddSSHKey, *RespAddSSHKey) error
+	// 获取用户的SSH公钥列表
+	ListSSHKeys(context.Context, *ReqListSSHKeys, *RespListSSHKeys) error
+	// 删除SSH公钥
+	DeleteSSHKey(context.Context, *ReqDeleteSSHKey, *RespDeleteSSHKey) error
+	// 按目录前缀分页获取用户文件
+	UserFileList(context.Context, *ReqUserFileList, *RespUserFileList) error
+	// 按文件名删除用户文件
+	UserFileDelete(context.Context, *ReqUserFileDelete, *RespUserFileDelete) error
+	// 按文件名移动(重命名)用户文件
+	UserFileMove(context.Context, *ReqUserFileMove, *RespUserFileMove) error
+	// 获取用户的空间用量
+	UserUsage(context.Context, *ReqUserUsage, *RespUserUsage) error
+	// 按文件名恢复已删除的用户文件
+	UserFileRestore(context.Context, *ReqUserFileRestore, *RespUserFileRestore) error
+	// 获取游标之后的用户文件变更事件
+	UserFileChanges(context.Context, *ReqUserFileChanges, *RespUserFileChanges) error
+	// 注册webhook
+	CreateWebhook(context.Context, *ReqCreateWebhook, *RespCreateWebhook) error
+	// 获取用户的webhook列表
+	ListWebhooks(context.Context, *ReqListWebhooks, *RespListWebhooks) error
+	// 删除webhook
+	DeleteWebhook(context.Context, *ReqDeleteWebhook, *RespDeleteWebhook) error
+	// 获取webhook的投递记录
+	ListWebhookDeliveries(context.Context, *ReqListWebhookDeliveries, *RespListWebhookDeliveries) error
+	// 设置加密目录密钥对(首次创建, 或公钥不变时更新以新口令加密的私钥)
+	SetVaultKeyPair(context.Context, *ReqSetVaultKeyPair, *RespSetVaultKeyPair) error
+	// 获取加密目录密钥对, 查询其他用户时只返回公钥
+	GetVaultKeyPair(context.Context, *ReqGetVaultKeyPair, *RespGetVaultKeyPair) error
+	// 将空目录设为加密目录
+	CreateVault(context.Context, *ReqCreateVault, *RespCreateVault) error
+	// 获取用户可访问的加密目录(自己的及分享给该用户的)
+	ListVaults(context.Context, *ReqListVaults, *RespListVaults) error
+	// 分享加密目录, 目录密钥由客户端以对方公钥加密
+	ShareVault(context.Context, *ReqShareVault, *RespShareVault) error
+	// 取消加密目录的分享
+	UnshareVault(context.Context, *ReqUnshareVault, *RespUnshareVault) error
+	// 获取可访问加密目录的用户
+	ListVaultMembers(context.Context, *ReqListVaultMembers, *RespListVaultMembers) error
+	// 按前缀分页获取可访问的加密目录中的文件(包括分享给该用户的)
+	ListVaultFiles(context.Context, *ReqListVaultFiles, *RespListVaultFiles) error
 }
 
 func RegisterUserServiceHandler(s server.Server, hdlr UserServiceHandler, opts ...server.HandlerOption) error {
@@ -136,6 +486,31 @@ func RegisterUserServiceHandler(s server.Server, hdlr UserServiceHandler, opts .
 		UserInfo(ctx context.Context, in *ReqUserInfo, out *RespUserInfo) error
 		UserFiles(ctx context.Context, in *ReqUserFile, out *RespUserFile) error
 		UserFileRename(ctx context.Context, in *ReqUserFileRename, out *RespUserFileRename) error
+		CreateAccessKey(ctx context.Context, in *ReqCreateAccessKey, out *RespCreateAccessKey) error
+		ListAccessKeys(ctx context.Context, in *ReqListAccessKeys, out *RespListAccessKeys) error
+		DeleteAccessKey(ctx context.Context, in *ReqDeleteAccessKey, out *RespDeleteAccessKey) error
+		Authenticate(ctx context.Context, in *ReqAuthenticate, out *RespAuthenticate) error
+		AddSSHKey(ctx context.Context, in *ReqAddSSHKey, out *RespAddSSHKey) error
+		ListSSHKeys(ctx context.Context, in *ReqListSSHKeys, out *RespListSSHKeys) error
+		DeleteSSHKey(ctx context.Context, in *ReqDeleteSSHKey, out *RespDeleteSSHKey) error
+		UserFileList(ctx context.Context, in *ReqUserFileList, out *RespUserFileList) error
+		UserFileDelete(ctx context.Context, in *ReqUserFileDelete, out *RespUserFileDelete) error
+		UserFileMove(ctx context.Context, in *ReqUserFileMove, out *RespUserFileMove) error
+		UserUsage(ctx context.Context, in *ReqUserUsage, out *RespUserUsage) error
+		UserFileRestore(ctx context.Context, in *ReqUserFileRestore, out *RespUserFileRestore) error
+		UserFileChanges(ctx context.Context, in *ReqUserFileChanges, out *RespUserFileChanges) error
+		CreateWebhook(ctx context.Context, in *ReqCreateWebhook, out *RespCreateWebhook) error
+		ListWebhooks(ctx context.Context, in *ReqListWebhooks, out *RespListWebhooks) error
+		DeleteWebhook(ctx context.Context, in *ReqDeleteWebhook, out *RespDeleteWebhook) error
+		ListWebhookDeliveries(ctx context.Context, in *ReqListWebhookDeliveries, out *RespListWebhookDeliveries) error
+		SetVaultKeyPair(ctx context.Context, in *ReqSetVaultKeyPair, out *RespSetVaultKeyPair) error
+		GetVaultKeyPair(ctx context.Context, in *ReqGetVaultKeyPair, out *RespGetVaultKeyPair) error
+		CreateVault(ctx context.Context, in *ReqCreateVault, out *RespCreateVault) error
+		ListVaults(ctx context.Context, in *ReqListVaults, out *RespListVaults) error
+		ShareVault(ctx context.Context, in *ReqShareVault, out *RespShareVault) error
+		UnshareVault(ctx context.Context, in *ReqUnshareVault, out *RespUnshareVault) error
+		ListVaultMembers(ctx context.Context, in *ReqListVaultMembers, out *RespListVaultMembers) error
+		ListVaultFiles(ctx context.Context, in *ReqListVaultFiles, out *RespListVaultFiles) error
 	}
 	type UserService struct {
 		userService
@@ -168,3 +543,102 @@ func (h *userServiceHandler) UserFileRename(ctx context.Context, in *ReqUserFile
 	return h.UserServiceHandler.UserFileRename(ctx, in, out)
 }
 
+func (h *userServiceHandler) CreateAccessKey(ctx context.Context, in *ReqCreateAccessKey, out *RespCreateAccessKey) error {
+	return h.UserServiceHandler.CreateAccessKey(ctx, in, out)
+}
+
+func (h *userServiceHandler) ListAccessKeys(ctx context.Context, in *ReqListAccessKeys, out *RespListAccessKeys) error {
+	return h.UserServiceHandler.ListAccessKeys(ctx, in, out)
+}
+
+func (h *userServiceHandler) DeleteAccessKey(ctx context.Context, in *ReqDeleteAccessKey, out *RespDeleteAccessKey) error {
+	return h.UserServiceHandler.DeleteAccessKey(ctx, in, out)
+}
+
+func (h *userServiceHandler) Authenticate(ctx context.Context, in *ReqAuthenticate, out *RespAuthenticate) error {
+	return h.UserServiceHandler.Authenticate(ctx, in, out)
+}
+
+func (h *userServiceHandler) AddSSHKey(ctx context.Context, in *ReqAddSSHKey, out *RespAddSSHKey) error {
+	return h.UserServiceHandler.AddSSHKey(ctx, in, out)
+}
+
+func (h *userServiceHandler) ListSSHKeys(ctx context.Context, in *ReqListSSHKeys, out *RespListSSHKeys) error {
+	return h.UserServiceHandler.ListSSHKeys(ctx, in, out)
+}
+
+func (h *userServiceHandler) DeleteSSHKey(ctx context.Context, in *ReqDeleteSSHKey, out *RespDeleteSSHKey) error {
+	return h.UserServiceHandler.DeleteSSHKey(ctx, in, out)
+}
+
+func (h *userServiceHandler) UserFileList(ctx context.Context, in *ReqUserFileList, out *RespUserFileList) error {
+	return h.UserServiceHandler.UserFileList(ctx, in, out)
+}
+
+func (h *userServiceHandler) UserFileDelete(ctx context.Context, in *ReqUserFileDelete, out *RespUserFileDelete) error {
+	return h.UserServiceHandler.UserFileDelete(ctx, in, out)
+}
+
+func (h *userServiceHandler) UserFileMove(ctx context.Context, in *ReqUserFileMove, out *RespUserFileMove) error {
+	return h.UserServiceHandler.UserFileMove(ctx, in, out)
+}
+
+func (h *userServiceHandler) UserUsage(ctx context.Context, in *ReqUserUsage, out *RespUserUsage) error {
+	return h.UserServiceHandler.UserUsage(ctx, in, out)
+}
+
+func (h *userServiceHandler) UserFileRestore(ctx context.Context, in *ReqUserFileRestore, out *RespUserFileRestore) error {
+	return h.UserServiceHandler.UserFileRestore(ctx, in, out)
+}
+
+func (h *userServiceHandler) UserFileChanges(ctx context.Context, in *ReqUserFileChanges, out *RespUserFileChanges) error {
+	return h.UserServiceHandler.UserFileChanges(ctx, in, out)
+}
+
+func (h *userServiceHandler) CreateWebhook(ctx context.Context, in *ReqCreateWebhook, out *RespCreateWebhook) error {
+	return h.UserServiceHandler.CreateWebhook(ctx, in, out)
+}
+
+func (h *userServiceHandler) ListWebhooks(ctx context.Context, in *ReqListWebhooks, out *RespListWebhooks) error {
+	return h.UserServiceHandler.ListWebhooks(ctx, in, out)
+}
+
+func (h *userServiceHandler) DeleteWebhook(ctx context.Context, in *ReqDeleteWebhook, out *RespDeleteWebhook) error {
+	return h.UserServiceHandler.DeleteWebhook(ctx, in, out)
+}
+
+func (h *userServiceHandler) ListWebhookDeliveries(ctx context.Context, in *ReqListWebhookDeliveries, out *RespListWebhookDeliveries) error {
+	return h.UserServiceHandler.ListWebhookDeliveries(ctx, in, out)
+}
+
+func (h *userServiceHandler) SetVaultKeyPair(ctx context.Context, in *ReqSetVaultKeyPair, out *RespSetVaultKeyPair) error {
+	return h.UserServiceHandler.SetVaultKeyPair(ctx, in, out)
+}
+
+func (h *userServiceHandler) GetVaultKeyPair(ctx context.Context, in *ReqGetVaultKeyPair, out *RespGetVaultKeyPair) error {
+	return h.UserServiceHandler.GetVaultKeyPair(ctx, in, out)
+}
+
+func (h *userServiceHandler) CreateVault(ctx context.Context, in *ReqCreateVault, out *RespCreateVault) error {
+	return h.UserServiceHandler.CreateVault(ctx, in, out)
+}
+
+func (h *userServiceHandler) ListVaults(ctx context.Context, in *ReqListVaults, out *RespListVaults) error {
+	return h.UserServiceHandler.ListVaults(ctx, in, out)
+}
+
+func (h *userServiceHandler) ShareVault(ctx context.Context, in *ReqShareVault, out *RespShareVault) error {
+	return h.UserServiceHandler.ShareVault(ctx, in, out)
+}
+
+func (h *userServiceHandler) UnshareVault(ctx context.Context, in *ReqUnshareVault, out *RespUnshareVault) error {
+	return h.UserServiceHandler.UnshareVault(ctx, in, out)
+}
+
+func (h *userServiceHandler) ListVaultMembers(ctx context.Context, in *ReqListVaultMembers, out *RespListVaultMembers) error {
+	return h.UserServiceHandler.ListVaultMembers(ctx, in, out)
+}
+
+func (h *userServiceHandler) ListVaultFiles(ctx context.Context, in *ReqListVaultFiles, out *RespListVaultFiles) error {
+	return h.UserServiceHandler.ListVaultFiles(ctx, in, out)
+}
@@ -562,6 +562,2602 @@ func (m *RespUserFileRename) GetFileData() []byte {
 	return nil
 }
 
+type ReqCreateAccessKey struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqCreateAccessKey) Reset()         { *m = ReqCreateAccessKey{} }
+func (m *ReqCreateAccessKey) String() string { return proto.CompactTextString(m) }
+func (*ReqCreateAccessKey) ProtoMessage()    {}
+func (*ReqCreateAccessKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{10}
+}
+
+func (m *ReqCreateAccessKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqCreateAccessKey.Unmarshal(m, b)
+}
+func (m *ReqCreateAccessKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqCreateAccessKey.Marshal(b, m, deterministic)
+}
+func (m *ReqCreateAccessKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqCreateAccessKey.Merge(m, src)
+}
+func (m *ReqCreateAccessKey) XXX_Size() int {
+	return xxx_messageInfo_ReqCreateAccessKey.Size(m)
+}
+func (m *ReqCreateAccessKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqCreateAccessKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqCreateAccessKey proto.InternalMessageInfo
+
+func (m *ReqCreateAccessKey) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+type RespCreateAccessKey struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	AccessKey            string   `protobuf:"bytes,3,opt,name=accessKey,proto3" json:"accessKey,omitempty"`
+	SecretKey            string   `protobuf:"bytes,4,opt,name=secretKey,proto3" json:"secretKey,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespCreateAccessKey) Reset()         { *m = RespCreateAccessKey{} }
+func (m *RespCreateAccessKey) String() string { return proto.CompactTextString(m) }
+func (*RespCreateAccessKey) ProtoMessage()    {}
+func (*RespCreateAccessKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{11}
+}
+
+func (m *RespCreateAccessKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespCreateAccessKey.Unmarshal(m, b)
+}
+func (m *RespCreateAccessKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespCreateAccessKey.Marshal(b, m, deterministic)
+}
+func (m *RespCreateAccessKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespCreateAccessKey.Merge(m, src)
+}
+func (m *RespCreateAccessKey) XXX_Size() int {
+	return xxx_messageInfo_RespCreateAccessKey.Size(m)
+}
+func (m *RespCreateAccessKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespCreateAccessKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespCreateAccessKey proto.InternalMessageInfo
+
+func (m *RespCreateAccessKey) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespCreateAccessKey) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *RespCreateAccessKey) GetAccessKey() string {
+	if m != nil {
+		return m.AccessKey
+	}
+	return ""
+}
+
+func (m *RespCreateAccessKey) GetSecretKey() string {
+	if m != nil {
+		return m.SecretKey
+	}
+	return ""
+}
+
+type ReqListAccessKeys struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqListAccessKeys) Reset()         { *m = ReqListAccessKeys{} }
+func (m *ReqListAccessKeys) String() string { return proto.CompactTextString(m) }
+func (*ReqListAccessKeys) ProtoMessage()    {}
+func (*ReqListAccessKeys) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{12}
+}
+
+func (m *ReqListAccessKeys) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqListAccessKeys.Unmarshal(m, b)
+}
+func (m *ReqListAccessKeys) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqListAccessKeys.Marshal(b, m, deterministic)
+}
+func (m *ReqListAccessKeys) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqListAccessKeys.Merge(m, src)
+}
+func (m *ReqListAccessKeys) XXX_Size() int {
+	return xxx_messageInfo_ReqListAccessKeys.Size(m)
+}
+func (m *ReqListAccessKeys) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqListAccessKeys.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqListAccessKeys proto.InternalMessageInfo
+
+func (m *ReqListAccessKeys) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+type RespListAccessKeys struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	KeyData              []byte   `protobuf:"bytes,3,opt,name=keyData,proto3" json:"keyData,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespListAccessKeys) Reset()         { *m = RespListAccessKeys{} }
+func (m *RespListAccessKeys) String() string { return proto.CompactTextString(m) }
+func (*RespListAccessKeys) ProtoMessage()    {}
+func (*RespListAccessKeys) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{13}
+}
+
+func (m *RespListAccessKeys) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespListAccessKeys.Unmarshal(m, b)
+}
+func (m *RespListAccessKeys) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespListAccessKeys.Marshal(b, m, deterministic)
+}
+func (m *RespListAccessKeys) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespListAccessKeys.Merge(m, src)
+}
+func (m *RespListAccessKeys) XXX_Size() int {
+	return xxx_messageInfo_RespListAccessKeys.Size(m)
+}
+func (m *RespListAccessKeys) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespListAccessKeys.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespListAccessKeys proto.InternalMessageInfo
+
+func (m *RespListAccessKeys) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespListAccessKeys) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *RespListAccessKeys) GetKeyData() []byte {
+	if m != nil {
+		return m.KeyData
+	}
+	return nil
+}
+
+type ReqDeleteAccessKey struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	AccessKey            string   `protobuf:"bytes,2,opt,name=accessKey,proto3" json:"accessKey,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqDeleteAccessKey) Reset()         { *m = ReqDeleteAccessKey{} }
+func (m *ReqDeleteAccessKey) String() string { return proto.CompactTextString(m) }
+func (*ReqDeleteAccessKey) ProtoMessage()    {}
+func (*ReqDeleteAccessKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{14}
+}
+
+func (m *ReqDeleteAccessKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqDeleteAccessKey.Unmarshal(m, b)
+}
+func (m *ReqDeleteAccessKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqDeleteAccessKey.Marshal(b, m, deterministic)
+}
+func (m *ReqDeleteAccessKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqDeleteAccessKey.Merge(m, src)
+}
+func (m *ReqDeleteAccessKey) XXX_Size() int {
+	return xxx_messageInfo_ReqDeleteAccessKey.Size(m)
+}
+func (m *ReqDeleteAccessKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqDeleteAccessKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqDeleteAccessKey proto.InternalMessageInfo
+
+func (m *ReqDeleteAccessKey) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqDeleteAccessKey) GetAccessKey() string {
+	if m != nil {
+		return m.AccessKey
+	}
+	return ""
+}
+
+type RespDeleteAccessKey struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespDeleteAccessKey) Reset()         { *m = RespDeleteAccessKey{} }
+func (m *RespDeleteAccessKey) String() string { return proto.CompactTextString(m) }
+func (*RespDeleteAccessKey) ProtoMessage()    {}
+func (*RespDeleteAccessKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{15}
+}
+
+func (m *RespDeleteAccessKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespDeleteAccessKey.Unmarshal(m, b)
+}
+func (m *RespDeleteAccessKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespDeleteAccessKey.Marshal(b, m, deterministic)
+}
+func (m *RespDeleteAccessKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespDeleteAccessKey.Merge(m, src)
+}
+func (m *RespDeleteAccessKey) XXX_Size() int {
+	return xxx_messageInfo_RespDeleteAccessKey.Size(m)
+}
+func (m *RespDeleteAccessKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespDeleteAccessKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespDeleteAccessKey proto.InternalMessageInfo
+
+func (m *RespDeleteAccessKey) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespDeleteAccessKey) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type ReqAuthenticate struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Password             string   `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqAuthenticate) Reset()         { *m = ReqAuthenticate{} }
+func (m *ReqAuthenticate) String() string { return proto.CompactTextString(m) }
+func (*ReqAuthenticate) ProtoMessage()    {}
+func (*ReqAuthenticate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{16}
+}
+
+func (m *ReqAuthenticate) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqAuthenticate.Unmarshal(m, b)
+}
+func (m *ReqAuthenticate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqAuthenticate.Marshal(b, m, deterministic)
+}
+func (m *ReqAuthenticate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqAuthenticate.Merge(m, src)
+}
+func (m *ReqAuthenticate) XXX_Size() int {
+	return xxx_messageInfo_ReqAuthenticate.Size(m)
+}
+func (m *ReqAuthenticate) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqAuthenticate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqAuthenticate proto.InternalMessageInfo
+
+func (m *ReqAuthenticate) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqAuthenticate) GetPassword() string {
+	if m != nil {
+		return m.Password
+	}
+	return ""
+}
+
+type RespAuthenticate struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespAuthenticate) Reset()         { *m = RespAuthenticate{} }
+func (m *RespAuthenticate) String() string { return proto.CompactTextString(m) }
+func (*RespAuthenticate) ProtoMessage()    {}
+func (*RespAuthenticate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{17}
+}
+
+func (m *RespAuthenticate) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespAuthenticate.Unmarshal(m, b)
+}
+func (m *RespAuthenticate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespAuthenticate.Marshal(b, m, deterministic)
+}
+func (m *RespAuthenticate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespAuthenticate.Merge(m, src)
+}
+func (m *RespAuthenticate) XXX_Size() int {
+	return xxx_messageInfo_RespAuthenticate.Size(m)
+}
+func (m *RespAuthenticate) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespAuthenticate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespAuthenticate proto.InternalMessageInfo
+
+func (m *RespAuthenticate) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespAuthenticate) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type ReqAddSSHKey struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	PublicKey            string   `protobuf:"bytes,2,opt,name=publicKey,proto3" json:"publicKey,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqAddSSHKey) Reset()         { *m = ReqAddSSHKey{} }
+func (m *ReqAddSSHKey) String() string { return proto.CompactTextString(m) }
+func (*ReqAddSSHKey) ProtoMessage()    {}
+func (*ReqAddSSHKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{18}
+}
+
+func (m *ReqAddSSHKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqAddSSHKey.Unmarshal(m, b)
+}
+func (m *ReqAddSSHKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqAddSSHKey.Marshal(b, m, deterministic)
+}
+func (m *ReqAddSSHKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqAddSSHKey.Merge(m, src)
+}
+func (m *ReqAddSSHKey) XXX_Size() int {
+	return xxx_messageInfo_ReqAddSSHKey.Size(m)
+}
+func (m *ReqAddSSHKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqAddSSHKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqAddSSHKey proto.InternalMessageInfo
+
+func (m *ReqAddSSHKey) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqAddSSHKey) GetPublicKey() string {
+	if m != nil {
+		return m.PublicKey
+	}
+	return ""
+}
+
+type RespAddSSHKey struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Fingerprint          string   `protobuf:"bytes,3,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespAddSSHKey) Reset()         { *m = RespAddSSHKey{} }
+func (m *RespAddSSHKey) String() string { return proto.CompactTextString(m) }
+func (*RespAddSSHKey) ProtoMessage()    {}
+func (*RespAddSSHKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{19}
+}
+
+func (m *RespAddSSHKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespAddSSHKey.Unmarshal(m, b)
+}
+func (m *RespAddSSHKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespAddSSHKey.Marshal(b, m, deterministic)
+}
+func (m *RespAddSSHKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespAddSSHKey.Merge(m, src)
+}
+func (m *RespAddSSHKey) XXX_Size() int {
+	return xxx_messageInfo_RespAddSSHKey.Size(m)
+}
+func (m *RespAddSSHKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespAddSSHKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespAddSSHKey proto.InternalMessageInfo
+
+func (m *RespAddSSHKey) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespAddSSHKey) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *RespAddSSHKey) GetFingerprint() string {
+	if m != nil {
+		return m.Fingerprint
+	}
+	return ""
+}
+
+type ReqListSSHKeys struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqListSSHKeys) Reset()         { *m = ReqListSSHKeys{} }
+func (m *ReqListSSHKeys) String() string { return proto.CompactTextString(m) }
+func (*ReqListSSHKeys) ProtoMessage()    {}
+func (*ReqListSSHKeys) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{20}
+}
+
+func (m *ReqListSSHKeys) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqListSSHKeys.Unmarshal(m, b)
+}
+func (m *ReqListSSHKeys) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqListSSHKeys.Marshal(b, m, deterministic)
+}
+func (m *ReqListSSHKeys) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqListSSHKeys.Merge(m, src)
+}
+func (m *ReqListSSHKeys) XXX_Size() int {
+	return xxx_messageInfo_ReqListSSHKeys.Size(m)
+}
+func (m *ReqListSSHKeys) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqListSSHKeys.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqListSSHKeys proto.InternalMessageInfo
+
+func (m *ReqListSSHKeys) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+type RespListSSHKeys struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	KeyData              []byte   `protobuf:"bytes,3,opt,name=keyData,proto3" json:"keyData,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespListSSHKeys) Reset()         { *m = RespListSSHKeys{} }
+func (m *RespListSSHKeys) String() string { return proto.CompactTextString(m) }
+func (*RespListSSHKeys) ProtoMessage()    {}
+func (*RespListSSHKeys) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{21}
+}
+
+func (m *RespListSSHKeys) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespListSSHKeys.Unmarshal(m, b)
+}
+func (m *RespListSSHKeys) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespListSSHKeys.Marshal(b, m, deterministic)
+}
+func (m *RespListSSHKeys) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespListSSHKeys.Merge(m, src)
+}
+func (m *RespListSSHKeys) XXX_Size() int {
+	return xxx_messageInfo_RespListSSHKeys.Size(m)
+}
+func (m *RespListSSHKeys) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespListSSHKeys.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespListSSHKeys proto.InternalMessageInfo
+
+func (m *RespListSSHKeys) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespListSSHKeys) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *RespListSSHKeys) GetKeyData() []byte {
+	if m != nil {
+		return m.KeyData
+	}
+	return nil
+}
+
+type ReqDeleteSSHKey struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Fingerprint          string   `protobuf:"bytes,2,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqDeleteSSHKey) Reset()         { *m = ReqDeleteSSHKey{} }
+func (m *ReqDeleteSSHKey) String() string { return proto.CompactTextString(m) }
+func (*ReqDeleteSSHKey) ProtoMessage()    {}
+func (*ReqDeleteSSHKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{22}
+}
+
+func (m *ReqDeleteSSHKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqDeleteSSHKey.Unmarshal(m, b)
+}
+func (m *ReqDeleteSSHKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqDeleteSSHKey.Marshal(b, m, deterministic)
+}
+func (m *ReqDeleteSSHKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqDeleteSSHKey.Merge(m, src)
+}
+func (m *ReqDeleteSSHKey) XXX_Size() int {
+	return xxx_messageInfo_ReqDeleteSSHKey.Size(m)
+}
+func (m *ReqDeleteSSHKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqDeleteSSHKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqDeleteSSHKey proto.InternalMessageInfo
+
+func (m *ReqDeleteSSHKey) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqDeleteSSHKey) GetFingerprint() string {
+	if m != nil {
+		return m.Fingerprint
+	}
+	return ""
+}
+
+type RespDeleteSSHKey struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespDeleteSSHKey) Reset()         { *m = RespDeleteSSHKey{} }
+func (m *RespDeleteSSHKey) String() string { return proto.CompactTextString(m) }
+func (*RespDeleteSSHKey) ProtoMessage()    {}
+func (*RespDeleteSSHKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{23}
+}
+
+func (m *RespDeleteSSHKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespDeleteSSHKey.Unmarshal(m, b)
+}
+func (m *RespDeleteSSHKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespDeleteSSHKey.Marshal(b, m, deterministic)
+}
+func (m *RespDeleteSSHKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespDeleteSSHKey.Merge(m, src)
+}
+func (m *RespDeleteSSHKey) XXX_Size() int {
+	return xxx_messageInfo_RespDeleteSSHKey.Size(m)
+}
+func (m *RespDeleteSSHKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespDeleteSSHKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespDeleteSSHKey proto.InternalMessageInfo
+
+func (m *RespDeleteSSHKey) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespDeleteSSHKey) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type ReqUserFileList struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Prefix               string   `protobuf:"bytes,2,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Marker               string   `protobuf:"bytes,3,opt,name=marker,proto3" json:"marker,omitempty"`
+	Limit                int32    `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqUserFileList) Reset()         { *m = ReqUserFileList{} }
+func (m *ReqUserFileList) String() string { return proto.CompactTextString(m) }
+func (*ReqUserFileList) ProtoMessage()    {}
+func (*ReqUserFileList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{24}
+}
+
+func (m *ReqUserFileList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqUserFileList.Unmarshal(m, b)
+}
+func (m *ReqUserFileList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqUserFileList.Marshal(b, m, deterministic)
+}
+func (m *ReqUserFileList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqUserFileList.Merge(m, src)
+}
+func (m *ReqUserFileList) XXX_Size() int {
+	return xxx_messageInfo_ReqUserFileList.Size(m)
+}
+func (m *ReqUserFileList) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqUserFileList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqUserFileList proto.InternalMessageInfo
+
+func (m *ReqUserFileList) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqUserFileList) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+func (m *ReqUserFileList) GetMarker() string {
+	if m != nil {
+		return m.Marker
+	}
+	return ""
+}
+
+func (m *ReqUserFileList) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type RespUserFileList struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	FileData             []byte   `protobuf:"bytes,3,opt,name=fileData,proto3" json:"fileData,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespUserFileList) Reset()         { *m = RespUserFileList{} }
+func (m *RespUserFileList) String() string { return proto.CompactTextString(m) }
+func (*RespUserFileList) ProtoMessage()    {}
+func (*RespUserFileList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{25}
+}
+
+func (m *RespUserFileList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespUserFileList.Unmarshal(m, b)
+}
+func (m *RespUserFileList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespUserFileList.Marshal(b, m, deterministic)
+}
+func (m *RespUserFileList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespUserFileList.Merge(m, src)
+}
+func (m *RespUserFileList) XXX_Size() int {
+	return xxx_messageInfo_RespUserFileList.Size(m)
+}
+func (m *RespUserFileList) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespUserFileList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespUserFileList proto.InternalMessageInfo
+
+func (m *RespUserFileList) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespUserFileList) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *RespUserFileList) GetFileData() []byte {
+	if m != nil {
+		return m.FileData
+	}
+	return nil
+}
+
+type ReqUserFileDelete struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	FileName             string   `protobuf:"bytes,2,opt,name=fileName,proto3" json:"fileName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqUserFileDelete) Reset()         { *m = ReqUserFileDelete{} }
+func (m *ReqUserFileDelete) String() string { return proto.CompactTextString(m) }
+func (*ReqUserFileDelete) ProtoMessage()    {}
+func (*ReqUserFileDelete) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{26}
+}
+
+func (m *ReqUserFileDelete) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqUserFileDelete.Unmarshal(m, b)
+}
+func (m *ReqUserFileDelete) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqUserFileDelete.Marshal(b, m, deterministic)
+}
+func (m *ReqUserFileDelete) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqUserFileDelete.Merge(m, src)
+}
+func (m *ReqUserFileDelete) XXX_Size() int {
+	return xxx_messageInfo_ReqUserFileDelete.Size(m)
+}
+func (m *ReqUserFileDelete) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqUserFileDelete.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqUserFileDelete proto.InternalMessageInfo
+
+func (m *ReqUserFileDelete) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqUserFileDelete) GetFileName() string {
+	if m != nil {
+		return m.FileName
+	}
+	return ""
+}
+
+type RespUserFileDelete struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespUserFileDelete) Reset()         { *m = RespUserFileDelete{} }
+func (m *RespUserFileDelete) String() string { return proto.CompactTextString(m) }
+func (*RespUserFileDelete) ProtoMessage()    {}
+func (*RespUserFileDelete) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{27}
+}
+
+func (m *RespUserFileDelete) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespUserFileDelete.Unmarshal(m, b)
+}
+func (m *RespUserFileDelete) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespUserFileDelete.Marshal(b, m, deterministic)
+}
+func (m *RespUserFileDelete) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespUserFileDelete.Merge(m, src)
+}
+func (m *RespUserFileDelete) XXX_Size() int {
+	return xxx_messageInfo_RespUserFileDelete.Size(m)
+}
+func (m *RespUserFileDelete) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespUserFileDelete.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespUserFileDelete proto.InternalMessageInfo
+
+func (m *RespUserFileDelete) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespUserFileDelete) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type ReqUserFileMove struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	SrcName              string   `protobuf:"bytes,2,opt,name=srcName,proto3" json:"srcName,omitempty"`
+	DestName             string   `protobuf:"bytes,3,opt,name=destName,proto3" json:"destName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqUserFileMove) Reset()         { *m = ReqUserFileMove{} }
+func (m *ReqUserFileMove) String() string { return proto.CompactTextString(m) }
+func (*ReqUserFileMove) ProtoMessage()    {}
+func (*ReqUserFileMove) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{28}
+}
+
+func (m *ReqUserFileMove) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqUserFileMove.Unmarshal(m, b)
+}
+func (m *ReqUserFileMove) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqUserFileMove.Marshal(b, m, deterministic)
+}
+func (m *ReqUserFileMove) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqUserFileMove.Merge(m, src)
+}
+func (m *ReqUserFileMove) XXX_Size() int {
+	return xxx_messageInfo_ReqUserFileMove.Size(m)
+}
+func (m *ReqUserFileMove) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqUserFileMove.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqUserFileMove proto.InternalMessageInfo
+
+func (m *ReqUserFileMove) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqUserFileMove) GetSrcName() string {
+	if m != nil {
+		return m.SrcName
+	}
+	return ""
+}
+
+func (m *ReqUserFileMove) GetDestName() string {
+	if m != nil {
+		return m.DestName
+	}
+	return ""
+}
+
+type RespUserFileMove struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespUserFileMove) Reset()         { *m = RespUserFileMove{} }
+func (m *RespUserFileMove) String() string { return proto.CompactTextString(m) }
+func (*RespUserFileMove) ProtoMessage()    {}
+func (*RespUserFileMove) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{29}
+}
+
+func (m *RespUserFileMove) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespUserFileMove.Unmarshal(m, b)
+}
+func (m *RespUserFileMove) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespUserFileMove.Marshal(b, m, deterministic)
+}
+func (m *RespUserFileMove) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespUserFileMove.Merge(m, src)
+}
+func (m *RespUserFileMove) XXX_Size() int {
+	return xxx_messageInfo_RespUserFileMove.Size(m)
+}
+func (m *RespUserFileMove) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespUserFileMove.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespUserFileMove proto.InternalMessageInfo
+
+func (m *RespUserFileMove) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespUserFileMove) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type ReqUserUsage struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqUserUsage) Reset()         { *m = ReqUserUsage{} }
+func (m *ReqUserUsage) String() string { return proto.CompactTextString(m) }
+func (*ReqUserUsage) ProtoMessage()    {}
+func (*ReqUserUsage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{30}
+}
+
+func (m *ReqUserUsage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqUserUsage.Unmarshal(m, b)
+}
+func (m *ReqUserUsage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqUserUsage.Marshal(b, m, deterministic)
+}
+func (m *ReqUserUsage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqUserUsage.Merge(m, src)
+}
+func (m *ReqUserUsage) XXX_Size() int {
+	return xxx_messageInfo_ReqUserUsage.Size(m)
+}
+func (m *ReqUserUsage) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqUserUsage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqUserUsage proto.InternalMessageInfo
+
+func (m *ReqUserUsage) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+type RespUserUsage struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	FileCount            int64    `protobuf:"varint,3,opt,name=fileCount,proto3" json:"fileCount,omitempty"`
+	TotalSize            int64    `protobuf:"varint,4,opt,name=totalSize,proto3" json:"totalSize,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespUserUsage) Reset()         { *m = RespUserUsage{} }
+func (m *RespUserUsage) String() string { return proto.CompactTextString(m) }
+func (*RespUserUsage) ProtoMessage()    {}
+func (*RespUserUsage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{31}
+}
+
+func (m *RespUserUsage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespUserUsage.Unmarshal(m, b)
+}
+func (m *RespUserUsage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespUserUsage.Marshal(b, m, deterministic)
+}
+func (m *RespUserUsage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespUserUsage.Merge(m, src)
+}
+func (m *RespUserUsage) XXX_Size() int {
+	return xxx_messageInfo_RespUserUsage.Size(m)
+}
+func (m *RespUserUsage) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespUserUsage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespUserUsage proto.InternalMessageInfo
+
+func (m *RespUserUsage) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespUserUsage) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *RespUserUsage) GetFileCount() int64 {
+	if m != nil {
+		return m.FileCount
+	}
+	return 0
+}
+
+func (m *RespUserUsage) GetTotalSize() int64 {
+	if m != nil {
+		return m.TotalSize
+	}
+	return 0
+}
+
+type ReqUserFileRestore struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	FileName             string   `protobuf:"bytes,2,opt,name=fileName,proto3" json:"fileName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqUserFileRestore) Reset()         { *m = ReqUserFileRestore{} }
+func (m *ReqUserFileRestore) String() string { return proto.CompactTextString(m) }
+func (*ReqUserFileRestore) ProtoMessage()    {}
+func (*ReqUserFileRestore) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{32}
+}
+
+func (m *ReqUserFileRestore) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqUserFileRestore.Unmarshal(m, b)
+}
+func (m *ReqUserFileRestore) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqUserFileRestore.Marshal(b, m, deterministic)
+}
+func (m *ReqUserFileRestore) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqUserFileRestore.Merge(m, src)
+}
+func (m *ReqUserFileRestore) XXX_Size() int {
+	return xxx_messageInfo_ReqUserFileRestore.Size(m)
+}
+func (m *ReqUserFileRestore) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqUserFileRestore.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqUserFileRestore proto.InternalMessageInfo
+
+func (m *ReqUserFileRestore) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqUserFileRestore) GetFileName() string {
+	if m != nil {
+		return m.FileName
+	}
+	return ""
+}
+
+type RespUserFileRestore struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespUserFileRestore) Reset()         { *m = RespUserFileRestore{} }
+func (m *RespUserFileRestore) String() string { return proto.CompactTextString(m) }
+func (*RespUserFileRestore) ProtoMessage()    {}
+func (*RespUserFileRestore) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{33}
+}
+
+func (m *RespUserFileRestore) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespUserFileRestore.Unmarshal(m, b)
+}
+func (m *RespUserFileRestore) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespUserFileRestore.Marshal(b, m, deterministic)
+}
+func (m *RespUserFileRestore) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespUserFileRestore.Merge(m, src)
+}
+func (m *RespUserFileRestore) XXX_Size() int {
+	return xxx_messageInfo_RespUserFileRestore.Size(m)
+}
+func (m *RespUserFileRestore) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespUserFileRestore.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespUserFileRestore proto.InternalMessageInfo
+
+func (m *RespUserFileRestore) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespUserFileRestore) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type ReqUserFileChanges struct {
+	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	// cursor : 上次返回的游标, 小于0时只返回最新游标
+	Cursor               int64    `protobuf:"varint,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit                int32    `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqUserFileChanges) Reset()         { *m = ReqUserFileChanges{} }
+func (m *ReqUserFileChanges) String() string { return proto.CompactTextString(m) }
+func (*ReqUserFileChanges) ProtoMessage()    {}
+func (*ReqUserFileChanges) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{34}
+}
+
+func (m *ReqUserFileChanges) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqUserFileChanges.Unmarshal(m, b)
+}
+func (m *ReqUserFileChanges) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqUserFileChanges.Marshal(b, m, deterministic)
+}
+func (m *ReqUserFileChanges) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqUserFileChanges.Merge(m, src)
+}
+func (m *ReqUserFileChanges) XXX_Size() int {
+	return xxx_messageInfo_ReqUserFileChanges.Size(m)
+}
+func (m *ReqUserFileChanges) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqUserFileChanges.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqUserFileChanges proto.InternalMessageInfo
+
+func (m *ReqUserFileChanges) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqUserFileChanges) GetCursor() int64 {
+	if m != nil {
+		return m.Cursor
+	}
+	return 0
+}
+
+func (m *ReqUserFileChanges) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type RespUserFileChanges struct {
+	Code    int32  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Events  []byte `protobuf:"bytes,3,opt,name=events,proto3" json:"events,omitempty"`
+	// cursor : 已返回的最后一个事件的游标, 没有新事件时与请求相同
+	Cursor               int64    `protobuf:"varint,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespUserFileChanges) Reset()         { *m = RespUserFileChanges{} }
+func (m *RespUserFileChanges) String() string { return proto.CompactTextString(m) }
+func (*RespUserFileChanges) ProtoMessage()    {}
+func (*RespUserFileChanges) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{35}
+}
+
+func (m *RespUserFileChanges) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespUserFileChanges.Unmarshal(m, b)
+}
+func (m *RespUserFileChanges) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespUserFileChanges.Marshal(b, m, deterministic)
+}
+func (m *RespUserFileChanges) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespUserFileChanges.Merge(m, src)
+}
+func (m *RespUserFileChanges) XXX_Size() int {
+	return xxx_messageInfo_RespUserFileChanges.Size(m)
+}
+func (m *RespUserFileChanges) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespUserFileChanges.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespUserFileChanges proto.InternalMessageInfo
+
+func (m *RespUserFileChanges) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespUserFileChanges) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *RespUserFileChanges) GetEvents() []byte {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+func (m *RespUserFileChanges) GetCursor() int64 {
+	if m != nil {
+		return m.Cursor
+	}
+	return 0
+}
+
+type ReqCreateWebhook struct {
+	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Url      string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	// events : 订阅的事件类型, 逗号分隔, 为空表示全部
+	Events               string   `protobuf:"bytes,3,opt,name=events,proto3" json:"events,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqCreateWebhook) Reset()         { *m = ReqCreateWebhook{} }
+func (m *ReqCreateWebhook) String() string { return proto.CompactTextString(m) }
+func (*ReqCreateWebhook) ProtoMessage()    {}
+func (*ReqCreateWebhook) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{36}
+}
+
+func (m *ReqCreateWebhook) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqCreateWebhook.Unmarshal(m, b)
+}
+func (m *ReqCreateWebhook) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqCreateWebhook.Marshal(b, m, deterministic)
+}
+func (m *ReqCreateWebhook) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqCreateWebhook.Merge(m, src)
+}
+func (m *ReqCreateWebhook) XXX_Size() int {
+	return xxx_messageInfo_ReqCreateWebhook.Size(m)
+}
+func (m *ReqCreateWebhook) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqCreateWebhook.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqCreateWebhook proto.InternalMessageInfo
+
+func (m *ReqCreateWebhook) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqCreateWebhook) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *ReqCreateWebhook) GetEvents() string {
+	if m != nil {
+		return m.Events
+	}
+	return ""
+}
+
+type RespCreateWebhook struct {
+	Code        int32  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message     string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	WebhookData []byte `protobuf:"bytes,3,opt,name=webhookData,proto3" json:"webhookData,omitempty"`
+	// secret : 签名密钥, 只在创建时返回
+	Secret               string   `protobuf:"bytes,4,opt,name=secret,proto3" json:"secret,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespCreateWebhook) Reset()         { *m = RespCreateWebhook{} }
+func (m *RespCreateWebhook) String() string { return proto.CompactTextString(m) }
+func (*RespCreateWebhook) ProtoMessage()    {}
+func (*RespCreateWebhook) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{37}
+}
+
+func (m *RespCreateWebhook) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespCreateWebhook.Unmarshal(m, b)
+}
+func (m *RespCreateWebhook) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespCreateWebhook.Marshal(b, m, deterministic)
+}
+func (m *RespCreateWebhook) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespCreateWebhook.Merge(m, src)
+}
+func (m *RespCreateWebhook) XXX_Size() int {
+	return xxx_messageInfo_RespCreateWebhook.Size(m)
+}
+func (m *RespCreateWebhook) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespCreateWebhook.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespCreateWebhook proto.InternalMessageInfo
+
+func (m *RespCreateWebhook) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespCreateWebhook) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *RespCreateWebhook) GetWebhookData() []byte {
+	if m != nil {
+		return m.WebhookData
+	}
+	return nil
+}
+
+func (m *RespCreateWebhook) GetSecret() string {
+	if m != nil {
+		return m.Secret
+	}
+	return ""
+}
+
+type ReqListWebhooks struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqListWebhooks) Reset()         { *m = ReqListWebhooks{} }
+func (m *ReqListWebhooks) String() string { return proto.CompactTextString(m) }
+func (*ReqListWebhooks) ProtoMessage()    {}
+func (*ReqListWebhooks) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{38}
+}
+
+func (m *ReqListWebhooks) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqListWebhooks.Unmarshal(m, b)
+}
+func (m *ReqListWebhooks) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqListWebhooks.Marshal(b, m, deterministic)
+}
+func (m *ReqListWebhooks) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqListWebhooks.Merge(m, src)
+}
+func (m *ReqListWebhooks) XXX_Size() int {
+	return xxx_messageInfo_ReqListWebhooks.Size(m)
+}
+func (m *ReqListWebhooks) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqListWebhooks.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqListWebhooks proto.InternalMessageInfo
+
+func (m *ReqListWebhooks) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+type RespListWebhooks struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	WebhookData          []byte   `protobuf:"bytes,3,opt,name=webhookData,proto3" json:"webhookData,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespListWebhooks) Reset()         { *m = RespListWebhooks{} }
+func (m *RespListWebhooks) String() string { return proto.CompactTextString(m) }
+func (*RespListWebhooks) ProtoMessage()    {}
+func (*RespListWebhooks) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{39}
+}
+
+func (m *RespListWebhooks) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespListWebhooks.Unmarshal(m, b)
+}
+func (m *RespListWebhooks) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespListWebhooks.Marshal(b, m, deterministic)
+}
+func (m *RespListWebhooks) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespListWebhooks.Merge(m, src)
+}
+func (m *RespListWebhooks) XXX_Size() int {
+	return xxx_messageInfo_RespListWebhooks.Size(m)
+}
+func (m *RespListWebhooks) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespListWebhooks.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespListWebhooks proto.InternalMessageInfo
+
+func (m *RespListWebhooks) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespListWebhooks) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *RespListWebhooks) GetWebhookData() []byte {
+	if m != nil {
+		return m.WebhookData
+	}
+	return nil
+}
+
+type ReqDeleteWebhook struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Id                   int64    `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqDeleteWebhook) Reset()         { *m = ReqDeleteWebhook{} }
+func (m *ReqDeleteWebhook) String() string { return proto.CompactTextString(m) }
+func (*ReqDeleteWebhook) ProtoMessage()    {}
+func (*ReqDeleteWebhook) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{40}
+}
+
+func (m *ReqDeleteWebhook) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqDeleteWebhook.Unmarshal(m, b)
+}
+func (m *ReqDeleteWebhook) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqDeleteWebhook.Marshal(b, m, deterministic)
+}
+func (m *ReqDeleteWebhook) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqDeleteWebhook.Merge(m, src)
+}
+func (m *ReqDeleteWebhook) XXX_Size() int {
+	return xxx_messageInfo_ReqDeleteWebhook.Size(m)
+}
+func (m *ReqDeleteWebhook) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqDeleteWebhook.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqDeleteWebhook proto.InternalMessageInfo
+
+func (m *ReqDeleteWebhook) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqDeleteWebhook) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+type RespDeleteWebhook struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespDeleteWebhook) Reset()         { *m = RespDeleteWebhook{} }
+func (m *RespDeleteWebhook) String() string { return proto.CompactTextString(m) }
+func (*RespDeleteWebhook) ProtoMessage()    {}
+func (*RespDeleteWebhook) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{41}
+}
+
+func (m *RespDeleteWebhook) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespDeleteWebhook.Unmarshal(m, b)
+}
+func (m *RespDeleteWebhook) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespDeleteWebhook.Marshal(b, m, deterministic)
+}
+func (m *RespDeleteWebhook) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespDeleteWebhook.Merge(m, src)
+}
+func (m *RespDeleteWebhook) XXX_Size() int {
+	return xxx_messageInfo_RespDeleteWebhook.Size(m)
+}
+func (m *RespDeleteWebhook) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespDeleteWebhook.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespDeleteWebhook proto.InternalMessageInfo
+
+func (m *RespDeleteWebhook) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespDeleteWebhook) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type ReqListWebhookDeliveries struct {
+	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	// hookId : 为0时查询全部webhook
+	HookId               int64    `protobuf:"varint,2,opt,name=hookId,proto3" json:"hookId,omitempty"`
+	Limit                int32    `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqListWebhookDeliveries) Reset()         { *m = ReqListWebhookDeliveries{} }
+func (m *ReqListWebhookDeliveries) String() string { return proto.CompactTextString(m) }
+func (*ReqListWebhookDeliveries) ProtoMessage()    {}
+func (*ReqListWebhookDeliveries) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{42}
+}
+
+func (m *ReqListWebhookDeliveries) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqListWebhookDeliveries.Unmarshal(m, b)
+}
+func (m *ReqListWebhookDeliveries) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqListWebhookDeliveries.Marshal(b, m, deterministic)
+}
+func (m *ReqListWebhookDeliveries) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqListWebhookDeliveries.Merge(m, src)
+}
+func (m *ReqListWebhookDeliveries) XXX_Size() int {
+	return xxx_messageInfo_ReqListWebhookDeliveries.Size(m)
+}
+func (m *ReqListWebhookDeliveries) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqListWebhookDeliveries.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqListWebhookDeliveries proto.InternalMessageInfo
+
+func (m *ReqListWebhookDeliveries) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqListWebhookDeliveries) GetHookId() int64 {
+	if m != nil {
+		return m.HookId
+	}
+	return 0
+}
+
+func (m *ReqListWebhookDeliveries) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type RespListWebhookDeliveries struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	DeliveryData         []byte   `protobuf:"bytes,3,opt,name=deliveryData,proto3" json:"deliveryData,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespListWebhookDeliveries) Reset()         { *m = RespListWebhookDeliveries{} }
+func (m *RespListWebhookDeliveries) String() string { return proto.CompactTextString(m) }
+func (*RespListWebhookDeliveries) ProtoMessage()    {}
+func (*RespListWebhookDeliveries) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{43}
+}
+
+func (m *RespListWebhookDeliveries) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespListWebhookDeliveries.Unmarshal(m, b)
+}
+func (m *RespListWebhookDeliveries) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespListWebhookDeliveries.Marshal(b, m, deterministic)
+}
+func (m *RespListWebhookDeliveries) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespListWebhookDeliveries.Merge(m, src)
+}
+func (m *RespListWebhookDeliveries) XXX_Size() int {
+	return xxx_messageInfo_RespListWebhookDeliveries.Size(m)
+}
+func (m *RespListWebhookDeliveries) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespListWebhookDeliveries.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespListWebhookDeliveries proto.InternalMessageInfo
+
+func (m *RespListWebhookDeliveries) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespListWebhookDeliveries) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *RespListWebhookDeliveries) GetDeliveryData() []byte {
+	if m != nil {
+		return m.DeliveryData
+	}
+	return nil
+}
+
+type ReqSetVaultKeyPair struct {
+	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	// publicKey : 公钥(base64)
+	PublicKey string `protobuf:"bytes,2,opt,name=publicKey,proto3" json:"publicKey,omitempty"`
+	// secretKey : 口令派生密钥加密后的私钥(base64), 服务端不能解密
+	SecretKey            string   `protobuf:"bytes,3,opt,name=secretKey,proto3" json:"secretKey,omitempty"`
+	KdfSalt              string   `protobuf:"bytes,4,opt,name=kdfSalt,proto3" json:"kdfSalt,omitempty"`
+	KdfParams            string   `protobuf:"bytes,5,opt,name=kdfParams,proto3" json:"kdfParams,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqSetVaultKeyPair) Reset()         { *m = ReqSetVaultKeyPair{} }
+func (m *ReqSetVaultKeyPair) String() string { return proto.CompactTextString(m) }
+func (*ReqSetVaultKeyPair) ProtoMessage()    {}
+func (*ReqSetVaultKeyPair) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{44}
+}
+
+func (m *ReqSetVaultKeyPair) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqSetVaultKeyPair.Unmarshal(m, b)
+}
+func (m *ReqSetVaultKeyPair) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqSetVaultKeyPair.Marshal(b, m, deterministic)
+}
+func (m *ReqSetVaultKeyPair) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqSetVaultKeyPair.Merge(m, src)
+}
+func (m *ReqSetVaultKeyPair) XXX_Size() int {
+	return xxx_messageInfo_ReqSetVaultKeyPair.Size(m)
+}
+func (m *ReqSetVaultKeyPair) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqSetVaultKeyPair.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqSetVaultKeyPair proto.InternalMessageInfo
+
+func (m *ReqSetVaultKeyPair) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqSetVaultKeyPair) GetPublicKey() string {
+	if m != nil {
+		return m.PublicKey
+	}
+	return ""
+}
+
+func (m *ReqSetVaultKeyPair) GetSecretKey() string {
+	if m != nil {
+		return m.SecretKey
+	}
+	return ""
+}
+
+func (m *ReqSetVaultKeyPair) GetKdfSalt() string {
+	if m != nil {
+		return m.KdfSalt
+	}
+	return ""
+}
+
+func (m *ReqSetVaultKeyPair) GetKdfParams() string {
+	if m != nil {
+		return m.KdfParams
+	}
+	return ""
+}
+
+type RespSetVaultKeyPair struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespSetVaultKeyPair) Reset()         { *m = RespSetVaultKeyPair{} }
+func (m *RespSetVaultKeyPair) String() string { return proto.CompactTextString(m) }
+func (*RespSetVaultKeyPair) ProtoMessage()    {}
+func (*RespSetVaultKeyPair) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{45}
+}
+
+func (m *RespSetVaultKeyPair) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespSetVaultKeyPair.Unmarshal(m, b)
+}
+func (m *RespSetVaultKeyPair) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespSetVaultKeyPair.Marshal(b, m, deterministic)
+}
+func (m *RespSetVaultKeyPair) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespSetVaultKeyPair.Merge(m, src)
+}
+func (m *RespSetVaultKeyPair) XXX_Size() int {
+	return xxx_messageInfo_RespSetVaultKeyPair.Size(m)
+}
+func (m *RespSetVaultKeyPair) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespSetVaultKeyPair.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespSetVaultKeyPair proto.InternalMessageInfo
+
+func (m *RespSetVaultKeyPair) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespSetVaultKeyPair) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type ReqGetVaultKeyPair struct {
+	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	// target : 查询的用户, 为空表示自己
+	Target               string   `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqGetVaultKeyPair) Reset()         { *m = ReqGetVaultKeyPair{} }
+func (m *ReqGetVaultKeyPair) String() string { return proto.CompactTextString(m) }
+func (*ReqGetVaultKeyPair) ProtoMessage()    {}
+func (*ReqGetVaultKeyPair) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{46}
+}
+
+func (m *ReqGetVaultKeyPair) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqGetVaultKeyPair.Unmarshal(m, b)
+}
+func (m *ReqGetVaultKeyPair) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqGetVaultKeyPair.Marshal(b, m, deterministic)
+}
+func (m *ReqGetVaultKeyPair) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqGetVaultKeyPair.Merge(m, src)
+}
+func (m *ReqGetVaultKeyPair) XXX_Size() int {
+	return xxx_messageInfo_ReqGetVaultKeyPair.Size(m)
+}
+func (m *ReqGetVaultKeyPair) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqGetVaultKeyPair.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqGetVaultKeyPair proto.InternalMessageInfo
+
+func (m *ReqGetVaultKeyPair) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqGetVaultKeyPair) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
+type RespGetVaultKeyPair struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	KeyPairData          []byte   `protobuf:"bytes,3,opt,name=keyPairData,proto3" json:"keyPairData,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespGetVaultKeyPair) Reset()         { *m = RespGetVaultKeyPair{} }
+func (m *RespGetVaultKeyPair) String() string { return proto.CompactTextString(m) }
+func (*RespGetVaultKeyPair) ProtoMessage()    {}
+func (*RespGetVaultKeyPair) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{47}
+}
+
+func (m *RespGetVaultKeyPair) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespGetVaultKeyPair.Unmarshal(m, b)
+}
+func (m *RespGetVaultKeyPair) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespGetVaultKeyPair.Marshal(b, m, deterministic)
+}
+func (m *RespGetVaultKeyPair) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespGetVaultKeyPair.Merge(m, src)
+}
+func (m *RespGetVaultKeyPair) XXX_Size() int {
+	return xxx_messageInfo_RespGetVaultKeyPair.Size(m)
+}
+func (m *RespGetVaultKeyPair) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespGetVaultKeyPair.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespGetVaultKeyPair proto.InternalMessageInfo
+
+func (m *RespGetVaultKeyPair) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespGetVaultKeyPair) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *RespGetVaultKeyPair) GetKeyPairData() []byte {
+	if m != nil {
+		return m.KeyPairData
+	}
+	return nil
+}
+
+type ReqCreateVault struct {
+	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	// folder : 目录(以/结尾)
+	Folder string `protobuf:"bytes,2,opt,name=folder,proto3" json:"folder,omitempty"`
+	// wrappedKey : 以自己的公钥加密的目录密钥(base64)
+	WrappedKey           string   `protobuf:"bytes,3,opt,name=wrappedKey,proto3" json:"wrappedKey,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqCreateVault) Reset()         { *m = ReqCreateVault{} }
+func (m *ReqCreateVault) String() string { return proto.CompactTextString(m) }
+func (*ReqCreateVault) ProtoMessage()    {}
+func (*ReqCreateVault) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{48}
+}
+
+func (m *ReqCreateVault) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqCreateVault.Unmarshal(m, b)
+}
+func (m *ReqCreateVault) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqCreateVault.Marshal(b, m, deterministic)
+}
+func (m *ReqCreateVault) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqCreateVault.Merge(m, src)
+}
+func (m *ReqCreateVault) XXX_Size() int {
+	return xxx_messageInfo_ReqCreateVault.Size(m)
+}
+func (m *ReqCreateVault) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqCreateVault.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqCreateVault proto.InternalMessageInfo
+
+func (m *ReqCreateVault) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqCreateVault) GetFolder() string {
+	if m != nil {
+		return m.Folder
+	}
+	return ""
+}
+
+func (m *ReqCreateVault) GetWrappedKey() string {
+	if m != nil {
+		return m.WrappedKey
+	}
+	return ""
+}
+
+type RespCreateVault struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespCreateVault) Reset()         { *m = RespCreateVault{} }
+func (m *RespCreateVault) String() string { return proto.CompactTextString(m) }
+func (*RespCreateVault) ProtoMessage()    {}
+func (*RespCreateVault) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{49}
+}
+
+func (m *RespCreateVault) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespCreateVault.Unmarshal(m, b)
+}
+func (m *RespCreateVault) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespCreateVault.Marshal(b, m, deterministic)
+}
+func (m *RespCreateVault) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespCreateVault.Merge(m, src)
+}
+func (m *RespCreateVault) XXX_Size() int {
+	return xxx_messageInfo_RespCreateVault.Size(m)
+}
+func (m *RespCreateVault) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespCreateVault.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespCreateVault proto.InternalMessageInfo
+
+func (m *RespCreateVault) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespCreateVault) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type ReqListVaults struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqListVaults) Reset()         { *m = ReqListVaults{} }
+func (m *ReqListVaults) String() string { return proto.CompactTextString(m) }
+func (*ReqListVaults) ProtoMessage()    {}
+func (*ReqListVaults) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{50}
+}
+
+func (m *ReqListVaults) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqListVaults.Unmarshal(m, b)
+}
+func (m *ReqListVaults) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqListVaults.Marshal(b, m, deterministic)
+}
+func (m *ReqListVaults) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqListVaults.Merge(m, src)
+}
+func (m *ReqListVaults) XXX_Size() int {
+	return xxx_messageInfo_ReqListVaults.Size(m)
+}
+func (m *ReqListVaults) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqListVaults.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqListVaults proto.InternalMessageInfo
+
+func (m *ReqListVaults) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+type RespListVaults struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	VaultData            []byte   `protobuf:"bytes,3,opt,name=vaultData,proto3" json:"vaultData,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespListVaults) Reset()         { *m = RespListVaults{} }
+func (m *RespListVaults) String() string { return proto.CompactTextString(m) }
+func (*RespListVaults) ProtoMessage()    {}
+func (*RespListVaults) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{51}
+}
+
+func (m *RespListVaults) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespListVaults.Unmarshal(m, b)
+}
+func (m *RespListVaults) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespListVaults.Marshal(b, m, deterministic)
+}
+func (m *RespListVaults) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespListVaults.Merge(m, src)
+}
+func (m *RespListVaults) XXX_Size() int {
+	return xxx_messageInfo_RespListVaults.Size(m)
+}
+func (m *RespListVaults) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespListVaults.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespListVaults proto.InternalMessageInfo
+
+func (m *RespListVaults) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespListVaults) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *RespListVaults) GetVaultData() []byte {
+	if m != nil {
+		return m.VaultData
+	}
+	return nil
+}
+
+type ReqShareVault struct {
+	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Folder   string `protobuf:"bytes,2,opt,name=folder,proto3" json:"folder,omitempty"`
+	Grantee  string `protobuf:"bytes,3,opt,name=grantee,proto3" json:"grantee,omitempty"`
+	// wrappedKey : 以对方公钥加密的目录密钥(base64)
+	WrappedKey           string   `protobuf:"bytes,4,opt,name=wrappedKey,proto3" json:"wrappedKey,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqShareVault) Reset()         { *m = ReqShareVault{} }
+func (m *ReqShareVault) String() string { return proto.CompactTextString(m) }
+func (*ReqShareVault) ProtoMessage()    {}
+func (*ReqShareVault) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{52}
+}
+
+func (m *ReqShareVault) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqShareVault.Unmarshal(m, b)
+}
+func (m *ReqShareVault) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqShareVault.Marshal(b, m, deterministic)
+}
+func (m *ReqShareVault) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqShareVault.Merge(m, src)
+}
+func (m *ReqShareVault) XXX_Size() int {
+	return xxx_messageInfo_ReqShareVault.Size(m)
+}
+func (m *ReqShareVault) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqShareVault.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqShareVault proto.InternalMessageInfo
+
+func (m *ReqShareVault) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqShareVault) GetFolder() string {
+	if m != nil {
+		return m.Folder
+	}
+	return ""
+}
+
+func (m *ReqShareVault) GetGrantee() string {
+	if m != nil {
+		return m.Grantee
+	}
+	return ""
+}
+
+func (m *ReqShareVault) GetWrappedKey() string {
+	if m != nil {
+		return m.WrappedKey
+	}
+	return ""
+}
+
+type RespShareVault struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespShareVault) Reset()         { *m = RespShareVault{} }
+func (m *RespShareVault) String() string { return proto.CompactTextString(m) }
+func (*RespShareVault) ProtoMessage()    {}
+func (*RespShareVault) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{53}
+}
+
+func (m *RespShareVault) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespShareVault.Unmarshal(m, b)
+}
+func (m *RespShareVault) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespShareVault.Marshal(b, m, deterministic)
+}
+func (m *RespShareVault) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespShareVault.Merge(m, src)
+}
+func (m *RespShareVault) XXX_Size() int {
+	return xxx_messageInfo_RespShareVault.Size(m)
+}
+func (m *RespShareVault) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespShareVault.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespShareVault proto.InternalMessageInfo
+
+func (m *RespShareVault) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespShareVault) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type ReqUnshareVault struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Folder               string   `protobuf:"bytes,2,opt,name=folder,proto3" json:"folder,omitempty"`
+	Grantee              string   `protobuf:"bytes,3,opt,name=grantee,proto3" json:"grantee,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqUnshareVault) Reset()         { *m = ReqUnshareVault{} }
+func (m *ReqUnshareVault) String() string { return proto.CompactTextString(m) }
+func (*ReqUnshareVault) ProtoMessage()    {}
+func (*ReqUnshareVault) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{54}
+}
+
+func (m *ReqUnshareVault) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqUnshareVault.Unmarshal(m, b)
+}
+func (m *ReqUnshareVault) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqUnshareVault.Marshal(b, m, deterministic)
+}
+func (m *ReqUnshareVault) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqUnshareVault.Merge(m, src)
+}
+func (m *ReqUnshareVault) XXX_Size() int {
+	return xxx_messageInfo_ReqUnshareVault.Size(m)
+}
+func (m *ReqUnshareVault) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqUnshareVault.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqUnshareVault proto.InternalMessageInfo
+
+func (m *ReqUnshareVault) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqUnshareVault) GetFolder() string {
+	if m != nil {
+		return m.Folder
+	}
+	return ""
+}
+
+func (m *ReqUnshareVault) GetGrantee() string {
+	if m != nil {
+		return m.Grantee
+	}
+	return ""
+}
+
+type RespUnshareVault struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespUnshareVault) Reset()         { *m = RespUnshareVault{} }
+func (m *RespUnshareVault) String() string { return proto.CompactTextString(m) }
+func (*RespUnshareVault) ProtoMessage()    {}
+func (*RespUnshareVault) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{55}
+}
+
+func (m *RespUnshareVault) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespUnshareVault.Unmarshal(m, b)
+}
+func (m *RespUnshareVault) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespUnshareVault.Marshal(b, m, deterministic)
+}
+func (m *RespUnshareVault) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespUnshareVault.Merge(m, src)
+}
+func (m *RespUnshareVault) XXX_Size() int {
+	return xxx_messageInfo_RespUnshareVault.Size(m)
+}
+func (m *RespUnshareVault) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespUnshareVault.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespUnshareVault proto.InternalMessageInfo
+
+func (m *RespUnshareVault) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespUnshareVault) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type ReqListVaultMembers struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Folder               string   `protobuf:"bytes,2,opt,name=folder,proto3" json:"folder,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqListVaultMembers) Reset()         { *m = ReqListVaultMembers{} }
+func (m *ReqListVaultMembers) String() string { return proto.CompactTextString(m) }
+func (*ReqListVaultMembers) ProtoMessage()    {}
+func (*ReqListVaultMembers) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{56}
+}
+
+func (m *ReqListVaultMembers) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqListVaultMembers.Unmarshal(m, b)
+}
+func (m *ReqListVaultMembers) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqListVaultMembers.Marshal(b, m, deterministic)
+}
+func (m *ReqListVaultMembers) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqListVaultMembers.Merge(m, src)
+}
+func (m *ReqListVaultMembers) XXX_Size() int {
+	return xxx_messageInfo_ReqListVaultMembers.Size(m)
+}
+func (m *ReqListVaultMembers) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqListVaultMembers.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqListVaultMembers proto.InternalMessageInfo
+
+func (m *ReqListVaultMembers) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqListVaultMembers) GetFolder() string {
+	if m != nil {
+		return m.Folder
+	}
+	return ""
+}
+
+type RespListVaultMembers struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	MemberData           []byte   `protobuf:"bytes,3,opt,name=memberData,proto3" json:"memberData,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespListVaultMembers) Reset()         { *m = RespListVaultMembers{} }
+func (m *RespListVaultMembers) String() string { return proto.CompactTextString(m) }
+func (*RespListVaultMembers) ProtoMessage()    {}
+func (*RespListVaultMembers) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{57}
+}
+
+func (m *RespListVaultMembers) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespListVaultMembers.Unmarshal(m, b)
+}
+func (m *RespListVaultMembers) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespListVaultMembers.Marshal(b, m, deterministic)
+}
+func (m *RespListVaultMembers) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespListVaultMembers.Merge(m, src)
+}
+func (m *RespListVaultMembers) XXX_Size() int {
+	return xxx_messageInfo_RespListVaultMembers.Size(m)
+}
+func (m *RespListVaultMembers) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespListVaultMembers.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespListVaultMembers proto.InternalMessageInfo
+
+func (m *RespListVaultMembers) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespListVaultMembers) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *RespListVaultMembers) GetMemberData() []byte {
+	if m != nil {
+		return m.MemberData
+	}
+	return nil
+}
+
+type ReqListVaultFiles struct {
+	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	// owner : 目录所有者, 为空表示自己
+	Owner string `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	// prefix : 加密目录或其中的子目录
+	Prefix               string   `protobuf:"bytes,3,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Marker               string   `protobuf:"bytes,4,opt,name=marker,proto3" json:"marker,omitempty"`
+	Limit                int32    `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReqListVaultFiles) Reset()         { *m = ReqListVaultFiles{} }
+func (m *ReqListVaultFiles) String() string { return proto.CompactTextString(m) }
+func (*ReqListVaultFiles) ProtoMessage()    {}
+func (*ReqListVaultFiles) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{58}
+}
+
+func (m *ReqListVaultFiles) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReqListVaultFiles.Unmarshal(m, b)
+}
+func (m *ReqListVaultFiles) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReqListVaultFiles.Marshal(b, m, deterministic)
+}
+func (m *ReqListVaultFiles) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReqListVaultFiles.Merge(m, src)
+}
+func (m *ReqListVaultFiles) XXX_Size() int {
+	return xxx_messageInfo_ReqListVaultFiles.Size(m)
+}
+func (m *ReqListVaultFiles) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReqListVaultFiles.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReqListVaultFiles proto.InternalMessageInfo
+
+func (m *ReqListVaultFiles) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ReqListVaultFiles) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *ReqListVaultFiles) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+func (m *ReqListVaultFiles) GetMarker() string {
+	if m != nil {
+		return m.Marker
+	}
+	return ""
+}
+
+func (m *ReqListVaultFiles) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type RespListVaultFiles struct {
+	Code     int32  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message  string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	FileData []byte `protobuf:"bytes,3,opt,name=fileData,proto3" json:"fileData,omitempty"`
+	// nextMarker : 非空时还有下一页
+	NextMarker           string   `protobuf:"bytes,4,opt,name=nextMarker,proto3" json:"nextMarker,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RespListVaultFiles) Reset()         { *m = RespListVaultFiles{} }
+func (m *RespListVaultFiles) String() string { return proto.CompactTextString(m) }
+func (*RespListVaultFiles) ProtoMessage()    {}
+func (*RespListVaultFiles) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{59}
+}
+
+func (m *RespListVaultFiles) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RespListVaultFiles.Unmarshal(m, b)
+}
+func (m *RespListVaultFiles) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RespListVaultFiles.Marshal(b, m, deterministic)
+}
+func (m *RespListVaultFiles) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RespListVaultFiles.Merge(m, src)
+}
+func (m *RespListVaultFiles) XXX_Size() int {
+	return xxx_messageInfo_RespListVaultFiles.Size(m)
+}
+func (m *RespListVaultFiles) XXX_DiscardUnknown() {
+	xxx_messageInfo_RespListVaultFiles.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RespListVaultFiles proto.InternalMessageInfo
+
+func (m *RespListVaultFiles) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *RespListVaultFiles) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *RespListVaultFiles) GetFileData() []byte {
+	if m != nil {
+		return m.FileData
+	}
+	return nil
+}
+
+func (m *RespListVaultFiles) GetNextMarker() string {
+	if m != nil {
+		return m.NextMarker
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*ReqSignup)(nil), "go.micro.service.user.ReqSignup")
 	proto.RegisterType((*RespSignup)(nil), "go.micro.service.user.RespSignup")
@@ -573,40 +3169,166 @@ func init() {
 	proto.RegisterType((*RespUserFile)(nil), "go.micro.service.user.RespUserFile")
 	proto.RegisterType((*ReqUserFileRename)(nil), "go.micro.service.user.ReqUserFileRename")
 	proto.RegisterType((*RespUserFileRename)(nil), "go.micro.service.user.RespUserFileRename")
+	proto.RegisterType((*ReqCreateAccessKey)(nil), "go.micro.service.user.ReqCreateAccessKey")
+	proto.RegisterType((*RespCreateAccessKey)(nil), "go.micro.service.user.RespCreateAccessKey")
+	proto.RegisterType((*ReqListAccessKeys)(nil), "go.micro.service.user.ReqListAccessKeys")
+	proto.RegisterType((*RespListAccessKeys)(nil), "go.micro.service.user.RespListAccessKeys")
+	proto.RegisterType((*ReqDeleteAccessKey)(nil), "go.micro.service.user.ReqDeleteAccessKey")
+	proto.RegisterType((*RespDeleteAccessKey)(nil), "go.micro.service.user.RespDeleteAccessKey")
+	proto.RegisterType((*ReqAuthenticate)(nil), "go.micro.service.user.ReqAuthenticate")
+	proto.RegisterType((*RespAuthenticate)(nil), "go.micro.service.user.RespAuthenticate")
+	proto.RegisterType((*ReqAddSSHKey)(nil), "go.micro.service.user.ReqAddSSHKey")
+	proto.RegisterType((*RespAddSSHKey)(nil), "go.micro.service.user.RespAddSSHKey")
+	proto.RegisterType((*ReqListSSHKeys)(nil), "go.micro.service.user.ReqListSSHKeys")
+	proto.RegisterType((*RespListSSHKeys)(nil), "go.micro.service.user.RespListSSHKeys")
+	proto.RegisterType((*ReqDeleteSSHKey)(nil), "go.micro.service.user.ReqDeleteSSHKey")
+	proto.RegisterType((*RespDeleteSSHKey)(nil), "go.micro.service.user.RespDeleteSSHKey")
+	proto.RegisterType((*ReqUserFileList)(nil), "go.micro.service.user.ReqUserFileList")
+	proto.RegisterType((*RespUserFileList)(nil), "go.micro.service.user.RespUserFileList")
+	proto.RegisterType((*ReqUserFileDelete)(nil), "go.micro.service.user.ReqUserFileDelete")
+	proto.RegisterType((*RespUserFileDelete)(nil), "go.micro.service.user.RespUserFileDelete")
+	proto.RegisterType((*ReqUserFileMove)(nil), "go.micro.service.user.ReqUserFileMove")
+	proto.RegisterType((*RespUserFileMove)(nil), "go.micro.service.user.RespUserFileMove")
+	proto.RegisterType((*ReqUserUsage)(nil), "go.micro.service.user.ReqUserUsage")
+	proto.RegisterType((*RespUserUsage)(nil), "go.micro.service.user.RespUserUsage")
+	proto.RegisterType((*ReqUserFileRestore)(nil), "go.micro.service.user.ReqUserFileRestore")
+	proto.RegisterType((*RespUserFileRestore)(nil), "go.micro.service.user.RespUserFileRestore")
+	proto.RegisterType((*ReqUserFileChanges)(nil), "go.micro.service.user.ReqUserFileChanges")
+	proto.RegisterType((*RespUserFileChanges)(nil), "go.micro.service.user.RespUserFileChanges")
+	proto.RegisterType((*ReqCreateWebhook)(nil), "go.micro.service.user.ReqCreateWebhook")
+	proto.RegisterType((*RespCreateWebhook)(nil), "go.micro.service.user.RespCreateWebhook")
+	proto.RegisterType((*ReqListWebhooks)(nil), "go.micro.service.user.ReqListWebhooks")
+	proto.RegisterType((*RespListWebhooks)(nil), "go.micro.service.user.RespListWebhooks")
+	proto.RegisterType((*ReqDeleteWebhook)(nil), "go.micro.service.user.ReqDeleteWebhook")
+	proto.RegisterType((*RespDeleteWebhook)(nil), "go.micro.service.user.RespDeleteWebhook")
+	proto.RegisterType((*ReqListWebhookDeliveries)(nil), "go.micro.service.user.ReqListWebhookDeliveries")
+	proto.RegisterType((*RespListWebhookDeliveries)(nil), "go.micro.service.user.RespListWebhookDeliveries")
+	proto.RegisterType((*ReqSetVaultKeyPair)(nil), "go.micro.service.user.ReqSetVaultKeyPair")
+	proto.RegisterType((*RespSetVaultKeyPair)(nil), "go.micro.service.user.RespSetVaultKeyPair")
+	proto.RegisterType((*ReqGetVaultKeyPair)(nil), "go.micro.service.user.ReqGetVaultKeyPair")
+	proto.RegisterType((*RespGetVaultKeyPair)(nil), "go.micro.service.user.RespGetVaultKeyPair")
+	proto.RegisterType((*ReqCreateVault)(nil), "go.micro.service.user.ReqCreateVault")
+	proto.RegisterType((*RespCreateVault)(nil), "go.micro.service.user.RespCreateVault")
+	proto.RegisterType((*ReqListVaults)(nil), "go.micro.service.user.ReqListVaults")
+	proto.RegisterType((*RespListVaults)(nil), "go.micro.service.user.RespListVaults")
+	proto.RegisterType((*ReqShareVault)(nil), "go.micro.service.user.ReqShareVault")
+	proto.RegisterType((*RespShareVault)(nil), "go.micro.service.user.RespShareVault")
+	proto.RegisterType((*ReqUnshareVault)(nil), "go.micro.service.user.ReqUnshareVault")
+	proto.RegisterType((*RespUnshareVault)(nil), "go.micro.service.user.RespUnshareVault")
+	proto.RegisterType((*ReqListVaultMembers)(nil), "go.micro.service.user.ReqListVaultMembers")
+	proto.RegisterType((*RespListVaultMembers)(nil), "go.micro.service.user.RespListVaultMembers")
+	proto.RegisterType((*ReqListVaultFiles)(nil), "go.micro.service.user.ReqListVaultFiles")
+	proto.RegisterType((*RespListVaultFiles)(nil), "go.micro.service.user.RespListVaultFiles")
 }
 
 func init() { proto.RegisterFile("user.proto", fileDescriptor_116e343673f7ffaf) }
 
 var fileDescriptor_116e343673f7ffaf = []byte{
-	// 462 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x94, 0x41, 0x6f, 0xd3, 0x30,
-	0x14, 0xc7, 0x53, 0xba, 0x74, 0xed, 0x6b, 0x85, 0x84, 0x55, 0x90, 0xd5, 0x53, 0x31, 0x97, 0xed,
-	0x92, 0x03, 0xdc, 0xb8, 0xa0, 0x0a, 0x84, 0xc4, 0x05, 0x90, 0xa7, 0x21, 0x4e, 0x48, 0xa6, 0x7b,
-	0x6b, 0x2d, 0x12, 0x3b, 0x8b, 0xdd, 0xed, 0x23, 0xf2, 0x25, 0xf8, 0x30, 0xc8, 0x76, 0x9c, 0xa6,
-	0xd3, 0x96, 0x30, 0xb4, 0x5b, 0xfe, 0xcf, 0xcf, 0x3f, 0xff, 0xfd, 0xfc, 0x5e, 0x00, 0x76, 0x06,
-	0xab, 0xac, 0xac, 0xb4, 0xd5, 0xe4, 0xf9, 0x46, 0x67, 0x85, 0x5c, 0x57, 0x3a, 0x33, 0x58, 0x5d,
-	0xcb, 0x35, 0x66, 0x6e, 0x91, 0xbd, 0x87, 0x09, 0xc7, 0xab, 0x33, 0xb9, 0x51, 0xbb, 0x92, 0x2c,
-	0x60, 0xec, 0x82, 0x4a, 0x14, 0x48, 0x07, 0xcb, 0xc1, 0xc9, 0x84, 0x37, 0xda, 0xad, 0x95, 0xc2,
-	0x98, 0x1b, 0x5d, 0x5d, 0xd0, 0x27, 0x61, 0x2d, 0x6a, 0xf6, 0x16, 0x80, 0xa3, 0x29, 0x6b, 0x0a,
-	0x81, 0xa3, 0xb5, 0xbe, 0x08, 0x84, 0x94, 0xfb, 0x6f, 0x42, 0xe1, 0xb8, 0x40, 0x63, 0xc4, 0x06,
-	0xeb, 0xcd, 0x51, 0xb6, 0x0c, 0x48, 0xf5, 0xdf, 0x06, 0xbe, 0xee, 0x0d, 0x48, 0x75, 0xa7, 0x81,
-	0x39, 0xa4, 0x56, 0xff, 0x42, 0x55, 0x6f, 0x0d, 0xa2, 0x6d, 0x6b, 0x78, 0x68, 0xeb, 0x14, 0xa6,
-	0x1c, 0xaf, 0xce, 0x0d, 0x56, 0x9f, 0xd4, 0xa5, 0xee, 0x32, 0xc6, 0xfe, 0x0c, 0x60, 0xe6, 0x4e,
-	0x6f, 0x92, 0x1f, 0x54, 0x80, 0x03, 0xf4, 0xf0, 0xd6, 0x9d, 0xe7, 0x90, 0x62, 0x21, 0x64, 0x4e,
-	0x8f, 0x82, 0x6b, 0x2f, 0x5c, 0xb4, 0xdc, 0x6a, 0x85, 0x34, 0x0d, 0x51, 0x2f, 0x1c, 0xc7, 0xf8,
-	0x07, 0x58, 0x59, 0x3a, 0x0a, 0x9c, 0xa8, 0x09, 0x83, 0x59, 0x2e, 0x8c, 0x5d, 0xad, 0xad, 0xbc,
-	0xc6, 0x95, 0xa5, 0xc7, 0x7e, 0xfd, 0x20, 0x46, 0x5e, 0xc0, 0xc8, 0x58, 0x61, 0x77, 0x86, 0x8e,
-	0xbd, 0xef, 0x5a, 0xb1, 0x77, 0x4d, 0x25, 0x3e, 0xca, 0x1c, 0x3b, 0x9f, 0x68, 0x0e, 0x69, 0x2e,
-	0x0b, 0x69, 0xfd, 0x15, 0x53, 0x1e, 0x04, 0xfb, 0xbe, 0x2f, 0x8f, 0x27, 0x3c, 0xb8, 0x3c, 0x97,
-	0x32, 0xc7, 0x0f, 0xc2, 0x0a, 0x5f, 0x9e, 0x19, 0x6f, 0x34, 0x2b, 0xe0, 0x59, 0xcb, 0x1a, 0xc7,
-	0xd8, 0x27, 0x5d, 0x3d, 0xe4, 0x36, 0x6f, 0x85, 0xd9, 0xc6, 0x1e, 0x8a, 0x9a, 0x2c, 0x61, 0xaa,
-	0xf0, 0xc6, 0x81, 0x3e, 0xef, 0x9f, 0xa2, 0x1d, 0x62, 0x3f, 0x80, 0xb4, 0x2f, 0x52, 0x9f, 0xf7,
-	0x68, 0xd7, 0x79, 0xfd, 0x7b, 0x08, 0x53, 0x07, 0x3f, 0x0b, 0x03, 0x4a, 0xbe, 0xc0, 0xa8, 0x1e,
-	0xa9, 0x65, 0x76, 0xe7, 0xf4, 0x66, 0xcd, 0xe8, 0x2e, 0x5e, 0xde, 0x9b, 0x11, 0xe7, 0x92, 0x25,
-	0x11, 0x28, 0x55, 0x1f, 0x50, 0xaa, 0x5e, 0xa0, 0x54, 0x2c, 0x21, 0xe7, 0x30, 0x6e, 0xba, 0x9e,
-	0xdd, 0x8f, 0x8c, 0x39, 0x8b, 0x57, 0x1d, 0xd0, 0x98, 0xc4, 0x12, 0xf2, 0x0d, 0x26, 0xb1, 0xc8,
-	0xa6, 0x8f, 0xeb, 0x92, 0x7a, 0xb9, 0x2e, 0x89, 0x25, 0x64, 0x03, 0x4f, 0x6f, 0x3d, 0xde, 0x49,
-	0x3f, 0x3c, 0x64, 0x2e, 0x4e, 0xff, 0xe1, 0x88, 0x90, 0xca, 0x92, 0x9f, 0x23, 0xff, 0xcf, 0x7d,
-	0xf3, 0x37, 0x00, 0x00, 0xff, 0xff, 0xd7, 0x5b, 0xb8, 0x64, 0x81, 0x05, 0x00, 0x00,
+	// 1696 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x5a, 0xef, 0x52, 0x1b, 0x37,
+	0x10, 0x37, 0x18, 0x9b, 0xb0, 0x10, 0x42, 0x2e, 0x84, 0xba, 0x9e, 0x4e, 0x86, 0x2a, 0x09, 0x90,
+	0xa4, 0x25, 0x99, 0xf6, 0x5b, 0x3f, 0x24, 0x75, 0xc9, 0x94, 0x30, 0xf9, 0x3b, 0xf6, 0x24, 0x4d,
+	0xa7, 0x19, 0x32, 0x87, 0x4f, 0xb6, 0xaf, 0xd8, 0x77, 0x46, 0x3a, 0x43, 0xe8, 0xdf, 0x07, 0xe8,
+	0x6b, 0xf4, 0x4d, 0xfa, 0x1a, 0x7d, 0x98, 0x8e, 0xfe, 0xdd, 0x49, 0x02, 0x74, 0x77, 0x84, 0x7e,
+	0x63, 0xe5, 0x9f, 0x76, 0x7f, 0xbb, 0xda, 0xdd, 0x5b, 0x69, 0x00, 0x98, 0x50, 0x4c, 0x36, 0xc7,
+	0x24, 0x4e, 0x62, 0xef, 0x7a, 0x3f, 0xde, 0x1c, 0x85, 0x5d, 0x12, 0x6f, 0x52, 0x4c, 0x0e, 0xc3,
+	0x2e, 0xde, 0x64, 0x3f, 0xa2, 0x2d, 0x98, 0x6b, 0xe3, 0x83, 0x4e, 0xd8, 0x8f, 0x26, 0x63, 0xaf,
+	0x09, 0x97, 0xd8, 0x62, 0xe4, 0x8f, 0x70, 0x63, 0x6a, 0x75, 0x6a, 0x63, 0xae, 0x9d, 0xca, 0xec,
+	0xb7, 0xb1, 0x4f, 0xe9, 0x51, 0x4c, 0x82, 0xc6, 0xb4, 0xf8, 0x4d, 0xc9, 0xe8, 0x1b, 0x80, 0x36,
+	0xa6, 0x63, 0xa9, 0xc5, 0x83, 0x99, 0x6e, 0x1c, 0x08, 0x0d, 0xb5, 0x36, 0xff, 0xdb, 0x6b, 0xc0,
+	0xec, 0x08, 0x53, 0xea, 0xf7, 0xb1, 0xdc, 0xac, 0x44, 0x8d, 0x40, 0x18, 0x9d, 0x9b, 0xc0, 0xab,
+	0x8c, 0x40, 0x18, 0x9d, 0x4a, 0x60, 0x19, 0x6a, 0x49, 0xbc, 0x8f, 0x23, 0xb9, 0x55, 0x08, 0x3a,
+	0xad, 0xaa, 0x49, 0xeb, 0x0e, 0xcc, 0xb7, 0xf1, 0xc1, 0x6b, 0x8a, 0xc9, 0x4e, 0xd4, 0x8b, 0x5d,
+	0xc4, 0xd0, 0xbf, 0x53, 0xb0, 0xc0, 0xac, 0xa7, 0xe0, 0x52, 0x01, 0x30, 0x54, 0x57, 0x2d, 0x9f,
+	0x97, 0xa1, 0x86, 0x47, 0x7e, 0x38, 0x6c, 0xcc, 0x08, 0xd6, 0x5c, 0x60, 0xab, 0xe3, 0x41, 0x1c,
+	0xe1, 0x46, 0x4d, 0xac, 0x72, 0x81, 0xe9, 0xa1, 0xfc, 0x00, 0x5a, 0x49, 0xa3, 0x2e, 0xf4, 0x28,
+	0xd9, 0x43, 0xb0, 0x30, 0xf4, 0x69, 0xd2, 0xea, 0x26, 0xe1, 0x21, 0x6e, 0x25, 0x8d, 0x59, 0xfe,
+	0xbb, 0xb1, 0xe6, 0xad, 0x40, 0x9d, 0x26, 0x7e, 0x32, 0xa1, 0x8d, 0x4b, 0x9c, 0xb7, 0x94, 0xd0,
+	0xa3, 0x34, 0x12, 0xdf, 0x87, 0x43, 0xec, 0x3c, 0xa2, 0x65, 0xa8, 0x0d, 0xc3, 0x51, 0x98, 0x70,
+	0x17, 0x6b, 0x6d, 0x21, 0xa0, 0xb7, 0x59, 0x78, 0xb8, 0x86, 0xd2, 0xe1, 0xe9, 0x85, 0x43, 0xfc,
+	0xd8, 0x4f, 0x7c, 0x1e, 0x9e, 0x85, 0x76, 0x2a, 0xa3, 0x11, 0x5c, 0xd5, 0xa8, 0xb5, 0xb1, 0xca,
+	0x13, 0x57, 0x0e, 0xb1, 0xcd, 0x03, 0x9f, 0x0e, 0x54, 0x0e, 0x29, 0xd9, 0x5b, 0x85, 0xf9, 0x08,
+	0x1f, 0x31, 0x45, 0x2f, 0xb2, 0xa3, 0xd0, 0x97, 0xd0, 0x2e, 0x78, 0xba, 0x23, 0xd2, 0xde, 0xc5,
+	0xb9, 0xf3, 0x80, 0xe9, 0x3f, 0xd8, 0x22, 0xd8, 0x4f, 0x70, 0xab, 0xdb, 0xc5, 0x94, 0x3e, 0xc5,
+	0xc7, 0xce, 0xd4, 0xfb, 0x13, 0xae, 0x31, 0x46, 0xf6, 0x96, 0x72, 0x94, 0x3e, 0x83, 0x39, 0x5f,
+	0x6d, 0x95, 0x6e, 0x67, 0x0b, 0xec, 0x57, 0x8a, 0xbb, 0x04, 0x27, 0xec, 0x57, 0x91, 0x86, 0xd9,
+	0x02, 0xba, 0xcf, 0x4f, 0xe0, 0x59, 0xc8, 0xf2, 0x48, 0xee, 0xa0, 0x4e, 0xc6, 0xef, 0x44, 0x0c,
+	0xad, 0x1d, 0xe5, 0x08, 0x37, 0x60, 0x76, 0x1f, 0x1f, 0x6b, 0x21, 0x54, 0x22, 0x7a, 0xc1, 0x23,
+	0xf8, 0x18, 0x0f, 0x71, 0xc1, 0x08, 0x9a, 0xce, 0x4f, 0x5b, 0xce, 0xa3, 0x2d, 0x11, 0x5f, 0x5b,
+	0x61, 0xb9, 0x0e, 0xb7, 0x03, 0x57, 0xda, 0xf8, 0xa0, 0x35, 0x49, 0x06, 0x38, 0x4a, 0xc2, 0xae,
+	0x9f, 0xe0, 0x73, 0xf7, 0xb9, 0x6f, 0x61, 0x89, 0xf1, 0x31, 0x74, 0x95, 0x23, 0xf3, 0x84, 0x15,
+	0xe3, 0x41, 0x2b, 0x08, 0x3a, 0x9d, 0x27, 0x05, 0x62, 0x33, 0x9e, 0xec, 0x0d, 0xc3, 0xae, 0x16,
+	0x9b, 0x74, 0x01, 0xbd, 0x87, 0xcb, 0x9c, 0x4b, 0xaa, 0xaa, 0xdc, 0x21, 0xae, 0xc2, 0x7c, 0x2f,
+	0x8c, 0xfa, 0x98, 0x8c, 0x49, 0x18, 0x25, 0xaa, 0xdc, 0xb4, 0x25, 0xf4, 0x05, 0x2c, 0xca, 0xdc,
+	0x12, 0x06, 0xdc, 0x89, 0xf5, 0x23, 0x5c, 0x51, 0x89, 0xa5, 0xe0, 0x17, 0x95, 0x55, 0x2f, 0xf9,
+	0x01, 0x8a, 0x24, 0x28, 0x10, 0x36, 0xcb, 0xb3, 0xe9, 0x93, 0x9e, 0xc9, 0x63, 0x34, 0x34, 0x96,
+	0x3b, 0x46, 0xca, 0x29, 0xa9, 0x4e, 0xc4, 0x9c, 0x76, 0x52, 0x5a, 0x81, 0xfa, 0x98, 0xe0, 0x5e,
+	0xf8, 0x41, 0xea, 0x91, 0x12, 0x5b, 0x1f, 0xf9, 0x64, 0x1f, 0x13, 0x19, 0x7f, 0x29, 0x65, 0x8d,
+	0x7c, 0x46, 0x6f, 0xe4, 0xef, 0x04, 0x6d, 0xc3, 0xea, 0xc5, 0x75, 0xbf, 0xa7, 0x46, 0x33, 0x17,
+	0xb1, 0x29, 0xd2, 0xcc, 0x79, 0xb7, 0xd6, 0x9a, 0x39, 0x93, 0xd1, 0x77, 0x66, 0xab, 0x96, 0xda,
+	0xca, 0xc5, 0xb8, 0x6b, 0xc4, 0xf8, 0x79, 0x7c, 0xe8, 0xa6, 0xd3, 0x80, 0x59, 0x4a, 0xba, 0x1a,
+	0x1b, 0x25, 0xb2, 0x5d, 0x01, 0xa6, 0x89, 0xf6, 0x59, 0x49, 0x65, 0x95, 0x0a, 0x86, 0x95, 0x72,
+	0x34, 0xef, 0xf2, 0x8a, 0x66, 0x0a, 0x5e, 0x9f, 0x98, 0x27, 0xec, 0x22, 0x39, 0x16, 0x35, 0x9b,
+	0x81, 0x4b, 0x7f, 0x29, 0x58, 0x84, 0xb7, 0xe2, 0x89, 0xac, 0xd8, 0x6a, 0x3b, 0x5b, 0x60, 0xbf,
+	0x26, 0x71, 0xe2, 0x0f, 0x3b, 0xe1, 0x2f, 0x98, 0x27, 0x4e, 0xb5, 0x9d, 0x2d, 0xa0, 0x67, 0xbc,
+	0x35, 0x67, 0xdf, 0x4e, 0x9a, 0xc4, 0xe4, 0xfc, 0xe7, 0x2b, 0x1b, 0xb3, 0xad, 0xae, 0x5c, 0xe4,
+	0x76, 0x0d, 0x4a, 0x5b, 0x03, 0x3f, 0xea, 0x63, 0x9a, 0x57, 0x47, 0xdd, 0x09, 0xa1, 0x31, 0xe1,
+	0xaa, 0xaa, 0x6d, 0x29, 0x65, 0xf5, 0x52, 0xd5, 0xeb, 0x85, 0x9a, 0x24, 0x95, 0x81, 0x72, 0x31,
+	0x5f, 0x81, 0x3a, 0x3e, 0xc4, 0x51, 0x42, 0x65, 0xc1, 0x48, 0x49, 0xa3, 0x32, 0xa3, 0x53, 0x41,
+	0x6f, 0x61, 0x29, 0x1d, 0x22, 0x7e, 0xc0, 0x7b, 0x83, 0x38, 0xde, 0x77, 0xba, 0xb4, 0x04, 0xd5,
+	0x09, 0x19, 0x4a, 0xab, 0xec, 0x4f, 0xcb, 0xe2, 0x9c, 0xb2, 0x88, 0x7e, 0x85, 0xab, 0xd9, 0xb0,
+	0xa1, 0x54, 0x97, 0x6e, 0xfa, 0x47, 0x62, 0xa3, 0xd6, 0x02, 0xf4, 0x25, 0x66, 0x5c, 0x4c, 0x17,
+	0x72, 0xd6, 0x90, 0x12, 0xfa, 0x92, 0x17, 0x23, 0x6b, 0x39, 0xd2, 0xb2, 0xfb, 0x6b, 0xb0, 0x27,
+	0xca, 0xca, 0xc0, 0x5f, 0x30, 0x55, 0xf4, 0x90, 0x47, 0x5a, 0xb4, 0x96, 0x22, 0x91, 0x5e, 0x84,
+	0xe9, 0x30, 0x90, 0x89, 0x33, 0x1d, 0x06, 0xa8, 0x25, 0xe2, 0x69, 0x2a, 0x28, 0x97, 0xc1, 0x01,
+	0x34, 0xcc, 0xa8, 0x3c, 0xc6, 0xc3, 0xf0, 0x10, 0x93, 0x30, 0x3f, 0x8f, 0x19, 0x7a, 0x47, 0xd1,
+	0x91, 0xd2, 0x19, 0x79, 0x3c, 0x82, 0x4f, 0xad, 0x60, 0x6a, 0x66, 0xca, 0x45, 0x15, 0xc1, 0x42,
+	0x20, 0xf6, 0xea, 0x5f, 0x5a, 0x63, 0x0d, 0xfd, 0x3d, 0xc5, 0xeb, 0xb2, 0x83, 0x93, 0x37, 0xfe,
+	0x64, 0xc8, 0xc6, 0xcc, 0x57, 0x7e, 0x48, 0xce, 0x3f, 0xa9, 0x98, 0x23, 0x6c, 0xd5, 0x1a, 0x61,
+	0xf9, 0x77, 0x3f, 0xe8, 0x75, 0xfc, 0xa1, 0x4a, 0x39, 0x25, 0xb2, 0x7d, 0xfb, 0x41, 0xef, 0x95,
+	0x4f, 0xfc, 0x11, 0x95, 0x77, 0xad, 0x6c, 0x41, 0xb5, 0x20, 0x9b, 0x66, 0xd9, 0x71, 0x8c, 0xb9,
+	0xba, 0x5d, 0xc2, 0xd5, 0x15, 0xa8, 0x27, 0x3e, 0xe9, 0x63, 0x35, 0x58, 0x48, 0x09, 0x61, 0x41,
+	0x67, 0xfb, 0x63, 0xe8, 0xb0, 0xa4, 0xdf, 0x17, 0x1b, 0xf5, 0xa4, 0xd7, 0x96, 0x50, 0xc0, 0x87,
+	0x32, 0xd1, 0x03, 0xb8, 0xa1, 0x3c, 0xb2, 0xbd, 0x78, 0x18, 0x60, 0xa2, 0xc8, 0x0a, 0xc9, 0xbb,
+	0x01, 0x70, 0x44, 0xfc, 0xf1, 0x18, 0x07, 0xd9, 0x91, 0x68, 0x2b, 0xe8, 0x91, 0x18, 0xe6, 0x74,
+	0x33, 0xe5, 0xe2, 0x7a, 0x0f, 0x2e, 0xcb, 0xc2, 0xe0, 0xbb, 0xf3, 0xee, 0x24, 0x8b, 0x2a, 0xbf,
+	0x25, 0xba, 0xf4, 0x67, 0xf1, 0x90, 0xed, 0xd3, 0x62, 0x96, 0x2d, 0xa0, 0xdf, 0x39, 0x95, 0xce,
+	0xc0, 0x27, 0x1f, 0x11, 0xb0, 0x06, 0xcc, 0xf6, 0x89, 0x1f, 0x25, 0x38, 0x7d, 0xa8, 0x90, 0xa2,
+	0x15, 0xca, 0x99, 0x13, 0xa1, 0x7c, 0x28, 0x9c, 0xd3, 0xec, 0x97, 0x8b, 0xe4, 0x7b, 0x31, 0x05,
+	0x45, 0xf4, 0x7f, 0x72, 0x20, 0x9d, 0x80, 0x22, 0x7a, 0x5e, 0x8a, 0x3b, 0x70, 0x4d, 0x3f, 0xec,
+	0xe7, 0x78, 0xb4, 0x87, 0x09, 0x3d, 0x0f, 0x4d, 0x14, 0xc0, 0xb2, 0x91, 0x0a, 0x4a, 0x57, 0xb9,
+	0x84, 0xb8, 0x01, 0x30, 0xe2, 0x1b, 0xb5, 0x8c, 0xd0, 0x56, 0xd0, 0x5f, 0x53, 0xe9, 0xb5, 0x99,
+	0x5b, 0x61, 0xd3, 0x01, 0xcd, 0x7b, 0x59, 0x89, 0x8f, 0xa2, 0x94, 0xae, 0x10, 0xb4, 0xb1, 0xbe,
+	0x7a, 0xc6, 0x58, 0x3f, 0x73, 0xfa, 0x58, 0x5f, 0xd3, 0xdb, 0xfb, 0x1f, 0xd9, 0x95, 0x5c, 0x63,
+	0x73, 0x61, 0x83, 0x3d, 0x8b, 0x46, 0x84, 0x3f, 0x24, 0xcf, 0x75, 0x46, 0xda, 0xca, 0x57, 0xff,
+	0x7c, 0x02, 0xf3, 0x6c, 0x46, 0xea, 0x88, 0x77, 0x49, 0xef, 0x25, 0xd4, 0xe5, 0x4b, 0xe2, 0xea,
+	0xe6, 0xa9, 0x8f, 0x96, 0x9b, 0xe9, 0x8b, 0x65, 0xf3, 0xf3, 0x33, 0x11, 0xea, 0x39, 0x12, 0x55,
+	0x94, 0xc2, 0x30, 0xca, 0x53, 0x18, 0x46, 0xb9, 0x0a, 0xc3, 0x08, 0x55, 0xbc, 0xd7, 0x70, 0x29,
+	0x7d, 0xec, 0x43, 0x67, 0xab, 0x54, 0x98, 0xe6, 0x4d, 0x87, 0x52, 0x05, 0x42, 0x15, 0xef, 0x0d,
+	0xcc, 0xa9, 0x59, 0x91, 0xe6, 0xe9, 0x65, 0xa0, 0x5c, 0xbd, 0x0c, 0x84, 0x2a, 0x5e, 0x1f, 0x16,
+	0xad, 0x37, 0xab, 0x8d, 0x7c, 0xe5, 0x02, 0xd9, 0xbc, 0x53, 0xc0, 0x84, 0x80, 0xa2, 0x8a, 0xf7,
+	0x33, 0x5c, 0xb1, 0x9f, 0xa2, 0xce, 0xde, 0x6f, 0x3f, 0x74, 0x35, 0xef, 0x3a, 0x4c, 0x59, 0x58,
+	0xe1, 0x94, 0xf5, 0x88, 0xe4, 0x70, 0xca, 0x44, 0x3a, 0x9d, 0x32, 0xa1, 0xc2, 0x29, 0xfb, 0xfd,
+	0xc7, 0xe1, 0x94, 0x05, 0x75, 0x3a, 0x65, 0x61, 0x51, 0xc5, 0xf3, 0x61, 0xc1, 0x78, 0xdb, 0x59,
+	0x3b, 0xdb, 0x90, 0x8e, 0x6b, 0xae, 0x3b, 0xac, 0xe8, 0x40, 0x54, 0xf1, 0xde, 0xc2, 0x5c, 0xf6,
+	0x64, 0x73, 0xd3, 0xa1, 0x5f, 0x81, 0x9a, 0xb7, 0x5c, 0xca, 0x15, 0x0a, 0x55, 0xbc, 0x5d, 0x98,
+	0xd7, 0x5f, 0x5f, 0x6e, 0xbb, 0x8f, 0x43, 0xc2, 0x9a, 0x6b, 0x39, 0x67, 0x21, 0x71, 0x22, 0x38,
+	0xc6, 0x8b, 0xc9, 0x5a, 0xde, 0x29, 0x48, 0xfe, 0xeb, 0xb9, 0x47, 0x90, 0xba, 0xe0, 0xc3, 0x82,
+	0xf1, 0xba, 0xb1, 0x96, 0x5f, 0x27, 0x0c, 0xe7, 0x34, 0xa1, 0x03, 0xcd, 0x62, 0x14, 0xc6, 0x8b,
+	0x14, 0xa3, 0x40, 0x16, 0x2a, 0x46, 0x01, 0x35, 0x7d, 0xe1, 0xaf, 0x0a, 0x05, 0x7c, 0x61, 0xb8,
+	0x42, 0xbe, 0x30, 0xa0, 0xc8, 0xa5, 0xec, 0x29, 0xe1, 0xa6, 0x5b, 0x3f, 0x07, 0x39, 0x73, 0x29,
+	0x45, 0x89, 0xa2, 0xb3, 0xef, 0xf6, 0x77, 0x8a, 0xf4, 0x2c, 0x0e, 0x75, 0x16, 0x9d, 0x85, 0x35,
+	0x6d, 0xa9, 0x2b, 0x7a, 0x01, 0x5b, 0x12, 0x5a, 0xc8, 0x96, 0xc4, 0xa2, 0x8a, 0x17, 0xc0, 0x65,
+	0xf3, 0xfe, 0xbc, 0x9e, 0xd7, 0x1f, 0x25, 0xb0, 0xb9, 0x91, 0xdb, 0x1d, 0x25, 0x52, 0x1c, 0xbd,
+	0x71, 0xf3, 0x5d, 0x73, 0x97, 0xa2, 0xc2, 0x39, 0x8f, 0x5e, 0x07, 0x0a, 0x47, 0xcc, 0x8b, 0xeb,
+	0x7a, 0x5e, 0x35, 0x16, 0x71, 0xc4, 0x40, 0xa2, 0x8a, 0xf7, 0x1b, 0x5c, 0x3f, 0xfd, 0xd6, 0x79,
+	0xbf, 0x90, 0x47, 0xd9, 0x86, 0xe6, 0x83, 0x62, 0xae, 0x65, 0x3b, 0x44, 0x62, 0xd8, 0xb7, 0x3b,
+	0x47, 0x62, 0x58, 0x50, 0x67, 0x62, 0x58, 0x58, 0x61, 0x6b, 0xbb, 0xb8, 0xad, 0xed, 0x12, 0xb6,
+	0xb6, 0x4f, 0xd8, 0xda, 0x85, 0x79, 0xfd, 0x66, 0x75, 0x3b, 0x2f, 0x05, 0x39, 0xcc, 0xd9, 0xa8,
+	0x35, 0x1c, 0xaa, 0x78, 0x3f, 0x01, 0x68, 0x77, 0xa9, 0x5b, 0xee, 0xa3, 0x12, 0xa8, 0xe6, 0xed,
+	0x9c, 0xf3, 0x11, 0x30, 0xa1, 0x5c, 0xbb, 0xcb, 0x38, 0x94, 0x67, 0x28, 0xa7, 0xf2, 0x0c, 0x26,
+	0x7b, 0xa6, 0x7e, 0x0f, 0x71, 0xf5, 0x4c, 0x0d, 0xe7, 0xee, 0x99, 0x1a, 0x10, 0x55, 0xbc, 0x11,
+	0x2c, 0x9d, 0xb8, 0x5d, 0xdc, 0x2d, 0x10, 0x22, 0x89, 0x6d, 0xde, 0x2b, 0x12, 0x28, 0x09, 0xce,
+	0xc6, 0x24, 0x6d, 0xb0, 0xdf, 0x28, 0x60, 0x8c, 0x23, 0x73, 0xc7, 0xa4, 0x0c, 0x8a, 0x2a, 0x7b,
+	0x75, 0xfe, 0x6f, 0x06, 0x5f, 0xff, 0x37, 0x00, 0x78, 0xa4, 0x77, 0x10, 0x74, 0x20, 0x00, 0x00,
 }
-
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cloud/common"
+	userProto "github.com/cloud/service/account/proto"
+	"github.com/cloud/util"
+)
+
+// AccessKeyCreateHandler : 创建S3访问密钥
+func AccessKeyCreateHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
+
+	rpcResp, err := userCli.CreateAccessKey(context.TODO(), &userProto.ReqCreateAccessKey{
+		Username: username,
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	if rpcResp.Code != common.StatusOK {
+		c.JSON(http.StatusOK, gin.H{
+			"msg":  rpcResp.Message,
+			"code": rpcResp.Code,
+		})
+		return
+	}
+
+	cliResp := util.RespMsg{
+		Code: int(common.StatusOK),
+		Msg:  "OK",
+		Data: gin.H{
+			"AccessKey": rpcResp.AccessKey,
+			"SecretKey": rpcResp.SecretKey,
+		},
+	}
+	c.Data(http.StatusOK, "application/json", cliResp.JSONBytes())
+}
+
+// AccessKeyListHandler : 查询S3访问密钥列表
+func AccessKeyListHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
+
+	rpcResp, err := userCli.ListAccessKeys(context.TODO(), &userProto.ReqListAccessKeys{
+		Username: username,
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if len(rpcResp.KeyData) <= 0 {
+		rpcResp.KeyData = []byte("[]")
+	}
+	c.Data(http.StatusOK, "application/json", rpcResp.KeyData)
+}
+
+// AccessKeyDeleteHandler : 删除S3访问密钥
+func AccessKeyDeleteHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
+	accessKey := c.Request.FormValue("accesskey")
+
+	rpcResp, err := userCli.DeleteAccessKey(context.TODO(), &userProto.ReqDeleteAccessKey{
+		Username:  username,
+		AccessKey: accessKey,
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"msg":  rpcResp.Message,
+		"code": rpcResp.Code,
+	})
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	userProto "github.com/cloud/service/account/proto"
+	"github.com/cloud/service/apigw/notify"
+)
+
+// fileEventHub : 本网关实例上的实时事件流订阅
+var fileEventHub = notify.NewHub()
+
+func init() {
+	if config.FileNotifyEnable {
+		go fileEventHub.Run(context.Background(), config.FileNotifyChannel)
+	}
+}
+
+// FileEventsHandler : 以Server-Sent Events推送用户文件变更事件, 事件名为事件类型, id为事件游标.
+// 断线重连时浏览器通过Last-Event-ID从上次收到的事件之后续传; 也可用cursor参数指定起点,
+// 都没有时从最新事件之后开始
+func FileEventsHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
+	cursor := int64(-1)
+	v := c.GetHeader("Last-Event-ID")
+	if v == "" {
+		v = c.Request.FormValue("cursor")
+	}
+	if v != "" {
+		var err error
+		if cursor, err = strconv.ParseInt(v, 10, 64); err != nil || cursor < 0 {
+			c.JSON(http.StatusOK, gin.H{
+				"msg":  "invalid cursor",
+				"code": common.StatusParamInvalid,
+			})
+			return
+		}
+	}
+
+	// 先订阅再读取事件, 读取之后提交的事件一定会唤醒事件流
+	wakeup, cancel := fileEventHub.Subscribe(username)
+	defer cancel()
+
+	if cursor < 0 {
+		rpcResp, err := userCli.UserFileChanges(context.TODO(), &userProto.ReqUserFileChanges{
+			Username: username,
+			Cursor:   -1,
+		})
+		if err != nil {
+			log.Println(err.Error())
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if rpcResp.Code != common.StatusOK {
+			c.JSON(http.StatusOK, gin.H{
+				"msg":  rpcResp.Message,
+				"code": rpcResp.Code,
+			})
+			return
+		}
+		cursor = rpcResp.Cursor
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", config.FileEventsRetry)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(config.FileEventsKeepAlive * time.Second)
+	defer keepAlive.Stop()
+	for {
+		n, next, err := writeFileEvents(c.Writer, username, cursor)
+		if err != nil {
+			log.Println(err.Error())
+			return
+		}
+		cursor = next
+		if n >= config.FileChangesLimit {
+			continue
+		}
+
+		// 等待通知; 未收到通知时也定期读取一次, redis不可用时事件流仍能推送
+		select {
+		case <-wakeup:
+		case <-keepAlive.C:
+			fmt.Fprint(c.Writer, ": keepalive\n\n")
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// writeFileEvents : 读取游标之后的事件并写入事件流, 返回写入的事件数及新的游标
+func writeFileEvents(w gin.ResponseWriter, username string, cursor int64) (int, int64, error) {
+	rpcResp, err := userCli.UserFileChanges(context.TODO(), &userProto.ReqUserFileChanges{
+		Username: username,
+		Cursor:   cursor,
+		Limit:    config.FileChangesLimit,
+	})
+	if err != nil {
+		return 0, cursor, err
+	}
+	if rpcResp.Code != common.StatusOK {
+		return 0, cursor, errors.New(rpcResp.Message)
+	}
+
+	events := []json.RawMessage{}
+	if err = json.Unmarshal(rpcResp.Events, &events); err != nil {
+		return 0, cursor, err
+	}
+	for _, data := range events {
+		ev := struct {
+			ID    int64
+			Event string
+		}{}
+		if err = json.Unmarshal(data, &ev); err != nil {
+			return 0, cursor, err
+		}
+		if _, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Event, data); err != nil {
+			return 0, cursor, err
+		}
+	}
+	w.Flush()
+	return len(events), rpcResp.Cursor, nil
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cloud/common"
+	userProto "github.com/cloud/service/account/proto"
+	"github.com/cloud/util"
+)
+
+// SSHKeyAddHandler : 添加SFTP登录使用的SSH公钥
+func SSHKeyAddHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
+	publicKey := c.Request.FormValue("publickey")
+
+	rpcResp, err := userCli.AddSSHKey(context.TODO(), &userProto.ReqAddSSHKey{
+		Username:  username,
+		PublicKey: publicKey,
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	if rpcResp.Code != common.StatusOK {
+		c.JSON(http.StatusOK, gin.H{
+			"msg":  rpcResp.Message,
+			"code": rpcResp.Code,
+		})
+		return
+	}
+
+	cliResp := util.RespMsg{
+		Code: int(common.StatusOK),
+		Msg:  "OK",
+		Data: gin.H{
+			"Fingerprint": rpcResp.Fingerprint,
+		},
+	}
+	c.Data(http.StatusOK, "application/json", cliResp.JSONBytes())
+}
+
+// SSHKeyListHandler : 查询SSH公钥列表
+func SSHKeyListHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
+
+	rpcResp, err := userCli.ListSSHKeys(context.TODO(), &userProto.ReqListSSHKeys{
+		Username: username,
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if len(rpcResp.KeyData) <= 0 {
+		rpcResp.KeyData = []byte("[]")
+	}
+	c.Data(http.StatusOK, "application/json", rpcResp.KeyData)
+}
+
+// SSHKeyDeleteHandler : 按指纹删除SSH公钥
+func SSHKeyDeleteHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
+	fingerprint := c.Request.FormValue("fingerprint")
+
+	rpcResp, err := userCli.DeleteSSHKey(context.TODO(), &userProto.ReqDeleteSSHKey{
+		Username:    username,
+		Fingerprint: fingerprint,
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"msg":  rpcResp.Message,
+		"code": rpcResp.Code,
+	})
+}
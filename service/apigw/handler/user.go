@@ -81,7 +81,7 @@ func DoSignInHandler(c *gin.Context) {
 		c.Status(http.StatusInternalServerError)
 		return
 	}
-	if rpcResp.Code != http.StatusOK {
+	if rpcResp.Code != common.StatusOK {
 		c.JSON(http.StatusOK, gin.H{
 			"msg":  "Failed to login",
 			"code": rpcResp.Code,
@@ -107,13 +107,7 @@ func DoSignInHandler(c *gin.Context) {
 	cliResp := util.RespMsg{
 		Code: int(common.StatusOK),
 		Msg:  "登陆成功",
-		Data: struct {
-			Location      string
-			Username      string
-			Token         string
-			UploadEntry   string
-			DownloadEntry string
-		}{
+		Data: common.SigninData{
 			Location:      "/static/view/home.html",
 			Username:      username,
 			Token:         rpcResp.Token,
@@ -144,17 +138,43 @@ func UserInfoHandler(c *gin.Context) {
 	cliResp := util.RespMsg{
 		Code: 0,
 		Msg:  "OK",
-		Data: gin.H{
-			"Username": username,
-			"SignupAt": resp.SignupAt,
+		Data: common.UserInfoData{
+			Username: username,
+			SignupAt: resp.SignupAt,
 			// TODO: 完善其他字段信息
-			"LastActive": resp.LastActiveAt,
+			LastActive: resp.LastActiveAt,
 		},
 	}
 	c.Data(http.StatusOK, "application/json", cliResp.JSONBytes())
 }
 
+// UserUsageHandler : 查询用户的文件数及占用空间
+func UserUsageHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
 
+	rpcResp, err := userCli.UserUsage(context.TODO(), &userProto.ReqUserUsage{
+		Username: username,
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	if rpcResp.Code != common.StatusOK {
+		c.JSON(http.StatusOK, gin.H{
+			"msg":  rpcResp.Message,
+			"code": rpcResp.Code,
+		})
+		return
+	}
 
-
-
+	cliResp := util.RespMsg{
+		Code: int(common.StatusOK),
+		Msg:  "OK",
+		Data: common.UserUsageData{
+			FileCount: rpcResp.FileCount,
+			TotalSize: rpcResp.TotalSize,
+		},
+	}
+	c.Data(http.StatusOK, "application/json", cliResp.JSONBytes())
+}
@@ -2,12 +2,17 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
-	"github.com/gin-gonic/gin"
+	"github.com/cloud/common"
+	"github.com/cloud/config"
 	userProto "github.com/cloud/service/account/proto"
+	"github.com/cloud/util"
+	"github.com/gin-gonic/gin"
 )
 
 // FileQueryHandler : 查询批量的文件元信息
@@ -61,3 +66,186 @@ func FileMetaUpdateHandler(c *gin.Context) {
 	}
 	c.Data(http.StatusOK, "application/json", rpcResp.FileData)
 }
+
+// FileListHandler : 按目录前缀分页查询用户文件, 返回的NextMarker非空时可用于查询下一页
+func FileListHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
+	prefix := c.Request.FormValue("prefix")
+	marker := c.Request.FormValue("marker")
+	limitCnt, _ := strconv.Atoi(c.Request.FormValue("limit"))
+
+	rpcResp, err := userCli.UserFileList(context.TODO(), &userProto.ReqUserFileList{
+		Username: username,
+		Prefix:   prefix,
+		Marker:   marker,
+		Limit:    int32(limitCnt),
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	if rpcResp.Code != common.StatusOK {
+		c.JSON(http.StatusOK, gin.H{
+			"msg":  rpcResp.Message,
+			"code": rpcResp.Code,
+		})
+		return
+	}
+
+	files := []struct{ FileName string }{}
+	json.Unmarshal(rpcResp.FileData, &files)
+	nextMarker := ""
+	if limitCnt > 0 && len(files) == limitCnt {
+		nextMarker = files[len(files)-1].FileName
+	}
+
+	cliResp := util.RespMsg{
+		Code: int(common.StatusOK),
+		Msg:  "OK",
+		Data: common.FileListData{
+			Files:      json.RawMessage(rpcResp.FileData),
+			NextMarker: nextMarker,
+		},
+	}
+	c.Data(http.StatusOK, "application/json", cliResp.JSONBytes())
+}
+
+// FileDeleteHandler : 按文件名删除用户文件
+func FileDeleteHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
+	filename := c.Request.FormValue("filename")
+
+	rpcResp, err := userCli.UserFileDelete(context.TODO(), &userProto.ReqUserFileDelete{
+		Username: username,
+		FileName: filename,
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"msg":  rpcResp.Message,
+		"code": rpcResp.Code,
+	})
+}
+
+// FileMoveHandler : 按文件名移动(重命名)用户文件
+func FileMoveHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
+	srcName := c.Request.FormValue("srcname")
+	destName := c.Request.FormValue("destname")
+
+	rpcResp, err := userCli.UserFileMove(context.TODO(), &userProto.ReqUserFileMove{
+		Username: username,
+		SrcName:  srcName,
+		DestName: destName,
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"msg":  rpcResp.Message,
+		"code": rpcResp.Code,
+	})
+}
+
+// FileRestoreHandler : 按文件名恢复已删除的用户文件
+func FileRestoreHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
+	filename := c.Request.FormValue("filename")
+
+	rpcResp, err := userCli.UserFileRestore(context.TODO(), &userProto.ReqUserFileRestore{
+		Username: username,
+		FileName: filename,
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"msg":  rpcResp.Message,
+		"code": rpcResp.Code,
+	})
+}
+
+// FileChangesHandler : 按顺序获取游标之后的用户文件变更事件, 返回的Cursor用于下次请求.
+// cursor为空时不返回事件, 只返回最新游标作为起点; timeout(秒)大于0且暂无新事件时长轮询等待,
+// 直到有新事件、超时或客户端断开. HasMore为true时应立即再次请求
+func FileChangesHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
+	limitCnt, _ := strconv.Atoi(c.Request.FormValue("limit"))
+	if limitCnt <= 0 || limitCnt > config.FileChangesLimit {
+		limitCnt = config.FileChangesLimit
+	}
+	cursor := int64(-1)
+	if v := c.Request.FormValue("cursor"); v != "" {
+		var err error
+		if cursor, err = strconv.ParseInt(v, 10, 64); err != nil || cursor < 0 {
+			c.JSON(http.StatusOK, gin.H{
+				"msg":  "invalid cursor",
+				"code": common.StatusParamInvalid,
+			})
+			return
+		}
+	}
+	wait, _ := strconv.Atoi(c.Request.FormValue("timeout"))
+	if wait > config.FileChangesMaxWait {
+		wait = config.FileChangesMaxWait
+	}
+	deadline := time.Now().Add(time.Duration(wait) * time.Second)
+
+	var rpcResp *userProto.RespUserFileChanges
+	for {
+		var err error
+		rpcResp, err = userCli.UserFileChanges(context.TODO(), &userProto.ReqUserFileChanges{
+			Username: username,
+			Cursor:   cursor,
+			Limit:    int32(limitCnt),
+		})
+		if err != nil {
+			log.Println(err.Error())
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if rpcResp.Code != common.StatusOK {
+			c.JSON(http.StatusOK, gin.H{
+				"msg":  rpcResp.Message,
+				"code": rpcResp.Code,
+			})
+			return
+		}
+		remain := time.Until(deadline)
+		if cursor < 0 || rpcResp.Cursor != cursor || remain <= 0 {
+			break
+		}
+		if remain > config.FileChangesPollInterval*time.Millisecond {
+			remain = config.FileChangesPollInterval * time.Millisecond
+		}
+		select {
+		case <-time.After(remain):
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+
+	events := []json.RawMessage{}
+	json.Unmarshal(rpcResp.Events, &events)
+	cliResp := util.RespMsg{
+		Code: int(common.StatusOK),
+		Msg:  "OK",
+		Data: common.FileChangesData{
+			Events:  events,
+			Cursor:  strconv.FormatInt(rpcResp.Cursor, 10),
+			HasMore: len(events) == limitCnt,
+		},
+	}
+	c.Data(http.StatusOK, "application/json", cliResp.JSONBytes())
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cloud/common"
+	userProto "github.com/cloud/service/account/proto"
+	"github.com/cloud/util"
+)
+
+// vaultResponse : 返回{code,msg,data}格式的结果, 只在成功时附带data
+func vaultResponse(c *gin.Context, code int32, msg string, data interface{}) {
+	if code != common.StatusOK {
+		c.JSON(http.StatusOK, gin.H{
+			"msg":  msg,
+			"code": code,
+		})
+		return
+	}
+	cliResp := util.RespMsg{
+		Code: int(common.StatusOK),
+		Msg:  "OK",
+		Data: data,
+	}
+	c.Data(http.StatusOK, "application/json", cliResp.JSONBytes())
+}
+
+// VaultKeyPairSetHandler : 设置加密目录密钥对, 私钥须已在客户端以口令派生的密钥加密
+func VaultKeyPairSetHandler(c *gin.Context) {
+	rpcResp, err := userCli.SetVaultKeyPair(context.TODO(), &userProto.ReqSetVaultKeyPair{
+		Username:  c.Request.FormValue("username"),
+		PublicKey: c.Request.FormValue("publickey"),
+		SecretKey: c.Request.FormValue("secretkey"),
+		KdfSalt:   c.Request.FormValue("kdfsalt"),
+		KdfParams: c.Request.FormValue("kdfparams"),
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	vaultResponse(c, rpcResp.Code, rpcResp.Message, nil)
+}
+
+// VaultKeyPairGetHandler : 获取自己的密钥对, 或指定user时获取其公钥
+func VaultKeyPairGetHandler(c *gin.Context) {
+	rpcResp, err := userCli.GetVaultKeyPair(context.TODO(), &userProto.ReqGetVaultKeyPair{
+		Username: c.Request.FormValue("username"),
+		Target:   c.Request.FormValue("user"),
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	vaultResponse(c, rpcResp.Code, rpcResp.Message, json.RawMessage(rpcResp.KeyPairData))
+}
+
+// VaultCreateHandler : 将空目录设为加密目录
+func VaultCreateHandler(c *gin.Context) {
+	rpcResp, err := userCli.CreateVault(context.TODO(), &userProto.ReqCreateVault{
+		Username:   c.Request.FormValue("username"),
+		Folder:     c.Request.FormValue("folder"),
+		WrappedKey: c.Request.FormValue("wrappedkey"),
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	vaultResponse(c, rpcResp.Code, rpcResp.Message, nil)
+}
+
+// VaultListHandler : 获取可访问的加密目录(自己的及分享给自己的)
+func VaultListHandler(c *gin.Context) {
+	rpcResp, err := userCli.ListVaults(context.TODO(), &userProto.ReqListVaults{
+		Username: c.Request.FormValue("username"),
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	vaultResponse(c, rpcResp.Code, rpcResp.Message, json.RawMessage(rpcResp.VaultData))
+}
+
+// VaultShareHandler : 分享加密目录, 目录密钥须已在客户端以对方公钥加密
+func VaultShareHandler(c *gin.Context) {
+	rpcResp, err := userCli.ShareVault(context.TODO(), &userProto.ReqShareVault{
+		Username:   c.Request.FormValue("username"),
+		Folder:     c.Request.FormValue("folder"),
+		Grantee:    c.Request.FormValue("user"),
+		WrappedKey: c.Request.FormValue("wrappedkey"),
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	vaultResponse(c, rpcResp.Code, rpcResp.Message, nil)
+}
+
+// VaultUnshareHandler : 取消加密目录的分享
+func VaultUnshareHandler(c *gin.Context) {
+	rpcResp, err := userCli.UnshareVault(context.TODO(), &userProto.ReqUnshareVault{
+		Username: c.Request.FormValue("username"),
+		Folder:   c.Request.FormValue("folder"),
+		Grantee:  c.Request.FormValue("user"),
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	vaultResponse(c, rpcResp.Code, rpcResp.Message, nil)
+}
+
+// VaultMembersHandler : 获取可访问自己的加密目录的用户
+func VaultMembersHandler(c *gin.Context) {
+	rpcResp, err := userCli.ListVaultMembers(context.TODO(), &userProto.ReqListVaultMembers{
+		Username: c.Request.FormValue("username"),
+		Folder:   c.Request.FormValue("folder"),
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	vaultResponse(c, rpcResp.Code, rpcResp.Message, json.RawMessage(rpcResp.MemberData))
+}
+
+// VaultFilesHandler : 按前缀分页获取加密目录中的文件, owner为分享者时列出分享给自己的目录
+func VaultFilesHandler(c *gin.Context) {
+	limitCnt, _ := strconv.Atoi(c.Request.FormValue("limit"))
+	rpcResp, err := userCli.ListVaultFiles(context.TODO(), &userProto.ReqListVaultFiles{
+		Username: c.Request.FormValue("username"),
+		Owner:    c.Request.FormValue("owner"),
+		Prefix:   c.Request.FormValue("prefix"),
+		Marker:   c.Request.FormValue("marker"),
+		Limit:    int32(limitCnt),
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	vaultResponse(c, rpcResp.Code, rpcResp.Message, gin.H{
+		"Files":      json.RawMessage(rpcResp.FileData),
+		"NextMarker": rpcResp.NextMarker,
+	})
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cloud/common"
+	userProto "github.com/cloud/service/account/proto"
+	"github.com/cloud/util"
+)
+
+// WebhookCreateHandler : 注册webhook, 返回的签名密钥只在此时可见
+func WebhookCreateHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
+	hookURL := c.Request.FormValue("url")
+	events := c.Request.FormValue("events")
+
+	rpcResp, err := userCli.CreateWebhook(context.TODO(), &userProto.ReqCreateWebhook{
+		Username: username,
+		Url:      hookURL,
+		Events:   events,
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	if rpcResp.Code != common.StatusOK {
+		c.JSON(http.StatusOK, gin.H{
+			"msg":  rpcResp.Message,
+			"code": rpcResp.Code,
+		})
+		return
+	}
+
+	cliResp := util.RespMsg{
+		Code: int(common.StatusOK),
+		Msg:  "OK",
+		Data: gin.H{
+			"Webhook": json.RawMessage(rpcResp.WebhookData),
+			"Secret":  rpcResp.Secret,
+		},
+	}
+	c.Data(http.StatusOK, "application/json", cliResp.JSONBytes())
+}
+
+// WebhookListHandler : 查询webhook列表
+func WebhookListHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
+
+	rpcResp, err := userCli.ListWebhooks(context.TODO(), &userProto.ReqListWebhooks{
+		Username: username,
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if len(rpcResp.WebhookData) <= 0 {
+		rpcResp.WebhookData = []byte("[]")
+	}
+	c.Data(http.StatusOK, "application/json", rpcResp.WebhookData)
+}
+
+// WebhookDeleteHandler : 删除webhook
+func WebhookDeleteHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
+	id, err := strconv.ParseInt(c.Request.FormValue("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"msg":  "参数无效",
+			"code": common.StatusParamInvalid,
+		})
+		return
+	}
+
+	rpcResp, err := userCli.DeleteWebhook(context.TODO(), &userProto.ReqDeleteWebhook{
+		Username: username,
+		Id:       id,
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"msg":  rpcResp.Message,
+		"code": rpcResp.Code,
+	})
+}
+
+// WebhookDeliveriesHandler : 按时间倒序查询webhook的投递记录, 不指定id时查询全部webhook
+func WebhookDeliveriesHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
+	hookID, _ := strconv.ParseInt(c.Request.FormValue("id"), 10, 64)
+	limitCnt, _ := strconv.Atoi(c.Request.FormValue("limit"))
+
+	rpcResp, err := userCli.ListWebhookDeliveries(context.TODO(), &userProto.ReqListWebhookDeliveries{
+		Username: username,
+		HookId:   hookID,
+		Limit:    int32(limitCnt),
+	})
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if len(rpcResp.DeliveryData) <= 0 {
+		rpcResp.DeliveryData = []byte("[]")
+	}
+	c.Data(http.StatusOK, "application/json", rpcResp.DeliveryData)
+}
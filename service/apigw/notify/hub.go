@@ -0,0 +1,98 @@
+// Package notify : 网关实例通过redis频道接收用户文件事件通知, 唤醒本实例上该用户的实时事件流.
+//
+// 通知只携带用户名, 事件内容由事件流按游标从事件日志读取, 因此通知丢失或重复不影响事件的完整和顺序,
+// 只影响推送的及时性
+package notify
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/cloud/cache/redis"
+)
+
+// reconnectInterval : redis订阅断开后的重连间隔
+const reconnectInterval = 3 * time.Second
+
+// Hub : 按用户名分发事件通知
+type Hub struct {
+	mu   sync.Mutex
+	subs map[string]map[chan struct{}]struct{}
+}
+
+// NewHub : 创建Hub
+func NewHub() *Hub {
+	return &Hub{subs: map[string]map[chan struct{}]struct{}{}}
+}
+
+// Subscribe : 订阅用户的事件通知, 有通知时返回的channel可读(多次通知可能合并为一次).
+// 不再使用时须调用cancel
+func (h *Hub) Subscribe(username string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	if h.subs[username] == nil {
+		h.subs[username] = map[chan struct{}]struct{}{}
+	}
+	h.subs[username][ch] = struct{}{}
+	h.mu.Unlock()
+
+	cancel := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs[username], ch)
+		if len(h.subs[username]) == 0 {
+			delete(h.subs, username)
+		}
+	}
+	return ch, cancel
+}
+
+// Notify : 唤醒用户的全部订阅者
+func (h *Hub) Notify(username string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[username] {
+		wake(ch)
+	}
+}
+
+// NotifyAll : 唤醒全部订阅者, 用于重新订阅后补上断开期间可能遗漏的通知
+func (h *Hub) NotifyAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, chs := range h.subs {
+		for ch := range chs {
+			wake(ch)
+		}
+	}
+}
+
+// wake : 非阻塞地唤醒订阅者, 已有未处理的通知时忽略
+func wake(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// Run : 订阅redis频道并分发收到的通知, 连接断开后自动重连, 直到ctx结束
+func (h *Hub) Run(ctx context.Context, channel string) {
+	for {
+		err := redis.Subscribe(ctx, channel, h.NotifyAll, func(msg []byte) {
+			h.Notify(string(msg))
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Println("subscribe file events failed: " + err.Error())
+		}
+		select {
+		case <-time.After(reconnectInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -33,11 +33,49 @@ func Router() *gin.Engine {
 
 	// 用户查询
 	router.POST("/user/info", handler.UserInfoHandler)
+	// 用户空间用量
+	router.POST("/user/usage", handler.UserUsageHandler)
 
 	// 用户文件查询
 	router.POST("/file/query", handler.FileQueryHandler)
 	// 用户文件修改(重命名)
 	router.POST("/file/update", handler.FileMetaUpdateHandler)
+	// 按目录前缀分页查询、删除及移动用户文件
+	router.POST("/file/list", handler.FileListHandler)
+	router.POST("/file/delete", handler.FileDeleteHandler)
+	router.POST("/file/move", handler.FileMoveHandler)
+	router.POST("/file/restore", handler.FileRestoreHandler)
+	// 用户文件变更事件(支持长轮询)
+	router.GET("/file/changes", handler.FileChangesHandler)
+	router.POST("/file/changes", handler.FileChangesHandler)
+	// 用户文件变更实时推送(Server-Sent Events)
+	router.GET("/file/events", handler.FileEventsHandler)
+
+	// S3访问密钥管理
+	router.POST("/user/accesskey/create", handler.AccessKeyCreateHandler)
+	router.POST("/user/accesskey/list", handler.AccessKeyListHandler)
+	router.POST("/user/accesskey/delete", handler.AccessKeyDeleteHandler)
+
+	// SFTP公钥管理
+	router.POST("/user/sshkey/add", handler.SSHKeyAddHandler)
+	router.POST("/user/sshkey/list", handler.SSHKeyListHandler)
+	router.POST("/user/sshkey/delete", handler.SSHKeyDeleteHandler)
+
+	// 端到端加密目录: 密钥对、目录及分享(内容及文件名由客户端加密)
+	router.POST("/vault/keypair/set", handler.VaultKeyPairSetHandler)
+	router.POST("/vault/keypair/get", handler.VaultKeyPairGetHandler)
+	router.POST("/vault/create", handler.VaultCreateHandler)
+	router.POST("/vault/list", handler.VaultListHandler)
+	router.POST("/vault/share", handler.VaultShareHandler)
+	router.POST("/vault/unshare", handler.VaultUnshareHandler)
+	router.POST("/vault/members", handler.VaultMembersHandler)
+	router.POST("/vault/files", handler.VaultFilesHandler)
+
+	// webhook管理
+	router.POST("/user/webhook/create", handler.WebhookCreateHandler)
+	router.POST("/user/webhook/list", handler.WebhookListHandler)
+	router.POST("/user/webhook/delete", handler.WebhookDeleteHandler)
+	router.POST("/user/webhook/deliveries", handler.WebhookDeliveriesHandler)
 
 	return router
 }
\ No newline at end of file
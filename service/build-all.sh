@@ -43,9 +43,13 @@ services="
 dbproxy
 upload
 download
-transfer
+job
 account
 apigw
+s3gw
+webdav
+sftp
+webhook
 "
 
 # 执行编译service
@@ -3,9 +3,11 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"github.com/mitchellh/mapstructure"
 	"github.com/micro/go-micro"
+	"github.com/cloud/common"
 	"github.com/cloud/config"
 	"github.com/cloud/service/dbproxy/orm"
 	dbProto "github.com/cloud/service/dbproxy/proto"
@@ -34,6 +36,11 @@ func init() {
 	dbCli = dbProto.NewDBProxyService("go.micro.service.dbproxy", service.Client())
 }
 
+// SetService : 替换访问dbproxy的客户端, 用于以进程内的实现测试依赖dbproxy的接口, 应在发起请求之前调用
+func SetService(svc dbProto.DBProxyService) {
+	dbCli = svc
+}
+
 func TableFileToFileMeta(tfile orm.TableFile) FileMeta {
 	return FileMeta{
 		FileSha1: tfile.FileHash,
@@ -99,6 +106,144 @@ func ToTableUserFiles(src interface{}) []orm.TableUserFile {
 	return ufile
 }
 
+func ToTableAccessKey(src interface{}) orm.TableAccessKey {
+	key := orm.TableAccessKey{}
+	mapstructure.Decode(src, &key)
+	return key
+}
+
+func ToTableAccessKeys(src interface{}) []orm.TableAccessKey {
+	keys := []orm.TableAccessKey{}
+	mapstructure.Decode(src, &keys)
+	return keys
+}
+
+func ToTableSSHKeys(src interface{}) []orm.TableSSHKey {
+	keys := []orm.TableSSHKey{}
+	mapstructure.Decode(src, &keys)
+	return keys
+}
+
+func ToTableUserUsage(src interface{}) orm.TableUserUsage {
+	usage := orm.TableUserUsage{}
+	mapstructure.Decode(src, &usage)
+	return usage
+}
+
+func ToTableUserFolders(src interface{}) []orm.TableUserFolder {
+	folders := []orm.TableUserFolder{}
+	mapstructure.Decode(src, &folders)
+	return folders
+}
+
+func ToTableUserFileEvents(src interface{}) []orm.TableUserFileEvent {
+	events := []orm.TableUserFileEvent{}
+	mapstructure.Decode(src, &events)
+	return events
+}
+
+func ToTableWebhook(src interface{}) orm.TableWebhook {
+	hook := orm.TableWebhook{}
+	mapstructure.Decode(src, &hook)
+	return hook
+}
+
+func ToTableWebhooks(src interface{}) []orm.TableWebhook {
+	hooks := []orm.TableWebhook{}
+	mapstructure.Decode(src, &hooks)
+	return hooks
+}
+
+func ToTableWebhookDelivery(src interface{}) orm.TableWebhookDelivery {
+	delivery := orm.TableWebhookDelivery{}
+	mapstructure.Decode(src, &delivery)
+	return delivery
+}
+
+func ToTableWebhookDeliveries(src interface{}) []orm.TableWebhookDelivery {
+	deliveries := []orm.TableWebhookDelivery{}
+	mapstructure.Decode(src, &deliveries)
+	return deliveries
+}
+
+func ToTableFileLocations(src interface{}) []orm.TableFileLocation {
+	locations := []orm.TableFileLocation{}
+	mapstructure.Decode(src, &locations)
+	return locations
+}
+
+func ToTableFileTier(src interface{}) orm.TableFileTier {
+	tier := orm.TableFileTier{}
+	mapstructure.Decode(src, &tier)
+	return tier
+}
+
+func ToTableTierUsages(src interface{}) []orm.TableTierUsage {
+	usages := []orm.TableTierUsage{}
+	mapstructure.Decode(src, &usages)
+	return usages
+}
+
+func ToTableFileScrubs(src interface{}) []orm.TableFileScrub {
+	scrubs := []orm.TableFileScrub{}
+	mapstructure.Decode(src, &scrubs)
+	return scrubs
+}
+
+func ToTableVaultKeyPair(src interface{}) orm.TableVaultKeyPair {
+	kp := orm.TableVaultKeyPair{}
+	mapstructure.Decode(src, &kp)
+	return kp
+}
+
+func ToTableVault(src interface{}) orm.TableVault {
+	v := orm.TableVault{}
+	mapstructure.Decode(src, &v)
+	return v
+}
+
+func ToTableVaults(src interface{}) []orm.TableVault {
+	vaults := []orm.TableVault{}
+	mapstructure.Decode(src, &vaults)
+	return vaults
+}
+
+func ToTableFileKey(src interface{}) orm.TableFileKey {
+	key := orm.TableFileKey{}
+	mapstructure.Decode(src, &key)
+	return key
+}
+
+func ToTableFileKeys(src interface{}) []orm.TableFileKey {
+	keys := []orm.TableFileKey{}
+	mapstructure.Decode(src, &keys)
+	return keys
+}
+
+func ToTableFileCompression(src interface{}) orm.TableFileCompression {
+	info := orm.TableFileCompression{}
+	mapstructure.Decode(src, &info)
+	return info
+}
+
+func ToTableCompressionUsages(src interface{}) []orm.TableCompressionUsage {
+	usages := []orm.TableCompressionUsage{}
+	mapstructure.Decode(src, &usages)
+	return usages
+}
+
+func ToTableJob(src interface{}) orm.TableJob {
+	job := orm.TableJob{}
+	mapstructure.Decode(src, &job)
+	return job
+}
+
+func ToTableJobs(src interface{}) []orm.TableJob {
+	jobs := []orm.TableJob{}
+	mapstructure.Decode(src, &jobs)
+	return jobs
+}
+
 func GetFileMeta(filehash string) (*orm.ExecResult, error) {
 	uInfo, _ := json.Marshal([]interface{}{filehash})
 	res, err := execAction("/file/GetFileMeta", uInfo)
@@ -124,6 +269,176 @@ func UpdateFileLocation(filehash, location string) (*orm.ExecResult, error) {
 	return parseBody(res), err
 }
 
+// ReplaceFileLocation : 将文件表、副本位置表及完整性检查表中记录为oldAddr的位置改为newAddr, 返回是否有记录被更新
+func ReplaceFileLocation(filehash, oldAddr, newAddr string) (bool, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash, oldAddr, newAddr})
+	res, err := execAction("/file/ReplaceFileLocation", uInfo)
+	if err != nil {
+		return false, err
+	}
+
+	execRes := parseBody(res)
+	if execRes == nil || !execRes.Suc {
+		return false, errors.New("replace file location failed")
+	}
+
+	var data map[string]bool
+	err = mapstructure.Decode(execRes.Data, &data)
+	if err != nil {
+		return false, err
+	}
+	return data["updated"], nil
+}
+
+// AddFileLocation : 记录文件在存储中的位置
+func AddFileLocation(filehash string, storeType common.StoreType, location string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash, storeType, location})
+	res, err := execAction("/file/AddFileLocation", uInfo)
+	return parseBody(res), err
+}
+
+// ListFileLocations : 查询文件的全部存储位置
+func ListFileLocations(filehash string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash})
+	res, err := execAction("/file/ListFileLocations", uInfo)
+	return parseBody(res), err
+}
+
+// SetFileLocationStatus : 更新文件在某个存储中的副本状态
+func SetFileLocationStatus(filehash string, storeType common.StoreType, status common.LocationStatus) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash, storeType, status})
+	res, err := execAction("/file/SetFileLocationStatus", uInfo)
+	return parseBody(res), err
+}
+
+// RemoveFileLocation : 删除文件在某个存储中的位置记录
+func RemoveFileLocation(filehash string, storeType common.StoreType) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash, storeType})
+	res, err := execAction("/file/RemoveFileLocation", uInfo)
+	return parseBody(res), err
+}
+
+// RecordFileAccess : 记录文件被访问一次, 返回文件的层级及访问情况
+func RecordFileAccess(filehash string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash})
+	res, err := execAction("/file/RecordFileAccess", uInfo)
+	return parseBody(res), err
+}
+
+// GetFileTier : 查询文件的层级及访问情况
+func GetFileTier(filehash string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash})
+	res, err := execAction("/file/GetFileTier", uInfo)
+	return parseBody(res), err
+}
+
+// SetFileTier : 更新文件的层级
+func SetFileTier(filehash string, tier common.StorageTier) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash, tier})
+	res, err := execAction("/file/SetFileTier", uInfo)
+	return parseBody(res), err
+}
+
+// ListTierCandidates : 查询层级为tier且最后访问时间早于before的文件hash
+func ListTierCandidates(tier common.StorageTier, before int64, limit int) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{tier, before, limit})
+	res, err := execAction("/file/ListTierCandidates", uInfo)
+	return parseBody(res), err
+}
+
+// GetTierUsage : 各层级的文件数及占用空间
+func GetTierUsage() (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{})
+	res, err := execAction("/file/GetTierUsage", uInfo)
+	return parseBody(res), err
+}
+
+// RecordFileScrub : 记录文件在某个位置最近一次完整性检查的结果
+func RecordFileScrub(filehash string, storeType common.StoreType, location string,
+	result common.ScrubResult, checksum, crc64 string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash, storeType, location, result, checksum, crc64})
+	res, err := execAction("/file/RecordFileScrub", uInfo)
+	return parseBody(res), err
+}
+
+// ListFileScrubs : 查询文件各位置最近一次完整性检查的结果
+func ListFileScrubs(filehash string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash})
+	res, err := execAction("/file/ListFileScrubs", uInfo)
+	return parseBody(res), err
+}
+
+// ListScrubCandidates : 查询从未检查过或最早的检查时间早于before的文件hash
+func ListScrubCandidates(before int64, limit int) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{before, limit})
+	res, err := execAction("/file/ListScrubCandidates", uInfo)
+	return parseBody(res), err
+}
+
+// GetFileKey : 查询文件在某个存储中的位置的数据密钥
+func GetFileKey(filehash string, storeType common.StoreType) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash, storeType})
+	res, err := execAction("/file/GetFileKey", uInfo)
+	return parseBody(res), err
+}
+
+// CreateFileKey : 保存数据密钥, 已有记录时不覆盖, 返回保存的记录
+func CreateFileKey(filehash string, storeType common.StoreType, keyID, wrappedKey string, chunkSize int) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash, storeType, keyID, wrappedKey, chunkSize})
+	res, err := execAction("/file/CreateFileKey", uInfo)
+	return parseBody(res), err
+}
+
+// RewrapFileKey : 主密钥仍为oldKeyID时更新为重新加密的数据密钥
+func RewrapFileKey(filehash string, storeType common.StoreType, oldKeyID, keyID, wrappedKey string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash, storeType, oldKeyID, keyID, wrappedKey})
+	res, err := execAction("/file/RewrapFileKey", uInfo)
+	return parseBody(res), err
+}
+
+// RemoveFileKey : 删除文件在某个存储中的位置的数据密钥
+func RemoveFileKey(filehash string, storeType common.StoreType) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash, storeType})
+	res, err := execAction("/file/RemoveFileKey", uInfo)
+	return parseBody(res), err
+}
+
+// ListStaleFileKeys : 查询主密钥不是keyID的数据密钥
+func ListStaleFileKeys(keyID string, limit int) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{keyID, limit})
+	res, err := execAction("/file/ListStaleFileKeys", uInfo)
+	return parseBody(res), err
+}
+
+// GetFileCompression : 查询文件在某个存储中的位置的压缩信息
+func GetFileCompression(filehash string, storeType common.StoreType) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash, storeType})
+	res, err := execAction("/file/GetFileCompression", uInfo)
+	return parseBody(res), err
+}
+
+// SaveFileCompression : 保存压缩信息, 已有记录时覆盖
+func SaveFileCompression(filehash string, storeType common.StoreType, codec string, level, frameSize int,
+	logicalSize, storedSize int64) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash, storeType, codec, level, frameSize, logicalSize, storedSize})
+	res, err := execAction("/file/SaveFileCompression", uInfo)
+	return parseBody(res), err
+}
+
+// RemoveFileCompression : 删除文件在某个存储中的位置的压缩信息
+func RemoveFileCompression(filehash string, storeType common.StoreType) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash, storeType})
+	res, err := execAction("/file/RemoveFileCompression", uInfo)
+	return parseBody(res), err
+}
+
+// GetCompressionUsage : 各存储中已压缩的文件数、原始长度及压缩后的长度
+func GetCompressionUsage() (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{})
+	res, err := execAction("/file/GetCompressionUsage", uInfo)
+	return parseBody(res), err
+}
+
 func UserSignup(username, encPasswd string) (*orm.ExecResult, error) {
 	uInfo, _ := json.Marshal([]interface{}{username, encPasswd})
 	res, err := execAction("/user/UserSignup", uInfo)
@@ -220,3 +535,391 @@ func IsUserFileUploaded(username string, filehash string) (bool, error) {
 	log.Printf("IsUserFileUploaded: %s %s %+v\n", username, filehash, data)
 	return data["exists"], nil
 }
+
+// CreateVaultKeyPair : 保存用户的加密目录密钥对, 已存在时失败
+func CreateVaultKeyPair(username, publicKey, secretKey, kdfSalt, kdfParams string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, publicKey, secretKey, kdfSalt, kdfParams})
+	res, err := execAction("/user/CreateVaultKeyPair", uInfo)
+	return parseBody(res), err
+}
+
+// UpdateVaultSecretKey : 修改口令后更新加密的私钥
+func UpdateVaultSecretKey(username, publicKey, secretKey, kdfSalt, kdfParams string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, publicKey, secretKey, kdfSalt, kdfParams})
+	res, err := execAction("/user/UpdateVaultSecretKey", uInfo)
+	return parseBody(res), err
+}
+
+// GetVaultKeyPair : 查询用户的加密目录密钥对
+func GetVaultKeyPair(username string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username})
+	res, err := execAction("/user/GetVaultKeyPair", uInfo)
+	return parseBody(res), err
+}
+
+// AddVaultMember : 创建加密目录(username为owner)或分享给username
+func AddVaultMember(owner, folder, username, wrappedKey string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{owner, folder, username, wrappedKey})
+	res, err := execAction("/user/AddVaultMember", uInfo)
+	return parseBody(res), err
+}
+
+// RemoveVaultMember : 取消加密目录的分享
+func RemoveVaultMember(owner, folder, username string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{owner, folder, username})
+	res, err := execAction("/user/RemoveVaultMember", uInfo)
+	return parseBody(res), err
+}
+
+// ListVaults : 查询用户可访问的加密目录
+func ListVaults(username string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username})
+	res, err := execAction("/user/ListVaults", uInfo)
+	return parseBody(res), err
+}
+
+// ListVaultMembers : 查询可访问加密目录的用户
+func ListVaultMembers(owner, folder string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{owner, folder})
+	res, err := execAction("/user/ListVaultMembers", uInfo)
+	return parseBody(res), err
+}
+
+// GetVaultByPath : 查询owner的文件filename所在的、username可访问的加密目录
+func GetVaultByPath(owner, username, filename string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{owner, username, filename})
+	res, err := execAction("/user/GetVaultByPath", uInfo)
+	return parseBody(res), err
+}
+
+// VaultFolder : owner的文件filename所在的加密目录, 不在加密目录中时返回空字符串
+func VaultFolder(owner, filename string) (string, error) {
+	res, err := GetVaultByPath(owner, owner, filename)
+	if err != nil {
+		return "", err
+	}
+	if res == nil || !res.Suc {
+		return "", errors.New("query vault failed")
+	}
+	if res.Data == nil {
+		return "", nil
+	}
+	return ToTableVault(res.Data).Folder, nil
+}
+
+// IsVaultFileHash : 是否有用户在加密目录中保存了该hash的文件
+func IsVaultFileHash(filehash string) (bool, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash})
+	res, err := execAction("/user/IsVaultFileHash", uInfo)
+	if err != nil {
+		return false, err
+	}
+	execRes := parseBody(res)
+	if execRes == nil || !execRes.Suc {
+		return false, errors.New("query vault file failed")
+	}
+	exists, _ := execRes.Data.(bool)
+	return exists, nil
+}
+
+// CreateAccessKey : 保存新生成的S3访问密钥
+func CreateAccessKey(username, accessKey, secretKey string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, accessKey, secretKey})
+	res, err := execAction("/user/CreateAccessKey", uInfo)
+	return parseBody(res), err
+}
+
+// GetAccessKey : 查询AccessKey对应的密钥及用户, 不存在时Data为nil
+func GetAccessKey(accessKey string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{accessKey})
+	res, err := execAction("/user/GetAccessKey", uInfo)
+	return parseBody(res), err
+}
+
+func ListAccessKeys(username string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username})
+	res, err := execAction("/user/ListAccessKeys", uInfo)
+	return parseBody(res), err
+}
+
+func DeleteAccessKey(username, accessKey string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, accessKey})
+	res, err := execAction("/user/DeleteAccessKey", uInfo)
+	return parseBody(res), err
+}
+
+func AddSSHKey(username, keyName, fingerprint, publicKey string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, keyName, fingerprint, publicKey})
+	res, err := execAction("/user/AddSSHKey", uInfo)
+	return parseBody(res), err
+}
+
+// GetSSHKeysByFingerprint : 查询指纹对应的全部有效公钥(同一公钥可能被多个用户添加)
+func GetSSHKeysByFingerprint(fingerprint string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{fingerprint})
+	res, err := execAction("/user/GetSSHKeysByFingerprint", uInfo)
+	return parseBody(res), err
+}
+
+func ListSSHKeys(username string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username})
+	res, err := execAction("/user/ListSSHKeys", uInfo)
+	return parseBody(res), err
+}
+
+func DeleteSSHKey(username, fingerprint string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, fingerprint})
+	res, err := execAction("/user/DeleteSSHKey", uInfo)
+	return parseBody(res), err
+}
+
+// QueryUserFileByName : 按文件名查询用户文件, 不存在时Data为nil
+func QueryUserFileByName(username, filename string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, filename})
+	res, err := execAction("/ufile/QueryUserFileByName", uInfo)
+	return parseBody(res), err
+}
+
+// ListUserFilesByPrefix : 按文件名字典序分页查询指定前缀的用户文件
+func ListUserFilesByPrefix(username, prefix, marker string, limit int) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, prefix, marker, limit})
+	res, err := execAction("/ufile/ListUserFilesByPrefix", uInfo)
+	return parseBody(res), err
+}
+
+// SetUserFileETag : 记录S3对象的ETag, 文件内容已不是filehash时不更新
+func SetUserFileETag(username, filename, filehash, etag string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, filename, filehash, etag})
+	res, err := execAction("/ufile/SetUserFileETag", uInfo)
+	return parseBody(res), err
+}
+
+func DeleteUserFileByName(username, filename string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, filename})
+	res, err := execAction("/ufile/DeleteUserFileByName", uInfo)
+	return parseBody(res), err
+}
+
+func ListUserFolders(username string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username})
+	res, err := execAction("/ufile/ListUserFolders", uInfo)
+	return parseBody(res), err
+}
+
+func DeleteUserFilesByPrefix(username, prefix string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, prefix})
+	res, err := execAction("/ufile/DeleteUserFilesByPrefix", uInfo)
+	return parseBody(res), err
+}
+
+func RenameUserFileByName(username, oldName, newName string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, oldName, newName})
+	res, err := execAction("/ufile/RenameUserFileByName", uInfo)
+	return parseBody(res), err
+}
+
+func RenameUserFilesByPrefix(username, oldPrefix, newPrefix string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, oldPrefix, newPrefix})
+	res, err := execAction("/ufile/RenameUserFilesByPrefix", uInfo)
+	return parseBody(res), err
+}
+
+func GetUserFileUsage(username string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username})
+	res, err := execAction("/ufile/GetUserFileUsage", uInfo)
+	return parseBody(res), err
+}
+
+// RestoreUserFile : 恢复已删除的用户文件
+func RestoreUserFile(username, filename string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, filename})
+	res, err := execAction("/ufile/RestoreUserFile", uInfo)
+	return parseBody(res), err
+}
+
+// AppendUserFileEvent : 记录不修改文件表的用户文件事件(如分享)
+func AppendUserFileEvent(username, event, filename, filehash string, filesize int64) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, event, filename, filehash, filesize})
+	res, err := execAction("/ufile/AppendUserFileEvent", uInfo)
+	return parseBody(res), err
+}
+
+// ListUserFileEvents : 查询游标之后的用户文件变更事件
+func ListUserFileEvents(username string, cursor int64, limit int) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, cursor, limit})
+	res, err := execAction("/ufile/ListUserFileEvents", uInfo)
+	return parseBody(res), err
+}
+
+// GetUserFileEventCursor : 查询用户最新的变更游标
+func GetUserFileEventCursor(username string) (int64, error) {
+	uInfo, _ := json.Marshal([]interface{}{username})
+	res, err := execAction("/ufile/GetUserFileEventCursor", uInfo)
+	if err != nil {
+		return 0, err
+	}
+
+	execRes := parseBody(res)
+	if execRes == nil || !execRes.Suc {
+		return 0, errors.New("query event cursor failed")
+	}
+
+	var data map[string]int64
+	err = mapstructure.Decode(execRes.Data, &data)
+	if err != nil {
+		return 0, err
+	}
+	return data["cursor"], nil
+}
+
+// AppendFileTransferEvents : 文件转移完成后为持有该文件的用户记录事件
+func AppendFileTransferEvents(filehash string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{filehash})
+	res, err := execAction("/ufile/AppendFileTransferEvents", uInfo)
+	return parseBody(res), err
+}
+
+// ListFileEvents : 查询游标之后、before(unix秒)之前所有用户的文件变更事件
+func ListFileEvents(cursor, before int64, limit int) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{cursor, before, limit})
+	res, err := execAction("/ufile/ListFileEvents", uInfo)
+	return parseBody(res), err
+}
+
+// CreateWebhook : 注册webhook
+func CreateWebhook(username, url, secret, events string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, url, secret, events})
+	res, err := execAction("/webhook/CreateWebhook", uInfo)
+	return parseBody(res), err
+}
+
+func ListWebhooks(username string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username})
+	res, err := execAction("/webhook/ListWebhooks", uInfo)
+	return parseBody(res), err
+}
+
+func DeleteWebhook(username string, id int64) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, id})
+	res, err := execAction("/webhook/DeleteWebhook", uInfo)
+	return parseBody(res), err
+}
+
+// ListActiveWebhooks : 查询接收用户事件的webhook(含签名密钥)
+func ListActiveWebhooks(username string) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username})
+	res, err := execAction("/webhook/ListActiveWebhooks", uInfo)
+	return parseBody(res), err
+}
+
+func AdvanceWebhookCursor(id, cursor int64) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{id, cursor})
+	res, err := execAction("/webhook/AdvanceWebhookCursor", uInfo)
+	return parseBody(res), err
+}
+
+// CreateWebhookDelivery : 记录待投递的事件, 返回新记录的ID, 已存在时为0
+func CreateWebhookDelivery(hookID, eventID int64, username, event, payload string, nextRetryAt int64) (int64, error) {
+	uInfo, _ := json.Marshal([]interface{}{hookID, eventID, username, event, payload, nextRetryAt})
+	res, err := execAction("/webhook/CreateWebhookDelivery", uInfo)
+	if err != nil {
+		return 0, err
+	}
+
+	execRes := parseBody(res)
+	if execRes == nil || !execRes.Suc {
+		return 0, errors.New("create webhook delivery failed")
+	}
+
+	var data map[string]int64
+	err = mapstructure.Decode(execRes.Data, &data)
+	if err != nil {
+		return 0, err
+	}
+	return data["id"], nil
+}
+
+func UpdateWebhookDelivery(id int64, status, attempts, responseCode int, lastError string, nextRetryAt int64) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{id, status, attempts, responseCode, lastError, nextRetryAt})
+	res, err := execAction("/webhook/UpdateWebhookDelivery", uInfo)
+	return parseBody(res), err
+}
+
+// GetWebhookDelivery : 查询webhook对某事件的投递记录, 不存在或webhook已停用时ID为0
+func GetWebhookDelivery(hookID, eventID int64) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{hookID, eventID})
+	res, err := execAction("/webhook/GetWebhookDelivery", uInfo)
+	return parseBody(res), err
+}
+
+func ListWebhookDeliveries(username string, hookID int64, limit int) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{username, hookID, limit})
+	res, err := execAction("/webhook/ListWebhookDeliveries", uInfo)
+	return parseBody(res), err
+}
+
+// CreateJob : 创建待执行的任务, 幂等键已存在时返回已有的任务且created为false,
+// 已有的任务已失败时重置为待执行且created为true
+func CreateJob(jobType, idemKey, payload string, priority, maxAttempts int, nextRunAt int64) (orm.TableJob, bool, error) {
+	uInfo, _ := json.Marshal([]interface{}{jobType, idemKey, payload, priority, maxAttempts, nextRunAt})
+	res, err := execAction("/job/CreateJob", uInfo)
+	if err != nil {
+		return orm.TableJob{}, false, err
+	}
+
+	execRes := parseBody(res)
+	if execRes == nil || !execRes.Suc {
+		return orm.TableJob{}, false, errors.New("create job failed")
+	}
+
+	var data struct {
+		Job     orm.TableJob
+		Created bool
+	}
+	err = mapstructure.Decode(execRes.Data, &data)
+	if err != nil {
+		return orm.TableJob{}, false, err
+	}
+	return data.Job, data.Created, nil
+}
+
+func GetJob(id int64) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{id})
+	res, err := execAction("/job/GetJob", uInfo)
+	return parseBody(res), err
+}
+
+// ListJobs : 按ID倒序查询任务, jobType为空时查询全部类型, status小于0时查询全部状态
+func ListJobs(jobType string, status, limit int) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{jobType, status, limit})
+	res, err := execAction("/job/ListJobs", uInfo)
+	return parseBody(res), err
+}
+
+// ListDueJobs : 查询待重新入队及租约已过期的任务
+func ListDueJobs(now int64, limit int) (*orm.ExecResult, error) {
+	uInfo, _ := json.Marshal([]interface{}{now, limit})
+	res, err := execAction("/job/ListDueJobs", uInfo)
+	return parseBody(res), err
+}
+
+// TransitJob : 按状态及next_run_at条件更新任务状态, 条件不满足(已被其他实例处理)时返回false
+func TransitJob(id int64, fromStatus int, fromNextRunAt int64, toStatus int, nextRunAt int64, lastError string) (bool, error) {
+	uInfo, _ := json.Marshal([]interface{}{id, fromStatus, fromNextRunAt, toStatus, nextRunAt, lastError})
+	res, err := execAction("/job/TransitJob", uInfo)
+	if err != nil {
+		return false, err
+	}
+
+	execRes := parseBody(res)
+	if execRes == nil || !execRes.Suc {
+		return false, errors.New("transit job failed")
+	}
+
+	var data map[string]bool
+	err = mapstructure.Decode(execRes.Data, &data)
+	if err != nil {
+		return false, err
+	}
+	return data["updated"], nil
+}
@@ -5,13 +5,18 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sync"
 	"github.com/cloud/service/dbproxy/config"
 	_ "github.com/go-sql-driver/mysql"
 )
 
-var db *sql.DB
+var (
+	db     *sql.DB
+	dbOnce sync.Once
+)
 
-func init() {
+// connect : 连接数据库, 失败时退出
+func connect() {
 	db, _ = sql.Open("mysql", config.MySQLSource)
 	db.SetMaxOpenConns(1000)
 	err := db.Ping()
@@ -21,8 +26,10 @@ func init() {
 	}
 }
 
-// DBConn : 返回数据库连接对象
+// DBConn : 返回数据库连接对象, 首次调用时连接数据库;
+// 只引用orm中类型的服务(如通过dbproxy客户端)不会连接数据库
 func DBConn() *sql.DB {
+	dbOnce.Do(connect)
 	return db
 }
 
@@ -1,7 +1,12 @@
 package dbproxy
 
 import (
+	"encoding/json"
+	"github.com/cloud/cache/redis"
 	"github.com/cloud/config"
+	"github.com/cloud/mq"
+	mydb "github.com/cloud/service/dbproxy/conn"
+	"github.com/cloud/service/dbproxy/orm"
 	dbProxy "github.com/cloud/service/dbproxy/proto"
 	dbRpc "github.com/cloud/service/dbproxy/rpc"
 	"github.com/micro/go-micro"
@@ -23,6 +28,28 @@ func startRPCService() {
 	}
 }
 
+// notifyUserFileEvent : 用户文件事件提交后通知webhook服务及各网关实例.
+// 通知失败时事件仍在事件日志中, 会随该用户的下一次通知一起投递
+func notifyUserFileEvent(username string) {
+	if config.WebhookEnable {
+		data, _ := json.Marshal(mq.FileEventData{UserName: username})
+		if !mq.Publish(config.WebhookExchangeName, config.WebhookEventRoutingKey, data) {
+			log.Println("publish file event failed, user: " + username)
+		}
+	}
+	if config.FileNotifyEnable {
+		if !redis.Publish(config.FileNotifyChannel, []byte(username)) {
+			log.Println("notify file event failed, user: " + username)
+		}
+	}
+}
+
 func main() {
+	// 启动时连接数据库, 连接失败时退出
+	mydb.DBConn()
+
+	if config.WebhookEnable || config.FileNotifyEnable {
+		orm.UserFileEventNotify = notifyUserFileEvent
+	}
 	startRPCService()
 }
\ No newline at end of file
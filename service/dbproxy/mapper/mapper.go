@@ -8,10 +8,32 @@ import (
 )
 
 var funcs = map[string]interface{}{
-	"/file/OnFileUploadFinished": orm.OnFileUploadFinished,
-	"/file/GetFileMeta":          orm.GetFileMeta,
-	"/file/GetFileMetaList":      orm.GetFileMetaList,
-	"/file/UpdateFileLocation":   orm.UpdateFileLocation,
+	"/file/OnFileUploadFinished":  orm.OnFileUploadFinished,
+	"/file/GetFileMeta":           orm.GetFileMeta,
+	"/file/GetFileMetaList":       orm.GetFileMetaList,
+	"/file/UpdateFileLocation":    orm.UpdateFileLocation,
+	"/file/ReplaceFileLocation":   orm.ReplaceFileLocation,
+	"/file/AddFileLocation":       orm.AddFileLocation,
+	"/file/ListFileLocations":     orm.ListFileLocations,
+	"/file/SetFileLocationStatus": orm.SetFileLocationStatus,
+	"/file/RemoveFileLocation":    orm.RemoveFileLocation,
+	"/file/RecordFileAccess":      orm.RecordFileAccess,
+	"/file/GetFileTier":           orm.GetFileTier,
+	"/file/SetFileTier":           orm.SetFileTier,
+	"/file/ListTierCandidates":    orm.ListTierCandidates,
+	"/file/GetTierUsage":          orm.GetTierUsage,
+	"/file/RecordFileScrub":       orm.RecordFileScrub,
+	"/file/ListFileScrubs":        orm.ListFileScrubs,
+	"/file/ListScrubCandidates":   orm.ListScrubCandidates,
+	"/file/GetFileKey":            orm.GetFileKey,
+	"/file/CreateFileKey":         orm.CreateFileKey,
+	"/file/RewrapFileKey":         orm.RewrapFileKey,
+	"/file/RemoveFileKey":         orm.RemoveFileKey,
+	"/file/ListStaleFileKeys":     orm.ListStaleFileKeys,
+	"/file/GetFileCompression":    orm.GetFileCompression,
+	"/file/SaveFileCompression":   orm.SaveFileCompression,
+	"/file/RemoveFileCompression": orm.RemoveFileCompression,
+	"/file/GetCompressionUsage":   orm.GetCompressionUsage,
 
 	"/user/UserSignup":   orm.UserSignup,
 	"/user/UserSignin":   orm.UserSignin,
@@ -20,12 +42,63 @@ var funcs = map[string]interface{}{
 	"/user/UserExist":    orm.UserExist,
 	"/user/GetUserToken": orm.GetUserToken,
 
+	"/user/CreateAccessKey": orm.CreateAccessKey,
+	"/user/GetAccessKey":    orm.GetAccessKey,
+	"/user/ListAccessKeys":  orm.ListAccessKeys,
+	"/user/DeleteAccessKey": orm.DeleteAccessKey,
+
+	"/user/CreateVaultKeyPair":   orm.CreateVaultKeyPair,
+	"/user/UpdateVaultSecretKey": orm.UpdateVaultSecretKey,
+	"/user/GetVaultKeyPair":      orm.GetVaultKeyPair,
+	"/user/AddVaultMember":       orm.AddVaultMember,
+	"/user/RemoveVaultMember":    orm.RemoveVaultMember,
+	"/user/ListVaults":           orm.ListVaults,
+	"/user/ListVaultMembers":     orm.ListVaultMembers,
+	"/user/GetVaultByPath":       orm.GetVaultByPath,
+	"/user/IsVaultFileHash":      orm.IsVaultFileHash,
+
+	"/user/AddSSHKey":               orm.AddSSHKey,
+	"/user/GetSSHKeysByFingerprint": orm.GetSSHKeysByFingerprint,
+	"/user/ListSSHKeys":             orm.ListSSHKeys,
+	"/user/DeleteSSHKey":            orm.DeleteSSHKey,
+
 	"/ufile/OnUserFileUploadFinished": orm.OnUserFileUploadFinished,
 	"/ufile/QueryUserFileMetas":       orm.QueryUserFileMetas,
 	"/ufile/DeleteUserFile":           orm.DeleteUserFile,
 	"/ufile/RenameFileName":           orm.RenameFileName,
 	"/ufile/QueryUserFileMeta":        orm.QueryUserFileMeta,
 	"/ufile/UserFileUploaded":         orm.IsUserFileUploaded,
+	"/ufile/QueryUserFileByName":      orm.QueryUserFileByName,
+	"/ufile/ListUserFilesByPrefix":    orm.ListUserFilesByPrefix,
+	"/ufile/SetUserFileETag":          orm.SetUserFileETag,
+	"/ufile/DeleteUserFileByName":     orm.DeleteUserFileByName,
+	"/ufile/ListUserFolders":          orm.ListUserFolders,
+	"/ufile/DeleteUserFilesByPrefix":  orm.DeleteUserFilesByPrefix,
+	"/ufile/RenameUserFileByName":     orm.RenameUserFileByName,
+	"/ufile/RenameUserFilesByPrefix":  orm.RenameUserFilesByPrefix,
+	"/ufile/GetUserFileUsage":         orm.GetUserFileUsage,
+	"/ufile/RestoreUserFile":          orm.RestoreUserFile,
+	"/ufile/AppendUserFileEvent":      orm.AppendUserFileEvent,
+	"/ufile/ListUserFileEvents":       orm.ListUserFileEvents,
+	"/ufile/GetUserFileEventCursor":   orm.GetUserFileEventCursor,
+	"/ufile/AppendFileTransferEvents": orm.AppendFileTransferEvents,
+	"/ufile/ListFileEvents":           orm.ListFileEvents,
+
+	"/webhook/CreateWebhook":         orm.CreateWebhook,
+	"/webhook/ListWebhooks":          orm.ListWebhooks,
+	"/webhook/DeleteWebhook":         orm.DeleteWebhook,
+	"/webhook/ListActiveWebhooks":    orm.ListActiveWebhooks,
+	"/webhook/AdvanceWebhookCursor":  orm.AdvanceWebhookCursor,
+	"/webhook/CreateWebhookDelivery": orm.CreateWebhookDelivery,
+	"/webhook/UpdateWebhookDelivery": orm.UpdateWebhookDelivery,
+	"/webhook/GetWebhookDelivery":    orm.GetWebhookDelivery,
+	"/webhook/ListWebhookDeliveries": orm.ListWebhookDeliveries,
+
+	"/job/CreateJob":   orm.CreateJob,
+	"/job/GetJob":      orm.GetJob,
+	"/job/ListJobs":    orm.ListJobs,
+	"/job/ListDueJobs": orm.ListDueJobs,
+	"/job/TransitJob":  orm.TransitJob,
 }
 
 func FuncCall(name string, params ...interface{}) (result []reflect.Value, err error) {
@@ -0,0 +1,130 @@
+package orm
+
+import (
+	"database/sql"
+	"log"
+
+	mydb "github.com/cloud/service/dbproxy/conn"
+)
+
+// CreateAccessKey : 为用户新增一对S3访问密钥
+func CreateAccessKey(username, accessKey, secretKey string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"insert into tbl_user_access_key (`user_name`,`access_key`,`secret_key`,`status`) " +
+			"values (?,?,?,1)")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(username, accessKey, secretKey)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// GetAccessKey : 根据AccessKey查询密钥及所属用户
+func GetAccessKey(accessKey string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select user_name,access_key,secret_key,create_at,status from tbl_user_access_key " +
+			"where access_key=? and status=1 limit 1")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	key := TableAccessKey{}
+	err = stmt.QueryRow(accessKey).Scan(
+		&key.UserName, &key.AccessKey, &key.SecretKey, &key.CreateAt, &key.Status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// 查不到对应记录， 返回参数及错误均为nil
+			res.Suc = true
+			res.Data = nil
+			return
+		}
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	res.Data = key
+	return
+}
+
+// ListAccessKeys : 查询用户的全部有效密钥(不返回SecretKey)
+func ListAccessKeys(username string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select user_name,access_key,create_at,status from tbl_user_access_key " +
+			"where user_name=? and status=1 order by id")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(username)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	keys := []TableAccessKey{}
+	for rows.Next() {
+		key := TableAccessKey{}
+		err = rows.Scan(&key.UserName, &key.AccessKey, &key.CreateAt, &key.Status)
+		if err != nil {
+			log.Println(err.Error())
+			break
+		}
+		keys = append(keys, key)
+	}
+	res.Suc = true
+	res.Data = keys
+	return
+}
+
+// DeleteAccessKey : 删除用户密钥(标记删除)
+func DeleteAccessKey(username, accessKey string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"update tbl_user_access_key set status=2 where user_name=? and access_key=? limit 1")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	ret, err := stmt.Exec(username, accessKey)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	if rowsAffected, err := ret.RowsAffected(); nil == err && rowsAffected > 0 {
+		res.Suc = true
+		return
+	}
+	res.Suc = false
+	res.Msg = "密钥不存在"
+	return
+}
@@ -26,10 +26,190 @@ type TableUserFile struct {
 	FileHash    string
 	FileName    string
 	FileSize    int64
+	ETag        string
 	UploadAt    string
 	LastUpdated string
 }
 
+// TableAccessKey : 用户S3访问密钥表结构体
+type TableAccessKey struct {
+	UserName  string
+	AccessKey string
+	SecretKey string
+	CreateAt  string
+	Status    int
+}
+
+// TableSSHKey : 用户SSH公钥表结构体
+type TableSSHKey struct {
+	UserName    string
+	KeyName     string
+	Fingerprint string
+	PublicKey   string
+	CreateAt    string
+	Status      int
+}
+
+// TableUserFolder : 用户顶层目录(S3 bucket)
+type TableUserFolder struct {
+	FolderName string
+	CreateAt   string
+}
+
+// TableUserUsage : 用户文件的数量及占用空间
+type TableUserUsage struct {
+	FileCount int64
+	TotalSize int64
+}
+
+// TableUserFileEvent : 用户文件变更事件, ID即变更游标
+type TableUserFileEvent struct {
+	ID       int64
+	UserName string
+	Event    string
+	FileName string
+	OldName  string
+	FileHash string
+	FileSize int64
+	CreateAt string
+}
+
+// TableWebhook : 用户webhook表结构体, UserName为空表示全局webhook
+type TableWebhook struct {
+	ID       int64
+	UserName string
+	URL      string
+	Secret   string
+	Events   string
+	Cursor   int64
+	CreateAt string
+	Status   int
+}
+
+// TableWebhookDelivery : webhook投递记录, URL及Secret来自所属webhook
+type TableWebhookDelivery struct {
+	ID           int64
+	HookID       int64
+	EventID      int64
+	UserName     string
+	Event        string
+	Payload      string
+	Status       int
+	Attempts     int
+	ResponseCode int
+	LastError    string
+	NextRetryAt  int64
+	CreateAt     string
+	UpdateAt     string
+	URL          string
+	Secret       string
+}
+
+// TableJob : 异步任务表结构体, NextRunAt为unix秒
+type TableJob struct {
+	ID          int64
+	Type        string
+	IdemKey     string
+	Payload     string
+	Priority    int
+	Status      int
+	Attempts    int
+	MaxAttempts int
+	LastError   string
+	NextRunAt   int64
+	CreateAt    string
+	UpdateAt    string
+	FinishAt    string
+}
+
+// TableFileLocation : 文件在某个存储中的位置(副本)
+type TableFileLocation struct {
+	ID         int64
+	FileHash   string
+	StoreType  int
+	Location   string
+	Status     int
+	VerifiedAt string
+	CreateAt   string
+	UpdateAt   string
+}
+
+// TableFileTier : 文件的存储层级及访问情况
+type TableFileTier struct {
+	FileHash    string
+	Tier        int
+	AccessCount int64
+	// LastAccessAt : 最后访问时间(unix秒), 没有访问过时为上传时间
+	LastAccessAt int64
+}
+
+// TableFileScrub : 文件在某个位置最近一次完整性检查的结果
+type TableFileScrub struct {
+	FileHash  string
+	StoreType int
+	Location  string
+	Result    int
+	// Checksum : 读取到的内容的sha1
+	Checksum string
+	// CRC64 : 内容一致时的CRC64(ECMA)
+	CRC64   string
+	ScrubAt string
+}
+
+// TableFileKey : 文件在某个存储中的位置的数据密钥
+type TableFileKey struct {
+	FileHash  string
+	StoreType int
+	KeyID     string
+	// WrappedKey : 主密钥加密后的数据密钥(base64)
+	WrappedKey string
+	ChunkSize  int
+}
+
+// TableFileCompression : 文件在某个存储中的位置的压缩信息
+type TableFileCompression struct {
+	FileHash    string
+	StoreType   int
+	Codec       string
+	Level       int
+	FrameSize   int
+	LogicalSize int64
+	StoredSize  int64
+}
+
+// TableCompressionUsage : 存储中已压缩的文件数、原始长度及压缩后的长度
+type TableCompressionUsage struct {
+	StoreType   int
+	FileCount   int64
+	LogicalSize int64
+	StoredSize  int64
+}
+
+// TableVaultKeyPair : 用户的加密目录密钥对, 私钥由口令派生的密钥加密
+type TableVaultKeyPair struct {
+	UserName  string
+	PublicKey string
+	SecretKey string
+	KDFSalt   string
+	KDFParams string
+}
+
+// TableVault : 用户可访问的加密目录, WrappedKey为以该用户公钥加密的目录密钥
+type TableVault struct {
+	Owner      string
+	Folder     string
+	UserName   string
+	WrappedKey string
+	CreateAt   string
+}
+
+// TableTierUsage : 存储层级的文件数及占用空间
+type TableTierUsage struct {
+	Tier      int
+	FileCount int64
+	TotalSize int64
+}
+
 // ExecResult: sql函数执行的结果
 type ExecResult struct {
 	Suc  bool        `json:"suc"`
@@ -145,3 +145,44 @@ func UpdateFileLocation(filehash string, fileaddr string) (res ExecResult) {
 		return
 	}
 }
+
+// ReplaceFileLocation : 在一个事务中将文件记录为oldAddr的位置改为newAddr(如本地文件迁移到分级路径):
+// 文件表中的存储地址、副本位置表及完整性检查表中的位置. 已不是oldAddr的记录(如已转移到其他位置)不更新
+func ReplaceFileLocation(filehash, oldAddr, newAddr string) (res ExecResult) {
+	tx, err := mydb.DBConn().Begin()
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer tx.Rollback()
+
+	var updated int64
+	for _, query := range []string{
+		"update tbl_file set `file_addr`=? where `file_sha1`=? and `file_addr`=?",
+		"update tbl_file_location set `location`=? where `file_sha1`=? and `location`=?",
+		"update tbl_file_scrub set `location`=? where `file_sha1`=? and `location`=?",
+	} {
+		ret, err := tx.Exec(query, newAddr, filehash, oldAddr)
+		if err != nil {
+			log.Println(err.Error())
+			res.Suc = false
+			res.Msg = err.Error()
+			return
+		}
+		rf, _ := ret.RowsAffected()
+		updated += rf
+	}
+	if err = tx.Commit(); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	res.Data = map[string]bool{
+		"updated": updated > 0,
+	}
+	return
+}
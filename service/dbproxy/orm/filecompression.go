@@ -0,0 +1,133 @@
+package orm
+
+import (
+	"log"
+
+	mydb "github.com/cloud/service/dbproxy/conn"
+)
+
+// GetFileCompression : 查询文件在某个存储中的位置的压缩信息, 未压缩(没有记录)时Data为nil
+func GetFileCompression(filehash string, storeType int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select `file_sha1`,`store_type`,`codec`,`level`,`frame_size`,`logical_size`,`stored_size` " +
+			"from tbl_file_compression where file_sha1=? and store_type=? limit 1")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(filehash, storeType)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	res.Suc = true
+	if rows.Next() {
+		info := TableFileCompression{}
+		err = rows.Scan(&info.FileHash, &info.StoreType, &info.Codec, &info.Level,
+			&info.FrameSize, &info.LogicalSize, &info.StoredSize)
+		if err != nil {
+			log.Println(err.Error())
+			res.Suc = false
+			res.Msg = err.Error()
+			return
+		}
+		res.Data = info
+	}
+	return
+}
+
+// SaveFileCompression : 保存压缩信息, 已有记录时覆盖(位置被重新写入)
+func SaveFileCompression(filehash string, storeType int64, codec string, level, frameSize,
+	logicalSize, storedSize int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"insert into tbl_file_compression (`file_sha1`,`store_type`,`codec`,`level`,`frame_size`," +
+			"`logical_size`,`stored_size`) values (?,?,?,?,?,?,?) on duplicate key update " +
+			"`codec`=values(`codec`),`level`=values(`level`),`frame_size`=values(`frame_size`)," +
+			"`logical_size`=values(`logical_size`),`stored_size`=values(`stored_size`)")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(filehash, storeType, codec, level, frameSize, logicalSize, storedSize)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// RemoveFileCompression : 删除文件在某个存储中的位置的压缩信息
+func RemoveFileCompression(filehash string, storeType int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"delete from tbl_file_compression where file_sha1=? and store_type=?")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.Exec(filehash, storeType); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// GetCompressionUsage : 各存储中已压缩的文件数、原始长度及压缩后的长度
+func GetCompressionUsage() (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select store_type,count(*),ifnull(sum(logical_size),0),ifnull(sum(stored_size),0) " +
+			"from tbl_file_compression group by store_type order by store_type")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query()
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	usages := []TableCompressionUsage{}
+	for rows.Next() {
+		usage := TableCompressionUsage{}
+		err = rows.Scan(&usage.StoreType, &usage.FileCount, &usage.LogicalSize, &usage.StoredSize)
+		if err != nil {
+			log.Println(err.Error())
+			res.Suc = false
+			res.Msg = err.Error()
+			return
+		}
+		usages = append(usages, usage)
+	}
+	res.Suc = true
+	res.Data = usages
+	return
+}
@@ -0,0 +1,232 @@
+package orm
+
+import (
+	"database/sql"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/cloud/common"
+	mydb "github.com/cloud/service/dbproxy/conn"
+)
+
+// beginUserFileTx : 开始修改用户文件的事务, 并锁定用户记录直到事务结束.
+// 同一用户的变更因此串行提交, 事件ID的分配顺序与提交顺序一致,
+// 按游标读取时不会因并发事务晚提交较小的ID而漏读事件
+func beginUserFileTx(username string) (*sql.Tx, error) {
+	tx, err := mydb.DBConn().Begin()
+	if err != nil {
+		return nil, err
+	}
+	var id int64
+	err = tx.QueryRow("select id from tbl_user where user_name=? limit 1 for update", username).Scan(&id)
+	if err != nil && err != sql.ErrNoRows {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// UserFileEventNotify : 用户有新的文件事件提交后调用(如通知webhook服务), 为空时不通知
+var UserFileEventNotify func(username string)
+
+// commitUserFileTx : 提交修改用户文件的事务, 成功后通知新事件
+func commitUserFileTx(tx *sql.Tx, username string) error {
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if UserFileEventNotify != nil {
+		UserFileEventNotify(username)
+	}
+	return nil
+}
+
+// addUserFileEvent : 在修改用户文件的事务中追加一条变更事件
+func addUserFileEvent(tx *sql.Tx, username, event, filename, oldName, filehash string, filesize int64) error {
+	_, err := tx.Exec("insert into tbl_user_file_event (`user_name`,`event`,`file_name`,"+
+		"`old_name`,`file_sha1`,`file_size`) values (?,?,?,?,?,?)",
+		username, event, filename, oldName, filehash, filesize)
+	return err
+}
+
+// renameEvent : 父目录不变时为重命名, 否则为移动. 目录以"/"结尾
+func renameEvent(oldName, newName string) string {
+	oldDir := path.Dir(strings.TrimSuffix(oldName, "/"))
+	newDir := path.Dir(strings.TrimSuffix(newName, "/"))
+	if oldDir == newDir {
+		return common.FileEventRename
+	}
+	return common.FileEventMove
+}
+
+// AppendUserFileEvent : 记录不修改文件表的事件(如生成分享链接)
+func AppendUserFileEvent(username, event, filename, filehash string, filesize int64) (res ExecResult) {
+	tx, err := beginUserFileTx(username)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer tx.Rollback()
+
+	if err = addUserFileEvent(tx, username, event, filename, "", filehash, filesize); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	if err = commitUserFileTx(tx, username); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// AppendFileTransferEvents : 文件内容转移到Ceph/OSS后, 为持有该文件的每个用户记录事件
+func AppendFileTransferEvents(filehash string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select user_name,file_name,file_size from tbl_user_file where file_sha1=? and status=1")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(filehash)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	owners := []TableUserFile{}
+	for rows.Next() {
+		ufile := TableUserFile{FileHash: filehash}
+		if err = rows.Scan(&ufile.UserName, &ufile.FileName, &ufile.FileSize); err != nil {
+			log.Println(err.Error())
+			break
+		}
+		owners = append(owners, ufile)
+	}
+	rows.Close()
+
+	// 每个用户的事件在各自的事务中按顺序写入
+	for _, ufile := range owners {
+		evRes := AppendUserFileEvent(ufile.UserName, common.FileEventTransfer,
+			ufile.FileName, ufile.FileHash, ufile.FileSize)
+		if !evRes.Suc {
+			return evRes
+		}
+	}
+	res.Suc = true
+	return
+}
+
+// ListUserFileEvents : 按顺序查询游标cursor之后的用户文件变更事件
+func ListUserFileEvents(username string, cursor, limit int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select id,user_name,event,file_name,old_name,file_sha1,file_size,create_at " +
+			"from tbl_user_file_event where user_name=? and id>? order by id limit ?")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(username, cursor, limit)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	events := []TableUserFileEvent{}
+	for rows.Next() {
+		ev := TableUserFileEvent{}
+		err = rows.Scan(&ev.ID, &ev.UserName, &ev.Event, &ev.FileName, &ev.OldName,
+			&ev.FileHash, &ev.FileSize, &ev.CreateAt)
+		if err != nil {
+			log.Println(err.Error())
+			break
+		}
+		events = append(events, ev)
+	}
+	res.Suc = true
+	res.Data = events
+	return
+}
+
+// ListFileEvents : 按顺序查询游标cursor之后、before(unix秒)之前所有用户的文件变更事件, 用于全局webhook.
+// 不同用户的事件并发提交, ID较小的事件可能较晚可见, 只读取已提交一段时间的事件以免游标越过它们
+func ListFileEvents(cursor, before, limit int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select id,user_name,event,file_name,old_name,file_sha1,file_size,create_at " +
+			"from tbl_user_file_event where id>? and create_at<from_unixtime(?) order by id limit ?")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(cursor, before, limit)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	events := []TableUserFileEvent{}
+	for rows.Next() {
+		ev := TableUserFileEvent{}
+		err = rows.Scan(&ev.ID, &ev.UserName, &ev.Event, &ev.FileName, &ev.OldName,
+			&ev.FileHash, &ev.FileSize, &ev.CreateAt)
+		if err != nil {
+			log.Println(err.Error())
+			break
+		}
+		events = append(events, ev)
+	}
+	res.Suc = true
+	res.Data = events
+	return
+}
+
+// GetUserFileEventCursor : 查询用户最新的变更游标, 没有任何事件时为0
+func GetUserFileEventCursor(username string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select ifnull(max(id),0) from tbl_user_file_event where user_name=?")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	var cursor int64
+	if err = stmt.QueryRow(username).Scan(&cursor); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	res.Data = map[string]int64{
+		"cursor": cursor,
+	}
+	return
+}
@@ -0,0 +1,146 @@
+package orm
+
+import (
+	"log"
+
+	mydb "github.com/cloud/service/dbproxy/conn"
+)
+
+// GetFileKey : 查询文件在某个存储中的位置的数据密钥, 没有记录时Data为nil
+func GetFileKey(filehash string, storeType int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select `file_sha1`,`store_type`,`key_id`,`wrapped_key`,`chunk_size` from tbl_file_key " +
+			"where file_sha1=? and store_type=? limit 1")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(filehash, storeType)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	res.Suc = true
+	if rows.Next() {
+		key := TableFileKey{}
+		err = rows.Scan(&key.FileHash, &key.StoreType, &key.KeyID, &key.WrappedKey, &key.ChunkSize)
+		if err != nil {
+			log.Println(err.Error())
+			res.Suc = false
+			res.Msg = err.Error()
+			return
+		}
+		res.Data = key
+	}
+	return
+}
+
+// CreateFileKey : 保存数据密钥, 已有记录时不覆盖, 返回保存的记录(同GetFileKey)
+func CreateFileKey(filehash string, storeType int64, keyID, wrappedKey string, chunkSize int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"insert ignore into tbl_file_key (`file_sha1`,`store_type`,`key_id`,`wrapped_key`,`chunk_size`) " +
+			"values (?,?,?,?,?)")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.Exec(filehash, storeType, keyID, wrappedKey, chunkSize); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	return GetFileKey(filehash, storeType)
+}
+
+// RewrapFileKey : 主密钥仍为oldKeyID时更新为重新加密的数据密钥
+func RewrapFileKey(filehash string, storeType int64, oldKeyID, keyID, wrappedKey string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"update tbl_file_key set `key_id`=?,`wrapped_key`=? " +
+			"where file_sha1=? and store_type=? and key_id=?")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.Exec(keyID, wrappedKey, filehash, storeType, oldKeyID); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// RemoveFileKey : 删除文件在某个存储中的位置的数据密钥
+func RemoveFileKey(filehash string, storeType int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"delete from tbl_file_key where file_sha1=? and store_type=?")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.Exec(filehash, storeType); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// ListStaleFileKeys : 查询主密钥不是keyID的数据密钥, 只返回文件hash及存储类型
+func ListStaleFileKeys(keyID string, limit int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select `file_sha1`,`store_type` from tbl_file_key where key_id<>? limit ?")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(keyID, limit)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	keys := []TableFileKey{}
+	for rows.Next() {
+		key := TableFileKey{}
+		if err = rows.Scan(&key.FileHash, &key.StoreType); err != nil {
+			log.Println(err.Error())
+			break
+		}
+		keys = append(keys, key)
+	}
+	res.Suc = true
+	res.Data = keys
+	return
+}
@@ -0,0 +1,115 @@
+package orm
+
+import (
+	"log"
+
+	mydb "github.com/cloud/service/dbproxy/conn"
+)
+
+// AddFileLocation : 记录文件在存储中的位置(副本已写入且可用), 同一存储已有记录时更新位置
+func AddFileLocation(filehash string, storeType int64, location string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"insert into tbl_file_location (`file_sha1`,`store_type`,`location`,`status`,`verified_at`) " +
+			"values (?,?,?,1,now()) on duplicate key update `location`=values(`location`)," +
+			"`status`=1,`verified_at`=now()")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.Exec(filehash, storeType, location); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// ListFileLocations : 查询文件的全部存储位置
+func ListFileLocations(filehash string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select id,file_sha1,store_type,location,status,verified_at,create_at,update_at " +
+			"from tbl_file_location " +
+			"where file_sha1=? order by id")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(filehash)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	locations := []TableFileLocation{}
+	for rows.Next() {
+		loc := TableFileLocation{}
+		err = rows.Scan(&loc.ID, &loc.FileHash, &loc.StoreType, &loc.Location,
+			&loc.Status, &loc.VerifiedAt, &loc.CreateAt, &loc.UpdateAt)
+		if err != nil {
+			log.Println(err.Error())
+			break
+		}
+		locations = append(locations, loc)
+	}
+	res.Suc = true
+	res.Data = locations
+	return
+}
+
+// SetFileLocationStatus : 更新副本状态, 状态为可用(1)时同时更新确认时间
+func SetFileLocationStatus(filehash string, storeType int64, status int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"update tbl_file_location set `status`=?," +
+			"`verified_at`=if(?=1,now(),`verified_at`) where file_sha1=? and store_type=?")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.Exec(status, status, filehash, storeType); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// RemoveFileLocation : 删除文件在某个存储中的位置记录
+func RemoveFileLocation(filehash string, storeType int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"delete from tbl_file_location where file_sha1=? and store_type=?")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.Exec(filehash, storeType); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
@@ -0,0 +1,109 @@
+package orm
+
+import (
+	"log"
+
+	mydb "github.com/cloud/service/dbproxy/conn"
+)
+
+// RecordFileScrub : 记录文件在某个存储中的位置最近一次完整性检查的结果
+func RecordFileScrub(filehash string, storeType int64, location string, result int64,
+	checksum, crc64 string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"insert into tbl_file_scrub (`file_sha1`,`store_type`,`location`,`result`,`checksum`,`crc64`,`scrub_at`) " +
+			"values (?,?,?,?,?,?,now()) on duplicate key update `location`=values(`location`)," +
+			"`result`=values(`result`),`checksum`=values(`checksum`),`crc64`=values(`crc64`),`scrub_at`=now()")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.Exec(filehash, storeType, location, result, checksum, crc64); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// ListFileScrubs : 查询文件各位置最近一次完整性检查的结果
+func ListFileScrubs(filehash string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select `file_sha1`,`store_type`,`location`,`result`,`checksum`,`crc64`,`scrub_at` " +
+			"from tbl_file_scrub where file_sha1=? order by store_type")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(filehash)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	scrubs := []TableFileScrub{}
+	for rows.Next() {
+		scrub := TableFileScrub{}
+		err = rows.Scan(&scrub.FileHash, &scrub.StoreType, &scrub.Location, &scrub.Result,
+			&scrub.Checksum, &scrub.CRC64, &scrub.ScrubAt)
+		if err != nil {
+			log.Println(err.Error())
+			break
+		}
+		scrubs = append(scrubs, scrub)
+	}
+	res.Suc = true
+	res.Data = scrubs
+	return
+}
+
+// ListScrubCandidates : 查询从未检查过或最早的检查时间(unix秒)早于before的文件hash,
+// 从未检查过的在前
+func ListScrubCandidates(before, limit int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select f.file_sha1 from tbl_file f left join " +
+			"(select file_sha1,min(scrub_at) as scrub_at from tbl_file_scrub group by file_sha1) s " +
+			"on s.file_sha1=f.file_sha1 where f.status=1 and f.file_size>0 " +
+			"and (s.scrub_at is null or s.scrub_at<from_unixtime(?)) order by s.scrub_at limit ?")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(before, limit)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	hashes := []string{}
+	for rows.Next() {
+		var filehash string
+		if err = rows.Scan(&filehash); err != nil {
+			log.Println(err.Error())
+			break
+		}
+		hashes = append(hashes, filehash)
+	}
+	res.Suc = true
+	res.Data = hashes
+	return
+}
@@ -0,0 +1,167 @@
+package orm
+
+import (
+	"log"
+
+	mydb "github.com/cloud/service/dbproxy/conn"
+)
+
+// RecordFileAccess : 记录文件被访问一次, 返回文件的层级及访问情况(同GetFileTier)
+func RecordFileAccess(filehash string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"insert into tbl_file_tier (`file_sha1`,`access_count`,`last_access_at`) values (?,1,now()) " +
+			"on duplicate key update `access_count`=`access_count`+1,`last_access_at`=now()")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.Exec(filehash); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	return GetFileTier(filehash)
+}
+
+// GetFileTier : 查询文件的层级及访问情况, 没有记录的文件为热存储, 最后访问时间为上传时间.
+// 文件不存在时Data为nil
+func GetFileTier(filehash string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select f.file_sha1,ifnull(t.tier,1),ifnull(t.access_count,0)," +
+			"unix_timestamp(ifnull(t.last_access_at,f.create_at)) from tbl_file f " +
+			"left join tbl_file_tier t on t.file_sha1=f.file_sha1 " +
+			"where f.file_sha1=? and f.status=1 limit 1")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(filehash)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	res.Suc = true
+	if rows.Next() {
+		tier := TableFileTier{}
+		err = rows.Scan(&tier.FileHash, &tier.Tier, &tier.AccessCount, &tier.LastAccessAt)
+		if err != nil {
+			log.Println(err.Error())
+			res.Suc = false
+			res.Msg = err.Error()
+			return
+		}
+		res.Data = tier
+	}
+	return
+}
+
+// SetFileTier : 更新文件的层级
+func SetFileTier(filehash string, tier int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"insert into tbl_file_tier (`file_sha1`,`tier`) values (?,?) " +
+			"on duplicate key update `tier`=values(`tier`)")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.Exec(filehash, tier); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// ListTierCandidates : 查询层级为tier且最后访问时间(unix秒)早于before的文件hash, 用于降级
+func ListTierCandidates(tier, before, limit int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select f.file_sha1 from tbl_file f left join tbl_file_tier t on t.file_sha1=f.file_sha1 " +
+			"where f.status=1 and f.file_size>0 and ifnull(t.tier,1)=? " +
+			"and ifnull(t.last_access_at,f.create_at)<from_unixtime(?) " +
+			"order by ifnull(t.last_access_at,f.create_at) limit ?")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(tier, before, limit)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	hashes := []string{}
+	for rows.Next() {
+		var filehash string
+		if err = rows.Scan(&filehash); err != nil {
+			log.Println(err.Error())
+			break
+		}
+		hashes = append(hashes, filehash)
+	}
+	res.Suc = true
+	res.Data = hashes
+	return
+}
+
+// GetTierUsage : 各层级的文件数及占用空间
+func GetTierUsage() (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select ifnull(t.tier,1) as tier,count(*),ifnull(sum(f.file_size),0) from tbl_file f " +
+			"left join tbl_file_tier t on t.file_sha1=f.file_sha1 where f.status=1 " +
+			"group by tier order by tier")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query()
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	usages := []TableTierUsage{}
+	for rows.Next() {
+		usage := TableTierUsage{}
+		if err = rows.Scan(&usage.Tier, &usage.FileCount, &usage.TotalSize); err != nil {
+			log.Println(err.Error())
+			break
+		}
+		usages = append(usages, usage)
+	}
+	res.Suc = true
+	res.Data = usages
+	return
+}
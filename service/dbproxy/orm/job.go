@@ -0,0 +1,180 @@
+package orm
+
+import (
+	"database/sql"
+	"log"
+
+	mydb "github.com/cloud/service/dbproxy/conn"
+)
+
+// jobColumns : 查询任务时的字段, 与scanJob对应
+const jobColumns = "id,job_type,ifnull(idem_key,''),payload,priority,status,attempts,max_attempts," +
+	"last_error,unix_timestamp(next_run_at),create_at,update_at,ifnull(finish_at,'')"
+
+// rowScanner : *sql.Row及*sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanJob : 读取一行任务记录
+func scanJob(row rowScanner) (TableJob, error) {
+	job := TableJob{}
+	err := row.Scan(&job.ID, &job.Type, &job.IdemKey, &job.Payload, &job.Priority, &job.Status,
+		&job.Attempts, &job.MaxAttempts, &job.LastError, &job.NextRunAt,
+		&job.CreateAt, &job.UpdateAt, &job.FinishAt)
+	return job, err
+}
+
+// CreateJob : 创建待执行的任务, nextRunAt(unix秒)之前不会被重新入队.
+// idemKey不为空且同一类型下已有该键的任务时不再创建, Data中返回已有的任务且created为false;
+// 已有的任务已失败(status为3)时以新的参数重置为待执行(保留id), created为true
+func CreateJob(jobType, idemKey, payload string, priority, maxAttempts, nextRunAt int64) (res ExecResult) {
+	// 更新时按顺序赋值, status须最后更新; id=last_insert_id(id)使重置时LastInsertId返回已有任务的id
+	stmt, err := mydb.DBConn().Prepare(
+		"insert into tbl_job (`job_type`,`idem_key`,`payload`,`priority`,`status`," +
+			"`max_attempts`,`next_run_at`) values (?,nullif(?,''),?,?,0,?,from_unixtime(?)) " +
+			"on duplicate key update `id`=last_insert_id(`id`)," +
+			"`payload`=if(`status`=3,values(`payload`),`payload`)," +
+			"`priority`=if(`status`=3,values(`priority`),`priority`)," +
+			"`attempts`=if(`status`=3,0,`attempts`)," +
+			"`max_attempts`=if(`status`=3,values(`max_attempts`),`max_attempts`)," +
+			"`last_error`=if(`status`=3,'',`last_error`)," +
+			"`next_run_at`=if(`status`=3,values(`next_run_at`),`next_run_at`)," +
+			"`finish_at`=if(`status`=3,null,`finish_at`)," +
+			"`status`=if(`status`=3,0,`status`)")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	ret, err := stmt.Exec(jobType, idemKey, payload, priority, maxAttempts, nextRunAt)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+
+	var row *sql.Row
+	rf, _ := ret.RowsAffected()
+	if rf > 0 {
+		id, _ := ret.LastInsertId()
+		row = mydb.DBConn().QueryRow("select "+jobColumns+" from tbl_job where id=?", id)
+	} else {
+		row = mydb.DBConn().QueryRow("select "+jobColumns+" from tbl_job where job_type=? and idem_key=?",
+			jobType, idemKey)
+	}
+	job, err := scanJob(row)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	res.Data = map[string]interface{}{
+		"job":     job,
+		"created": rf > 0,
+	}
+	return
+}
+
+// GetJob : 按ID查询任务
+func GetJob(id int64) (res ExecResult) {
+	job, err := scanJob(mydb.DBConn().QueryRow("select "+jobColumns+" from tbl_job where id=?", id))
+	if err == sql.ErrNoRows {
+		res.Suc = false
+		res.Msg = "任务不存在"
+		return
+	} else if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	res.Data = job
+	return
+}
+
+// queryJobs : 执行查询并读取全部任务记录
+func queryJobs(query string, args ...interface{}) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(query)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	jobs := []TableJob{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			log.Println(err.Error())
+			break
+		}
+		jobs = append(jobs, job)
+	}
+	res.Suc = true
+	res.Data = jobs
+	return
+}
+
+// ListJobs : 按ID倒序查询任务, jobType为空时查询全部类型, status小于0时查询全部状态
+func ListJobs(jobType string, status, limit int64) (res ExecResult) {
+	return queryJobs("select "+jobColumns+" from tbl_job where (job_type=? or ?='') "+
+		"and (status=? or ?<0) order by id desc limit ?",
+		jobType, jobType, status, status, limit)
+}
+
+// ListDueJobs : 查询到期的任务: 待执行且到了(重新)入队时间的, 及执行中但租约已过期的
+func ListDueJobs(now, limit int64) (res ExecResult) {
+	return queryJobs("select "+jobColumns+" from tbl_job where status in (0,1) "+
+		"and next_run_at<=from_unixtime(?) order by priority desc, next_run_at limit ?",
+		now, limit)
+}
+
+// TransitJob : 仅当任务仍处于fromStatus且next_run_at为fromNextRunAt时更新其状态,
+// 多个实例同时操作同一任务时只有一个成功. 转为执行中时执行次数加1, 转为成功或失败时记录完成时间
+func TransitJob(id, fromStatus, fromNextRunAt, toStatus, nextRunAt int64, lastError string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"update tbl_job set status=?,next_run_at=from_unixtime(?),last_error=?," +
+			"attempts=attempts+if(?=1,1,0),finish_at=if(? in (2,3),now(),null) " +
+			"where id=? and status=? and next_run_at=from_unixtime(?)")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	ret, err := stmt.Exec(toStatus, nextRunAt, lastError, toStatus, toStatus,
+		id, fromStatus, fromNextRunAt)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	rf, _ := ret.RowsAffected()
+	res.Suc = true
+	res.Data = map[string]bool{
+		"updated": rf > 0,
+	}
+	return
+}
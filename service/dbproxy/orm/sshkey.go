@@ -0,0 +1,135 @@
+package orm
+
+import (
+	"log"
+
+	mydb "github.com/cloud/service/dbproxy/conn"
+)
+
+// AddSSHKey : 为用户新增一个SSH公钥
+func AddSSHKey(username, keyName, fingerprint, publicKey string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"insert into tbl_user_ssh_key (`user_name`,`key_name`,`fingerprint`,`public_key`,`status`) " +
+			"values (?,?,?,?,1)")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(username, keyName, fingerprint, publicKey)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// GetSSHKeysByFingerprint : 根据指纹查询有效的公钥及所属用户
+func GetSSHKeysByFingerprint(fingerprint string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select user_name,key_name,fingerprint,public_key,create_at,status from tbl_user_ssh_key " +
+			"where fingerprint=? and status=1")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(fingerprint)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	keys := []TableSSHKey{}
+	for rows.Next() {
+		key := TableSSHKey{}
+		err = rows.Scan(&key.UserName, &key.KeyName, &key.Fingerprint,
+			&key.PublicKey, &key.CreateAt, &key.Status)
+		if err != nil {
+			log.Println(err.Error())
+			break
+		}
+		keys = append(keys, key)
+	}
+	res.Suc = true
+	res.Data = keys
+	return
+}
+
+// ListSSHKeys : 查询用户的全部有效公钥
+func ListSSHKeys(username string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select user_name,key_name,fingerprint,public_key,create_at,status from tbl_user_ssh_key " +
+			"where user_name=? and status=1 order by id")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(username)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	keys := []TableSSHKey{}
+	for rows.Next() {
+		key := TableSSHKey{}
+		err = rows.Scan(&key.UserName, &key.KeyName, &key.Fingerprint,
+			&key.PublicKey, &key.CreateAt, &key.Status)
+		if err != nil {
+			log.Println(err.Error())
+			break
+		}
+		keys = append(keys, key)
+	}
+	res.Suc = true
+	res.Data = keys
+	return
+}
+
+// DeleteSSHKey : 删除用户公钥(标记删除)
+func DeleteSSHKey(username, fingerprint string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"update tbl_user_ssh_key set status=2 where user_name=? and fingerprint=? and status=1")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	ret, err := stmt.Exec(username, fingerprint)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	if rowsAffected, err := ret.RowsAffected(); nil == err && rowsAffected > 0 {
+		res.Suc = true
+		return
+	}
+	res.Suc = false
+	res.Msg = "公钥不存在"
+	return
+}
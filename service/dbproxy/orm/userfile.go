@@ -1,32 +1,52 @@
 package orm
 
 import (
+	"database/sql"
 	"log"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/cloud/common"
 	mydb "github.com/cloud/service/dbproxy/conn"
 )
 
-// OnUserFileUploadFinished : 更新用户文件表
+// OnUserFileUploadFinished : 更新用户文件表, 同名文件则覆盖为新内容并清除原内容的ETag
 func OnUserFileUploadFinished(username, filehash, filename string, filesize int64) (res ExecResult) {
-	stmt, err := mydb.DBConn().Prepare(
-		"insert ignore into tbl_user_file (`user_name`,`file_sha1`,`file_name`," +
-			"`file_size`,`upload_at`,`status`) values (?,?,?,?,?,1)")
+	tx, err := beginUserFileTx(username)
 	if err != nil {
 		log.Println(err.Error())
 		res.Suc = false
 		res.Msg = err.Error()
 		return
 	}
-	defer stmt.Close()
+	defer tx.Rollback()
 
-	_, err = stmt.Exec(username, filehash, filename, filesize, time.Now())
+	_, err = tx.Exec(
+		"insert into tbl_user_file (`user_name`,`file_sha1`,`file_name`,"+
+			"`file_size`,`upload_at`,`status`) values (?,?,?,?,?,1) "+
+			"on duplicate key update `file_sha1`=values(`file_sha1`),"+
+			"`file_size`=values(`file_size`),`etag`='',`upload_at`=values(`upload_at`),`status`=1",
+		username, filehash, filename, filesize, time.Now())
 	if err != nil {
 		log.Println(err.Error())
 		res.Suc = false
 		res.Msg = err.Error()
 		return
 	}
+	err = addUserFileEvent(tx, username, common.FileEventUpload, filename, "", filehash, filesize)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	if err = commitUserFileTx(tx, username); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
 	res.Suc = true
 	return
 }
@@ -70,46 +90,101 @@ func QueryUserFileMetas(username string, limit int64) (res ExecResult) {
 
 // DeleteUserFile : 删除文件(标记删除)
 func DeleteUserFile(username, filehash string) (res ExecResult) {
-	stmt, err := mydb.DBConn().Prepare(
-		"update tbl_user_file set status=2 where user_name=? and file_sha1=? limit 1")
+	tx, err := beginUserFileTx(username)
 	if err != nil {
 		log.Println(err.Error())
 		res.Suc = false
 		res.Msg = err.Error()
 		return
 	}
-	defer stmt.Close()
+	defer tx.Rollback()
 
-	_, err = stmt.Exec(username, filehash)
+	var id, filesize int64
+	var filename string
+	err = tx.QueryRow("select id,file_name,file_size from tbl_user_file "+
+		"where user_name=? and file_sha1=? and status=1 limit 1", username, filehash).
+		Scan(&id, &filename, &filesize)
+	if err == sql.ErrNoRows {
+		res.Suc = true
+		return
+	} else if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	_, err = tx.Exec("update tbl_user_file set status=2 where id=?", id)
 	if err != nil {
 		log.Println(err.Error())
 		res.Suc = false
 		res.Msg = err.Error()
 		return
 	}
+	err = addUserFileEvent(tx, username, common.FileEventDelete, filename, "", filehash, filesize)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	if err = commitUserFileTx(tx, username); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
 	res.Suc = true
 	return
 }
 
 // RenameFileName : 文件重命名
 func RenameFileName(username, filehash, filename string) (res ExecResult) {
-	stmt, err := mydb.DBConn().Prepare(
-		"update tbl_user_file set file_name=? where user_name=? and file_sha1=? limit 1")
+	tx, err := beginUserFileTx(username)
 	if err != nil {
 		log.Println(err.Error())
 		res.Suc = false
 		res.Msg = err.Error()
 		return
 	}
-	defer stmt.Close()
+	defer tx.Rollback()
 
-	_, err = stmt.Exec(filename, username, filehash)
+	var id, filesize, status int64
+	var oldName string
+	err = tx.QueryRow("select id,file_name,file_size,status from tbl_user_file "+
+		"where user_name=? and file_sha1=? limit 1", username, filehash).
+		Scan(&id, &oldName, &filesize, &status)
+	if err == sql.ErrNoRows {
+		res.Suc = true
+		return
+	} else if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	_, err = tx.Exec("update tbl_user_file set file_name=? where id=?", filename, id)
 	if err != nil {
 		log.Println(err.Error())
 		res.Suc = false
 		res.Msg = err.Error()
 		return
 	}
+	// 已删除的文件改名对用户不可见, 不记录事件
+	if status == 1 && oldName != filename {
+		err = addUserFileEvent(tx, username, renameEvent(oldName, filename), filename, oldName, filehash, filesize)
+		if err != nil {
+			log.Println(err.Error())
+			res.Suc = false
+			res.Msg = err.Error()
+			return
+		}
+	}
+	if err = commitUserFileTx(tx, username); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
 	res.Suc = true
 	return
 }
@@ -173,3 +248,417 @@ func IsUserFileUploaded(username string, filehash string) (res ExecResult) {
 	}
 	return
 }
+
+// QueryUserFileByName : 按文件名获取用户单个文件信息
+func QueryUserFileByName(username string, filename string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select user_name,file_sha1,file_name,file_size,etag,upload_at," +
+			"last_update from tbl_user_file where user_name=? and file_name=? and status=1 limit 1")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	ufile := TableUserFile{}
+	err = stmt.QueryRow(username, filename).Scan(&ufile.UserName, &ufile.FileHash,
+		&ufile.FileName, &ufile.FileSize, &ufile.ETag, &ufile.UploadAt, &ufile.LastUpdated)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// 查不到对应记录， 返回参数及错误均为nil
+			res.Suc = true
+			res.Data = nil
+			return
+		}
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	res.Data = ufile
+	return
+}
+
+// ListUserFilesByPrefix : 按文件名字典序分页查询指定前缀的用户文件, marker为上一页最后一个文件名
+func ListUserFilesByPrefix(username, prefix, marker string, limit int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select user_name,file_sha1,file_name,file_size,etag,upload_at,last_update from tbl_user_file " +
+			"where user_name=? and status=1 and file_name like ? and binary file_name > ? " +
+			"order by binary file_name limit ?")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(username, escapeLike(prefix)+"%", marker, limit)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	userFiles := []TableUserFile{}
+	for rows.Next() {
+		ufile := TableUserFile{}
+		err = rows.Scan(&ufile.UserName, &ufile.FileHash, &ufile.FileName,
+			&ufile.FileSize, &ufile.ETag, &ufile.UploadAt, &ufile.LastUpdated)
+		if err != nil {
+			log.Println(err.Error())
+			break
+		}
+		userFiles = append(userFiles, ufile)
+	}
+	res.Suc = true
+	res.Data = userFiles
+	return
+}
+
+// SetUserFileETag : 记录S3对象的ETag. 只在文件内容仍为filehash时更新, 避免覆盖并发上传的新内容
+func SetUserFileETag(username, filename, filehash, etag string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"update tbl_user_file set etag=? where user_name=? and file_name=? and file_sha1=? and status=1")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	ret, err := stmt.Exec(etag, username, filename, filehash)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	rf, _ := ret.RowsAffected()
+	res.Suc = true
+	res.Data = map[string]bool{"updated": rf > 0}
+	return
+}
+
+// DeleteUserFileByName : 按文件名删除用户文件(标记删除)
+func DeleteUserFileByName(username, filename string) (res ExecResult) {
+	tx, err := beginUserFileTx(username)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer tx.Rollback()
+
+	var id, filesize int64
+	var filehash string
+	err = tx.QueryRow("select id,file_sha1,file_size from tbl_user_file "+
+		"where user_name=? and file_name=? and status=1 limit 1", username, filename).
+		Scan(&id, &filehash, &filesize)
+	if err == sql.ErrNoRows {
+		res.Suc = true
+		return
+	} else if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	_, err = tx.Exec("update tbl_user_file set status=2 where id=?", id)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	err = addUserFileEvent(tx, username, common.FileEventDelete, filename, "", filehash, filesize)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	if err = commitUserFileTx(tx, username); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// DeleteUserFilesByPrefix : 删除文件名以prefix开头的全部用户文件(标记删除), 用于删除目录
+func DeleteUserFilesByPrefix(username, prefix string) (res ExecResult) {
+	tx, err := beginUserFileTx(username)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer tx.Rollback()
+
+	// 每个被删除的文件记录一条事件
+	_, err = tx.Exec("insert into tbl_user_file_event (`user_name`,`event`,`file_name`,`file_sha1`,`file_size`) "+
+		"select user_name,?,file_name,file_sha1,file_size from tbl_user_file "+
+		"where user_name=? and status=1 and binary file_name like ? order by binary file_name",
+		common.FileEventDelete, username, escapeLike(prefix)+"%")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	_, err = tx.Exec("update tbl_user_file set status=2 where user_name=? and status=1 and binary file_name like ?",
+		username, escapeLike(prefix)+"%")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	if err = commitUserFileTx(tx, username); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// RenameUserFileByName : 按文件名重命名用户文件.
+// 标记删除的记录仍占用唯一索引, 需先清除目标文件名上已删除的记录
+func RenameUserFileByName(username, oldName, newName string) (res ExecResult) {
+	tx, err := beginUserFileTx(username)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("delete from tbl_user_file where user_name=? and file_name=? and status<>1",
+		username, newName)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	var id, filesize int64
+	var filehash, storedName string
+	err = tx.QueryRow("select id,file_name,file_sha1,file_size from tbl_user_file "+
+		"where user_name=? and file_name=? and status=1 limit 1", username, oldName).
+		Scan(&id, &storedName, &filehash, &filesize)
+	if err == sql.ErrNoRows {
+		res.Suc = false
+		res.Msg = "文件不存在"
+		return
+	} else if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	_, err = tx.Exec("update tbl_user_file set file_name=? where id=?", newName, id)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	err = addUserFileEvent(tx, username, renameEvent(storedName, newName), newName, storedName, filehash, filesize)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	if err = commitUserFileTx(tx, username); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// RenameUserFilesByPrefix : 将文件名以oldPrefix开头的用户文件批量改为以newPrefix开头, 用于移动目录
+func RenameUserFilesByPrefix(username, oldPrefix, newPrefix string) (res ExecResult) {
+	tx, err := beginUserFileTx(username)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("delete from tbl_user_file where user_name=? and status<>1 and file_name like ?",
+		username, escapeLike(newPrefix)+"%")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	// substring按字符而非字节计算位置. 每个被移动的文件记录一条事件
+	_, err = tx.Exec("insert into tbl_user_file_event (`user_name`,`event`,`file_name`,`old_name`,`file_sha1`,`file_size`) "+
+		"select user_name,?,concat(?,substring(file_name,?)),file_name,file_sha1,file_size from tbl_user_file "+
+		"where user_name=? and status=1 and binary file_name like ? order by binary file_name",
+		renameEvent(oldPrefix, newPrefix), newPrefix, utf8.RuneCountInString(oldPrefix)+1,
+		username, escapeLike(oldPrefix)+"%")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	_, err = tx.Exec("update tbl_user_file set file_name=concat(?,substring(file_name,?)) "+
+		"where user_name=? and status=1 and binary file_name like ?",
+		newPrefix, utf8.RuneCountInString(oldPrefix)+1, username, escapeLike(oldPrefix)+"%")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	if err = commitUserFileTx(tx, username); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// RestoreUserFile : 恢复已删除(标记删除)的用户文件, 同名文件已存在时失败
+func RestoreUserFile(username, filename string) (res ExecResult) {
+	tx, err := beginUserFileTx(username)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer tx.Rollback()
+
+	// 唯一索引保证同名记录只有一条, 未删除时即已存在
+	var id, filesize, status int64
+	var filehash, storedName string
+	err = tx.QueryRow("select id,file_name,file_sha1,file_size,status from tbl_user_file "+
+		"where user_name=? and file_name=? limit 1", username, filename).
+		Scan(&id, &storedName, &filehash, &filesize, &status)
+	if err == sql.ErrNoRows || (err == nil && status != 2) {
+		res.Suc = false
+		res.Msg = "没有可恢复的文件"
+		return
+	} else if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	_, err = tx.Exec("update tbl_user_file set status=1 where id=?", id)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	err = addUserFileEvent(tx, username, common.FileEventRestore, storedName, "", filehash, filesize)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	if err = commitUserFileTx(tx, username); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// ListUserFolders : 查询用户的顶层目录(文件名中第一个"/"之前的部分)
+func ListUserFolders(username string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select substring_index(file_name,'/',1) as folder,min(upload_at) from tbl_user_file " +
+			"where user_name=? and status=1 and locate('/',file_name)>1 " +
+			"group by folder order by folder")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(username)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	folders := []TableUserFolder{}
+	for rows.Next() {
+		folder := TableUserFolder{}
+		if err = rows.Scan(&folder.FolderName, &folder.CreateAt); err != nil {
+			log.Println(err.Error())
+			break
+		}
+		folders = append(folders, folder)
+	}
+	res.Suc = true
+	res.Data = folders
+	return
+}
+
+// GetUserFileUsage : 统计用户的文件数及占用空间(不含目录标记)
+func GetUserFileUsage(username string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select count(*),ifnull(sum(file_size),0) from tbl_user_file " +
+			"where user_name=? and status=1 and file_name not like '%/'")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	usage := TableUserUsage{}
+	err = stmt.QueryRow(username).Scan(&usage.FileCount, &usage.TotalSize)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	res.Data = usage
+	return
+}
+
+// escapeLike : 转义like语句中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,241 @@
+package orm
+
+import (
+	"log"
+
+	mydb "github.com/cloud/service/dbproxy/conn"
+)
+
+// CreateVaultKeyPair : 保存用户的加密目录密钥对, 已存在时失败
+func CreateVaultKeyPair(username, publicKey, secretKey, kdfSalt, kdfParams string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"insert ignore into tbl_vault_keypair (`user_name`,`public_key`,`secret_key`,`kdf_salt`,`kdf_params`) " +
+			"values (?,?,?,?,?)")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	ret, err := stmt.Exec(username, publicKey, secretKey, kdfSalt, kdfParams)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	if rowsAffected, err := ret.RowsAffected(); nil == err && rowsAffected > 0 {
+		res.Suc = true
+		return
+	}
+	res.Suc = false
+	res.Msg = "密钥对已存在"
+	return
+}
+
+// UpdateVaultSecretKey : 修改口令后更新加密的私钥, 公钥须不变
+func UpdateVaultSecretKey(username, publicKey, secretKey, kdfSalt, kdfParams string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"update tbl_vault_keypair set `secret_key`=?,`kdf_salt`=?,`kdf_params`=? " +
+			"where user_name=? and public_key=?")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	ret, err := stmt.Exec(secretKey, kdfSalt, kdfParams, username, publicKey)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	if rowsAffected, err := ret.RowsAffected(); nil == err && rowsAffected > 0 {
+		res.Suc = true
+		return
+	}
+	res.Suc = false
+	res.Msg = "密钥对不存在或公钥不一致"
+	return
+}
+
+// GetVaultKeyPair : 查询用户的加密目录密钥对, 没有时Data为nil
+func GetVaultKeyPair(username string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select `user_name`,`public_key`,`secret_key`,`kdf_salt`,`kdf_params` from tbl_vault_keypair " +
+			"where user_name=? limit 1")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(username)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	res.Suc = true
+	if rows.Next() {
+		kp := TableVaultKeyPair{}
+		err = rows.Scan(&kp.UserName, &kp.PublicKey, &kp.SecretKey, &kp.KDFSalt, &kp.KDFParams)
+		if err != nil {
+			log.Println(err.Error())
+			res.Suc = false
+			res.Msg = err.Error()
+			return
+		}
+		res.Data = kp
+	}
+	return
+}
+
+// AddVaultMember : 记录用户可访问的加密目录及以其公钥加密的目录密钥.
+// username与owner相同时为创建目录, 否则为分享(重复分享时更新目录密钥)
+func AddVaultMember(owner, folder, username, wrappedKey string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"insert into tbl_vault (`owner`,`folder`,`user_name`,`wrapped_key`) values (?,?,?,?) " +
+			"on duplicate key update `wrapped_key`=values(`wrapped_key`)")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.Exec(owner, folder, username, wrappedKey); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// RemoveVaultMember : 取消分享, 不能移除所有者
+func RemoveVaultMember(owner, folder, username string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"delete from tbl_vault where owner=? and folder=? and user_name=? and user_name<>owner")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	ret, err := stmt.Exec(owner, folder, username)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	if rowsAffected, err := ret.RowsAffected(); nil == err && rowsAffected > 0 {
+		res.Suc = true
+		return
+	}
+	res.Suc = false
+	res.Msg = "未分享给该用户"
+	return
+}
+
+// queryVaults : 查询加密目录记录
+func queryVaults(query string, args ...interface{}) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select `owner`,`folder`,`user_name`,`wrapped_key`,`create_at` from tbl_vault " + query)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	vaults := []TableVault{}
+	for rows.Next() {
+		v := TableVault{}
+		if err = rows.Scan(&v.Owner, &v.Folder, &v.UserName, &v.WrappedKey, &v.CreateAt); err != nil {
+			log.Println(err.Error())
+			break
+		}
+		vaults = append(vaults, v)
+	}
+	res.Suc = true
+	res.Data = vaults
+	return
+}
+
+// ListVaults : 查询用户可访问的加密目录(自己的及分享给该用户的)
+func ListVaults(username string) (res ExecResult) {
+	return queryVaults("where user_name=? order by owner, folder", username)
+}
+
+// ListVaultMembers : 查询可访问加密目录的用户(包括所有者)
+func ListVaultMembers(owner, folder string) (res ExecResult) {
+	return queryVaults("where owner=? and folder=? order by create_at", owner, folder)
+}
+
+// GetVaultByPath : 查询owner的文件filename所在的、username可访问的加密目录, 不在其中时Data为nil.
+// username与owner相同时用于判断文件是否在加密目录中
+func GetVaultByPath(owner, username, filename string) (res ExecResult) {
+	res = queryVaults("where owner=? and user_name=? and left(?, char_length(folder))=folder limit 1",
+		owner, username, filename)
+	if vaults, ok := res.Data.([]TableVault); ok {
+		res.Data = nil
+		if len(vaults) > 0 {
+			res.Data = vaults[0]
+		}
+	}
+	return
+}
+
+// IsVaultFileHash : 是否有用户在加密目录中保存了该hash的文件(包括已删除的), 这样的文件不能被秒传
+func IsVaultFileHash(filehash string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select 1 from tbl_user_file f join tbl_vault v " +
+			"on v.owner=f.user_name and v.user_name=f.user_name " +
+			"and left(f.file_name, char_length(v.folder))=v.folder " +
+			"where f.file_sha1=? limit 1")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(filehash)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+	res.Suc = true
+	res.Data = rows.Next()
+	return
+}
@@ -0,0 +1,286 @@
+package orm
+
+import (
+	"database/sql"
+	"log"
+
+	mydb "github.com/cloud/service/dbproxy/conn"
+)
+
+// CreateWebhook : 注册webhook, 游标从用户当前最新的事件开始, 不投递历史事件
+func CreateWebhook(username, url, secret, events string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"insert into tbl_user_webhook (`user_name`,`url`,`secret`,`events`,`event_cursor`,`status`) " +
+			"select ?,?,?,?,ifnull(max(id),0),1 from tbl_user_file_event where user_name=?")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	ret, err := stmt.Exec(username, url, secret, events, username)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	id, err := ret.LastInsertId()
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	res.Data = TableWebhook{ID: id, UserName: username, URL: url, Events: events, Status: 1}
+	return
+}
+
+// ListWebhooks : 查询用户已注册的webhook(不含签名密钥)
+func ListWebhooks(username string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select id,user_name,url,events,event_cursor,create_at,status from tbl_user_webhook " +
+			"where user_name=? and status=1 order by id")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(username)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	hooks := []TableWebhook{}
+	for rows.Next() {
+		hook := TableWebhook{}
+		err = rows.Scan(&hook.ID, &hook.UserName, &hook.URL, &hook.Events,
+			&hook.Cursor, &hook.CreateAt, &hook.Status)
+		if err != nil {
+			log.Println(err.Error())
+			break
+		}
+		hooks = append(hooks, hook)
+	}
+	res.Suc = true
+	res.Data = hooks
+	return
+}
+
+// DeleteWebhook : 删除用户的webhook(标记删除), 未投递的记录不再投递
+func DeleteWebhook(username string, id int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"update tbl_user_webhook set status=2 where user_name=? and id=? and status=1")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	ret, err := stmt.Exec(username, id)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	if rf, err := ret.RowsAffected(); err == nil && rf <= 0 {
+		res.Suc = false
+		res.Msg = "webhook不存在"
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// ListActiveWebhooks : 查询接收指定用户事件的webhook(含签名密钥): 用户自己的及全局的.
+// username为空时只查询全局webhook
+func ListActiveWebhooks(username string) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select id,user_name,url,secret,events,event_cursor,create_at,status from tbl_user_webhook " +
+			"where user_name in (?,'') and status=1 order by id")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(username)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	hooks := []TableWebhook{}
+	for rows.Next() {
+		hook := TableWebhook{}
+		err = rows.Scan(&hook.ID, &hook.UserName, &hook.URL, &hook.Secret, &hook.Events,
+			&hook.Cursor, &hook.CreateAt, &hook.Status)
+		if err != nil {
+			log.Println(err.Error())
+			break
+		}
+		hooks = append(hooks, hook)
+	}
+	res.Suc = true
+	res.Data = hooks
+	return
+}
+
+// AdvanceWebhookCursor : 前移webhook的游标, 游标只增不减
+func AdvanceWebhookCursor(id, cursor int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"update tbl_user_webhook set event_cursor=? where id=? and event_cursor<?")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.Exec(cursor, id, cursor); err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// CreateWebhookDelivery : 记录待投递的事件, nextRetryAt(unix秒)为预计的投递时间.
+// 同一webhook的同一事件只记录一次, 已存在时Data中的id为0
+func CreateWebhookDelivery(hookID, eventID int64, username, event, payload string, nextRetryAt int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"insert ignore into tbl_webhook_delivery (`hook_id`,`event_id`,`user_name`,`event`," +
+			"`payload`,`status`,`next_retry_at`) values (?,?,?,?,?,0,from_unixtime(?))")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	ret, err := stmt.Exec(hookID, eventID, username, event, payload, nextRetryAt)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	var id int64
+	if rf, err := ret.RowsAffected(); err == nil && rf > 0 {
+		id, _ = ret.LastInsertId()
+	}
+	res.Suc = true
+	res.Data = map[string]int64{
+		"id": id,
+	}
+	return
+}
+
+// UpdateWebhookDelivery : 记录一次投递的结果
+func UpdateWebhookDelivery(id, status, attempts, responseCode int64, lastError string, nextRetryAt int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"update tbl_webhook_delivery set status=?,attempts=?,response_code=?,last_error=?," +
+			"next_retry_at=from_unixtime(?) where id=?")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(status, attempts, responseCode, lastError, nextRetryAt, id)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	return
+}
+
+// GetWebhookDelivery : 查询webhook对某事件的投递记录(含webhook的URL及签名密钥),
+// 记录不存在或webhook已停用时Data中的ID为0
+func GetWebhookDelivery(hookID, eventID int64) (res ExecResult) {
+	d := TableWebhookDelivery{}
+	err := mydb.DBConn().QueryRow(
+		"select d.id,d.hook_id,d.event_id,d.user_name,d.event,d.payload,d.status,d.attempts,"+
+			"d.response_code,d.last_error,unix_timestamp(d.next_retry_at),d.create_at,d.update_at,"+
+			"h.url,h.secret from tbl_webhook_delivery d join tbl_user_webhook h on d.hook_id=h.id "+
+			"where d.hook_id=? and d.event_id=? and h.status=1", hookID, eventID).Scan(
+		&d.ID, &d.HookID, &d.EventID, &d.UserName, &d.Event, &d.Payload,
+		&d.Status, &d.Attempts, &d.ResponseCode, &d.LastError, &d.NextRetryAt,
+		&d.CreateAt, &d.UpdateAt, &d.URL, &d.Secret)
+	if err != nil && err != sql.ErrNoRows {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	res.Suc = true
+	res.Data = d
+	return
+}
+
+// ListWebhookDeliveries : 按时间倒序查询用户webhook的投递记录, hookID为0时查询全部webhook
+func ListWebhookDeliveries(username string, hookID, limit int64) (res ExecResult) {
+	stmt, err := mydb.DBConn().Prepare(
+		"select id,hook_id,event_id,user_name,event,payload,status,attempts,response_code," +
+			"last_error,unix_timestamp(next_retry_at),create_at,update_at from tbl_webhook_delivery " +
+			"where user_name=? and (hook_id=? or ?=0) order by id desc limit ?")
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(username, hookID, hookID, limit)
+	if err != nil {
+		log.Println(err.Error())
+		res.Suc = false
+		res.Msg = err.Error()
+		return
+	}
+	defer rows.Close()
+
+	deliveries := []TableWebhookDelivery{}
+	for rows.Next() {
+		d := TableWebhookDelivery{}
+		err = rows.Scan(&d.ID, &d.HookID, &d.EventID, &d.UserName, &d.Event, &d.Payload,
+			&d.Status, &d.Attempts, &d.ResponseCode, &d.LastError, &d.NextRetryAt,
+			&d.CreateAt, &d.UpdateAt)
+		if err != nil {
+			log.Println(err.Error())
+			break
+		}
+		deliveries = append(deliveries, d)
+	}
+	res.Suc = true
+	res.Data = deliveries
+	return
+}
@@ -2,26 +2,26 @@ package api
 
 import (
 	"fmt"
-	"io"
-	"io/ioutil"
-	"os"
 	"log"
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/cloud/common"
-	"github.com/cloud/config"
 	dbcli "github.com/cloud/service/dbproxy/client"
-	"github.com/cloud/store/ceph"
+	"github.com/cloud/store/backend"
 	"github.com/cloud/store/oss"
+	"github.com/cloud/store/replica"
+	"github.com/cloud/store/replica/dbreplica"
+	"github.com/cloud/store/tier"
+	"github.com/cloud/store/tier/dbtier"
 )
 
 // DownloadURLHandler : 生成文件的下载地址
 func DownloadURLHandler(c *gin.Context) {
 	filehash := c.Request.FormValue("filehash")
-	// 从文件表查找记录
-	dbResp, err := dbcli.GetFileMeta(filehash)
+	// 查询文件可读取的位置, 按读取偏好排序
+	candidates, err := dbreplica.Candidates(filehash)
 	if err != nil {
 		c.JSON(
 			http.StatusOK,
@@ -32,33 +32,48 @@ func DownloadURLHandler(c *gin.Context) {
 		return
 	}
 
-	tblFile := dbcli.ToTableFile(dbResp.Data)
-
-	// TODO: 判断文件存在OSS，还是Ceph，还是在本地
-	if strings.HasPrefix(tblFile.FileAddr.String, config.MergeLocalRootDir) ||
-		strings.HasPrefix(tblFile.FileAddr.String, config.CephRootDir) {
+	// 只有OSS上未加密且未压缩的副本可用时返回OSS的签名地址, 否则经由本服务下载(解密、解压, 读取失败时切换副本)
+	if len(candidates) == 0 || candidates[0].Status != common.LocationAvailable {
+		c.Data(http.StatusOK, "application/octet-stream", []byte("Error: 下载链接暂时无法生成"))
+	} else if candidates[0].StoreType == common.StoreOSS && raw(candidates[0]) {
+		// oss下载url, 不经过本服务下载, 在此记录访问
+		if !accessFile(c, filehash) {
+			return
+		}
+		signedURL := oss.DownloadURL(candidates[0].Location)
+		log.Println(candidates[0].Location)
+		c.Data(http.StatusOK, "application/octet-stream", []byte(signedURL))
+	} else {
 		username := c.Request.FormValue("username")
 		token := c.Request.FormValue("token")
 		tmpURL := fmt.Sprintf("http://%s/file/download?filehash=%s&username=%s&token=%s",
 			c.Request.Host, filehash, username, token)
 		c.Data(http.StatusOK, "application/octet-stream", []byte(tmpURL))
-	} else if strings.HasPrefix(tblFile.FileAddr.String, config.OSSRootDir) {
-		// oss下载url
-		signedURL := oss.DownloadURL(tblFile.FileAddr.String)
-		log.Println(tblFile.FileAddr.String)
-		c.Data(http.StatusOK, "application/octet-stream", []byte(signedURL))
-	} else {
-		c.Data(http.StatusOK, "application/octet-stream", []byte("Error: 下载链接暂时无法生成"))
 	}
 }
 
+// raw : 副本是否未加密且未压缩(可直接下载), 查询失败时按不可直接下载处理
+func raw(rep replica.Replica) bool {
+	ok, err := backend.Raw(rep.StoreType, rep.Location)
+	if err != nil {
+		log.Println(err.Error())
+		return false
+	}
+	return ok
+}
+
 // DownloadHandler : 文件下载接口
 func DownloadHandler(c *gin.Context) {
 	fsha1 := c.Request.FormValue("filehash")
 	username := c.Request.FormValue("username")
+	// owner : 下载分享给自己的加密目录中的文件时为目录所有者
+	owner := c.Request.FormValue("owner")
+	if owner == "" {
+		owner = username
+	}
 	// TODO: 处理异常情况
 	fResp, ferr := dbcli.GetFileMeta(fsha1)
-	ufResp, uferr := dbcli.QueryUserFileMeta(username, fsha1)
+	ufResp, uferr := dbcli.QueryUserFileMeta(owner, fsha1)
 	if ferr != nil || uferr != nil || !fResp.Suc || !ufResp.Suc {
 		c.JSON(
 			http.StatusOK,
@@ -71,39 +86,74 @@ func DownloadHandler(c *gin.Context) {
 	uniqFile := dbcli.ToTableFile(fResp.Data)
 	userFile := dbcli.ToTableUserFile(ufResp.Data)
 
-	if strings.HasPrefix(uniqFile.FileAddr.String, config.MergeLocalRootDir) {
-		// 本地文件， 直接下载
-		c.FileAttachment(uniqFile.FileAddr.String, userFile.FileName)
-	} else if strings.HasPrefix(uniqFile.FileAddr.String, config.CephRootDir) {
-		fmt.Println("to download file from ceph...")
-		// ceph中的文件，通过ceph api先下载
-		bucket := ceph.GetCephBucket("userfile")
-		data, _ := bucket.Get(uniqFile.FileAddr.String)
-		//	c.Header("content-type", "application/octect-stream")
-		c.Header("content-disposition", "attachment; filename=\""+userFile.FileName+"\"")
-		c.Data(http.StatusOK, "application/octect-stream", data)
-	} else if strings.HasPrefix(uniqFile.FileAddr.String, config.OSSRootDir) {
-		fmt.Println("to download file from oss...")
-		var err1 error
-		var err2 error
-		var fd io.ReadCloser
-		var fileData []byte
-		fd, err1 = oss.Bucket().GetObject(uniqFile.FileAddr.String)
-		if err1 == nil {
-			fileData, err2 = ioutil.ReadAll(fd)
-			if err2 == nil {
-				c.Header("content-disposition", "attachment; filename=\""+userFile.FileName+"\"")
-				c.Data(http.StatusOK, "application/octect-stream", fileData)
-			}
-		}
-		if err1 != nil || err2 != nil {
-			c.Data(http.StatusInternalServerError, "application/octect-stream", []byte("Intern server error."))
-			return
-		}
-	} else {
-		c.Data(http.StatusNotFound, "application/octect-stream", []byte("File not found."))
+	// 他人的文件只能在加密目录分享给自己后下载(内容为密文)
+	if owner != username && !vaultMember(owner, username, userFile.FileName) {
+		c.JSON(
+			http.StatusOK,
+			gin.H{
+				"code": common.StatusParamInvalid,
+				"msg":  "file not found",
+			})
+		return
+	}
+
+	if !accessFile(c, fsha1) {
 		return
 	}
+	sendFile(c, fsha1, uniqFile.FileSize.Int64, userFile.FileName)
+}
+
+// vaultMember : username是否可访问owner的文件filename所在的加密目录
+func vaultMember(owner, username, filename string) bool {
+	if filename == "" {
+		return false
+	}
+	res, err := dbcli.GetVaultByPath(owner, username, filename)
+	if err != nil {
+		log.Println(err.Error())
+		return false
+	}
+	return res != nil && res.Suc && res.Data != nil
+}
+
+// accessFile : 记录文件访问. 归档的文件解冻完成前返回"解冻中"(202), 客户端稍后重试
+func accessFile(c *gin.Context, filehash string) bool {
+	err := dbtier.Access(filehash)
+	if err == nil {
+		return true
+	}
+	if err == tier.ErrRestoring {
+		c.JSON(
+			http.StatusAccepted,
+			gin.H{
+				"code": common.StatusFileRestoring,
+				"msg":  "file is being restored from archive, please retry later",
+			})
+		return false
+	}
+	log.Println(err.Error())
+	c.JSON(
+		http.StatusOK,
+		gin.H{
+			"code": common.StatusServerError,
+			"msg":  "server error",
+		})
+	return false
+}
+
+// sendFile : 从可用的副本读取文件内容作为附件返回, 副本读取失败时切换到其他副本
+func sendFile(c *gin.Context, filehash string, size int64, filename string) {
+	reader, err := dbreplica.Open(filehash, 0, size)
+	if err != nil {
+		log.Println(err.Error())
+		c.Data(http.StatusInternalServerError, "application/octect-stream", []byte("Intern server error."))
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, size, "application/octect-stream", reader, map[string]string{
+		"content-disposition": "attachment; filename=\"" + filename + "\"",
+	})
 }
 
 // RangeDownloadHandler : 支持断点的文件下载接口
@@ -125,25 +175,16 @@ func RangeDownloadHandler(c *gin.Context) {
 	//         uniqFile := dbcli.ToTableFile(fResp.Data)
 	userFile := dbcli.ToTableUserFile(ufResp.Data)
 
-	// 使用本地目录文件
-	fpath := config.MergeLocalRootDir + fsha1
-	fmt.Println("range-download-fpath: " + fpath)
-
-	f, err := os.Open(fpath)
-	if err != nil {
-		c.JSON(
-			http.StatusOK,
-			gin.H{
-				"code": common.StatusServerError,
-				"msg":  "server error",
-			})
+	// 按Range从可用的副本读取
+	if !accessFile(c, fsha1) {
 		return
 	}
+	f := dbreplica.NewFile(fsha1, userFile.FileSize)
 	defer f.Close()
 
 	c.Writer.Header().Set("Content-Type", "application/octect-stream")
 	c.Writer.Header().Set("content-disposition", "attachment; filename=\""+userFile.FileName+"\"")
-	c.File(fpath)
+	http.ServeContent(c.Writer, c.Request, userFile.FileName, time.Time{}, f)
 }
 
 
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cloud/common"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	dlCfg "github.com/cloud/service/download/config"
+	"github.com/cloud/util"
+)
+
+// shareSignature : 分享链接的签名, 覆盖用户名、文件hash、文件名及过期时间
+func shareSignature(username, filehash, filename string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(dlCfg.ShareLinkSecret))
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%d", username, filehash, filename, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ShareLinkHandler : 为用户文件生成限时分享链接, 任何人可通过链接下载而无需登录.
+// 链接不保存在服务端, 文件被删除、重命名或覆盖后链接自动失效
+func ShareLinkHandler(c *gin.Context) {
+	// 未配置签名密钥时不生成链接(下载服务启动时已检查)
+	if dlCfg.ShareLinkSecret == "" {
+		c.JSON(http.StatusOK, gin.H{
+			"code": common.StatusServerError,
+			"msg":  "server error",
+		})
+		return
+	}
+	username := c.Request.FormValue("username")
+	filename := c.Request.FormValue("filename")
+	ttl := dlCfg.ShareLinkTTLDefault
+	if v := c.Request.FormValue("expires"); v != "" {
+		var err error
+		ttl, err = strconv.Atoi(v)
+		if err != nil || ttl <= 0 || ttl > dlCfg.ShareLinkTTLMax {
+			c.JSON(http.StatusOK, gin.H{
+				"code": common.StatusParamInvalid,
+				"msg":  "invalid expires",
+			})
+			return
+		}
+	}
+
+	dbResp, err := dbcli.QueryUserFileByName(username, filename)
+	if err != nil || !dbResp.Suc {
+		c.JSON(http.StatusOK, gin.H{
+			"code": common.StatusServerError,
+			"msg":  "server error",
+		})
+		return
+	}
+	if dbResp.Data == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"code": common.StatusParamInvalid,
+			"msg":  "file not found",
+		})
+		return
+	}
+	userFile := dbcli.ToTableUserFile(dbResp.Data)
+
+	// 加密目录中的文件只能以公钥分享目录密钥, 不生成公开链接
+	folder, err := dbcli.VaultFolder(username, userFile.FileName)
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusOK, gin.H{
+			"code": common.StatusServerError,
+			"msg":  "server error",
+		})
+		return
+	}
+	if folder != "" {
+		c.JSON(http.StatusOK, gin.H{
+			"code": common.StatusVaultUnsupported,
+			"msg":  "加密目录中的文件不支持分享链接, 请分享加密目录",
+		})
+		return
+	}
+
+	// 分享不修改文件, 事件记录失败不影响生成链接
+	evResp, err := dbcli.AppendUserFileEvent(username, common.FileEventShare,
+		userFile.FileName, userFile.FileHash, userFile.FileSize)
+	if err != nil {
+		log.Println(err.Error())
+	} else if !evResp.Suc {
+		log.Println(evResp.Msg)
+	}
+
+	expireAt := time.Now().Add(time.Duration(ttl) * time.Second)
+	query := url.Values{
+		"user":     {username},
+		"filehash": {userFile.FileHash},
+		"filename": {userFile.FileName},
+		"expires":  {strconv.FormatInt(expireAt.Unix(), 10)},
+		"sig":      {shareSignature(username, userFile.FileHash, userFile.FileName, expireAt.Unix())},
+	}
+	cliResp := util.RespMsg{
+		Code: int(common.StatusOK),
+		Msg:  "OK",
+		Data: gin.H{
+			"URL":      fmt.Sprintf("http://%s/file/share?%s", c.Request.Host, query.Encode()),
+			"ExpireAt": expireAt.Format("2006-01-02 15:04:05"),
+		},
+	}
+	c.Data(http.StatusOK, "application/json", cliResp.JSONBytes())
+}
+
+// ShareDownloadHandler : 通过分享链接下载文件
+func ShareDownloadHandler(c *gin.Context) {
+	username := c.Query("user")
+	filehash := c.Query("filehash")
+	filename := c.Query("filename")
+	expires, _ := strconv.ParseInt(c.Query("expires"), 10, 64)
+
+	expected := shareSignature(username, filehash, filename, expires)
+	if dlCfg.ShareLinkSecret == "" || !hmac.Equal([]byte(expected), []byte(c.Query("sig"))) {
+		c.Data(http.StatusForbidden, "text/plain", []byte("Invalid share link."))
+		return
+	}
+	if time.Now().Unix() > expires {
+		c.Data(http.StatusForbidden, "text/plain", []byte("Share link expired."))
+		return
+	}
+
+	// 文件须仍然存在且内容未变
+	ufResp, err := dbcli.QueryUserFileByName(username, filename)
+	if err != nil || !ufResp.Suc {
+		c.Data(http.StatusInternalServerError, "text/plain", []byte("Intern server error."))
+		return
+	}
+	userFile := dbcli.ToTableUserFile(ufResp.Data)
+	if ufResp.Data == nil || userFile.FileHash != filehash {
+		c.Data(http.StatusNotFound, "text/plain", []byte("File not found."))
+		return
+	}
+
+	if !accessFile(c, filehash) {
+		return
+	}
+	sendFile(c, filehash, userFile.FileSize, path.Base(filename))
+}
@@ -1,7 +1,22 @@
 package config
 
+import "os"
+
 // DownloadEntry : 配置上传入口地址
 var DownloadEntry = "127.0.0.1:38080"
 
 // DownloadServiceHost : 上传服务监听的地址
-var DownloadServiceHost = "0.0.0.0:38080"
\ No newline at end of file
+var DownloadServiceHost = "0.0.0.0:38080"
+
+// ShareLinkSecretEnv : 设置分享链接签名密钥的环境变量
+const ShareLinkSecretEnv = "CLOUD_SHARE_LINK_SECRET"
+
+// ShareLinkSecret : 分享链接的签名密钥, 从环境变量ShareLinkSecretEnv读取, 多实例部署时须保持一致.
+// 未设置时下载服务拒绝启动
+var ShareLinkSecret = os.Getenv(ShareLinkSecretEnv)
+
+// ShareLinkTTLDefault : 分享链接的默认有效期(秒)
+var ShareLinkTTLDefault = 86400
+
+// ShareLinkTTLMax : 分享链接的最长有效期(秒)
+var ShareLinkTTLMax = 7 * 86400
@@ -2,9 +2,14 @@ package download
 
 import (
 	"fmt"
+	"os"
+	"github.com/cloud/job/dbqueue"
+	"github.com/cloud/mq"
 	"github.com/cloud/service/download/config"
 	dlProto "github.com/cloud/service/download/proto"
 	"github.com/cloud/service/download/route"
+	"github.com/cloud/store/replica/dbreplica"
+	"github.com/cloud/store/tier/dbtier"
 	"github.com/micro/go-micro"
 	"time"
 )
@@ -29,6 +34,17 @@ func startAPIService() {
 }
 
 func main() {
+	// 分享链接的签名密钥不能使用默认值, 未配置时拒绝启动
+	if config.ShareLinkSecret == "" {
+		fmt.Println("未设置分享链接签名密钥, 请通过环境变量" + config.ShareLinkSecretEnv + "配置")
+		os.Exit(1)
+	}
+
+	// 修复及层级任务通过dbproxy保存、通过默认消息队列发布
+	jobs := dbqueue.NewClient(mq.Default())
+	dbreplica.Setup(jobs)
+	dbtier.Setup(jobs)
+
 	// 启动API服务
 	startAPIService()
 
@@ -1,6 +1,7 @@
 package route
 
 import (
+	"github.com/cloud/middleware"
 	"github.com/cloud/service/download/api"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -28,10 +29,14 @@ func Router() *gin.Engine {
 
 	// Use之后的所有handler都会经过拦截器进行token校验
 
-	// 文件下载相关接口
-	router.GET("/file/download", api.DownloadHandler)
-	router.GET("/file/download/range", api.RangeDownloadHandler)
-	router.POST("/file/downloadurl", api.DownloadURLHandler)
+	// 文件下载相关接口, 须校验token(下载他人加密目录中的文件时按username校验成员身份)
+	router.GET("/file/download", middleware.HTTPInterceptor(), api.DownloadHandler)
+	router.GET("/file/download/range", middleware.HTTPInterceptor(), api.RangeDownloadHandler)
+	router.POST("/file/downloadurl", middleware.HTTPInterceptor(), api.DownloadURLHandler)
+
+	// 分享链接: 生成链接须校验token, 通过链接下载无需登录
+	router.POST("/file/sharelink", middleware.HTTPInterceptor(), api.ShareLinkHandler)
+	router.GET("/file/share", api.ShareDownloadHandler)
 
 	return router
 }
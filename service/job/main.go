@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/micro/go-micro"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	"github.com/cloud/job"
+	"github.com/cloud/job/dbqueue"
+	"github.com/cloud/mq"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	jobProto "github.com/cloud/service/job/proto"
+	jobRpc "github.com/cloud/service/job/rpc"
+	"github.com/cloud/service/job/tasks"
+	"github.com/cloud/store/backend"
+	"github.com/cloud/store/crypt/dbcrypt"
+	"github.com/cloud/store/replica/dbreplica"
+	"github.com/cloud/store/scrub"
+	"github.com/cloud/store/scrub/dbscrub"
+	"github.com/cloud/store/tier"
+	"github.com/cloud/store/tier/dbtier"
+)
+
+func startRPCService() {
+	service := micro.NewService(
+		micro.Name("go.micro.service.job"),
+		micro.RegisterTTL(time.Second*10),
+		micro.RegisterInterval(time.Second*5),
+		micro.Registry(config.RegistryConsul()))
+	service.Init()
+
+	jobProto.RegisterJobServiceHandler(service.Server(), new(jobRpc.Job))
+	if err := service.Run(); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// startScheduler : 定期重新入队到期的任务
+func startScheduler(w *job.Worker) {
+	ticker := time.NewTicker(config.JobTickInterval * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := w.Tick(); err != nil {
+			log.Println(err.Error())
+		}
+	}
+}
+
+// startTierScan : 定期为长期未访问的文件提交降级任务
+func startTierScan(jobs *job.Client) {
+	ticker := time.NewTicker(config.TierScanInterval * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		count, err := tier.Scan(dbtier.ListCandidates, jobs.Enqueue, time.Now())
+		if err != nil {
+			log.Println(err.Error())
+		}
+		if count > 0 {
+			log.Printf("enqueued %d tier jobs\n", count)
+		}
+	}
+}
+
+// startScrubScan : 定期为长期未检查的文件提交完整性检查任务
+func startScrubScan(jobs *job.Client) {
+	ticker := time.NewTicker(config.ScrubInterval * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		count, err := scrub.Scan(dbscrub.ListCandidates, jobs.Enqueue, time.Now())
+		if err != nil {
+			log.Println(err.Error())
+		}
+		if count > 0 {
+			log.Printf("enqueued %d scrub jobs\n", count)
+		}
+	}
+}
+
+// startKeyRewrap : 定期以当前主密钥重新加密由旧主密钥加密的数据密钥(更换主密钥后)
+func startKeyRewrap() {
+	ticker := time.NewTicker(config.KeyRewrapInterval * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		count, err := dbcrypt.Default().Rewrap(config.KeyRewrapBatch)
+		if err != nil {
+			log.Println(err.Error())
+		}
+		if count > 0 {
+			log.Printf("rewrapped %d data keys\n", count)
+		}
+	}
+}
+
+// startLegacyTransferConsumer : 将升级前发布到原rabbitmq转移队列中的消息转为转移任务
+func startLegacyTransferConsumer(b mq.Broker, jobs *job.Client) {
+	if !config.AsyncTransferEnable || config.MQBackend != mq.BackendRabbitMQ {
+		return
+	}
+	err := b.Consume(context.Background(), config.TransOSSQueueName, "transfer_oss", 1, mq.AckHandler(func(msg []byte) bool {
+		data := mq.TransferData{}
+		if err := json.Unmarshal(msg, &data); err != nil {
+			log.Println(err.Error())
+			return false
+		}
+		payload := job.TransferPayload{
+			FileHash:      data.FileHash,
+			Location:      data.Location,
+			DestLocation:  data.DestLocation,
+			DestStoreType: data.DestStoreType,
+		}
+		_, err := jobs.Enqueue(job.TypeTransfer, payload,
+			job.EnqueueOptions{IdempotencyKey: payload.IdempotencyKey()})
+		if err != nil {
+			log.Println(err.Error())
+			return false
+		}
+		return true
+	}))
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// newTransferer : 写入Ceph/OSS并通过dbproxy记录文件位置的转移任务
+func newTransferer() *tasks.Transferer {
+	return &tasks.Transferer{
+		Open: backend.Open,
+		Put:  backend.Put,
+		AddLocation: func(filehash string, t common.StoreType, location string) error {
+			resp, err := dbcli.AddFileLocation(filehash, t, location)
+			if err != nil {
+				return err
+			}
+			if !resp.Suc {
+				return errors.New("add file location failed: " + resp.Msg)
+			}
+			return nil
+		},
+		UpdateLocation: func(filehash, location string) error {
+			resp, err := dbcli.UpdateFileLocation(filehash, location)
+			if err != nil {
+				return err
+			}
+			if !resp.Suc {
+				return errors.New("update file location failed: " + resp.Msg)
+			}
+			return nil
+		},
+		Transferred: func(filehash string) {
+			if resp, err := dbcli.AppendFileTransferEvents(filehash); err != nil {
+				log.Println(err.Error())
+			} else if !resp.Suc {
+				log.Println("记录转移事件失败:" + filehash)
+			}
+		},
+	}
+}
+
+// newRepairer : 从可用的副本重新写入缺失副本的修复任务
+func newRepairer() *tasks.Repairer {
+	return &tasks.Repairer{
+		Store:  dbreplica.Store{},
+		Open:   backend.Open,
+		Put:    backend.Put,
+		TypeOf: backend.TypeOf,
+	}
+}
+
+// newTierer : 在本地/Ceph与OSS的各存储类型之间移动文件的层级任务
+func newTierer() *tasks.Tierer {
+	return &tasks.Tierer{
+		Store:           dbreplica.Store{},
+		Open:            backend.Open,
+		Put:             backend.Put,
+		TypeOf:          backend.TypeOf,
+		Remove:          backend.Remove,
+		SetStorageClass: backend.SetStorageClass,
+		Restore:         backend.Restore,
+		GetTier:         dbtier.GetTier,
+		SetTier:         dbtier.SetTier,
+	}
+}
+
+// newScrubber : 重新计算各位置内容的sha1, 隔离损坏副本并提交修复的完整性检查任务
+func newScrubber() *tasks.Scrubber {
+	return &tasks.Scrubber{
+		Store:      dbreplica.Store{},
+		Open:       backend.Open,
+		TypeOf:     backend.TypeOf,
+		Checksum:   backend.Checksum,
+		Quarantine: backend.Quarantine,
+		GetTier:    dbtier.GetTier,
+		Record:     dbscrub.Record,
+		LastCRC64:  dbscrub.LastCRC64,
+		Repair:     dbreplica.Repair,
+		Alert:      dbscrub.Alert,
+		Limiter:    scrub.NewLimiter(config.ScrubBytesPerSecond),
+	}
+}
+
+func main() {
+	b := mq.Default()
+	jobs := dbqueue.NewClient(b)
+	dbreplica.Setup(jobs)
+	w := job.NewWorker(dbqueue.Store{}, job.BrokerPublisher(b), dbqueue.QueueLease)
+	tasks.Register(w, newTransferer())
+	tasks.RegisterRepair(w, newRepairer())
+	tasks.RegisterTier(w, newTierer())
+	tasks.RegisterScrub(w, newScrubber())
+
+	log.Println("任务服务启动中，开始监听任务队列...")
+	go job.Consume(context.Background(), b, w)
+	go startScheduler(w)
+	go startTierScan(jobs)
+	go startScrubScan(jobs)
+	go startKeyRewrap()
+	go startLegacyTransferConsumer(b, jobs)
+
+	// rpc 服务
+	startRPCService()
+}
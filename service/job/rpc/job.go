@@ -0,0 +1,206 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	"github.com/cloud/job"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	jobProto "github.com/cloud/service/job/proto"
+	"github.com/cloud/store/tier"
+)
+
+// Job : 任务查询rpc
+type Job struct{}
+
+// JobType : 任务类型的描述
+type JobType struct {
+	Name        string
+	Priority    int
+	Concurrency int
+	MaxAttempts int
+	// Timeout : 单次执行的超时时间(秒)
+	Timeout int64
+	// Backoff : 重试等待时间(秒)
+	Backoff []int64
+	Schema  *job.Schema
+}
+
+// GetJob : 按ID查询任务
+func (j *Job) GetJob(ctx context.Context, req *jobProto.ReqGetJob, res *jobProto.RespGetJob) error {
+	dbResp, err := dbcli.GetJob(req.Id)
+	if err != nil {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+	if !dbResp.Suc {
+		res.Code = common.StatusParamInvalid
+		res.Message = dbResp.Msg
+		return nil
+	}
+
+	data, err := json.Marshal(dbcli.ToTableJob(dbResp.Data))
+	if err != nil {
+		res.Code = common.StatusServerError
+		return nil
+	}
+	res.Code = common.StatusOK
+	res.JobData = data
+	return nil
+}
+
+// ListJobs : 按ID倒序查询任务
+func (j *Job) ListJobs(ctx context.Context, req *jobProto.ReqListJobs, res *jobProto.RespListJobs) error {
+	limit := int(req.Limit)
+	if limit <= 0 || limit > config.JobListLimit {
+		limit = config.JobListLimit
+	}
+	dbResp, err := dbcli.ListJobs(req.Type, int(req.Status), limit)
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+
+	data, err := json.Marshal(dbcli.ToTableJobs(dbResp.Data))
+	if err != nil {
+		res.Code = common.StatusServerError
+		return nil
+	}
+	res.Code = common.StatusOK
+	res.JobData = data
+	return nil
+}
+
+// ListJobTypes : 获取已定义的任务类型, 包括执行选项及参数的JSON Schema
+func (j *Job) ListJobTypes(ctx context.Context, req *jobProto.ReqListJobTypes, res *jobProto.RespListJobTypes) error {
+	types := []JobType{}
+	for _, def := range job.Definitions() {
+		t := JobType{
+			Name:        def.Name,
+			Priority:    def.Priority,
+			Concurrency: def.Concurrency,
+			MaxAttempts: def.MaxAttempts,
+			Timeout:     int64(def.Timeout.Seconds()),
+			Schema:      def.Schema(),
+		}
+		for _, b := range def.Options.Backoff {
+			t.Backoff = append(t.Backoff, int64(b.Seconds()))
+		}
+		types = append(types, t)
+	}
+
+	data, err := json.Marshal(types)
+	if err != nil {
+		res.Code = common.StatusServerError
+		return nil
+	}
+	res.Code = common.StatusOK
+	res.TypeData = data
+	return nil
+}
+
+// TierUsage : 存储层级的用量
+type TierUsage struct {
+	Tier      common.StorageTier
+	Name      string
+	FileCount int64
+	TotalSize int64
+	// Capacity : 层级的容量(字节), 0表示不限
+	Capacity int64
+}
+
+// TierUsage : 获取各存储层级的文件数、占用空间及容量, 没有文件的层级也会返回
+func (j *Job) TierUsage(ctx context.Context, req *jobProto.ReqTierUsage, res *jobProto.RespTierUsage) error {
+	dbResp, err := dbcli.GetTierUsage()
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+
+	usages := []TierUsage{}
+	for _, t := range []common.StorageTier{common.TierHot, common.TierCold, common.TierArchive} {
+		usage := TierUsage{Tier: t, Name: tier.Name(t), Capacity: config.TierCapacity[t]}
+		for _, u := range dbcli.ToTableTierUsages(dbResp.Data) {
+			if common.StorageTier(u.Tier) == t {
+				usage.FileCount = u.FileCount
+				usage.TotalSize = u.TotalSize
+			}
+		}
+		usages = append(usages, usage)
+	}
+
+	data, err := json.Marshal(usages)
+	if err != nil {
+		res.Code = common.StatusServerError
+		return nil
+	}
+	res.Code = common.StatusOK
+	res.UsageData = data
+	return nil
+}
+
+// storeNames : 存储类型的名称
+var storeNames = map[common.StoreType]string{
+	common.StoreLocal:   "local",
+	common.StoreCeph:    "ceph",
+	common.StoreOSS:     "oss",
+	common.StoreErasure: "erasure",
+}
+
+// StorageUsage : 存储中已压缩内容的用量
+type StorageUsage struct {
+	StoreType common.StoreType
+	Name      string
+	// FileCount : 已压缩的文件数
+	FileCount int64
+	// LogicalSize : 已压缩文件的原始长度
+	LogicalSize int64
+	// StoredSize : 已压缩文件写入存储的长度
+	StoredSize int64
+	// SavedSize : 压缩节省的空间
+	SavedSize int64
+	// Ratio : 压缩后长度与原始长度之比, 没有已压缩的文件时为0
+	Ratio float64
+}
+
+// StorageUsage : 获取各存储中已压缩的文件数、原始长度、压缩后的长度及节省的空间,
+// 没有已压缩文件的存储也会返回
+func (j *Job) StorageUsage(ctx context.Context, req *jobProto.ReqStorageUsage, res *jobProto.RespStorageUsage) error {
+	dbResp, err := dbcli.GetCompressionUsage()
+	if err != nil || !dbResp.Suc {
+		res.Code = common.StatusServerError
+		res.Message = "服务错误"
+		return nil
+	}
+
+	usages := []StorageUsage{}
+	for _, t := range []common.StoreType{common.StoreLocal, common.StoreErasure, common.StoreCeph, common.StoreOSS} {
+		usage := StorageUsage{StoreType: t, Name: storeNames[t]}
+		for _, u := range dbcli.ToTableCompressionUsages(dbResp.Data) {
+			if common.StoreType(u.StoreType) == t {
+				usage.FileCount = u.FileCount
+				usage.LogicalSize = u.LogicalSize
+				usage.StoredSize = u.StoredSize
+			}
+		}
+		usage.SavedSize = usage.LogicalSize - usage.StoredSize
+		if usage.LogicalSize > 0 {
+			usage.Ratio = float64(usage.StoredSize) / float64(usage.LogicalSize)
+		}
+		usages = append(usages, usage)
+	}
+
+	data, err := json.Marshal(usages)
+	if err != nil {
+		res.Code = common.StatusServerError
+		return nil
+	}
+	res.Code = common.StatusOK
+	res.UsageData = data
+	return nil
+}
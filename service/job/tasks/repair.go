@@ -0,0 +1,150 @@
+package tasks
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/cloud/common"
+	"github.com/cloud/job"
+	"github.com/cloud/store/policy"
+	"github.com/cloud/store/replica"
+)
+
+// putFunc : 将文件内容写入存储类型t中的key, 如backend.Put
+type putFunc func(t common.StoreType, key string, r io.Reader) error
+
+// Repairer : 副本修复任务, 存储及数据库操作由调用方注入, 测试时可替换为内存实现
+type Repairer struct {
+	Store replica.Store
+	// Open : 打开存储中的数据
+	Open replica.Opener
+	// Put : 将文件内容写入存储类型t中的key
+	Put putFunc
+	// TypeOf : 按文件表中的位置判断存储类型
+	TypeOf func(location string) common.StoreType
+}
+
+// RegisterRepair : 注册副本修复任务
+func RegisterRepair(w *job.Worker, r *Repairer) {
+	w.Handle(job.TypeRepair, r.Repair)
+}
+
+// Repair : 修复文件缺失的副本, 指定存储策略时补齐策略中尚未记录的副本.
+// 各副本分别修复, 返回第一个失败的错误, 任务重试时只处理仍缺失的副本
+func (r *Repairer) Repair(ctx context.Context, payload interface{}) error {
+	data := payload.(*job.RepairPayload)
+
+	// 1 查询文件大小及已记录的副本
+	_, size, err := r.Store.File(data.FileHash)
+	if err != nil {
+		return err
+	}
+	replicas, err := r.Store.Locations(data.FileHash)
+	if err != nil {
+		return err
+	}
+
+	// 2 需要修复的副本: 缺失的副本及存储策略中未记录的存储
+	targets := []replica.Replica{}
+	recorded := map[common.StoreType]bool{}
+	for _, rep := range replicas {
+		recorded[rep.StoreType] = true
+		if rep.Status != common.LocationAvailable {
+			rep.Recorded = true
+			targets = append(targets, rep)
+		}
+	}
+	if data.Policy != "" {
+		for _, t := range policy.Targets(data.Policy) {
+			if !recorded[t] {
+				targets = append(targets, replica.Replica{StoreType: t, Location: policy.Location(t, data.FileHash)})
+			}
+		}
+	}
+
+	// 3 逐个修复
+	var firstErr error
+	for _, target := range targets {
+		if err := r.repair(data.FileHash, size, target); err != nil {
+			log.Printf("repair replica %s failed, err:%s\n", target.Location, err.Error())
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// repair : 已记录的副本仍可完整读取时(如之前只是存储暂时不可用)恢复为可用,
+// 否则按读取偏好从其他位置复制
+func (r *Repairer) repair(filehash string, size int64, target replica.Replica) error {
+	if target.Recorded && r.verify(target, filehash, size) == nil {
+		return r.Store.SetStatus(filehash, target.StoreType, common.LocationAvailable)
+	}
+
+	reader := &replica.Reader{Store: r.Store, OpenLocation: r.Open, TypeOf: r.TypeOf}
+	if err := copyFromReplicas(reader, r.Put, target, filehash, size); err != nil {
+		return err
+	}
+	return r.Store.AddLocation(filehash, target.StoreType, target.Location)
+}
+
+// copyFromReplicas : 按读取偏好依次尝试target所在存储之外的位置, 将文件复制到target
+func copyFromReplicas(reader *replica.Reader, put putFunc, target replica.Replica, filehash string, size int64) error {
+	sources, err := reader.Candidates(filehash)
+	if err != nil {
+		return err
+	}
+	lastErr := replica.ErrNoReplica
+	for _, src := range sources {
+		if src.StoreType == target.StoreType {
+			continue
+		}
+		if lastErr = copyReplica(reader.OpenLocation, put, src, target, filehash, size); lastErr == nil {
+			return nil
+		}
+		log.Printf("copy replica from %s failed, err:%s\n", src.Location, lastErr.Error())
+	}
+	return lastErr
+}
+
+// verify : 读取副本的全部内容并校验sha1
+func (r *Repairer) verify(rep replica.Replica, filehash string, size int64) error {
+	rc, err := r.Open(rep.StoreType, rep.Location, 0, size)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	h := sha1.New()
+	if _, err := io.Copy(h, rc); err != nil {
+		return err
+	}
+	return checkSha1(h.Sum(nil), filehash, rep.Location)
+}
+
+// copyReplica : 将src的内容写入dst, 写入时校验sha1, 不一致时返回错误(dst需要重新写入)
+func copyReplica(open replica.Opener, put putFunc, src, dst replica.Replica, filehash string, size int64) error {
+	rc, err := open(src.StoreType, src.Location, 0, size)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	h := sha1.New()
+	if err := put(dst.StoreType, dst.Location, io.TeeReader(rc, h)); err != nil {
+		return err
+	}
+	return checkSha1(h.Sum(nil), filehash, src.Location)
+}
+
+func checkSha1(sum []byte, filehash, location string) error {
+	if hex.EncodeToString(sum) != filehash {
+		return fmt.Errorf("content of %s does not match sha1 %s", location, filehash)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package tasks
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	"github.com/cloud/job"
+	"github.com/cloud/store/replica"
+	"github.com/cloud/store/scrub"
+)
+
+// Scrubber : 完整性检查任务, 存储及数据库操作由调用方注入, 测试时可替换为内存实现
+type Scrubber struct {
+	Store replica.Store
+	// Open : 打开存储中的数据
+	Open replica.Opener
+	// TypeOf : 按文件表中的位置判断存储类型
+	TypeOf func(location string) common.StoreType
+	// Checksum : 存储中记录的对象CRC64, 不提供时返回空字符串
+	Checksum func(t common.StoreType, key string) (string, error)
+	// Quarantine : 隔离内容损坏的数据(如移走本地文件)
+	Quarantine func(t common.StoreType, key string) error
+	// GetTier : 文件当前的层级, 归档文件的OSS副本不读取内容
+	GetTier func(filehash string) (common.StorageTier, int64, error)
+	// Record : 记录位置的检查结果, checksum为读取到的内容的sha1, crc为内容一致时的CRC64
+	Record func(filehash string, rep replica.Replica, result common.ScrubResult, checksum, crc string) error
+	// LastCRC64 : 各存储中的位置最近一次检查一致时记录的CRC64
+	LastCRC64 func(filehash string) (map[common.StoreType]string, error)
+	// Repair : 有副本缺失或损坏时调用(如提交修复任务)
+	Repair func(filehash string)
+	// Alert : 发现损坏的副本时告警
+	Alert func(alert scrub.Alert)
+	// Limiter : 读取速率限制, 为空时不限制
+	Limiter *scrub.Limiter
+}
+
+// RegisterScrub : 注册完整性检查任务
+func RegisterScrub(w *job.Worker, s *Scrubber) {
+	w.Handle(job.TypeScrub, s.Scrub)
+}
+
+// Scrub : 检查文件表中的位置及各可用副本的内容. 读取失败的副本标记为缺失,
+// 内容不一致的副本隔离并告警, 之后提交修复任务. 只有数据库等操作失败时返回错误
+func (s *Scrubber) Scrub(ctx context.Context, payload interface{}) error {
+	data := payload.(*job.ScrubPayload)
+
+	// 1 查询文件表中的位置、已记录的副本、层级及上次检查的CRC64
+	location, size, err := s.Store.File(data.FileHash)
+	if err != nil {
+		return err
+	}
+	replicas, err := s.Store.Locations(data.FileHash)
+	if err != nil {
+		return err
+	}
+	current, _, err := s.GetTier(data.FileHash)
+	if err != nil {
+		return err
+	}
+	crcs, err := s.LastCRC64(data.FileHash)
+	if err != nil {
+		return err
+	}
+
+	// 2 待检查的位置: 可用的副本, 以及没有副本记录的文件表中的位置
+	targets := []replica.Replica{}
+	recorded := false
+	for _, rep := range replicas {
+		if rep.Location == location {
+			recorded = true
+		}
+		if rep.Status == common.LocationAvailable {
+			rep.Recorded = true
+			targets = append(targets, rep)
+		}
+	}
+	if location != "" && !recorded {
+		targets = append(targets, replica.Replica{StoreType: s.TypeOf(location), Location: location})
+	}
+
+	// 3 逐个检查, 损坏的副本在全部检查后处理, 以便文件表中的位置切换到确认一致的副本
+	repair := false
+	healthy := []replica.Replica{}
+	corrupt := []replica.Replica{}
+	checksums := map[string]string{}
+	for _, target := range targets {
+		result, checksum, crc := s.check(data.FileHash, size, target, current, crcs[target.StoreType])
+		if err := s.Record(data.FileHash, target, result, checksum, crc); err != nil {
+			return err
+		}
+		switch result {
+		case common.ScrubOK:
+			healthy = append(healthy, target)
+		case common.ScrubUnreadable:
+			if target.Recorded {
+				if err := s.Store.SetStatus(data.FileHash, target.StoreType, common.LocationMissing); err != nil {
+					return err
+				}
+				repair = true
+			}
+		case common.ScrubMismatch:
+			corrupt = append(corrupt, target)
+			checksums[target.Location] = checksum
+		}
+	}
+
+	// 4 隔离损坏的副本并告警
+	for _, bad := range corrupt {
+		quarantined, err := s.quarantine(data.FileHash, bad, healthy)
+		if err != nil {
+			return err
+		}
+		repair = repair || quarantined
+		msg := "content does not match sha1, replica quarantined"
+		if !quarantined {
+			msg = "content does not match sha1, no healthy replica to fail over to"
+		}
+		s.Alert(scrub.Alert{
+			FileHash:  data.FileHash,
+			StoreType: int(bad.StoreType),
+			Location:  bad.Location,
+			Checksum:  checksums[bad.Location],
+			Message:   msg,
+			Time:      time.Now().Unix(),
+		})
+	}
+
+	if repair {
+		s.Repair(data.FileHash)
+	}
+	return nil
+}
+
+// check : 检查一个位置, 返回结果、读取到的内容的sha1及内容一致时的CRC64.
+// 归档文件的OSS副本无法直接读取, 与开启config.ScrubTrustOSSChecksum时一样只比较CRC64;
+// 已加密的OSS副本没有明文的CRC64, 归档时跳过, 否则读取内容检查
+func (s *Scrubber) check(filehash string, size int64, target replica.Replica,
+	current common.StorageTier, lastCRC string) (common.ScrubResult, string, string) {
+	if target.StoreType == common.StoreOSS {
+		archived := current == common.TierArchive
+		if archived || (config.ScrubTrustOSSChecksum && lastCRC != "") {
+			if lastCRC == "" {
+				return common.ScrubSkipped, "", ""
+			}
+			crc, err := s.Checksum(target.StoreType, target.Location)
+			if err != nil {
+				log.Printf("scrub %s failed, err:%s\n", target.Location, err.Error())
+				return common.ScrubUnreadable, "", ""
+			}
+			switch {
+			case crc == "" && archived:
+				// 已加密的对象没有明文的CRC64, 归档时无法检查
+				return common.ScrubSkipped, "", ""
+			case crc == "":
+				// 已加密的对象读取内容检查
+			case crc != lastCRC:
+				return common.ScrubMismatch, "", ""
+			default:
+				return common.ScrubOK, "", crc
+			}
+		}
+	}
+
+	rc, err := s.Open(target.StoreType, target.Location, 0, size)
+	if err != nil {
+		log.Printf("scrub %s failed, err:%s\n", target.Location, err.Error())
+		return common.ScrubUnreadable, "", ""
+	}
+	defer rc.Close()
+	sum, crc, err := scrub.Digest(s.Limiter.Reader(rc))
+	if err != nil {
+		log.Printf("scrub %s failed, err:%s\n", target.Location, err.Error())
+		return common.ScrubUnreadable, "", ""
+	}
+	if sum != filehash {
+		return common.ScrubMismatch, sum, ""
+	}
+	return common.ScrubOK, sum, crc
+}
+
+// quarantine : 隔离损坏的位置, 返回是否已隔离(需要修复). 没有确认一致的副本时保留原状.
+// 副本标记为损坏后不再读取, 由修复任务重新写入; 损坏的是文件表中的本地文件时,
+// 先将文件表指向确认一致的副本再移走本地文件
+func (s *Scrubber) quarantine(filehash string, bad replica.Replica, healthy []replica.Replica) (bool, error) {
+	replica.Sort(healthy)
+	var good *replica.Replica
+	for i := range healthy {
+		if healthy[i].Recorded {
+			good = &healthy[i]
+			break
+		}
+	}
+	if good == nil {
+		return false, nil
+	}
+
+	if !bad.Recorded && bad.StoreType != common.StoreLocal {
+		// 没有副本记录的旧文件, 先记录为副本以便标记损坏及修复
+		if err := s.Store.AddLocation(filehash, bad.StoreType, bad.Location); err != nil {
+			return false, err
+		}
+		bad.Recorded = true
+	}
+	if bad.Recorded {
+		if err := s.Store.SetStatus(filehash, bad.StoreType, common.LocationCorrupt); err != nil {
+			return false, err
+		}
+		return true, s.Quarantine(bad.StoreType, bad.Location)
+	}
+
+	if err := s.Store.UpdateLocation(filehash, good.Location); err != nil {
+		return false, err
+	}
+	return true, s.Quarantine(bad.StoreType, bad.Location)
+}
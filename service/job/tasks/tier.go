@@ -0,0 +1,191 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	"github.com/cloud/job"
+	"github.com/cloud/store/policy"
+	"github.com/cloud/store/replica"
+	"github.com/cloud/store/tier"
+)
+
+// Tierer : 文件层级任务, 存储及数据库操作由调用方注入, 测试时可替换为内存实现
+type Tierer struct {
+	Store replica.Store
+	// Open : 打开存储中的数据
+	Open replica.Opener
+	// Put : 将文件内容写入存储类型t中的key
+	Put putFunc
+	// TypeOf : 按文件表中的位置判断存储类型
+	TypeOf func(location string) common.StoreType
+	// Remove : 删除存储中的数据, 数据不存在时不返回错误
+	Remove func(t common.StoreType, key string) error
+	// SetStorageClass : 将OSS对象的存储类型改为层级对应的类型
+	SetStorageClass func(key string, t common.StorageTier) error
+	// Restore : 返回OSS对象是否可读取, 未解冻的归档对象发起解冻
+	Restore func(key string) (bool, error)
+	// GetTier : 文件当前的层级及最后访问时间(unix秒)
+	GetTier func(filehash string) (common.StorageTier, int64, error)
+	// SetTier : 更新文件的层级
+	SetTier func(filehash string, t common.StorageTier) error
+	// Now : 当前时间, 为空时使用time.Now
+	Now func() time.Time
+}
+
+// RegisterTier : 注册文件层级任务
+func RegisterTier(w *job.Worker, t *Tierer) {
+	w.Handle(job.TypeTier, t.Tier)
+}
+
+// Tier : 将文件降级到低频访问/归档, 或升级到热存储. 降级任务执行时文件已被访问(不再满足降级条件)
+// 或已在目标层级时不做处理
+func (t *Tierer) Tier(ctx context.Context, payload interface{}) error {
+	data := payload.(*job.TierPayload)
+
+	current, lastAccess, err := t.GetTier(data.FileHash)
+	if err != nil {
+		return err
+	}
+	if data.Tier == common.TierHot {
+		if current == common.TierHot {
+			return nil
+		}
+		return t.promote(data.FileHash, current)
+	}
+
+	now := time.Now()
+	if t.Now != nil {
+		now = t.Now()
+	}
+	if current >= data.Tier || !tier.Due(data.Tier, lastAccess, now) {
+		return nil
+	}
+	return t.demote(data.FileHash, data.Tier)
+}
+
+// demote : 确保OSS上有可用的副本并修改其存储类型, 文件表指向该副本后删除其余副本及本地文件
+func (t *Tierer) demote(filehash string, target common.StorageTier) error {
+	// 1 查询文件大小及已记录的副本
+	location, size, err := t.Store.File(filehash)
+	if err != nil {
+		return err
+	}
+	replicas, err := t.Store.Locations(filehash)
+	if err != nil {
+		return err
+	}
+
+	// 2 OSS上没有可用的副本时从其他位置复制
+	dst := replica.Replica{StoreType: common.StoreOSS, Location: policy.Location(common.StoreOSS, filehash)}
+	copied := false
+	for _, rep := range replicas {
+		if rep.StoreType == common.StoreOSS && rep.Status == common.LocationAvailable {
+			dst.Location = rep.Location
+			copied = true
+		}
+	}
+	if !copied {
+		reader := &replica.Reader{Store: t.Store, OpenLocation: t.Open, TypeOf: t.TypeOf}
+		if err := copyFromReplicas(reader, t.Put, dst, filehash, size); err != nil {
+			return err
+		}
+		if err := t.Store.AddLocation(filehash, dst.StoreType, dst.Location); err != nil {
+			return err
+		}
+	}
+
+	// 3 修改OSS对象的存储类型, 文件表指向OSS上的副本
+	if err := t.SetStorageClass(dst.Location, target); err != nil {
+		return err
+	}
+	if location != dst.Location {
+		if err := t.Store.UpdateLocation(filehash, dst.Location); err != nil {
+			return err
+		}
+	}
+
+	// 4 删除其余副本及本地文件
+	for _, rep := range replicas {
+		if rep.StoreType == common.StoreOSS {
+			continue
+		}
+		if err := t.Remove(rep.StoreType, rep.Location); err != nil {
+			return err
+		}
+		if err := t.Store.RemoveLocation(filehash, rep.StoreType); err != nil {
+			return err
+		}
+	}
+	local := policy.Location(common.StoreLocal, filehash)
+	if err := t.Remove(common.StoreLocal, local); err != nil {
+		return err
+	}
+	if location != local && location != dst.Location && t.TypeOf(location) == common.StoreLocal {
+		if err := t.Remove(common.StoreLocal, location); err != nil {
+			return err
+		}
+	}
+
+	return t.SetTier(filehash, target)
+}
+
+// promote : 将OSS上的副本(归档的先解冻)复制到config.TierHotStore并作为文件表中的位置,
+// OSS上的副本保留为低频访问
+func (t *Tierer) promote(filehash string, current common.StorageTier) error {
+	// 1 查询OSS上的副本
+	location, size, err := t.Store.File(filehash)
+	if err != nil {
+		return err
+	}
+	replicas, err := t.Store.Locations(filehash)
+	if err != nil {
+		return err
+	}
+	src := replica.Replica{StoreType: common.StoreOSS}
+	for _, rep := range replicas {
+		if rep.StoreType == common.StoreOSS {
+			src.Location = rep.Location
+		}
+	}
+	if src.Location == "" && t.TypeOf(location) == common.StoreOSS {
+		src.Location = location
+	}
+	if src.Location == "" {
+		return errors.New("no oss replica to promote: " + filehash)
+	}
+
+	// 2 归档的文件解冻完成前任务失败, 等待重试
+	ready, err := t.Restore(src.Location)
+	if err != nil {
+		return err
+	}
+	if !ready {
+		return tier.ErrRestoring
+	}
+
+	// 3 复制到热存储, 文件表指向热存储上的副本(本地文件不记录为副本)
+	dst := replica.Replica{StoreType: config.TierHotStore, Location: policy.Location(config.TierHotStore, filehash)}
+	if err := copyReplica(t.Open, t.Put, src, dst, filehash, size); err != nil {
+		return err
+	}
+	if dst.StoreType != common.StoreLocal {
+		if err := t.Store.AddLocation(filehash, dst.StoreType, dst.Location); err != nil {
+			return err
+		}
+	}
+	if err := t.Store.UpdateLocation(filehash, dst.Location); err != nil {
+		return err
+	}
+
+	// 4 归档的副本改为低频访问, 以便读取失败时切换
+	if current == common.TierArchive {
+		if err := t.SetStorageClass(src.Location, common.TierCold); err != nil {
+			return err
+		}
+	}
+	return t.SetTier(filehash, common.TierHot)
+}
@@ -0,0 +1,74 @@
+// Package tasks : 任务服务执行的任务类型
+package tasks
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+
+	"github.com/cloud/common"
+	"github.com/cloud/job"
+	"github.com/cloud/store/replica"
+)
+
+// Transferer : 文件转移任务, 存储及数据库操作由调用方注入, 测试时可替换为内存实现
+type Transferer struct {
+	// Open : 打开存储类型t中的key(读取已加密的本地文件), 为空时直接读取本地文件
+	Open replica.Opener
+	// Put : 将文件内容写入存储类型t中的key
+	Put func(t common.StoreType, key string, r io.Reader) error
+	// AddLocation : 记录文件在存储中的位置(主存储及副本)
+	AddLocation func(filehash string, t common.StoreType, location string) error
+	// UpdateLocation : 更新唯一文件表中文件的存储位置(只用于主存储)
+	UpdateLocation func(filehash, location string) error
+	// Transferred : 转移到主存储完成后调用(如为持有该文件的用户记录事件), 可为空
+	Transferred func(filehash string)
+}
+
+// Register : 注册本服务执行的任务类型
+func Register(w *job.Worker, t *Transferer) {
+	w.Handle(job.TypeTransfer, t.Transfer)
+}
+
+// Transfer : 将本地文件写入目标存储并记录其位置, 主存储还要更新唯一文件表中的存储位置
+func (t *Transferer) Transfer(ctx context.Context, payload interface{}) error {
+	data := payload.(*job.TransferPayload)
+
+	// 1 获取当前文件临时存储路径
+	var file io.ReadCloser
+	var err error
+	if t.Open != nil {
+		file, err = t.Open(common.StoreLocal, data.Location, 0, -1)
+	} else {
+		file, err = os.Open(data.Location)
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// 2 将文件写入目标存储
+	if err = t.Put(data.DestStoreType, data.DestLocation, bufio.NewReader(file)); err != nil {
+		return err
+	}
+
+	// 3 记录文件在目标存储中的位置
+	if err = t.AddLocation(data.FileHash, data.DestStoreType, data.DestLocation); err != nil {
+		return err
+	}
+	if data.Replica {
+		return nil
+	}
+
+	// 4 更新唯一文件表中文件的存储路径
+	if err = t.UpdateLocation(data.FileHash, data.DestLocation); err != nil {
+		return err
+	}
+
+	// 5 转移完成后的通知, 失败不影响转移结果
+	if t.Transferred != nil {
+		t.Transferred(data.FileHash)
+	}
+	return nil
+}
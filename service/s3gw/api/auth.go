@@ -0,0 +1,332 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/service/s3gw/config"
+	"github.com/cloud/util"
+)
+
+// AWS Signature Version 4 相关常量
+const (
+	signV4Algorithm = "AWS4-HMAC-SHA256"
+	iso8601Format   = "20060102T150405Z"
+	yyyymmdd        = "20060102"
+
+	unsignedPayload          = "UNSIGNED-PAYLOAD"
+	streamingPayload         = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	streamingPayloadTrailer  = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER"
+	streamingUnsignedTrailer = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+	emptySHA256              = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	// usernameKey : 认证通过后保存在gin.Context中的用户名
+	usernameKey = "s3_username"
+	// requestIDKey : 本次请求的ID, 随响应头及错误信息返回
+	requestIDKey = "s3_request_id"
+)
+
+// credential : Credential字段, 格式为 AccessKey/日期/region/服务名/aws4_request
+type credential struct {
+	accessKey string
+	date      string
+	region    string
+	service   string
+}
+
+// scope : 签名范围
+func (cred credential) scope() string {
+	return cred.date + "/" + cred.region + "/" + cred.service + "/aws4_request"
+}
+
+// signV4Request : 从请求中解析出的签名信息
+type signV4Request struct {
+	cred          credential
+	signedHeaders []string
+	signature     string
+	amzDate       time.Time
+	expires       time.Duration
+	payloadHash   string
+	presigned     bool
+}
+
+// SigV4Auth : S3请求认证拦截器, 校验SigV4签名并将AccessKey映射为用户名
+func SigV4Auth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID, _ := util.RandomHex(8)
+		c.Set(requestIDKey, strings.ToUpper(requestID))
+		c.Header("x-amz-request-id", strings.ToUpper(requestID))
+
+		// 1. 解析签名信息, 支持Authorization头及预签名URL两种方式
+		var sreq *signV4Request
+		var apiErr *APIError
+		authHeader := c.GetHeader("Authorization")
+		switch {
+		case strings.HasPrefix(authHeader, signV4Algorithm):
+			sreq, apiErr = parseAuthHeader(c.Request, authHeader)
+		case c.Query("X-Amz-Algorithm") == signV4Algorithm:
+			sreq, apiErr = parsePresignedQuery(c.Request)
+		case authHeader != "" || c.Query("AWSAccessKeyId") != "":
+			apiErr = ErrUnsupportedSignature
+		default:
+			// 不支持匿名访问
+			apiErr = ErrAccessDenied
+		}
+		if apiErr != nil {
+			writeError(c, apiErr)
+			return
+		}
+
+		// 2. 校验请求时间
+		now := time.Now().UTC()
+		if sreq.presigned {
+			if now.Before(sreq.amzDate.Add(-config.MaxClockSkew)) || now.After(sreq.amzDate.Add(sreq.expires)) {
+				writeError(c, ErrExpiredPresignRequest)
+				return
+			}
+		} else if d := now.Sub(sreq.amzDate); d > config.MaxClockSkew || d < -config.MaxClockSkew {
+			writeError(c, ErrRequestTimeTooSkewed)
+			return
+		}
+
+		// 3. 查询AccessKey对应的用户及SecretKey
+		dbResp, err := dbcli.GetAccessKey(sreq.cred.accessKey)
+		if err != nil || !dbResp.Suc {
+			writeError(c, ErrInternalError)
+			return
+		}
+		if dbResp.Data == nil {
+			writeError(c, ErrInvalidAccessKeyID)
+			return
+		}
+		accessKey := dbcli.ToTableAccessKey(dbResp.Data)
+
+		// 4. 计算并比对签名
+		signingKey := deriveSigningKey(accessKey.SecretKey, sreq.cred)
+		signature := calcSignature(signingKey, sreq.amzDate, sreq.cred,
+			canonicalRequest(c.Request, sreq))
+		if !hmac.Equal([]byte(signature), []byte(sreq.signature)) {
+			writeError(c, ErrSignatureDoesNotMatch)
+			return
+		}
+
+		// 5. 请求体校验: 签名只覆盖了声明的payload hash, 读取时需确认与实际内容一致
+		switch sreq.payloadHash {
+		case unsignedPayload:
+		case streamingPayload, streamingPayloadTrailer, streamingUnsignedTrailer:
+			decodedLen, err := strconv.ParseInt(c.GetHeader("X-Amz-Decoded-Content-Length"), 10, 64)
+			if err != nil {
+				writeError(c, ErrMissingSecurityHeader)
+				return
+			}
+			c.Request.Body = newChunkedReader(c.Request.Body, sreq.payloadHash != streamingUnsignedTrailer,
+				signingKey, sreq.amzDate, sreq.cred, signature)
+			c.Request.ContentLength = decodedLen
+		default:
+			if !sreq.presigned {
+				c.Request.Body = newSHA256Reader(c.Request.Body, sreq.payloadHash)
+			}
+		}
+
+		c.Set(usernameKey, accessKey.UserName)
+		c.Next()
+	}
+}
+
+// parseAuthHeader : 解析Authorization头
+// AWS4-HMAC-SHA256 Credential=AK/20200101/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-date, Signature=...
+func parseAuthHeader(r *http.Request, authHeader string) (*signV4Request, *APIError) {
+	fields := map[string]string{}
+	for _, kv := range strings.Split(strings.TrimPrefix(authHeader, signV4Algorithm), ",") {
+		pair := strings.SplitN(strings.TrimSpace(kv), "=", 2)
+		if len(pair) != 2 {
+			return nil, ErrAuthorizationMalformed
+		}
+		fields[pair[0]] = pair[1]
+	}
+
+	sreq := &signV4Request{
+		signature:   fields["Signature"],
+		payloadHash: r.Header.Get("X-Amz-Content-Sha256"),
+	}
+	if sreq.payloadHash == "" {
+		return nil, ErrMissingSecurityHeader
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	if amzDate == "" {
+		amzDate = r.Header.Get("Date")
+	}
+	var err error
+	if sreq.amzDate, err = time.Parse(iso8601Format, amzDate); err != nil {
+		if sreq.amzDate, err = http.ParseTime(amzDate); err != nil {
+			return nil, ErrMissingSecurityHeader
+		}
+	}
+	return sreq, parseSignParams(sreq, fields["Credential"], fields["SignedHeaders"])
+}
+
+// parsePresignedQuery : 解析预签名URL中的签名参数
+func parsePresignedQuery(r *http.Request) (*signV4Request, *APIError) {
+	query := parseQuery(r.URL.RawQuery)
+	sreq := &signV4Request{
+		signature:   query.Get("X-Amz-Signature"),
+		payloadHash: query.Get("X-Amz-Content-Sha256"),
+		presigned:   true,
+	}
+	if sreq.payloadHash == "" {
+		sreq.payloadHash = unsignedPayload
+	}
+
+	var err error
+	if sreq.amzDate, err = time.Parse(iso8601Format, query.Get("X-Amz-Date")); err != nil {
+		return nil, ErrAuthorizationMalformed
+	}
+	expires, err := strconv.ParseInt(query.Get("X-Amz-Expires"), 10, 64)
+	if err != nil || expires < 0 {
+		return nil, ErrAuthorizationMalformed
+	}
+	sreq.expires = time.Duration(expires) * time.Second
+	if sreq.expires > config.MaxPresignExpires {
+		return nil, ErrAuthorizationMalformed
+	}
+	return sreq, parseSignParams(sreq, query.Get("X-Amz-Credential"), query.Get("X-Amz-SignedHeaders"))
+}
+
+// parseSignParams : 解析Credential及SignedHeaders并校验签名范围
+func parseSignParams(sreq *signV4Request, cred, signedHeaders string) *APIError {
+	parts := strings.Split(cred, "/")
+	if len(parts) != 5 || parts[4] != "aws4_request" || sreq.signature == "" || signedHeaders == "" {
+		return ErrAuthorizationMalformed
+	}
+	sreq.cred = credential{
+		accessKey: parts[0],
+		date:      parts[1],
+		region:    parts[2],
+		service:   parts[3],
+	}
+	if sreq.cred.service != "s3" || sreq.cred.region != config.Region ||
+		sreq.cred.date != sreq.amzDate.UTC().Format(yyyymmdd) {
+		return ErrAuthorizationMalformed
+	}
+
+	sreq.signedHeaders = strings.Split(signedHeaders, ";")
+	for _, h := range sreq.signedHeaders {
+		if h == "host" {
+			return nil
+		}
+	}
+	// host必须参与签名
+	return ErrAuthorizationMalformed
+}
+
+// canonicalRequest : 按SigV4规则构造规范请求
+func canonicalRequest(r *http.Request, sreq *signV4Request) string {
+	// 规范化的请求头
+	var headers strings.Builder
+	for _, name := range sreq.signedHeaders {
+		var values []string
+		switch name {
+		case "host":
+			values = []string{r.Host}
+		case "content-length":
+			values = []string{strconv.FormatInt(r.ContentLength, 10)}
+		case "transfer-encoding":
+			values = r.TransferEncoding
+		default:
+			values = r.Header[http.CanonicalHeaderKey(name)]
+		}
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		headers.WriteString(name + ":" + strings.Join(trimmed, ",") + "\n")
+	}
+
+	// 规范化的查询参数, 预签名请求不包含签名本身
+	query := parseQuery(r.URL.RawQuery)
+	if sreq.presigned {
+		query.Del("X-Amz-Signature")
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	params := []string{}
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		for _, v := range values {
+			params = append(params, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+
+	path := r.URL.Path
+	if path == "" {
+		path = "/"
+	}
+	return strings.Join([]string{
+		r.Method,
+		uriEncode(path, false),
+		strings.Join(params, "&"),
+		headers.String(),
+		strings.Join(sreq.signedHeaders, ";"),
+		sreq.payloadHash,
+	}, "\n")
+}
+
+// deriveSigningKey : 根据SecretKey及签名范围派生签名密钥
+func deriveSigningKey(secretKey string, cred credential) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretKey), cred.date)
+	key = hmacSHA256(key, cred.region)
+	key = hmacSHA256(key, cred.service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+// calcSignature : 计算规范请求的签名
+func calcSignature(signingKey []byte, amzDate time.Time, cred credential, canonicalReq string) string {
+	sum := sha256.Sum256([]byte(canonicalReq))
+	stringToSign := signV4Algorithm + "\n" +
+		amzDate.UTC().Format(iso8601Format) + "\n" +
+		cred.scope() + "\n" +
+		hex.EncodeToString(sum[:])
+	return hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// uriEncode : 按SigV4规则编码, 除非保留字符外全部转义; 路径中的"/"不转义
+func uriEncode(s string, encodeSlash bool) string {
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9') ||
+			ch == '-' || ch == '_' || ch == '.' || ch == '~' || (ch == '/' && !encodeSlash) {
+			buf.WriteByte(ch)
+			continue
+		}
+		buf.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{ch})))
+	}
+	return buf.String()
+}
+
+// parseQuery : 解析查询参数, 与url.ParseQuery不同的是"+"不会被当作空格
+func parseQuery(rawQuery string) url.Values {
+	query, _ := url.ParseQuery(strings.Replace(rawQuery, "+", "%2B", -1))
+	return query
+}
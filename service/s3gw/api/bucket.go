@@ -0,0 +1,384 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/service/s3gw/config"
+)
+
+const (
+	// s3Namespace : S3响应体的XML命名空间
+	s3Namespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+	// emptySHA1 : 空内容的sha1, 用作bucket目录标记的文件hash
+	emptySHA1 = "da39a3ee5e6b4b0d3255bfef95601890afd80709"
+	// s3TimeFormat : 响应体中的时间格式
+	s3TimeFormat = "2006-01-02T15:04:05.000Z"
+)
+
+// owner : 对象及bucket的所有者
+type owner struct {
+	ID          string `xml:"ID"`
+	DisplayName string `xml:"DisplayName"`
+}
+
+// listAllMyBucketsResult : ListBuckets响应
+type listAllMyBucketsResult struct {
+	XMLName xml.Name     `xml:"ListAllMyBucketsResult"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Owner   owner        `xml:"Owner"`
+	Buckets []bucketInfo `xml:"Buckets>Bucket"`
+}
+
+type bucketInfo struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+// listBucketResult : ListObjects(V1/V2)响应
+type listBucketResult struct {
+	XMLName               xml.Name       `xml:"ListBucketResult"`
+	Xmlns                 string         `xml:"xmlns,attr"`
+	Name                  string         `xml:"Name"`
+	Prefix                string         `xml:"Prefix"`
+	Marker                string         `xml:"Marker,omitempty"`
+	NextMarker            string         `xml:"NextMarker,omitempty"`
+	StartAfter            string         `xml:"StartAfter,omitempty"`
+	ContinuationToken     string         `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string         `xml:"NextContinuationToken,omitempty"`
+	KeyCount              int            `xml:"KeyCount"`
+	MaxKeys               int            `xml:"MaxKeys"`
+	Delimiter             string         `xml:"Delimiter,omitempty"`
+	EncodingType          string         `xml:"EncodingType,omitempty"`
+	IsTruncated           bool           `xml:"IsTruncated"`
+	Contents              []objectInfo   `xml:"Contents"`
+	CommonPrefixes        []commonPrefix `xml:"CommonPrefixes"`
+}
+
+type objectInfo struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+	Owner        *owner `xml:"Owner,omitempty"`
+}
+
+type commonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+// ListBucketsHandler : 列出用户的全部bucket(即顶层目录)
+func ListBucketsHandler(c *gin.Context) {
+	username := c.GetString(usernameKey)
+
+	dbResp, err := dbcli.ListUserFolders(username)
+	if err != nil || !dbResp.Suc {
+		writeError(c, ErrInternalError)
+		return
+	}
+
+	result := listAllMyBucketsResult{
+		Xmlns:   s3Namespace,
+		Owner:   owner{ID: username, DisplayName: username},
+		Buckets: []bucketInfo{},
+	}
+	for _, folder := range dbcli.ToTableUserFolders(dbResp.Data) {
+		result.Buckets = append(result.Buckets, bucketInfo{
+			Name:         folder.FolderName,
+			CreationDate: formatDBTime(folder.CreateAt, s3TimeFormat),
+		})
+	}
+	writeXML(c, http.StatusOK, result)
+}
+
+// CreateBucketHandler : 创建bucket, 即在用户文件表中写入一个空的目录标记"bucket/"
+func CreateBucketHandler(c *gin.Context) {
+	username := c.GetString(usernameKey)
+	bucket := c.GetString(bucketKey)
+
+	exists, apiErr := bucketExists(username, bucket)
+	if apiErr != nil {
+		writeError(c, apiErr)
+		return
+	}
+	if exists {
+		writeError(c, ErrBucketAlreadyOwnedByYou)
+		return
+	}
+
+	dbResp, err := dbcli.OnUserFileUploadFinished(username, dbcli.FileMeta{
+		FileSha1: emptySHA1,
+		FileName: objectName(bucket, ""),
+	})
+	if err != nil || !dbResp.Suc {
+		writeError(c, ErrInternalError)
+		return
+	}
+	c.Header("Location", "/"+bucket)
+	c.Status(http.StatusOK)
+}
+
+// HeadBucketHandler : 判断bucket是否存在
+func HeadBucketHandler(c *gin.Context) {
+	exists, apiErr := bucketExists(c.GetString(usernameKey), c.GetString(bucketKey))
+	if apiErr != nil {
+		writeError(c, apiErr)
+		return
+	}
+	if !exists {
+		writeError(c, ErrNoSuchBucket)
+		return
+	}
+	c.Header("x-amz-bucket-region", config.Region)
+	c.Status(http.StatusOK)
+}
+
+// GetBucketLocationHandler : 返回bucket所在的region
+func GetBucketLocationHandler(c *gin.Context) {
+	exists, apiErr := bucketExists(c.GetString(usernameKey), c.GetString(bucketKey))
+	if apiErr != nil {
+		writeError(c, apiErr)
+		return
+	}
+	if !exists {
+		writeError(c, ErrNoSuchBucket)
+		return
+	}
+
+	// us-east-1 按S3约定返回空的LocationConstraint
+	location := config.Region
+	if location == "us-east-1" {
+		location = ""
+	}
+	writeXML(c, http.StatusOK, struct {
+		XMLName  xml.Name `xml:"LocationConstraint"`
+		Xmlns    string   `xml:"xmlns,attr"`
+		Location string   `xml:",chardata"`
+	}{Xmlns: s3Namespace, Location: location})
+}
+
+// DeleteBucketHandler : 删除空的bucket(即删除目录标记)
+func DeleteBucketHandler(c *gin.Context) {
+	username := c.GetString(usernameKey)
+	bucket := c.GetString(bucketKey)
+
+	files, apiErr := listBucketFiles(username, bucket, 2)
+	if apiErr != nil {
+		writeError(c, apiErr)
+		return
+	}
+	if len(files) == 0 {
+		writeError(c, ErrNoSuchBucket)
+		return
+	}
+	for _, name := range files {
+		if name != objectName(bucket, "") {
+			writeError(c, ErrBucketNotEmpty)
+			return
+		}
+	}
+
+	dbResp, err := dbcli.DeleteUserFileByName(username, objectName(bucket, ""))
+	if err != nil || !dbResp.Suc {
+		writeError(c, ErrInternalError)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListObjectsHandler : 列出bucket内的对象, 同时支持ListObjects(V1)及ListObjectsV2
+func ListObjectsHandler(c *gin.Context) {
+	username := c.GetString(usernameKey)
+	bucket := c.GetString(bucketKey)
+	query := parseQuery(c.Request.URL.RawQuery)
+	isV2 := query.Get("list-type") == "2"
+
+	// 1. 解析分页参数
+	maxKeys := config.ListMaxKeys
+	if v := query.Get("max-keys"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(c, ErrInvalidArgument)
+			return
+		}
+		if n < maxKeys {
+			maxKeys = n
+		}
+	}
+	encodingType := query.Get("encoding-type")
+	if encodingType != "" && encodingType != "url" {
+		writeError(c, ErrInvalidArgument)
+		return
+	}
+
+	result := listBucketResult{
+		Xmlns:        s3Namespace,
+		Name:         bucket,
+		Prefix:       query.Get("prefix"),
+		Delimiter:    query.Get("delimiter"),
+		MaxKeys:      maxKeys,
+		EncodingType: encodingType,
+	}
+	marker := query.Get("marker")
+	if isV2 {
+		result.StartAfter = query.Get("start-after")
+		result.ContinuationToken = query.Get("continuation-token")
+		marker = result.StartAfter
+		if result.ContinuationToken != "" {
+			token, err := base64.StdEncoding.DecodeString(result.ContinuationToken)
+			if err != nil {
+				writeError(c, ErrInvalidArgument)
+				return
+			}
+			marker = string(token)
+		}
+	} else {
+		result.Marker = marker
+	}
+
+	// 2. 按字典序扫描, 遇到分隔符时合并为CommonPrefixes
+	exists, apiErr := bucketExists(username, bucket)
+	if apiErr != nil {
+		writeError(c, apiErr)
+		return
+	}
+	if !exists {
+		writeError(c, ErrNoSuchBucket)
+		return
+	}
+	next, apiErr := listObjects(username, bucket, marker, maxKeys, &result)
+	if apiErr != nil {
+		writeError(c, apiErr)
+		return
+	}
+	if result.IsTruncated {
+		if isV2 {
+			result.NextContinuationToken = base64.StdEncoding.EncodeToString([]byte(next))
+		} else if result.Delimiter != "" {
+			// 未指定分隔符时客户端以最后一个Key作为下一页的marker
+			result.NextMarker = next
+		}
+	}
+	result.KeyCount = len(result.Contents) + len(result.CommonPrefixes)
+
+	// 3. encoding-type=url时需对返回的key进行编码
+	if encodingType == "url" {
+		result.Prefix = uriEncode(result.Prefix, false)
+		result.Delimiter = uriEncode(result.Delimiter, false)
+		result.Marker = uriEncode(result.Marker, false)
+		result.NextMarker = uriEncode(result.NextMarker, false)
+		result.StartAfter = uriEncode(result.StartAfter, false)
+		for i := range result.Contents {
+			result.Contents[i].Key = uriEncode(result.Contents[i].Key, false)
+		}
+		for i := range result.CommonPrefixes {
+			result.CommonPrefixes[i].Prefix = uriEncode(result.CommonPrefixes[i].Prefix, false)
+		}
+	}
+	writeXML(c, http.StatusOK, result)
+}
+
+// listObjects : 从marker之后开始列出最多maxKeys个对象或公共前缀, 返回最后一项用于翻页
+func listObjects(username, bucket, marker string, maxKeys int, result *listBucketResult) (string, *APIError) {
+	base := objectName(bucket, "")
+	fullPrefix := base + result.Prefix
+	cursor := base + marker
+
+	// 上一页以公共前缀结束时, 跳过该前缀下的全部对象
+	skipPrefix := ""
+	if result.Delimiter != "" && len(marker) > len(result.Prefix) && strings.HasPrefix(marker, result.Prefix) {
+		rest := marker[len(result.Prefix):]
+		if strings.Index(rest, result.Delimiter) == len(rest)-len(result.Delimiter) {
+			skipPrefix = cursor
+		}
+	}
+
+	count := 0
+	next := ""
+	for {
+		dbResp, err := dbcli.ListUserFilesByPrefix(username, fullPrefix, cursor, config.ListMaxKeys)
+		if err != nil || !dbResp.Suc {
+			return "", ErrInternalError
+		}
+		files := dbcli.ToTableUserFiles(dbResp.Data)
+
+		for _, file := range files {
+			cursor = file.FileName
+			// like查询不区分大小写, 需再次按字节比较前缀
+			if !strings.HasPrefix(file.FileName, fullPrefix) || file.FileName == base ||
+				(skipPrefix != "" && strings.HasPrefix(file.FileName, skipPrefix)) {
+				continue
+			}
+			if count == maxKeys {
+				result.IsTruncated = true
+				return next, nil
+			}
+
+			key := strings.TrimPrefix(file.FileName, base)
+			if result.Delimiter != "" {
+				if i := strings.Index(key[len(result.Prefix):], result.Delimiter); i >= 0 {
+					prefix := key[:len(result.Prefix)+i+len(result.Delimiter)]
+					result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: prefix})
+					skipPrefix = base + prefix
+					next = prefix
+					count++
+					continue
+				}
+			}
+			result.Contents = append(result.Contents, objectInfo{
+				Key:          key,
+				LastModified: formatDBTime(file.UploadAt, s3TimeFormat),
+				ETag:         objectETag(&file),
+				Size:         file.FileSize,
+				StorageClass: "STANDARD",
+				Owner:        &owner{ID: username, DisplayName: username},
+			})
+			next = key
+			count++
+		}
+		if len(files) < config.ListMaxKeys {
+			return next, nil
+		}
+	}
+}
+
+// bucketExists : bucket存在即用户至少有一个文件名以"bucket/"开头的文件
+func bucketExists(username, bucket string) (bool, *APIError) {
+	files, apiErr := listBucketFiles(username, bucket, 1)
+	return len(files) > 0, apiErr
+}
+
+// listBucketFiles : 查询bucket下按字典序的前limit个文件名(包含目录标记)
+func listBucketFiles(username, bucket string, limit int) ([]string, *APIError) {
+	base := objectName(bucket, "")
+	dbResp, err := dbcli.ListUserFilesByPrefix(username, base, "", limit)
+	if err != nil || !dbResp.Suc {
+		return nil, ErrInternalError
+	}
+	names := []string{}
+	for _, file := range dbcli.ToTableUserFiles(dbResp.Data) {
+		if strings.HasPrefix(file.FileName, base) {
+			names = append(names, file.FileName)
+		}
+	}
+	return names, nil
+}
+
+// formatDBTime : 将数据库中的时间(本地时区)转换为UTC格式
+func formatDBTime(value, layout string) string {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+	if err != nil {
+		log.Println(err.Error())
+		t = time.Now()
+	}
+	return t.UTC().Format(layout)
+}
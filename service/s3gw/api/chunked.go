@@ -0,0 +1,223 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// errContentSHA256Mismatch : 请求体与x-amz-content-sha256不一致
+	errContentSHA256Mismatch = errors.New("x-amz-content-sha256 mismatch")
+	// errChunkSignatureMismatch : aws-chunked分块签名校验失败
+	errChunkSignatureMismatch = errors.New("chunk signature mismatch")
+	// errMalformedChunk : aws-chunked格式错误
+	errMalformedChunk = errors.New("malformed aws-chunked body")
+)
+
+// bodyError : 将读取请求体时的错误转换为S3错误
+func bodyError(err error) *APIError {
+	switch err {
+	case errContentSHA256Mismatch:
+		return ErrContentSHA256Mismatch
+	case errChunkSignatureMismatch:
+		return ErrSignatureDoesNotMatch
+	case errMalformedChunk, io.ErrUnexpectedEOF:
+		return ErrIncompleteBody
+	default:
+		return ErrInternalError
+	}
+}
+
+// sha256Reader : 读取到EOF时校验请求体的sha256
+type sha256Reader struct {
+	body io.ReadCloser
+	hash hash.Hash
+	want string
+}
+
+func newSHA256Reader(body io.ReadCloser, want string) io.ReadCloser {
+	return &sha256Reader{body: body, hash: sha256.New(), want: want}
+}
+
+func (r *sha256Reader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF && hex.EncodeToString(r.hash.Sum(nil)) != r.want {
+		return n, errContentSHA256Mismatch
+	}
+	return n, err
+}
+
+func (r *sha256Reader) Close() error {
+	return r.body.Close()
+}
+
+// chunkedReader : 解码aws-chunked格式的请求体, 每块格式为
+// hex(size);chunk-signature=sig\r\n data \r\n, 最后一块大小为0, 之后可能跟随trailer头
+type chunkedReader struct {
+	body   io.ReadCloser
+	reader *bufio.Reader
+	signed bool
+
+	signingKey []byte
+	amzDate    string
+	scope      string
+	prevSig    string
+
+	chunkSig  string
+	remaining int64
+	inChunk   bool
+	hash      hash.Hash
+	err       error
+}
+
+func newChunkedReader(body io.ReadCloser, signed bool, signingKey []byte,
+	amzDate time.Time, cred credential, seedSignature string) io.ReadCloser {
+	return &chunkedReader{
+		body:       body,
+		reader:     bufio.NewReader(body),
+		signed:     signed,
+		signingKey: signingKey,
+		amzDate:    amzDate.UTC().Format(iso8601Format),
+		scope:      cred.scope(),
+		prevSig:    seedSignature,
+		hash:       sha256.New(),
+	}
+}
+
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	for r.err == nil && r.remaining == 0 {
+		r.err = r.nextChunk()
+	}
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.reader.Read(p)
+	r.hash.Write(p[:n])
+	r.remaining -= int64(n)
+	if err == io.EOF {
+		// 块数据尚未读完
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		r.err = err
+	}
+	return n, err
+}
+
+func (r *chunkedReader) Close() error {
+	return r.body.Close()
+}
+
+// nextChunk : 结束当前块并读取下一块的头部, 读完最后一块时返回io.EOF
+func (r *chunkedReader) nextChunk() error {
+	if r.inChunk {
+		if err := r.readCRLF(); err != nil {
+			return err
+		}
+		if err := r.verifyChunk(); err != nil {
+			return err
+		}
+	}
+
+	line, err := r.readLine()
+	if err == io.EOF {
+		// 缺少大小为0的结束块
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	sizeStr, ext := line, ""
+	if i := strings.IndexByte(line, ';'); i >= 0 {
+		sizeStr, ext = line[:i], line[i+1:]
+	}
+	size, err := strconv.ParseInt(sizeStr, 16, 64)
+	if err != nil || size < 0 {
+		return errMalformedChunk
+	}
+	if r.signed {
+		if !strings.HasPrefix(ext, "chunk-signature=") {
+			return errMalformedChunk
+		}
+		r.chunkSig = strings.TrimPrefix(ext, "chunk-signature=")
+	}
+	r.hash.Reset()
+	r.inChunk = true
+	r.remaining = size
+	if size > 0 {
+		return nil
+	}
+
+	// 最后一块: 校验签名后跳过trailer(如x-amz-checksum-crc32), 直到空行
+	if err := r.verifyChunk(); err != nil {
+		return err
+	}
+	for {
+		line, err := r.readLine()
+		if err == io.EOF {
+			// 没有trailer的非标准客户端可能省略结尾空行
+			return io.EOF
+		}
+		if err != nil {
+			return err
+		}
+		if line == "" {
+			return io.EOF
+		}
+	}
+}
+
+// verifyChunk : 校验当前块的签名, 每块签名都以上一块的签名为种子
+func (r *chunkedReader) verifyChunk() error {
+	if !r.signed {
+		return nil
+	}
+	stringToSign := "AWS4-HMAC-SHA256-PAYLOAD\n" + r.amzDate + "\n" + r.scope + "\n" +
+		r.prevSig + "\n" + emptySHA256 + "\n" + hex.EncodeToString(r.hash.Sum(nil))
+	signature := hex.EncodeToString(hmacSHA256(r.signingKey, stringToSign))
+	if !hmac.Equal([]byte(signature), []byte(r.chunkSig)) {
+		return errChunkSignatureMismatch
+	}
+	r.prevSig = signature
+	return nil
+}
+
+// readLine : 读取一行并去掉结尾的\r\n, 单行长度受bufio缓冲区大小限制
+func (r *chunkedReader) readLine() (string, error) {
+	line, err := r.reader.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		return "", errMalformedChunk
+	}
+	if err != nil {
+		if err == io.EOF && len(line) > 0 {
+			return "", io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	return string(bytes.TrimRight(line, "\r\n")), nil
+}
+
+func (r *chunkedReader) readCRLF() error {
+	crlf := make([]byte, 2)
+	if _, err := io.ReadFull(r.reader, crlf); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	if crlf[0] != '\r' || crlf[1] != '\n' {
+		return errMalformedChunk
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cloud/service/s3gw/config"
+)
+
+const (
+	// bucketKey : 请求对应的bucket, 保存在gin.Context中
+	bucketKey = "s3_bucket"
+	// objectKey : 请求对应的对象key, 保存在gin.Context中
+	objectKey = "s3_key"
+)
+
+// S3Handler : S3以请求方法及查询参数区分操作, 所有请求统一在此分发
+func S3Handler(c *gin.Context) {
+	bucket, key := parseBucketKey(c.Request)
+	if bucket == "." || bucket == ".." {
+		writeError(c, ErrInvalidBucketName)
+		return
+	}
+	c.Set(bucketKey, bucket)
+	c.Set(objectKey, key)
+
+	query := parseQuery(c.Request.URL.RawQuery)
+	_, hasUploads := query["uploads"]
+	_, hasUploadID := query["uploadId"]
+	_, hasLocation := query["location"]
+	method := c.Request.Method
+
+	switch {
+	case bucket == "":
+		if method == http.MethodGet {
+			ListBucketsHandler(c)
+			return
+		}
+	case key == "":
+		switch {
+		case method == http.MethodGet && hasLocation:
+			GetBucketLocationHandler(c)
+			return
+		case method == http.MethodGet && len(query) > 0 && !isListQuery(query):
+			// 其他bucket子资源(acl, policy, versioning等)暂不支持
+			writeError(c, ErrNotImplemented)
+			return
+		case method == http.MethodGet:
+			ListObjectsHandler(c)
+			return
+		case method == http.MethodPut:
+			CreateBucketHandler(c)
+			return
+		case method == http.MethodHead:
+			HeadBucketHandler(c)
+			return
+		case method == http.MethodDelete:
+			DeleteBucketHandler(c)
+			return
+		}
+	default:
+		switch {
+		case method == http.MethodPost && hasUploads:
+			CreateMultipartUploadHandler(c)
+			return
+		case method == http.MethodPost && hasUploadID:
+			CompleteMultipartUploadHandler(c)
+			return
+		case method == http.MethodPut && hasUploadID:
+			UploadPartHandler(c)
+			return
+		case method == http.MethodDelete && hasUploadID:
+			AbortMultipartUploadHandler(c)
+			return
+		case method == http.MethodGet && hasUploadID:
+			// ListParts暂不支持
+			writeError(c, ErrNotImplemented)
+			return
+		case method == http.MethodGet:
+			GetObjectHandler(c)
+			return
+		case method == http.MethodHead:
+			HeadObjectHandler(c)
+			return
+		case method == http.MethodPut:
+			PutObjectHandler(c)
+			return
+		case method == http.MethodDelete:
+			DeleteObjectHandler(c)
+			return
+		}
+	}
+	writeError(c, ErrMethodNotAllowed)
+}
+
+// parseBucketKey : 从请求中解析bucket及key, 支持路径风格(/bucket/key)
+// 及配置了S3Domain时的虚拟主机风格(bucket.S3Domain/key)
+func parseBucketKey(r *http.Request) (string, string) {
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	if config.S3Domain != "" {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if strings.HasSuffix(host, "."+config.S3Domain) {
+			return strings.TrimSuffix(host, "."+config.S3Domain), path
+		}
+	}
+
+	parts := strings.SplitN(path, "/", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// isListQuery : 查询参数是否均为ListObjects的参数
+func isListQuery(query map[string][]string) bool {
+	for k := range query {
+		switch k {
+		case "list-type", "prefix", "delimiter", "max-keys", "marker", "start-after",
+			"continuation-token", "encoding-type", "fetch-owner":
+		default:
+			if !strings.HasPrefix(k, "X-Amz-") {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIError : S3错误码及对应的HTTP状态码
+type APIError struct {
+	Code       string
+	Message    string
+	StatusCode int
+}
+
+// S3协议定义的错误码(仅包含网关用到的部分)
+var (
+	ErrAccessDenied            = &APIError{"AccessDenied", "Access Denied.", http.StatusForbidden}
+	ErrInvalidAccessKeyID      = &APIError{"InvalidAccessKeyId", "The AWS access key Id you provided does not exist in our records.", http.StatusForbidden}
+	ErrSignatureDoesNotMatch   = &APIError{"SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided.", http.StatusForbidden}
+	ErrAuthorizationMalformed  = &APIError{"AuthorizationHeaderMalformed", "The authorization header is malformed.", http.StatusBadRequest}
+	ErrMissingSecurityHeader   = &APIError{"MissingSecurityHeader", "Your request is missing a required header.", http.StatusBadRequest}
+	ErrUnsupportedSignature    = &APIError{"NotImplemented", "Only AWS Signature Version 4 is supported.", http.StatusNotImplemented}
+	ErrRequestTimeTooSkewed    = &APIError{"RequestTimeTooSkewed", "The difference between the request time and the server's time is too large.", http.StatusForbidden}
+	ErrExpiredPresignRequest   = &APIError{"AccessDenied", "Request has expired.", http.StatusForbidden}
+	ErrContentSHA256Mismatch   = &APIError{"XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed.", http.StatusBadRequest}
+	ErrBadDigest               = &APIError{"BadDigest", "The Content-MD5 you specified did not match what we received.", http.StatusBadRequest}
+	ErrInvalidDigest           = &APIError{"InvalidDigest", "The Content-MD5 you specified is not valid.", http.StatusBadRequest}
+	ErrIncompleteBody          = &APIError{"IncompleteBody", "You did not provide the number of bytes specified by the Content-Length HTTP header.", http.StatusBadRequest}
+	ErrInvalidArgument         = &APIError{"InvalidArgument", "Invalid Argument.", http.StatusBadRequest}
+	ErrInvalidBucketName       = &APIError{"InvalidBucketName", "The specified bucket is not valid.", http.StatusBadRequest}
+	ErrNoSuchBucket            = &APIError{"NoSuchBucket", "The specified bucket does not exist.", http.StatusNotFound}
+	ErrBucketNotEmpty          = &APIError{"BucketNotEmpty", "The bucket you tried to delete is not empty.", http.StatusConflict}
+	ErrBucketAlreadyOwnedByYou = &APIError{"BucketAlreadyOwnedByYou", "Your previous request to create the named bucket succeeded and you already own it.", http.StatusConflict}
+	ErrNoSuchKey               = &APIError{"NoSuchKey", "The specified key does not exist.", http.StatusNotFound}
+	ErrKeyTooLong              = &APIError{"KeyTooLongError", "Your key is too long.", http.StatusBadRequest}
+	ErrInvalidRange            = &APIError{"InvalidRange", "The requested range is not satisfiable.", http.StatusRequestedRangeNotSatisfiable}
+	ErrNoSuchUpload            = &APIError{"NoSuchUpload", "The specified multipart upload does not exist.", http.StatusNotFound}
+	ErrInvalidPart             = &APIError{"InvalidPart", "One or more of the specified parts could not be found.", http.StatusBadRequest}
+	ErrInvalidPartOrder        = &APIError{"InvalidPartOrder", "The list of parts was not in ascending order.", http.StatusBadRequest}
+	ErrEntityTooSmall          = &APIError{"EntityTooSmall", "Your proposed upload is smaller than the minimum allowed object size.", http.StatusBadRequest}
+	ErrMalformedXML            = &APIError{"MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema.", http.StatusBadRequest}
+	ErrNotImplemented          = &APIError{"NotImplemented", "A header you provided implies functionality that is not implemented.", http.StatusNotImplemented}
+	ErrMethodNotAllowed        = &APIError{"MethodNotAllowed", "The specified method is not allowed against this resource.", http.StatusMethodNotAllowed}
+	ErrInternalError           = &APIError{"InternalError", "We encountered an internal error, please try again.", http.StatusInternalServerError}
+	ErrInvalidObjectState      = &APIError{"InvalidObjectState", "The object is archived and is being restored, please try again later.", http.StatusForbidden}
+)
+
+// errorResponse : S3错误响应体
+type errorResponse struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string   `xml:"Code"`
+	Message   string   `xml:"Message"`
+	Resource  string   `xml:"Resource"`
+	RequestID string   `xml:"RequestId"`
+}
+
+// writeError : 以S3格式返回错误并终止后续handler
+func writeError(c *gin.Context, apiErr *APIError) {
+	c.Abort()
+	// HEAD请求不允许携带响应体
+	if c.Request.Method == http.MethodHead {
+		c.Status(apiErr.StatusCode)
+		return
+	}
+	writeXML(c, apiErr.StatusCode, errorResponse{
+		Code:      apiErr.Code,
+		Message:   apiErr.Message,
+		Resource:  c.Request.URL.Path,
+		RequestID: c.GetString(requestIDKey),
+	})
+}
+
+// writeXML : 返回XML格式的响应体
+func writeXML(c *gin.Context, statusCode int, v interface{}) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Data(statusCode, "application/xml", append([]byte(xml.Header), data...))
+}
@@ -0,0 +1,334 @@
+package api
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomodule/redigo/redis"
+
+	rPool "github.com/cloud/cache/redis"
+	cmnCfg "github.com/cloud/config"
+	"github.com/cloud/service/s3gw/config"
+	"github.com/cloud/store/layout"
+	"github.com/cloud/util"
+)
+
+const (
+	// mpKeyPrefix : 与upload服务的分块上传共用MP_前缀, 使upload服务的janitor不会清理进行中的分块目录
+	mpKeyPrefix = "MP_"
+	// partFieldPrefix : 已上传分块在会话hash中的字段名前缀, 值为 etag:size
+	partFieldPrefix = "part_"
+	// maxCompleteBodySize : CompleteMultipartUpload请求体的大小限制
+	maxCompleteBodySize = 1 << 20
+)
+
+// initiateMultipartUploadResult : CreateMultipartUpload响应
+type initiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+// completeMultipartUpload : CompleteMultipartUpload请求体
+type completeMultipartUpload struct {
+	XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+	Parts   []completePart `xml:"Part"`
+}
+
+type completePart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// completeMultipartUploadResult : CompleteMultipartUpload响应
+type completeMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
+
+// s3Upload : redis中保存的分块上传会话
+type s3Upload struct {
+	uploadID string
+	parts    map[int]uploadedPart
+}
+
+// uploadedPart : 已上传的分块
+type uploadedPart struct {
+	etag string
+	size int64
+}
+
+// CreateMultipartUploadHandler : 初始化分块上传
+func CreateMultipartUploadHandler(c *gin.Context) {
+	username := c.GetString(usernameKey)
+	bucket := c.GetString(bucketKey)
+	key := c.GetString(objectKey)
+	if utf8.RuneCountInString(objectName(bucket, key)) > maxObjectNameLen {
+		writeError(c, ErrKeyTooLong)
+		return
+	}
+
+	uploadID, err := util.RandomHex(16)
+	if err != nil {
+		writeError(c, ErrInternalError)
+		return
+	}
+	if err := os.MkdirAll(layout.ChunkDir(uploadID), 0744); err != nil {
+		log.Println(err.Error())
+		writeError(c, ErrInternalError)
+		return
+	}
+
+	rConn := rPool.Pool().Get()
+	defer rConn.Close()
+
+	hkey := mpKeyPrefix + uploadID
+	rConn.Do("HSET", hkey, "protocol", "s3")
+	rConn.Do("HSET", hkey, "username", username)
+	rConn.Do("HSET", hkey, "filename", objectName(bucket, key))
+	rConn.Do("HSET", hkey, "createat", time.Now().Unix())
+	rConn.Do("EXPIRE", hkey, int(config.MultipartSessionTTL/time.Second))
+
+	writeXML(c, http.StatusOK, initiateMultipartUploadResult{
+		Xmlns:    s3Namespace,
+		Bucket:   bucket,
+		Key:      key,
+		UploadID: uploadID,
+	})
+}
+
+// UploadPartHandler : 上传分块, 每块以分块编号为文件名保存在分块目录中
+func UploadPartHandler(c *gin.Context) {
+	partNumber, err := strconv.Atoi(c.Query("partNumber"))
+	if err != nil || partNumber < 1 || partNumber > config.MultipartMaxParts {
+		writeError(c, ErrInvalidArgument)
+		return
+	}
+	contentMD5, apiErr := parseContentMD5(c)
+	if apiErr != nil {
+		writeError(c, apiErr)
+		return
+	}
+
+	rConn := rPool.Pool().Get()
+	defer rConn.Close()
+
+	upload, apiErr := loadUpload(c, rConn)
+	if apiErr != nil {
+		writeError(c, apiErr)
+		return
+	}
+
+	// 1. 先写入临时文件, 校验通过后再替换同编号的旧分块
+	partPath := layout.ChunkDir(upload.uploadID) + strconv.Itoa(partNumber)
+	fd, err := os.Create(partPath + ".tmp")
+	if err != nil {
+		log.Println(err.Error())
+		writeError(c, ErrInternalError)
+		return
+	}
+	md5Hash := md5.New()
+	size, err := io.Copy(io.MultiWriter(fd, md5Hash), c.Request.Body)
+	fd.Close()
+	if err == nil {
+		apiErr = verifyBody(c, size, md5Hash.Sum(nil), contentMD5)
+	} else {
+		log.Println(err.Error())
+		apiErr = bodyError(err)
+	}
+	if apiErr == nil && os.Rename(partPath+".tmp", partPath) != nil {
+		apiErr = ErrInternalError
+	}
+	if apiErr != nil {
+		os.Remove(partPath + ".tmp")
+		writeError(c, apiErr)
+		return
+	}
+
+	// 2. 记录分块信息并刷新会话有效期
+	etag := hex.EncodeToString(md5Hash.Sum(nil))
+	hkey := mpKeyPrefix + upload.uploadID
+	rConn.Do("HSET", hkey, partFieldPrefix+strconv.Itoa(partNumber), etag+":"+strconv.FormatInt(size, 10))
+	rConn.Do("EXPIRE", hkey, int(config.MultipartSessionTTL/time.Second))
+
+	c.Header("ETag", `"`+etag+`"`)
+	c.Status(http.StatusOK)
+}
+
+// CompleteMultipartUploadHandler : 按请求中的分块列表合并文件并保存为用户文件
+func CompleteMultipartUploadHandler(c *gin.Context) {
+	username := c.GetString(usernameKey)
+	bucket := c.GetString(bucketKey)
+	key := c.GetString(objectKey)
+
+	rConn := rPool.Pool().Get()
+	defer rConn.Close()
+
+	upload, apiErr := loadUpload(c, rConn)
+	if apiErr != nil {
+		writeError(c, apiErr)
+		return
+	}
+
+	// 1. 校验分块列表: 编号递增, etag一致, 除最后一块外不小于最小分块大小
+	var req completeMultipartUpload
+	if err := xml.NewDecoder(io.LimitReader(c.Request.Body, maxCompleteBodySize)).Decode(&req); err != nil ||
+		len(req.Parts) == 0 {
+		writeError(c, ErrMalformedXML)
+		return
+	}
+	for i, part := range req.Parts {
+		if i > 0 && part.PartNumber <= req.Parts[i-1].PartNumber {
+			writeError(c, ErrInvalidPartOrder)
+			return
+		}
+		uploaded, ok := upload.parts[part.PartNumber]
+		if !ok || strings.Trim(part.ETag, `"`) != uploaded.etag {
+			writeError(c, ErrInvalidPart)
+			return
+		}
+		if i < len(req.Parts)-1 && uploaded.size < config.MultipartMinPartSize {
+			writeError(c, ErrEntityTooSmall)
+			return
+		}
+	}
+
+	// 2. 按顺序合并分块, 同时计算sha1及ETag
+	chunkDir := layout.ChunkDir(upload.uploadID)
+	obj, err := mergeParts(chunkDir, req.Parts)
+	if err != nil {
+		log.Println(err.Error())
+		writeError(c, ErrInternalError)
+		return
+	}
+
+	// 3. 更新文件表及用户文件表
+	if err := commitObject(username, objectName(bucket, key), obj); err != nil {
+		log.Println(err.Error())
+		writeError(c, ErrInternalError)
+		return
+	}
+
+	// 4. 清理会话及分块
+	rConn.Do("DEL", mpKeyPrefix+upload.uploadID)
+	if !util.RemovePathByShell(layout.ChunkDir(upload.uploadID)) {
+		log.Println("Failed to remove chunk dir: " + upload.uploadID)
+	}
+
+	writeXML(c, http.StatusOK, completeMultipartUploadResult{
+		Xmlns:    s3Namespace,
+		Location: "/" + objectName(bucket, key),
+		Bucket:   bucket,
+		Key:      key,
+		ETag:     `"` + obj.etag + `"`,
+	})
+}
+
+// AbortMultipartUploadHandler : 取消分块上传并删除已上传的分块
+func AbortMultipartUploadHandler(c *gin.Context) {
+	rConn := rPool.Pool().Get()
+	defer rConn.Close()
+
+	upload, apiErr := loadUpload(c, rConn)
+	if apiErr != nil {
+		writeError(c, apiErr)
+		return
+	}
+
+	rConn.Do("DEL", mpKeyPrefix+upload.uploadID)
+	if !util.RemovePathByShell(layout.ChunkDir(upload.uploadID)) {
+		log.Println("Failed to remove chunk dir: " + upload.uploadID)
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// loadUpload : 读取uploadId对应的会话, 会话须由S3网关创建且属于当前用户及对象
+func loadUpload(c *gin.Context, rConn redis.Conn) (*s3Upload, *APIError) {
+	uploadID := c.Query("uploadId")
+	data, err := redis.StringMap(rConn.Do("HGETALL", mpKeyPrefix+uploadID))
+	if err != nil {
+		log.Println(err.Error())
+		return nil, ErrInternalError
+	}
+	if uploadID == "" || data["protocol"] != "s3" || data["username"] != c.GetString(usernameKey) ||
+		data["filename"] != objectName(c.GetString(bucketKey), c.GetString(objectKey)) {
+		return nil, ErrNoSuchUpload
+	}
+
+	upload := &s3Upload{
+		uploadID: uploadID,
+		parts:    map[int]uploadedPart{},
+	}
+	for field, value := range data {
+		if !strings.HasPrefix(field, partFieldPrefix) {
+			continue
+		}
+		partNumber, _ := strconv.Atoi(strings.TrimPrefix(field, partFieldPrefix))
+		kv := strings.SplitN(value, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		size, _ := strconv.ParseInt(kv[1], 10, 64)
+		upload.parts[partNumber] = uploadedPart{etag: kv[0], size: size}
+	}
+	return upload, nil
+}
+
+// mergeParts : 将分块按顺序合并到临时文件. 与S3相同, ETag为各分块md5拼接后的md5加上"-分块数",
+// 分块的etag已与上传时记录的md5校验过
+func mergeParts(chunkDir string, parts []completePart) (*tempObject, error) {
+	name, err := util.RandomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	obj := &tempObject{path: cmnCfg.TempLocalRootDir + name}
+	fd, err := os.Create(obj.path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	sha1Hash := sha1.New()
+	etagHash := md5.New()
+	writer := io.MultiWriter(fd, sha1Hash)
+	for _, part := range parts {
+		sum, err := hex.DecodeString(strings.Trim(part.ETag, `"`))
+		if err != nil {
+			os.Remove(obj.path)
+			return nil, err
+		}
+		etagHash.Write(sum)
+		partFd, err := os.Open(chunkDir + strconv.Itoa(part.PartNumber))
+		if err != nil {
+			os.Remove(obj.path)
+			return nil, err
+		}
+		n, err := io.Copy(writer, partFd)
+		partFd.Close()
+		if err != nil {
+			os.Remove(obj.path)
+			return nil, err
+		}
+		obj.size += n
+	}
+	obj.sha1 = hex.EncodeToString(sha1Hash.Sum(nil))
+	obj.etag = hex.EncodeToString(etagHash.Sum(nil)) + "-" + strconv.Itoa(len(parts))
+	return obj, nil
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/service/dbproxy/orm"
+	"github.com/cloud/store/replica/dbreplica"
+	"github.com/cloud/store/tier"
+	"github.com/cloud/store/tier/dbtier"
+)
+
+// maxObjectNameLen : 用户文件表file_name字段的长度限制
+const maxObjectNameLen = 256
+
+// PutObjectHandler : 上传对象, 与普通上传共用去重及异步转移流程
+func PutObjectHandler(c *gin.Context) {
+	username := c.GetString(usernameKey)
+	name := objectName(c.GetString(bucketKey), c.GetString(objectKey))
+	if utf8.RuneCountInString(name) > maxObjectNameLen {
+		writeError(c, ErrKeyTooLong)
+		return
+	}
+	if c.GetHeader("X-Amz-Copy-Source") != "" {
+		// CopyObject暂不支持
+		writeError(c, ErrNotImplemented)
+		return
+	}
+	contentMD5, apiErr := parseContentMD5(c)
+	if apiErr != nil {
+		writeError(c, apiErr)
+		return
+	}
+
+	// 1. 写入临时文件并校验数据完整性
+	obj, err := writeTempObject(c.Request.Body)
+	if err != nil {
+		log.Println(err.Error())
+		writeError(c, bodyError(err))
+		return
+	}
+	if apiErr := verifyBody(c, obj.size, obj.md5, contentMD5); apiErr != nil {
+		removeTempObject(obj)
+		writeError(c, apiErr)
+		return
+	}
+
+	// 2. 更新文件表及用户文件表
+	if err := commitObject(username, name, obj); err != nil {
+		log.Println(err.Error())
+		writeError(c, ErrInternalError)
+		return
+	}
+
+	c.Header("ETag", `"`+obj.etag+`"`)
+	c.Status(http.StatusOK)
+}
+
+// GetObjectHandler : 下载对象, 支持Range请求
+func GetObjectHandler(c *gin.Context) {
+	serveObject(c, true)
+}
+
+// HeadObjectHandler : 获取对象元信息
+func HeadObjectHandler(c *gin.Context) {
+	serveObject(c, false)
+}
+
+// DeleteObjectHandler : 删除对象(标记删除), 对象不存在时同样返回成功
+func DeleteObjectHandler(c *gin.Context) {
+	username := c.GetString(usernameKey)
+	name := objectName(c.GetString(bucketKey), c.GetString(objectKey))
+
+	dbResp, err := dbcli.DeleteUserFileByName(username, name)
+	if err != nil || !dbResp.Suc {
+		writeError(c, ErrInternalError)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// serveObject : 返回对象元信息, withBody为true时同时返回对象数据
+func serveObject(c *gin.Context, withBody bool) {
+	username := c.GetString(usernameKey)
+	key := c.GetString(objectKey)
+
+	// 1. 查询用户文件
+	ufile, apiErr := queryObject(username, objectName(c.GetString(bucketKey), key))
+	if apiErr != nil {
+		writeError(c, apiErr)
+		return
+	}
+
+	// 2. 解析Range
+	start, length, partial, apiErr := parseRange(c.GetHeader("Range"), ufile.FileSize)
+	if apiErr != nil {
+		c.Header("Content-Range", "bytes */"+strconv.FormatInt(ufile.FileSize, 10))
+		writeError(c, apiErr)
+		return
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Length", strconv.FormatInt(length, 10))
+	c.Header("ETag", objectETag(ufile))
+	c.Header("Last-Modified", formatDBTime(ufile.UploadAt, http.TimeFormat))
+	c.Header("Accept-Ranges", "bytes")
+	statusCode := http.StatusOK
+	if partial {
+		statusCode = http.StatusPartialContent
+		c.Header("Content-Range", "bytes "+strconv.FormatInt(start, 10)+"-"+
+			strconv.FormatInt(start+length-1, 10)+"/"+strconv.FormatInt(ufile.FileSize, 10))
+	}
+	if !withBody {
+		c.Status(statusCode)
+		return
+	}
+
+	// 3. 从本地/Ceph/OSS中可用的副本读取数据, 归档的对象解冻完成前返回InvalidObjectState
+	if length == 0 {
+		c.Status(statusCode)
+		return
+	}
+	if err := dbtier.Access(ufile.FileHash); err != nil {
+		c.Header("Content-Length", "")
+		if err == tier.ErrRestoring {
+			writeError(c, ErrInvalidObjectState)
+		} else {
+			log.Println(err.Error())
+			writeError(c, ErrInternalError)
+		}
+		return
+	}
+	reader, err := dbreplica.Open(ufile.FileHash, start, length)
+	if err != nil {
+		log.Println(err.Error())
+		c.Header("Content-Length", "")
+		writeError(c, ErrInternalError)
+		return
+	}
+	defer reader.Close()
+
+	c.Status(statusCode)
+	if _, err := io.Copy(c.Writer, reader); err != nil {
+		log.Printf("Failed to send object %s, err:%s\n", key, err.Error())
+	}
+}
+
+// objectETag : 对象的ETag(带引号). 不是通过S3网关上传的文件没有记录ETag, 使用文件的sha1
+func objectETag(ufile *orm.TableUserFile) string {
+	if ufile.ETag == "" {
+		return `"` + ufile.FileHash + `"`
+	}
+	return `"` + ufile.ETag + `"`
+}
+
+// queryObject : 按文件名查询用户文件
+func queryObject(username, name string) (*orm.TableUserFile, *APIError) {
+	dbResp, err := dbcli.QueryUserFileByName(username, name)
+	if err != nil || !dbResp.Suc {
+		return nil, ErrInternalError
+	}
+	if dbResp.Data == nil {
+		return nil, ErrNoSuchKey
+	}
+	ufile := dbcli.ToTableUserFile(dbResp.Data)
+	return &ufile, nil
+}
+
+// parseRange : 解析单个Range(bytes=a-b, bytes=a-, bytes=-n), 返回起始位置及长度
+func parseRange(header string, size int64) (int64, int64, bool, *APIError) {
+	if header == "" || !strings.HasPrefix(header, "bytes=") || strings.Contains(header, ",") {
+		// 不支持的Range格式按S3行为忽略, 返回完整对象
+		return 0, size, false, nil
+	}
+	spec := strings.SplitN(strings.TrimPrefix(header, "bytes="), "-", 2)
+	if len(spec) != 2 {
+		return 0, size, false, nil
+	}
+
+	var start, end int64
+	var err error
+	if spec[0] == "" {
+		// 最后n个字节
+		n, err := strconv.ParseInt(spec[1], 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0, false, ErrInvalidRange
+		}
+		if n > size {
+			n = size
+		}
+		start, end = size-n, size-1
+	} else {
+		if start, err = strconv.ParseInt(spec[0], 10, 64); err != nil {
+			return 0, size, false, nil
+		}
+		end = size - 1
+		if spec[1] != "" {
+			if end, err = strconv.ParseInt(spec[1], 10, 64); err != nil || end < start {
+				return 0, size, false, nil
+			}
+			if end > size-1 {
+				end = size - 1
+			}
+		}
+	}
+	if start >= size || start < 0 {
+		return 0, 0, false, ErrInvalidRange
+	}
+	return start, end - start + 1, true, nil
+}
+
+// parseContentMD5 : 解析Content-MD5请求头
+func parseContentMD5(c *gin.Context) ([]byte, *APIError) {
+	header := c.GetHeader("Content-MD5")
+	if header == "" {
+		return nil, nil
+	}
+	sum, err := base64.StdEncoding.DecodeString(header)
+	if err != nil || len(sum) != 16 {
+		return nil, ErrInvalidDigest
+	}
+	return sum, nil
+}
+
+// verifyBody : 校验请求体长度及Content-MD5
+func verifyBody(c *gin.Context, size int64, sum, contentMD5 []byte) *APIError {
+	if c.Request.ContentLength >= 0 && size != c.Request.ContentLength {
+		return ErrIncompleteBody
+	}
+	if contentMD5 != nil && !bytes.Equal(sum, contentMD5) {
+		return ErrBadDigest
+	}
+	return nil
+}
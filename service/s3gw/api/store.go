@@ -0,0 +1,161 @@
+package api
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	cmnCfg "github.com/cloud/config"
+	"github.com/cloud/job"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/store/backend"
+	"github.com/cloud/store/layout"
+	"github.com/cloud/store/policy"
+	"github.com/cloud/util"
+)
+
+// jobs : 提交异步转移任务使用的Client, 由Setup设置
+var jobs *job.Client
+
+// Setup : 设置提交异步转移任务使用的Client, 须在启动API服务前调用
+func Setup(jobClient *job.Client) {
+	jobs = jobClient
+}
+
+func init() {
+	if err := os.MkdirAll(cmnCfg.TempLocalRootDir, 0744); err != nil {
+		fmt.Println("无法指定目录用于存储临时文件: " + cmnCfg.TempLocalRootDir)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(cmnCfg.MergeLocalRootDir, 0744); err != nil {
+		fmt.Println("无法指定目录用于存储合并后文件: " + cmnCfg.MergeLocalRootDir)
+		os.Exit(1)
+	}
+}
+
+// objectName : bucket对应用户的顶层目录, 对象在用户文件表中的文件名为 bucket/key
+func objectName(bucket, key string) string {
+	return bucket + "/" + key
+}
+
+// tempObject : 写入本地临时目录的对象数据
+type tempObject struct {
+	path string
+	sha1 string
+	md5  []byte
+	etag string
+	size int64
+}
+
+// writeTempObject : 将数据流写入临时文件, 同时计算sha1(用于去重)及md5(用于Content-MD5校验及ETag)
+func writeTempObject(body io.Reader) (*tempObject, error) {
+	name, err := util.RandomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	obj := &tempObject{path: cmnCfg.TempLocalRootDir + name}
+	fd, err := os.Create(obj.path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	sha1Hash := sha1.New()
+	md5Hash := md5.New()
+	obj.size, err = io.Copy(io.MultiWriter(fd, sha1Hash, md5Hash), body)
+	if err != nil {
+		os.Remove(obj.path)
+		return nil, err
+	}
+	obj.sha1 = hex.EncodeToString(sha1Hash.Sum(nil))
+	obj.md5 = md5Hash.Sum(nil)
+	obj.etag = hex.EncodeToString(obj.md5)
+	return obj, nil
+}
+
+// removeTempObject : 删除校验失败的临时文件
+func removeTempObject(obj *tempObject) {
+	if err := os.Remove(obj.path); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// commitObject : 将临时文件保存为用户文件并记录其ETag. 文件表中已有相同内容时直接复用(秒传),
+// 否则移入合并目录, 更新文件表并发起异步转移
+func commitObject(username, name string, obj *tempObject) error {
+	// 1. 查询是否已存在相同hash的文件
+	dbResp, err := dbcli.GetFileMeta(obj.sha1)
+	if err != nil {
+		os.Remove(obj.path)
+		return err
+	}
+	if !dbResp.Suc {
+		os.Remove(obj.path)
+		return errors.New(dbResp.Msg)
+	}
+
+	fileMeta := dbcli.FileMeta{
+		FileSha1: obj.sha1,
+		FileName: name,
+		FileSize: obj.size,
+		UploadAt: time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if dbResp.Data != nil {
+		os.Remove(obj.path)
+		fileMeta.Location = dbcli.ToTableFile(dbResp.Data).FileAddr.String
+	} else {
+		// 2. 新文件: 写入合并目录(加密)并写入文件表
+		fileMeta.Location = layout.MergePath(obj.sha1)
+		if err := backend.PutLocalFile(obj.path, fileMeta.Location); err != nil {
+			os.Remove(obj.path)
+			return err
+		}
+		fRes, err := dbcli.OnFileUploadFinished(fileMeta)
+		if err != nil {
+			return err
+		}
+		if !fRes.Suc {
+			return errors.New("failed to update tbl_file: " + obj.sha1)
+		}
+		publishTransfer(username, fileMeta)
+	}
+
+	// 3. 写入用户文件表, 同名对象会被覆盖
+	ufRes, err := dbcli.OnUserFileUploadFinished(username, fileMeta)
+	if err != nil {
+		return err
+	}
+	if !ufRes.Suc {
+		return errors.New(ufRes.Msg)
+	}
+
+	// 4. 记录ETag, 之后的HEAD/GET及列举返回与上传响应相同的ETag
+	etRes, err := dbcli.SetUserFileETag(username, name, obj.sha1, obj.etag)
+	if err != nil {
+		return err
+	}
+	if !etRes.Suc {
+		return errors.New(etRes.Msg)
+	}
+	return nil
+}
+
+// publishTransfer : 按存储策略提交异步转移任务, 将本地文件转移到Ceph/OSS
+func publishTransfer(username string, fileMeta dbcli.FileMeta) {
+	err := policy.Enqueue(jobs.Enqueue, policy.File{
+		UserName: username,
+		FileName: fileMeta.FileName,
+		FileSize: fileMeta.FileSize,
+		FileHash: fileMeta.FileSha1,
+		Location: fileMeta.Location,
+	})
+	if err != nil {
+		log.Println("enqueue transfer job failed, sha1: " + fileMeta.FileSha1 + ", " + err.Error())
+	}
+}
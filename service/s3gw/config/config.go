@@ -0,0 +1,33 @@
+package config
+
+import "time"
+
+// S3GatewayEntry : S3兼容网关的入口地址
+var S3GatewayEntry = "127.0.0.1:39000"
+
+// S3GatewayHost : S3兼容网关监听的地址
+var S3GatewayHost = "0.0.0.0:39000"
+
+// S3Domain : 虚拟主机风格访问(bucket.S3Domain)时使用的域名, 为空则只支持路径风格
+var S3Domain = ""
+
+// Region : SigV4签名使用的region
+var Region = "us-east-1"
+
+// MaxClockSkew : 请求时间与服务器时间允许的最大偏差
+var MaxClockSkew = 15 * time.Minute
+
+// MaxPresignExpires : 预签名URL的最长有效期
+var MaxPresignExpires = 7 * 24 * time.Hour
+
+// ListMaxKeys : ListObjectsV2单次返回的最大对象数
+var ListMaxKeys = 1000
+
+// MultipartMinPartSize : 分块上传中除最后一块外每块的最小大小
+var MultipartMinPartSize int64 = 5 * 1024 * 1024
+
+// MultipartMaxParts : 分块上传允许的最大块数
+var MultipartMaxParts = 10000
+
+// MultipartSessionTTL : 分块上传会话在redis中的有效期
+var MultipartSessionTTL = 7 * 24 * time.Hour
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"log"
+
+	"github.com/cloud/job/dbqueue"
+	"github.com/cloud/mq"
+	"github.com/cloud/service/s3gw/api"
+	"github.com/cloud/service/s3gw/config"
+	"github.com/cloud/service/s3gw/route"
+	"github.com/cloud/store/layout"
+	"github.com/cloud/store/replica/dbreplica"
+	"github.com/cloud/store/tier/dbtier"
+)
+
+func main() {
+	// 转移、修复及层级任务通过dbproxy保存、通过默认消息队列发布
+	jobs := dbqueue.NewClient(mq.Default())
+	api.Setup(jobs)
+	dbreplica.Setup(jobs)
+	dbtier.Setup(jobs)
+
+	// 检查本地存储中写入中断的文件
+	go layout.CheckLocal()
+
+	// 启动S3兼容API服务
+	router := route.Router()
+	if err := router.Run(config.S3GatewayHost); err != nil {
+		log.Println(err)
+	}
+}
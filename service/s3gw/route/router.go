@@ -0,0 +1,20 @@
+package route
+
+import (
+	"github.com/cloud/service/s3gw/api"
+	"github.com/gin-gonic/gin"
+)
+
+// Router : S3兼容网关路由表配置
+func Router() *gin.Engine {
+	// gin framework, 包括Logger, Recovery
+	router := gin.Default()
+
+	// 所有S3请求都需通过SigV4签名校验
+	router.Use(api.SigV4Auth())
+
+	// S3以请求方法及查询参数区分操作, 由api.S3Handler统一分发
+	router.Any("/*path", api.S3Handler)
+
+	return router
+}
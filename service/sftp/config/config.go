@@ -0,0 +1,11 @@
+package config
+
+// SFTPHost : SFTP服务监听的地址
+var SFTPHost = "0.0.0.0:2022"
+
+// HostKeyPath : SSH主机私钥路径, 不存在时自动生成ed25519私钥.
+// 多实例部署时应使用同一私钥, 避免客户端提示主机密钥变更
+var HostKeyPath = "./data/sftp_host_ed25519_key"
+
+// MaxAuthTries : 每个连接允许的认证失败次数
+var MaxAuthTries = 6
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"log"
+
+	"github.com/cloud/job/dbqueue"
+	"github.com/cloud/mq"
+	"github.com/cloud/service/sftp/config"
+	"github.com/cloud/service/sftp/server"
+	"github.com/cloud/store/layout"
+	"github.com/cloud/store/replica/dbreplica"
+	"github.com/cloud/store/tier/dbtier"
+	"github.com/cloud/store/userfs"
+)
+
+func main() {
+	// 转移、修复及层级任务通过dbproxy保存、通过默认消息队列发布
+	jobs := dbqueue.NewClient(mq.Default())
+	userfs.Setup(jobs)
+	dbreplica.Setup(jobs)
+	dbtier.Setup(jobs)
+
+	// 检查本地存储中写入中断的文件
+	go layout.CheckLocal()
+
+	// 启动SFTP服务
+	if err := server.ListenAndServe(config.SFTPHost); err != nil {
+		log.Println(err)
+	}
+}
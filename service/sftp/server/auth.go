@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	micro "github.com/micro/go-micro"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/cloud/common"
+	cmnCfg "github.com/cloud/config"
+	userProto "github.com/cloud/service/account/proto"
+	dbcli "github.com/cloud/service/dbproxy/client"
+)
+
+// usernameExt : 认证通过后保存在ssh.Permissions中的用户名
+const usernameExt = "username"
+
+var (
+	userCli userProto.UserService
+
+	errAuthFailed = errors.New("authentication failed")
+)
+
+func init() {
+	service := micro.NewService(
+		micro.Registry(cmnCfg.RegistryConsul()),
+	)
+	// 初始化，解析命令行参数
+	service.Init()
+	// 初始化一个account服务的rpcCli
+	userCli = userProto.NewUserService("go.micro.service.user", service.Client())
+}
+
+// passwordCallback : 用户名及密码交由account服务校验
+func passwordCallback(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+	rpcResp, err := userCli.Authenticate(context.TODO(), &userProto.ReqAuthenticate{
+		Username: conn.User(),
+		Password: string(password),
+	})
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	if rpcResp.Code == common.StatusServerError {
+		log.Println("account service error: " + rpcResp.Message)
+		return nil, errAuthFailed
+	}
+	if rpcResp.Code != common.StatusOK {
+		return nil, errAuthFailed
+	}
+	return permissions(conn.User()), nil
+}
+
+// publicKeyCallback : 按指纹查询用户添加的公钥, 公钥须属于登录的用户
+func publicKeyCallback(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+	dbResp, err := dbcli.GetSSHKeysByFingerprint(ssh.FingerprintSHA256(key))
+	if err != nil || !dbResp.Suc {
+		return nil, fmt.Errorf("failed to query ssh key of user %s", conn.User())
+	}
+	authorizedKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+	for _, userKey := range dbcli.ToTableSSHKeys(dbResp.Data) {
+		if userKey.UserName == conn.User() && userKey.PublicKey == authorizedKey {
+			return permissions(conn.User()), nil
+		}
+	}
+	return nil, errAuthFailed
+}
+
+func permissions(username string) *ssh.Permissions {
+	return &ssh.Permissions{
+		Extensions: map[string]string{usernameExt: username},
+	}
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"io"
+	"log"
+	"os"
+
+	"github.com/pkg/sftp"
+
+	"github.com/cloud/store/userfs"
+)
+
+// handler : 将用户的目录树(userfs.FS)适配为sftp.Handlers.
+// 写入的文件总是整体替换同名文件, 不支持追加及在原有内容上修改
+type handler struct {
+	fs *userfs.FS
+}
+
+func (h *handler) Fileread(r *sftp.Request) (io.ReaderAt, error) {
+	reader, err := h.fs.Open(r.Filepath)
+	if err != nil {
+		return nil, sftpError(err)
+	}
+	return reader, nil
+}
+
+func (h *handler) Filewrite(r *sftp.Request) (io.WriterAt, error) {
+	flags := r.Pflags()
+	if flags.Append {
+		return nil, sftp.ErrSSHFxOpUnsupported
+	}
+	info, err := h.fs.Stat(r.Filepath)
+	if err == nil {
+		if info.IsDir() {
+			return nil, sftpError(userfs.ErrIsDirectory)
+		}
+		if flags.Excl {
+			return nil, os.ErrExist
+		}
+		if !flags.Trunc {
+			return nil, sftp.ErrSSHFxOpUnsupported
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, sftpError(err)
+	} else if !flags.Creat {
+		return nil, sftp.ErrSSHFxNoSuchFile
+	}
+
+	writer, err := h.fs.Create(r.Filepath)
+	if err != nil {
+		return nil, sftpError(err)
+	}
+	return &fileWriter{Writer: writer}, nil
+}
+
+func (h *handler) Filecmd(r *sftp.Request) error {
+	switch r.Method {
+	case "Setstat":
+		// 不保存权限及修改时间
+		return nil
+	case "Rename":
+		// SFTP v3的rename要求目标不存在
+		if _, err := h.fs.Stat(r.Target); err == nil {
+			return sftp.ErrSSHFxFailure
+		} else if !os.IsNotExist(err) {
+			return sftpError(err)
+		}
+		return sftpError(h.fs.Rename(r.Filepath, r.Target))
+	case "Rmdir":
+		info, err := h.fs.Stat(r.Filepath)
+		if err != nil {
+			return sftpError(err)
+		}
+		if !info.IsDir() {
+			return sftpError(userfs.ErrNotDirectory)
+		}
+		children, err := h.fs.ReadDir(r.Filepath)
+		if err != nil {
+			return sftpError(err)
+		}
+		if len(children) > 0 {
+			return sftp.ErrSSHFxFailure
+		}
+		return sftpError(h.fs.RemoveAll(r.Filepath))
+	case "Remove":
+		info, err := h.fs.Stat(r.Filepath)
+		if err != nil {
+			return sftpError(err)
+		}
+		if info.IsDir() {
+			return sftpError(userfs.ErrIsDirectory)
+		}
+		return sftpError(h.fs.Remove(r.Filepath))
+	case "Mkdir":
+		return sftpError(h.fs.Mkdir(r.Filepath))
+	}
+	// Link/Symlink等
+	return sftp.ErrSSHFxOpUnsupported
+}
+
+// PosixRename : posix-rename@openssh.com扩展, 目标文件已存在时先删除
+func (h *handler) PosixRename(r *sftp.Request) error {
+	info, err := h.fs.Stat(r.Target)
+	if err == nil {
+		if info.IsDir() {
+			return sftpError(userfs.ErrIsDirectory)
+		}
+		if err := h.fs.Remove(r.Target); err != nil {
+			return sftpError(err)
+		}
+	} else if !os.IsNotExist(err) {
+		return sftpError(err)
+	}
+	return sftpError(h.fs.Rename(r.Filepath, r.Target))
+}
+
+func (h *handler) Filelist(r *sftp.Request) (sftp.ListerAt, error) {
+	switch r.Method {
+	case "List":
+		infos, err := h.fs.ReadDir(r.Filepath)
+		if err != nil {
+			return nil, sftpError(err)
+		}
+		return listerAt(infos), nil
+	case "Stat":
+		info, err := h.fs.Stat(r.Filepath)
+		if err != nil {
+			return nil, sftpError(err)
+		}
+		return listerAt{info}, nil
+	}
+	// Readlink
+	return nil, sftp.ErrSSHFxOpUnsupported
+}
+
+// listerAt : 实现sftp.ListerAt
+type listerAt []os.FileInfo
+
+func (l listerAt) ListAt(ls []os.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+	n := copy(ls, l[offset:])
+	if n < len(ls) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// fileWriter : 传输正常结束(客户端关闭文件句柄)时提交文件, 连接中断时丢弃已写入的数据
+type fileWriter struct {
+	*userfs.Writer
+	aborted bool
+}
+
+func (w *fileWriter) Close() error {
+	if w.aborted {
+		return nil
+	}
+	if err := w.Commit(); err != nil {
+		log.Println(err.Error())
+		return sftp.ErrSSHFxFailure
+	}
+	return nil
+}
+
+func (w *fileWriter) TransferError(err error) {
+	w.aborted = true
+	w.Abort()
+}
+
+// sftpError : 将userfs的错误转换为SFTP状态码
+func sftpError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case os.IsNotExist(err):
+		return sftp.ErrSSHFxNoSuchFile
+	case os.IsPermission(err):
+		return sftp.ErrSSHFxPermissionDenied
+	case err == userfs.ErrServer:
+		log.Println(err.Error())
+		return sftp.ErrSSHFxFailure
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"encoding/binary"
+	"io"
+	"log"
+	"net"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/cloud/service/sftp/config"
+	"github.com/cloud/store/userfs"
+)
+
+// ListenAndServe : 监听addr并为每个连接提供SFTP子系统, 不支持shell/exec及端口转发
+func ListenAndServe(addr string) error {
+	hostKey, err := loadHostKey(config.HostKeyPath)
+	if err != nil {
+		return err
+	}
+	sshConfig := &ssh.ServerConfig{
+		PasswordCallback:  passwordCallback,
+		PublicKeyCallback: publicKeyCallback,
+		MaxAuthTries:      config.MaxAuthTries,
+	}
+	sshConfig.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	log.Println("sftp server listening on " + addr)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go handleConn(conn, sshConfig)
+	}
+}
+
+func handleConn(conn net.Conn, sshConfig *ssh.ServerConfig) {
+	sshConn, chans, reqs, err := ssh.NewServerConn(conn, sshConfig)
+	if err != nil {
+		log.Printf("sftp handshake from %s failed: %s\n", conn.RemoteAddr(), err.Error())
+		conn.Close()
+		return
+	}
+	defer sshConn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	username := sshConn.Permissions.Extensions[usernameExt]
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			log.Println(err.Error())
+			continue
+		}
+		go handleSession(channel, requests, username)
+	}
+}
+
+// handleSession : 只接受sftp子系统请求
+func handleSession(channel ssh.Channel, requests <-chan *ssh.Request, username string) {
+	for req := range requests {
+		ok := req.Type == "subsystem" && subsystemName(req.Payload) == "sftp"
+		req.Reply(ok, nil)
+		if !ok {
+			continue
+		}
+		go serveSFTP(channel, username)
+	}
+}
+
+// subsystemName : 解析subsystem请求中的名称(uint32长度 + 字符串)
+func subsystemName(payload []byte) string {
+	if len(payload) < 4 {
+		return ""
+	}
+	n := binary.BigEndian.Uint32(payload)
+	if uint32(len(payload)-4) < n {
+		return ""
+	}
+	return string(payload[4 : 4+n])
+}
+
+func serveSFTP(channel ssh.Channel, username string) {
+	defer channel.Close()
+	h := &handler{fs: userfs.New(username)}
+	server := sftp.NewRequestServer(channel, sftp.Handlers{
+		FileGet:  h,
+		FilePut:  h,
+		FileCmd:  h,
+		FileList: h,
+	})
+	if err := server.Serve(); err != nil && err != io.EOF {
+		log.Printf("sftp session of %s closed: %s\n", username, err.Error())
+	}
+	server.Close()
+}
@@ -15,9 +15,13 @@ stop_process() {
 
 
 services="
+webhook
+sftp
+webdav
+s3gw
 apigw
 account
-transfer
+job
 download
 upload
 dbproxy
@@ -1,13 +1,15 @@
 package api
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"math"
 	"net/http"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -18,27 +20,96 @@ import (
 	rPool "github.com/cloud/cache/redis"
 	"github.com/cloud/common"
 	"github.com/cloud/config"
-	"github.com/cloud/mq"
 	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/store/backend"
+	"github.com/cloud/store/layout"
+	"github.com/cloud/store/policy"
+	upCfg "github.com/cloud/service/upload/config"
 	"github.com/cloud/util"
 )
 
 const (
 	// ChunkKeyPrefix : 分块信息对应的redis键前缀
 	ChunkKeyPrefix = "MP_"
-	// HashUpIDKeyPrefix : 文件hash映射uploadid对应的redis键前缀
+	// HashUpIDKeyPrefix : 用户+文件hash映射uploadid对应的redis键前缀
 	HashUpIDKeyPrefix = "HASH_UPID_"
+	// UserUpIDKeyPrefix : 用户进行中的uploadid集合对应的redis键前缀
+	UserUpIDKeyPrefix = "USER_UPID_"
+	// uploadSessionTTL : 分块上传会话的有效期(秒)
+	uploadSessionTTL = 43200
 )
 
-// MultipartUploadInfo : 初始化信息
-type MultipartUploadInfo struct {
-	FileHash   string
-	FileSize   int
-	UploadID   string
-	ChunkSize  int
-	ChunkCount int
-	// 已经上传完成的分块索引列表
-	ChunkExists []int
+// MultipartUploadStatus : 分块上传会话的当前状态
+type MultipartUploadStatus struct {
+	UploadID      string
+	FileHash      string
+	FileSize      int
+	ChunkSize     int
+	ChunkCount    int
+	ChunkExists   []int
+	BytesReceived int64
+	ExpireAt      string
+	Owner         string
+}
+
+// hashUpIDKey : 文件hash映射uploadid的redis键, 按用户隔离
+func hashUpIDKey(username, filehash string) string {
+	return HashUpIDKeyPrefix + username + "_" + filehash
+}
+
+// loadUploadStatus : 从redis读取分块上传会话, 会话不存在时返回nil
+func loadUploadStatus(rConn redis.Conn, uploadID string) (*MultipartUploadStatus, error) {
+	data, err := redis.Values(rConn.Do("HGETALL", ChunkKeyPrefix+uploadID))
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+
+	status := MultipartUploadStatus{
+		UploadID:    uploadID,
+		ChunkExists: []int{},
+	}
+	// tus会话按偏移量而非分块索引记录进度
+	offset := int64(-1)
+	for i := 0; i < len(data); i += 2 {
+		k := string(data[i].([]byte))
+		v := string(data[i+1].([]byte))
+		switch {
+		case k == "chunkcount":
+			status.ChunkCount, _ = strconv.Atoi(v)
+		case k == "chunksize":
+			status.ChunkSize, _ = strconv.Atoi(v)
+		case k == "filehash":
+			status.FileHash = v
+		case k == "filesize":
+			status.FileSize, _ = strconv.Atoi(v)
+		case k == "username":
+			status.Owner = v
+		case k == "offset":
+			offset, _ = strconv.ParseInt(v, 10, 64)
+		case strings.HasPrefix(k, "chkidx_") && v == "1":
+			// chkidx_6 -> 6
+			chunkIdx, _ := strconv.Atoi(k[7:])
+			status.ChunkExists = append(status.ChunkExists, chunkIdx)
+		}
+	}
+	sort.Ints(status.ChunkExists)
+
+	// 已接收字节数: 除最后一块外每块大小均为chunksize
+	if offset >= 0 {
+		status.BytesReceived = offset
+	}
+	for _, idx := range status.ChunkExists {
+		if idx == status.ChunkCount-1 {
+			status.BytesReceived += int64(status.FileSize - status.ChunkSize*(status.ChunkCount-1))
+		} else {
+			status.BytesReceived += int64(status.ChunkSize)
+		}
+	}
+
+	if ttl, err := redis.Int64(rConn.Do("TTL", ChunkKeyPrefix+uploadID)); err == nil && ttl > 0 {
+		status.ExpireAt = time.Now().Add(time.Duration(ttl) * time.Second).Format("2006-01-02 15:04:05")
+	}
+	return &status, nil
 }
 
 func init() {
@@ -59,7 +130,8 @@ func InitialMultipartUploadHandler(c *gin.Context) {
 	username := c.Request.FormValue("username")
 	filehash := c.Request.FormValue("filehash")
 	filesize, err := strconv.Atoi(c.Request.FormValue("filesize"))
-	if err != nil {
+	// 文件hash用作本地存储的路径, 须为sha1
+	if err != nil || !layout.IsFileHash(filehash) {
 		c.JSON(
 			http.StatusOK,
 			gin.H{
@@ -69,6 +141,25 @@ func InitialMultipartUploadHandler(c *gin.Context) {
 		return
 	}
 
+	// 客户端可提议分块大小，但须在服务端配置的范围内
+	chunkSize := upCfg.ChunkSizeDefault
+	if proposed := c.Request.FormValue("chunksize"); proposed != "" {
+		chunkSize, err = strconv.Atoi(proposed)
+		if err != nil || chunkSize < upCfg.ChunkSizeMin || chunkSize > upCfg.ChunkSizeMax {
+			c.JSON(
+				http.StatusOK,
+				gin.H{
+					"code": -1,
+					"msg":  "chunk size out of range",
+					"data": gin.H{
+						"ChunkSizeMin": upCfg.ChunkSizeMin,
+						"ChunkSizeMax": upCfg.ChunkSizeMax,
+					},
+				})
+			return
+		}
+	}
+
 	// 判断文件是否存在
 	if exists, _ := dbcli.IsUserFileUploaded(username, filehash); exists {
 		c.JSON(
@@ -84,29 +175,47 @@ func InitialMultipartUploadHandler(c *gin.Context) {
 	rConn := rPool.Pool().Get()
 	defer rConn.Close()
 
-	// 3. 通过文件hash判断是否断点续传，并获取uploadID
-	uploadID := ""
-	keyExists, _ := redis.Bool(rConn.Do("EXISTS", HashUpIDKeyPrefix+filehash))
-	if keyExists {
-		uploadID, err = redis.String(rConn.Do("GET", HashUpIDKeyPrefix+filehash))
+	// 3. 判断是否断点续传，并获取uploadID
+	// 客户端可直接携带uploadid续传(如跨设备)，否则通过用户名+文件hash查找
+	uploadID := c.Request.FormValue("uploadid")
+	resumeByID := uploadID != ""
+	if !resumeByID {
+		uploadID, _ = redis.String(rConn.Do("GET", hashUpIDKey(username, filehash)))
+	}
+
+	// 4.1 断点续传则根据uploadID获取已上传的文件分块列表，并沿用当初协商的分块大小
+	// 4.2 首次上传(或原有会话已过期)则新建uploadID
+	var upStatus *MultipartUploadStatus
+	if uploadID != "" {
+		upStatus, err = loadUploadStatus(rConn, uploadID)
 		if err != nil {
 			c.JSON(
 				http.StatusOK,
 				gin.H{
-					"code": -2,
+					"code": -3,
 					"msg":  err.Error(),
 				})
 			return
 		}
+		// uploadid只能由创建它的用户针对同一文件续传
+		if upStatus != nil && (upStatus.Owner != username || upStatus.FileHash != filehash) {
+			upStatus = nil
+		}
+		if upStatus == nil && resumeByID {
+			c.JSON(
+				http.StatusOK,
+				gin.H{
+					"code": -2,
+					"msg":  "upload session not found",
+				})
+			return
+		}
 	}
 
-	// 4.1 首次上传则新建uploadID
-	// 4.2 断点续传则根据uploadID获取已上传的文件分块列表
 	chunksExist := []int{}
-	if uploadID == "" {
-		uploadID = username + fmt.Sprintf("%x", time.Now().UnixNano())
-	} else {
-		chunks, err := redis.Values(rConn.Do("HGETALL", ChunkKeyPrefix+uploadID))
+	isNewUpload := upStatus == nil
+	if isNewUpload {
+		uploadID, err = util.RandomHex(16)
 		if err != nil {
 			c.JSON(
 				http.StatusOK,
@@ -116,35 +225,36 @@ func InitialMultipartUploadHandler(c *gin.Context) {
 				})
 			return
 		}
-		for i := 0; i < len(chunks); i += 2 {
-			k := string(chunks[i].([]byte))
-			v := string(chunks[i+1].([]byte))
-			if strings.HasPrefix(k, "chkidx_") && v == "1" {
-				// chkidx_6 -> 6
-				chunkIdx, _ := strconv.Atoi(k[7:])
-				chunksExist = append(chunksExist, chunkIdx)
-			}
-		}
+	} else {
+		chunkSize = upStatus.ChunkSize
+		filesize = upStatus.FileSize
+		chunksExist = upStatus.ChunkExists
 	}
 
 	// 5. 生成分块上传的初始化信息
-	upInfo := MultipartUploadInfo{
+	upInfo := common.MultipartUploadInfo{
 		FileHash:    filehash,
 		FileSize:    filesize,
 		UploadID:    uploadID,
-		ChunkSize:   5 * 1024 * 1024, // 5MB
-		ChunkCount:  int(math.Ceil(float64(filesize) / (5 * 1024 * 1024))),
+		ChunkSize:   chunkSize,
+		ChunkCount:  int(math.Ceil(float64(filesize) / float64(chunkSize))),
 		ChunkExists: chunksExist,
+		MaxParallel: upCfg.MaxParallelChunks,
 	}
 
 	// 6. 将初始化信息写入到redis缓存
-	if len(upInfo.ChunkExists) <= 0 {
+	if isNewUpload {
 		hkey := ChunkKeyPrefix + upInfo.UploadID
 		rConn.Do("HSET", hkey, "chunkcount", upInfo.ChunkCount)
+		rConn.Do("HSET", hkey, "chunksize", upInfo.ChunkSize)
 		rConn.Do("HSET", hkey, "filehash", upInfo.FileHash)
 		rConn.Do("HSET", hkey, "filesize", upInfo.FileSize)
-		rConn.Do("EXPIRE", hkey, 43200)
-		rConn.Do("SET", HashUpIDKeyPrefix+filehash, upInfo.UploadID, "EX", 43200)
+		rConn.Do("HSET", hkey, "username", username)
+		rConn.Do("HSET", hkey, "createat", time.Now().Unix())
+		rConn.Do("EXPIRE", hkey, uploadSessionTTL)
+		rConn.Do("SET", hashUpIDKey(username, filehash), upInfo.UploadID, "EX", uploadSessionTTL)
+		rConn.Do("SADD", UserUpIDKeyPrefix+username, upInfo.UploadID)
+		rConn.Do("EXPIRE", UserUpIDKeyPrefix+username, uploadSessionTTL)
 	}
 
 	// 7. 将响应初始化数据返回到客户端
@@ -160,7 +270,7 @@ func InitialMultipartUploadHandler(c *gin.Context) {
 // UploadPartHandler : 上传文件分块
 func UploadPartHandler(c *gin.Context) {
 	// 1. 解析用户请求参数
-	//	username := c.Request.FormValue("username")
+	username := c.Request.FormValue("username")
 	uploadID := c.Request.FormValue("uploadid")
 	chunkSha1 := c.Request.FormValue("chkhash")
 	chunkIndex := c.Request.FormValue("index")
@@ -169,8 +279,43 @@ func UploadPartHandler(c *gin.Context) {
 	rConn := rPool.Pool().Get()
 	defer rConn.Close()
 
-	// 3. 获得文件句柄，用于存储分块内容
-	fpath := config.ChunkLocalRootDir + uploadID + "/" + chunkIndex
+	// 3. 校验会话归属，以及分块索引是否在 [0, chunkcount) 范围内
+	sess, err := redis.Strings(rConn.Do("HMGET", ChunkKeyPrefix+uploadID,
+		"username", "chunkcount", "chunksize", "filesize"))
+	if err != nil || sess[0] == "" || sess[0] != username {
+		c.JSON(
+			http.StatusOK,
+			gin.H{
+				"code": -1,
+				"msg":  "Upload session not found",
+				"data": nil,
+			})
+		return
+	}
+	chunkCount, _ := strconv.Atoi(sess[1])
+	chunkSize, _ := strconv.Atoi(sess[2])
+	fileSize, _ := strconv.Atoi(sess[3])
+	idx, err := strconv.Atoi(chunkIndex)
+	if err != nil || idx < 0 || idx >= chunkCount {
+		c.JSON(
+			http.StatusOK,
+			gin.H{
+				"code": -1,
+				"msg":  "Invalid chunk index:" + chunkIndex,
+				"data": nil,
+			})
+		return
+	}
+
+	// 除最后一块外，每个分块的大小都必须等于初始化时协商的chunksize
+	expectSize := chunkSize
+	if idx == chunkCount-1 {
+		expectSize = fileSize - chunkSize*(chunkCount-1)
+	}
+
+	// 4. 获得文件句柄，用于存储分块内容
+	chunkIndex = strconv.Itoa(idx)
+	fpath := layout.ChunkDir(uploadID) + chunkIndex
 	os.MkdirAll(path.Dir(fpath), 0744)
 	fd, err := os.Create(fpath)
 	if err != nil {
@@ -185,13 +330,21 @@ func UploadPartHandler(c *gin.Context) {
 	}
 	defer fd.Close()
 
-	buf := make([]byte, 1024*1024)
-	for {
-		n, err := c.Request.Body.Read(buf)
-		fd.Write(buf[:n])
-		if err != nil {
-			break
-		}
+	// 多读一个字节，用于判断分块是否超出预期大小
+	written, err := io.Copy(fd, io.LimitReader(c.Request.Body, int64(expectSize)+1))
+	if err != nil || written != int64(expectSize) {
+		log.Printf("Chunk size mismatch, chkIdx:%s expect:%d got:%d err:%+v\n",
+			chunkIndex, expectSize, written, err)
+		fd.Close()
+		os.Remove(fpath)
+		c.JSON(
+			http.StatusOK,
+			gin.H{
+				"code": -3,
+				"msg":  "Invalid chunk size, chkIdx:" + chunkIndex,
+				"data": nil,
+			})
+		return
 	}
 
 	// 校验分块hash (updated at 2020-05)
@@ -209,10 +362,10 @@ func UploadPartHandler(c *gin.Context) {
 		return
 	}
 
-	// 4. 更新redis缓存状态
+	// 5. 更新redis缓存状态
 	rConn.Do("HSET", "MP_"+uploadID, "chkidx_"+chunkIndex, 1)
 
-	// 5. 返回处理结果到客户端
+	// 6. 返回处理结果到客户端
 	c.JSON(
 		http.StatusOK,
 		gin.H{
@@ -227,8 +380,6 @@ func CompleteUploadHandler(c *gin.Context) {
 	// 1. 解析请求参数
 	upid := c.Request.FormValue("uploadid")
 	username := c.Request.FormValue("username")
-	filehash := c.Request.FormValue("filehash")
-	filesize := c.Request.FormValue("filesize")
 	filename := c.Request.FormValue("filename")
 
 	// 2. 获得redis连接池中的一个连接
@@ -236,8 +387,8 @@ func CompleteUploadHandler(c *gin.Context) {
 	defer rConn.Close()
 
 	// 3. 通过uploadid查询redis并判断是否所有分块上传完成
-	data, err := redis.Values(rConn.Do("HGETALL", "MP_"+upid))
-	if err != nil {
+	upStatus, err := loadUploadStatus(rConn, upid)
+	if err != nil || upStatus == nil || upStatus.Owner != username {
 		c.JSON(
 			http.StatusOK,
 			gin.H{
@@ -247,18 +398,12 @@ func CompleteUploadHandler(c *gin.Context) {
 			})
 		return
 	}
-	totalCount := 0
-	chunkCount := 0
-	for i := 0; i < len(data); i += 2 {
-		k := string(data[i].([]byte))
-		v := string(data[i+1].([]byte))
-		if k == "chunkcount" {
-			totalCount, _ = strconv.Atoi(v)
-		} else if strings.HasPrefix(k, "chkidx_") && v == "1" {
-			chunkCount++
-		}
-	}
-	if totalCount != chunkCount {
+	// 文件hash及大小以初始化时记录的为准
+	filehash := upStatus.FileHash
+	totalCount := upStatus.ChunkCount
+	chunkCount := len(upStatus.ChunkExists)
+	totalSize := upStatus.FileSize
+	if totalCount == 0 || totalCount != chunkCount {
 		c.JSON(
 			http.StatusOK,
 			gin.H{
@@ -271,10 +416,15 @@ func CompleteUploadHandler(c *gin.Context) {
 
 	// 4. TODO：合并分块, 可以将ceph当临时存储，合并时将文件写入ceph;
 	// 也可以不用在本地进行合并，转移的时候将分块append到ceph/oss即可
-	srcPath := config.ChunkLocalRootDir + upid + "/"
-	destPath := config.MergeLocalRootDir + filehash
-	cmd := fmt.Sprintf("cd %s && ls | sort -n | xargs cat > %s", srcPath, destPath)
-	mergeRes, err := util.ExecLinuxShell(cmd)
+	srcPath := layout.ChunkDir(upid)
+	destPath := layout.MergePath(filehash)
+	// 合并命令在分块目录下执行, 临时文件须使用绝对路径
+	mergedPath, err := filepath.Abs(config.TempLocalRootDir + upid)
+	var mergeRes string
+	if err == nil {
+		cmd := fmt.Sprintf("cd %s && ls | sort -n | xargs cat > %s", srcPath, mergedPath)
+		mergeRes, err = util.ExecLinuxShell(cmd)
+	}
 	if err != nil {
 		log.Println(err)
 		c.JSON(
@@ -288,37 +438,70 @@ func CompleteUploadHandler(c *gin.Context) {
 	}
 	log.Println(mergeRes)
 
-	// 5. 更新唯一文件表及用户文件表
-	fsize, _ := strconv.Atoi(filesize)
+	// 合并后的文件大小须与初始化时声明的一致
+	if mergedSize := util.GetFileSize(mergedPath); mergedSize != int64(totalSize) {
+		log.Printf("Merged size mismatch, expect:%d got:%d\n", totalSize, mergedSize)
+		os.Remove(mergedPath)
+		c.JSON(
+			http.StatusOK,
+			gin.H{
+				"code": -3,
+				"msg":  "合并失败",
+				"data": nil,
+			})
+		return
+	}
+
+	// 合并后文件的sha1须与初始化时声明的一致, 否则他人秒传该hash时会得到错误的内容
+	if mergedHash, err := util.ComputeSha1ByShell(mergedPath); err != nil || mergedHash != filehash {
+		log.Printf("Merged sha1 mismatch, expect:%s got:%s\n", filehash, mergedHash)
+		os.Remove(mergedPath)
+		c.JSON(
+			http.StatusOK,
+			gin.H{
+				"code": -3,
+				"msg":  "合并失败",
+				"data": nil,
+			})
+		return
+	}
+
+	// 合并后的文件经加密写入本地存储
+	if err := backend.PutLocalFile(mergedPath, destPath); err != nil {
+		log.Println(err.Error())
+		os.Remove(mergedPath)
+		c.JSON(
+			http.StatusOK,
+			gin.H{
+				"code": -3,
+				"msg":  "合并失败",
+				"data": nil,
+			})
+		return
+	}
 
+	// 5. 更新唯一文件表及用户文件表
 	fileMeta := dbcli.FileMeta{
 		FileSha1: filehash,
 		FileName: filename,
-		FileSize: int64(fsize),
+		FileSize: int64(totalSize),
 		Location: destPath,
 	}
-	_, ferr := dbcli.OnFileUploadFinished(fileMeta)
-	_, uferr := dbcli.OnUserFileUploadFinished(username, fileMeta)
-	if ferr != nil || uferr != nil {
-		errMsg := ""
-		if ferr != nil {
-			errMsg = ferr.Error()
-		} else {
-			errMsg = uferr.Error()
-		}
-		log.Println(errMsg)
+	if err := saveFileMeta(username, fileMeta); err != nil {
+		log.Println(err.Error())
 		c.JSON(
 			http.StatusOK,
 			gin.H{
 				"code": -4,
 				"msg":  "数据更新失败",
-				"data": errMsg,
+				"data": err.Error(),
 			})
 		return
 	}
 
 	// 更新于2020-04: 删除已上传的分块文件及redis分块信息
-	_, delHashErr := rConn.Do("DEL", HashUpIDKeyPrefix+filehash)
+	_, delHashErr := rConn.Do("DEL", hashUpIDKey(username, filehash))
+	rConn.Do("SREM", UserUpIDKeyPrefix+username, upid)
 	delUploadID, delUploadInfoErr := redis.Int64(rConn.Do("DEL", ChunkKeyPrefix+upid))
 	if delUploadID != 1 || delUploadInfoErr != nil || delHashErr != nil {
 		c.JSON(
@@ -330,25 +513,12 @@ func CompleteUploadHandler(c *gin.Context) {
 			})
 		return
 	}
+	if !util.RemovePathByShell(srcPath) {
+		log.Println("Failed to remove chunk dir: " + srcPath)
+	}
 
 	// 6. 异步文件转移
-	ossPath := config.OSSRootDir + fileMeta.FileSha1
-	transMsg := mq.TransferData{
-		FileHash:      fileMeta.FileSha1,
-		Location:   fileMeta.Location,
-		DestLocation:  ossPath,
-		DestStoreType: common.StoreOSS,
-	}
-	pubData, _ := json.Marshal(transMsg)
-	pubSuc := mq.Publish(
-		config.TransExchangeName,
-		config.TransOSSRoutingKey,
-		pubData,
-	)
-	if !pubSuc {
-		// TODO: 当前发送转移信息失败，稍后重试
-		fmt.Println("publish transfer data failed, sha1: " + fileMeta.FileSha1)
-	}
+	publishTransfer(username, fileMeta)
 
 	// 7. 响应处理结果
 	c.JSON(
@@ -360,3 +530,112 @@ func CompleteUploadHandler(c *gin.Context) {
 		})
 }
 
+// saveFileMeta : 合并完成后更新唯一文件表及用户文件表
+func saveFileMeta(username string, fileMeta dbcli.FileMeta) error {
+	if _, err := dbcli.OnFileUploadFinished(fileMeta); err != nil {
+		return err
+	}
+	if _, err := dbcli.OnUserFileUploadFinished(username, fileMeta); err != nil {
+		return err
+	}
+	return nil
+}
+
+// publishTransfer : 按存储策略提交异步转移任务, 将本地文件转移到Ceph/OSS
+func publishTransfer(username string, fileMeta dbcli.FileMeta) {
+	err := policy.Enqueue(jobs.Enqueue, policy.File{
+		UserName: username,
+		FileName: fileMeta.FileName,
+		FileSize: fileMeta.FileSize,
+		FileHash: fileMeta.FileSha1,
+		Location: fileMeta.Location,
+	})
+	if err != nil {
+		log.Println("enqueue transfer job failed, sha1: " + fileMeta.FileSha1 + ", " + err.Error())
+	}
+}
+
+// UploadStatusHandler : 查询分块上传会话的状态
+func UploadStatusHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
+	uploadID := c.Request.FormValue("uploadid")
+
+	rConn := rPool.Pool().Get()
+	defer rConn.Close()
+
+	upStatus, err := loadUploadStatus(rConn, uploadID)
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(
+			http.StatusOK,
+			gin.H{
+				"code": -1,
+				"msg":  "服务错误",
+				"data": nil,
+			})
+		return
+	}
+	// 不属于当前用户的会话按不存在处理
+	if upStatus == nil || upStatus.Owner != username {
+		c.JSON(
+			http.StatusOK,
+			gin.H{
+				"code": -2,
+				"msg":  "upload session not found",
+				"data": nil,
+			})
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		gin.H{
+			"code": 0,
+			"msg":  "OK",
+			"data": upStatus,
+		})
+}
+
+// ListUploadsHandler : 查询当前用户进行中的分块上传会话
+func ListUploadsHandler(c *gin.Context) {
+	username := c.Request.FormValue("username")
+
+	rConn := rPool.Pool().Get()
+	defer rConn.Close()
+
+	uploadIDs, err := redis.Strings(rConn.Do("SMEMBERS", UserUpIDKeyPrefix+username))
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(
+			http.StatusOK,
+			gin.H{
+				"code": -1,
+				"msg":  "服务错误",
+				"data": nil,
+			})
+		return
+	}
+
+	uploads := []*MultipartUploadStatus{}
+	for _, uploadID := range uploadIDs {
+		upStatus, err := loadUploadStatus(rConn, uploadID)
+		if err != nil {
+			log.Println(err.Error())
+			continue
+		}
+		// 会话已过期，顺带从集合中清除
+		if upStatus == nil {
+			rConn.Do("SREM", UserUpIDKeyPrefix+username, uploadID)
+			continue
+		}
+		uploads = append(uploads, upStatus)
+	}
+
+	c.JSON(
+		http.StatusOK,
+		gin.H{
+			"code": 0,
+			"msg":  "OK",
+			"data": uploads,
+		})
+}
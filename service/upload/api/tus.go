@@ -0,0 +1,434 @@
+package api
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomodule/redigo/redis"
+
+	rPool "github.com/cloud/cache/redis"
+	"github.com/cloud/config"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	upCfg "github.com/cloud/service/upload/config"
+	"github.com/cloud/store/backend"
+	"github.com/cloud/store/layout"
+	"github.com/cloud/util"
+)
+
+// tus 1.0 协议(https://tus.io/protocols/resumable-upload)相关常量
+const (
+	// TusVersion : 支持的tus协议版本
+	TusVersion = "1.0.0"
+	// TusExtensions : 支持的tus扩展
+	TusExtensions = "creation,termination,checksum,expiration"
+	// TusChecksumAlgorithms : 支持的校验算法
+	TusChecksumAlgorithms = "sha1"
+	// TusBasePath : tus上传入口
+	TusBasePath = "/files/"
+	// tusLockKeyPrefix : 同一uploadID的PATCH请求互斥锁
+	tusLockKeyPrefix = "LOCK_TUS_"
+	// statusChecksumMismatch : tus checksum扩展定义的校验失败状态码
+	statusChecksumMismatch = 460
+)
+
+// errTusLockLost : 写入过程中PATCH锁续期失败(已过期或被其他请求持有)
+var errTusLockLost = errors.New("tus upload lock lost")
+
+// TusOptionsHandler : 返回服务端支持的tus版本及扩展
+func TusOptionsHandler(c *gin.Context) {
+	c.Header("Tus-Resumable", TusVersion)
+	c.Header("Tus-Version", TusVersion)
+	c.Header("Tus-Extension", TusExtensions)
+	c.Header("Tus-Max-Size", strconv.FormatInt(upCfg.TusMaxSize, 10))
+	c.Header("Tus-Checksum-Algorithm", TusChecksumAlgorithms)
+	c.Status(http.StatusNoContent)
+}
+
+// TusCreateHandler : 创建上传会话(creation扩展)
+func TusCreateHandler(c *gin.Context) {
+	if !checkTusResumable(c) {
+		return
+	}
+	username := c.Request.FormValue("username")
+
+	// 1. 解析文件大小及元信息
+	fileSize, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || fileSize < 0 {
+		c.String(http.StatusBadRequest, "invalid Upload-Length")
+		return
+	}
+	if fileSize > upCfg.TusMaxSize {
+		c.String(http.StatusRequestEntityTooLarge, "upload exceeds Tus-Max-Size")
+		return
+	}
+	metadata := parseTusMetadata(c.GetHeader("Upload-Metadata"))
+
+	uploadID, err := util.RandomHex(16)
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	// 2. 将会话信息写入redis, 与分块上传共用MP_前缀, 便于状态查询及过期清理
+	rConn := rPool.Pool().Get()
+	defer rConn.Close()
+
+	hkey := ChunkKeyPrefix + uploadID
+	rConn.Do("HSET", hkey, "protocol", "tus")
+	rConn.Do("HSET", hkey, "filesize", fileSize)
+	rConn.Do("HSET", hkey, "filename", metadata["filename"])
+	rConn.Do("HSET", hkey, "offset", 0)
+	rConn.Do("HSET", hkey, "username", username)
+	rConn.Do("HSET", hkey, "createat", time.Now().Unix())
+	rConn.Do("EXPIRE", hkey, uploadSessionTTL)
+	rConn.Do("SADD", UserUpIDKeyPrefix+username, uploadID)
+	rConn.Do("EXPIRE", UserUpIDKeyPrefix+username, uploadSessionTTL)
+
+	if err := os.MkdirAll(layout.ChunkDir(uploadID), 0744); err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	// 3. 返回上传地址, 保留查询参数以便后续请求携带用户凭证
+	location := TusBasePath + uploadID
+	if c.Request.URL.RawQuery != "" {
+		location += "?" + c.Request.URL.RawQuery
+	}
+	c.Header("Tus-Resumable", TusVersion)
+	c.Header("Location", location)
+	c.Header("Upload-Expires", tusExpires(rConn, uploadID))
+	c.Status(http.StatusCreated)
+}
+
+// TusHeadHandler : 查询上传偏移量
+func TusHeadHandler(c *gin.Context) {
+	if !checkTusResumable(c) {
+		return
+	}
+	rConn := rPool.Pool().Get()
+	defer rConn.Close()
+
+	sess, ok := loadTusSession(c, rConn)
+	if !ok {
+		return
+	}
+
+	c.Header("Tus-Resumable", TusVersion)
+	c.Header("Cache-Control", "no-store")
+	c.Header("Upload-Offset", strconv.FormatInt(sess.offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(sess.fileSize, 10))
+	c.Header("Upload-Expires", tusExpires(rConn, sess.uploadID))
+	c.Status(http.StatusOK)
+}
+
+// TusPatchHandler : 从指定偏移量追加上传数据
+func TusPatchHandler(c *gin.Context) {
+	if !checkTusResumable(c) {
+		return
+	}
+	if c.GetHeader("Content-Type") != "application/offset+octet-stream" {
+		c.String(http.StatusUnsupportedMediaType, "Content-Type must be application/offset+octet-stream")
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		c.String(http.StatusBadRequest, "invalid Upload-Offset")
+		return
+	}
+
+	rConn := rPool.Pool().Get()
+	defer rConn.Close()
+
+	sess, ok := loadTusSession(c, rConn)
+	if !ok {
+		return
+	}
+
+	// 1. 同一会话同时只允许一个PATCH写入
+	lockOwner, _ := util.RandomHex(8)
+	lockKey := tusLockKeyPrefix + sess.uploadID
+	if !rPool.TryLock(lockKey, lockOwner, upCfg.TusLockTTL) {
+		c.String(http.StatusConflict, "upload is locked by another request")
+		return
+	}
+	defer rPool.Unlock(lockKey, lockOwner)
+
+	// 加锁后重新读取偏移量, 避免使用过期的状态
+	if sess, ok = loadTusSession(c, rConn); !ok {
+		return
+	}
+	if offset != sess.offset {
+		c.String(http.StatusConflict, "Upload-Offset mismatch")
+		return
+	}
+
+	// 2. 以起始偏移量作为块文件名写入分块目录, 合并时按数值排序即可
+	if offset < sess.fileSize {
+		body := &tusLockedReader{r: c.Request.Body, key: lockKey, owner: lockOwner}
+		written, sum, err := writeTusChunk(sess.uploadID, offset, body, sess.fileSize-offset)
+		if err == errTusLockLost {
+			c.String(http.StatusConflict, "upload lock expired")
+			return
+		}
+		if err != nil {
+			log.Println(err.Error())
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		// 3. checksum扩展: 校验失败则丢弃本次数据
+		if checksum := c.GetHeader("Upload-Checksum"); checksum != "" {
+			algo, expect := parseTusChecksum(checksum)
+			if algo != TusChecksumAlgorithms {
+				os.Remove(tusChunkPath(sess.uploadID, offset))
+				c.String(http.StatusBadRequest, "unsupported checksum algorithm")
+				return
+			}
+			if expect != base64.StdEncoding.EncodeToString(sum) {
+				os.Remove(tusChunkPath(sess.uploadID, offset))
+				c.String(statusChecksumMismatch, "Checksum Mismatch")
+				return
+			}
+		}
+		if written == 0 {
+			os.Remove(tusChunkPath(sess.uploadID, offset))
+		}
+
+		// 记录偏移量前确认仍持有锁, 否则本次写入可能已与其他请求重叠
+		if !rPool.RenewLock(lockKey, lockOwner, upCfg.TusLockTTL) {
+			c.String(http.StatusConflict, "upload lock expired")
+			return
+		}
+		sess.offset += written
+		rConn.Do("HSET", ChunkKeyPrefix+sess.uploadID, "offset", sess.offset)
+		rConn.Do("EXPIRE", ChunkKeyPrefix+sess.uploadID, uploadSessionTTL)
+	}
+
+	// 4. 所有数据上传完毕则合并文件并走与普通上传相同的入库及转移流程
+	if sess.offset == sess.fileSize {
+		if err := finishTusUpload(rConn, sess); err != nil {
+			log.Println(err.Error())
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	c.Header("Tus-Resumable", TusVersion)
+	c.Header("Upload-Offset", strconv.FormatInt(sess.offset, 10))
+	if sess.offset < sess.fileSize {
+		c.Header("Upload-Expires", tusExpires(rConn, sess.uploadID))
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// TusDeleteHandler : 终止上传并清理已上传数据(termination扩展)
+func TusDeleteHandler(c *gin.Context) {
+	if !checkTusResumable(c) {
+		return
+	}
+	rConn := rPool.Pool().Get()
+	defer rConn.Close()
+
+	sess, ok := loadTusSession(c, rConn)
+	if !ok {
+		return
+	}
+	removeTusSession(rConn, sess)
+
+	c.Header("Tus-Resumable", TusVersion)
+	c.Status(http.StatusNoContent)
+}
+
+// tusSession : tus上传会话
+type tusSession struct {
+	uploadID string
+	username string
+	fileName string
+	fileSize int64
+	offset   int64
+}
+
+// checkTusResumable : 校验客户端使用的tus协议版本
+func checkTusResumable(c *gin.Context) bool {
+	if c.GetHeader("Tus-Resumable") != TusVersion {
+		c.Header("Tus-Version", TusVersion)
+		c.Status(http.StatusPreconditionFailed)
+		return false
+	}
+	return true
+}
+
+// loadTusSession : 读取当前请求对应的tus会话, 会话不存在或不属于当前用户时返回404
+func loadTusSession(c *gin.Context, rConn redis.Conn) (*tusSession, bool) {
+	uploadID := c.Param("id")
+	vals, err := redis.Strings(rConn.Do("HMGET", ChunkKeyPrefix+uploadID,
+		"protocol", "username", "filename", "filesize", "offset"))
+	if err != nil || vals[0] != "tus" || vals[1] != c.Request.FormValue("username") {
+		c.Header("Tus-Resumable", TusVersion)
+		c.Status(http.StatusNotFound)
+		return nil, false
+	}
+
+	sess := tusSession{
+		uploadID: uploadID,
+		username: vals[1],
+		fileName: vals[2],
+	}
+	sess.fileSize, _ = strconv.ParseInt(vals[3], 10, 64)
+	sess.offset, _ = strconv.ParseInt(vals[4], 10, 64)
+	return &sess, true
+}
+
+// tusChunkPath : 块文件路径, 文件名为该块的起始偏移量
+func tusChunkPath(uploadID string, offset int64) string {
+	return layout.ChunkDir(uploadID) + strconv.FormatInt(offset, 10)
+}
+
+// tusLockedReader : 读取PATCH请求体, 每upCfg.TusLockTTL/3续期一次写入锁, 续期失败时返回errTusLockLost
+type tusLockedReader struct {
+	r       io.Reader
+	key     string
+	owner   string
+	renewAt time.Time
+}
+
+func (l *tusLockedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	now := time.Now()
+	if l.renewAt.IsZero() {
+		l.renewAt = now.Add(time.Duration(upCfg.TusLockTTL) * time.Second / 3)
+	} else if now.After(l.renewAt) {
+		if !rPool.RenewLock(l.key, l.owner, upCfg.TusLockTTL) {
+			return 0, errTusLockLost
+		}
+		l.renewAt = now.Add(time.Duration(upCfg.TusLockTTL) * time.Second / 3)
+	}
+	return n, err
+}
+
+// writeTusChunk : 写入一块数据, 返回写入的字节数及其sha1. 写入锁丢失时返回errTusLockLost,
+// 块文件可能已由持有锁的请求重新写入, 不做清理
+func writeTusChunk(uploadID string, offset int64, body io.Reader, limit int64) (int64, []byte, error) {
+	fd, err := os.Create(tusChunkPath(uploadID, offset))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer fd.Close()
+
+	hash := sha1.New()
+	written, err := io.Copy(io.MultiWriter(fd, hash), io.LimitReader(body, limit))
+	if err == errTusLockLost {
+		log.Printf("tus patch aborted, uploadID:%s written:%d err:%s\n", uploadID, written, err.Error())
+		return written, nil, err
+	}
+	if err != nil {
+		// 连接中断时保留已接收的数据, 客户端可从新的偏移量继续上传
+		log.Printf("tus patch interrupted, uploadID:%s written:%d err:%s\n", uploadID, written, err.Error())
+	}
+	return written, hash.Sum(nil), nil
+}
+
+// finishTusUpload : 合并块文件, 更新文件表并发起异步转移
+func finishTusUpload(rConn redis.Conn, sess *tusSession) error {
+	srcPath := layout.ChunkDir(sess.uploadID)
+	// 合并命令在分块目录下执行, 临时文件须使用绝对路径
+	tmpPath, err := filepath.Abs(config.TempLocalRootDir + sess.uploadID)
+	if err != nil {
+		return err
+	}
+	cmd := fmt.Sprintf("cd %s && ls | sort -n | xargs cat > %s", srcPath, tmpPath)
+	if sess.fileSize == 0 {
+		cmd = "touch " + tmpPath
+	}
+	if _, err := util.ExecLinuxShell(cmd); err != nil {
+		return err
+	}
+
+	// tus不提供文件hash, 合并后计算并以hash作为最终存储路径
+	filehash, err := util.ComputeSha1ByShell(tmpPath)
+	if err != nil {
+		return err
+	}
+	destPath := layout.MergePath(filehash)
+	if err := backend.PutLocalFile(tmpPath, destPath); err != nil {
+		return err
+	}
+
+	fileName := sess.fileName
+	if fileName == "" {
+		fileName = filehash
+	}
+	fileMeta := dbcli.FileMeta{
+		FileSha1: filehash,
+		FileName: fileName,
+		FileSize: sess.fileSize,
+		Location: destPath,
+		UploadAt: time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if err := saveFileMeta(sess.username, fileMeta); err != nil {
+		return err
+	}
+
+	removeTusSession(rConn, sess)
+	publishTransfer(sess.username, fileMeta)
+	return nil
+}
+
+// removeTusSession : 删除redis会话及本地块文件
+func removeTusSession(rConn redis.Conn, sess *tusSession) {
+	rConn.Do("DEL", ChunkKeyPrefix+sess.uploadID)
+	rConn.Do("SREM", UserUpIDKeyPrefix+sess.username, sess.uploadID)
+	if !util.RemovePathByShell(layout.ChunkDir(sess.uploadID)) {
+		log.Println("Failed to remove chunk dir: " + sess.uploadID)
+	}
+}
+
+// tusExpires : 会话过期时间(expiration扩展), RFC 7231格式
+func tusExpires(rConn redis.Conn, uploadID string) string {
+	ttl, err := redis.Int64(rConn.Do("TTL", ChunkKeyPrefix+uploadID))
+	if err != nil || ttl < 0 {
+		ttl = uploadSessionTTL
+	}
+	return time.Now().Add(time.Duration(ttl) * time.Second).UTC().Format(http.TimeFormat)
+}
+
+// parseTusMetadata : 解析Upload-Metadata, 格式为逗号分隔的"key base64(value)"
+func parseTusMetadata(header string) map[string]string {
+	metadata := map[string]string{}
+	for _, pair := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), " ", 2)
+		if kv[0] == "" {
+			continue
+		}
+		if len(kv) == 1 {
+			metadata[kv[0]] = ""
+			continue
+		}
+		if v, err := base64.StdEncoding.DecodeString(kv[1]); err == nil {
+			metadata[kv[0]] = string(v)
+		}
+	}
+	return metadata
+}
+
+// parseTusChecksum : 解析Upload-Checksum, 格式为"algorithm base64(checksum)"
+func parseTusChecksum(header string) (string, string) {
+	kv := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(kv) != 2 {
+		return "", ""
+	}
+	return kv[0], kv[1]
+}
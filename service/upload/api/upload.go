@@ -2,10 +2,8 @@ package api
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
@@ -15,13 +13,22 @@ import (
 
 	"github.com/cloud/common"
 	cmnCfg "github.com/cloud/config"
-	"github.com/cloud/mq"
+	"github.com/cloud/job"
 	dbcli "github.com/cloud/service/dbproxy/client"
-	"github.com/cloud/store/ceph"
-	"github.com/cloud/store/oss"
+	"github.com/cloud/store/backend"
+	"github.com/cloud/store/layout"
+	"github.com/cloud/store/policy"
 	"github.com/cloud/util"
 )
 
+// jobs : 提交异步转移任务使用的Client, 由Setup设置
+var jobs *job.Client
+
+// Setup : 设置提交异步转移任务使用的Client, 须在启动API服务前调用
+func Setup(jobClient *job.Client) {
+	jobs = jobClient
+}
+
 func init() {
 	if err := os.MkdirAll(cmnCfg.TempLocalRootDir, 0744); err != nil {
 		fmt.Println("无法指定目录用于存储临时文件: " + cmnCfg.TempLocalRootDir)
@@ -78,61 +85,40 @@ func DoUploadHandler(c *gin.Context) {
 	}
 
 	// 4. 将文件写入临时存储位置
-	fileMeta.Location = cmnCfg.MergeLocalRootDir + fileMeta.FileSha1 // 存储地址
-	newFile, err := os.Create(fileMeta.Location)
-	if err != nil {
-		log.Printf("Failed to create file, err:%s\n", err.Error())
+	fileMeta.Location = layout.MergePath(fileMeta.FileSha1) // 存储地址
+	if err = backend.Put(common.StoreLocal, fileMeta.Location, bytes.NewReader(buf.Bytes())); err != nil {
+		log.Printf("Failed to save data into file, err:%s\n", err.Error())
 		errCode = -3
 		return
 	}
-	defer newFile.Close()
 
-	nByte, err := newFile.Write(buf.Bytes())
-	if int64(nByte) != fileMeta.FileSize || err != nil {
-		log.Printf("Failed to save data into file, writtenSize:%d, err:%s\n", nByte, err.Error())
-		errCode = -4
-		return
+	// 5. 按存储策略同步或异步将文件转移到Ceph/OSS
+	username := c.Request.FormValue("username")
+	stored := policy.File{
+		UserName: username,
+		FileName: fileMeta.FileName,
+		FileSize: fileMeta.FileSize,
+		FileHash: fileMeta.FileSha1,
+		Location: fileMeta.Location,
 	}
-
-	// 5. 同步或异步将文件转移到Ceph/OSS
-	newFile.Seek(0, 0) // 游标重新回到文件头部
-	if cmnCfg.CurrentStoreType == common.StoreCeph {
-		// 文件写入Ceph存储
-		data, _ := ioutil.ReadAll(newFile)
-		cephPath := cmnCfg.CephRootDir + fileMeta.FileSha1
-		_ = ceph.PutObject("userfile", cephPath, data)
-		fileMeta.Location = cephPath
-	} else if cmnCfg.CurrentStoreType == common.StoreOSS {
-		// 文件写入OSS存储
-		ossPath := cmnCfg.OSSRootDir + fileMeta.FileSha1
-		// 判断写入OSS为同步还是异步
-		if !cmnCfg.AsyncTransferEnable {
-			// TODO: 设置oss中的文件名，方便指定文件名下载
-			err = oss.Bucket().PutObject(ossPath, newFile)
-			if err != nil {
+	if !cmnCfg.AsyncTransferEnable {
+		// 同步写入各目标存储, 文件表中记录主存储的位置
+		for _, t := range policy.Transfers(stored) {
+			if err = backend.Put(t.DestStoreType, t.DestLocation, bytes.NewReader(buf.Bytes())); err != nil {
 				log.Println(err.Error())
 				errCode = -5
 				return
 			}
-			fileMeta.Location = ossPath
-		} else {
-			// 写入异步转移任务队列
-			data := mq.TransferData{
-				FileHash:      fileMeta.FileSha1,
-				Location:   fileMeta.Location,
-				DestLocation:  ossPath,
-				DestStoreType: common.StoreOSS,
+			if _, err := dbcli.AddFileLocation(t.FileHash, t.DestStoreType, t.DestLocation); err != nil {
+				log.Println(err.Error())
 			}
-			pubData, _ := json.Marshal(data)
-			pubSuc := mq.Publish(
-				cmnCfg.TransExchangeName,
-				cmnCfg.TransOSSRoutingKey,
-				pubData,
-			)
-			if !pubSuc {
-				// TODO: 当前发送转移信息失败，稍后重试
+			if !t.Replica {
+				fileMeta.Location = t.DestLocation
 			}
 		}
+	} else if err := policy.Enqueue(jobs.Enqueue, stored); err != nil {
+		// 提交异步转移任务, 同一文件到同一存储只转移一次
+		log.Println("enqueue transfer job failed, sha1: " + fileMeta.FileSha1 + ", " + err.Error())
 	}
 
 	//6.  更新文件表记录
@@ -143,7 +129,6 @@ func DoUploadHandler(c *gin.Context) {
 	}
 
 	// 7. 更新用户文件表
-	username := c.Request.FormValue("username")
 	upRes, err := dbcli.OnUserFileUploadFinished(username, fileMeta)
 	if err == nil && upRes.Suc {
 		errCode = 0
@@ -161,7 +146,23 @@ func TryFastUploadHandler(c *gin.Context) {
 	filename := c.Request.FormValue("filename")
 	// filesize, _ := strconv.Atoi(c.Request.FormValue("filesize"))
 
-	// 2. 从文件表中查询相同hash的文件记录
+	// 2. 加密目录中的内容由客户端加密, 不支持秒传
+	folder, err := dbcli.VaultFolder(username, filename)
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	if folder != "" {
+		resp := util.RespMsg{
+			Code: int(common.StatusVaultUnsupported),
+			Msg:  "加密目录不支持秒传，请访问普通上传接口",
+		}
+		c.Data(http.StatusOK, "application/json", resp.JSONBytes())
+		return
+	}
+
+	// 3. 从文件表中查询相同hash的文件记录
 	fileMetaResp, err := dbcli.GetFileMeta(filehash)
 	if err != nil {
 		log.Println(err.Error())
@@ -169,8 +170,15 @@ func TryFastUploadHandler(c *gin.Context) {
 		return
 	}
 
-	// 3. 查不到记录则返回秒传失败 (2020-05更新，判断Data == nil)
-	if !fileMetaResp.Suc || fileMetaResp.Data == nil {
+	// 4. 查不到记录则返回秒传失败 (2020-05更新，判断Data == nil);
+	// 加密目录中保存的密文不作为秒传来源, 避免通过hash探测他人的加密文件
+	vaultFile, err := dbcli.IsVaultFileHash(filehash)
+	if err != nil {
+		log.Println(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	if !fileMetaResp.Suc || fileMetaResp.Data == nil || vaultFile {
 		resp := util.RespMsg{
 			Code: -1,
 			Msg:  "秒传失败，请访问普通上传接口",
@@ -179,7 +187,7 @@ func TryFastUploadHandler(c *gin.Context) {
 		return
 	}
 
-	// 4. 上传过则将文件信息写入用户文件表， 返回成功
+	// 5. 上传过则将文件信息写入用户文件表， 返回成功
 	tblFile := dbcli.ToTableFile(fileMetaResp.Data)
 	fmeta := dbcli.TableFileToFileMeta(tblFile)
 	fmeta.FileName = filename
@@ -1,8 +1,36 @@
 package config
 
+import "time"
+
 // UploadEntry : 配置上传入口地址
 var UploadEntry = "127.0.0.1:28080"
 
 // UploadServiceHost : 上传服务监听的地址
 var UploadServiceHost = "0.0.0.0:28080"
 
+// UploadAdminHost : 内部管理接口(运行指标)监听的地址, 只应绑定本机或内网地址
+var UploadAdminHost = "127.0.0.1:28081"
+
+// ChunkSizeDefault : 客户端未指定分块大小时使用的默认值
+var ChunkSizeDefault = 5 * 1024 * 1024
+
+// ChunkSizeMin : 客户端可协商的最小分块大小
+var ChunkSizeMin = 1 * 1024 * 1024
+
+// ChunkSizeMax : 客户端可协商的最大分块大小
+var ChunkSizeMax = 64 * 1024 * 1024
+
+// MaxParallelChunks : 单个上传会话建议的最大并发分块上传数
+var MaxParallelChunks = 4
+
+// JanitorInterval : 清理过期分块目录的执行间隔
+var JanitorInterval = 30 * time.Minute
+
+// JanitorGracePeriod : 分块目录最后修改后至少保留的时长, 避免误删正在写入的目录
+var JanitorGracePeriod = 10 * time.Minute
+
+// TusMaxSize : tus协议允许上传的最大文件大小
+var TusMaxSize int64 = 10 * 1024 * 1024 * 1024
+
+// TusLockTTL : tus PATCH写入锁的有效期(秒), 写入过程中每TusLockTTL/3续期一次
+var TusLockTTL = 60
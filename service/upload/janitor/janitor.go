@@ -0,0 +1,104 @@
+package janitor
+
+import (
+	"expvar"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+
+	rPool "github.com/cloud/cache/redis"
+	cmnCfg "github.com/cloud/config"
+	"github.com/cloud/service/upload/api"
+	"github.com/cloud/service/upload/config"
+	"github.com/cloud/store/layout"
+	"github.com/cloud/util"
+)
+
+// lockPrefix : 清理任务的锁. 分块目录位于各节点本地, 每个节点清理自己的目录,
+// 锁以主机名区分, 同一主机上只允许一个upload进程执行清理
+const lockPrefix = "LOCK_UPLOAD_JANITOR_"
+
+var (
+	// reclaimedBytes : 累计回收的分块字节数
+	reclaimedBytes = expvar.NewInt("janitor_reclaimed_bytes")
+	// removedDirs : 累计删除的分块目录数
+	removedDirs = expvar.NewInt("janitor_removed_dirs")
+	// lastRunAt : 最近一次执行清理的时间
+	lastRunAt = expvar.NewString("janitor_last_run_at")
+)
+
+// Start : 定期清理本节点上已失效上传会话遗留的分块目录
+func Start() {
+	hostname, _ := os.Hostname()
+	lockKey := lockPrefix + hostname
+	owner := fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	// 锁只在清理期间持有, 有效期为一个执行周期, 进程在清理中退出后可由同主机的其他进程接手
+	lockTTL := int(config.JanitorInterval / time.Second)
+
+	ticker := time.NewTicker(config.JanitorInterval)
+	defer ticker.Stop()
+	for {
+		if rPool.TryLock(lockKey, owner, lockTTL) {
+			Sweep()
+			rPool.Unlock(lockKey, owner)
+		}
+		<-ticker.C
+	}
+}
+
+// Sweep : 扫描本节点的分块目录，删除redis中已无对应会话的目录
+func Sweep() {
+	lastRunAt.Set(time.Now().Format("2006-01-02 15:04:05"))
+
+	dirs, err := layout.ChunkDirs(cmnCfg.ChunkLocalRootDir)
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+
+	rConn := rPool.Pool().Get()
+	defer rConn.Close()
+
+	for _, dirPath := range dirs {
+		dir, err := os.Stat(dirPath)
+		if err != nil || time.Since(dir.ModTime()) < config.JanitorGracePeriod {
+			continue
+		}
+
+		// 目录名即uploadID, 会话仍存在则跳过
+		uploadID := dir.Name()
+		alive, err := redis.Bool(rConn.Do("EXISTS", api.ChunkKeyPrefix+uploadID))
+		if err != nil {
+			log.Println(err.Error())
+			return
+		}
+		if alive {
+			continue
+		}
+
+		size := dirSize(dirPath)
+		if !util.RemovePathByShell(dirPath) {
+			log.Printf("Failed to remove orphan chunk dir: %s\n", dirPath)
+			continue
+		}
+		reclaimedBytes.Add(size)
+		removedDirs.Add(1)
+		log.Printf("Removed orphan chunk dir: %s, reclaimed %d bytes\n", dirPath, size)
+	}
+}
+
+// dirSize : 统计目录下所有文件的总大小
+func dirSize(dirPath string) int64 {
+	var size int64
+	filepath.Walk(dirPath, func(path string, f os.FileInfo, err error) error {
+		if err == nil && !f.IsDir() {
+			size += f.Size()
+		}
+		return nil
+	})
+	return size
+}
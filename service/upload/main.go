@@ -1,13 +1,17 @@
 package main
 
 import (
-	"fmt"
 	cmncfg "github.com/cloud/config"
+	"github.com/cloud/job/dbqueue"
+	"github.com/cloud/mq"
+	"github.com/cloud/service/upload/api"
 	"github.com/cloud/service/upload/config"
+	"github.com/cloud/service/upload/janitor"
 	"github.com/cloud/service/upload/route"
 	"github.com/micro/go-micro"
 	upProto "github.com/cloud/service/upload/proto"
 	upRpc "github.com/cloud/service/upload/rpc"
+	"github.com/cloud/store/layout"
 	"log"
 	"time"
 )
@@ -31,10 +35,27 @@ func startAPIService() {
 	router.Run(config.UploadServiceHost)
 }
 
+func startAdminService() {
+	router := route.AdminRouter()
+	router.Run(config.UploadAdminHost)
+}
+
 func main() {
+	// 转移任务通过dbproxy保存、通过默认消息队列发布
+	api.Setup(dbqueue.NewClient(mq.Default()))
+
 	// 启动API服务
 	go startAPIService()
 
+	// 启动内部管理接口
+	go startAdminService()
+
+	// 检查本地存储中写入中断的文件
+	go layout.CheckLocal()
+
+	// 启动过期分块清理任务
+	go janitor.Start()
+
 	// 启动RPC服务
-	startRPCService()　
+	startRPCService()
 }
@@ -1,6 +1,9 @@
 package route
 
 import (
+	"expvar"
+
+	"github.com/cloud/middleware"
 	"github.com/cloud/service/upload/api"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -20,13 +23,20 @@ func Router() *gin.Engine {
 	// 使用gin插件支持跨域请求
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:  []string{"*"}, // []string{"http://localhost:8080"},
-		AllowMethods:  []string{"GET", "POST", "OPTIONS"},
-		AllowHeaders:  []string{"Origin", "Range", "x-requested-with", "content-Type"},
-		ExposeHeaders: []string{"Content-Length", "Accept-Ranges", "Content-Range", "Content-Disposition"},
+		AllowMethods:  []string{"GET", "POST", "OPTIONS", "HEAD", "PATCH", "DELETE"},
+		AllowHeaders:  []string{"Origin", "Range", "x-requested-with", "content-Type",
+			"Tus-Resumable", "Upload-Length", "Upload-Metadata", "Upload-Offset", "Upload-Checksum"},
+		ExposeHeaders: []string{"Content-Length", "Accept-Ranges", "Content-Range", "Content-Disposition",
+			"Location", "Tus-Resumable", "Tus-Version", "Tus-Extension", "Tus-Max-Size",
+			"Tus-Checksum-Algorithm", "Upload-Offset", "Upload-Length", "Upload-Expires"},
 		// AllowCredentials: true,
 	}))
 
+	// tus协议能力查询无需登录
+	router.OPTIONS(api.TusBasePath, api.TusOptionsHandler)
+
 	// Use之后的所有handler都会经过拦截器进行token校验
+	router.Use(middleware.HTTPInterceptor())
 
 	// 文件上传相关接口
 	router.POST("/file/upload", api.DoUploadHandler)
@@ -37,6 +47,27 @@ func Router() *gin.Engine {
 	router.POST("/file/mpupload/init", api.InitialMultipartUploadHandler)
 	router.POST("/file/mpupload/uppart", api.UploadPartHandler)
 	router.POST("/file/mpupload/complete", api.CompleteUploadHandler)
+	// 分块上传会话查询接口
+	router.GET("/file/mpupload/status", api.UploadStatusHandler)
+	router.POST("/file/mpupload/status", api.UploadStatusHandler)
+	router.GET("/file/mpupload/list", api.ListUploadsHandler)
+	router.POST("/file/mpupload/list", api.ListUploadsHandler)
+
+	// tus 1.0 断点续传接口
+	router.POST(api.TusBasePath, api.TusCreateHandler)
+	router.HEAD(api.TusBasePath+":id", api.TusHeadHandler)
+	router.PATCH(api.TusBasePath+":id", api.TusPatchHandler)
+	router.DELETE(api.TusBasePath+":id", api.TusDeleteHandler)
+
+	return router
+}
+
+// AdminRouter : 内部管理接口, 只在config.UploadAdminHost上监听, 不对外暴露
+func AdminRouter() *gin.Engine {
+	router := gin.Default()
+
+	// 运行指标(如分块清理回收的字节数)
+	router.GET("/debug/vars", gin.WrapH(expvar.Handler()))
 
 	return router
 }
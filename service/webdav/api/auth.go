@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	micro "github.com/micro/go-micro"
+
+	"github.com/cloud/common"
+	cmnCfg "github.com/cloud/config"
+	userProto "github.com/cloud/service/account/proto"
+	"github.com/cloud/service/webdav/config"
+)
+
+// usernameKey : 认证通过后保存在gin.Context中的用户名
+const usernameKey = "dav_username"
+
+var userCli userProto.UserService
+
+// authCache : 认证成功的用户名及密码摘要 -> 过期时间
+var authCache = struct {
+	sync.Mutex
+	entries map[string]time.Time
+}{entries: map[string]time.Time{}}
+
+func init() {
+	service := micro.NewService(
+		micro.Registry(cmnCfg.RegistryConsul()),
+	)
+	// 初始化，解析命令行参数
+	service.Init()
+	// 初始化一个account服务的rpcCli
+	userCli = userProto.NewUserService("go.micro.service.user", service.Client())
+}
+
+// BasicAuth : 基本认证中间件, 用户名及密码交由account服务校验
+func BasicAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, password, ok := c.Request.BasicAuth()
+		if !ok || username == "" {
+			unauthorized(c)
+			return
+		}
+
+		suc, err := authenticate(username, password)
+		if err != nil {
+			log.Println(err.Error())
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		if !suc {
+			unauthorized(c)
+			return
+		}
+		c.Set(usernameKey, username)
+		c.Next()
+	}
+}
+
+func unauthorized(c *gin.Context) {
+	c.Header("WWW-Authenticate", `Basic realm="`+config.AuthRealm+`", charset="UTF-8"`)
+	c.AbortWithStatus(http.StatusUnauthorized)
+}
+
+// authenticate : 校验用户名及密码, 成功结果在本地缓存AuthCacheTTL
+func authenticate(username, password string) (bool, error) {
+	sum := sha256.Sum256([]byte(username + "\x00" + password))
+	key := hex.EncodeToString(sum[:])
+
+	now := time.Now()
+	authCache.Lock()
+	expireAt, ok := authCache.entries[key]
+	authCache.Unlock()
+	if ok && now.Before(expireAt) {
+		return true, nil
+	}
+
+	rpcResp, err := userCli.Authenticate(context.TODO(), &userProto.ReqAuthenticate{
+		Username: username,
+		Password: password,
+	})
+	if err != nil {
+		return false, err
+	}
+	if rpcResp.Code == common.StatusServerError {
+		return false, errors.New("account service error: " + rpcResp.Message)
+	}
+	if rpcResp.Code != common.StatusOK {
+		return false, nil
+	}
+
+	authCache.Lock()
+	defer authCache.Unlock()
+	for k, t := range authCache.entries {
+		if now.After(t) {
+			delete(authCache.entries, k)
+		}
+	}
+	authCache.entries[key] = now.Add(config.AuthCacheTTL)
+	return true, nil
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+
+	"golang.org/x/net/webdav"
+
+	"github.com/cloud/store/userfs"
+)
+
+var (
+	errReadOnly      = errors.New("file is opened read-only")
+	errWriteOnly     = errors.New("file is opened write-only")
+	errUploadAborted = errors.New("upload aborted")
+)
+
+// davInfo : 在userfs.FileInfo的基础上实现webdav.ETager及webdav.ContentTyper,
+// 避免PROPFIND时为了获取属性而读取文件内容
+type davInfo struct {
+	*userfs.FileInfo
+}
+
+// ETag : 以文件sha1作为ETag
+func (fi *davInfo) ETag(ctx context.Context) (string, error) {
+	if fi.FileHash() == "" {
+		return "", webdav.ErrNotImplemented
+	}
+	return `"` + fi.FileHash() + `"`, nil
+}
+
+// ContentType : 按扩展名判断文件类型
+func (fi *davInfo) ContentType(ctx context.Context) (string, error) {
+	return fi.FileInfo.ContentType(), nil
+}
+
+// dirFile : 以只读方式打开的目录
+type dirFile struct {
+	fs   *userFS
+	name string
+	info os.FileInfo
+
+	children []os.FileInfo
+	loaded   bool
+}
+
+func (f *dirFile) Close() error                                 { return nil }
+func (f *dirFile) Read(p []byte) (int, error)                   { return 0, userfs.ErrIsDirectory }
+func (f *dirFile) Seek(offset int64, whence int) (int64, error) { return 0, userfs.ErrIsDirectory }
+func (f *dirFile) Write(p []byte) (int, error)                  { return 0, userfs.ErrIsDirectory }
+func (f *dirFile) Stat() (os.FileInfo, error)                   { return f.info, nil }
+
+func (f *dirFile) Readdir(count int) ([]os.FileInfo, error) {
+	if !f.loaded {
+		children, err := f.fs.fs.ReadDir(f.name)
+		if err != nil {
+			return nil, err
+		}
+		for i, child := range children {
+			children[i] = &davInfo{child.(*userfs.FileInfo)}
+		}
+		f.children, f.loaded = children, true
+	}
+	if count <= 0 {
+		children := f.children
+		f.children = nil
+		return children, nil
+	}
+	if len(f.children) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(f.children) {
+		count = len(f.children)
+	}
+	children := f.children[:count]
+	f.children = f.children[count:]
+	return children, nil
+}
+
+// readFile : 以只读方式打开的文件
+type readFile struct {
+	*userfs.Reader
+}
+
+// WriteTo : 复制到同一文件系统中的文件(COPY)时直接复用文件表中的记录, 无需读取文件内容
+func (f *readFile) WriteTo(w io.Writer) (int64, error) {
+	if wf, ok := w.(*writeFile); ok {
+		if offset, _ := f.Seek(0, io.SeekCurrent); offset == 0 && wf.writer.Link(f.Info()) == nil {
+			f.Seek(0, io.SeekEnd)
+			return f.Info().Size(), nil
+		}
+	}
+	return io.Copy(w, struct{ io.Reader }{f})
+}
+
+func (f *readFile) Readdir(count int) ([]os.FileInfo, error) { return nil, userfs.ErrNotDirectory }
+func (f *readFile) Write(p []byte) (int, error)              { return 0, errReadOnly }
+func (f *readFile) Stat() (os.FileInfo, error)               { return &davInfo{f.Info()}, nil }
+
+// writeFile : 以写方式打开的文件, 请求体完整读取后才提交
+type writeFile struct {
+	ctx    context.Context
+	writer *userfs.Writer
+	err    error
+}
+
+func (f *writeFile) Write(p []byte) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	n, err := f.writer.Write(p)
+	if err != nil {
+		f.err = err
+	}
+	return n, err
+}
+
+// Close : 上传中断时丢弃已写入的数据
+func (f *writeFile) Close() error {
+	if f.err == nil {
+		f.err = uploadError(f.ctx, f.writer.Size())
+	}
+	if f.err != nil {
+		f.writer.Abort()
+		return f.err
+	}
+	if err := f.writer.Commit(); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	return nil
+}
+
+func (f *writeFile) Read(p []byte) (int, error)                   { return 0, errWriteOnly }
+func (f *writeFile) Seek(offset int64, whence int) (int64, error) { return 0, errWriteOnly }
+func (f *writeFile) Readdir(count int) ([]os.FileInfo, error)     { return nil, userfs.ErrNotDirectory }
+func (f *writeFile) Stat() (os.FileInfo, error)                   { return &davInfo{f.writer.Info()}, nil }
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"os"
+
+	"golang.org/x/net/webdav"
+
+	"github.com/cloud/store/userfs"
+)
+
+// userFS : 将用户的目录树(userfs.FS)适配为webdav.FileSystem
+type userFS struct {
+	fs *userfs.FS
+}
+
+func (fs *userFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	return fs.fs.Mkdir(name)
+}
+
+func (fs *userFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) == 0 {
+		info, err := fs.fs.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			return &dirFile{fs: fs, name: name, info: &davInfo{info}}, nil
+		}
+		reader, err := fs.fs.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		return &readFile{reader}, nil
+	}
+
+	// 写入时总是以新内容整体替换同名文件
+	info, err := fs.fs.Stat(name)
+	if err == nil {
+		if info.IsDir() {
+			return nil, userfs.ErrIsDirectory
+		}
+		if flag&os.O_EXCL != 0 {
+			return nil, os.ErrExist
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	} else if flag&os.O_CREATE == 0 {
+		return nil, err
+	}
+	writer, err := fs.fs.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return &writeFile{ctx: ctx, writer: writer}, nil
+}
+
+func (fs *userFS) RemoveAll(ctx context.Context, name string) error {
+	return fs.fs.RemoveAll(name)
+}
+
+func (fs *userFS) Rename(ctx context.Context, oldName, newName string) error {
+	return fs.fs.Rename(oldName, newName)
+}
+
+func (fs *userFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	info, err := fs.fs.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	return &davInfo{info}, nil
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/webdav"
+
+	"github.com/cloud/service/webdav/config"
+	"github.com/cloud/store/userfs"
+)
+
+// putBodyKey : PUT请求体在context中的key
+type putBodyKey struct{}
+
+// putBody : 记录PUT请求体读取过程中的错误, 用于判断上传是否完整
+type putBody struct {
+	io.ReadCloser
+	length int64
+	err    error
+}
+
+func (b *putBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+	return n, err
+}
+
+// lockSystems : 每个用户独立的锁管理, 锁只保存在当前实例的内存中
+var lockSystems = struct {
+	sync.Mutex
+	m map[string]webdav.LockSystem
+}{m: map[string]webdav.LockSystem{}}
+
+func userLockSystem(username string) webdav.LockSystem {
+	lockSystems.Lock()
+	defer lockSystems.Unlock()
+	ls, ok := lockSystems.m[username]
+	if !ok {
+		ls = webdav.NewMemLS()
+		lockSystems.m[username] = ls
+	}
+	return ls
+}
+
+// DAVHandler : 处理WebDAV请求, 将当前用户的文件映射为WebDAV的目录树
+func DAVHandler(c *gin.Context) {
+	username := c.GetString(usernameKey)
+
+	req := c.Request
+	if req.Method == http.MethodPut {
+		body := &putBody{ReadCloser: req.Body, length: req.ContentLength}
+		req.Body = body
+		req = req.WithContext(context.WithValue(req.Context(), putBodyKey{}, body))
+	}
+
+	handler := &webdav.Handler{
+		Prefix:     config.WebDAVPrefix,
+		FileSystem: &userFS{fs: userfs.New(username)},
+		LockSystem: userLockSystem(username),
+		Logger: func(r *http.Request, err error) {
+			if err != nil {
+				log.Printf("webdav %s %s: %s\n", r.Method, r.URL.Path, err.Error())
+			}
+		},
+	}
+	handler.ServeHTTP(c.Writer, req)
+}
+
+// uploadError : 判断PUT请求体是否完整读取, 客户端断开或长度不符时返回错误
+func uploadError(ctx context.Context, size int64) error {
+	body, ok := ctx.Value(putBodyKey{}).(*putBody)
+	if !ok {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if body.err != nil {
+		return body.err
+	}
+	if body.length >= 0 && body.length != size {
+		return errUploadAborted
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package config
+
+import "time"
+
+// WebDAVEntry : WebDAV服务的入口地址
+var WebDAVEntry = "127.0.0.1:39100"
+
+// WebDAVHost : WebDAV服务监听的地址
+var WebDAVHost = "0.0.0.0:39100"
+
+// WebDAVPrefix : WebDAV的挂载路径, 客户端挂载 http://host:port/dav 即可访问用户的全部文件
+var WebDAVPrefix = "/dav"
+
+// AuthRealm : 基本认证的realm
+var AuthRealm = "cloud"
+
+// AuthCacheTTL : 认证成功后的缓存时间, 文件管理器会在每个请求中携带密码, 避免频繁调用account服务
+var AuthCacheTTL = 5 * time.Minute
@@ -0,0 +1,31 @@
+package route
+
+import (
+	"net/http"
+
+	"github.com/cloud/service/webdav/api"
+	"github.com/cloud/service/webdav/config"
+	"github.com/gin-gonic/gin"
+)
+
+// davMethods : WebDAV使用的请求方法, 其中大部分不在gin.Any的范围内
+var davMethods = []string{
+	http.MethodOptions, http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete,
+	"PROPFIND", "PROPPATCH", "MKCOL", "COPY", "MOVE", "LOCK", "UNLOCK",
+}
+
+// Router : WebDAV服务路由表配置
+func Router() *gin.Engine {
+	// gin framework, 包括Logger, Recovery
+	router := gin.Default()
+
+	// 所有请求都需通过基本认证
+	router.Use(api.BasicAuth())
+
+	for _, method := range davMethods {
+		router.Handle(method, config.WebDAVPrefix, api.DAVHandler)
+		router.Handle(method, config.WebDAVPrefix+"/*path", api.DAVHandler)
+	}
+
+	return router
+}
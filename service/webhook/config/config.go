@@ -0,0 +1,12 @@
+package config
+
+import "time"
+
+// RequestTimeout : 单次投递的超时时间
+var RequestTimeout = 10 * time.Second
+
+// GlobalScanInterval : 扫描全局webhook事件的间隔, 投递的重试由任务框架按job.TypeWebhook的退避计划进行
+var GlobalScanInterval = 5 * time.Second
+
+// GlobalEventSettle : 全局webhook只投递提交超过该时长的事件, 见orm.ListFileEvents
+var GlobalEventSettle = 5 * time.Second
@@ -0,0 +1,118 @@
+// Package dbstore : 通过dbproxy读写webhook及投递记录
+package dbstore
+
+import (
+	"errors"
+	"time"
+
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/service/dbproxy/orm"
+	"github.com/cloud/service/webhook/dispatcher"
+)
+
+// Store : 实现dispatcher.Store
+type Store struct{}
+
+// execResult : 将dbproxy的执行结果转换为错误
+func execResult(res *orm.ExecResult, err error) (*orm.ExecResult, error) {
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, errors.New("dbproxy: empty result")
+	}
+	if !res.Suc {
+		return nil, errors.New("dbproxy: " + res.Msg)
+	}
+	return res, nil
+}
+
+// Hooks : 实现dispatcher.Store
+func (Store) Hooks(username string) ([]dispatcher.Hook, error) {
+	res, err := execResult(dbcli.ListActiveWebhooks(username))
+	if err != nil {
+		return nil, err
+	}
+	hooks := []dispatcher.Hook{}
+	for _, h := range dbcli.ToTableWebhooks(res.Data) {
+		hooks = append(hooks, dispatcher.Hook{
+			ID:       h.ID,
+			UserName: h.UserName,
+			URL:      h.URL,
+			Secret:   h.Secret,
+			Events:   h.Events,
+			Cursor:   h.Cursor,
+		})
+	}
+	return hooks, nil
+}
+
+// Events : 实现dispatcher.Store
+func (Store) Events(hook dispatcher.Hook, before time.Time, limit int) ([]dispatcher.Event, error) {
+	var res *orm.ExecResult
+	var err error
+	if hook.UserName == "" {
+		res, err = execResult(dbcli.ListFileEvents(hook.Cursor, before.Unix(), limit))
+	} else {
+		res, err = execResult(dbcli.ListUserFileEvents(hook.UserName, hook.Cursor, limit))
+	}
+	if err != nil {
+		return nil, err
+	}
+	events := []dispatcher.Event{}
+	for _, ev := range dbcli.ToTableUserFileEvents(res.Data) {
+		events = append(events, dispatcher.Event(ev))
+	}
+	return events, nil
+}
+
+// AdvanceCursor : 实现dispatcher.Store
+func (Store) AdvanceCursor(hookID, cursor int64) error {
+	_, err := execResult(dbcli.AdvanceWebhookCursor(hookID, cursor))
+	return err
+}
+
+// CreateDelivery : 实现dispatcher.Store
+func (Store) CreateDelivery(d *dispatcher.Delivery) (bool, error) {
+	id, err := dbcli.CreateWebhookDelivery(d.HookID, d.EventID, d.UserName, d.Event,
+		d.Payload, d.NextRetryAt.Unix())
+	if err != nil || id == 0 {
+		return false, err
+	}
+	d.ID = id
+	return true, nil
+}
+
+// Delivery : 实现dispatcher.Store
+func (Store) Delivery(hookID, eventID int64) (*dispatcher.Delivery, error) {
+	res, err := execResult(dbcli.GetWebhookDelivery(hookID, eventID))
+	if err != nil {
+		return nil, err
+	}
+	d := dbcli.ToTableWebhookDelivery(res.Data)
+	if d.ID == 0 {
+		return nil, nil
+	}
+	return &dispatcher.Delivery{
+		ID:           d.ID,
+		HookID:       d.HookID,
+		EventID:      d.EventID,
+		UserName:     d.UserName,
+		Event:        d.Event,
+		Payload:      d.Payload,
+		Status:       d.Status,
+		Attempts:     d.Attempts,
+		ResponseCode: d.ResponseCode,
+		LastError:    d.LastError,
+		NextRetryAt:  time.Unix(d.NextRetryAt, 0),
+		URL:          d.URL,
+		Secret:       d.Secret,
+	}, nil
+}
+
+// UpdateDelivery : 实现dispatcher.Store
+func (Store) UpdateDelivery(d *dispatcher.Delivery) error {
+	_, err := execResult(dbcli.UpdateWebhookDelivery(d.ID, d.Status, d.Attempts, d.ResponseCode,
+		d.LastError, d.NextRetryAt.Unix()))
+	return err
+}
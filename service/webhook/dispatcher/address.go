@@ -0,0 +1,96 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress : webhook地址指向本机、内网或链路本地地址, 不允许投递
+var ErrForbiddenAddress = errors.New("webhook address is not allowed")
+
+// ErrInvalidURL : webhook地址不是http(s)绝对地址
+var ErrInvalidURL = errors.New("webhook url must be an absolute http(s) url")
+
+// reservedNets : IsPrivate等方法之外不应从服务端访问的地址段
+var reservedNets = []*net.IPNet{
+	mustCIDR("0.0.0.0/8"),     // 本网络
+	mustCIDR("100.64.0.0/10"), // 运营商级NAT
+	mustCIDR("192.0.0.0/24"),  // IETF协议分配
+	mustCIDR("198.18.0.0/15"), // 基准测试
+}
+
+func mustCIDR(s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// PublicIP : ip是否可作为投递目标, 回环、私有、链路本地、未指定、组播及保留地址都不允许
+func PublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, n := range reservedNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL : 校验webhook地址: 须为http(s)绝对地址, 且主机解析到的所有地址都满足PublicIP.
+// 注册时校验; 投递时由NewClient的连接再按实际连接的地址校验, 解析结果变化也无法绕过
+func CheckURL(ctx context.Context, rawurl string) error {
+	u, err := url.Parse(rawurl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidURL
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !PublicIP(addr.IP) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// NewClient : 投递使用的http.Client. 只连接allowed允许的地址(在解析之后、连接之前按实际地址校验),
+// 不经过代理, 不跟随重定向(3xx按投递失败处理)
+func NewClient(timeout time.Duration, allowed func(ip net.IP) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !allowed(ip) {
+				return ErrForbiddenAddress
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 2,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,246 @@
+// Package dispatcher : 将用户文件事件投递到注册的webhook.
+//
+// 事件来自用户文件事件日志(tbl_user_file_event), 每个webhook记录已处理到的事件游标.
+// 收到用户有新事件的通知后, 为游标之后订阅的事件生成投递记录并提交投递任务(job.TypeWebhook),
+// 由任务框架执行、按退避计划重试, 重试用完后投递记录标记为失败.
+// 投递记录及任务在前移游标前已持久化, 服务重启或多实例部署时不会丢失或重复生成
+package dispatcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloud/job"
+	"github.com/cloud/service/webhook/config"
+)
+
+// 投递状态
+const (
+	StatusPending   = 0
+	StatusSucceeded = 1
+	StatusFailed    = 2
+)
+
+// batchSize : 每次读取的事件数
+const batchSize = 100
+
+// Event : 文件变更事件, 格式与/file/changes返回的事件相同, 作为投递的请求体
+type Event struct {
+	ID       int64
+	UserName string
+	Event    string
+	FileName string
+	OldName  string
+	FileHash string
+	FileSize int64
+	CreateAt string
+}
+
+// Hook : 已注册的webhook, UserName为空表示接收所有用户事件的全局webhook
+type Hook struct {
+	ID       int64
+	UserName string
+	URL      string
+	Secret   string
+	// Events : 订阅的事件类型, 逗号分隔, 为空表示全部
+	Events string
+	// Cursor : 已生成投递记录的最后一个事件ID
+	Cursor int64
+}
+
+// Wants : 是否订阅了该类型的事件
+func (h Hook) Wants(event string) bool {
+	if h.Events == "" {
+		return true
+	}
+	for _, e := range strings.Split(h.Events, ",") {
+		if strings.TrimSpace(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery : 一个事件到一个webhook的投递记录
+type Delivery struct {
+	ID           int64
+	HookID       int64
+	EventID      int64
+	UserName     string
+	Event        string
+	Payload      string
+	Status       int
+	Attempts     int
+	ResponseCode int
+	LastError    string
+	// NextRetryAt : 待投递记录预计的下次投递时间, 实际由投递任务的退避计划决定
+	NextRetryAt time.Time
+	URL         string
+	Secret      string
+}
+
+// Store : 投递所需的持久化操作
+type Store interface {
+	// Hooks : 接收username事件的webhook, 包括全局webhook; username为空时只返回全局webhook
+	Hooks(username string) ([]Hook, error)
+	// Events : hook游标之后的事件. before只对全局webhook有效, 只返回该时间之前的事件
+	Events(hook Hook, before time.Time, limit int) ([]Event, error)
+	// AdvanceCursor : 前移webhook的游标, 游标只增不减
+	AdvanceCursor(hookID, cursor int64) error
+	// CreateDelivery : 保存投递记录并设置d.ID; 同一webhook的同一事件已有记录时返回false
+	CreateDelivery(d *Delivery) (bool, error)
+	// Delivery : webhook对某事件的投递记录, 不存在或webhook已停用时返回nil
+	Delivery(hookID, eventID int64) (*Delivery, error)
+	// UpdateDelivery : 保存投递结果
+	UpdateDelivery(d *Delivery) error
+}
+
+// Enqueuer : 提交任务, 如job.Client.Enqueue
+type Enqueuer func(jobType string, payload interface{}, opts job.EnqueueOptions) (*job.Job, error)
+
+// Dispatcher : webhook投递器
+type Dispatcher struct {
+	Store  Store
+	Client *http.Client
+	// Enqueue : 提交投递任务
+	Enqueue Enqueuer
+	// Settle : 全局webhook只投递提交超过该时长的事件
+	Settle time.Duration
+	// Now : 用于计算预计的重试时间, 测试时可替换
+	Now func() time.Time
+}
+
+// New : 使用config中的默认配置创建投递器
+func New(store Store, enqueue Enqueuer) *Dispatcher {
+	return &Dispatcher{
+		Store:   store,
+		Client:  NewClient(config.RequestTimeout, PublicIP),
+		Enqueue: enqueue,
+		Settle:  config.GlobalEventSettle,
+		Now:     time.Now,
+	}
+}
+
+// HandleEvent : 处理用户有新事件的通知, 为其webhook生成投递记录并提交投递任务.
+// username为空时只处理全局webhook(全局webhook只投递已提交一段时间的事件, 收到通知时可能尚未满足条件),
+// 应定期调用
+func (d *Dispatcher) HandleEvent(username string) error {
+	hooks, err := d.Store.Hooks(username)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		if err = d.collect(hook); err != nil {
+			log.Printf("webhook %d: %s\n", hook.ID, err.Error())
+		}
+	}
+	return err
+}
+
+// collect : 为webhook游标之后订阅的事件生成投递记录并提交投递任务, 然后前移游标.
+// 记录已存在时同样提交, 由幂等键去重, 提交前进程退出的记录在游标回退后重新提交
+func (d *Dispatcher) collect(hook Hook) error {
+	for {
+		events, err := d.Store.Events(hook, d.Now().Add(-d.Settle), batchSize)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+		for _, ev := range events {
+			if !hook.Wants(ev.Event) {
+				continue
+			}
+			payload, _ := json.Marshal(ev)
+			del := &Delivery{
+				HookID:      hook.ID,
+				EventID:     ev.ID,
+				UserName:    hook.UserName,
+				Event:       ev.Event,
+				Payload:     string(payload),
+				Status:      StatusPending,
+				NextRetryAt: d.Now(),
+			}
+			if _, err = d.Store.CreateDelivery(del); err != nil {
+				return err
+			}
+			p := job.WebhookPayload{HookID: hook.ID, EventID: ev.ID}
+			_, err = d.Enqueue(job.TypeWebhook, p, job.EnqueueOptions{IdempotencyKey: p.IdempotencyKey()})
+			if err != nil {
+				return err
+			}
+		}
+		hook.Cursor = events[len(events)-1].ID
+		if err = d.Store.AdvanceCursor(hook.ID, hook.Cursor); err != nil {
+			return err
+		}
+		if len(events) < batchSize {
+			return nil
+		}
+	}
+}
+
+// Deliver : 执行投递任务: 投递一次并保存结果, 失败时返回错误由任务框架按退避计划重试,
+// 次数用完后标记为失败. 记录已完成或webhook已停用时直接返回
+func (d *Dispatcher) Deliver(ctx context.Context, payload interface{}) error {
+	data := payload.(*job.WebhookPayload)
+	del, err := d.Store.Delivery(data.HookID, data.EventID)
+	if err != nil || del == nil || del.Status != StatusPending {
+		return err
+	}
+
+	code, err := d.post(ctx, del)
+	del.Attempts++
+	del.ResponseCode = code
+	if err == nil {
+		del.Status = StatusSucceeded
+		del.LastError = ""
+	} else {
+		del.LastError = err.Error()
+		if len(del.LastError) > 256 {
+			del.LastError = del.LastError[:256]
+		}
+		def, _ := job.Lookup(job.TypeWebhook)
+		if del.Attempts >= def.MaxAttempts {
+			del.Status = StatusFailed
+		} else {
+			del.NextRetryAt = d.Now().Add(def.Backoff(del.Attempts))
+		}
+	}
+	if uerr := d.Store.UpdateDelivery(del); uerr != nil {
+		log.Printf("webhook delivery %d: %s\n", del.ID, uerr.Error())
+	}
+	return err
+}
+
+// post : 发送带签名的请求, 2xx视为成功
+func (d *Dispatcher) post(ctx context.Context, del *Delivery) (int, error) {
+	req, err := http.NewRequest("POST", del.URL, strings.NewReader(del.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, del.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(del.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(del.Secret, timestamp, []byte(del.Payload)))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode/100 != 2 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
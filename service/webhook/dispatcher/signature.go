@@ -0,0 +1,62 @@
+package dispatcher
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 投递请求携带的header
+const (
+	// HeaderEvent : 事件类型
+	HeaderEvent = "X-Webhook-Event"
+	// HeaderDelivery : 投递记录ID, 重试时不变, 接收方可用于去重
+	HeaderDelivery = "X-Webhook-Delivery"
+	// HeaderTimestamp : 签名时间(unix秒)
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature : "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// ErrInvalidSignature : 签名不正确或已过期
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign : 计算请求体的签名, 签名覆盖时间戳以防重放
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify : 供接收方校验签名, 签名时间与当前时间相差超过tolerance时视为重放
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if math.Abs(float64(time.Now().Unix()-timestamp)) > tolerance.Seconds() {
+		return ErrInvalidSignature
+	}
+	expected := Sign(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(header.Get(HeaderSignature))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyRequest : 读取请求体并校验签名
+func VerifyRequest(secret string, r *http.Request, tolerance time.Duration) ([]byte, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	return body, Verify(secret, r.Header, body, tolerance)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/micro/go-micro"
+
+	cmnCfg "github.com/cloud/config"
+	"github.com/cloud/job"
+	"github.com/cloud/job/dbqueue"
+	"github.com/cloud/mq"
+	"github.com/cloud/service/webhook/config"
+	"github.com/cloud/service/webhook/dbstore"
+	"github.com/cloud/service/webhook/dispatcher"
+)
+
+func startRPCService() {
+	service := micro.NewService(
+		micro.Name("go.micro.service.webhook"),
+		micro.RegisterTTL(time.Second*10),
+		micro.RegisterInterval(time.Second*5),
+		micro.Registry(cmnCfg.RegistryConsul()))
+	service.Init()
+
+	if err := service.Run(); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// reconnectInterval : 事件通知队列连接断开后重新监听的间隔
+const reconnectInterval = 3 * time.Second
+
+// startEventConsumer : 监听用户文件事件通知, 投递由投递任务进行, 慢的接收方不会阻塞队列
+func startEventConsumer(d *dispatcher.Dispatcher) {
+	if !cmnCfg.WebhookEnable {
+		log.Println("webhook功能目前被禁用，请检查相关配置")
+		return
+	}
+	log.Println("webhook服务启动中，开始监听事件通知队列...")
+	b := mq.Default()
+	handler := mq.AckHandler(func(msg []byte) bool {
+		data := mq.FileEventData{}
+		if err := json.Unmarshal(msg, &data); err != nil {
+			log.Println(err.Error())
+			return false
+		}
+		return d.HandleEvent(data.UserName) == nil
+	})
+	for {
+		err := b.DeclareQueue(cmnCfg.WebhookExchangeName, cmnCfg.WebhookEventQueueName,
+			cmnCfg.WebhookEventRoutingKey, 0)
+		if err == nil {
+			err = b.Consume(context.Background(), cmnCfg.WebhookEventQueueName, "webhook_dispatcher", 1, handler)
+		}
+		if err != nil {
+			log.Println(err.Error())
+		}
+		log.Println("事件通知队列连接断开, 重新连接...")
+		time.Sleep(reconnectInterval)
+	}
+}
+
+// startGlobalScan : 定期为全局webhook生成投递记录, 失败投递的重试由任务服务的调度重新入队
+func startGlobalScan(d *dispatcher.Dispatcher) {
+	if !cmnCfg.WebhookEnable {
+		return
+	}
+	ticker := time.NewTicker(config.GlobalScanInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := d.HandleEvent(""); err != nil {
+			log.Println(err.Error())
+		}
+	}
+}
+
+func main() {
+	b := mq.Default()
+	w := job.NewWorker(dbqueue.Store{}, job.BrokerPublisher(b), dbqueue.QueueLease)
+	d := dispatcher.New(dbstore.Store{}, dbqueue.NewClient(b).Enqueue)
+	w.Handle(job.TypeWebhook, d.Deliver)
+
+	go job.Consume(context.Background(), b, w)
+	go startEventConsumer(d)
+	go startGlobalScan(d)
+
+	// rpc 服务
+	startRPCService()
+}
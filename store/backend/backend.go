@@ -0,0 +1,448 @@
+// Package backend : 按存储类型读写文件内容
+package backend
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	ossSDK "github.com/aliyun/aliyun-oss-go-sdk/oss"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	"github.com/cloud/store/ceph"
+	"github.com/cloud/store/compress/dbcompress"
+	"github.com/cloud/store/crypt"
+	"github.com/cloud/store/crypt/dbcrypt"
+	"github.com/cloud/store/erasure"
+	"github.com/cloud/store/layout"
+	"github.com/cloud/store/oss"
+)
+
+// TypeOf : 按存储位置的前缀判断存储类型, 无法识别时返回0
+func TypeOf(location string) common.StoreType {
+	switch {
+	case strings.HasPrefix(location, config.MergeLocalRootDir):
+		return common.StoreLocal
+	case strings.HasPrefix(location, config.CephRootDir):
+		return common.StoreCeph
+	case strings.HasPrefix(location, config.OSSRootDir):
+		return common.StoreOSS
+	case strings.HasPrefix(location, config.ErasureRootDir):
+		return common.StoreErasure
+	}
+	return 0
+}
+
+// fileHash : key对应的文件hash(key以文件sha1结尾), 无法识别时返回空字符串, 此时内容不加密
+func fileHash(key string) string {
+	if len(key) < 40 {
+		return ""
+	}
+	hash := key[len(key)-40:]
+	if _, err := hex.DecodeString(hash); err != nil {
+		return ""
+	}
+	return hash
+}
+
+// Put : 将r的内容写入存储类型t中的key. 可压缩的内容先压缩, 开启加密时写入以文件数据密钥加密的内容,
+// 关闭时写入明文并删除该位置原有的数据密钥
+func Put(t common.StoreType, key string, r io.Reader) error {
+	filehash := fileHash(key)
+	if filehash == "" {
+		return put(t, key, r)
+	}
+
+	envelope := dbcrypt.Default()
+	compressor := dbcompress.Default()
+	var existing *crypt.Key
+	if config.EncryptionEnable {
+		var err error
+		if existing, err = envelope.Lookup(filehash, t); err != nil {
+			return err
+		}
+	}
+	prev, err := compressor.Lookup(filehash, t)
+	if err != nil {
+		return err
+	}
+	// 沿用数据密钥时内容须与原有内容相同, 压缩与否及压缩参数不变
+	body, info, err := compressor.Compress(r, prev, existing != nil)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	// 先保存压缩信息, 写入失败时恢复
+	if err := compressor.Record(filehash, t, prev, info); err != nil {
+		return err
+	}
+
+	if !config.EncryptionEnable {
+		if err := put(t, key, body); err != nil {
+			compressor.Record(filehash, t, info, prev)
+			return err
+		}
+		return envelope.Forget(filehash, t)
+	}
+
+	encrypted, err := envelope.Encrypt(filehash, t, body)
+	if err != nil {
+		compressor.Record(filehash, t, info, prev)
+		return err
+	}
+	if err := put(t, key, encrypted); err != nil {
+		compressor.Record(filehash, t, info, prev)
+		if existing == nil {
+			// 原有内容(如有)仍为明文, 删除新建的数据密钥
+			envelope.Forget(filehash, t)
+		}
+		return err
+	}
+	return nil
+}
+
+// put : 将r的内容原样写入存储类型t中的key
+func put(t common.StoreType, key string, r io.Reader) error {
+	switch t {
+	case common.StoreLocal:
+		// 先写入临时文件, 完整写入并同步到磁盘后再移到目标位置
+		if err := os.MkdirAll(filepath.Dir(key), 0744); err != nil {
+			return err
+		}
+		tmp := key + ".tmp"
+		fd, err := os.Create(tmp)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(fd, r)
+		if cerr := fd.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = layout.Rename(tmp, key)
+		}
+		if err != nil {
+			os.Remove(tmp)
+		}
+		return err
+	case common.StoreCeph:
+		bucket := ceph.GetCephBucket(config.CephBucket)
+		if bucket == nil {
+			return errors.New("ceph bucket unavailable")
+		}
+		return bucket.Put(key, r)
+	case common.StoreOSS:
+		bucket := oss.Bucket()
+		if bucket == nil {
+			return errors.New("oss bucket unavailable")
+		}
+		return bucket.PutObject(key, r)
+	case common.StoreErasure:
+		return erasure.Default().Put(strings.TrimPrefix(key, config.ErasureRootDir), r)
+	}
+	return fmt.Errorf("unsupported store type %d", t)
+}
+
+// PutLocalFile : 将本地临时文件tmpPath的内容写入本地存储中的key并删除临时文件.
+// 不加密也不压缩时同步到磁盘后直接移动
+func PutLocalFile(tmpPath, key string) error {
+	if !config.EncryptionEnable && !config.CompressionEnable {
+		if err := layout.Rename(tmpPath, key); err != nil {
+			return err
+		}
+		return forget(common.StoreLocal, key)
+	}
+
+	fd, err := os.Open(tmpPath)
+	if err != nil {
+		return err
+	}
+	err = Put(common.StoreLocal, key, fd)
+	fd.Close()
+	if err != nil {
+		return err
+	}
+	return os.Remove(tmpPath)
+}
+
+// Encrypted : 存储类型t中的key是否已加密
+func Encrypted(t common.StoreType, key string) (bool, error) {
+	filehash := fileHash(key)
+	if filehash == "" {
+		return false, nil
+	}
+	dataKey, err := dbcrypt.Default().Lookup(filehash, t)
+	return dataKey != nil, err
+}
+
+// Compressed : 存储类型t中的key是否已压缩
+func Compressed(t common.StoreType, key string) (bool, error) {
+	filehash := fileHash(key)
+	if filehash == "" {
+		return false, nil
+	}
+	info, err := dbcompress.Default().Lookup(filehash, t)
+	return info != nil, err
+}
+
+// Raw : 存储类型t中的key的内容是否与文件内容相同(未加密且未压缩), 可直接读取
+func Raw(t common.StoreType, key string) (bool, error) {
+	if encrypted, err := Encrypted(t, key); err != nil || encrypted {
+		return false, err
+	}
+	compressed, err := Compressed(t, key)
+	return !compressed, err
+}
+
+// forget : 删除存储类型t中的key的数据密钥及压缩信息
+func forget(t common.StoreType, key string) error {
+	filehash := fileHash(key)
+	if filehash == "" {
+		return nil
+	}
+	if err := dbcrypt.Default().Forget(filehash, t); err != nil {
+		return err
+	}
+	return dbcompress.Default().Forget(filehash, t)
+}
+
+// readCloser : 组合Reader及Closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Open : 打开存储类型t中的key, 返回从offset开始长度为length的数据, length为-1时读取到末尾.
+// 已加密或压缩的内容返回解密、解压后的原始内容
+func Open(t common.StoreType, key string, offset, length int64) (io.ReadCloser, error) {
+	if length == 0 {
+		return ioutil.NopCloser(bytes.NewReader(nil)), nil
+	}
+
+	filehash := fileHash(key)
+	if filehash == "" {
+		return open(t, key, offset, length, true)
+	}
+	compressor := dbcompress.Default()
+	info, err := compressor.Lookup(filehash, t)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return openStored(t, key, filehash, offset, length)
+	}
+	return compressor.Open(info, offset, length, func(cOffset, cLength int64) (io.ReadCloser, error) {
+		return openStored(t, key, filehash, cOffset, cLength)
+	})
+}
+
+// openStored : 打开存储类型t中的key写入的(压缩后的)内容, 已加密的内容返回解密后的数据
+func openStored(t common.StoreType, key, filehash string, offset, length int64) (io.ReadCloser, error) {
+	envelope := dbcrypt.Default()
+	dataKey, err := envelope.Lookup(filehash, t)
+	if err != nil {
+		return nil, err
+	}
+	if dataKey == nil {
+		return open(t, key, offset, length, true)
+	}
+	return envelope.Open(filehash, dataKey, offset, length, func(cOffset, cLength int64) (io.ReadCloser, error) {
+		return open(t, key, cOffset, cLength, false)
+	})
+}
+
+// localPath : 本地文件key的实际路径. 转移任务等记录的平铺路径下没有文件时,
+// 文件已迁移到分级路径, 返回分级路径
+func localPath(key string) string {
+	if _, err := os.Lstat(key); os.IsNotExist(err) {
+		if sharded := layout.Sharded(config.MergeLocalRootDir, key); sharded != "" {
+			return sharded
+		}
+	}
+	return key
+}
+
+// open : 打开存储类型t中的key的原始内容. strict为true时内容短于offset+length返回错误,
+// 否则返回到末尾的数据(密文按整块范围读取, 最后一块可能较短)
+func open(t common.StoreType, key string, offset, length int64, strict bool) (io.ReadCloser, error) {
+	switch t {
+	case common.StoreLocal:
+		fd, err := os.Open(localPath(key))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fd.Seek(offset, io.SeekStart); err != nil {
+			fd.Close()
+			return nil, err
+		}
+		if length < 0 {
+			return fd, nil
+		}
+		return readCloser{io.LimitReader(fd, length), fd}, nil
+	case common.StoreCeph:
+		bucket := ceph.GetCephBucket(config.CephBucket)
+		if bucket == nil {
+			return nil, errors.New("ceph bucket unavailable")
+		}
+		rc, size, err := bucket.Get(key, offset, length)
+		if err != nil {
+			return nil, err
+		}
+		if strict && length >= 0 && size < length {
+			rc.Close()
+			return nil, fmt.Errorf("ceph object %s is shorter than expected", key)
+		}
+		return rc, nil
+	case common.StoreOSS:
+		bucket := oss.Bucket()
+		if bucket == nil {
+			return nil, errors.New("oss bucket unavailable")
+		}
+		if length < 0 {
+			return bucket.GetObject(key, ossSDK.NormalizedRange(strconv.FormatInt(offset, 10)+"-"))
+		}
+		options := []ossSDK.Option{ossSDK.Range(offset, offset+length-1)}
+		if !strict {
+			// 范围超出对象末尾时返回到末尾的数据
+			options = append(options, ossSDK.RangeBehavior("standard"))
+		}
+		return bucket.GetObject(key, options...)
+	case common.StoreErasure:
+		rd, err := erasure.Default().Open(strings.TrimPrefix(key, config.ErasureRootDir), offset, length)
+		if err != nil {
+			return nil, err
+		}
+		if strict && length >= 0 && offset+length > rd.Size() {
+			rd.Close()
+			return nil, fmt.Errorf("erasure object %s is shorter than expected", key)
+		}
+		return rd, nil
+	}
+	return nil, fmt.Errorf("unsupported store type %d", t)
+}
+
+// Remove : 删除存储类型t中的key及其数据密钥、压缩信息, key不存在时不返回错误
+func Remove(t common.StoreType, key string) error {
+	if err := remove(t, key); err != nil {
+		return err
+	}
+	return forget(t, key)
+}
+
+// remove : 删除存储类型t中的key
+func remove(t common.StoreType, key string) error {
+	switch t {
+	case common.StoreLocal:
+		if err := os.Remove(localPath(key)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	case common.StoreCeph:
+		bucket := ceph.GetCephBucket(config.CephBucket)
+		if bucket == nil {
+			return errors.New("ceph bucket unavailable")
+		}
+		return bucket.Remove(key)
+	case common.StoreOSS:
+		bucket := oss.Bucket()
+		if bucket == nil {
+			return errors.New("oss bucket unavailable")
+		}
+		return bucket.DeleteObject(key)
+	case common.StoreErasure:
+		return erasure.Default().Remove(strings.TrimPrefix(key, config.ErasureRootDir))
+	}
+	return fmt.Errorf("unsupported store type %d", t)
+}
+
+// storageClasses : 各层级在OSS中的存储类型
+var storageClasses = map[common.StorageTier]ossSDK.StorageClassType{
+	common.TierHot:     ossSDK.StorageStandard,
+	common.TierCold:    ossSDK.StorageIA,
+	common.TierArchive: ossSDK.StorageArchive,
+}
+
+// SetStorageClass : 将OSS对象的存储类型改为层级tier对应的类型(Standard/IA/Archive).
+// 归档对象需要先解冻
+func SetStorageClass(key string, tier common.StorageTier) error {
+	class, ok := storageClasses[tier]
+	if !ok {
+		return fmt.Errorf("unsupported storage tier %d", tier)
+	}
+	bucket := oss.Bucket()
+	if bucket == nil {
+		return errors.New("oss bucket unavailable")
+	}
+	_, err := bucket.CopyObject(key, key, ossSDK.ObjectStorageClass(class))
+	return err
+}
+
+// Restore : 返回OSS对象是否可读取. 非归档对象及已解冻的归档对象可读取,
+// 未解冻的归档对象发起解冻(解冻中不重复发起), 解冻完成前返回false
+func Restore(key string) (bool, error) {
+	bucket := oss.Bucket()
+	if bucket == nil {
+		return false, errors.New("oss bucket unavailable")
+	}
+	header, err := bucket.GetObjectDetailedMeta(key)
+	if err != nil {
+		return false, err
+	}
+	if header.Get(ossSDK.HTTPHeaderOssStorageClass) != string(ossSDK.StorageArchive) {
+		return true, nil
+	}
+
+	restore := header.Get("X-Oss-Restore")
+	switch {
+	case restore == "":
+		// 只指定解冻天数, 解冻优先级(JobParameters)只适用于冷归档
+		conf := fmt.Sprintf("<RestoreRequest><Days>%d</Days></RestoreRequest>", config.TierRestoreDays)
+		return false, bucket.RestoreObjectXML(key, conf)
+	case strings.Contains(restore, `ongoing-request="true"`):
+		return false, nil
+	}
+	return true, nil
+}
+
+// Checksum : 存储中记录的对象CRC64(ECMA, 十进制), 写入时由存储计算, 读取时不重新计算.
+// 只有OSS提供, 其余存储及已加密或压缩的对象(CRC64不是文件内容的校验值)返回空字符串
+func Checksum(t common.StoreType, key string) (string, error) {
+	if t != common.StoreOSS {
+		return "", nil
+	}
+	if raw, err := Raw(t, key); err != nil || !raw {
+		return "", err
+	}
+	bucket := oss.Bucket()
+	if bucket == nil {
+		return "", errors.New("oss bucket unavailable")
+	}
+	header, err := bucket.GetObjectDetailedMeta(key)
+	if err != nil {
+		return "", err
+	}
+	return header.Get(ossSDK.HTTPHeaderOssCRC64), nil
+}
+
+// Quarantine : 隔离内容损坏的数据. 本地文件移到config.ScrubQuarantineDir(文件名后加时间戳);
+// Ceph/OSS上的对象不移动, 由修复任务覆盖写入
+func Quarantine(t common.StoreType, key string) error {
+	if t != common.StoreLocal {
+		return nil
+	}
+	if err := os.MkdirAll(config.ScrubQuarantineDir, 0744); err != nil {
+		return err
+	}
+	dst := filepath.Join(config.ScrubQuarantineDir,
+		filepath.Base(key)+"."+strconv.FormatInt(time.Now().Unix(), 10))
+	return os.Rename(localPath(key), dst)
+}
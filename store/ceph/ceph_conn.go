@@ -1,45 +1,179 @@
+// Package ceph : 通过S3兼容接口(SigV4签名)读写Ceph RGW中的对象,
+// 同样适用于MinIO、SeaweedFS等S3兼容存储
 package ceph
 
 import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
 	"github.com/cloud/config"
-	"gopkg.in/amz.v1/aws"
-	"gopkg.in/amz.v1/s3"
 )
 
-var cephConn *s3.S3
+// Options : 连接S3兼容存储的参数
+type Options struct {
+	// Endpoint : 网关地址(host:port)
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	// Region : SigV4签名使用的region, 为空时向网关查询bucket所在的region
+	Region string
+	UseSSL bool
+	// PathStyle : 以路径而不是虚拟主机的方式访问bucket
+	PathStyle bool
+	// PartSize : 分块上传的块大小, 为0时使用config.CephPartSize
+	PartSize int64
+}
+
+// Bucket : S3兼容存储中的一个bucket
+type Bucket struct {
+	client   *minio.Client
+	name     string
+	partSize int64
+}
+
+// New : 按opts连接S3兼容存储并返回其中的bucket, 不检查bucket是否存在
+func New(opts Options, bucket string) (*Bucket, error) {
+	lookup := minio.BucketLookupAuto
+	if opts.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+	client, err := minio.New(opts.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(opts.AccessKey, opts.SecretKey, ""),
+		Secure:       opts.UseSSL,
+		Region:       opts.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, err
+	}
+	partSize := opts.PartSize
+	if partSize == 0 {
+		partSize = config.CephPartSize
+	}
+	return &Bucket{client: client, name: bucket, partSize: partSize}, nil
+}
+
+var (
+	cephConn *minio.Client
+	connOnce sync.Once
+)
+
+// GetCephBucket : 按config中的网关配置返回bucket, 配置无效时返回nil
+func GetCephBucket(bucket string) *Bucket {
+	connOnce.Do(func() {
+		b, err := New(Options{
+			Endpoint:  config.CephGWEndpoint,
+			AccessKey: config.CephAccessKey,
+			SecretKey: config.CephSecretKey,
+			Region:    config.CephRegion,
+			UseSSL:    config.CephUseSSL,
+			PathStyle: config.CephPathStyle,
+		}, "")
+		if err != nil {
+			log.Println(err.Error())
+			return
+		}
+		cephConn = b.client
+	})
+	if cephConn == nil {
+		return nil
+	}
+	return &Bucket{client: cephConn, name: bucket, partSize: config.CephPartSize}
+}
+
+// objectName : key对应的对象名. 与原客户端一致, 开头的"/"(如config.CephRootDir)不属于对象名
+func objectName(key string) string {
+	return strings.TrimPrefix(key, "/")
+}
 
-// GetCephConn : 获取ceph集群连接
-func GetCephConn() *s3.S3 {
-	if cephConn != nil {
-		return cephConn
+// EnsureBucket : bucket不存在时创建
+func (b *Bucket) EnsureBucket() error {
+	ctx := context.Background()
+	exists, err := b.client.BucketExists(ctx, b.name)
+	if err != nil || exists {
+		return err
+	}
+	return b.client.MakeBucket(ctx, b.name, minio.MakeBucketOptions{})
+}
+
+// Put : 将r的内容写入key. 不超过块大小的内容以一个请求写入,
+// 更大的内容边读取边分块上传, 最多缓存两块, 上传失败时取消已上传的块
+func (b *Bucket) Put(key string, r io.Reader) error {
+	ctx := context.Background()
+	opts := minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+		PartSize:    uint64(b.partSize),
+	}
+	head := &bytes.Buffer{}
+	n, err := io.CopyN(head, r, b.partSize+1)
+	if err == io.EOF {
+		_, err = b.client.PutObject(ctx, b.name, objectName(key), head, n, opts)
+		return err
 	}
+	if err != nil {
+		return err
+	}
+	_, err = b.client.PutObject(ctx, b.name, objectName(key), io.MultiReader(head, r), -1, opts)
+	return err
+}
 
-	// 1 初始化ceph信息
-	auth := aws.Auth{ // aws验证信息
-		AccessKey: config.CephAccessKey,
-		SecretKey: config.CephSecretKey,
+// Get : 读取key从offset开始的length字节, length为-1或超出对象末尾时读取到末尾.
+// 只请求该范围(Range)并以流的方式读取, 返回的第二个值为将读取到的字节数
+func (b *Bucket) Get(key string, offset, length int64) (io.ReadCloser, int64, error) {
+	if length == 0 {
+		return ioutil.NopCloser(bytes.NewReader(nil)), 0, nil
 	}
-	region := aws.Region{ // aws region信息
-		Name:                 "default",
-		EC2Endpoint:          config.CephGWEndpoint, // 9080端口会映射到容器内部的80端口
-		S3Endpoint:           config.CephGWEndpoint,
-		S3BucketEndpoint:     "",
-		S3LocationConstraint: false,
-		S3LowercaseBucket:    false,
-		Sign:                 aws.SignV2,
+	opts := minio.GetObjectOptions{}
+	var err error
+	switch {
+	case length > 0:
+		err = opts.SetRange(offset, offset+length-1)
+	case offset > 0:
+		err = opts.SetRange(offset, 0)
+	}
+	if err != nil {
+		return nil, 0, err
 	}
 
-	// 2 创建S3类型的连接
-	return s3.New(auth, region)
+	// 以单个GET请求读取该范围, 对象不存在或范围无效时在此返回错误
+	core := minio.Core{Client: b.client}
+	body, info, _, err := core.GetObject(context.Background(), b.name, objectName(key), opts)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "InvalidRange" {
+			// offset恰为对象末尾时没有可读取的数据
+			if size, serr := b.Size(key); serr == nil && size == offset {
+				return ioutil.NopCloser(bytes.NewReader(nil)), 0, nil
+			}
+		}
+		return nil, 0, err
+	}
+	return body, info.Size, nil
+}
+
+// Size : 对象的长度
+func (b *Bucket) Size(key string) (int64, error) {
+	info, err := b.client.StatObject(context.Background(), b.name, objectName(key), minio.StatObjectOptions{})
+	if err != nil {
+		return 0, err
+	}
+	return info.Size, nil
 }
 
-// GetCephBucket : 返回ceph指定bucket
-func GetCephBucket(bucket string) *s3.Bucket {
-	conn := GetCephConn()
-	return conn.Bucket(bucket)
+// Remove : 删除key, 对象不存在时不返回错误
+func (b *Bucket) Remove(key string) error {
+	return b.client.RemoveObject(context.Background(), b.name, objectName(key), minio.RemoveObjectOptions{})
 }
 
-// PutObject : 向指定bucket的指定path存储data
-func PutObject(bucket, path string, data []byte) error {
-	return GetCephBucket(bucket).Put(path, data, "octet-stream", s3.PublicRead)
+// IsNotExist : err是否表示对象或bucket不存在
+func IsNotExist(err error) bool {
+	code := minio.ToErrorResponse(err).Code
+	return code == "NoSuchKey" || code == "NoSuchBucket"
 }
@@ -0,0 +1,173 @@
+// Package compress : 存储内容的透明压缩. 开头的采样可压缩时以seekable zstd写入(见seekable.go),
+// 压缩信息(编码、原始长度及写入长度)按位置保存, 读取时按记录解压, range读取只解压覆盖的帧.
+// 文件的逻辑长度(配额、下载)始终为原始长度
+package compress
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/cloud/common"
+)
+
+// CodecZstd : seekable zstd
+const CodecZstd = "zstd"
+
+// Info : 文件在一个存储中的位置的压缩信息
+type Info struct {
+	Codec string
+	// Level : zstd压缩等级(zstd.EncoderLevel)
+	Level int
+	// FrameSize : 每帧的原始长度
+	FrameSize   int
+	LogicalSize int64
+	StoredSize  int64
+}
+
+// Store : 压缩信息的读写, 由dbcompress通过dbproxy实现, 测试时可替换为内存实现.
+// 位置有压缩信息记录表示该位置的内容已压缩
+type Store interface {
+	// Get : 查询压缩信息, 没有记录(内容未压缩)时返回nil
+	Get(filehash string, t common.StoreType) (*Info, error)
+	// Save : 保存压缩信息, 已有记录时覆盖
+	Save(filehash string, t common.StoreType, info Info) error
+	// Remove : 删除压缩信息(内容已删除或以原始内容重新写入)
+	Remove(filehash string, t common.StoreType) error
+}
+
+// Compressor : 按采样决定是否压缩写入的内容
+type Compressor struct {
+	Store Store
+	// Enable : 是否压缩新写入的内容
+	Enable bool
+	Level  int
+	// FrameSize : 每帧的原始长度
+	FrameSize int
+	// SampleSize : 采样长度(内容开头)
+	SampleSize int
+	// MinRatio : 采样压缩后的长度不超过原长度的该比例时压缩
+	MinRatio float64
+	// MinSize : 小于该长度的内容不压缩
+	MinSize int
+	// TempDir : 压缩内容的临时目录, 为空时使用系统临时目录
+	TempDir string
+}
+
+// Lookup : 位置的压缩信息, 内容未压缩时返回nil
+func (c *Compressor) Lookup(filehash string, t common.StoreType) (*Info, error) {
+	return c.Store.Get(filehash, t)
+}
+
+// compressible : 采样以level压缩后是否达到MinRatio
+func (c *Compressor) compressible(sample []byte, level int) (bool, error) {
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.EncoderLevel(level)),
+		zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return false, err
+	}
+	defer enc.Close()
+	out := enc.EncodeAll(sample, nil)
+	return float64(len(out)) <= float64(len(sample))*c.MinRatio, nil
+}
+
+// tempFile : 压缩内容的临时文件, 关闭时删除
+type tempFile struct {
+	*os.File
+}
+
+func (f tempFile) Close() error {
+	err := f.File.Close()
+	os.Remove(f.Name())
+	return err
+}
+
+// Compress : 返回写入存储的内容及其压缩信息, 不压缩时返回r及nil. 返回的内容须关闭.
+// prev为位置原有的压缩信息; keep为true时(如位置沿用原有的数据密钥)不采样, 按prev决定是否压缩
+// 及压缩参数, 使重新写入的内容与原有内容相同
+func (c *Compressor) Compress(r io.Reader, prev *Info, keep bool) (io.ReadCloser, *Info, error) {
+	level, frameSize := c.Level, c.FrameSize
+	if keep {
+		if prev == nil {
+			return ioutil.NopCloser(r), nil, nil
+		}
+		if prev.Codec != CodecZstd {
+			return nil, nil, fmt.Errorf("compress: unsupported codec %q", prev.Codec)
+		}
+		level, frameSize = prev.Level, prev.FrameSize
+	} else {
+		if !c.Enable {
+			return ioutil.NopCloser(r), nil, nil
+		}
+		sample := make([]byte, c.SampleSize)
+		n, err := io.ReadFull(r, sample)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, nil, err
+		}
+		sample = sample[:n]
+		r = io.MultiReader(bytes.NewReader(sample), r)
+		if n < c.MinSize {
+			return ioutil.NopCloser(r), nil, nil
+		}
+		ok, err := c.compressible(sample, level)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			return ioutil.NopCloser(r), nil, nil
+		}
+	}
+
+	// 先写入临时文件, 写入存储前确定压缩后的长度
+	fd, err := ioutil.TempFile(c.TempDir, "compress-")
+	if err != nil {
+		return nil, nil, err
+	}
+	tmp := tempFile{fd}
+	logical, stored, err := Encode(fd, r, level, frameSize)
+	if err == nil {
+		_, err = fd.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		tmp.Close()
+		return nil, nil, err
+	}
+	return tmp, &Info{
+		Codec:       CodecZstd,
+		Level:       level,
+		FrameSize:   frameSize,
+		LogicalSize: logical,
+		StoredSize:  stored,
+	}, nil
+}
+
+// Record : 将位置的压缩信息由prev更新为info, info为nil时删除记录
+func (c *Compressor) Record(filehash string, t common.StoreType, prev, info *Info) error {
+	switch {
+	case info == nil && prev == nil:
+		return nil
+	case info == nil:
+		return c.Store.Remove(filehash, t)
+	case prev != nil && *prev == *info:
+		return nil
+	}
+	return c.Store.Save(filehash, t, *info)
+}
+
+// Open : 读取以info压缩的位置中原始内容[offset, offset+length)的数据, length为-1时读取到末尾.
+// raw读取压缩内容的指定范围
+func (c *Compressor) Open(info *Info, offset, length int64, raw Opener) (io.ReadCloser, error) {
+	if info.Codec != CodecZstd {
+		return nil, fmt.Errorf("compress: unsupported codec %q", info.Codec)
+	}
+	return Open(info.StoredSize, offset, length, raw)
+}
+
+// Forget : 删除位置的压缩信息
+func (c *Compressor) Forget(filehash string, t common.StoreType) error {
+	return c.Store.Remove(filehash, t)
+}
@@ -0,0 +1,80 @@
+// Package dbcompress : 通过dbproxy保存压缩信息, 提供默认的Compressor
+package dbcompress
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/service/dbproxy/orm"
+	"github.com/cloud/store/compress"
+)
+
+// execResult : 将dbproxy的执行结果转换为错误
+func execResult(res *orm.ExecResult, err error) (*orm.ExecResult, error) {
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, errors.New("dbproxy: empty result")
+	}
+	if !res.Suc {
+		return nil, errors.New("dbproxy: " + res.Msg)
+	}
+	return res, nil
+}
+
+// Store : 实现compress.Store, 压缩信息保存在tbl_file_compression中
+type Store struct{}
+
+// Get : 实现compress.Store
+func (Store) Get(filehash string, t common.StoreType) (*compress.Info, error) {
+	res, err := execResult(dbcli.GetFileCompression(filehash, t))
+	if err != nil || res.Data == nil {
+		return nil, err
+	}
+	row := dbcli.ToTableFileCompression(res.Data)
+	return &compress.Info{
+		Codec:       row.Codec,
+		Level:       row.Level,
+		FrameSize:   row.FrameSize,
+		LogicalSize: row.LogicalSize,
+		StoredSize:  row.StoredSize,
+	}, nil
+}
+
+// Save : 实现compress.Store
+func (Store) Save(filehash string, t common.StoreType, info compress.Info) error {
+	_, err := execResult(dbcli.SaveFileCompression(filehash, t, info.Codec, info.Level, info.FrameSize,
+		info.LogicalSize, info.StoredSize))
+	return err
+}
+
+// Remove : 实现compress.Store
+func (Store) Remove(filehash string, t common.StoreType) error {
+	_, err := execResult(dbcli.RemoveFileCompression(filehash, t))
+	return err
+}
+
+var (
+	defaultOnce       sync.Once
+	defaultCompressor *compress.Compressor
+)
+
+// Default : 使用config中压缩配置及dbproxy的Compressor, 首次使用时创建
+func Default() *compress.Compressor {
+	defaultOnce.Do(func() {
+		defaultCompressor = &compress.Compressor{
+			Store:      Store{},
+			Enable:     config.CompressionEnable,
+			Level:      config.CompressionLevel,
+			FrameSize:  config.CompressionFrameSize,
+			SampleSize: config.CompressionSampleSize,
+			MinRatio:   config.CompressionMinRatio,
+			MinSize:    config.CompressionMinSize,
+		}
+	})
+	return defaultCompressor
+}
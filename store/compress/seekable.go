@@ -0,0 +1,271 @@
+package compress
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// seekable zstd格式(与zstd contrib/seekable_format一致): 内容按固定原始长度切分为独立的zstd帧,
+// 末尾的skippable帧保存每帧的压缩长度及原始长度(seek table), 普通zstd解压工具也可以解压整个对象
+const (
+	// skippableMagic : seek table所在skippable帧的magic
+	skippableMagic = 0x184D2A5E
+	// seekableMagic : seek table末尾的magic
+	seekableMagic = 0x8F92EAB1
+	// footerSize : Number_Of_Frames(4) + Seek_Table_Descriptor(1) + Seekable_Magic_Number(4)
+	footerSize = 9
+	// checksumFlag : Seek_Table_Descriptor中表示每项带有校验值的位
+	checksumFlag = 1 << 7
+)
+
+// ErrCorrupt : 压缩内容或seek table无效
+var ErrCorrupt = errors.New("compress: corrupt seekable zstd data")
+
+// frame : seek table中的一项, 及其在压缩内容和原始内容中的偏移
+type frame struct {
+	cOffset, cSize int64
+	offset, size   int64
+}
+
+// Encode : 将src按frameSize切分, 以level(zstd.EncoderLevel)压缩为seekable zstd写入dst,
+// 返回原始长度及写入的长度
+func Encode(dst io.Writer, src io.Reader, level, frameSize int) (int64, int64, error) {
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.EncoderLevel(level)),
+		zstd.WithEncoderConcurrency(1), zstd.WithEncoderCRC(true))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer enc.Close()
+
+	var logical, stored int64
+	table := &bytes.Buffer{}
+	frames := uint32(0)
+	buf := make([]byte, frameSize)
+	var out []byte
+	for {
+		n, err := io.ReadFull(src, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return logical, stored, err
+		}
+		if n == 0 {
+			break
+		}
+		out = enc.EncodeAll(buf[:n], out[:0])
+		if _, err := dst.Write(out); err != nil {
+			return logical, stored, err
+		}
+		binary.Write(table, binary.LittleEndian, uint32(len(out)))
+		binary.Write(table, binary.LittleEndian, uint32(n))
+		frames++
+		logical += int64(n)
+		stored += int64(len(out))
+		if n < frameSize {
+			break
+		}
+	}
+
+	// seek table
+	binary.Write(table, binary.LittleEndian, frames)
+	table.WriteByte(0)
+	binary.Write(table, binary.LittleEndian, uint32(seekableMagic))
+	header := make([]byte, 8)
+	binary.LittleEndian.PutUint32(header[0:4], skippableMagic)
+	binary.LittleEndian.PutUint32(header[4:8], uint32(table.Len()))
+	if _, err := dst.Write(append(header, table.Bytes()...)); err != nil {
+		return logical, stored, err
+	}
+	stored += int64(len(header) + table.Len())
+	return logical, stored, nil
+}
+
+// Opener : 读取压缩内容中从offset开始的length字节
+type Opener func(offset, length int64) (io.ReadCloser, error)
+
+// readRange : 读取压缩内容中的一段
+func readRange(raw Opener, offset, length int64) ([]byte, error) {
+	rc, err := raw(offset, length)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(rc, length))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != length {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return data, nil
+}
+
+// readSeekTable : 从长度为storedSize的压缩内容末尾读取seek table
+func readSeekTable(raw Opener, storedSize int64) ([]frame, error) {
+	if storedSize < 8+footerSize {
+		return nil, ErrCorrupt
+	}
+	footer, err := readRange(raw, storedSize-footerSize, footerSize)
+	if err != nil {
+		return nil, err
+	}
+	if binary.LittleEndian.Uint32(footer[5:9]) != seekableMagic {
+		return nil, ErrCorrupt
+	}
+	count := int64(binary.LittleEndian.Uint32(footer[0:4]))
+	entrySize := int64(8)
+	if footer[4]&checksumFlag != 0 {
+		entrySize = 12
+	}
+	tableSize := 8 + count*entrySize + footerSize
+	if tableSize > storedSize {
+		return nil, ErrCorrupt
+	}
+	table, err := readRange(raw, storedSize-tableSize, tableSize-footerSize)
+	if err != nil {
+		return nil, err
+	}
+	if binary.LittleEndian.Uint32(table[0:4]) != skippableMagic ||
+		int64(binary.LittleEndian.Uint32(table[4:8])) != tableSize-8 {
+		return nil, ErrCorrupt
+	}
+
+	frames := make([]frame, 0, count)
+	var cOffset, offset int64
+	for i := int64(0); i < count; i++ {
+		entry := table[8+i*entrySize:]
+		f := frame{
+			cOffset: cOffset,
+			cSize:   int64(binary.LittleEndian.Uint32(entry[0:4])),
+			offset:  offset,
+			size:    int64(binary.LittleEndian.Uint32(entry[4:8])),
+		}
+		cOffset += f.cSize
+		offset += f.size
+		frames = append(frames, f)
+	}
+	if cOffset != storedSize-tableSize {
+		return nil, ErrCorrupt
+	}
+	return frames, nil
+}
+
+// Open : 读取长度为storedSize的seekable zstd内容解压后从offset开始的length字节,
+// length为-1时读取到末尾. 只读取并解压覆盖该范围的帧
+func Open(storedSize, offset, length int64, raw Opener) (io.ReadCloser, error) {
+	frames, err := readSeekTable(raw, storedSize)
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	if len(frames) > 0 {
+		last := frames[len(frames)-1]
+		total = last.offset + last.size
+	}
+	end := offset + length
+	if length < 0 {
+		end = total
+	}
+	if offset < 0 || offset > end || end > total {
+		return nil, fmt.Errorf("compress: range %d-%d out of %d bytes", offset, end, total)
+	}
+	if offset == end {
+		return ioutil.NopCloser(bytes.NewReader(nil)), nil
+	}
+
+	// 覆盖[offset, end)的帧
+	first, last := -1, -1
+	for i, f := range frames {
+		if f.offset+f.size > offset && first < 0 {
+			first = i
+		}
+		if f.offset < end {
+			last = i
+		}
+	}
+	span := frames[last].cOffset + frames[last].cSize - frames[first].cOffset
+	rc, err := raw(frames[first].cOffset, span)
+	if err != nil {
+		return nil, err
+	}
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+	return &frameReader{
+		src:    rc,
+		dec:    dec,
+		frames: frames[first : last+1],
+		skip:   offset - frames[first].offset,
+		remain: end - offset,
+	}, nil
+}
+
+// frameReader : 依次读取并解压各帧
+type frameReader struct {
+	src    io.ReadCloser
+	dec    *zstd.Decoder
+	frames []frame
+	cBuf   []byte
+	buf    []byte
+	plain  []byte
+	skip   int64
+	remain int64
+	err    error
+}
+
+func (r *frameReader) Read(p []byte) (int, error) {
+	if r.remain == 0 {
+		return 0, io.EOF
+	}
+	for len(r.plain) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.next()
+	}
+	if int64(len(p)) > r.remain {
+		p = p[:r.remain]
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	r.remain -= int64(n)
+	return n, nil
+}
+
+// next : 解压下一帧, 长度须与seek table一致
+func (r *frameReader) next() error {
+	if len(r.frames) == 0 {
+		return io.ErrUnexpectedEOF
+	}
+	f := r.frames[0]
+	r.frames = r.frames[1:]
+	if int64(cap(r.cBuf)) < f.cSize {
+		r.cBuf = make([]byte, f.cSize)
+	}
+	r.cBuf = r.cBuf[:f.cSize]
+	if _, err := io.ReadFull(r.src, r.cBuf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	plain, err := r.dec.DecodeAll(r.cBuf, r.buf[:0])
+	if err != nil || int64(len(plain)) != f.size {
+		return ErrCorrupt
+	}
+	r.buf = plain
+	r.plain = plain[r.skip:]
+	r.skip = 0
+	return nil
+}
+
+func (r *frameReader) Close() error {
+	r.dec.Close()
+	return r.src.Close()
+}
@@ -0,0 +1,211 @@
+// Package crypt : 存储内容的信封加密. 文件在每个存储中的位置使用独立的数据密钥,
+// 内容按块以AES-256-GCM加密以支持range读取, 数据密钥由KMS中的主密钥加密后保存,
+// 更换主密钥时只需重新加密数据密钥, 不需要重新加密文件内容
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	// KeySize : 数据密钥及主密钥的长度(AES-256)
+	KeySize = 32
+	// Overhead : 每个加密块增加的长度(GCM tag)
+	Overhead = 16
+)
+
+// ErrCorrupt : 密文块校验失败(内容损坏、被篡改或使用了错误的密钥)
+var ErrCorrupt = errors.New("crypt: ciphertext authentication failed")
+
+// NewDataKey : 生成随机的数据密钥
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// nonce : 第index块的nonce. 每个数据密钥只用于一个位置的固定内容,
+// 块序号作为nonce不会以同一nonce加密不同的明文, 同时防止块被重排
+func nonce(index int64) []byte {
+	n := make([]byte, 12)
+	binary.BigEndian.PutUint64(n[4:], uint64(index))
+	return n
+}
+
+// CipherSize : 明文长度为size时密文的长度
+func CipherSize(size int64, chunkSize int) int64 {
+	cs := int64(chunkSize)
+	return size + (size+cs-1)/cs*Overhead
+}
+
+// encryptReader : 按块读取明文并输出密文
+type encryptReader struct {
+	aead      cipher.AEAD
+	aad       []byte
+	src       io.Reader
+	chunkSize int
+	index     int64
+	buf       []byte
+	out       []byte
+	err       error
+}
+
+// NewEncryptReader : 读取src的明文, 以chunkSize为块输出密文. aad为附加校验数据(如文件hash),
+// 解密时须相同
+func NewEncryptReader(key, aad []byte, chunkSize int, src io.Reader) (io.Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptReader{
+		aead:      aead,
+		aad:       aad,
+		src:       src,
+		chunkSize: chunkSize,
+		buf:       make([]byte, chunkSize+Overhead),
+	}, nil
+}
+
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		n, err := io.ReadFull(r.src, r.buf[:r.chunkSize])
+		if n > 0 {
+			r.out = r.aead.Seal(r.buf[:0], nonce(r.index), r.buf[:n], r.aad)
+			r.index++
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			r.err = io.EOF
+		} else if err != nil {
+			r.err = err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// decryptReader : 按块读取密文并输出明文, 只输出从skip开始的remaining字节
+type decryptReader struct {
+	aead      cipher.AEAD
+	aad       []byte
+	src       io.ReadCloser
+	chunkSize int
+	index     int64
+	skip      int64
+	// remaining : 尚未输出的明文长度, -1表示读取到末尾
+	remaining int64
+	buf       []byte
+	out       []byte
+	err       error
+}
+
+// NewDecryptReader : 读取明文[offset, offset+length)的内容, length为-1时读取到末尾.
+// open按密文范围打开数据, cLength为-1时读取到末尾; 范围超出密文末尾时返回到末尾的数据.
+// 密文在length之前结束时返回io.ErrUnexpectedEOF
+func NewDecryptReader(key, aad []byte, chunkSize int, offset, length int64,
+	open func(cOffset, cLength int64) (io.ReadCloser, error)) (io.ReadCloser, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	cs := int64(chunkSize)
+	first := offset / cs
+	cLength := int64(-1)
+	if length >= 0 {
+		last := first
+		if length > 0 {
+			last = (offset + length - 1) / cs
+		}
+		cLength = (last - first + 1) * (cs + Overhead)
+	}
+	src, err := open(first*(cs+Overhead), cLength)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{
+		aead:      aead,
+		aad:       aad,
+		src:       src,
+		chunkSize: chunkSize,
+		index:     first,
+		skip:      offset - first*cs,
+		remaining: length,
+		buf:       make([]byte, chunkSize+Overhead),
+	}, nil
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.remaining == 0 {
+			return 0, io.EOF
+		}
+		if r.err != nil {
+			if r.err == io.EOF && r.remaining > 0 {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, r.err
+		}
+		r.next()
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// next : 读取并解密下一块
+func (r *decryptReader) next() {
+	n, err := io.ReadFull(r.src, r.buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		r.err = io.EOF
+	} else if err != nil {
+		r.err = err
+		return
+	}
+	if n == 0 {
+		return
+	}
+
+	plain, oerr := r.aead.Open(r.buf[:0], nonce(r.index), r.buf[:n], r.aad)
+	if oerr != nil {
+		r.err = ErrCorrupt
+		return
+	}
+	r.index++
+	if r.skip > 0 {
+		skip := r.skip
+		if skip > int64(len(plain)) {
+			skip = int64(len(plain))
+		}
+		plain = plain[skip:]
+		r.skip -= skip
+	}
+	if r.remaining >= 0 {
+		if int64(len(plain)) > r.remaining {
+			plain = plain[:r.remaining]
+		}
+		r.remaining -= int64(len(plain))
+	}
+	r.out = plain
+}
+
+func (r *decryptReader) Close() error {
+	return r.src.Close()
+}
@@ -0,0 +1,110 @@
+// Package dbcrypt : 通过dbproxy保存数据密钥, 提供默认的信封加密
+package dbcrypt
+
+import (
+	"encoding/base64"
+	"errors"
+	"sync"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/service/dbproxy/orm"
+	"github.com/cloud/store/crypt"
+)
+
+// execResult : 将dbproxy的执行结果转换为错误
+func execResult(res *orm.ExecResult, err error) (*orm.ExecResult, error) {
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, errors.New("dbproxy: empty result")
+	}
+	if !res.Suc {
+		return nil, errors.New("dbproxy: " + res.Msg)
+	}
+	return res, nil
+}
+
+// KeyStore : 实现crypt.KeyStore, 数据密钥以base64保存在tbl_file_key中
+type KeyStore struct{}
+
+// toKey : 将查询结果转换为数据密钥, 没有记录时返回nil
+func toKey(res *orm.ExecResult) (*crypt.Key, error) {
+	if res.Data == nil {
+		return nil, nil
+	}
+	row := dbcli.ToTableFileKey(res.Data)
+	wrapped, err := base64.StdEncoding.DecodeString(row.WrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	return &crypt.Key{KeyID: row.KeyID, Wrapped: wrapped, ChunkSize: row.ChunkSize}, nil
+}
+
+// Get : 实现crypt.KeyStore
+func (KeyStore) Get(filehash string, t common.StoreType) (*crypt.Key, error) {
+	res, err := execResult(dbcli.GetFileKey(filehash, t))
+	if err != nil {
+		return nil, err
+	}
+	return toKey(res)
+}
+
+// Create : 实现crypt.KeyStore
+func (KeyStore) Create(filehash string, t common.StoreType, key crypt.Key) (*crypt.Key, error) {
+	res, err := execResult(dbcli.CreateFileKey(filehash, t, key.KeyID,
+		base64.StdEncoding.EncodeToString(key.Wrapped), key.ChunkSize))
+	if err != nil {
+		return nil, err
+	}
+	stored, err := toKey(res)
+	if err == nil && stored == nil {
+		err = errors.New("dbcrypt: data key of " + filehash + " not saved")
+	}
+	return stored, err
+}
+
+// Rewrap : 实现crypt.KeyStore
+func (KeyStore) Rewrap(filehash string, t common.StoreType, oldKeyID string, key crypt.Key) error {
+	_, err := execResult(dbcli.RewrapFileKey(filehash, t, oldKeyID, key.KeyID,
+		base64.StdEncoding.EncodeToString(key.Wrapped)))
+	return err
+}
+
+// Remove : 实现crypt.KeyStore
+func (KeyStore) Remove(filehash string, t common.StoreType) error {
+	_, err := execResult(dbcli.RemoveFileKey(filehash, t))
+	return err
+}
+
+// ListStale : 实现crypt.KeyStore
+func (KeyStore) ListStale(keyID string, limit int) ([]crypt.KeyRef, error) {
+	res, err := execResult(dbcli.ListStaleFileKeys(keyID, limit))
+	if err != nil {
+		return nil, err
+	}
+	refs := []crypt.KeyRef{}
+	for _, row := range dbcli.ToTableFileKeys(res.Data) {
+		refs = append(refs, crypt.KeyRef{FileHash: row.FileHash, StoreType: common.StoreType(row.StoreType)})
+	}
+	return refs, nil
+}
+
+var (
+	defaultOnce     sync.Once
+	defaultEnvelope *crypt.Envelope
+)
+
+// Default : 使用默认KMS及dbproxy的信封加密, 首次使用时创建
+func Default() *crypt.Envelope {
+	defaultOnce.Do(func() {
+		defaultEnvelope = &crypt.Envelope{
+			KMS:       crypt.DefaultKMS(),
+			Keys:      KeyStore{},
+			ChunkSize: config.EncryptionChunkSize,
+		}
+	})
+	return defaultEnvelope
+}
@@ -0,0 +1,141 @@
+package crypt
+
+import (
+	"bytes"
+	"io"
+	"log"
+
+	"github.com/cloud/common"
+)
+
+// Key : 文件在一个存储中的位置的数据密钥(已由主密钥加密)
+type Key struct {
+	// KeyID : 加密数据密钥的主密钥ID
+	KeyID   string
+	Wrapped []byte
+	// ChunkSize : 加密块的明文长度
+	ChunkSize int
+}
+
+// KeyRef : 数据密钥对应的文件及存储类型
+type KeyRef struct {
+	FileHash  string
+	StoreType common.StoreType
+}
+
+// KeyStore : 数据密钥的读写, 由dbcrypt通过dbproxy实现, 测试时可替换为内存实现.
+// 位置有数据密钥记录表示该位置的内容已加密
+type KeyStore interface {
+	// Get : 查询数据密钥, 没有记录(内容未加密)时返回nil
+	Get(filehash string, t common.StoreType) (*Key, error)
+	// Create : 保存数据密钥, 已有记录时(如并发写入)不覆盖, 返回保存的记录
+	Create(filehash string, t common.StoreType, key Key) (*Key, error)
+	// Rewrap : 主密钥仍为oldKeyID时更新为重新加密的数据密钥
+	Rewrap(filehash string, t common.StoreType, oldKeyID string, key Key) error
+	// Remove : 删除数据密钥(内容已删除或以明文重新写入)
+	Remove(filehash string, t common.StoreType) error
+	// ListStale : 主密钥不是keyID的数据密钥
+	ListStale(keyID string, limit int) ([]KeyRef, error)
+}
+
+// Envelope : 以数据密钥加密存储内容, 数据密钥由KMS中的主密钥加密
+type Envelope struct {
+	KMS  KMS
+	Keys KeyStore
+	// ChunkSize : 新数据密钥的加密块长度
+	ChunkSize int
+}
+
+// Lookup : 位置的数据密钥, 内容未加密时返回nil
+func (e *Envelope) Lookup(filehash string, t common.StoreType) (*Key, error) {
+	return e.Keys.Get(filehash, t)
+}
+
+// Encrypt : 返回r的密文. 位置已有数据密钥时(如副本修复后重新写入)沿用, 否则生成新的数据密钥.
+// 同一位置的内容固定为filehash对应的内容, 沿用数据密钥不会以同一nonce加密不同的明文
+func (e *Envelope) Encrypt(filehash string, t common.StoreType, r io.Reader) (io.Reader, error) {
+	key, err := e.Keys.Get(filehash, t)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataKey []byte
+	if key == nil {
+		if dataKey, err = NewDataKey(); err != nil {
+			return nil, err
+		}
+		keyID, wrapped, err := e.KMS.Wrap(dataKey)
+		if err != nil {
+			return nil, err
+		}
+		key, err = e.Keys.Create(filehash, t, Key{KeyID: keyID, Wrapped: wrapped, ChunkSize: e.ChunkSize})
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(key.Wrapped, wrapped) {
+			// 并发写入时以先保存的数据密钥为准
+			dataKey = nil
+		}
+	}
+	if dataKey == nil {
+		if dataKey, err = e.KMS.Unwrap(key.KeyID, key.Wrapped); err != nil {
+			return nil, err
+		}
+	}
+	return NewEncryptReader(dataKey, []byte(filehash), key.ChunkSize, r)
+}
+
+// Open : 读取以key加密的位置中明文[offset, offset+length)的内容, 见NewDecryptReader
+func (e *Envelope) Open(filehash string, key *Key, offset, length int64,
+	open func(cOffset, cLength int64) (io.ReadCloser, error)) (io.ReadCloser, error) {
+	dataKey, err := e.KMS.Unwrap(key.KeyID, key.Wrapped)
+	if err != nil {
+		return nil, err
+	}
+	return NewDecryptReader(dataKey, []byte(filehash), key.ChunkSize, offset, length, open)
+}
+
+// Forget : 删除位置的数据密钥
+func (e *Envelope) Forget(filehash string, t common.StoreType) error {
+	return e.Keys.Remove(filehash, t)
+}
+
+// Rewrap : 以当前主密钥重新加密最多limit个由旧主密钥加密的数据密钥, 文件内容不变.
+// 返回处理的个数
+func (e *Envelope) Rewrap(limit int) (int, error) {
+	current, err := e.KMS.CurrentKeyID()
+	if err != nil {
+		return 0, err
+	}
+	refs, err := e.Keys.ListStale(current, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, ref := range refs {
+		key, err := e.Keys.Get(ref.FileHash, ref.StoreType)
+		if err != nil {
+			return count, err
+		}
+		if key == nil || key.KeyID == current {
+			continue
+		}
+		dataKey, err := e.KMS.Unwrap(key.KeyID, key.Wrapped)
+		if err != nil {
+			log.Printf("unwrap data key of %s failed, err:%s\n", ref.FileHash, err.Error())
+			continue
+		}
+		keyID, wrapped, err := e.KMS.Wrap(dataKey)
+		if err != nil {
+			return count, err
+		}
+		err = e.Keys.Rewrap(ref.FileHash, ref.StoreType, key.KeyID,
+			Key{KeyID: keyID, Wrapped: wrapped, ChunkSize: key.ChunkSize})
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,59 @@
+package crypt
+
+import (
+	"errors"
+	"log"
+	"sync"
+
+	"github.com/cloud/config"
+)
+
+// KMS后端, 见config.KMSBackend
+const (
+	KMSLocal = "local"
+)
+
+// KMS : 保管主密钥, 加密及解密数据密钥. 主密钥不离开KMS
+type KMS interface {
+	// CurrentKeyID : 当前用于加密数据密钥的主密钥ID
+	CurrentKeyID() (string, error)
+	// Wrap : 以当前主密钥加密数据密钥, 返回使用的主密钥ID
+	Wrap(dataKey []byte) (keyID string, wrapped []byte, err error)
+	// Unwrap : 以keyID对应的主密钥解密数据密钥, 更换后的旧主密钥仍可解密
+	Unwrap(keyID string, wrapped []byte) ([]byte, error)
+}
+
+// NewKMS : 创建指定后端的KMS
+func NewKMS(backend string) (KMS, error) {
+	switch backend {
+	case KMSLocal:
+		return NewLocalKMS(config.KMSLocalKeyFile)
+	}
+	return nil, errors.New("crypt: unknown kms backend " + backend)
+}
+
+var (
+	defaultMu  sync.Mutex
+	defaultKMS KMS
+)
+
+// DefaultKMS : 按config.KMSBackend创建的默认KMS, 首次使用时创建
+func DefaultKMS() KMS {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultKMS == nil {
+		k, err := NewKMS(config.KMSBackend)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		defaultKMS = k
+	}
+	return defaultKMS
+}
+
+// SetDefaultKMS : 替换默认KMS, 应在使用DefaultKMS之前调用
+func SetDefaultKMS(k KMS) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultKMS = k
+}
@@ -0,0 +1,192 @@
+package crypt
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// localKeyFile : 本地主密钥文件的内容
+type localKeyFile struct {
+	Current string            `json:"current"`
+	Keys    map[string]string `json:"keys"`
+}
+
+// LocalKMS : 主密钥保存在本地文件中的KMS, 用于开发及测试. 文件不存在时生成第一个主密钥;
+// 文件被修改(如其他进程执行了Rotate)后自动重新加载
+type LocalKMS struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	current string
+	keys    map[string][]byte
+}
+
+// NewLocalKMS : 使用path中的主密钥
+func NewLocalKMS(path string) (*LocalKMS, error) {
+	k := &LocalKMS{path: path}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		// 多个服务同时启动时只有一个生成的主密钥生效, 其余使用已生成的文件
+		if _, err := k.rotate(true); err != nil && !os.IsExist(err) {
+			return nil, err
+		}
+	}
+	if err := k.reload(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// reload : 文件修改时间变化时重新读取
+func (k *LocalKMS) reload() error {
+	info, err := os.Stat(k.path)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(k.modTime) && k.keys != nil {
+		return nil
+	}
+	data, err := ioutil.ReadFile(k.path)
+	if err != nil {
+		return err
+	}
+	file := localKeyFile{}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	keys := map[string][]byte{}
+	for id, encoded := range file.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != KeySize {
+			return errors.New("crypt: invalid master key " + id)
+		}
+		keys[id] = key
+	}
+	if _, ok := keys[file.Current]; !ok {
+		return errors.New("crypt: current master key not found: " + file.Current)
+	}
+	k.current, k.keys, k.modTime = file.Current, keys, info.ModTime()
+	return nil
+}
+
+// Rotate : 生成新的主密钥并作为当前主密钥, 旧主密钥保留用于解密, 返回新主密钥ID
+func (k *LocalKMS) Rotate() (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.reload(); err != nil {
+		return "", err
+	}
+	return k.rotate(false)
+}
+
+// rotate : 写入增加了新主密钥的文件, create为true时只在文件不存在时创建
+func (k *LocalKMS) rotate(create bool) (string, error) {
+	key, err := NewDataKey()
+	if err != nil {
+		return "", err
+	}
+	suffix := make([]byte, 4)
+	if _, err := io.ReadFull(rand.Reader, suffix); err != nil {
+		return "", err
+	}
+	id := strconv.FormatInt(time.Now().Unix(), 10) + "-" + hex.EncodeToString(suffix)
+
+	file := localKeyFile{Current: id, Keys: map[string]string{}}
+	for kid, mk := range k.keys {
+		file.Keys[kid] = base64.StdEncoding.EncodeToString(mk)
+	}
+	file.Keys[id] = base64.StdEncoding.EncodeToString(key)
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	// 先写入临时文件再替换, 其他进程不会读到不完整的文件
+	if err := os.MkdirAll(filepath.Dir(k.path), 0700); err != nil {
+		return "", err
+	}
+	tmp := k.path + "." + id + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return "", err
+	}
+	if create {
+		err = os.Link(tmp, k.path)
+		os.Remove(tmp)
+	} else if err = os.Rename(tmp, k.path); err != nil {
+		os.Remove(tmp)
+	}
+	if err != nil {
+		return "", err
+	}
+	k.keys = nil
+	if err := k.reload(); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// CurrentKeyID : 实现KMS
+func (k *LocalKMS) CurrentKeyID() (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.reload(); err != nil {
+		return "", err
+	}
+	return k.current, nil
+}
+
+// Wrap : 实现KMS, 以主密钥AES-256-GCM加密, 结果为nonce+密文, 主密钥ID作为附加校验数据
+func (k *LocalKMS) Wrap(dataKey []byte) (string, []byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.reload(); err != nil {
+		return "", nil, err
+	}
+	aead, err := newAEAD(k.keys[k.current])
+	if err != nil {
+		return "", nil, err
+	}
+	n := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, n); err != nil {
+		return "", nil, err
+	}
+	return k.current, aead.Seal(n, n, dataKey, []byte(k.current)), nil
+}
+
+// Unwrap : 实现KMS
+func (k *LocalKMS) Unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.reload(); err != nil {
+		return nil, err
+	}
+	master, ok := k.keys[keyID]
+	if !ok {
+		return nil, errors.New("crypt: unknown master key " + keyID)
+	}
+	aead, err := newAEAD(master)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, ErrCorrupt
+	}
+	n := aead.NonceSize()
+	dataKey, err := aead.Open(nil, wrapped[:n], wrapped[n:], []byte(keyID))
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	return dataKey, nil
+}
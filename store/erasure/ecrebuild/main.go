@@ -0,0 +1,50 @@
+// ecrebuild : 更换磁盘后重新写入纠删码存储中缺失或损坏的分片, 在挂载config.ErasureDirs的节点上运行:
+//
+//	go run ./store/erasure/ecrebuild            # 检查并重建所有对象
+//	go run ./store/erasure/ecrebuild -name <sha1> # 只重建一个对象
+//
+// 可用分片少于数据分片数的对象无法重建, 由副本修复任务从其他存储重新写入
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/cloud/store/erasure"
+)
+
+func main() {
+	name := flag.String("name", "", "只重建该对象(存储位置去掉config.ErasureRootDir前缀, 通常为文件sha1)")
+	flag.Parse()
+
+	store := erasure.Default()
+	names := []string{*name}
+	if *name == "" {
+		var err error
+		if names, err = store.Names(); err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+
+	objects, shards, failed := 0, 0, 0
+	for _, n := range names {
+		count, err := store.Rebuild(n)
+		if err != nil {
+			log.Printf("rebuild %s failed, err:%s\n", n, err.Error())
+			failed++
+			continue
+		}
+		if count > 0 {
+			log.Printf("rebuilt %d shards of %s\n", count, n)
+			objects++
+			shards += count
+		}
+	}
+	fmt.Printf("checked %d objects, rebuilt %d shards of %d objects, %d failed\n",
+		len(names), shards, objects, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
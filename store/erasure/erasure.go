@@ -0,0 +1,428 @@
+// Package erasure : 本地多磁盘的纠删码存储. 对象按条带切分为k个数据分片及m个校验分片(Reed-Solomon),
+// 各分片写入不同磁盘上的目录, 最多m个分片缺失或损坏时读取可重建内容; 更换磁盘后由Rebuild重新写入分片.
+// 分片文件以头部记录分片参数及对象长度, 每块附带CRC32以发现损坏的块
+package erasure
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/reedsolomon"
+
+	"github.com/cloud/config"
+)
+
+const (
+	// headerSize : 分片文件头部的长度
+	headerSize = 32
+	// crcSize : 每块末尾CRC32的长度
+	crcSize = 4
+	// shardExt : 分片文件名的后缀, 后接分片序号
+	shardExt = ".ec"
+	magic    = "RSEC"
+	version  = 1
+)
+
+var (
+	// ErrTooFewShards : 可用的分片少于数据分片数, 无法重建
+	ErrTooFewShards = errors.New("erasure: too few healthy shards")
+	// errChunkCorrupt : 块的CRC32不一致
+	errChunkCorrupt = errors.New("erasure: chunk checksum mismatch")
+)
+
+// Store : 纠删码存储
+type Store struct {
+	// Dirs : 各磁盘上的目录, 数量不少于分片总数
+	Dirs         []string
+	DataShards   int
+	ParityShards int
+	// ChunkSize : 每个分片中一块的长度
+	ChunkSize int
+
+	mu       sync.Mutex
+	encoders map[[2]int]reedsolomon.Encoder
+}
+
+// New : 以k个数据分片及m个校验分片写入新对象的存储, dirs须位于不同磁盘且数量不少于k+m
+func New(dirs []string, dataShards, parityShards, chunkSize int) (*Store, error) {
+	if dataShards <= 0 || parityShards <= 0 || dataShards+parityShards > 255 {
+		return nil, fmt.Errorf("erasure: invalid shards %d+%d", dataShards, parityShards)
+	}
+	if chunkSize <= 0 {
+		return nil, fmt.Errorf("erasure: invalid chunk size %d", chunkSize)
+	}
+	if len(dirs) < dataShards+parityShards {
+		return nil, fmt.Errorf("erasure: %d dirs for %d shards", len(dirs), dataShards+parityShards)
+	}
+	seen := map[string]bool{}
+	for _, dir := range dirs {
+		clean := filepath.Clean(dir)
+		if seen[clean] {
+			return nil, errors.New("erasure: duplicate dir " + dir)
+		}
+		seen[clean] = true
+	}
+	s := &Store{
+		Dirs:         dirs,
+		DataShards:   dataShards,
+		ParityShards: parityShards,
+		ChunkSize:    chunkSize,
+		encoders:     map[[2]int]reedsolomon.Encoder{},
+	}
+	if _, err := s.encoder(dataShards, parityShards); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+var (
+	defaultOnce  sync.Once
+	defaultStore *Store
+)
+
+// Default : 按config中纠删码配置创建的存储, 首次使用时创建
+func Default() *Store {
+	defaultOnce.Do(func() {
+		s, err := New(config.ErasureDirs, config.ErasureDataShards, config.ErasureParityShards, config.ErasureChunkSize)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		defaultStore = s
+	})
+	return defaultStore
+}
+
+// encoder : k+m的编码器. 对象以写入时的分片参数读取, 修改配置不影响已写入的对象
+func (s *Store) encoder(dataShards, parityShards int) (reedsolomon.Encoder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := [2]int{dataShards, parityShards}
+	if enc, ok := s.encoders[key]; ok {
+		return enc, nil
+	}
+	enc, err := reedsolomon.New(dataShards, parityShards)
+	if err != nil {
+		return nil, err
+	}
+	s.encoders[key] = enc
+	return enc, nil
+}
+
+// checkName : 对象名须为不含".."的相对路径
+func checkName(name string) error {
+	if name == "" || filepath.IsAbs(name) || filepath.Clean(name) != name || strings.HasPrefix(name, "..") {
+		return errors.New("erasure: invalid object name " + name)
+	}
+	return nil
+}
+
+// shardPath : 对象name的第i个分片的路径. 分片从按name选择的目录开始依次放置, 使各磁盘的用量均衡
+func (s *Store) shardPath(name string, i int) string {
+	start := int(crc32.ChecksumIEEE([]byte(name)) % uint32(len(s.Dirs)))
+	return filepath.Join(s.Dirs[(start+i)%len(s.Dirs)], name+shardExt+strconv.Itoa(i))
+}
+
+// locate : 对象name第i个分片所在的路径. 分片通常位于shardPath, 目录数量变化后在其余目录中查找,
+// 都不存在时返回shardPath
+func (s *Store) locate(name string, i int) string {
+	path := s.shardPath(name, i)
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	for _, dir := range s.Dirs {
+		other := filepath.Join(dir, name+shardExt+strconv.Itoa(i))
+		if _, err := os.Stat(other); err == nil {
+			return other
+		}
+	}
+	return path
+}
+
+// shardFiles : 目录dir中对象name的分片文件(含未完成的临时文件)
+func shardFiles(dir, name string) ([]string, error) {
+	parent := filepath.Join(dir, filepath.Dir(name))
+	prefix := filepath.Base(name) + shardExt
+	fd, err := os.Open(parent)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer fd.Close()
+	entries, err := fd.Readdirnames(-1)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry, prefix) {
+			continue
+		}
+		if _, err := strconv.Atoi(strings.TrimSuffix(entry[len(prefix):], ".tmp")); err == nil {
+			files = append(files, filepath.Join(parent, entry))
+		}
+	}
+	return files, nil
+}
+
+// header : 分片文件头部
+type header struct {
+	dataShards, parityShards int
+	index                    int
+	chunkSize                int
+	size                     int64
+}
+
+// stripes : 条带数
+func (h header) stripes() int64 {
+	stripe := int64(h.dataShards * h.chunkSize)
+	return (h.size + stripe - 1) / stripe
+}
+
+// fileSize : 分片文件的长度
+func (h header) fileSize() int64 {
+	return headerSize + h.stripes()*int64(h.chunkSize+crcSize)
+}
+
+// chunkOffset : 第j个条带的块在分片文件中的偏移
+func (h header) chunkOffset(j int64) int64 {
+	return headerSize + j*int64(h.chunkSize+crcSize)
+}
+
+// sameObject : 两个分片是否属于同一对象(序号除外)
+func (h header) sameObject(o header) bool {
+	return h.dataShards == o.dataShards && h.parityShards == o.parityShards &&
+		h.chunkSize == o.chunkSize && h.size == o.size
+}
+
+func (h header) marshal() []byte {
+	b := make([]byte, headerSize)
+	copy(b[0:4], magic)
+	b[4] = version
+	b[5] = byte(h.dataShards)
+	b[6] = byte(h.parityShards)
+	b[7] = byte(h.index)
+	binary.LittleEndian.PutUint32(b[8:12], uint32(h.chunkSize))
+	binary.LittleEndian.PutUint64(b[12:20], uint64(h.size))
+	binary.LittleEndian.PutUint32(b[28:32], crc32.ChecksumIEEE(b[:28]))
+	return b
+}
+
+func unmarshalHeader(b []byte) (header, error) {
+	if len(b) < headerSize || string(b[0:4]) != magic || b[4] != version ||
+		binary.LittleEndian.Uint32(b[28:32]) != crc32.ChecksumIEEE(b[:28]) {
+		return header{}, errors.New("erasure: invalid shard header")
+	}
+	h := header{
+		dataShards:   int(b[5]),
+		parityShards: int(b[6]),
+		index:        int(b[7]),
+		chunkSize:    int(binary.LittleEndian.Uint32(b[8:12])),
+		size:         int64(binary.LittleEndian.Uint64(b[12:20])),
+	}
+	if h.dataShards == 0 || h.parityShards == 0 || h.index >= h.dataShards+h.parityShards || h.chunkSize == 0 {
+		return header{}, errors.New("erasure: invalid shard header")
+	}
+	return h, nil
+}
+
+// shardWriter : 写入一个分片的临时文件, 完成后移到分片路径
+type shardWriter struct {
+	path string
+	fd   *os.File
+	w    *bufio.Writer
+}
+
+func createShard(path string) (*shardWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0744); err != nil {
+		return nil, err
+	}
+	fd, err := os.Create(path + ".tmp")
+	if err != nil {
+		return nil, err
+	}
+	return &shardWriter{path: path, fd: fd, w: bufio.NewWriter(fd)}, nil
+}
+
+// writeChunk : 写入一块及其CRC32
+func (sw *shardWriter) writeChunk(chunk []byte) error {
+	if _, err := sw.w.Write(chunk); err != nil {
+		return err
+	}
+	sum := make([]byte, crcSize)
+	binary.LittleEndian.PutUint32(sum, crc32.ChecksumIEEE(chunk))
+	_, err := sw.w.Write(sum)
+	return err
+}
+
+// finish : 写入头部并同步到磁盘
+func (sw *shardWriter) finish(h header) error {
+	if err := sw.w.Flush(); err != nil {
+		return err
+	}
+	if _, err := sw.fd.WriteAt(h.marshal(), 0); err != nil {
+		return err
+	}
+	if err := sw.fd.Sync(); err != nil {
+		return err
+	}
+	return sw.fd.Close()
+}
+
+// abort : 删除临时文件
+func (sw *shardWriter) abort() {
+	sw.fd.Close()
+	os.Remove(sw.path + ".tmp")
+}
+
+// Put : 将r的内容写入对象name. 各分片先写入临时文件, 全部写入后再依次移到分片路径
+func (s *Store) Put(name string, r io.Reader) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	enc, err := s.encoder(s.DataShards, s.ParityShards)
+	if err != nil {
+		return err
+	}
+	total := s.DataShards + s.ParityShards
+	writers := make([]*shardWriter, 0, total)
+	defer func() {
+		for _, sw := range writers {
+			if sw != nil {
+				sw.abort()
+			}
+		}
+	}()
+	for i := 0; i < total; i++ {
+		sw, err := createShard(s.shardPath(name, i))
+		if err != nil {
+			return err
+		}
+		writers = append(writers, sw)
+		// 头部在写完内容后填写
+		if _, err := sw.w.Write(make([]byte, headerSize)); err != nil {
+			return err
+		}
+	}
+
+	// 逐条带编码: 数据分片为条带内容的切分, 最后一个条带以0填充
+	buf := make([]byte, total*s.ChunkSize)
+	shards := make([][]byte, total)
+	for i := range shards {
+		shards[i] = buf[i*s.ChunkSize : (i+1)*s.ChunkSize]
+	}
+	data := buf[:s.DataShards*s.ChunkSize]
+	var size int64
+	for {
+		n, err := io.ReadFull(r, data)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+		for i := n; i < len(data); i++ {
+			data[i] = 0
+		}
+		if err := enc.Encode(shards); err != nil {
+			return err
+		}
+		for i, sw := range writers {
+			if err := sw.writeChunk(shards[i]); err != nil {
+				return err
+			}
+		}
+		size += int64(n)
+		if n < len(data) {
+			break
+		}
+	}
+
+	h := header{dataShards: s.DataShards, parityShards: s.ParityShards, chunkSize: s.ChunkSize, size: size}
+	for i, sw := range writers {
+		h.index = i
+		if err := sw.finish(h); err != nil {
+			return err
+		}
+	}
+	for i, sw := range writers {
+		if err := os.Rename(sw.path+".tmp", sw.path); err != nil {
+			return err
+		}
+		writers[i] = nil
+	}
+	return nil
+}
+
+// Remove : 删除对象name的所有分片, 不存在时不返回错误
+func (s *Store) Remove(name string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	// 分片可能不在shardPath(如目录数量变化后), 删除所有目录中的分片
+	var firstErr error
+	for _, dir := range s.Dirs {
+		files, err := shardFiles(dir, name)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		for _, file := range files {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// Names : 各目录中有分片的对象名
+func (s *Store) Names() ([]string, error) {
+	set := map[string]bool{}
+	for _, dir := range s.Dirs {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			pos := strings.LastIndex(rel, shardExt)
+			if pos <= 0 {
+				return nil
+			}
+			if _, err := strconv.Atoi(rel[pos+len(shardExt):]); err != nil {
+				return nil
+			}
+			set[rel[:pos]] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
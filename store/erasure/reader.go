@@ -0,0 +1,342 @@
+package erasure
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+// object : 打开的对象, 不可用的分片为nil
+type object struct {
+	name string
+	// h : 对象的分片参数及长度(index无意义)
+	h       header
+	enc     reedsolomon.Encoder
+	paths   []string
+	files   []*os.File
+	corrupt []bool
+}
+
+// openShard : 打开第i个分片并读取头部
+func openShard(path string, i int) (*os.File, header, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, header{}, err
+	}
+	b := make([]byte, headerSize)
+	var h header
+	if _, err = fd.ReadAt(b, 0); err == nil {
+		h, err = unmarshalHeader(b)
+	}
+	if err == nil && h.index != i {
+		err = fmt.Errorf("erasure: shard %s has index %d", path, h.index)
+	}
+	if err != nil {
+		fd.Close()
+		return nil, header{}, err
+	}
+	return fd, h, nil
+}
+
+// openObject : 打开对象name的各分片. 分片参数以第一个有效分片的头部为准,
+// 头部不一致或长度不符的分片视为不可用; 可用分片少于数据分片数时返回ErrTooFewShards
+func (s *Store) openObject(name string) (*object, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	o := &object{name: name}
+	headers := []header{}
+	found, missing := false, 0
+	total := s.DataShards + s.ParityShards
+	for i := 0; i < total; i++ {
+		path := s.locate(name, i)
+		fd, h, err := openShard(path, i)
+		if err != nil {
+			if os.IsNotExist(err) {
+				missing++
+			} else {
+				log.Printf("erasure: shard %d of %s unavailable, err:%s\n", i, name, err.Error())
+			}
+		} else if !found {
+			// 对象以写入时的分片数读取
+			found, o.h, total = true, h, h.dataShards+h.parityShards
+		}
+		o.paths = append(o.paths, path)
+		o.files = append(o.files, fd)
+		headers = append(headers, h)
+	}
+	if !found {
+		if missing == len(o.files) {
+			return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+		}
+		o.close()
+		return nil, ErrTooFewShards
+	}
+	for i := total; i < len(o.files); i++ {
+		if o.files[i] != nil {
+			o.files[i].Close()
+		}
+	}
+	o.paths, o.files, headers = o.paths[:total], o.files[:total], headers[:total]
+	o.corrupt = make([]bool, total)
+
+	available := 0
+	for i, fd := range o.files {
+		if fd == nil {
+			continue
+		}
+		info, err := fd.Stat()
+		if err != nil || !headers[i].sameObject(o.h) || info.Size() != o.h.fileSize() {
+			log.Printf("erasure: shard %d of %s does not match the object\n", i, name)
+			o.fail(i)
+			continue
+		}
+		available++
+	}
+	if available < o.h.dataShards {
+		o.close()
+		return nil, ErrTooFewShards
+	}
+
+	var err error
+	if o.enc, err = s.encoder(o.h.dataShards, o.h.parityShards); err != nil {
+		o.close()
+		return nil, err
+	}
+	return o, nil
+}
+
+// fail : 第i个分片不再读取
+func (o *object) fail(i int) {
+	if o.files[i] != nil {
+		o.files[i].Close()
+		o.files[i] = nil
+	}
+}
+
+func (o *object) close() {
+	for i := range o.files {
+		o.fail(i)
+	}
+}
+
+// newBuffers : 每个分片一块及其CRC32的缓冲
+func (o *object) newBuffers() [][]byte {
+	bufs := make([][]byte, len(o.files))
+	for i := range bufs {
+		bufs[i] = make([]byte, o.h.chunkSize+crcSize)
+	}
+	return bufs
+}
+
+// readStripe : 读取第j个条带中各分片的块, 缺失或校验失败的块为长度0的缓冲(用于重建).
+// all为false时数据分片完整则不读取校验分片, 否则只读取到可以重建数据为止
+func (o *object) readStripe(j int64, bufs [][]byte, all bool) ([][]byte, error) {
+	shards := make([][]byte, len(o.files))
+	have := 0
+	for i, fd := range o.files {
+		shards[i] = bufs[i][:0]
+		if fd == nil || (!all && have == o.h.dataShards) {
+			continue
+		}
+		chunk := bufs[i][:o.h.chunkSize+crcSize]
+		if _, err := fd.ReadAt(chunk, o.h.chunkOffset(j)); err != nil {
+			log.Printf("erasure: read shard %d of %s failed, err:%s\n", i, o.name, err.Error())
+			o.fail(i)
+			continue
+		}
+		data := chunk[:o.h.chunkSize]
+		if binary.LittleEndian.Uint32(chunk[o.h.chunkSize:]) != crc32.ChecksumIEEE(data) {
+			log.Printf("erasure: stripe %d of shard %d of %s: %s\n", j, i, o.name, errChunkCorrupt.Error())
+			o.corrupt[i] = true
+			continue
+		}
+		shards[i] = data
+		have++
+	}
+	if have < o.h.dataShards {
+		return nil, ErrTooFewShards
+	}
+	return shards, nil
+}
+
+// Reader : 读取对象的一段, 缺失或损坏的数据块由校验分片重建
+type Reader struct {
+	o      *object
+	bufs   [][]byte
+	data   []byte
+	plain  []byte
+	stripe int64
+	skip   int64
+	remain int64
+	err    error
+}
+
+// Open : 读取对象name从offset开始的length字节, length为-1或超出末尾时读取到末尾.
+// 对象不存在时返回的错误满足os.IsNotExist
+func (s *Store) Open(name string, offset, length int64) (*Reader, error) {
+	o, err := s.openObject(name)
+	if err != nil {
+		return nil, err
+	}
+	if offset < 0 || offset > o.h.size {
+		o.close()
+		return nil, fmt.Errorf("erasure: offset %d out of %d bytes", offset, o.h.size)
+	}
+	if length < 0 || offset+length > o.h.size {
+		length = o.h.size - offset
+	}
+	stripe := int64(o.h.dataShards * o.h.chunkSize)
+	return &Reader{
+		o:      o,
+		bufs:   o.newBuffers(),
+		data:   make([]byte, stripe),
+		stripe: offset / stripe,
+		skip:   offset % stripe,
+		remain: length,
+	}, nil
+}
+
+// Size : 对象的长度
+func (r *Reader) Size() int64 {
+	return r.o.h.size
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.remain == 0 {
+		return 0, io.EOF
+	}
+	for len(r.plain) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.next()
+	}
+	if int64(len(p)) > r.remain {
+		p = p[:r.remain]
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	r.remain -= int64(n)
+	return n, nil
+}
+
+// next : 读取下一个条带的数据, 数据块不全时重建
+func (r *Reader) next() error {
+	o := r.o
+	shards, err := o.readStripe(r.stripe, r.bufs, false)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < o.h.dataShards; i++ {
+		if len(shards[i]) == 0 {
+			if err := o.enc.ReconstructData(shards); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	for i := 0; i < o.h.dataShards; i++ {
+		copy(r.data[i*o.h.chunkSize:], shards[i])
+	}
+
+	// 最后一个条带只有对象末尾的数据
+	end := int64(len(r.data))
+	if rest := o.h.size - r.stripe*end; rest < end {
+		end = rest
+	}
+	r.plain = r.data[r.skip:end]
+	r.skip = 0
+	r.stripe++
+	return nil
+}
+
+func (r *Reader) Close() error {
+	r.o.close()
+	return nil
+}
+
+// Rebuild : 重新写入对象name缺失、不一致或有损坏块的分片(如更换磁盘后), 返回重写的分片数.
+// 分片以写入时的参数重建, 写入shardPath(目录数量未变时即原位置)
+func (s *Store) Rebuild(name string) (int, error) {
+	o, err := s.openObject(name)
+	if err != nil {
+		return 0, err
+	}
+	defer o.close()
+
+	// 1 检查所有块, 确定需要重写的分片
+	bufs := o.newBuffers()
+	stripes := o.h.stripes()
+	for j := int64(0); j < stripes; j++ {
+		if _, err := o.readStripe(j, bufs, true); err != nil {
+			return 0, err
+		}
+	}
+	targets := []int{}
+	for i, fd := range o.files {
+		if fd == nil || o.corrupt[i] {
+			targets = append(targets, i)
+		}
+	}
+	if len(targets) == 0 {
+		return 0, nil
+	}
+
+	// 2 逐条带重建并写入临时文件, 目标分片中完好的块保留
+	writers := map[int]*shardWriter{}
+	defer func() {
+		for _, sw := range writers {
+			sw.abort()
+		}
+	}()
+	for _, i := range targets {
+		sw, err := createShard(s.shardPath(name, i))
+		if err != nil {
+			return 0, err
+		}
+		writers[i] = sw
+		if _, err := sw.w.Write(make([]byte, headerSize)); err != nil {
+			return 0, err
+		}
+	}
+	for j := int64(0); j < stripes; j++ {
+		shards, err := o.readStripe(j, bufs, true)
+		if err != nil {
+			return 0, err
+		}
+		if err := o.enc.Reconstruct(shards); err != nil {
+			return 0, err
+		}
+		for _, i := range targets {
+			if err := writers[i].writeChunk(shards[i]); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	h := o.h
+	for _, i := range targets {
+		h.index = i
+		if err := writers[i].finish(h); err != nil {
+			return 0, err
+		}
+	}
+	for _, i := range targets {
+		sw := writers[i]
+		if err := os.Rename(sw.path+".tmp", sw.path); err != nil {
+			return 0, err
+		}
+		delete(writers, i)
+		if o.paths[i] != sw.path {
+			// 原分片在其他目录中(目录数量变化后), 已在shardPath重建
+			os.Remove(o.paths[i])
+		}
+	}
+	return len(targets), nil
+}
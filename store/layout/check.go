@@ -0,0 +1,72 @@
+package layout
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cloud/config"
+)
+
+// Report : 启动检查的结果
+type Report struct {
+	// Removed : 已删除的写入中断的临时文件
+	Removed []string
+	// Recent : 修改时间在grace以内的临时文件, 可能仍在写入, 暂不删除
+	Recent []string
+	// Flat : 尚未迁移到分级路径的文件数
+	Flat int
+}
+
+// Check : 检查根目录root下写入中断(进程崩溃或断电)残留的临时文件(*.tmp), 删除修改时间早于grace的文件.
+// 文件先写入临时文件并同步后才移到最终路径, 因此最终路径下的文件总是完整的
+func Check(root string, grace time.Duration) (*Report, error) {
+	report := &Report{Removed: []string{}, Recent: []string{}}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if !strings.HasSuffix(path, ".tmp") {
+			if filepath.Dir(path) == filepath.Clean(root) && IsFileHash(info.Name()) {
+				report.Flat++
+			}
+			return nil
+		}
+		if time.Since(info.ModTime()) < grace {
+			report.Recent = append(report.Recent, path)
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		report.Removed = append(report.Removed, path)
+		return nil
+	})
+	if os.IsNotExist(err) {
+		err = nil
+	}
+	return report, err
+}
+
+// CheckLocal : 服务启动时检查本地存储, 结果写入日志
+func CheckLocal() {
+	report, err := Check(config.MergeLocalRootDir, time.Duration(config.LocalTempGracePeriod)*time.Second)
+	if err != nil {
+		log.Printf("Failed to check local store, err:%s\n", err.Error())
+		return
+	}
+	for _, path := range report.Removed {
+		log.Println("Removed half-written file: " + path)
+	}
+	if len(report.Recent) > 0 {
+		log.Printf("%d temp files in local store may still be written, kept\n", len(report.Recent))
+	}
+	if report.Flat > 0 {
+		log.Printf("%d files in local store are not migrated to the sharded layout, run store/layout/migrate\n", report.Flat)
+	}
+}
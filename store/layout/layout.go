@@ -0,0 +1,161 @@
+// Package layout : 本地存储的目录布局. 合并后的文件及分块目录按名称的前4个字符分为两级子目录
+// (如"abcd…"位于"ab/cd/abcd…"), 避免单个目录下有数百万个文件
+package layout
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloud/config"
+)
+
+// isHex : s是否只包含小写hex字符
+func isHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// IsFileHash : 名称是否为文件sha1(40个小写hex字符)
+func IsFileHash(name string) bool {
+	return len(name) == 40 && isHex(name)
+}
+
+// Fanout : name在两级子目录下的相对路径. 文件sha1及uploadID均为hex, 直接以前4个字符分级;
+// 其他名称以其sha1分级
+func Fanout(name string) string {
+	prefix := name
+	if len(prefix) < 4 || !isHex(prefix) {
+		sum := sha1.Sum([]byte(name))
+		prefix = hex.EncodeToString(sum[:])
+	}
+	return prefix[0:2] + "/" + prefix[2:4] + "/" + name
+}
+
+// isFanoutDir : 目录名是否为一级或二级子目录(2个hex字符)
+func isFanoutDir(name string) bool {
+	return len(name) == 2 && isHex(name)
+}
+
+// Path : 根目录root下name的分级路径
+func Path(root, name string) string {
+	return root + Fanout(name)
+}
+
+// MergePath : 文件在本地存储中的路径
+func MergePath(filehash string) string {
+	return Path(config.MergeLocalRootDir, filehash)
+}
+
+// ChunkDir : 分块上传会话的分块目录, 以"/"结尾. 升级前创建的会话沿用原来平铺的目录
+func ChunkDir(uploadID string) string {
+	flat := config.ChunkLocalRootDir + uploadID
+	if info, err := os.Stat(flat); err == nil && info.IsDir() {
+		return flat + "/"
+	}
+	return Path(config.ChunkLocalRootDir, uploadID) + "/"
+}
+
+// Sharded : 根目录root下平铺的路径key对应的分级路径, key不是root下平铺的路径时返回空字符串
+func Sharded(root, key string) string {
+	name := strings.TrimPrefix(key, root)
+	if name == key || name == "" || strings.Contains(name, "/") {
+		return ""
+	}
+	return Path(root, name)
+}
+
+// ChunkDirs : 根目录root下的所有分块目录, 包括尚未迁移的平铺目录
+func ChunkDirs(root string) ([]string, error) {
+	entries, err := readDirs(root)
+	if err != nil {
+		return nil, err
+	}
+	dirs := []string{}
+	for _, top := range entries {
+		if !isFanoutDir(top) {
+			dirs = append(dirs, root+top)
+			continue
+		}
+		subs, err := readDirs(root + top)
+		if err != nil {
+			return nil, err
+		}
+		for _, sub := range subs {
+			if !isFanoutDir(sub) {
+				continue
+			}
+			names, err := readDirs(root + top + "/" + sub)
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range names {
+				dirs = append(dirs, root+top+"/"+sub+"/"+name)
+			}
+		}
+	}
+	return dirs, nil
+}
+
+// readDirs : dir下的子目录名
+func readDirs(dir string) ([]string, error) {
+	fd, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	infos, err := fd.Readdir(-1)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, info := range infos {
+		if info.IsDir() {
+			names = append(names, info.Name())
+		}
+	}
+	return names, nil
+}
+
+// SyncDir : 将目录中的文件创建、重命名及删除同步到磁盘
+func SyncDir(dir string) error {
+	fd, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = fd.Sync()
+	if cerr := fd.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Rename : 将已写完的文件tmp原子地移到path: 先将内容同步到磁盘再重命名, 并同步所在目录.
+// 断电后path要么不存在, 要么是完整的内容
+func Rename(tmp, path string) error {
+	fd, err := os.Open(tmp)
+	if err != nil {
+		return err
+	}
+	err = fd.Sync()
+	if cerr := fd.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0744); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return SyncDir(dir)
+}
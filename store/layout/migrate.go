@@ -0,0 +1,145 @@
+package layout
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Update : 文件filehash已在to创建, 将文件表及副本位置等记录中的位置由from改为to(在同一事务中).
+// 没有记录为from的位置(如已转移到其他存储)时不更新并返回false
+type Update func(filehash, from, to string) (bool, error)
+
+// Result : 迁移的结果
+type Result struct {
+	// Moved : 移到分级路径的文件数
+	Moved int
+	// Updated : 其中更新了文件表或副本位置记录的文件数
+	Updated int
+	// Skipped : 未迁移的文件(名称不是sha1, 或分级路径下已有不同的文件)
+	Skipped []string
+}
+
+// Migrate : 将根目录root下平铺的文件移到分级路径. 每个文件先以硬链接在分级路径创建并同步目录,
+// 再由update更新文件表及副本位置记录, 最后删除原路径; 期间两个路径都可以读取, 中断后可重新执行
+func Migrate(root string, update Update) (*Result, error) {
+	fd, err := os.Open(root)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := fd.Readdir(-1)
+	fd.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{Skipped: []string{}}
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		from := root + name
+		if !IsFileHash(name) {
+			res.Skipped = append(res.Skipped, from)
+			continue
+		}
+		to := Path(root, name)
+		ok, err := link(from, to, info)
+		if err != nil {
+			return res, err
+		}
+		if !ok {
+			log.Printf("layout: %s differs from %s, skipped\n", to, from)
+			res.Skipped = append(res.Skipped, from)
+			continue
+		}
+		updated, err := update(name, from, to)
+		if err != nil {
+			return res, err
+		}
+		if err := os.Remove(from); err != nil {
+			return res, err
+		}
+		res.Moved++
+		if updated {
+			res.Updated++
+		}
+	}
+	if res.Moved > 0 {
+		return res, SyncDir(root)
+	}
+	return res, nil
+}
+
+// link : 在to创建与from相同的文件. to已存在(如上次迁移中断)时长度须一致, 否则返回false
+func link(from, to string, info os.FileInfo) (bool, error) {
+	if existing, err := os.Stat(to); err == nil {
+		return os.SameFile(existing, info) || existing.Size() == info.Size(), nil
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+	dir := filepath.Dir(to)
+	if err := os.MkdirAll(dir, 0744); err != nil {
+		return false, err
+	}
+	if err := os.Link(from, to); err != nil {
+		// 不支持硬链接的文件系统上复制到临时文件后重命名
+		if err := copyFile(from, to+".tmp"); err != nil {
+			os.Remove(to + ".tmp")
+			return false, err
+		}
+		return true, Rename(to+".tmp", to)
+	}
+	return true, SyncDir(dir)
+}
+
+// copyFile : 复制文件内容
+func copyFile(from, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// MigrateChunks : 将根目录root下平铺的分块目录移到分级路径, 返回移动的目录数.
+// 移动期间写入的分块会丢失, 须在上传服务停止时执行
+func MigrateChunks(root string) (int, error) {
+	names, err := readDirs(root)
+	if err != nil {
+		return 0, err
+	}
+	moved := 0
+	for _, name := range names {
+		if isFanoutDir(name) {
+			continue
+		}
+		to := Path(root, name)
+		if err := os.MkdirAll(filepath.Dir(to), 0744); err != nil {
+			return moved, err
+		}
+		if err := os.Rename(root+name, to); err != nil {
+			return moved, err
+		}
+		if err := SyncDir(filepath.Dir(to)); err != nil {
+			return moved, err
+		}
+		moved++
+	}
+	if moved > 0 {
+		return moved, SyncDir(root)
+	}
+	return moved, nil
+}
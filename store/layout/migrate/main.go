@@ -0,0 +1,47 @@
+// migrate : 将本地存储中平铺的文件移到按sha1分级的子目录下, 并更新文件表、副本位置表及完整性检查表中记录的位置.
+// 在保存config.MergeLocalRootDir的节点上运行, 可在服务运行时执行, 中断后可重新执行:
+//
+//	go run ./store/layout/migrate          # 迁移合并后的文件
+//	go run ./store/layout/migrate -chunks  # 同时迁移分块目录(须先停止上传服务)
+//
+// 没有记录指向原路径(如已转移到Ceph/OSS)的文件也会移动, 只是不更新记录. 存储层级表只以sha1记录文件, 无需更新
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/cloud/config"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/store/layout"
+)
+
+func main() {
+	chunks := flag.Bool("chunks", false, "同时迁移分块目录, 移动期间写入的分块会丢失, 须先停止上传服务")
+	flag.Parse()
+
+	res, err := layout.Migrate(config.MergeLocalRootDir, dbcli.ReplaceFileLocation)
+	if res != nil {
+		for _, path := range res.Skipped {
+			log.Println("skipped " + path)
+		}
+		fmt.Printf("moved %d files, updated records of %d, skipped %d\n",
+			res.Moved, res.Updated, len(res.Skipped))
+	}
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	if *chunks {
+		moved, err := layout.MigrateChunks(config.ChunkLocalRootDir)
+		fmt.Printf("moved %d chunk dirs\n", moved)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+	if res != nil && len(res.Skipped) > 0 {
+		os.Exit(1)
+	}
+}
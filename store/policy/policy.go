@@ -0,0 +1,103 @@
+// Package policy : 按存储策略决定文件上传后转移到哪些存储
+package policy
+
+import (
+	"log"
+	"strings"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	"github.com/cloud/job"
+	"github.com/cloud/store/layout"
+)
+
+// File : 选择存储策略及生成转移任务所需的文件信息
+type File struct {
+	UserName string
+	// FileName : 用户文件名, 目录以"/"分隔
+	FileName string
+	FileSize int64
+	FileHash string
+	// Location : 文件当前(本地)的存储位置
+	Location string
+}
+
+// Enqueuer : 提交任务, 如job.Client.Enqueue
+type Enqueuer func(jobType string, payload interface{}, opts job.EnqueueOptions) (*job.Job, error)
+
+// match : 规则是否匹配文件
+func match(r config.StorageRule, f File) bool {
+	if r.UserName != "" && r.UserName != f.UserName {
+		return false
+	}
+	if r.Folder != "" && !strings.HasPrefix(strings.TrimPrefix(f.FileName, "/"), strings.TrimPrefix(r.Folder, "/")) {
+		return false
+	}
+	if f.FileSize < r.MinSize || (r.MaxSize > 0 && f.FileSize >= r.MaxSize) {
+		return false
+	}
+	return true
+}
+
+// Resolve : 文件适用的存储策略名, 按顺序第一个匹配的规则生效, 都不匹配时使用默认策略
+func Resolve(f File) string {
+	for _, r := range config.StorageRules {
+		if match(r, f) {
+			return r.Policy
+		}
+	}
+	return config.DefaultStoragePolicy
+}
+
+// Targets : 策略的目标存储, 第一个为主存储. 策略未定义时使用默认策略
+func Targets(policy string) []common.StoreType {
+	targets, ok := config.StoragePolicies[policy]
+	if !ok {
+		log.Printf("undefined storage policy %q, using default\n", policy)
+		targets = config.StoragePolicies[config.DefaultStoragePolicy]
+	}
+	return targets
+}
+
+// Location : 文件在存储中的位置
+func Location(t common.StoreType, filehash string) string {
+	switch t {
+	case common.StoreLocal:
+		return layout.MergePath(filehash)
+	case common.StoreCeph:
+		return config.CephRootDir + filehash
+	case common.StoreOSS:
+		return config.OSSRootDir + filehash
+	case common.StoreErasure:
+		return config.ErasureRootDir + filehash
+	}
+	return ""
+}
+
+// Transfers : 按文件的存储策略生成转移任务的参数, 第一个为主存储
+func Transfers(f File) []job.TransferPayload {
+	transfers := []job.TransferPayload{}
+	for i, t := range Targets(Resolve(f)) {
+		transfers = append(transfers, job.TransferPayload{
+			FileHash:      f.FileHash,
+			Location:      f.Location,
+			DestLocation:  Location(t, f.FileHash),
+			DestStoreType: t,
+			Replica:       i > 0,
+		})
+	}
+	return transfers
+}
+
+// Enqueue : 按存储策略提交文件的转移任务, 每个目标存储一个任务, 同一文件到同一存储只转移一次.
+// 返回第一个提交失败的错误, 其余任务仍会提交
+func Enqueue(enqueue Enqueuer, f File) error {
+	var firstErr error
+	for _, t := range Transfers(f) {
+		_, err := enqueue(job.TypeTransfer, t, job.EnqueueOptions{IdempotencyKey: t.IdempotencyKey()})
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,135 @@
+// Package dbreplica : 通过dbproxy读写副本记录, 通过store/backend读取各存储中的数据
+package dbreplica
+
+import (
+	"errors"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	"github.com/cloud/job"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/service/dbproxy/orm"
+	"github.com/cloud/store/backend"
+	"github.com/cloud/store/replica"
+)
+
+// jobs : 提交修复任务使用的Client, 由Setup设置
+var jobs *job.Client
+
+// Setup : 设置提交修复任务使用的Client, 须在读取文件前调用
+func Setup(jobClient *job.Client) {
+	jobs = jobClient
+}
+
+// reader : 默认的副本读取, 读取失败时提交修复任务
+var reader = &replica.Reader{
+	Store:        Store{},
+	OpenLocation: backend.Open,
+	TypeOf:       backend.TypeOf,
+	Repair:       Repair,
+}
+
+// Open : 打开文件从offset开始长度为length的数据, 见replica.Reader.Open
+func Open(filehash string, offset, length int64) (io.ReadCloser, error) {
+	return reader.Open(filehash, offset, length)
+}
+
+// NewFile : 可Seek的文件读取, 见replica.File
+func NewFile(filehash string, size int64) *replica.File {
+	return reader.NewFile(filehash, size)
+}
+
+// Candidates : 文件可读取的位置, 见replica.Reader.Candidates
+func Candidates(filehash string) ([]replica.Replica, error) {
+	return reader.Candidates(filehash)
+}
+
+// Repair : 提交文件的修复任务, 同一文件在config.RepairWindow内只提交一次
+func Repair(filehash string) {
+	key := filehash + ":" + strconv.FormatInt(time.Now().Unix()/config.RepairWindow, 10)
+	if jobs == nil {
+		log.Println("job client not set, skip repair, sha1: " + filehash)
+		return
+	}
+	_, err := jobs.Enqueue(job.TypeRepair, job.RepairPayload{FileHash: filehash},
+		job.EnqueueOptions{IdempotencyKey: key})
+	if err != nil {
+		log.Println("enqueue repair job failed, sha1: " + filehash + ", " + err.Error())
+	}
+}
+
+// Store : 通过dbproxy读写文件表及副本表
+type Store struct{}
+
+// execResult : 将dbproxy的执行结果转换为错误
+func execResult(res *orm.ExecResult, err error) (*orm.ExecResult, error) {
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, errors.New("dbproxy: empty result")
+	}
+	if !res.Suc {
+		return nil, errors.New("dbproxy: " + res.Msg)
+	}
+	return res, nil
+}
+
+// File : 实现replica.Store
+func (Store) File(filehash string) (string, int64, error) {
+	res, err := execResult(dbcli.GetFileMeta(filehash))
+	if err != nil {
+		return "", 0, err
+	}
+	if res.Data == nil {
+		return "", 0, errors.New("file meta not found: " + filehash)
+	}
+	tblFile := dbcli.ToTableFile(res.Data)
+	return tblFile.FileAddr.String, tblFile.FileSize.Int64, nil
+}
+
+// Locations : 实现replica.Store
+func (Store) Locations(filehash string) ([]replica.Replica, error) {
+	res, err := execResult(dbcli.ListFileLocations(filehash))
+	if err != nil {
+		return nil, err
+	}
+	replicas := []replica.Replica{}
+	for _, loc := range dbcli.ToTableFileLocations(res.Data) {
+		replicas = append(replicas, replica.Replica{
+			StoreType:  common.StoreType(loc.StoreType),
+			Location:   loc.Location,
+			Status:     common.LocationStatus(loc.Status),
+			VerifiedAt: loc.VerifiedAt,
+		})
+	}
+	return replicas, nil
+}
+
+// AddLocation : 实现replica.Store
+func (Store) AddLocation(filehash string, t common.StoreType, location string) error {
+	_, err := execResult(dbcli.AddFileLocation(filehash, t, location))
+	return err
+}
+
+// SetStatus : 实现replica.Store
+func (Store) SetStatus(filehash string, t common.StoreType, status common.LocationStatus) error {
+	_, err := execResult(dbcli.SetFileLocationStatus(filehash, t, status))
+	return err
+}
+
+// RemoveLocation : 实现replica.Store
+func (Store) RemoveLocation(filehash string, t common.StoreType) error {
+	_, err := execResult(dbcli.RemoveFileLocation(filehash, t))
+	return err
+}
+
+// UpdateLocation : 实现replica.Store
+func (Store) UpdateLocation(filehash, location string) error {
+	_, err := execResult(dbcli.UpdateFileLocation(filehash, location))
+	return err
+}
@@ -0,0 +1,70 @@
+package replica
+
+import (
+	"io"
+	"os"
+)
+
+// File : 可Seek的文件读取, 适合顺序读取及HTTP Range请求(http.ServeContent).
+// Read时从当前位置打开副本, Seek到其他位置后重新打开
+type File struct {
+	reader   *Reader
+	filehash string
+	size     int64
+
+	offset int64
+	stream io.ReadCloser
+}
+
+// NewFile : 读取大小为size的文件
+func (r *Reader) NewFile(filehash string, size int64) *File {
+	return &File{reader: r, filehash: filehash, size: size}
+}
+
+// Size : 文件大小
+func (f *File) Size() int64 { return f.size }
+
+func (f *File) Read(p []byte) (int, error) {
+	if f.offset >= f.size {
+		return 0, io.EOF
+	}
+	if f.stream == nil {
+		stream, err := f.reader.Open(f.filehash, f.offset, f.size-f.offset)
+		if err != nil {
+			return 0, err
+		}
+		f.stream = stream
+	}
+	n, err := f.stream.Read(p)
+	f.offset += int64(n)
+	if err == io.EOF && f.offset < f.size {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.size
+	}
+	if offset < 0 {
+		return 0, os.ErrInvalid
+	}
+	if offset != f.offset && f.stream != nil {
+		f.stream.Close()
+		f.stream = nil
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *File) Close() error {
+	if f.stream != nil {
+		f.stream.Close()
+		f.stream = nil
+	}
+	return nil
+}
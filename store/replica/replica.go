@@ -0,0 +1,161 @@
+// Package replica : 文件在多个存储中的副本, 按读取偏好选择可用的副本, 读取失败时切换到其他副本
+package replica
+
+import (
+	"errors"
+	"io"
+	"log"
+	"sort"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+)
+
+// ErrNoReplica : 文件没有可读取的位置
+var ErrNoReplica = errors.New("replica: no readable location")
+
+// Replica : 文件的一个存储位置
+type Replica struct {
+	StoreType common.StoreType
+	Location  string
+	Status    common.LocationStatus
+	// VerifiedAt : 最后确认可读取的时间, 格式为"2006-01-02 15:04:05"
+	VerifiedAt string
+	// Recorded : 是否为副本表中记录的位置, 否则为文件表中的位置(本地暂存或没有副本记录的旧文件)
+	Recorded bool
+}
+
+// Store : 文件表及副本表的读写, 由dbreplica通过dbproxy实现, 测试时可替换为内存实现
+type Store interface {
+	// File : 文件表中记录的存储位置及文件大小
+	File(filehash string) (location string, size int64, err error)
+	// Locations : 副本表中文件的全部副本
+	Locations(filehash string) ([]Replica, error)
+	// AddLocation : 记录已写入且可用的副本
+	AddLocation(filehash string, t common.StoreType, location string) error
+	// SetStatus : 更新副本状态, 状态为可用时同时更新确认时间
+	SetStatus(filehash string, t common.StoreType, status common.LocationStatus) error
+	// RemoveLocation : 删除副本记录(副本数据已删除)
+	RemoveLocation(filehash string, t common.StoreType) error
+	// UpdateLocation : 更新文件表中的存储位置
+	UpdateLocation(filehash, location string) error
+}
+
+// Opener : 打开存储类型t中的key, 返回从offset开始长度为length的数据, 如backend.Open
+type Opener func(t common.StoreType, key string, offset, length int64) (io.ReadCloser, error)
+
+// rank : 存储类型在config.ReadPreference中的位置, 未列出的排在最后
+func rank(t common.StoreType) int {
+	for i, p := range config.ReadPreference {
+		if p == t {
+			return i
+		}
+	}
+	return len(config.ReadPreference)
+}
+
+// Sort : 可用的副本在前, 其次按读取偏好, 同一偏好按确认时间从新到旧
+func Sort(replicas []Replica) {
+	sort.SliceStable(replicas, func(i, j int) bool {
+		a, b := replicas[i], replicas[j]
+		if (a.Status == common.LocationAvailable) != (b.Status == common.LocationAvailable) {
+			return a.Status == common.LocationAvailable
+		}
+		if rank(a.StoreType) != rank(b.StoreType) {
+			return rank(a.StoreType) < rank(b.StoreType)
+		}
+		return a.VerifiedAt > b.VerifiedAt
+	})
+}
+
+// Reader : 按读取偏好打开文件, 副本读取失败时依次尝试其余副本
+type Reader struct {
+	Store Store
+	// OpenLocation : 打开存储中的数据
+	OpenLocation Opener
+	// TypeOf : 按文件表中的位置判断存储类型
+	TypeOf func(location string) common.StoreType
+	// Repair : 有副本读取失败时调用(如提交修复任务), 可为空
+	Repair func(filehash string)
+}
+
+// Candidates : 文件可读取的位置, 包括副本表中的副本及文件表中的位置, 按Sort排序.
+// 缺失的副本排在最后, 只在其余位置都读取失败时尝试; 已隔离的损坏副本不读取
+func (r *Reader) Candidates(filehash string) ([]Replica, error) {
+	replicas, err := r.Store.Locations(filehash)
+	if err != nil {
+		return nil, err
+	}
+	for i := range replicas {
+		replicas[i].Recorded = true
+	}
+
+	location, _, err := r.Store.File(filehash)
+	if err != nil {
+		return nil, err
+	}
+	if location != "" {
+		recorded := false
+		for _, rep := range replicas {
+			if rep.Location == location {
+				recorded = true
+				break
+			}
+		}
+		if !recorded {
+			replicas = append(replicas, Replica{
+				StoreType: r.TypeOf(location),
+				Location:  location,
+				Status:    common.LocationAvailable,
+			})
+		}
+	}
+
+	readable := replicas[:0]
+	for _, rep := range replicas {
+		if rep.Status != common.LocationCorrupt {
+			readable = append(readable, rep)
+		}
+	}
+	Sort(readable)
+	return readable, nil
+}
+
+// Open : 打开文件从offset开始长度为length的数据. 按Candidates的顺序尝试,
+// 打开失败的已记录副本标记为缺失, 并调用Repair修复
+func (r *Reader) Open(filehash string, offset, length int64) (io.ReadCloser, error) {
+	candidates, err := r.Candidates(filehash)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := false
+	lastErr := ErrNoReplica
+	for _, c := range candidates {
+		rc, err := r.OpenLocation(c.StoreType, c.Location, offset, length)
+		if err == nil {
+			if failed {
+				r.repair(filehash)
+			}
+			return rc, nil
+		}
+		log.Printf("open replica %s failed, err:%s\n", c.Location, err.Error())
+		lastErr = err
+		if c.Recorded && c.Status == common.LocationAvailable {
+			if err := r.Store.SetStatus(filehash, c.StoreType, common.LocationMissing); err != nil {
+				log.Println(err.Error())
+			}
+			failed = true
+		}
+	}
+	if failed {
+		r.repair(filehash)
+	}
+	return nil, lastErr
+}
+
+func (r *Reader) repair(filehash string) {
+	if r.Repair != nil {
+		r.Repair(filehash)
+	}
+}
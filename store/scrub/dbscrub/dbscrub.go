@@ -0,0 +1,77 @@
+// Package dbscrub : 通过dbproxy记录及查询完整性检查的结果
+package dbscrub
+
+import (
+	"errors"
+	"log"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/service/dbproxy/orm"
+	"github.com/cloud/store/replica"
+	"github.com/cloud/store/scrub"
+)
+
+// execResult : 将dbproxy的执行结果转换为错误
+func execResult(res *orm.ExecResult, err error) (*orm.ExecResult, error) {
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, errors.New("dbproxy: empty result")
+	}
+	if !res.Suc {
+		return nil, errors.New("dbproxy: " + res.Msg)
+	}
+	return res, nil
+}
+
+// Record : 记录位置的检查结果
+func Record(filehash string, rep replica.Replica, result common.ScrubResult, checksum, crc string) error {
+	_, err := execResult(dbcli.RecordFileScrub(filehash, rep.StoreType, rep.Location, result, checksum, crc))
+	return err
+}
+
+// LastCRC64 : 各存储中的位置最近一次检查一致时记录的CRC64
+func LastCRC64(filehash string) (map[common.StoreType]string, error) {
+	res, err := execResult(dbcli.ListFileScrubs(filehash))
+	if err != nil {
+		return nil, err
+	}
+	crcs := map[common.StoreType]string{}
+	for _, s := range dbcli.ToTableFileScrubs(res.Data) {
+		if common.ScrubResult(s.Result) == common.ScrubOK && s.CRC64 != "" {
+			crcs[common.StoreType(s.StoreType)] = s.CRC64
+		}
+	}
+	return crcs, nil
+}
+
+// ListCandidates : 实现scrub.Lister
+func ListCandidates(before int64, limit int) ([]string, error) {
+	res, err := execResult(dbcli.ListScrubCandidates(before, limit))
+	if err != nil {
+		return nil, err
+	}
+	items, _ := res.Data.([]interface{})
+	hashes := []string{}
+	for _, h := range items {
+		if s, ok := h.(string); ok {
+			hashes = append(hashes, s)
+		}
+	}
+	return hashes, nil
+}
+
+// Alert : 记录告警日志, 配置了config.ScrubAlertURL时同时POST告警
+func Alert(alert scrub.Alert) {
+	log.Printf("[ALERT] scrub %s (store type %d, sha1 %s): %s\n",
+		alert.Location, alert.StoreType, alert.FileHash, alert.Message)
+	if config.ScrubAlertURL == "" {
+		return
+	}
+	if err := scrub.PostAlert(config.ScrubAlertURL, alert); err != nil {
+		log.Println(err.Error())
+	}
+}
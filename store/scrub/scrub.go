@@ -0,0 +1,134 @@
+// Package scrub : 存储内容的完整性检查. 任务服务定期为长期未检查的文件提交检查任务,
+// 检查任务重新计算各位置内容的sha1, 读取速率由Limiter限制
+package scrub
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/crc64"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cloud/config"
+	"github.com/cloud/job"
+	"github.com/cloud/store/policy"
+)
+
+// crcTable : 与OSS的x-oss-hash-crc64ecma相同的CRC64(ECMA)
+var crcTable = crc64.MakeTable(crc64.ECMA)
+
+// Digest : 读取r的全部内容, 返回sha1(十六进制)及CRC64(十进制, 与OSS记录的格式相同)
+func Digest(r io.Reader) (string, string, error) {
+	h := sha1.New()
+	c := crc64.New(crcTable)
+	if _, err := io.Copy(io.MultiWriter(h, c), r); err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), strconv.FormatUint(c.Sum64(), 10), nil
+}
+
+// Limiter : 按字节数限制读取速率, 多个检查任务共用时合计不超过该速率. nil或速率为0时不限制
+type Limiter struct {
+	mu   sync.Mutex
+	rate int64
+	next time.Time
+}
+
+// NewLimiter : 每秒最多读取bytesPerSecond字节
+func NewLimiter(bytesPerSecond int64) *Limiter {
+	return &Limiter{rate: bytesPerSecond}
+}
+
+// Wait : 已读取n字节, 按速率等待到可以继续读取
+func (l *Limiter) Wait(n int) {
+	if l == nil || l.rate <= 0 || n <= 0 {
+		return
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	l.next = l.next.Add(time.Duration(int64(n) * int64(time.Second) / l.rate))
+	wait := l.next.Sub(now)
+	l.mu.Unlock()
+	time.Sleep(wait)
+}
+
+// Reader : 按速率读取r
+func (l *Limiter) Reader(r io.Reader) io.Reader {
+	return &limitedReader{r: r, l: l}
+}
+
+type limitedReader struct {
+	r io.Reader
+	l *Limiter
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.l.Wait(n)
+	return n, err
+}
+
+// JobKey : 检查任务的去重键, 同一文件在config.ScrubInterval内只提交一次
+func JobKey(filehash string, now time.Time) string {
+	return filehash + ":" + strconv.FormatInt(now.Unix()/config.ScrubInterval, 10)
+}
+
+// Lister : 查询从未检查过或最早的检查时间(unix秒)早于before的文件hash
+type Lister func(before int64, limit int) ([]string, error)
+
+// Scan : 为超过config.ScrubAfterDays未检查的文件提交检查任务, 最多config.ScrubBatch个,
+// 返回提交的任务数
+func Scan(list Lister, enqueue policy.Enqueuer, now time.Time) (int, error) {
+	hashes, err := list(now.Unix()-int64(config.ScrubAfterDays)*86400, config.ScrubBatch)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, filehash := range hashes {
+		_, err := enqueue(job.TypeScrub, job.ScrubPayload{FileHash: filehash},
+			job.EnqueueOptions{IdempotencyKey: JobKey(filehash, now)})
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Alert : 发现副本损坏时的告警内容
+type Alert struct {
+	FileHash  string `json:"file_sha1"`
+	StoreType int    `json:"store_type"`
+	Location  string `json:"location"`
+	// Checksum : 读取到的内容的sha1, 只比较CRC64时为空
+	Checksum string `json:"checksum,omitempty"`
+	Message  string `json:"message"`
+	Time     int64  `json:"time"`
+}
+
+// PostAlert : 将告警以JSON POST到url
+func PostAlert(url string, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("scrub alert: %s responded %s", url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// Package dbtier : 通过dbproxy记录文件访问及查询层级, 通过任务队列提交层级任务
+package dbtier
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/cloud/common"
+	"github.com/cloud/job"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/service/dbproxy/orm"
+	"github.com/cloud/store/backend"
+	"github.com/cloud/store/tier"
+)
+
+// jobs : 提交层级任务使用的Client, 由Setup设置
+var jobs *job.Client
+
+// Setup : 设置提交层级任务使用的Client, 须在读取文件前调用
+func Setup(jobClient *job.Client) {
+	jobs = jobClient
+}
+
+// tracker : 默认的访问记录
+var tracker = &tier.Tracker{
+	Record:  record,
+	Restore: restore,
+	Promote: Promote,
+}
+
+// Access : 读取文件前调用, 见tier.Tracker.Access
+func Access(filehash string) error {
+	return tracker.Access(filehash)
+}
+
+// Promote : 提交将文件升级到热存储的任务
+func Promote(filehash string) {
+	if jobs == nil {
+		log.Println("job client not set, skip promote, sha1: " + filehash)
+		return
+	}
+	_, err := jobs.Enqueue(job.TypeTier, job.TierPayload{FileHash: filehash, Tier: common.TierHot},
+		job.EnqueueOptions{IdempotencyKey: tier.JobKey(filehash, common.TierHot, time.Now())})
+	if err != nil {
+		log.Println("enqueue tier job failed, sha1: " + filehash + ", " + err.Error())
+	}
+}
+
+// execResult : 将dbproxy的执行结果转换为错误
+func execResult(res *orm.ExecResult, err error) (*orm.ExecResult, error) {
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, errors.New("dbproxy: empty result")
+	}
+	if !res.Suc {
+		return nil, errors.New("dbproxy: " + res.Msg)
+	}
+	return res, nil
+}
+
+// record : 记录访问, 文件表中没有该文件时按热存储处理
+func record(filehash string) (common.StorageTier, error) {
+	res, err := execResult(dbcli.RecordFileAccess(filehash))
+	if err != nil {
+		return 0, err
+	}
+	if res.Data == nil {
+		return common.TierHot, nil
+	}
+	return common.StorageTier(dbcli.ToTableFileTier(res.Data).Tier), nil
+}
+
+// restore : 归档文件在文件表中的位置为OSS上的副本
+func restore(filehash string) (bool, error) {
+	res, err := execResult(dbcli.GetFileMeta(filehash))
+	if err != nil {
+		return false, err
+	}
+	location := dbcli.ToTableFile(res.Data).FileAddr.String
+	if backend.TypeOf(location) != common.StoreOSS {
+		return true, nil
+	}
+	return backend.Restore(location)
+}
+
+// GetTier : 文件当前的层级及最后访问时间(unix秒)
+func GetTier(filehash string) (common.StorageTier, int64, error) {
+	res, err := execResult(dbcli.GetFileTier(filehash))
+	if err != nil {
+		return 0, 0, err
+	}
+	if res.Data == nil {
+		return 0, 0, errors.New("file meta not found: " + filehash)
+	}
+	t := dbcli.ToTableFileTier(res.Data)
+	return common.StorageTier(t.Tier), t.LastAccessAt, nil
+}
+
+// SetTier : 更新文件的层级
+func SetTier(filehash string, t common.StorageTier) error {
+	_, err := execResult(dbcli.SetFileTier(filehash, t))
+	return err
+}
+
+// ListCandidates : 实现tier.Lister
+func ListCandidates(t common.StorageTier, before int64, limit int) ([]string, error) {
+	res, err := execResult(dbcli.ListTierCandidates(t, before, limit))
+	if err != nil {
+		return nil, err
+	}
+	items, _ := res.Data.([]interface{})
+	hashes := []string{}
+	for _, h := range items {
+		if s, ok := h.(string); ok {
+			hashes = append(hashes, s)
+		}
+	}
+	return hashes, nil
+}
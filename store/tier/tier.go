@@ -0,0 +1,121 @@
+// Package tier : 文件的冷热分层. 读取前记录访问, 归档的文件先解冻,
+// 被访问的低频/归档文件升级回热存储, 长期未访问的文件由任务服务定期降级
+package tier
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	"github.com/cloud/job"
+	"github.com/cloud/store/policy"
+)
+
+// ErrRestoring : 文件已归档, 正在解冻, 解冻完成后才能读取
+var ErrRestoring = errors.New("tier: file is being restored from archive")
+
+// Name : 层级的名称
+func Name(t common.StorageTier) string {
+	switch t {
+	case common.TierHot:
+		return "hot"
+	case common.TierCold:
+		return "cold"
+	case common.TierArchive:
+		return "archive"
+	}
+	return "unknown"
+}
+
+// IdleDays : 降级到层级t需要的未访问天数, 0表示不降级到该层级
+func IdleDays(t common.StorageTier) int {
+	switch t {
+	case common.TierCold:
+		return config.TierColdAfterDays
+	case common.TierArchive:
+		return config.TierArchiveAfterDays
+	}
+	return 0
+}
+
+// Due : 最后访问时间为lastAccess(unix秒)的文件在now时是否应降级到层级t
+func Due(t common.StorageTier, lastAccess int64, now time.Time) bool {
+	days := IdleDays(t)
+	return days > 0 && lastAccess < now.Unix()-int64(days)*86400
+}
+
+// JobKey : 层级任务的去重键, 同一文件到同一层级在config.TierScanInterval内只提交一次
+func JobKey(filehash string, t common.StorageTier, now time.Time) string {
+	return filehash + ":" + strconv.Itoa(int(t)) + ":" +
+		strconv.FormatInt(now.Unix()/config.TierScanInterval, 10)
+}
+
+// Tracker : 读取文件前记录访问, 由dbtier通过dbproxy实现, 测试时可替换为内存实现
+type Tracker struct {
+	// Record : 记录一次访问, 返回文件当前的层级
+	Record func(filehash string) (common.StorageTier, error)
+	// Restore : 返回归档的文件是否已可读取, 未解冻时发起解冻
+	Restore func(filehash string) (bool, error)
+	// Promote : 提交升级到热存储的任务
+	Promote func(filehash string)
+}
+
+// Access : 读取文件前调用. 低频访问的文件提交升级任务后直接读取;
+// 归档的文件解冻完成前返回ErrRestoring, 解冻后同样提交升级任务. 访问记录失败不影响读取
+func (t *Tracker) Access(filehash string) error {
+	current, err := t.Record(filehash)
+	if err != nil {
+		log.Println("record file access failed, sha1: " + filehash + ", " + err.Error())
+		return nil
+	}
+
+	switch current {
+	case common.TierCold:
+		t.Promote(filehash)
+	case common.TierArchive:
+		ready, err := t.Restore(filehash)
+		if err != nil {
+			return err
+		}
+		if !ready {
+			return ErrRestoring
+		}
+		t.Promote(filehash)
+	}
+	return nil
+}
+
+// Lister : 查询层级为t且最后访问时间(unix秒)早于before的文件hash
+type Lister func(t common.StorageTier, before int64, limit int) ([]string, error)
+
+// Scan : 为超过未访问天数的文件提交降级任务(热存储到低频访问, 低频访问到归档),
+// 每个层级最多config.TierScanBatch个, 返回提交的任务数
+func Scan(list Lister, enqueue policy.Enqueuer, now time.Time) (int, error) {
+	steps := []struct{ from, to common.StorageTier }{
+		{common.TierHot, common.TierCold},
+		{common.TierCold, common.TierArchive},
+	}
+	count := 0
+	for _, step := range steps {
+		days := IdleDays(step.to)
+		if days <= 0 {
+			continue
+		}
+		hashes, err := list(step.from, now.Unix()-int64(days)*86400, config.TierScanBatch)
+		if err != nil {
+			return count, err
+		}
+		for _, filehash := range hashes {
+			_, err := enqueue(job.TypeTier, job.TierPayload{FileHash: filehash, Tier: step.to},
+				job.EnqueueOptions{IdempotencyKey: JobKey(filehash, step.to, now)})
+			if err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,321 @@
+package userfs
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/cloud/common"
+	cmnCfg "github.com/cloud/config"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/store/backend"
+	"github.com/cloud/store/replica"
+	"github.com/cloud/store/replica/dbreplica"
+	"github.com/cloud/store/tier/dbtier"
+	"github.com/cloud/util"
+)
+
+var errWriterClosed = errors.New("writer already committed or aborted")
+
+// Reader : 以只读方式打开的文件.
+// Read/Seek按当前位置以range方式读取底层存储, 适合顺序读取及HTTP Range请求;
+// ReadAt用于SFTP等乱序并发读取, 本地文件直接读取, Ceph/OSS上的文件先缓存到本地临时文件.
+// 副本读取失败时切换到其他副本; 首次读取时记录访问, 归档的文件解冻完成前读取返回tier.ErrRestoring
+type Reader struct {
+	info *FileInfo
+	file *replica.File
+
+	accessOnce sync.Once
+	accessErr  error
+
+	mu      sync.Mutex
+	fd      *os.File
+	fdErr   error
+	fdCache bool
+}
+
+// Info : 文件信息
+func (r *Reader) Info() *FileInfo { return r.info }
+
+func (r *Reader) Read(p []byte) (int, error) {
+	if err := r.access(); err != nil {
+		return 0, err
+	}
+	return r.file.Read(p)
+}
+
+func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	return r.file.Seek(offset, whence)
+}
+
+func (r *Reader) ReadAt(p []byte, off int64) (int, error) {
+	if err := r.access(); err != nil {
+		return 0, err
+	}
+	fd, err := r.randomAccessFile()
+	if err != nil {
+		return 0, err
+	}
+	return fd.ReadAt(p, off)
+}
+
+// access : 首次读取时记录文件访问, 空文件不记录
+func (r *Reader) access() error {
+	if r.info.size == 0 {
+		return nil
+	}
+	r.accessOnce.Do(func() {
+		r.accessErr = dbtier.Access(r.info.filehash)
+	})
+	return r.accessErr
+}
+
+// randomAccessFile : 返回可随机读取的本地文件, 只在首次调用时打开或下载
+func (r *Reader) randomAccessFile() (*os.File, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fd != nil || r.fdErr != nil {
+		return r.fd, r.fdErr
+	}
+
+	candidates, err := dbreplica.Candidates(r.info.filehash)
+	if err != nil {
+		r.fdErr = err
+		return nil, err
+	}
+	for _, c := range candidates {
+		if c.StoreType != common.StoreLocal || c.Status != common.LocationAvailable {
+			continue
+		}
+		// 已加密或压缩的本地文件不能直接读取, 解密、解压到临时文件
+		if raw, err := backend.Raw(c.StoreType, c.Location); err != nil || !raw {
+			continue
+		}
+		if r.fd, r.fdErr = os.Open(c.Location); r.fdErr == nil {
+			return r.fd, nil
+		}
+	}
+
+	r.fd, r.fdErr = r.cacheFile()
+	r.fdCache = r.fdErr == nil
+	return r.fd, r.fdErr
+}
+
+// cacheFile : 将Ceph/OSS上的文件下载到本地临时文件
+func (r *Reader) cacheFile() (*os.File, error) {
+	stream, err := dbreplica.Open(r.info.filehash, 0, r.info.size)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	name, err := util.RandomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	fd, err := os.Create(cmnCfg.TempLocalRootDir + name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(fd, stream); err != nil {
+		fd.Close()
+		os.Remove(fd.Name())
+		return nil, err
+	}
+	return fd, nil
+}
+
+func (r *Reader) Close() error {
+	r.file.Close()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fd != nil {
+		r.fd.Close()
+		if r.fdCache {
+			os.Remove(r.fd.Name())
+		}
+		r.fd = nil
+	}
+	return nil
+}
+
+// Writer : 以写方式打开的文件. 数据写入本地临时文件, 同时按顺序计算sha1;
+// 乱序到达的数据(如SFTP的并发写请求)在前面的空缺补齐后再从临时文件读回计算.
+// Commit时经过与普通上传相同的去重及转移流程写入文件表及用户文件表
+type Writer struct {
+	fs   *FS
+	name string
+	info *FileInfo
+
+	mu      sync.Mutex
+	tmp     *os.File
+	hash    hash.Hash
+	hashed  int64
+	pending map[int64]int64
+	source  *FileInfo
+	closed  bool
+}
+
+func newWriter(fs *FS, fn string) (*Writer, error) {
+	name, err := util.RandomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.Create(cmnCfg.TempLocalRootDir + name)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{
+		fs:      fs,
+		name:    fn,
+		info:    &FileInfo{name: path.Base(fn), modTime: time.Now()},
+		tmp:     tmp,
+		hash:    sha1.New(),
+		pending: map[int64]int64{},
+	}, nil
+}
+
+// Info : 文件信息, Commit之后包含文件的sha1
+func (w *Writer) Info() *FileInfo { return w.info }
+
+// Size : 已写入数据的长度
+func (w *Writer) Size() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.info.size
+}
+
+// Write : 追加写入
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writeAt(p, w.info.size)
+}
+
+func (w *Writer) WriteAt(p []byte, off int64) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writeAt(p, off)
+}
+
+func (w *Writer) writeAt(p []byte, off int64) (int, error) {
+	if w.closed {
+		return 0, errWriterClosed
+	}
+	if w.source != nil {
+		return 0, errors.New("write after link")
+	}
+	n, err := w.tmp.WriteAt(p, off)
+	end := off + int64(n)
+	if end > w.info.size {
+		w.info.size = end
+	}
+
+	if off <= w.hashed && end > w.hashed {
+		w.hash.Write(p[w.hashed-off : n])
+		w.hashed = end
+		if hErr := w.drainPending(); hErr != nil && err == nil {
+			err = hErr
+		}
+	} else if off > w.hashed && end > w.pending[off] {
+		w.pending[off] = end
+	}
+	return n, err
+}
+
+// drainPending : 将已与顺序部分相连的乱序数据从临时文件读回并计算hash
+func (w *Writer) drainPending() error {
+	for {
+		progressed := false
+		for start, end := range w.pending {
+			if start > w.hashed {
+				continue
+			}
+			delete(w.pending, start)
+			if end <= w.hashed {
+				continue
+			}
+			section := io.NewSectionReader(w.tmp, w.hashed, end-w.hashed)
+			if _, err := io.Copy(w.hash, section); err != nil {
+				return err
+			}
+			w.hashed = end
+			progressed = true
+		}
+		if !progressed {
+			return nil
+		}
+	}
+}
+
+// Link : 直接以已有文件作为内容(如WebDAV的COPY), 无需读取及写入数据
+func (w *Writer) Link(src *FileInfo) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed || w.info.size > 0 {
+		return errors.New("link after write")
+	}
+	w.source = src
+	return nil
+}
+
+// Commit : 提交文件
+func (w *Writer) Commit() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errWriterClosed
+	}
+	w.closed = true
+	w.tmp.Close()
+
+	if w.source != nil {
+		os.Remove(w.tmp.Name())
+		w.info.size, w.info.filehash = w.source.size, w.source.filehash
+		return linkFile(w.fs.username, dbcli.FileMeta{
+			FileSha1: w.source.filehash,
+			FileName: w.name,
+			FileSize: w.source.size,
+		})
+	}
+
+	// 存在从未写入的空洞时, 按读回的内容(空洞为0)补齐hash
+	if w.hashed < w.info.size {
+		fd, err := os.Open(w.tmp.Name())
+		if err != nil {
+			os.Remove(w.tmp.Name())
+			return err
+		}
+		_, err = io.Copy(w.hash, io.NewSectionReader(fd, w.hashed, w.info.size-w.hashed))
+		fd.Close()
+		if err != nil {
+			os.Remove(w.tmp.Name())
+			return err
+		}
+	}
+
+	filehash := hex.EncodeToString(w.hash.Sum(nil))
+	if err := commitFile(w.fs.username, w.name, w.tmp.Name(), filehash, w.info.size); err != nil {
+		return err
+	}
+	w.info.filehash = filehash
+	return nil
+}
+
+// Abort : 放弃写入的数据
+func (w *Writer) Abort() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	w.closed = true
+	w.tmp.Close()
+	os.Remove(w.tmp.Name())
+}
@@ -0,0 +1,380 @@
+package userfs
+
+import (
+	"errors"
+	"log"
+	"mime"
+	"os"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/service/dbproxy/orm"
+	"github.com/cloud/store/replica/dbreplica"
+)
+
+const (
+	// EmptySHA1 : 空内容的sha1, 用作目录标记的文件hash(与S3网关的bucket标记一致)
+	EmptySHA1 = "da39a3ee5e6b4b0d3255bfef95601890afd80709"
+	// MaxFileNameLen : 用户文件表file_name字段的长度限制
+	MaxFileNameLen = 256
+	// ListPageSize : 列目录时每次从dbproxy查询的文件数
+	ListPageSize = 1000
+	// subtreeEnd : 拼接在目录前缀之后, 按字节序大于该目录下的任意文件名, 用于列目录时跳过子目录
+	subtreeEnd = "\U0010FFFF"
+)
+
+var (
+	// ErrIsDirectory : 对目录执行文件操作
+	ErrIsDirectory = errors.New("is a directory")
+	// ErrNotDirectory : 对文件执行目录操作
+	ErrNotDirectory = errors.New("not a directory")
+	// ErrNameTooLong : 文件名超出用户文件表的长度限制
+	ErrNameTooLong = errors.New("file name too long")
+	// ErrServer : 访问dbproxy失败
+	ErrServer = errors.New("dbproxy request failed")
+)
+
+// FS : 将用户文件表映射为目录树, 供WebDAV/SFTP等文件协议使用.
+// 文件名即用户文件表中的file_name(路径去掉开头的"/"), 目录由文件名中的"/"隐式构成,
+// 新建的空目录以"dir/"形式的目录标记保存
+type FS struct {
+	username string
+}
+
+// New : 返回指定用户的目录树
+func New(username string) *FS {
+	return &FS{username: username}
+}
+
+// FileInfo : 实现os.FileInfo
+type FileInfo struct {
+	name     string
+	size     int64
+	modTime  time.Time
+	isDir    bool
+	filehash string
+}
+
+func newFileInfo(name string, ufile orm.TableUserFile) *FileInfo {
+	return &FileInfo{
+		name:     name,
+		size:     ufile.FileSize,
+		modTime:  parseDBTime(ufile.UploadAt),
+		filehash: ufile.FileHash,
+	}
+}
+
+func newDirInfo(name string, modTime time.Time) *FileInfo {
+	return &FileInfo{name: name, isDir: true, modTime: modTime}
+}
+
+func (fi *FileInfo) Name() string       { return fi.name }
+func (fi *FileInfo) Size() int64        { return fi.size }
+func (fi *FileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *FileInfo) IsDir() bool        { return fi.isDir }
+func (fi *FileInfo) Sys() interface{}   { return nil }
+
+func (fi *FileInfo) Mode() os.FileMode {
+	if fi.isDir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+
+// FileHash : 文件的sha1, 目录及尚未提交的文件为空
+func (fi *FileInfo) FileHash() string { return fi.filehash }
+
+// ContentType : 按扩展名判断文件类型
+func (fi *FileInfo) ContentType() string {
+	if ctype := mime.TypeByExtension(path.Ext(fi.name)); ctype != "" {
+		return ctype
+	}
+	return "application/octet-stream"
+}
+
+// parseDBTime : 解析数据库中的时间(本地时区)
+func parseDBTime(value string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// fileName : 将路径转换为用户文件表中的文件名, 根目录为空字符串
+func fileName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// dirPrefix : 目录下文件的文件名前缀
+func dirPrefix(fn string) string {
+	if fn == "" {
+		return ""
+	}
+	return fn + "/"
+}
+
+// Stat : 查询文件或目录信息, 同名的文件优先于目录
+func (fs *FS) Stat(name string) (*FileInfo, error) {
+	fn := fileName(name)
+	if fn == "" {
+		return newDirInfo("/", time.Now()), nil
+	}
+	if utf8.RuneCountInString(fn) > MaxFileNameLen {
+		return nil, os.ErrNotExist
+	}
+
+	// 1. 同名文件
+	dbResp, err := dbcli.QueryUserFileByName(fs.username, fn)
+	if err != nil || !dbResp.Suc {
+		return nil, ErrServer
+	}
+	if dbResp.Data != nil {
+		ufile := dbcli.ToTableUserFile(dbResp.Data)
+		return newFileInfo(path.Base(fn), ufile), nil
+	}
+
+	// 2. 存在以"fn/"开头的文件(包括目录标记)即为目录
+	var first *orm.TableUserFile
+	err = fs.walk(dirPrefix(fn), func(ufile orm.TableUserFile) (string, bool) {
+		first = &ufile
+		return "", true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if first == nil {
+		return nil, os.ErrNotExist
+	}
+	return newDirInfo(path.Base(fn), parseDBTime(first.UploadAt)), nil
+}
+
+// ReadDir : 列出目录下的文件及子目录
+func (fs *FS) ReadDir(name string) ([]os.FileInfo, error) {
+	prefix := dirPrefix(fileName(name))
+	infos := []os.FileInfo{}
+	err := fs.walk(prefix, func(ufile orm.TableUserFile) (string, bool) {
+		rel := ufile.FileName[len(prefix):]
+		if rel == "" {
+			// 当前目录的目录标记
+			return "", false
+		}
+		if i := strings.Index(rel, "/"); i >= 0 {
+			sub := rel[:i]
+			infos = append(infos, newDirInfo(sub, parseDBTime(ufile.UploadAt)))
+			return prefix + sub + "/" + subtreeEnd, false
+		}
+		infos = append(infos, newFileInfo(rel, ufile))
+		return "", false
+	})
+	return infos, err
+}
+
+// Mkdir : 新建空目录, 父目录必须存在
+func (fs *FS) Mkdir(name string) error {
+	fn := fileName(name)
+	if fn == "" {
+		return os.ErrExist
+	}
+	if utf8.RuneCountInString(fn)+1 > MaxFileNameLen {
+		return ErrNameTooLong
+	}
+	if _, err := fs.Stat(name); err == nil {
+		return os.ErrExist
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if err := fs.checkParent(fn); err != nil {
+		return err
+	}
+	return linkFile(fs.username, dbcli.FileMeta{
+		FileSha1: EmptySHA1,
+		FileName: dirPrefix(fn),
+	})
+}
+
+// RemoveAll : 删除文件或整个目录(标记删除)
+func (fs *FS) RemoveAll(name string) error {
+	fn := fileName(name)
+	if fn == "" {
+		return os.ErrPermission
+	}
+	dbResp, err := dbcli.DeleteUserFileByName(fs.username, fn)
+	if err != nil || !dbResp.Suc {
+		return ErrServer
+	}
+	dbResp, err = dbcli.DeleteUserFilesByPrefix(fs.username, dirPrefix(fn))
+	if err != nil || !dbResp.Suc {
+		return ErrServer
+	}
+	return nil
+}
+
+// Remove : 删除单个文件(标记删除), 不影响同名目录
+func (fs *FS) Remove(name string) error {
+	fn := fileName(name)
+	if fn == "" {
+		return os.ErrPermission
+	}
+	dbResp, err := dbcli.DeleteUserFileByName(fs.username, fn)
+	if err != nil || !dbResp.Suc {
+		return ErrServer
+	}
+	return nil
+}
+
+// Rename : 重命名文件或目录, 目标位置须不存在且其父目录存在
+func (fs *FS) Rename(oldName, newName string) error {
+	oldFn, newFn := fileName(oldName), fileName(newName)
+	if oldFn == "" || newFn == "" {
+		return os.ErrPermission
+	}
+	if newFn == oldFn || strings.HasPrefix(newFn, dirPrefix(oldFn)) {
+		// 不能将目录移动到自身之下
+		return os.ErrInvalid
+	}
+	info, err := fs.Stat(oldName)
+	if err != nil {
+		return err
+	}
+	if err := fs.checkParent(newFn); err != nil {
+		return err
+	}
+
+	if err := fs.checkVault(oldFn, newFn, info.IsDir()); err != nil {
+		return err
+	}
+
+	var dbResp *orm.ExecResult
+	if info.IsDir() {
+		dbResp, err = dbcli.RenameUserFilesByPrefix(fs.username, dirPrefix(oldFn), dirPrefix(newFn))
+	} else {
+		if utf8.RuneCountInString(newFn) > MaxFileNameLen {
+			return ErrNameTooLong
+		}
+		dbResp, err = dbcli.RenameUserFileByName(fs.username, oldFn, newFn)
+	}
+	if err != nil {
+		return err
+	}
+	if !dbResp.Suc {
+		log.Println("rename failed: " + dbResp.Msg)
+		return ErrServer
+	}
+	return nil
+}
+
+// checkVault : 加密目录中的文件名为密文, 不能与明文目录之间移动, 也不能移动包含加密目录的目录
+func (fs *FS) checkVault(oldFn, newFn string, isDir bool) error {
+	oldVault, err := dbcli.VaultFolder(fs.username, oldFn)
+	if err != nil {
+		return ErrServer
+	}
+	newVault, err := dbcli.VaultFolder(fs.username, newFn)
+	if err != nil {
+		return ErrServer
+	}
+	if oldVault != newVault {
+		return os.ErrPermission
+	}
+	if !isDir {
+		return nil
+	}
+	dbResp, err := dbcli.ListVaults(fs.username)
+	if err != nil || !dbResp.Suc {
+		return ErrServer
+	}
+	for _, v := range dbcli.ToTableVaults(dbResp.Data) {
+		if v.Owner == fs.username && strings.HasPrefix(v.Folder, dirPrefix(oldFn)) {
+			return os.ErrPermission
+		}
+	}
+	return nil
+}
+
+// Open : 以只读方式打开文件
+func (fs *FS) Open(name string) (*Reader, error) {
+	info, err := fs.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, ErrIsDirectory
+	}
+	return &Reader{info: info, file: dbreplica.NewFile(info.filehash, info.size)}, nil
+}
+
+// Create : 创建文件或整体替换同名文件, 父目录必须存在. 数据在Writer.Commit之后才可见
+func (fs *FS) Create(name string) (*Writer, error) {
+	fn := fileName(name)
+	if fn == "" {
+		return nil, ErrIsDirectory
+	}
+	if utf8.RuneCountInString(fn) > MaxFileNameLen {
+		return nil, ErrNameTooLong
+	}
+	info, err := fs.Stat(name)
+	if err == nil && info.IsDir() {
+		return nil, ErrIsDirectory
+	} else if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := fs.checkParent(fn); err != nil {
+		return nil, err
+	}
+	return newWriter(fs, fn)
+}
+
+// checkParent : 新建文件或目录时其父目录必须存在
+func (fs *FS) checkParent(fn string) error {
+	parent := path.Dir("/" + fn)
+	if parent == "/" {
+		return nil
+	}
+	info, err := fs.Stat(parent)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return os.ErrNotExist
+	}
+	return nil
+}
+
+// walk : 按文件名字节序遍历以prefix开头的用户文件.
+// visit返回非空的skipTo时从该位置之后继续遍历, 返回stop为true时结束遍历.
+// like查询不区分大小写, 但从prefix开始按字节序遍历时, 大小写完全匹配的文件总是排在最前,
+// 因此遇到第一个前缀不匹配的文件即可结束
+func (fs *FS) walk(prefix string, visit func(ufile orm.TableUserFile) (skipTo string, stop bool)) error {
+	cursor := strings.TrimSuffix(prefix, "/")
+	for {
+		dbResp, err := dbcli.ListUserFilesByPrefix(fs.username, prefix, cursor, ListPageSize)
+		if err != nil || !dbResp.Suc {
+			return ErrServer
+		}
+		files := dbcli.ToTableUserFiles(dbResp.Data)
+
+		skipped := false
+		for _, ufile := range files {
+			if !strings.HasPrefix(ufile.FileName, prefix) {
+				return nil
+			}
+			cursor = ufile.FileName
+			skipTo, stop := visit(ufile)
+			if stop {
+				return nil
+			}
+			if skipTo != "" {
+				cursor = skipTo
+				skipped = true
+				break
+			}
+		}
+		if !skipped && len(files) < ListPageSize {
+			return nil
+		}
+	}
+}
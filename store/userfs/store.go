@@ -0,0 +1,104 @@
+package userfs
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	cmnCfg "github.com/cloud/config"
+	"github.com/cloud/job"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/store/backend"
+	"github.com/cloud/store/layout"
+	"github.com/cloud/store/policy"
+)
+
+// jobs : 提交异步转移任务使用的Client, 由Setup设置
+var jobs *job.Client
+
+// Setup : 设置提交异步转移任务使用的Client, 须在写入文件前调用
+func Setup(jobClient *job.Client) {
+	jobs = jobClient
+}
+
+func init() {
+	if err := os.MkdirAll(cmnCfg.TempLocalRootDir, 0744); err != nil {
+		fmt.Println("无法指定目录用于存储临时文件: " + cmnCfg.TempLocalRootDir)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(cmnCfg.MergeLocalRootDir, 0744); err != nil {
+		fmt.Println("无法指定目录用于存储合并后文件: " + cmnCfg.MergeLocalRootDir)
+		os.Exit(1)
+	}
+}
+
+// commitFile : 将临时文件保存为用户文件. 文件表中已有相同内容时直接复用(秒传),
+// 否则移入合并目录, 更新文件表并发起异步转移, 与/file/upload的处理流程一致
+func commitFile(username, name, tmpPath, filehash string, filesize int64) error {
+	// 1. 查询是否已存在相同hash的文件
+	dbResp, err := dbcli.GetFileMeta(filehash)
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if !dbResp.Suc {
+		os.Remove(tmpPath)
+		return errors.New(dbResp.Msg)
+	}
+
+	fileMeta := dbcli.FileMeta{
+		FileSha1: filehash,
+		FileName: name,
+		FileSize: filesize,
+		UploadAt: time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if dbResp.Data != nil {
+		os.Remove(tmpPath)
+	} else {
+		// 2. 新文件: 写入合并目录(加密)并写入文件表
+		fileMeta.Location = layout.MergePath(filehash)
+		if err := backend.PutLocalFile(tmpPath, fileMeta.Location); err != nil {
+			os.Remove(tmpPath)
+			return err
+		}
+		fRes, err := dbcli.OnFileUploadFinished(fileMeta)
+		if err != nil {
+			return err
+		}
+		if !fRes.Suc {
+			return errors.New("failed to update tbl_file: " + filehash)
+		}
+		publishTransfer(username, fileMeta)
+	}
+
+	// 3. 写入用户文件表, 同名文件会被覆盖
+	return linkFile(username, fileMeta)
+}
+
+// linkFile : 将文件表中已存在的文件写入用户文件表
+func linkFile(username string, fileMeta dbcli.FileMeta) error {
+	ufRes, err := dbcli.OnUserFileUploadFinished(username, fileMeta)
+	if err != nil {
+		return err
+	}
+	if !ufRes.Suc {
+		return errors.New(ufRes.Msg)
+	}
+	return nil
+}
+
+// publishTransfer : 按存储策略提交异步转移任务, 将本地文件转移到Ceph/OSS
+func publishTransfer(username string, fileMeta dbcli.FileMeta) {
+	err := policy.Enqueue(jobs.Enqueue, policy.File{
+		UserName: username,
+		FileName: fileMeta.FileName,
+		FileSize: fileMeta.FileSize,
+		FileHash: fileMeta.FileSha1,
+		Location: fileMeta.Location,
+	})
+	if err != nil {
+		log.Println("enqueue transfer job failed, sha1: " + fileMeta.FileSha1 + ", " + err.Error())
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+
+	"github.com/cloud/config"
+	"github.com/cloud/store/ceph"
+	"github.com/cloud/test/testutil"
+)
+
+// 对S3兼容存储进行集成测试: SigV4签名, 普通及分块上传, Range读取, 删除.
+// 先启动本地MinIO:
+// docker run -d -p 9000:9000 minio/minio server /data
+// go run ./test/ceph -endpoint 127.0.0.1:9000 -ak minioadmin -sk minioadmin
+
+var (
+	endpoint  string
+	accessKey string
+	secretKey string
+	region    string
+	bucket    string
+)
+
+// read : 读取key从offset开始的length字节, 读取的长度须与Get返回的一致
+func read(b *ceph.Bucket, key string, offset, length int64) ([]byte, error) {
+	rc, size, err := b.Get(key, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	if err == nil && int64(len(data)) != size {
+		err = fmt.Errorf("read %d bytes, expect %d", len(data), size)
+	}
+	return data, err
+}
+
+// expect : key从offset开始length字节的内容须为data
+func expect(b *ceph.Bucket, key string, offset, length int64, data []byte) error {
+	got, err := read(b, key, offset, length)
+	if err == nil && !bytes.Equal(got, data) {
+		err = fmt.Errorf("range %d+%d mismatch, got %d bytes", offset, length, len(got))
+	}
+	return err
+}
+
+// failingReader : 读取limit字节后返回错误, 模拟上传中途源数据读取失败
+type failingReader struct {
+	r io.Reader
+}
+
+func (f failingReader) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	if err == io.EOF {
+		return n, errors.New("source interrupted")
+	}
+	return n, err
+}
+
+func main() {
+	flag.StringVar(&endpoint, "endpoint", "127.0.0.1:9000", "S3兼容存储地址(host:port)")
+	flag.StringVar(&accessKey, "ak", "minioadmin", "access key")
+	flag.StringVar(&secretKey, "sk", "minioadmin", "secret key")
+	flag.StringVar(&region, "region", "us-east-1", "SigV4签名使用的region")
+	flag.StringVar(&bucket, "bucket", "cephtest", "测试使用的bucket")
+	flag.Parse()
+
+	opts := ceph.Options{
+		Endpoint:  endpoint,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		Region:    region,
+		PathStyle: true,
+		PartSize:  5 << 20,
+	}
+	b, err := ceph.New(opts, bucket)
+	testutil.Check("connect", err)
+	testutil.Check("create bucket", b.EnsureBucket())
+	testutil.Check("bucket exists", b.EnsureBucket())
+
+	// 1. 错误的密钥被拒绝
+	bad := opts
+	bad.AccessKey, bad.SecretKey = "invalid", "invalid"
+	badBucket, err := ceph.New(bad, bucket)
+	if err == nil {
+		if _, err = badBucket.Size("any"); err == nil {
+			err = errors.New("request with invalid credentials accepted")
+		} else {
+			err = nil
+		}
+	}
+	testutil.Check("reject invalid credentials", err)
+
+	// 2. 小对象以一个请求写入; 存储位置开头的"/"不属于对象名
+	small := []byte("hello from the ceph backend")
+	key := config.CephRootDir + testutil.Sha1Hex(small)
+	testutil.Check("put small object", b.Put(key, bytes.NewReader(small)))
+	testutil.Check("get small object", expect(b, key, 0, -1, small))
+	testutil.Check("get without leading slash", expect(b, key[1:], 0, -1, small))
+	size, err := b.Size(key)
+	if err == nil && size != int64(len(small)) {
+		err = fmt.Errorf("size %d", size)
+	}
+	testutil.Check("stat small object", err)
+
+	// 3. Range读取: 中间一段, 到末尾, 超出末尾时读取到末尾, offset为末尾时为空, 超出末尾返回错误
+	testutil.Check("range read", expect(b, key, 6, 4, small[6:10]))
+	testutil.Check("range read to end", expect(b, key, 6, -1, small[6:]))
+	testutil.Check("range read past end", expect(b, key, 20, 100, small[20:]))
+	testutil.Check("range read at end", expect(b, key, int64(len(small)), -1, nil))
+	if _, _, err = b.Get(key, int64(len(small))+1, -1); err == nil {
+		err = errors.New("offset past end accepted")
+	} else {
+		err = nil
+	}
+	testutil.Check("reject offset past end", err)
+	testutil.Check("put empty object", b.Put("empty", bytes.NewReader(nil)))
+	testutil.Check("get empty object", expect(b, "empty", 0, -1, nil))
+
+	// 4. 大对象边读取边分块上传, 跨块读取
+	large := make([]byte, 18<<20+12345)
+	rand.Read(large)
+	largeKey := config.CephRootDir + testutil.Sha1Hex(large)
+	testutil.Check("put large object", b.Put(largeKey, bytes.NewReader(large)))
+	got, err := read(b, largeKey, 0, -1)
+	if err == nil && testutil.Sha1Hex(got) != testutil.Sha1Hex(large) {
+		err = errors.New("content mismatch")
+	}
+	testutil.Check("get large object", err)
+	testutil.Check("range across parts", expect(b, largeKey, 5<<20-100, 200, large[5<<20-100:5<<20+100]))
+	testutil.Check("range in last part", expect(b, largeKey, 15<<20+7, 3<<20, large[15<<20+7:18<<20+7]))
+	testutil.Check("range to end of large object", expect(b, largeKey, 18<<20, -1, large[18<<20:]))
+	exact := large[:5<<20]
+	testutil.Check("put one full part", b.Put("exact", bytes.NewReader(exact)))
+	testutil.Check("get one full part", expect(b, "exact", 0, -1, exact))
+
+	// 5. 源数据读取失败时上传失败, 不留下对象
+	err = b.Put("broken", failingReader{bytes.NewReader(large[:12<<20])})
+	if err == nil {
+		err = errors.New("interrupted upload succeeded")
+	} else if _, err = b.Size("broken"); err == nil {
+		err = errors.New("interrupted upload left an object")
+	} else {
+		err = nil
+	}
+	testutil.Check("abort interrupted upload", err)
+
+	// 6. 删除后对象不存在, 删除不存在的对象不返回错误
+	for _, k := range []string{key, largeKey, "empty", "exact"} {
+		testutil.Check("remove "+k, b.Remove(k))
+	}
+	_, _, err = b.Get(largeKey, 0, -1)
+	if !ceph.IsNotExist(err) {
+		err = fmt.Errorf("get removed object returned %v", err)
+	} else {
+		err = nil
+	}
+	testutil.Check("get removed object", err)
+	testutil.Check("remove missing object", b.Remove(largeKey))
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/cloud/common"
+	"github.com/cloud/store/compress"
+	"github.com/cloud/store/crypt"
+	"github.com/cloud/test/testutil"
+)
+
+// 测试存储内容的透明压缩: 采样判断, seekable zstd的range读取, 压缩信息记录及恢复,
+// 沿用数据密钥时重新写入的内容不变, 与信封加密组合读取. 压缩信息表使用内存实现:
+// go run ./test/compress
+
+// memStore : 内存中的压缩信息表
+type memStore struct {
+	mu    sync.Mutex
+	infos map[string]compress.Info
+}
+
+func (m *memStore) Get(filehash string, t common.StoreType) (*compress.Info, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	info, ok := m.infos[fmt.Sprintf("%s/%d", filehash, t)]
+	if !ok {
+		return nil, nil
+	}
+	return &info, nil
+}
+
+func (m *memStore) Save(filehash string, t common.StoreType, info compress.Info) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.infos[fmt.Sprintf("%s/%d", filehash, t)] = info
+	return nil
+}
+
+func (m *memStore) Remove(filehash string, t common.StoreType) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.infos, fmt.Sprintf("%s/%d", filehash, t))
+	return nil
+}
+
+// opener : 读取data中的一段, 与backend.open一样范围超出末尾时返回错误
+func opener(data []byte) compress.Opener {
+	return func(offset, length int64) (io.ReadCloser, error) {
+		end := int64(len(data))
+		if length >= 0 {
+			end = offset + length
+		}
+		if offset < 0 || end > int64(len(data)) || offset > end {
+			return nil, fmt.Errorf("range %d+%d out of %d bytes", offset, length, len(data))
+		}
+		return ioutil.NopCloser(bytes.NewReader(data[offset:end])), nil
+	}
+}
+
+// write : 按c的判断写入plain, 返回写入的内容及压缩信息
+func write(c *compress.Compressor, plain []byte, prev *compress.Info, keep bool) ([]byte, *compress.Info, error) {
+	body, info, err := c.Compress(bytes.NewReader(plain), prev, keep)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer body.Close()
+	data, err := ioutil.ReadAll(body)
+	return data, info, err
+}
+
+// read : 读取原始内容[offset, offset+length)
+func read(c *compress.Compressor, info *compress.Info, raw compress.Opener, offset, length int64) ([]byte, error) {
+	rc, err := c.Open(info, offset, length, raw)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+// text : 可压缩的文本内容
+func text(size int) []byte {
+	var b strings.Builder
+	for i := 0; b.Len() < size; i++ {
+		fmt.Fprintf(&b, "%08d INFO request /file/download?filehash=%040x served in %dms\n", i, i*7919, i%97)
+	}
+	return []byte(b.String()[:size])
+}
+
+func main() {
+	dir, err := ioutil.TempDir("", "compress")
+	testutil.Check("create temp dir", err)
+	defer os.RemoveAll(dir)
+
+	store := &memStore{infos: map[string]compress.Info{}}
+	c := &compress.Compressor{
+		Store:      store,
+		Enable:     true,
+		Level:      int(zstd.SpeedDefault),
+		FrameSize:  4096,
+		SampleSize: 8192,
+		MinRatio:   0.8,
+		MinSize:    1024,
+		TempDir:    dir,
+	}
+
+	// 1. 可压缩的内容以seekable zstd写入, 记录原始长度及写入长度, 完整读取与原文一致
+	plain := text(50*1024 + 123)
+	filehash := testutil.Sha1Hex(plain)
+	data, info, err := write(c, plain, nil, false)
+	if err == nil && info == nil {
+		err = errors.New("compressible content not compressed")
+	}
+	if err == nil && (info.LogicalSize != int64(len(plain)) || info.StoredSize != int64(len(data))) {
+		err = fmt.Errorf("info sizes %d/%d, expect %d/%d", info.LogicalSize, info.StoredSize, len(plain), len(data))
+	}
+	if err == nil && info.StoredSize >= info.LogicalSize/2 {
+		err = fmt.Errorf("stored %d bytes for %d", info.StoredSize, info.LogicalSize)
+	}
+	testutil.Check("compress", err)
+	got, err := read(c, info, opener(data), 0, -1)
+	if err == nil && !bytes.Equal(got, plain) {
+		err = errors.New("decompressed content mismatch")
+	}
+	testutil.Check("decompress whole file", err)
+
+	// 压缩内容是标准的zstd流, 普通解压工具也可以解压(跳过seek table)
+	dec, err := zstd.NewReader(bytes.NewReader(data))
+	if err == nil {
+		got, err = ioutil.ReadAll(dec)
+		dec.Close()
+	}
+	if err == nil && !bytes.Equal(got, plain) {
+		err = errors.New("standard zstd decode mismatch")
+	}
+	testutil.Check("standard zstd stream", err)
+
+	// 2. range读取: 帧内, 跨帧, 帧边界, 到末尾; 只读取覆盖范围的帧
+	ranges := [][2]int64{{0, 1}, {10, 100}, {4000, 200}, {4096, 4096}, {4095, 2}, {9000, 20000}, {51200, 123}, {51322, 1}}
+	for _, rg := range ranges {
+		var fetched int64
+		counting := func(offset, length int64) (io.ReadCloser, error) {
+			fetched += length
+			return opener(data)(offset, length)
+		}
+		got, err := read(c, info, counting, rg[0], rg[1])
+		if err == nil && !bytes.Equal(got, plain[rg[0]:rg[0]+rg[1]]) {
+			err = fmt.Errorf("range %d+%d mismatch", rg[0], rg[1])
+		}
+		if err == nil && rg[1] <= 4096 && fetched > info.StoredSize/2 {
+			err = fmt.Errorf("range %d+%d fetched %d of %d bytes", rg[0], rg[1], fetched, info.StoredSize)
+		}
+		if err != nil {
+			testutil.Check("range read", err)
+		}
+	}
+	got, err = read(c, info, opener(data), 30000, -1)
+	if err == nil && !bytes.Equal(got, plain[30000:]) {
+		err = errors.New("read to end mismatch")
+	}
+	testutil.Check("range read", err)
+	_, err = read(c, info, opener(data), 50000, 2000)
+	if err == nil {
+		err = errors.New("range past end accepted")
+	} else {
+		err = nil
+	}
+	testutil.Check("reject range past end", err)
+
+	// 3. 不可压缩的内容及小文件原样写入
+	random := make([]byte, 20000)
+	rand.Read(random)
+	got, info, err = write(c, random, nil, false)
+	if err == nil && (info != nil || !bytes.Equal(got, random)) {
+		err = errors.New("incompressible content changed")
+	}
+	testutil.Check("skip incompressible content", err)
+	small := text(500)
+	got, info, err = write(c, small, nil, false)
+	if err == nil && (info != nil || !bytes.Equal(got, small)) {
+		err = errors.New("small file changed")
+	}
+	testutil.Check("skip small file", err)
+
+	// 开头可压缩而后续内容不可压缩时以采样为准, 仍可正确读取
+	mixed := append(text(8192), random...)
+	mixedData, mixedInfo, err := write(c, mixed, nil, false)
+	if err == nil && mixedInfo == nil {
+		err = errors.New("sampled content not compressed")
+	}
+	if err == nil {
+		got, err = read(c, mixedInfo, opener(mixedData), 8000, 5000)
+		if err == nil && !bytes.Equal(got, mixed[8000:13000]) {
+			err = errors.New("mixed content mismatch")
+		}
+	}
+	testutil.Check("decide by sample", err)
+
+	// 4. 关闭压缩后新写入的内容不压缩, 已压缩的内容仍可读取
+	c.Enable = false
+	got, info, err = write(c, plain, nil, false)
+	if err == nil && (info != nil || !bytes.Equal(got, plain)) {
+		err = errors.New("content compressed while disabled")
+	}
+	testutil.Check("compression disabled", err)
+
+	// 沿用数据密钥时按原有记录写入: 有记录时以原参数压缩且内容相同, 没有记录时不压缩
+	_, first, _ := write(&compress.Compressor{Store: store, Enable: true, Level: c.Level, FrameSize: 4096,
+		SampleSize: 8192, MinRatio: 0.8, MinSize: 1024, TempDir: dir}, plain, nil, false)
+	again, kept, err := write(c, plain, first, true)
+	if err == nil && (kept == nil || *kept != *first || !bytes.Equal(again, data)) {
+		err = errors.New("rewrite with kept settings differs")
+	}
+	if err == nil {
+		c.Enable = true
+		got, kept, err = write(c, plain, nil, true)
+		if err == nil && (kept != nil || !bytes.Equal(got, plain)) {
+			err = errors.New("uncompressed location compressed on rewrite")
+		}
+	}
+	testutil.Check("keep settings on rewrite", err)
+
+	// 5. 记录压缩信息: 相同时不更新, 不压缩时删除, 写入失败时恢复原记录
+	testutil.Check("record info", c.Record(filehash, common.StoreOSS, nil, first))
+	saved, _ := store.Get(filehash, common.StoreOSS)
+	err = nil
+	if saved == nil || *saved != *first {
+		err = errors.New("info not saved")
+	}
+	if err == nil {
+		if err = c.Record(filehash, common.StoreOSS, first, nil); err == nil {
+			if saved, _ = store.Get(filehash, common.StoreOSS); saved != nil {
+				err = errors.New("info not removed")
+			}
+		}
+	}
+	if err == nil {
+		if err = c.Record(filehash, common.StoreOSS, nil, first); err == nil {
+			err = c.Forget(filehash, common.StoreOSS)
+		}
+		if saved, _ = store.Get(filehash, common.StoreOSS); err == nil && saved != nil {
+			err = errors.New("info not forgotten")
+		}
+	}
+	testutil.Check("restore info", err)
+
+	// 6. 损坏的seek table及帧返回ErrCorrupt
+	broken := append([]byte(nil), data...)
+	broken[len(broken)-1] ^= 0xff
+	_, err = read(c, first, opener(broken), 0, -1)
+	if err != compress.ErrCorrupt {
+		err = fmt.Errorf("corrupt footer returned %v", err)
+	} else {
+		broken = append([]byte(nil), data...)
+		broken[len(broken)-20] ^= 0xff
+		_, err = read(c, first, opener(broken), 0, -1)
+		if err != compress.ErrCorrupt {
+			err = fmt.Errorf("corrupt seek table returned %v", err)
+		} else {
+			err = nil
+		}
+	}
+	testutil.Check("detect corrupt seek table", err)
+	broken = append([]byte(nil), data...)
+	broken[100] ^= 0xff
+	_, err = read(c, first, opener(broken), 0, 4096)
+	if err != compress.ErrCorrupt {
+		err = fmt.Errorf("corrupt frame returned %v", err)
+	} else {
+		err = nil
+	}
+	testutil.Check("detect corrupt frame", err)
+	_, err = read(c, first, opener(data[:len(data)/2]), 0, -1)
+	if err == nil {
+		err = errors.New("truncated content accepted")
+	} else {
+		err = nil
+	}
+	testutil.Check("detect truncation", err)
+
+	// 7. 先压缩后加密: range读取依次经过解压及解密, 只解密覆盖帧的密文
+	kms, err := crypt.NewLocalKMS(filepath.Join(dir, "kms", "master_keys.json"))
+	testutil.Check("create local kms", err)
+	envelope := &crypt.Envelope{KMS: kms, Keys: testutil.NewMemKeys(), ChunkSize: 1024}
+	body, info, err := c.Compress(bytes.NewReader(plain), nil, false)
+	testutil.Check("compress before encryption", err)
+	var cipher []byte
+	r, err := envelope.Encrypt(filehash, common.StoreCeph, body)
+	if err == nil {
+		cipher, err = ioutil.ReadAll(r)
+	}
+	body.Close()
+	testutil.Check("encrypt compressed content", err)
+	key, _ := envelope.Lookup(filehash, common.StoreCeph)
+	stored := func(offset, length int64) (io.ReadCloser, error) {
+		return envelope.Open(filehash, key, offset, length, func(cOffset, cLength int64) (io.ReadCloser, error) {
+			end := cOffset + cLength
+			if end > int64(len(cipher)) {
+				end = int64(len(cipher))
+			}
+			return ioutil.NopCloser(bytes.NewReader(cipher[cOffset:end])), nil
+		})
+	}
+	got, err = read(c, info, stored, 12345, 6789)
+	if err == nil && !bytes.Equal(got, plain[12345:12345+6789]) {
+		err = errors.New("compressed and encrypted range mismatch")
+	}
+	testutil.Check("read compressed and encrypted content", err)
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/cloud/common"
+	"github.com/cloud/store/crypt"
+	"github.com/cloud/test/testutil"
+)
+
+// 测试存储内容的信封加密: 分块加解密及range读取, 截断/篡改检测, 数据密钥复用,
+// 主密钥更换后重新加密数据密钥. 数据密钥表使用内存实现, KMS使用临时目录中的本地KMS:
+// go run ./test/crypt
+
+// memObjects : 内存中的对象存储, 读取行为与backend中的密文读取一致(范围超出末尾时返回到末尾)
+type memObjects struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (s *memObjects) put(e *crypt.Envelope, filehash string, t common.StoreType, plain []byte) error {
+	r, err := e.Encrypt(filehash, t, bytes.NewReader(plain))
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[filehash] = data
+	return nil
+}
+
+func (s *memObjects) opener(filehash string) func(cOffset, cLength int64) (io.ReadCloser, error) {
+	return func(cOffset, cLength int64) (io.ReadCloser, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		data := s.data[filehash]
+		end := int64(len(data))
+		if cLength >= 0 && cOffset+cLength < end {
+			end = cOffset + cLength
+		}
+		if cOffset > end {
+			return nil, errors.New("range out of object")
+		}
+		return ioutil.NopCloser(bytes.NewReader(data[cOffset:end])), nil
+	}
+}
+
+// read : 读取明文[offset, offset+length)
+func (s *memObjects) read(e *crypt.Envelope, filehash string, t common.StoreType, offset, length int64) ([]byte, error) {
+	key, err := e.Lookup(filehash, t)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, errors.New("data key not found")
+	}
+	rc, err := e.Open(filehash, key, offset, length, s.opener(filehash))
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+func main() {
+	dir, err := ioutil.TempDir("", "crypt")
+	testutil.Check("create temp dir", err)
+	defer os.RemoveAll(dir)
+
+	kms, err := crypt.NewLocalKMS(filepath.Join(dir, "kms", "master_keys.json"))
+	testutil.Check("create local kms", err)
+	keys := testutil.NewMemKeys()
+	envelope := &crypt.Envelope{KMS: kms, Keys: keys, ChunkSize: 1024}
+	objects := &memObjects{data: map[string][]byte{}}
+
+	// 1. 加密后密文长度符合分块, 不含明文, 完整解密与原文一致
+	plain := make([]byte, 5*1024+100)
+	rand.Read(plain)
+	filehash := testutil.Sha1Hex(plain)
+	testutil.Check("encrypt", objects.put(envelope, filehash, common.StoreLocal, plain))
+	err = nil
+	if int64(len(objects.data[filehash])) != crypt.CipherSize(int64(len(plain)), 1024) {
+		err = fmt.Errorf("cipher size %d, expect %d", len(objects.data[filehash]),
+			crypt.CipherSize(int64(len(plain)), 1024))
+	} else if bytes.Contains(objects.data[filehash], plain[:64]) {
+		err = errors.New("ciphertext contains plaintext")
+	}
+	testutil.Check("ciphertext layout", err)
+	got, err := objects.read(envelope, filehash, common.StoreLocal, 0, -1)
+	if err == nil && !bytes.Equal(got, plain) {
+		err = errors.New("decrypted content mismatch")
+	}
+	testutil.Check("decrypt whole file", err)
+
+	// 2. range读取: 块内, 跨块, 块边界, 到末尾
+	ranges := [][2]int64{{0, 1}, {10, 100}, {1000, 100}, {1024, 1024}, {1023, 2}, {3000, 2220}, {5120, 100}, {5219, 1}}
+	for _, rg := range ranges {
+		got, err := objects.read(envelope, filehash, common.StoreLocal, rg[0], rg[1])
+		if err == nil && !bytes.Equal(got, plain[rg[0]:rg[0]+rg[1]]) {
+			err = fmt.Errorf("range %d+%d mismatch", rg[0], rg[1])
+		}
+		if err != nil {
+			testutil.Check("range read", err)
+		}
+	}
+	got, err = objects.read(envelope, filehash, common.StoreLocal, 2000, -1)
+	if err == nil && !bytes.Equal(got, plain[2000:]) {
+		err = errors.New("read to end mismatch")
+	}
+	testutil.Check("range read", err)
+
+	// 3. 空文件
+	empty := testutil.Sha1Hex(nil)
+	testutil.Check("encrypt empty file", objects.put(envelope, empty, common.StoreLocal, nil))
+	got, err = objects.read(envelope, empty, common.StoreLocal, 0, -1)
+	if err == nil && len(got) != 0 {
+		err = errors.New("empty file decrypted to data")
+	}
+	testutil.Check("decrypt empty file", err)
+
+	// 4. 同一位置重新写入沿用数据密钥, 不同存储使用不同的数据密钥
+	before, _ := keys.Get(filehash, common.StoreLocal)
+	testutil.Check("re-encrypt", objects.put(envelope, filehash, common.StoreLocal, plain))
+	after, _ := keys.Get(filehash, common.StoreLocal)
+	r, err := envelope.Encrypt(filehash, common.StoreOSS, bytes.NewReader(plain))
+	if err == nil {
+		var ossData []byte
+		ossData, err = ioutil.ReadAll(r)
+		if err == nil && bytes.Equal(ossData, objects.data[filehash]) {
+			err = errors.New("same ciphertext in different stores")
+		}
+	}
+	if err == nil && !bytes.Equal(before.Wrapped, after.Wrapped) {
+		err = errors.New("data key changed on rewrite")
+	}
+	testutil.Check("data key reuse", err)
+
+	// 5. 在块边界截断的密文返回io.ErrUnexpectedEOF, 块内截断无法通过校验
+	full := objects.data[filehash]
+	objects.data[filehash] = full[:len(full)-(100+crypt.Overhead)]
+	_, err = objects.read(envelope, filehash, common.StoreLocal, 0, int64(len(plain)))
+	if err != io.ErrUnexpectedEOF {
+		err = fmt.Errorf("truncated read returned %v", err)
+	} else {
+		objects.data[filehash] = full[:len(full)-200]
+		if _, err = objects.read(envelope, filehash, common.StoreLocal, 0, -1); err != crypt.ErrCorrupt {
+			err = fmt.Errorf("truncated read returned %v", err)
+		} else {
+			err = nil
+		}
+	}
+	testutil.Check("detect truncation", err)
+
+	// 6. 篡改的密文返回ErrCorrupt
+	tampered := append([]byte(nil), full...)
+	tampered[1500] ^= 0xff
+	objects.data[filehash] = tampered
+	_, err = objects.read(envelope, filehash, common.StoreLocal, 1100, 10)
+	if err != crypt.ErrCorrupt {
+		err = fmt.Errorf("tampered read returned %v", err)
+	} else {
+		err = nil
+	}
+	testutil.Check("detect tampering", err)
+	objects.data[filehash] = full
+
+	// 7. 附加校验数据(文件hash)不同时无法解密
+	key, _ := keys.Get(filehash, common.StoreLocal)
+	rc, err := envelope.Open(empty, key, 0, -1, objects.opener(filehash))
+	if err == nil {
+		_, err = ioutil.ReadAll(rc)
+		rc.Close()
+	}
+	if err != crypt.ErrCorrupt {
+		err = fmt.Errorf("wrong aad returned %v", err)
+	} else {
+		err = nil
+	}
+	testutil.Check("bind ciphertext to file hash", err)
+
+	// 8. 并发写入同一位置时只保存一个数据密钥, 各写入的密文都可解密
+	other := make([]byte, 3000)
+	rand.Read(other)
+	otherHash := testutil.Sha1Hex(other)
+	var wg sync.WaitGroup
+	ciphers := make([][]byte, 8)
+	errs := make([]error, 8)
+	for i := range ciphers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r, err := envelope.Encrypt(otherHash, common.StoreCeph, bytes.NewReader(other))
+			if err == nil {
+				ciphers[i], err = ioutil.ReadAll(r)
+			}
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+	err = nil
+	for i := range ciphers {
+		if errs[i] != nil {
+			err = errs[i]
+			break
+		}
+		objects.data[otherHash] = ciphers[i]
+		var got []byte
+		if got, err = objects.read(envelope, otherHash, common.StoreCeph, 0, -1); err == nil && !bytes.Equal(got, other) {
+			err = fmt.Errorf("writer %d ciphertext mismatch", i)
+		}
+		if err != nil {
+			break
+		}
+	}
+	testutil.Check("concurrent key creation", err)
+
+	// 9. 更换主密钥: 旧数据仍可读取, 重新加密数据密钥后使用新主密钥, 文件内容不变
+	oldKeyID, _ := kms.CurrentKeyID()
+	newKeyID, err := kms.Rotate()
+	if err == nil && newKeyID == oldKeyID {
+		err = errors.New("master key not rotated")
+	}
+	testutil.Check("rotate master key", err)
+	got, err = objects.read(envelope, filehash, common.StoreLocal, 0, -1)
+	if err == nil && !bytes.Equal(got, plain) {
+		err = errors.New("old data unreadable after rotation")
+	}
+	testutil.Check("read with old master key", err)
+
+	// 另一个进程的KMS读取同一文件, 看到更换后的主密钥
+	kms2, err := crypt.NewLocalKMS(filepath.Join(dir, "kms", "master_keys.json"))
+	if err == nil {
+		var current string
+		if current, err = kms2.CurrentKeyID(); err == nil && current != newKeyID {
+			err = fmt.Errorf("second kms current %s, expect %s", current, newKeyID)
+		}
+	}
+	testutil.Check("share master keys", err)
+
+	cipherBefore := append([]byte(nil), objects.data[filehash]...)
+	count, err := envelope.Rewrap(2)
+	if err == nil && count != 2 {
+		err = fmt.Errorf("rewrapped %d keys in first batch, expect 2", count)
+	}
+	for err == nil {
+		if count, err = envelope.Rewrap(2); count == 0 {
+			break
+		}
+	}
+	stale, _ := keys.ListStale(newKeyID, 100)
+	if err == nil && len(stale) != 0 {
+		err = fmt.Errorf("%d stale keys after rewrap", len(stale))
+	}
+	testutil.Check("rewrap data keys", err)
+	got, err = objects.read(envelope, filehash, common.StoreLocal, 100, 3000)
+	if err == nil && !bytes.Equal(got, plain[100:3100]) {
+		err = errors.New("content mismatch after rewrap")
+	}
+	if err == nil && !bytes.Equal(cipherBefore, objects.data[filehash]) {
+		err = errors.New("ciphertext changed by rewrap")
+	}
+	testutil.Check("read after rewrap", err)
+
+	// 10. 删除数据密钥后视为未加密
+	testutil.Check("forget data key", envelope.Forget(filehash, common.StoreLocal))
+	key, err = envelope.Lookup(filehash, common.StoreLocal)
+	if err == nil && key != nil {
+		err = errors.New("data key still present")
+	}
+	testutil.Check("lookup after forget", err)
+}
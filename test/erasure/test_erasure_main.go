@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloud/store/erasure"
+	"github.com/cloud/test/testutil"
+)
+
+// 测试纠删码本地存储: 分片写入及range读取, 模拟磁盘丢失时由校验分片重建读取,
+// 更换磁盘后重建分片, 块损坏的发现及修复, 修改分片配置及增加磁盘后读取已有对象.
+// 各磁盘以临时目录代替:
+// go run ./test/erasure
+
+// read : 读取对象[offset, offset+length)
+func read(s *erasure.Store, name string, offset, length int64) ([]byte, error) {
+	rd, err := s.Open(name, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	defer rd.Close()
+	return ioutil.ReadAll(rd)
+}
+
+// expect : 完整读取的内容须与data一致
+func expect(s *erasure.Store, name string, data []byte) error {
+	got, err := read(s, name, 0, -1)
+	if err == nil && !bytes.Equal(got, data) {
+		err = errors.New("content mismatch")
+	}
+	return err
+}
+
+// shardFiles : 各磁盘中对象name的分片文件
+func shardFiles(disks []string, name string) []string {
+	files := []string{}
+	for _, disk := range disks {
+		matches, _ := filepath.Glob(filepath.Join(disk, name+".ec*"))
+		files = append(files, matches...)
+	}
+	return files
+}
+
+// replaceDisk : 模拟磁盘损坏后更换为空磁盘
+func replaceDisk(disk string) error {
+	if err := os.RemoveAll(disk); err != nil {
+		return err
+	}
+	return os.MkdirAll(disk, 0744)
+}
+
+func main() {
+	root, err := ioutil.TempDir("", "erasure")
+	testutil.Check("create temp dir", err)
+	defer os.RemoveAll(root)
+	disks := []string{}
+	for i := 0; i < 7; i++ {
+		disks = append(disks, filepath.Join(root, fmt.Sprintf("disk%d", i)))
+	}
+
+	_, err = erasure.New(disks[:5], 4, 2, 1000)
+	if err == nil {
+		err = errors.New("accepted fewer dirs than shards")
+	} else if _, err = erasure.New([]string{disks[0], disks[1], disks[2], disks[0] + "/"}, 2, 1, 1000); err == nil {
+		err = errors.New("accepted duplicate dirs")
+	} else {
+		err = nil
+	}
+	testutil.Check("validate config", err)
+	store, err := erasure.New(disks[:6], 4, 2, 1000)
+	testutil.Check("create store", err)
+
+	// 1. 写入后6个分片分别位于6个磁盘, 完整读取与原文一致
+	data := make([]byte, 23456)
+	rand.Read(data)
+	name := "ab/0123456789abcdef0123456789abcdef01234567"
+	testutil.Check("put object", store.Put(name, bytes.NewReader(data)))
+	files := shardFiles(disks, name)
+	err = nil
+	if len(files) != 6 {
+		err = fmt.Errorf("%d shard files, expect 6", len(files))
+	}
+	for _, disk := range disks[:6] {
+		if len(shardFiles([]string{disk}, name)) != 1 && err == nil {
+			err = errors.New("shards not spread across disks: " + strings.Join(files, ", "))
+		}
+	}
+	testutil.Check("spread shards", err)
+	testutil.Check("read object", expect(store, name, data))
+
+	// 2. range读取: 块内, 跨块, 跨条带, 到末尾; 超出末尾时读取到末尾
+	ranges := [][2]int64{{0, 1}, {999, 2}, {3990, 20}, {4000, 4000}, {5000, 13000}, {20000, 3456}, {23455, 1}}
+	for _, rg := range ranges {
+		got, err := read(store, name, rg[0], rg[1])
+		if err == nil && !bytes.Equal(got, data[rg[0]:rg[0]+rg[1]]) {
+			err = fmt.Errorf("range %d+%d mismatch", rg[0], rg[1])
+		}
+		if err != nil {
+			testutil.Check("range read", err)
+		}
+	}
+	got, err := read(store, name, 23000, 1000)
+	if err == nil && !bytes.Equal(got, data[23000:]) {
+		err = errors.New("read past end mismatch")
+	}
+	testutil.Check("range read", err)
+	if _, err = store.Open(name, 30000, 1); err == nil {
+		err = errors.New("offset past end accepted")
+	} else {
+		err = nil
+	}
+	testutil.Check("reject offset past end", err)
+
+	// 空对象及长度恰为整条带的对象
+	testutil.Check("put empty object", store.Put("empty", bytes.NewReader(nil)))
+	testutil.Check("read empty object", expect(store, "empty", nil))
+	exact := data[:8000]
+	testutil.Check("put full stripes", store.Put("exact", bytes.NewReader(exact)))
+	testutil.Check("read full stripes", expect(store, "exact", exact))
+
+	// 3. 丢失m个磁盘时仍可读取, 丢失m+1个时返回ErrTooFewShards
+	testutil.Check("lose first disk", os.RemoveAll(disks[1]))
+	testutil.Check("read with one disk lost", expect(store, name, data))
+	got, err = read(store, name, 4500, 9000)
+	if err == nil && !bytes.Equal(got, data[4500:13500]) {
+		err = errors.New("degraded range mismatch")
+	}
+	testutil.Check("degraded range read", err)
+	testutil.Check("lose second disk", os.RemoveAll(disks[4]))
+	testutil.Check("read with two disks lost", expect(store, name, data))
+	testutil.Check("read empty object degraded", expect(store, "empty", nil))
+	saved := filepath.Join(root, "saved")
+	testutil.Check("lose third disk", os.Rename(disks[2], saved))
+	if _, err = read(store, name, 0, -1); err != erasure.ErrTooFewShards {
+		err = fmt.Errorf("read with three disks lost returned %v", err)
+	} else {
+		err = nil
+	}
+	testutil.Check("detect too many lost shards", err)
+	testutil.Check("restore third disk", os.Rename(saved, disks[2]))
+
+	// 4. 更换磁盘后重建分片: 重建后再丢失另外两个磁盘仍可读取
+	testutil.Check("replace disks", replaceDisk(disks[1]))
+	testutil.Check("replace disks", replaceDisk(disks[4]))
+	names, err := store.Names()
+	if err == nil && strings.Join(names, ",") != "ab/0123456789abcdef0123456789abcdef01234567,empty,exact" {
+		err = fmt.Errorf("names %v", names)
+	}
+	testutil.Check("list objects", err)
+	rebuilt := 0
+	for _, n := range names {
+		count, err := store.Rebuild(n)
+		if err != nil {
+			testutil.Check("rebuild "+n, err)
+		}
+		rebuilt += count
+	}
+	err = nil
+	if rebuilt != 6 || len(shardFiles(disks, name)) != 6 {
+		err = fmt.Errorf("rebuilt %d shards, %d shard files", rebuilt, len(shardFiles(disks, name)))
+	}
+	testutil.Check("rebuild replaced disks", err)
+	testutil.Check("lose other disks", replaceDisk(disks[0]))
+	testutil.Check("lose other disks", replaceDisk(disks[5]))
+	testutil.Check("read rebuilt shards", expect(store, name, data))
+	testutil.Check("read rebuilt full stripes", expect(store, "exact", exact))
+	for _, n := range names {
+		if _, err := store.Rebuild(n); err != nil {
+			testutil.Check("rebuild "+n, err)
+		}
+	}
+
+	// 5. 损坏的块由CRC32发现, 读取时以校验分片重建, Rebuild只重写该分片
+	files = shardFiles(disks, name)
+	fd, err := os.OpenFile(files[0], os.O_RDWR, 0)
+	if err == nil {
+		_, err = fd.WriteAt([]byte("garbage"), 32+1500)
+		fd.Close()
+	}
+	testutil.Check("corrupt chunk", err)
+	testutil.Check("read with corrupt chunk", expect(store, name, data))
+	count, err := store.Rebuild(name)
+	if err == nil && count != 1 {
+		err = fmt.Errorf("rebuilt %d shards, expect 1", count)
+	}
+	testutil.Check("rebuild corrupt shard", err)
+	count, err = store.Rebuild(name)
+	if err == nil && count != 0 {
+		err = fmt.Errorf("rebuilt %d shards of healthy object", count)
+	}
+	testutil.Check("healthy object unchanged", err)
+
+	// 截断或头部损坏的分片视为缺失
+	testutil.Check("truncate shard", os.Truncate(files[1], 100))
+	testutil.Check("damage header", ioutil.WriteFile(files[2], []byte("not a shard"), 0644))
+	testutil.Check("read with damaged shards", expect(store, name, data))
+	count, err = store.Rebuild(name)
+	if err == nil && count != 2 {
+		err = fmt.Errorf("rebuilt %d shards, expect 2", count)
+	}
+	testutil.Check("rebuild damaged shards", err)
+
+	// 6. 修改分片配置及增加磁盘后, 已有对象以写入时的参数读取
+	wider, err := erasure.New(disks, 5, 2, 700)
+	testutil.Check("create store with new config", err)
+	testutil.Check("read with new config", expect(wider, name, data))
+	testutil.Check("remove disk of old layout", replaceDisk(disks[3]))
+	count, err = wider.Rebuild(name)
+	if err == nil && count != 1 {
+		err = fmt.Errorf("rebuilt %d shards, expect 1", count)
+	}
+	if err == nil {
+		err = expect(wider, name, data)
+	}
+	testutil.Check("rebuild with new config", err)
+	other := data[:12345]
+	testutil.Check("put with new config", wider.Put("other", bytes.NewReader(other)))
+	testutil.Check("read with new config", expect(wider, "other", other))
+	if len(shardFiles(disks, "other")) != 7 {
+		err = fmt.Errorf("%d shard files, expect 7", len(shardFiles(disks, "other")))
+	}
+	testutil.Check("write new shard count", err)
+
+	// 7. 删除对象的所有分片, 不存在的对象返回os.ErrNotExist
+	testutil.Check("remove object", wider.Remove(name))
+	err = nil
+	if files := shardFiles(disks, name); len(files) != 0 {
+		err = fmt.Errorf("%d shard files left", len(files))
+	} else if _, err = store.Open(name, 0, -1); !os.IsNotExist(err) {
+		err = fmt.Errorf("open removed object returned %v", err)
+	} else {
+		err = nil
+	}
+	testutil.Check("open removed object", err)
+	testutil.Check("remove missing object", wider.Remove(name))
+	if err = store.Put("../escape", bytes.NewReader(data)); err == nil {
+		err = errors.New("accepted name outside dirs")
+	} else {
+		err = nil
+	}
+	testutil.Check("reject invalid name", err)
+}
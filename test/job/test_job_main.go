@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloud/job"
+	"github.com/cloud/test/testutil"
+)
+
+// 使用内存存储测试任务框架:
+// go run ./test/job
+
+// queue : 记录发布的任务
+type queue struct {
+	mu        sync.Mutex
+	published []job.Job
+}
+
+func (q *queue) Publish(j *job.Job) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.published = append(q.published, *j)
+	return true
+}
+
+// drain : 取出已发布的任务
+func (q *queue) drain() []job.Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	list := q.published
+	q.published = nil
+	return list
+}
+
+// echoPayload : 测试任务的参数
+type echoPayload struct {
+	Name  string `job:"required"`
+	Count int
+	Tags  []string `json:"tags,omitempty"`
+}
+
+// Validate : Count不能为负
+func (p *echoPayload) Validate() error {
+	if p.Count < 0 {
+		return errors.New("count must not be negative")
+	}
+	return nil
+}
+
+const (
+	typeEcho = "test.echo"
+	typeBusy = "test.busy"
+)
+
+func init() {
+	job.Define(typeEcho, echoPayload{}, job.Options{
+		Priority:    3,
+		MaxAttempts: 3,
+		Timeout:     time.Second,
+		Backoff:     []time.Duration{time.Minute, 5 * time.Minute},
+	})
+	job.Define(typeBusy, echoPayload{}, job.Options{Concurrency: 2})
+}
+
+// snapshot : 任务表中任务的当前状态
+func snapshot(store *job.MemoryStore, id int64) job.Job {
+	j, err := store.Get(id)
+	if err != nil {
+		return job.Job{}
+	}
+	return *j
+}
+
+// expectJob : 检查任务的状态及执行次数
+func expectJob(j job.Job, status, attempts int) error {
+	if j.Status != status || j.Attempts != attempts {
+		return fmt.Errorf("job %d: status %d attempts %d (%s), expected %d/%d",
+			j.ID, j.Status, j.Attempts, j.LastError, status, attempts)
+	}
+	return nil
+}
+
+func main() {
+	clk := testutil.NewClock(time.Now())
+	store := job.NewMemoryStore()
+	q := &queue{}
+	client := job.NewClient(store, q.Publish, 10*time.Minute)
+	client.Now = clk.Now
+	w := job.NewWorker(store, q.Publish, 10*time.Minute)
+	w.Now = clk.Now
+
+	// echo任务按Name决定结果: fail-once第一次失败, fail总是失败, panic时panic
+	var echoRuns int32
+	w.Handle(typeEcho, func(ctx context.Context, payload interface{}) error {
+		p := payload.(*echoPayload)
+		n := atomic.AddInt32(&echoRuns, 1)
+		switch {
+		case p.Name == "fail-once" && n == 1, p.Name == "fail":
+			return errors.New("echo failed")
+		case p.Name == "panic":
+			panic("echo panicked")
+		}
+		return nil
+	})
+
+	// 1. 提交时校验参数
+	_, err := client.Enqueue("test.unknown", echoPayload{Name: "x"}, job.EnqueueOptions{})
+	if err != job.ErrUnknownType {
+		err = fmt.Errorf("expected ErrUnknownType, got %v", err)
+	} else {
+		err = nil
+	}
+	invalid := []interface{}{
+		echoPayload{},
+		echoPayload{Name: "x", Count: -1},
+		json.RawMessage(`{"Name":"x","Unknown":1}`),
+		json.RawMessage(`{"Name":null}`),
+		struct{ Name string }{"x"},
+	}
+	for i, payload := range invalid {
+		if err == nil {
+			if _, e := client.Enqueue(typeEcho, payload, job.EnqueueOptions{}); e == nil {
+				err = fmt.Errorf("invalid payload %d accepted", i)
+			}
+		}
+	}
+	if err == nil && len(q.drain()) != 0 {
+		err = fmt.Errorf("invalid jobs were published")
+	}
+	testutil.Check("validate payload", err)
+
+	// 2. 相同幂等键只创建及发布一次, 发布时带优先级
+	first, err := client.Enqueue(typeEcho, &echoPayload{Name: "fail-once"},
+		job.EnqueueOptions{IdempotencyKey: "k1"})
+	var again *job.Job
+	if err == nil {
+		again, err = client.Enqueue(typeEcho, json.RawMessage(`{"Name":"fail-once"}`),
+			job.EnqueueOptions{IdempotencyKey: "k1", Priority: 9})
+	}
+	published := q.drain()
+	if err == nil && again.ID != first.ID {
+		err = fmt.Errorf("duplicate job created: %d, %d", first.ID, again.ID)
+	}
+	if err == nil && (len(published) != 1 || published[0].Priority != 3) {
+		err = fmt.Errorf("unexpected publishes %+v", published)
+	}
+	if err == nil {
+		_, err = client.Enqueue(typeEcho, echoPayload{Name: "urgent"}, job.EnqueueOptions{Priority: 8})
+		if published = q.drain(); err == nil && (len(published) != 1 || published[0].Priority != 8) {
+			err = fmt.Errorf("priority override not published: %+v", published)
+		}
+	}
+	testutil.Check("idempotency key and priority", err)
+
+	// 3. 执行失败后按退避计划重试, 未到时间不重新入队
+	testutil.Check("Process", w.Process(first.ID))
+	err = expectJob(snapshot(store, first.ID), job.StatusPending, 1)
+	if j := snapshot(store, first.ID); err == nil && (!j.NextRunAt.Equal(clk.Now().Add(time.Minute)) || j.LastError != "echo failed") {
+		err = fmt.Errorf("unexpected retry state %+v", j)
+	}
+	if err == nil {
+		err = w.Tick()
+	}
+	if published = q.drain(); err == nil && len(published) != 0 {
+		err = fmt.Errorf("job requeued before backoff: %+v", published)
+	}
+	testutil.Check("schedule retry", err)
+
+	clk.Advance(time.Minute)
+	err = w.Tick()
+	published = q.drain()
+	if err == nil && (len(published) != 1 || published[0].ID != first.ID) {
+		err = fmt.Errorf("job was not requeued: %+v", published)
+	}
+	if err == nil {
+		msg, _ := json.Marshal(job.Message{ID: first.ID, Type: typeEcho})
+		if !w.HandleMessage(msg) {
+			err = fmt.Errorf("HandleMessage failed")
+		}
+	}
+	if err == nil {
+		err = expectJob(snapshot(store, first.ID), job.StatusSucceeded, 2)
+	}
+	if err == nil && w.Process(first.ID) == nil {
+		err = expectJob(snapshot(store, first.ID), job.StatusSucceeded, 2)
+	}
+	testutil.Check("retry succeeds", err)
+
+	// 4. 执行次数用完后标记为失败; panic记为执行失败
+	for _, name := range []string{"fail", "panic"} {
+		j, err := client.Enqueue(typeEcho, echoPayload{Name: name}, job.EnqueueOptions{})
+		for i := 0; err == nil && i < 3; i++ {
+			if err = w.Process(j.ID); err == nil {
+				clk.Advance(5 * time.Minute)
+				err = w.Tick()
+			}
+		}
+		if err == nil {
+			err = expectJob(snapshot(store, j.ID), job.StatusFailed, 3)
+		}
+		if err == nil && name == "panic" && snapshot(store, j.ID).LastError != "panic: echo panicked" {
+			err = fmt.Errorf("unexpected error %q", snapshot(store, j.ID).LastError)
+		}
+		testutil.Check("give up after max attempts: "+name, err)
+	}
+	q.drain()
+	failed, err := client.Enqueue(typeEcho, echoPayload{Name: "fail"}, job.EnqueueOptions{IdempotencyKey: "k-fail"})
+	for i := 0; err == nil && i < 3; i++ {
+		if err = w.Process(failed.ID); err == nil {
+			clk.Advance(5 * time.Minute)
+			err = w.Tick()
+		}
+	}
+	if err == nil {
+		err = expectJob(snapshot(store, failed.ID), job.StatusFailed, 3)
+	}
+	q.drain()
+	if err == nil {
+		again, err = client.Enqueue(typeEcho, echoPayload{Name: "fail"}, job.EnqueueOptions{IdempotencyKey: "k-fail"})
+	}
+	if err == nil && again.ID != failed.ID {
+		err = fmt.Errorf("failed job not reused: %d, %d", failed.ID, again.ID)
+	}
+	if err == nil {
+		err = expectJob(snapshot(store, failed.ID), job.StatusPending, 0)
+	}
+	if published = q.drain(); err == nil && len(published) != 1 {
+		err = fmt.Errorf("resubmitted job not published: %+v", published)
+	}
+	testutil.Check("resubmit failed job with the same idempotency key", err)
+	q.drain()
+
+	// 5. 保存的参数无效时直接失败, 不重试
+	bad := store.Insert(&job.Job{Type: typeEcho, Payload: `{"Count":1}`, MaxAttempts: 3,
+		NextRunAt: clk.Now().Add(10 * time.Minute)})
+	err = w.Process(bad.ID)
+	if err == nil {
+		err = expectJob(snapshot(store, bad.ID), job.StatusFailed, 1)
+	}
+	testutil.Check("invalid stored payload", err)
+
+	// 6. 执行中的任务租约过期后重新入队, 执行次数用完时标记为失败
+	crashed, err := client.Enqueue(typeEcho, echoPayload{Name: "crashed"}, job.EnqueueOptions{})
+	q.drain()
+	if err == nil {
+		var claimed bool
+		claimed, err = store.Transit(crashed, job.StatusRunning, clk.Now().Add(time.Minute), "")
+		if err == nil && !claimed {
+			err = fmt.Errorf("claim failed")
+		}
+	}
+	if err == nil {
+		clk.Advance(2 * time.Minute)
+		err = w.Tick()
+	}
+	published = q.drain()
+	if err == nil {
+		err = expectJob(snapshot(store, crashed.ID), job.StatusPending, 1)
+	}
+	if err == nil && (len(published) != 1 || snapshot(store, crashed.ID).LastError != "job lease expired") {
+		err = fmt.Errorf("expired job was not requeued: %+v", snapshot(store, crashed.ID))
+	}
+	if err == nil {
+		err = w.Process(crashed.ID)
+	}
+	if err == nil {
+		err = expectJob(snapshot(store, crashed.ID), job.StatusSucceeded, 2)
+	}
+	testutil.Check("requeue after lease expired", err)
+
+	// 7. 同一类型同时执行的任务数不超过Concurrency
+	var running, maxRunning int32
+	w.Handle(typeBusy, func(ctx context.Context, payload interface{}) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil
+	})
+	ids := []int64{}
+	for i := 0; i < 6; i++ {
+		j, err := client.Enqueue(typeBusy, echoPayload{Name: fmt.Sprint(i)}, job.EnqueueOptions{})
+		testutil.Check(fmt.Sprintf("enqueue busy job %d", i), err)
+		ids = append(ids, j.ID)
+	}
+	wg := sync.WaitGroup{}
+	errs := make(chan error, len(ids))
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id int64) {
+			defer wg.Done()
+			errs <- w.Process(id)
+		}(id)
+	}
+	wg.Wait()
+	close(errs)
+	for e := range errs {
+		if e != nil && err == nil {
+			err = e
+		}
+	}
+	for _, id := range ids {
+		if err == nil {
+			err = expectJob(snapshot(store, id), job.StatusSucceeded, 1)
+		}
+	}
+	if err == nil && maxRunning != 2 {
+		err = fmt.Errorf("max concurrent jobs %d, expected 2", maxRunning)
+	}
+	testutil.Check("concurrency limit", err)
+
+	// 8. 参数的JSON Schema
+	def, _ := job.Lookup(typeEcho)
+	data, err := json.Marshal(def.Schema())
+	want := `{"type":"object","properties":{"Count":{"type":"integer"},"Name":{"type":"string"},` +
+		`"tags":{"type":"array","items":{"type":"string"}}},"required":["Name"],"additionalProperties":false}`
+	if err == nil && string(data) != want {
+		err = fmt.Errorf("unexpected schema %s", data)
+	}
+	testutil.Check("payload schema", err)
+
+	// 9. 内置的转移任务
+	def, ok := job.Lookup(job.TypeTransfer)
+	if !ok {
+		err = fmt.Errorf("transfer job type is not defined")
+	} else if _, err = def.Decode([]byte(`{"FileHash":"abc","Location":"/tmp/abc"}`)); err == nil {
+		err = fmt.Errorf("transfer payload without DestLocation accepted")
+	} else {
+		err = nil
+	}
+	testutil.Check("transfer job type", err)
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloud/store/layout"
+	"github.com/cloud/test/testutil"
+)
+
+// 测试本地存储的分级目录布局: 路径分级, 平铺文件迁移(文件表更新、中断后重新执行、冲突),
+// 分块目录迁移及列举, 启动检查删除写入中断的临时文件. 存储根目录以临时目录代替:
+// go run ./test/layout
+
+// expectFile : path的内容须为data
+func expectFile(path, data string) error {
+	got, err := ioutil.ReadFile(path)
+	if err == nil && string(got) != data {
+		err = fmt.Errorf("%s content mismatch", path)
+	}
+	return err
+}
+
+// missing : path须不存在
+func missing(path string) error {
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return fmt.Errorf("%s still exists", path)
+	}
+	return nil
+}
+
+func main() {
+	root, err := ioutil.TempDir("", "layout")
+	testutil.Check("create temp dir", err)
+	defer os.RemoveAll(root)
+	merge := filepath.Join(root, "merge") + "/"
+	chunk := filepath.Join(root, "chunk") + "/"
+	testutil.Check("create roots", os.MkdirAll(merge, 0744))
+	testutil.Check("create roots", os.MkdirAll(chunk, 0744))
+
+	// 1. 路径按名称前4个字符分级, 非hex名称以其sha1分级
+	hash1 := "abcdef0123456789abcdef0123456789abcdef01"
+	hash2 := "0123456789abcdef0123456789abcdef01234567"
+	hash3 := "ffff000000000000000000000000000000000000"
+	err = nil
+	if p := layout.Path(merge, hash1); p != merge+"ab/cd/"+hash1 {
+		err = fmt.Errorf("path %s", p)
+	} else if f := layout.Fanout("../x"); strings.Contains(f[:6], ".") || !strings.HasSuffix(f, "/../x") {
+		err = fmt.Errorf("fanout of non-hex name %s", f)
+	} else if s := layout.Sharded(merge, merge+hash1); s != merge+"ab/cd/"+hash1 {
+		err = fmt.Errorf("sharded %s", s)
+	} else if s := layout.Sharded(merge, merge+"ab/cd/"+hash1); s != "" {
+		err = fmt.Errorf("sharded path of sharded path %s", s)
+	}
+	testutil.Check("fanout path", err)
+
+	// 2. Rename创建上级目录后移动
+	tmp := filepath.Join(root, "upload.tmp")
+	testutil.Check("write temp file", ioutil.WriteFile(tmp, []byte("renamed"), 0644))
+	testutil.Check("rename into layout", layout.Rename(tmp, layout.Path(merge, hash3)))
+	testutil.Check("read renamed file", expectFile(merge+"ff/ff/"+hash3, "renamed"))
+	testutil.Check("temp file moved", missing(tmp))
+
+	// 3. 迁移平铺的文件: 文件表或副本位置记录为原路径的更新(同一次update), 其他(已转移)的只移动.
+	// hash5的文件表指向OSS上的主存储, 副本位置及完整性检查记录仍为本地的平铺路径
+	hash5 := "5555555555555555555555555555555555555555"
+	testutil.Check("write flat files", ioutil.WriteFile(merge+hash1, []byte("one"), 0644))
+	testutil.Check("write flat files", ioutil.WriteFile(merge+hash2, []byte("two"), 0644))
+	testutil.Check("write flat files", ioutil.WriteFile(merge+hash5, []byte("five"), 0644))
+	testutil.Check("write flat files", ioutil.WriteFile(merge+"notahash", []byte("x"), 0644))
+	testutil.Check("write flat files", ioutil.WriteFile(merge+hash1+".tmp", []byte("half"), 0644))
+	table := map[string]string{hash1: merge + hash1, hash2: "oss/" + hash2, hash5: "oss/" + hash5}
+	replicas := map[string]map[string]string{hash5: {"location": merge + hash5, "scrub": merge + hash5, "oss": "oss/" + hash5}}
+	update := func(filehash, from, to string) (bool, error) {
+		if _, err := os.Stat(to); err != nil {
+			return false, errors.New("updated before the new path exists")
+		}
+		updated := false
+		if table[filehash] == from {
+			table[filehash] = to
+			updated = true
+		}
+		for name, loc := range replicas[filehash] {
+			if loc == from {
+				replicas[filehash][name] = to
+				updated = true
+			}
+		}
+		return updated, nil
+	}
+	res, err := layout.Migrate(merge, update)
+	if err == nil && (res.Moved != 3 || res.Updated != 2 || len(res.Skipped) != 1 || res.Skipped[0] != merge+"notahash") {
+		err = fmt.Errorf("result %+v", res)
+	}
+	testutil.Check("migrate flat files", err)
+	testutil.Check("read migrated file", expectFile(merge+"ab/cd/"+hash1, "one"))
+	testutil.Check("read migrated file", expectFile(merge+"01/23/"+hash2, "two"))
+	testutil.Check("flat file removed", missing(merge+hash1))
+	err = nil
+	if table[hash1] != merge+"ab/cd/"+hash1 || table[hash2] != "oss/"+hash2 {
+		err = fmt.Errorf("file table %v", table)
+	}
+	testutil.Check("update file table", err)
+	err = nil
+	if rep := replicas[hash5]; table[hash5] != "oss/"+hash5 || rep["location"] != merge+"55/55/"+hash5 ||
+		rep["scrub"] != merge+"55/55/"+hash5 || rep["oss"] != "oss/"+hash5 {
+		err = fmt.Errorf("replicated file table %s, replicas %v", table[hash5], rep)
+	}
+	testutil.Check("update replica locations", err)
+	testutil.Check("read migrated replica", expectFile(merge+"55/55/"+hash5, "five"))
+	testutil.Check("flat replica removed", missing(merge+hash5))
+	testutil.Check("keep temp file", expectFile(merge+hash1+".tmp", "half"))
+
+	// 4. 上次迁移在更新文件表前中断: 新路径已有同一文件, 重新执行时完成迁移
+	hash4 := hash3[:39] + "e"
+	testutil.Check("write flat file", ioutil.WriteFile(merge+hash4, []byte("three"), 0644))
+	testutil.Check("interrupted link", os.Link(merge+hash4, layout.Path(merge, hash4)))
+	table[hash4] = merge + hash4
+	res, err = layout.Migrate(merge, update)
+	if err == nil && (res.Moved != 1 || res.Updated != 1) {
+		err = fmt.Errorf("result %+v", res)
+	}
+	testutil.Check("resume interrupted migration", err)
+	testutil.Check("read resumed file", expectFile(layout.Path(merge, hash4), "three"))
+	testutil.Check("flat file removed", missing(merge+hash4))
+
+	// 分级路径下已有长度不同的文件时保留两者
+	testutil.Check("write flat file", ioutil.WriteFile(merge+hash3, []byte("conflict!"), 0644))
+	res, err = layout.Migrate(merge, update)
+	if err == nil && (res.Moved != 0 || len(res.Skipped) != 2) {
+		err = fmt.Errorf("result %+v", res)
+	}
+	testutil.Check("skip conflicting file", err)
+	testutil.Check("keep conflicting file", expectFile(merge+hash3, "conflict!"))
+	testutil.Check("keep conflicting file", expectFile(layout.Path(merge, hash3), "renamed"))
+	testutil.Check("remove conflicting file", os.Remove(merge+hash3))
+	res, err = layout.Migrate(merge, update)
+	if err == nil && (res.Moved != 0 || res.Updated != 0) {
+		err = fmt.Errorf("result %+v", res)
+	}
+	testutil.Check("migrate again", err)
+
+	// 5. 分块目录: 平铺及分级的目录都能列出, 迁移后均为分级目录
+	upload1 := "0123456789abcdef0123456789abcdef"
+	upload2 := "fedcba9876543210fedcba9876543210"
+	testutil.Check("create flat chunk dir", os.MkdirAll(chunk+upload1, 0744))
+	testutil.Check("write chunk", ioutil.WriteFile(chunk+upload1+"/0", []byte("chunk"), 0644))
+	testutil.Check("create sharded chunk dir", os.MkdirAll(layout.Path(chunk, upload2), 0744))
+	dirs, err := layout.ChunkDirs(chunk)
+	sort.Strings(dirs)
+	if err == nil && strings.Join(dirs, ",") != chunk+upload1+","+chunk+"fe/dc/"+upload2 {
+		err = fmt.Errorf("chunk dirs %v", dirs)
+	}
+	testutil.Check("list chunk dirs", err)
+	moved, err := layout.MigrateChunks(chunk)
+	if err == nil && moved != 1 {
+		err = fmt.Errorf("moved %d chunk dirs", moved)
+	}
+	testutil.Check("migrate chunk dirs", err)
+	testutil.Check("read moved chunk", expectFile(chunk+"01/23/"+upload1+"/0", "chunk"))
+	dirs, err = layout.ChunkDirs(chunk)
+	sort.Strings(dirs)
+	if err == nil && strings.Join(dirs, ",") != chunk+"01/23/"+upload1+","+chunk+"fe/dc/"+upload2 {
+		err = fmt.Errorf("chunk dirs %v", dirs)
+	}
+	testutil.Check("list migrated chunk dirs", err)
+
+	// 6. 启动检查: 删除早于grace的临时文件, 保留较新的, 统计尚未迁移的文件
+	stale := layout.Path(merge, hash2) + ".tmp"
+	testutil.Check("write stale temp file", ioutil.WriteFile(stale, []byte("half"), 0644))
+	old := time.Now().Add(-2 * time.Hour)
+	testutil.Check("age temp files", os.Chtimes(stale, old, old))
+	testutil.Check("age temp files", os.Chtimes(merge+hash1+".tmp", old, old))
+	recent := layout.Path(merge, hash1) + ".tmp"
+	testutil.Check("write recent temp file", ioutil.WriteFile(recent, []byte("writing"), 0644))
+	testutil.Check("write flat file", ioutil.WriteFile(merge+hash3, []byte("renamed"), 0644))
+	report, err := layout.Check(merge, time.Hour)
+	if err == nil && (len(report.Removed) != 2 || len(report.Recent) != 1 || report.Recent[0] != recent || report.Flat != 1) {
+		err = fmt.Errorf("report %+v", report)
+	}
+	testutil.Check("check half-written files", err)
+	testutil.Check("stale temp file removed", missing(stale))
+	testutil.Check("stale temp file removed", missing(merge+hash1+".tmp"))
+	testutil.Check("keep recent temp file", expectFile(recent, "writing"))
+	testutil.Check("keep complete files", expectFile(layout.Path(merge, hash2), "two"))
+	report, err = layout.Check(filepath.Join(root, "none"), time.Hour)
+	if err == nil && len(report.Removed) != 0 {
+		err = fmt.Errorf("report %+v", report)
+	}
+	testutil.Check("check missing root", err)
+}
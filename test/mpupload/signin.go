@@ -44,6 +44,8 @@ type MultipartUploadInfo struct {
 	ChunkCount int
 	// 已经存在的分块，告诉客户端可以跳过这些分块，无需重复上传
 	ChunkExists []int
+	// 服务端建议的最大并发上传分块数
+	MaxParallel int
 }
 
 // UploadInitResponse : 初始化接口返回的数据
@@ -98,7 +98,7 @@ func cancelTestMain() {
 		"&token=" + token + "&uploadid=" + uploadID
 	// 只上传第一个分块后，取消上传
 	uploadChunkCount = 1
-	uploadPartsSpecified(uploadFilePath, tURL, chunkSize, []int{1})
+	uploadPartsSpecified(uploadFilePath, tURL, chunkSize, []int{0})
 
 	// 4. 取消分块上传接口
 	resp, err = http.PostForm(
@@ -104,7 +104,7 @@ func normalTestMain() {
 		os.Exit(-1)
 	}
 	var chunksToUpload []int
-	for idx := 1; idx <= initResp.Data.ChunkCount; idx++ {
+	for idx := 0; idx < initResp.Data.ChunkCount; idx++ {
 		chunksToUpload = append(chunksToUpload, idx)
 	}
 	uploadChunkCount = len(chunksToUpload)
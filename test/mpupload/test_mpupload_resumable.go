@@ -68,7 +68,7 @@ func uploadFile(username, token, fhash, fpath string, filesize int) string {
 			return ""
 		}
 		var chunksToUpload []int
-		for idx := 1; idx <= initResp.Data.ChunkCount; idx++ {
+		for idx := 0; idx < initResp.Data.ChunkCount; idx++ {
 			if len(chunksToUpload) >= uploadChunkCount {
 				break
 			}
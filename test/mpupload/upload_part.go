@@ -28,18 +28,20 @@ func uploadPartsSpecified(filename string, targetURL string, chunkSize int, chun
 	ch := make(chan int)
 	buf := make([]byte, chunkSize) //每次读取chunkSize大小的内容
 	for {
-		n, err := bfRd.Read(buf)
+		n, err := io.ReadFull(bfRd, buf)
 		if n <= 0 {
 			break
 		}
+		// 分块索引从0开始
+		curIdx := index
 		index++
 
 		// 判断当前所在的块是否需要上传
-		if contained, err := util.Contain(chunkIdxs, index); err != nil || !contained {
+		if contained, err := util.Contain(chunkIdxs, curIdx); err != nil || !contained {
 			continue
 		}
 
-		bufCopied := make([]byte, 5*1048576)
+		bufCopied := make([]byte, chunkSize)
 		copy(bufCopied, buf)
 
 		go func(b []byte, curIdx int) {
@@ -58,11 +60,11 @@ func uploadPartsSpecified(filename string, targetURL string, chunkSize int, chun
 			resp.Body.Close()
 
 			ch <- curIdx
-		}(bufCopied[:n], index)
+		}(bufCopied[:n], curIdx)
 
 		//遇到任何错误立即返回，并忽略 EOF 错误信息
 		if err != nil {
-			if err == io.EOF {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
 				break
 			} else {
 				fmt.Println(err.Error())
@@ -0,0 +1,164 @@
+// Package memproxy : 进程内的dbproxy, 用于测试调用上传接口等依赖dbproxy的代码
+package memproxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/micro/go-micro/client"
+
+	"github.com/cloud/service/dbproxy/orm"
+	dbProto "github.com/cloud/service/dbproxy/proto"
+)
+
+// UserFile : 用户文件表中的一条记录
+type UserFile struct {
+	UserName string
+	FileHash string
+	FileName string
+	FileSize int64
+}
+
+// Proxy : 进程内的dbproxy, 以内存中的表实现上传接口及本地存储读写用到的action,
+// 通过dbcli.SetService替换rpc客户端. 其他action返回失败
+type Proxy struct {
+	mu           sync.Mutex
+	files        map[string]orm.TableFile
+	userFiles    []UserFile
+	keys         map[string]orm.TableFileKey
+	compressions map[string]orm.TableFileCompression
+}
+
+// New : 创建空表的Proxy
+func New() *Proxy {
+	return &Proxy{
+		files:        map[string]orm.TableFile{},
+		keys:         map[string]orm.TableFileKey{},
+		compressions: map[string]orm.TableFileCompression{},
+	}
+}
+
+// File : 文件表中的记录
+func (p *Proxy) File(filehash string) (orm.TableFile, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f, ok := p.files[filehash]
+	return f, ok
+}
+
+// Owners : 用户文件表中的记录
+func (p *Proxy) Owners() []UserFile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]UserFile(nil), p.userFiles...)
+}
+
+// ExecuteAction : 实现dbProto.DBProxyService, 与dbproxy相同地按顺序执行action并返回各自的结果
+func (p *Proxy) ExecuteAction(ctx context.Context, in *dbProto.ReqExec, opts ...client.CallOption) (*dbProto.RespExec, error) {
+	results := []orm.ExecResult{}
+	for _, action := range in.Action {
+		args := []json.RawMessage{}
+		if err := json.Unmarshal(action.Params, &args); err != nil {
+			return nil, err
+		}
+		res, err := p.exec(action.Name, args)
+		if err != nil {
+			res = orm.ExecResult{Suc: false, Msg: err.Error()}
+		}
+		results = append(results, res)
+	}
+	data, err := json.Marshal(results)
+	if err != nil {
+		return nil, err
+	}
+	return &dbProto.RespExec{Data: data}, nil
+}
+
+// decode : 按顺序解码action参数
+func decode(args []json.RawMessage, dst ...interface{}) error {
+	if len(args) != len(dst) {
+		return fmt.Errorf("expected %d params, got %d", len(dst), len(args))
+	}
+	for i := range dst {
+		if err := json.Unmarshal(args[i], dst[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exec : 执行一个action
+func (p *Proxy) exec(name string, args []json.RawMessage) (orm.ExecResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var filehash string
+	var storeType int
+	switch name {
+	case "/file/OnFileUploadFinished":
+		f := orm.TableFile{}
+		if err := decode(args, &f.FileHash, &f.FileName.String, &f.FileSize.Int64, &f.FileAddr.String); err != nil {
+			return orm.ExecResult{}, err
+		}
+		// 与insert ignore相同, 已有记录时不覆盖
+		if _, ok := p.files[f.FileHash]; !ok {
+			f.FileName.Valid, f.FileSize.Valid, f.FileAddr.Valid = true, true, true
+			p.files[f.FileHash] = f
+		}
+		return orm.ExecResult{Suc: true}, nil
+
+	case "/ufile/OnUserFileUploadFinished":
+		uf := UserFile{}
+		if err := decode(args, &uf.UserName, &uf.FileHash, &uf.FileName, &uf.FileSize); err != nil {
+			return orm.ExecResult{}, err
+		}
+		p.userFiles = append(p.userFiles, uf)
+		return orm.ExecResult{Suc: true}, nil
+
+	case "/file/GetFileKey", "/file/RemoveFileKey", "/file/GetFileCompression", "/file/RemoveFileCompression":
+		if err := decode(args, &filehash, &storeType); err != nil {
+			return orm.ExecResult{}, err
+		}
+		id := fmt.Sprintf("%s#%d", filehash, storeType)
+		res := orm.ExecResult{Suc: true}
+		switch name {
+		case "/file/GetFileKey":
+			if key, ok := p.keys[id]; ok {
+				res.Data = key
+			}
+		case "/file/RemoveFileKey":
+			delete(p.keys, id)
+		case "/file/GetFileCompression":
+			if info, ok := p.compressions[id]; ok {
+				res.Data = info
+			}
+		case "/file/RemoveFileCompression":
+			delete(p.compressions, id)
+		}
+		return res, nil
+
+	case "/file/CreateFileKey":
+		key := orm.TableFileKey{}
+		if err := decode(args, &key.FileHash, &key.StoreType, &key.KeyID, &key.WrappedKey, &key.ChunkSize); err != nil {
+			return orm.ExecResult{}, err
+		}
+		// 已有记录时不覆盖, 返回保存的记录
+		id := fmt.Sprintf("%s#%d", key.FileHash, key.StoreType)
+		if _, ok := p.keys[id]; !ok {
+			p.keys[id] = key
+		}
+		return orm.ExecResult{Suc: true, Data: p.keys[id]}, nil
+
+	case "/file/SaveFileCompression":
+		info := orm.TableFileCompression{}
+		if err := decode(args, &info.FileHash, &info.StoreType, &info.Codec, &info.Level, &info.FrameSize,
+			&info.LogicalSize, &info.StoredSize); err != nil {
+			return orm.ExecResult{}, err
+		}
+		p.compressions[fmt.Sprintf("%s#%d", info.FileHash, info.StoreType)] = info
+		return orm.ExecResult{Suc: true}, nil
+	}
+	return orm.ExecResult{}, fmt.Errorf("action %s is not supported", name)
+}
@@ -0,0 +1,511 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cloud/cache/redis"
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	"github.com/cloud/job"
+	"github.com/cloud/mq"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/service/job/tasks"
+	"github.com/cloud/service/upload/api"
+	"github.com/cloud/store/backend"
+	"github.com/cloud/store/layout"
+	"github.com/cloud/store/policy"
+	"github.com/cloud/test/mq/memproxy"
+	"github.com/cloud/test/testutil"
+)
+
+// 测试消息队列后端及进程内按存储策略的上传->转移流程(上传接口->任务队列->转移任务):
+// go run ./test/mq
+// 内存队列总是测试; redis及rabbitmq不可用时跳过
+
+// waitFor : 等待cond成立, 超时返回false
+func waitFor(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return cond()
+}
+
+// received : 记录收到的消息
+type received struct {
+	mu     sync.Mutex
+	bodies []string
+}
+
+func (r *received) add(body []byte) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies = append(r.bodies, string(body))
+	return len(r.bodies)
+}
+
+func (r *received) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.bodies...)
+}
+
+// consumer : 在后台消费队列, 返回停止函数, 停止后返回Consume的结果
+func consumer(b mq.Broker, queue string, concurrency int, handler mq.Handler) func() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Consume(ctx, queue, "test", concurrency, handler)
+	}()
+	return func() error {
+		cancel()
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(5 * time.Second):
+			return errors.New("consume did not return after cancel")
+		}
+	}
+}
+
+// expectBodies : 比较收到的消息, ordered为false时不比较顺序
+func expectBodies(got []string, ordered bool, want ...string) error {
+	if !ordered {
+		got = append([]string(nil), got...)
+		want = append([]string(nil), want...)
+		sort.Strings(got)
+		sort.Strings(want)
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		return fmt.Errorf("received %v, expected %v", got, want)
+	}
+	return nil
+}
+
+// testBroker : 各后端共同的行为. priority为false的后端按发布顺序投递
+func testBroker(name string, b mq.Broker, priority bool) {
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	ex := "test.mq." + suffix
+	qa1, qa2, qb := ex+".a1", ex+".a2", ex+".b"
+
+	// 1. 按routing key投递到绑定的全部队列, 未绑定的丢弃
+	err := b.DeclareQueue(ex, qa1, "a", 9)
+	if err == nil {
+		err = b.DeclareQueue(ex, qa2, "a", 0)
+	}
+	if err == nil {
+		err = b.DeclareQueue(ex, qb, "b", 0)
+	}
+	for _, m := range []struct {
+		key, body string
+		priority  uint8
+	}{{"a", "m1", 0}, {"c", "lost", 0}, {"b", "m2", 0}, {"a", "low", 1}, {"a", "high", 5}} {
+		if err == nil {
+			err = b.Publish(ex, m.key, []byte(m.body), m.priority)
+		}
+	}
+	testutil.Check(name+": declare and publish", err)
+
+	r1 := &received{}
+	stop := consumer(b, qa1, 1, func(d mq.Delivery) {
+		r1.add(d.Body())
+		d.Ack()
+	})
+	waitFor(3*time.Second, func() bool { return len(r1.list()) >= 3 })
+	err = stop()
+	if err == nil {
+		if priority {
+			err = expectBodies(r1.list(), true, "high", "low", "m1")
+		} else {
+			err = expectBodies(r1.list(), true, "m1", "low", "high")
+		}
+	}
+	testutil.Check(name+": route by key and priority", err)
+
+	// 2. 拒绝并重新入队的消息再次投递, 确认后不再投递
+	var nacked int32
+	r2 := &received{}
+	stop = consumer(b, qa2, 1, func(d mq.Delivery) {
+		r2.add(d.Body())
+		if string(d.Body()) == "m1" && atomic.CompareAndSwapInt32(&nacked, 0, 1) {
+			d.Nack(true)
+			return
+		}
+		d.Ack()
+	})
+	waitFor(3*time.Second, func() bool { return len(r2.list()) >= 4 })
+	time.Sleep(200 * time.Millisecond)
+	err = stop()
+	if err == nil {
+		err = expectBodies(r2.list(), false, "m1", "m1", "low", "high")
+	}
+	testutil.Check(name+": nack with requeue", err)
+
+	// 3. 拒绝且不重新入队的消息被丢弃
+	r3 := &received{}
+	stop = consumer(b, qb, 1, func(d mq.Delivery) {
+		r3.add(d.Body())
+		d.Nack(false)
+	})
+	waitFor(3*time.Second, func() bool { return len(r3.list()) >= 1 })
+	time.Sleep(200 * time.Millisecond)
+	err = stop()
+	if err == nil {
+		err = expectBodies(r3.list(), true, "m2")
+	}
+	testutil.Check(name+": nack without requeue", err)
+
+	// 4. 同时处理的消息数不超过concurrency
+	for i := 0; i < 8 && err == nil; i++ {
+		err = b.Publish(ex, "b", []byte("c"+strconv.Itoa(i)), 0)
+	}
+	var inflight, maxInflight int32
+	r4 := &received{}
+	stop = consumer(b, qb, 3, func(d mq.Delivery) {
+		n := atomic.AddInt32(&inflight, 1)
+		for {
+			m := atomic.LoadInt32(&maxInflight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInflight, m, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&inflight, -1)
+		r4.add(d.Body())
+		d.Ack()
+	})
+	waitFor(5*time.Second, func() bool { return len(r4.list()) >= 8 })
+	if e := stop(); err == nil {
+		err = e
+	}
+	if err == nil && len(r4.list()) != 8 {
+		err = fmt.Errorf("received %d of 8 messages", len(r4.list()))
+	}
+	if max := atomic.LoadInt32(&maxInflight); err == nil && (max < 2 || max > 3) {
+		err = fmt.Errorf("max in-flight %d, expected up to 3", max)
+	}
+	testutil.Check(name+": concurrency limit", err)
+
+	// 5. 关闭后Consume返回ErrClosed
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Consume(context.Background(), qa1, "test", 1, func(d mq.Delivery) { d.Ack() })
+	}()
+	time.Sleep(200 * time.Millisecond)
+	b.Close()
+	select {
+	case err = <-done:
+		if err != mq.ErrClosed {
+			err = fmt.Errorf("expected ErrClosed, got %v", err)
+		} else {
+			err = nil
+		}
+	case <-time.After(5 * time.Second):
+		err = errors.New("consume did not return after close")
+	}
+	if err == nil && b.Publish(ex, "a", []byte("x"), 0) == nil {
+		err = errors.New("publish succeeded after close")
+	}
+	testutil.Check(name+": close", err)
+}
+
+// testRedisReclaim : 消费者未确认的消息超时后由其他消费者认领
+func testRedisReclaim() {
+	b := mq.NewRedisStreams(nil, time.Second)
+	defer b.Close()
+	ex := "test.mq." + strconv.FormatInt(time.Now().UnixNano(), 36)
+	queue := ex + ".reclaim"
+	err := b.DeclareQueue(ex, queue, "a", 0)
+	if err == nil {
+		err = b.Publish(ex, "a", []byte("orphan"), 0)
+	}
+
+	// 第一个消费者收到后不确认即退出
+	var got int32
+	stop := consumer(b, queue, 1, func(d mq.Delivery) { atomic.AddInt32(&got, 1) })
+	waitFor(3*time.Second, func() bool { return atomic.LoadInt32(&got) == 1 })
+	stop()
+
+	r := &received{}
+	stop = consumer(b, queue, 1, func(d mq.Delivery) {
+		r.add(d.Body())
+		d.Ack()
+	})
+	waitFor(5*time.Second, func() bool { return len(r.list()) >= 1 })
+	if e := stop(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = expectBodies(r.list(), true, "orphan")
+	}
+	testutil.Check("redis: reclaim unacknowledged message", err)
+}
+
+// bucket : 内存中的目标存储
+type bucket struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	puts    int
+	// failOnce : 该存储的第一次写入失败
+	failOnce common.StoreType
+}
+
+func (bk *bucket) Put(t common.StoreType, key string, r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	bk.mu.Lock()
+	defer bk.mu.Unlock()
+	bk.puts++
+	if t == bk.failOnce {
+		bk.failOnce = 0
+		return errors.New("bucket unavailable")
+	}
+	bk.objects[fmt.Sprintf("%d:%s", t, key)] = data
+	return nil
+}
+
+func (bk *bucket) get(t common.StoreType, key string) ([]byte, int) {
+	bk.mu.Lock()
+	defer bk.mu.Unlock()
+	return bk.objects[fmt.Sprintf("%d:%s", t, key)], bk.puts
+}
+
+// fileTable : 内存中的文件表及文件位置表
+type fileTable struct {
+	mu        sync.Mutex
+	addrs     map[string]string
+	locations map[string]map[common.StoreType]string
+}
+
+func (ft *fileTable) AddLocation(filehash string, t common.StoreType, location string) error {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	if ft.locations[filehash] == nil {
+		ft.locations[filehash] = map[common.StoreType]string{}
+	}
+	ft.locations[filehash][t] = location
+	return nil
+}
+
+func (ft *fileTable) UpdateLocation(filehash, location string) error {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	ft.addrs[filehash] = location
+	return nil
+}
+
+// snapshot : 任务表中任务的当前状态
+func snapshot(store *job.MemoryStore, id int64) job.Job {
+	j, err := store.Get(id)
+	if err != nil {
+		return job.Job{}
+	}
+	return *j
+}
+
+// upload : 以multipart表单调用上传接口, 返回响应中的code
+func upload(url, username, filename string, data []byte) (int, error) {
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	form.WriteField("username", username)
+	part, err := form.CreateFormFile("file", filename)
+	if err == nil {
+		_, err = part.Write(data)
+	}
+	if err == nil {
+		err = form.Close()
+	}
+	if err != nil {
+		return 0, err
+	}
+	resp, err := http.Post(url+"/file/upload", form.FormDataContentType(), body)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	res := struct{ Code int }{}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return 0, err
+	}
+	return res.Code, nil
+}
+
+// testUploadTransfer : 上传接口按存储策略提交的转移任务经内存队列由worker执行, 失败后重试.
+// 上传接口及worker读取本地文件使用进程内的dbproxy, 本地存储位于临时目录
+func testUploadTransfer() {
+	dir, err := ioutil.TempDir("", "mqtest")
+	testutil.Check("pipeline: temp dir", err)
+	defer os.RemoveAll(dir)
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	testutil.Check("pipeline: enter temp dir", os.Chdir(dir))
+	proxy := memproxy.New()
+	dbcli.SetService(proxy)
+
+	clk := testutil.NewClock(time.Now())
+	b := mq.NewMemory()
+	store := job.NewMemoryStore()
+	client := job.NewClient(store, job.BrokerPublisher(b), time.Minute)
+	client.Now = clk.Now
+	w := job.NewWorker(store, job.BrokerPublisher(b), time.Minute)
+	w.Now = clk.Now
+
+	// alice的文件保存到Ceph(主存储)及OSS(副本), OSS第一次写入失败
+	config.StorageRules = []config.StorageRule{{UserName: "alice", Policy: "ceph+oss"}}
+	bk := &bucket{objects: map[string][]byte{}, failOnce: common.StoreOSS}
+	ft := &fileTable{addrs: map[string]string{}, locations: map[string]map[common.StoreType]string{}}
+	var transferred int32
+	tasks.Register(w, &tasks.Transferer{
+		Open:           backend.Open,
+		Put:            bk.Put,
+		AddLocation:    ft.AddLocation,
+		UpdateLocation: ft.UpdateLocation,
+		Transferred:    func(filehash string) { atomic.AddInt32(&transferred, 1) },
+	})
+
+	// 1. 与任务服务相同地声明并监听任务队列
+	err = b.DeclareQueue(config.JobExchangeName, job.QueueName(job.TypeTransfer),
+		job.TypeTransfer, config.JobMaxPriority)
+	testutil.Check("pipeline: declare job queue", err)
+	ctx, cancel := context.WithCancel(context.Background())
+	consumed := make(chan struct{})
+	go func() {
+		job.Consume(ctx, b, w)
+		close(consumed)
+	}()
+
+	// 2. 上传接口: 文件写入本地存储并记录文件表, 按存储策略提交转移任务, 重复上传同一文件不重复提交
+	api.Setup(client)
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.POST("/file/upload", api.DoUploadHandler)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	data := bytes.Repeat([]byte("transfer me "), 1024)
+	sum := sha1.Sum(data)
+	filehash := hex.EncodeToString(sum[:])
+	for i := 0; i < 2 && err == nil; i++ {
+		var code int
+		if code, err = upload(srv.URL, "alice", "report.txt", data); err == nil && code != 0 {
+			err = fmt.Errorf("upload returned code %d", code)
+		}
+	}
+	testutil.Check("pipeline: upload", err)
+	localLoc := layout.MergePath(filehash)
+	if f, ok := proxy.File(filehash); !ok || f.FileAddr.String != localLoc || f.FileSize.Int64 != int64(len(data)) {
+		err = fmt.Errorf("file table %+v", f)
+	} else if owners := proxy.Owners(); len(owners) != 2 || owners[0].UserName != "alice" || owners[0].FileName != "report.txt" {
+		err = fmt.Errorf("user file table %+v", owners)
+	} else if stored, e := ioutil.ReadFile(localLoc); e != nil || bytes.Equal(stored, data) {
+		err = fmt.Errorf("local file is missing or not encoded: %v", e)
+	}
+	testutil.Check("pipeline: store locally and record file", err)
+	if _, e := store.Get(3); snapshot(store, 2).Type != job.TypeTransfer || e == nil {
+		err = errors.New("expected 2 transfer jobs")
+	}
+	testutil.Check("pipeline: enqueue transfers on upload", err)
+	cephLoc, ossLoc := policy.Location(common.StoreCeph, filehash), policy.Location(common.StoreOSS, filehash)
+
+	// 3. 主存储转移完成, 副本第一次写入失败后按退避计划等待重试
+	ok := waitFor(3*time.Second, func() bool {
+		primary, replica := snapshot(store, 1), snapshot(store, 2)
+		return primary.Status == job.StatusSucceeded &&
+			replica.Status == job.StatusPending && replica.Attempts == 1
+	})
+	if !ok {
+		err = fmt.Errorf("unexpected jobs: %+v, %+v", snapshot(store, 1), snapshot(store, 2))
+	}
+	ft.mu.Lock()
+	addr := ft.addrs[filehash]
+	ft.mu.Unlock()
+	if err == nil && addr != cephLoc {
+		err = fmt.Errorf("file location %q, expected %q", addr, cephLoc)
+	}
+	testutil.Check("pipeline: primary transferred, replica scheduled for retry", err)
+
+	// 4. 到达重试时间后由Tick重新发布, 副本转移成功, 不改变文件表中的位置
+	def, _ := job.Lookup(job.TypeTransfer)
+	clk.Advance(def.Backoff(1))
+	err = w.Tick()
+	ok = waitFor(3*time.Second, func() bool {
+		return snapshot(store, 2).Status == job.StatusSucceeded
+	})
+	if err == nil && !ok {
+		err = fmt.Errorf("replica transfer did not succeed: %+v", snapshot(store, 2))
+	}
+	ft.mu.Lock()
+	addr, locations := ft.addrs[filehash], ft.locations[filehash]
+	ft.mu.Unlock()
+	if err == nil && (addr != cephLoc || locations[common.StoreCeph] != cephLoc || locations[common.StoreOSS] != ossLoc) {
+		err = fmt.Errorf("file location %q, replicas %v", addr, locations)
+	}
+	cephData, puts := bk.get(common.StoreCeph, cephLoc)
+	ossData, _ := bk.get(common.StoreOSS, ossLoc)
+	if err == nil && (!bytes.Equal(cephData, data) || !bytes.Equal(ossData, data) || puts != 3) {
+		err = fmt.Errorf("stored %d/%d bytes after %d puts", len(cephData), len(ossData), puts)
+	}
+	if n := atomic.LoadInt32(&transferred); err == nil && n != 1 {
+		err = fmt.Errorf("transferred callback called %d times", n)
+	}
+	testutil.Check("pipeline: replica retried and every location recorded", err)
+
+	// 5. 停止监听
+	cancel()
+	select {
+	case <-consumed:
+		err = nil
+	case <-time.After(5 * time.Second):
+		err = errors.New("job consumer did not stop")
+	}
+	b.Close()
+	testutil.Check("pipeline: stop consumer", err)
+}
+
+func main() {
+	testBroker("memory", mq.NewMemory(), true)
+
+	conn := redis.Pool().Get()
+	_, err := conn.Do("PING")
+	conn.Close()
+	if err != nil {
+		fmt.Println("[SKIP] redis streams: redis is not available")
+	} else {
+		testBroker("redis", mq.NewRedisStreams(nil, time.Minute), false)
+		testRedisReclaim()
+	}
+
+	rabbit := mq.NewRabbitMQ(config.RabbitURL)
+	if err := rabbit.DeclareQueue("test.mq.ping", "test.mq.ping", "ping", 0); err != nil {
+		fmt.Println("[SKIP] rabbitmq: rabbitmq is not available")
+	} else {
+		testBroker("rabbitmq", rabbit, true)
+	}
+
+	testUploadTransfer()
+}
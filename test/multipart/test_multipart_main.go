@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cloud/cache/redis"
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	"github.com/cloud/job"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/service/upload/api"
+	upCfg "github.com/cloud/service/upload/config"
+	"github.com/cloud/store/layout"
+	"github.com/cloud/test/mq/memproxy"
+	"github.com/cloud/test/testutil"
+)
+
+// 使用进程内的分块上传接口、dbproxy及本地临时目录对分块上传的校验进行测试:
+// go run ./test/multipart
+// 会话保存在redis中, redis不可用时跳过
+
+// apiResp : 分块上传接口的响应
+type apiResp struct {
+	Code int
+	Msg  string
+	Data json.RawMessage
+}
+
+// post : 以表单参数调用接口, body非空时作为请求体, 表单参数放在查询字符串中
+func post(server, path string, form url.Values, body []byte) (*apiResp, error) {
+	var resp *http.Response
+	var err error
+	if body == nil {
+		resp, err = http.PostForm(server+path, form)
+	} else {
+		resp, err = http.Post(server+path+"?"+form.Encode(), "application/octet-stream", bytes.NewReader(body))
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	res := &apiResp{}
+	if err = json.Unmarshal(data, res); err != nil {
+		return nil, fmt.Errorf("invalid response %q", data)
+	}
+	return res, nil
+}
+
+// expectCode : 检查响应的code
+func expectCode(res *apiResp, err error, code int) error {
+	if err == nil && res.Code != code {
+		err = fmt.Errorf("returned code %d (%s), expected %d", res.Code, res.Msg, code)
+	}
+	return err
+}
+
+// upload : 以filehash初始化分块上传并上传data的全部分块, 返回uploadID
+func upload(server, username, filehash string, data []byte, chunkSize int) (string, error) {
+	res, err := post(server, "/file/mpupload/init", url.Values{
+		"username":  {username},
+		"filehash":  {filehash},
+		"filesize":  {strconv.Itoa(len(data))},
+		"chunksize": {strconv.Itoa(chunkSize)},
+	}, nil)
+	if err = expectCode(res, err, 0); err != nil {
+		return "", err
+	}
+	info := common.MultipartUploadInfo{}
+	if err = json.Unmarshal(res.Data, &info); err != nil {
+		return "", err
+	}
+	for idx := 0; idx < info.ChunkCount; idx++ {
+		end := (idx + 1) * chunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk := data[idx*chunkSize : end]
+		res, err = post(server, "/file/mpupload/uppart", url.Values{
+			"username": {username},
+			"uploadid": {info.UploadID},
+			"index":    {strconv.Itoa(idx)},
+			"chkhash":  {testutil.Sha1Hex(chunk)},
+		}, chunk)
+		if err = expectCode(res, err, 0); err != nil {
+			return "", fmt.Errorf("chunk %d: %s", idx, err.Error())
+		}
+	}
+	return info.UploadID, nil
+}
+
+func main() {
+	conn := redis.Pool().Get()
+	_, err := conn.Do("PING")
+	conn.Close()
+	if err != nil {
+		fmt.Println("[SKIP] multipart: redis is not available")
+		return
+	}
+
+	dir, err := ioutil.TempDir("", "multiparttest")
+	testutil.Check("temp dir", err)
+	defer os.RemoveAll(dir)
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	testutil.Check("enter temp dir", os.Chdir(dir))
+	testutil.Check("create temp root", os.MkdirAll(config.TempLocalRootDir, 0744))
+	proxy := memproxy.New()
+	dbcli.SetService(proxy)
+	api.Setup(job.NewClient(job.NewMemoryStore(), func(*job.Job) bool { return true }, time.Minute))
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.POST("/file/mpupload/init", api.InitialMultipartUploadHandler)
+	router.POST("/file/mpupload/uppart", api.UploadPartHandler)
+	router.POST("/file/mpupload/complete", api.CompleteUploadHandler)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	chunkSize := upCfg.ChunkSizeMin
+	data := bytes.Repeat([]byte("multipart "), chunkSize/10+500)
+
+	// 1. 文件hash用作存储路径, 只接受40个小写hex字符
+	for _, bad := range []string{"../../../../etc/passwd", "ABCDEF0123456789ABCDEF0123456789ABCDEF01", "abc"} {
+		res, err := post(srv.URL, "/file/mpupload/init", url.Values{
+			"username": {"mallory"}, "filehash": {bad}, "filesize": {"10"},
+		}, nil)
+		testutil.Check("reject filehash "+bad, expectCode(res, err, -1))
+	}
+
+	// 2. 以他人文件的hash上传不同的内容, 合并后校验sha1失败, 不写入存储及文件表
+	victim := testutil.Sha1Hex([]byte("victim content"))
+	uploadID, err := upload(srv.URL, "mallory", victim, data, chunkSize)
+	testutil.Check("upload chunks under another hash", err)
+	res, err := post(srv.URL, "/file/mpupload/complete", url.Values{
+		"username": {"mallory"}, "uploadid": {uploadID}, "filename": {"evil.txt"},
+		"filehash": {testutil.Sha1Hex(data)}, "filesize": {strconv.Itoa(len(data))},
+	}, nil)
+	err = expectCode(res, err, -3)
+	if _, ok := proxy.File(victim); err == nil && ok {
+		err = fmt.Errorf("file table records %s", victim)
+	}
+	if _, e := os.Stat(layout.MergePath(victim)); err == nil && !os.IsNotExist(e) {
+		err = fmt.Errorf("merged file stored under %s", victim)
+	}
+	if _, ok := proxy.File(testutil.Sha1Hex(data)); err == nil && ok {
+		err = fmt.Errorf("file table records the hash from the complete request")
+	}
+	testutil.Check("reject content not matching the declared sha1", err)
+
+	// 3. 完成时以会话记录的hash及大小为准, 忽略请求中的filehash/filesize
+	filehash := testutil.Sha1Hex(data)
+	uploadID, err = upload(srv.URL, "alice", filehash, data, chunkSize)
+	testutil.Check("upload chunks", err)
+	res, err = post(srv.URL, "/file/mpupload/complete", url.Values{
+		"username": {"alice"}, "uploadid": {uploadID}, "filename": {"docs/multipart.txt"},
+		"filehash": {victim}, "filesize": {"1"},
+	}, nil)
+	err = expectCode(res, err, 0)
+	if f, ok := proxy.File(filehash); err == nil && (!ok || f.FileSize.Int64 != int64(len(data)) ||
+		f.FileAddr.String != layout.MergePath(filehash)) {
+		err = fmt.Errorf("file table %+v", f)
+	}
+	if _, ok := proxy.File(victim); err == nil && ok {
+		err = fmt.Errorf("file table records the hash from the complete request")
+	}
+	if owners := proxy.Owners(); err == nil && (len(owners) != 1 || owners[0].UserName != "alice" ||
+		owners[0].FileHash != filehash || owners[0].FileSize != int64(len(data))) {
+		err = fmt.Errorf("user file table %+v", owners)
+	}
+	testutil.Check("complete with the session's hash and size", err)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloud/cache/redis"
+	"github.com/cloud/config"
+	"github.com/cloud/service/apigw/notify"
+	"github.com/cloud/test/testutil"
+)
+
+// 测试网关的事件通知分发; 本机redis可用时同时测试跨实例分发:
+// go run ./test/notify
+
+// woken : 等待timeout内是否收到通知
+func woken(ch <-chan struct{}, timeout time.Duration) bool {
+	select {
+	case <-ch:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func main() {
+	// 1. 只唤醒对应用户的订阅者, 多次通知合并为一次
+	hub := notify.NewHub()
+	alice1, cancel1 := hub.Subscribe("alice")
+	alice2, cancel2 := hub.Subscribe("alice")
+	bob, cancelBob := hub.Subscribe("bob")
+	defer cancelBob()
+	hub.Notify("alice")
+	hub.Notify("alice")
+	var err error
+	if !woken(alice1, time.Second) || !woken(alice2, time.Second) {
+		err = fmt.Errorf("alice was not notified")
+	} else if woken(alice1, 100*time.Millisecond) {
+		err = fmt.Errorf("notifications were not coalesced")
+	} else if woken(bob, 100*time.Millisecond) {
+		err = fmt.Errorf("bob was notified")
+	}
+	testutil.Check("Notify", err)
+
+	// 2. 取消后不再唤醒
+	cancel1()
+	hub.Notify("alice")
+	if woken(alice1, 100*time.Millisecond) {
+		err = fmt.Errorf("cancelled subscriber was notified")
+	} else if !woken(alice2, time.Second) {
+		err = fmt.Errorf("remaining subscriber was not notified")
+	}
+	cancel2()
+	testutil.Check("cancel", err)
+
+	// 3. NotifyAll唤醒全部订阅者
+	hub.NotifyAll()
+	if !woken(bob, time.Second) {
+		err = fmt.Errorf("bob was not notified")
+	}
+	testutil.Check("NotifyAll", err)
+
+	// 4. 通过redis在两个网关实例间分发
+	if !redis.Publish(config.FileNotifyChannel+":ping", []byte("ping")) {
+		fmt.Println("[SKIP] redis fan-out: redis is not available")
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	gw1, gw2 := notify.NewHub(), notify.NewHub()
+	sub1, c1 := gw1.Subscribe("carol")
+	defer c1()
+	sub2, c2 := gw2.Subscribe("carol")
+	defer c2()
+	channel := fmt.Sprintf("%s:test:%d", config.FileNotifyChannel, time.Now().UnixNano())
+	go gw1.Run(ctx, channel)
+	go gw2.Run(ctx, channel)
+	// 订阅生效时会唤醒全部订阅者, 先消费掉
+	if !woken(sub1, 5*time.Second) || !woken(sub2, 5*time.Second) {
+		err = fmt.Errorf("subscription did not become active")
+	}
+	if err == nil {
+		redis.Publish(channel, []byte("carol"))
+		if !woken(sub1, 5*time.Second) || !woken(sub2, 5*time.Second) {
+			err = fmt.Errorf("notification did not reach both gateways")
+		}
+	}
+	testutil.Check("redis fan-out", err)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	"github.com/cloud/job"
+	"github.com/cloud/store/policy"
+	"github.com/cloud/test/testutil"
+)
+
+// 测试存储策略的选择及生成的转移任务:
+// go run ./test/policy
+
+func main() {
+	config.StorageRules = []config.StorageRule{
+		{UserName: "alice", Folder: "backup/", Policy: "ceph+oss"},
+		{UserName: "alice", Policy: "ceph"},
+		{Folder: "/tmp/", Policy: "local"},
+		{MinSize: 1 << 20, MaxSize: 1 << 30, Policy: "ceph"},
+		{UserName: "bob", Policy: "no-such-policy"},
+	}
+
+	// 1. 按顺序匹配用户、目录及文件大小, 都不匹配时使用默认策略
+	cases := []struct {
+		file   policy.File
+		policy string
+	}{
+		{policy.File{UserName: "alice", FileName: "backup/db.tar"}, "ceph+oss"},
+		{policy.File{UserName: "alice", FileName: "/backup/db.tar"}, "ceph+oss"},
+		{policy.File{UserName: "alice", FileName: "photos/a.jpg", FileSize: 1 << 21}, "ceph"},
+		{policy.File{UserName: "carol", FileName: "tmp/x"}, "local"},
+		{policy.File{UserName: "carol", FileName: "big.iso", FileSize: 1 << 20}, "ceph"},
+		{policy.File{UserName: "carol", FileName: "huge.iso", FileSize: 1 << 30}, config.DefaultStoragePolicy},
+		{policy.File{UserName: "carol", FileName: "small.txt", FileSize: 10}, config.DefaultStoragePolicy},
+	}
+	var err error
+	for _, c := range cases {
+		if got := policy.Resolve(c.file); err == nil && got != c.policy {
+			err = fmt.Errorf("%+v: policy %q, expected %q", c.file, got, c.policy)
+		}
+	}
+	testutil.Check("resolve policy", err)
+
+	// 2. 第一个目标为主存储, 其余为副本; 未定义的策略使用默认策略, local不转移
+	f := policy.File{UserName: "alice", FileName: "backup/db.tar", FileHash: "abc", Location: "/data/abc"}
+	transfers := policy.Transfers(f)
+	if len(transfers) != 2 || transfers[0].DestStoreType != common.StoreCeph || transfers[0].Replica ||
+		transfers[1].DestStoreType != common.StoreOSS || !transfers[1].Replica ||
+		transfers[0].DestLocation != config.CephRootDir+"abc" || transfers[1].DestLocation != config.OSSRootDir+"abc" {
+		err = fmt.Errorf("unexpected transfers %+v", transfers)
+	}
+	if err == nil && transfers[0].IdempotencyKey() == transfers[1].IdempotencyKey() {
+		err = fmt.Errorf("replicas share idempotency key %s", transfers[0].IdempotencyKey())
+	}
+	if t := policy.Transfers(policy.File{UserName: "bob", FileHash: "abc"}); err == nil &&
+		(len(t) != 1 || t[0].DestStoreType != common.StoreOSS) {
+		err = fmt.Errorf("undefined policy transfers %+v", t)
+	}
+	if t := policy.Transfers(policy.File{FileName: "tmp/x", FileHash: "abc"}); err == nil && len(t) != 0 {
+		err = fmt.Errorf("local policy transfers %+v", t)
+	}
+	testutil.Check("transfers by policy", err)
+
+	// 3. 转移任务只接受Ceph或OSS为目标
+	def, _ := job.Lookup(job.TypeTransfer)
+	for _, t := range transfers {
+		data, _ := json.Marshal(t)
+		if _, e := def.Decode(data); err == nil && e != nil {
+			err = e
+		}
+	}
+	if err == nil {
+		if _, e := def.Decode([]byte(`{"FileHash":"abc","Location":"/data/abc","DestLocation":"x","DestStoreType":4}`)); e == nil {
+			err = fmt.Errorf("transfer to mixed store type accepted")
+		}
+	}
+	if err == nil {
+		// 升级前提交的任务没有Replica字段
+		if _, e := def.Decode([]byte(`{"FileHash":"abc","Location":"/data/abc","DestLocation":"oss/abc","DestStoreType":3}`)); e != nil {
+			err = e
+		}
+	}
+	testutil.Check("validate transfer payload", err)
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	"github.com/cloud/job"
+	"github.com/cloud/service/job/tasks"
+	"github.com/cloud/store/replica"
+	"github.com/cloud/test/testutil"
+)
+
+// 测试副本的读取切换及修复任务, 文件表/副本表及各存储使用内存实现:
+// go run ./test/replica
+
+// memStore : 内存中的文件表及副本表, 实现replica.Store
+type memStore struct {
+	mu       sync.Mutex
+	location string
+	size     int64
+	replicas map[common.StoreType]*replica.Replica
+}
+
+func (s *memStore) File(filehash string) (string, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.location, s.size, nil
+}
+
+func (s *memStore) Locations(filehash string) ([]replica.Replica, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	replicas := []replica.Replica{}
+	for _, t := range []common.StoreType{common.StoreCeph, common.StoreOSS} {
+		if rep, ok := s.replicas[t]; ok {
+			replicas = append(replicas, *rep)
+		}
+	}
+	return replicas, nil
+}
+
+func (s *memStore) AddLocation(filehash string, t common.StoreType, location string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replicas[t] = &replica.Replica{StoreType: t, Location: location,
+		Status: common.LocationAvailable, VerifiedAt: "2026-01-02 00:00:00"}
+	return nil
+}
+
+func (s *memStore) SetStatus(filehash string, t common.StoreType, status common.LocationStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rep, ok := s.replicas[t]; ok {
+		rep.Status = status
+	}
+	return nil
+}
+
+func (s *memStore) RemoveLocation(filehash string, t common.StoreType) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.replicas, t)
+	return nil
+}
+
+func (s *memStore) UpdateLocation(filehash, location string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.location = location
+	return nil
+}
+
+func (s *memStore) status(t common.StoreType) common.LocationStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rep, ok := s.replicas[t]; ok {
+		return rep.Status
+	}
+	return 0
+}
+
+// memBackend : 内存中的各存储, down中的存储读写都失败
+type memBackend struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	down    map[common.StoreType]bool
+	opened  []string
+}
+
+func (b *memBackend) typeOf(location string) common.StoreType {
+	if strings.HasPrefix(location, "local/") {
+		return common.StoreLocal
+	}
+	return 0
+}
+
+func (b *memBackend) open(t common.StoreType, key string, offset, length int64) (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.opened = append(b.opened, key)
+	if b.down[t] {
+		return nil, errors.New("store unavailable")
+	}
+	data, ok := b.objects[key]
+	if !ok {
+		return nil, errors.New("no such key " + key)
+	}
+	if offset+length > int64(len(data)) {
+		return nil, errors.New("object is shorter than expected")
+	}
+	return ioutil.NopCloser(bytes.NewReader(data[offset : offset+length])), nil
+}
+
+func (b *memBackend) put(t common.StoreType, key string, r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.down[t] {
+		return errors.New("store unavailable")
+	}
+	b.objects[key] = data
+	return nil
+}
+
+func (b *memBackend) reset() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	opened := b.opened
+	b.opened = nil
+	return opened
+}
+
+func main() {
+	content := []byte("the quick brown fox jumps over the lazy dog")
+	sum := sha1.Sum(content)
+	filehash := hex.EncodeToString(sum[:])
+	size := int64(len(content))
+	cephKey := config.CephRootDir + filehash
+	ossKey := config.OSSRootDir + filehash
+	localKey := "local/" + filehash
+
+	store := &memStore{
+		location: cephKey,
+		size:     size,
+		replicas: map[common.StoreType]*replica.Replica{
+			common.StoreCeph: {StoreType: common.StoreCeph, Location: cephKey,
+				Status: common.LocationAvailable, VerifiedAt: "2026-01-01 00:00:00"},
+			common.StoreOSS: {StoreType: common.StoreOSS, Location: ossKey,
+				Status: common.LocationAvailable, VerifiedAt: "2026-01-01 00:00:00"},
+		},
+	}
+	backend := &memBackend{
+		objects: map[string][]byte{cephKey: content, ossKey: content},
+		down:    map[common.StoreType]bool{},
+	}
+	var repairs []string
+	reader := &replica.Reader{
+		Store:        store,
+		OpenLocation: backend.open,
+		TypeOf:       backend.typeOf,
+		Repair:       func(filehash string) { repairs = append(repairs, filehash) },
+	}
+
+	// 1. 按读取偏好排序: 可用的在前, 本地优先于Ceph及OSS, 文件表中已记录的位置不重复
+	store.location = localKey
+	candidates, err := reader.Candidates(filehash)
+	if err == nil && (len(candidates) != 3 || candidates[0].Location != localKey || candidates[0].Recorded ||
+		candidates[1].Location != cephKey || !candidates[1].Recorded || candidates[2].Location != ossKey) {
+		err = fmt.Errorf("unexpected candidates %+v", candidates)
+	}
+	store.location = cephKey
+	if err == nil {
+		store.replicas[common.StoreCeph].Status = common.LocationMissing
+		candidates, err = reader.Candidates(filehash)
+		store.replicas[common.StoreCeph].Status = common.LocationAvailable
+		if err == nil && (len(candidates) != 2 || candidates[0].Location != ossKey || candidates[1].Location != cephKey) {
+			err = fmt.Errorf("missing replica should be tried last, got %+v", candidates)
+		}
+	}
+	testutil.Check("sort candidates by status and read preference", err)
+
+	// 2. Ceph读取失败时切换到OSS, Ceph副本标记为缺失并提交修复
+	backend.down[common.StoreCeph] = true
+	rc, err := reader.Open(filehash, 4, 5)
+	if err == nil {
+		data, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		if string(data) != "quick" {
+			err = fmt.Errorf("read %q", data)
+		} else if store.status(common.StoreCeph) != common.LocationMissing {
+			err = errors.New("failed replica is not marked missing")
+		} else if len(repairs) != 1 || repairs[0] != filehash {
+			err = fmt.Errorf("repair requests %v", repairs)
+		}
+	}
+	testutil.Check("fail over to next replica", err)
+
+	// 3. 缺失的副本排在最后, 之后的读取直接从OSS开始
+	backend.reset()
+	rc, err = reader.Open(filehash, 0, size)
+	if err == nil {
+		rc.Close()
+		if opened := backend.reset(); len(opened) != 1 || opened[0] != ossKey {
+			err = fmt.Errorf("opened %v", opened)
+		}
+	}
+	testutil.Check("skip missing replica", err)
+
+	// 4. 所有副本都不可用时返回错误
+	backend.down[common.StoreOSS] = true
+	if _, err = reader.Open(filehash, 0, size); err == nil {
+		err = errors.New("expected error")
+	} else {
+		err = nil
+	}
+	testutil.Check("all replicas unavailable", err)
+	backend.down[common.StoreOSS] = false
+
+	// 5. File按当前位置读取, Seek后重新打开
+	f := reader.NewFile(filehash, size)
+	buf := make([]byte, 3)
+	_, err = io.ReadFull(f, buf)
+	if err == nil && string(buf) != "the" {
+		err = fmt.Errorf("read %q", buf)
+	}
+	if err == nil {
+		_, err = f.Seek(-3, io.SeekEnd)
+	}
+	if err == nil {
+		var rest []byte
+		rest, err = ioutil.ReadAll(f)
+		if err == nil && string(rest) != "dog" {
+			err = fmt.Errorf("read %q after seek", rest)
+		}
+	}
+	f.Close()
+	testutil.Check("seekable file", err)
+
+	repairer := &tasks.Repairer{Store: store, Open: backend.open, Put: backend.put, TypeOf: backend.typeOf}
+	ctx := context.Background()
+
+	// 6. 存储恢复后副本仍完整时只恢复状态, 不重新写入(4中两个副本都已标记为缺失)
+	backend.down[common.StoreCeph] = false
+	backend.reset()
+	err = repairer.Repair(ctx, &job.RepairPayload{FileHash: filehash})
+	if err == nil {
+		if store.status(common.StoreCeph) != common.LocationAvailable ||
+			store.status(common.StoreOSS) != common.LocationAvailable {
+			err = errors.New("replica is still missing")
+		} else if opened := backend.reset(); len(opened) != 2 || opened[0] != cephKey || opened[1] != ossKey {
+			err = fmt.Errorf("opened %v", opened)
+		}
+	}
+	testutil.Check("repair: reachable replica is marked available", err)
+
+	// 7. 副本丢失时从其他副本复制; 来源内容与hash不一致时换下一个来源
+	delete(backend.objects, cephKey)
+	store.SetStatus(filehash, common.StoreCeph, common.LocationMissing)
+	backend.objects[ossKey] = []byte("corrupted")
+	store.location = localKey
+	backend.objects[localKey] = content
+	err = repairer.Repair(ctx, &job.RepairPayload{FileHash: filehash})
+	if err == nil {
+		if !bytes.Equal(backend.objects[cephKey], content) {
+			err = fmt.Errorf("ceph replica is %q", backend.objects[cephKey])
+		} else if store.status(common.StoreCeph) != common.LocationAvailable {
+			err = errors.New("repaired replica is not available")
+		}
+	}
+	testutil.Check("repair: copy from a good replica", err)
+
+	// 8. 没有完整的来源时任务失败, 副本保持缺失
+	backend.objects[ossKey] = content
+	delete(backend.objects, cephKey)
+	store.SetStatus(filehash, common.StoreCeph, common.LocationMissing)
+	delete(backend.objects, localKey)
+	backend.down[common.StoreOSS] = true
+	if err = repairer.Repair(ctx, &job.RepairPayload{FileHash: filehash}); err == nil {
+		err = errors.New("expected error")
+	} else if store.status(common.StoreCeph) != common.LocationMissing {
+		err = errors.New("replica should stay missing")
+	} else {
+		err = nil
+	}
+	testutil.Check("repair: no good source", err)
+	backend.down[common.StoreOSS] = false
+
+	// 9. 指定存储策略时补齐未记录的副本
+	backend.objects[localKey] = content
+	delete(store.replicas, common.StoreOSS)
+	delete(backend.objects, ossKey)
+	err = repairer.Repair(ctx, &job.RepairPayload{FileHash: filehash})
+	if err == nil && store.status(common.StoreCeph) != common.LocationAvailable {
+		err = errors.New("ceph replica is not repaired")
+	}
+	if err == nil {
+		err = repairer.Repair(ctx, &job.RepairPayload{FileHash: filehash, Policy: "ceph+oss"})
+	}
+	if err == nil && (store.status(common.StoreOSS) != common.LocationAvailable ||
+		!bytes.Equal(backend.objects[ossKey], content)) {
+		err = errors.New("oss replica is not created")
+	}
+	testutil.Check("repair: fill replicas required by policy", err)
+
+	// 10. 修复任务的参数
+	def, _ := job.Lookup(job.TypeRepair)
+	_, err = def.Decode([]byte(`{"FileHash":"` + filehash + `"}`))
+	if err == nil {
+		if _, err = def.Decode([]byte(`{}`)); err == nil {
+			err = errors.New("FileHash should be required")
+		} else {
+			err = nil
+		}
+	}
+	testutil.Check("repair payload", err)
+}
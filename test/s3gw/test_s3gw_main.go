@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	jsonit "github.com/json-iterator/go"
+
+	"github.com/cloud/test/testutil"
+)
+
+// 使用aws sdk对S3兼容网关进行端到端测试:
+// go run test/s3gw/test_s3gw_main.go -user admin -pwd admin123 -bucket s3test
+
+const (
+	apiHost            = "http://localhost:8080/"
+	apiUserSignin      = apiHost + "user/signin"
+	apiAccessKeyCreate = apiHost + "user/accesskey/create"
+)
+
+var (
+	endpoint string
+	username string
+	password string
+	bucket   string
+)
+
+// createAccessKey : 登录后通过网关签发一对S3访问密钥
+func createAccessKey() (string, string, error) {
+	resp, err := http.PostForm(apiUserSignin, url.Values{
+		"username": {username},
+		"password": {password},
+	})
+	if err != nil {
+		return "", "", err
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	token := jsonit.Get(body, "data").Get("Token").ToString()
+	if token == "" {
+		return "", "", fmt.Errorf("signin failed: %s", string(body))
+	}
+
+	resp, err = http.PostForm(apiAccessKeyCreate, url.Values{
+		"username": {username},
+		"token":    {token},
+	})
+	if err != nil {
+		return "", "", err
+	}
+	body, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	data := jsonit.Get(body, "data")
+	if data.Get("AccessKey").ToString() == "" {
+		return "", "", fmt.Errorf("create access key failed: %s", string(body))
+	}
+	return data.Get("AccessKey").ToString(), data.Get("SecretKey").ToString(), nil
+}
+
+// multipartETag : 按partSize分块上传后S3返回的ETag, 即各分块md5拼接后的md5加上"-分块数"
+func multipartETag(data []byte, partSize int) string {
+	sums := []byte{}
+	parts := 0
+	for start := 0; start < len(data); start += partSize {
+		end := start + partSize
+		if end > len(data) {
+			end = len(data)
+		}
+		sum := md5.Sum(data[start:end])
+		sums = append(sums, sum[:]...)
+		parts++
+	}
+	sum := md5.Sum(sums)
+	return fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(sum[:]), parts)
+}
+
+func main() {
+	flag.StringVar(&endpoint, "endpoint", "http://localhost:39000", "S3网关地址")
+	flag.StringVar(&username, "user", "admin", "测试用户名")
+	flag.StringVar(&password, "pwd", "admin123", "测试用户密码")
+	flag.StringVar(&bucket, "bucket", "s3test", "测试使用的bucket(顶层目录)")
+	flag.Parse()
+
+	accessKey, secretKey, err := createAccessKey()
+	testutil.Check("create access key", err)
+
+	sess := session.Must(session.NewSession(&aws.Config{
+		Endpoint:         aws.String(endpoint),
+		Region:           aws.String("us-east-1"),
+		Credentials:      credentials.NewStaticCredentials(accessKey, secretKey, ""),
+		S3ForcePathStyle: aws.Bool(true),
+	}))
+	cli := s3.New(sess)
+
+	// 1. bucket
+	_, err = cli.CreateBucket(&s3.CreateBucketInput{Bucket: aws.String(bucket)})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "BucketAlreadyOwnedByYou" {
+		err = nil
+	}
+	testutil.Check("CreateBucket", err)
+	_, err = cli.HeadBucket(&s3.HeadBucketInput{Bucket: aws.String(bucket)})
+	testutil.Check("HeadBucket", err)
+	buckets, err := cli.ListBuckets(&s3.ListBucketsInput{})
+	testutil.Check("ListBuckets", err)
+	fmt.Printf("       buckets: %v\n", buckets.Buckets)
+
+	// 2. 普通上传及下载
+	small := []byte("hello from aws sdk")
+	smallMD5 := md5.Sum(small)
+	smallETag := `"` + hex.EncodeToString(smallMD5[:]) + `"`
+	put, err := cli.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("dir/hello.txt"),
+		Body:   bytes.NewReader(small),
+	})
+	if err == nil && aws.StringValue(put.ETag) != smallETag {
+		err = fmt.Errorf("ETag %s, expected md5 %s", aws.StringValue(put.ETag), smallETag)
+	}
+	testutil.Check("PutObject", err)
+	head, err := cli.HeadObject(&s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String("dir/hello.txt")})
+	if err == nil && aws.Int64Value(head.ContentLength) != int64(len(small)) {
+		err = fmt.Errorf("unexpected size %d", aws.Int64Value(head.ContentLength))
+	}
+	if err == nil && aws.StringValue(head.ETag) != smallETag {
+		err = fmt.Errorf("ETag %s, expected %s", aws.StringValue(head.ETag), smallETag)
+	}
+	testutil.Check("HeadObject", err)
+	obj, err := cli.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("dir/hello.txt"),
+		Range:  aws.String("bytes=6-9"),
+	})
+	if err == nil {
+		data, _ := ioutil.ReadAll(obj.Body)
+		obj.Body.Close()
+		if string(data) != "from" {
+			err = fmt.Errorf("unexpected range data %q", string(data))
+		}
+	}
+	testutil.Check("GetObject with Range", err)
+
+	// 3. 分块上传(12MB, 每块5MB)
+	large := make([]byte, 12*1024*1024)
+	rand.Read(large)
+	uploader := s3manager.NewUploader(sess, func(u *s3manager.Uploader) {
+		u.PartSize = 5 * 1024 * 1024
+		u.Concurrency = 3
+	})
+	_, err = uploader.Upload(&s3manager.UploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("dir/large.bin"),
+		Body:   bytes.NewReader(large),
+	})
+	testutil.Check("multipart upload", err)
+	obj, err = cli.GetObject(&s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String("dir/large.bin")})
+	if err == nil {
+		data, _ := ioutil.ReadAll(obj.Body)
+		obj.Body.Close()
+		if testutil.Sha1Hex(data) != testutil.Sha1Hex(large) {
+			err = fmt.Errorf("sha1 mismatch after multipart upload")
+		}
+	}
+	testutil.Check("GetObject after multipart upload", err)
+	head, err = cli.HeadObject(&s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String("dir/large.bin")})
+	if want := multipartETag(large, 5*1024*1024); err == nil && aws.StringValue(head.ETag) != want {
+		err = fmt.Errorf("ETag %s, expected %s", aws.StringValue(head.ETag), want)
+	}
+	testutil.Check("multipart ETag", err)
+
+	// 4. 列举
+	list, err := cli.ListObjectsV2(&s3.ListObjectsV2Input{
+		Bucket:    aws.String(bucket),
+		Delimiter: aws.String("/"),
+	})
+	if err == nil && (len(list.CommonPrefixes) != 1 || aws.StringValue(list.CommonPrefixes[0].Prefix) != "dir/") {
+		err = fmt.Errorf("unexpected common prefixes %v", list.CommonPrefixes)
+	}
+	testutil.Check("ListObjectsV2 with delimiter", err)
+	etags := map[string]string{}
+	err = cli.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket:  aws.String(bucket),
+		Prefix:  aws.String("dir/"),
+		MaxKeys: aws.Int64(1),
+	}, func(page *s3.ListObjectsV2Output, last bool) bool {
+		for _, obj := range page.Contents {
+			etags[aws.StringValue(obj.Key)] = aws.StringValue(obj.ETag)
+		}
+		return true
+	})
+	if err == nil && len(etags) != 2 {
+		err = fmt.Errorf("expected 2 keys, got %d", len(etags))
+	}
+	if err == nil && (etags["dir/hello.txt"] != smallETag || etags["dir/large.bin"] != aws.StringValue(head.ETag)) {
+		err = fmt.Errorf("listed ETags %v differ from HeadObject", etags)
+	}
+	testutil.Check("ListObjectsV2 pagination", err)
+
+	// 5. 删除
+	_, err = cli.DeleteBucket(&s3.DeleteBucketInput{Bucket: aws.String(bucket)})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "BucketNotEmpty" {
+		err = nil
+	} else if err == nil {
+		err = fmt.Errorf("non-empty bucket was deleted")
+	}
+	testutil.Check("DeleteBucket on non-empty bucket", err)
+	for _, key := range []string{"dir/hello.txt", "dir/large.bin"} {
+		_, err = cli.DeleteObject(&s3.DeleteObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+		testutil.Check("DeleteObject "+key, err)
+	}
+	_, err = cli.HeadObject(&s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String("dir/hello.txt")})
+	if err == nil {
+		err = fmt.Errorf("deleted object still exists")
+	} else {
+		err = nil
+	}
+	testutil.Check("HeadObject after delete", err)
+	_, err = cli.DeleteBucket(&s3.DeleteBucketInput{Bucket: aws.String(bucket)})
+	testutil.Check("DeleteBucket", err)
+}
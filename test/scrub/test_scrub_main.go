@@ -0,0 +1,429 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc64"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	"github.com/cloud/job"
+	"github.com/cloud/service/job/tasks"
+	"github.com/cloud/store/policy"
+	"github.com/cloud/store/replica"
+	"github.com/cloud/store/scrub"
+	"github.com/cloud/test/testutil"
+)
+
+// 测试完整性检查: 读取限速, 扫描, 损坏副本的隔离、告警及修复. 数据库及各存储使用内存实现:
+// go run ./test/scrub
+
+// memStore : 内存中的文件表、副本表、层级表及检查结果表
+type memStore struct {
+	mu       sync.Mutex
+	location string
+	size     int64
+	replicas map[common.StoreType]*replica.Replica
+	tier     common.StorageTier
+	results  map[common.StoreType]common.ScrubResult
+	crcs     map[common.StoreType]string
+}
+
+func (s *memStore) File(filehash string) (string, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.location, s.size, nil
+}
+
+func (s *memStore) Locations(filehash string) ([]replica.Replica, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	replicas := []replica.Replica{}
+	for _, t := range []common.StoreType{common.StoreCeph, common.StoreOSS} {
+		if rep, ok := s.replicas[t]; ok {
+			replicas = append(replicas, *rep)
+		}
+	}
+	return replicas, nil
+}
+
+func (s *memStore) AddLocation(filehash string, t common.StoreType, location string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replicas[t] = &replica.Replica{StoreType: t, Location: location, Status: common.LocationAvailable}
+	return nil
+}
+
+func (s *memStore) SetStatus(filehash string, t common.StoreType, status common.LocationStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rep, ok := s.replicas[t]; ok {
+		rep.Status = status
+	}
+	return nil
+}
+
+func (s *memStore) RemoveLocation(filehash string, t common.StoreType) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.replicas, t)
+	return nil
+}
+
+func (s *memStore) UpdateLocation(filehash, location string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.location = location
+	return nil
+}
+
+func (s *memStore) status(t common.StoreType) common.LocationStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rep, ok := s.replicas[t]; ok {
+		return rep.Status
+	}
+	return 0
+}
+
+func (s *memStore) getTier(filehash string) (common.StorageTier, int64, error) {
+	return s.tier, 0, nil
+}
+
+func (s *memStore) record(filehash string, rep replica.Replica, result common.ScrubResult, checksum, crc string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[rep.StoreType] = result
+	if result == common.ScrubOK && crc != "" {
+		s.crcs[rep.StoreType] = crc
+	}
+	return nil
+}
+
+func (s *memStore) lastCRC64(filehash string) (map[common.StoreType]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	crcs := map[common.StoreType]string{}
+	for t, crc := range s.crcs {
+		crcs[t] = crc
+	}
+	return crcs, nil
+}
+
+// memBackend : 内存中的各存储, checksums为OSS写入时记录的CRC64, down中的存储读取失败
+type memBackend struct {
+	mu          sync.Mutex
+	objects     map[string][]byte
+	checksums   map[string]string
+	down        map[common.StoreType]bool
+	opened      []string
+	quarantined []string
+}
+
+func typeOf(location string) common.StoreType {
+	switch {
+	case strings.HasPrefix(location, config.MergeLocalRootDir):
+		return common.StoreLocal
+	case strings.HasPrefix(location, config.CephRootDir):
+		return common.StoreCeph
+	case strings.HasPrefix(location, config.OSSRootDir):
+		return common.StoreOSS
+	}
+	return 0
+}
+
+func crcOf(data []byte) string {
+	return strconv.FormatUint(crc64.Checksum(data, crc64.MakeTable(crc64.ECMA)), 10)
+}
+
+func (b *memBackend) open(t common.StoreType, key string, offset, length int64) (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.opened = append(b.opened, key)
+	if b.down[t] {
+		return nil, errors.New("store unavailable")
+	}
+	data, ok := b.objects[key]
+	if !ok {
+		return nil, errors.New("no such key " + key)
+	}
+	if offset+length > int64(len(data)) {
+		return nil, errors.New("object is shorter than expected")
+	}
+	return ioutil.NopCloser(bytes.NewReader(data[offset : offset+length])), nil
+}
+
+func (b *memBackend) put(t common.StoreType, key string, r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.objects[key] = data
+	if t == common.StoreOSS {
+		b.checksums[key] = crcOf(data)
+	}
+	return nil
+}
+
+func (b *memBackend) checksum(t common.StoreType, key string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if t != common.StoreOSS {
+		return "", nil
+	}
+	return b.checksums[key], nil
+}
+
+func (b *memBackend) quarantine(t common.StoreType, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.quarantined = append(b.quarantined, key)
+	if t == common.StoreLocal {
+		delete(b.objects, key)
+	}
+	return nil
+}
+
+func (b *memBackend) reset() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	opened := b.opened
+	b.opened = nil
+	return opened
+}
+
+// flip : 与data长度相同、最后一个字节不同的内容
+func flip(data []byte) []byte {
+	bad := append([]byte{}, data...)
+	bad[len(bad)-1] ^= 0xff
+	return bad
+}
+
+func main() {
+	content := []byte("bytes that should never change on disk")
+	sum := sha1.Sum(content)
+	filehash := hex.EncodeToString(sum[:])
+	size := int64(len(content))
+	localKey := policy.Location(common.StoreLocal, filehash)
+	cephKey := policy.Location(common.StoreCeph, filehash)
+	ossKey := policy.Location(common.StoreOSS, filehash)
+	now := time.Now()
+
+	// 1. sha1及与OSS格式相同的CRC64
+	digest, crc, err := scrub.Digest(bytes.NewReader(content))
+	if err == nil && (digest != filehash || crc != crcOf(content)) {
+		err = fmt.Errorf("digest %s crc %s", digest, crc)
+	}
+	testutil.Check("digest", err)
+
+	// 2. 限速: 以256KB/s读取96KB约需375ms
+	limiter := scrub.NewLimiter(256 << 10)
+	start := time.Now()
+	n, err := io.Copy(ioutil.Discard, limiter.Reader(bytes.NewReader(make([]byte, 96<<10))))
+	elapsed := time.Since(start)
+	if err == nil && (n != 96<<10 || elapsed < 300*time.Millisecond || elapsed > 2*time.Second) {
+		err = fmt.Errorf("read %d bytes in %s", n, elapsed)
+	}
+	if err == nil {
+		start = time.Now()
+		io.Copy(ioutil.Discard, (*scrub.Limiter)(nil).Reader(bytes.NewReader(make([]byte, 1<<20))))
+		if time.Since(start) > 100*time.Millisecond {
+			err = errors.New("nil limiter should not throttle")
+		}
+	}
+	testutil.Check("throttle reads", err)
+
+	// 3. 扫描: 按检查间隔查询并提交检查任务
+	var before int64
+	var keys []string
+	list := func(b int64, limit int) ([]string, error) {
+		before = b
+		return []string{"file-a", "file-b"}, nil
+	}
+	enqueue := func(jobType string, payload interface{}, opts job.EnqueueOptions) (*job.Job, error) {
+		if jobType != job.TypeScrub {
+			return nil, errors.New("unexpected job type " + jobType)
+		}
+		keys = append(keys, opts.IdempotencyKey)
+		return &job.Job{}, nil
+	}
+	count, err := scrub.Scan(list, enqueue, now)
+	if err == nil && (count != 2 || before != now.Unix()-int64(config.ScrubAfterDays)*86400 ||
+		keys[0] != scrub.JobKey("file-a", now)) {
+		err = fmt.Errorf("enqueued %d before %d keys %v", count, before, keys)
+	}
+	testutil.Check("scan files not checked recently", err)
+
+	store := &memStore{
+		location: cephKey,
+		size:     size,
+		replicas: map[common.StoreType]*replica.Replica{
+			common.StoreCeph: {StoreType: common.StoreCeph, Location: cephKey, Status: common.LocationAvailable},
+			common.StoreOSS:  {StoreType: common.StoreOSS, Location: ossKey, Status: common.LocationAvailable},
+		},
+		tier:    common.TierHot,
+		results: map[common.StoreType]common.ScrubResult{},
+		crcs:    map[common.StoreType]string{},
+	}
+	backend := &memBackend{
+		objects:   map[string][]byte{cephKey: content, ossKey: content},
+		checksums: map[string]string{ossKey: crcOf(content)},
+		down:      map[common.StoreType]bool{},
+	}
+	var repairs []string
+	var alerts []scrub.Alert
+	scrubber := &tasks.Scrubber{
+		Store:      store,
+		Open:       backend.open,
+		TypeOf:     typeOf,
+		Checksum:   backend.checksum,
+		Quarantine: backend.quarantine,
+		GetTier:    store.getTier,
+		Record:     store.record,
+		LastCRC64:  store.lastCRC64,
+		Repair:     func(filehash string) { repairs = append(repairs, filehash) },
+		Alert:      func(alert scrub.Alert) { alerts = append(alerts, alert) },
+	}
+	repairer := &tasks.Repairer{Store: store, Open: backend.open, Put: backend.put, TypeOf: typeOf}
+	reader := &replica.Reader{Store: store, OpenLocation: backend.open, TypeOf: typeOf}
+	ctx := context.Background()
+	payload := &job.ScrubPayload{FileHash: filehash}
+
+	// 4. 副本完整时只记录结果
+	err = scrubber.Scrub(ctx, payload)
+	if err == nil {
+		switch {
+		case store.results[common.StoreCeph] != common.ScrubOK || store.results[common.StoreOSS] != common.ScrubOK:
+			err = fmt.Errorf("results %v", store.results)
+		case store.crcs[common.StoreOSS] != crcOf(content):
+			err = errors.New("crc64 of oss replica is not recorded")
+		case len(repairs) != 0 || len(alerts) != 0:
+			err = fmt.Errorf("repairs %v alerts %v", repairs, alerts)
+		}
+	}
+	testutil.Check("scrub healthy replicas", err)
+
+	// 5. OSS副本内容损坏: 隔离(不再读取)、告警并提交修复, 修复后恢复可用
+	backend.objects[ossKey] = flip(content)
+	err = scrubber.Scrub(ctx, payload)
+	if err == nil {
+		switch {
+		case store.results[common.StoreOSS] != common.ScrubMismatch:
+			err = fmt.Errorf("oss result %d", store.results[common.StoreOSS])
+		case store.status(common.StoreOSS) != common.LocationCorrupt:
+			err = errors.New("corrupt replica is not quarantined")
+		case len(alerts) != 1 || alerts[0].Location != ossKey || alerts[0].Checksum == "":
+			err = fmt.Errorf("alerts %+v", alerts)
+		case len(repairs) != 1:
+			err = fmt.Errorf("repairs %v", repairs)
+		}
+	}
+	if err == nil {
+		candidates, _ := reader.Candidates(filehash)
+		if len(candidates) != 1 || candidates[0].Location != cephKey {
+			err = fmt.Errorf("quarantined replica is still readable: %+v", candidates)
+		}
+	}
+	if err == nil {
+		err = repairer.Repair(ctx, &job.RepairPayload{FileHash: filehash})
+	}
+	if err == nil && (store.status(common.StoreOSS) != common.LocationAvailable ||
+		!bytes.Equal(backend.objects[ossKey], content)) {
+		err = errors.New("corrupt replica is not repaired")
+	}
+	testutil.Check("quarantine and repair corrupt replica", err)
+
+	// 6. 副本读取失败: 标记为缺失并提交修复, 不告警
+	repairs, alerts = nil, nil
+	backend.down[common.StoreCeph] = true
+	err = scrubber.Scrub(ctx, payload)
+	backend.down[common.StoreCeph] = false
+	if err == nil && (store.results[common.StoreCeph] != common.ScrubUnreadable ||
+		store.status(common.StoreCeph) != common.LocationMissing || len(repairs) != 1 || len(alerts) != 0) {
+		err = fmt.Errorf("result %d status %d repairs %v alerts %v", store.results[common.StoreCeph],
+			store.status(common.StoreCeph), repairs, alerts)
+	}
+	if err == nil {
+		err = repairer.Repair(ctx, &job.RepairPayload{FileHash: filehash})
+	}
+	testutil.Check("unreadable replica is marked missing", err)
+
+	// 7. 文件表中的本地文件损坏: 文件表指向一致的副本, 本地文件移走
+	repairs, alerts = nil, nil
+	store.location = localKey
+	backend.objects[localKey] = flip(content)
+	err = scrubber.Scrub(ctx, payload)
+	if err == nil {
+		switch {
+		case store.results[common.StoreLocal] != common.ScrubMismatch:
+			err = fmt.Errorf("local result %d", store.results[common.StoreLocal])
+		case store.location != cephKey:
+			err = fmt.Errorf("file location is %s", store.location)
+		case backend.objects[localKey] != nil || len(backend.quarantined) != 2:
+			err = fmt.Errorf("local file is not quarantined: %v", backend.quarantined)
+		case len(alerts) != 1:
+			err = fmt.Errorf("alerts %+v", alerts)
+		}
+	}
+	testutil.Check("quarantine corrupt local file", err)
+
+	// 8. 没有一致的副本时保留原状, 只告警
+	repairs, alerts = nil, nil
+	store.replicas = map[common.StoreType]*replica.Replica{}
+	backend.objects[cephKey] = flip(content)
+	err = scrubber.Scrub(ctx, payload)
+	if err == nil && (len(alerts) != 1 || !strings.Contains(alerts[0].Message, "no healthy replica") ||
+		len(repairs) != 0 || store.location != cephKey || store.replicas[common.StoreCeph] != nil) {
+		err = fmt.Errorf("alerts %+v repairs %v replicas %v", alerts, repairs, store.replicas)
+	}
+	testutil.Check("keep the only copy", err)
+
+	// 9. 归档文件的OSS副本不读取内容, 只比较CRC64
+	repairs, alerts = nil, nil
+	store.location = ossKey
+	store.replicas = map[common.StoreType]*replica.Replica{
+		common.StoreOSS: {StoreType: common.StoreOSS, Location: ossKey, Status: common.LocationAvailable},
+	}
+	store.tier = common.TierArchive
+	backend.reset()
+	err = scrubber.Scrub(ctx, payload)
+	if err == nil && (store.results[common.StoreOSS] != common.ScrubOK || len(backend.reset()) != 0) {
+		err = fmt.Errorf("oss result %d", store.results[common.StoreOSS])
+	}
+	if err == nil {
+		backend.checksums[ossKey] = "1"
+		err = scrubber.Scrub(ctx, payload)
+	}
+	if err == nil && (store.results[common.StoreOSS] != common.ScrubMismatch || len(alerts) != 1) {
+		err = fmt.Errorf("oss result %d alerts %+v", store.results[common.StoreOSS], alerts)
+	}
+	if err == nil {
+		delete(store.crcs, common.StoreOSS)
+		err = scrubber.Scrub(ctx, payload)
+		if err == nil && store.results[common.StoreOSS] != common.ScrubSkipped {
+			err = fmt.Errorf("oss result %d", store.results[common.StoreOSS])
+		}
+	}
+	testutil.Check("archived replica compares crc64", err)
+
+	// 10. 任务参数
+	def, _ := job.Lookup(job.TypeScrub)
+	if _, err = def.Decode([]byte(`{}`)); err == nil {
+		err = errors.New("FileHash should be required")
+	} else {
+		_, err = def.Decode([]byte(`{"FileHash":"` + filehash + `"}`))
+	}
+	testutil.Check("scrub payload", err)
+}
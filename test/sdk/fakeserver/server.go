@@ -0,0 +1,444 @@
+// Package fakeserver : 在进程内模拟网关及上传/下载服务的HTTP接口, 用于测试sdk及基于sdk的客户端
+package fakeserver
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloud/common"
+	"github.com/cloud/service/dbproxy/orm"
+	"github.com/cloud/util"
+)
+
+// Server : 在进程内模拟网关及上传/下载服务的HTTP接口, 数据保存在内存中.
+// 接口的参数与service/apigw、service/upload、service/download一致, 响应使用与其相同的util.RespMsg及common中的data结构
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	password map[string]string
+	tokens   map[string]string
+	blobs    map[string][]byte            // filehash -> 内容
+	files    map[string]map[string]string // username -> filename -> filehash
+	deleted  map[string]map[string]string // username -> filename -> filehash, 可恢复的已删除文件
+	uploads  map[string]*fakeUpload
+	events   map[string][]orm.TableUserFileEvent // username -> 变更事件
+	eventID  int64
+	changed  chan struct{} // 有新事件时关闭并替换, 用于唤醒长轮询
+
+	// 以下字段用于模拟异常情况及检查请求, 在没有进行中的请求时读写
+	NoRange      bool         // 下载时忽略Range(如文件已转移到OSS)
+	FailPartOnce map[int]bool // 对应编号的分块第一次上传时返回502
+	Signins      int          // 登录成功的次数
+}
+
+type fakeUpload struct {
+	owner     string
+	filehash  string
+	filesize  int
+	chunkSize int
+	chunks    map[int][]byte
+}
+
+// New : 启动模拟服务端, 使用完后调用Close
+func New() *Server {
+	s := &Server{
+		password:     map[string]string{},
+		tokens:       map[string]string{},
+		blobs:        map[string][]byte{},
+		files:        map[string]map[string]string{},
+		deleted:      map[string]map[string]string{},
+		uploads:      map[string]*fakeUpload{},
+		events:       map[string][]orm.TableUserFileEvent{},
+		changed:      make(chan struct{}),
+		FailPartOnce: map[int]bool{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/user/signup", s.signup)
+	mux.HandleFunc("/user/signin", s.signin)
+	mux.HandleFunc("/user/info", s.auth(s.userInfo))
+	mux.HandleFunc("/user/usage", s.auth(s.usage))
+	mux.HandleFunc("/file/list", s.auth(s.list))
+	mux.HandleFunc("/file/delete", s.auth(s.delete))
+	mux.HandleFunc("/file/move", s.auth(s.move))
+	mux.HandleFunc("/file/restore", s.auth(s.restore))
+	mux.HandleFunc("/file/changes", s.auth(s.changes))
+	mux.HandleFunc("/file/fastupload", s.auth(s.fastUpload))
+	mux.HandleFunc("/file/upload", s.auth(s.upload))
+	mux.HandleFunc("/file/mpupload/init", s.auth(s.mpInit))
+	mux.HandleFunc("/file/mpupload/uppart", s.auth(s.mpPart))
+	mux.HandleFunc("/file/mpupload/complete", s.auth(s.mpComplete))
+	mux.HandleFunc("/file/download", s.auth(s.download))
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+func writeResp(w http.ResponseWriter, code int, msg string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(util.NewRespMsg(code, msg, data).JSONBytes())
+}
+
+// genToken : 与middleware.GenToken相同的格式, 末尾8位为16进制时间戳
+func genToken(username string, at time.Time) string {
+	ts := fmt.Sprintf("%x", at.Unix())
+	sum := md5.Sum([]byte(username + ts + "token_salt"))
+	return hex.EncodeToString(sum[:]) + ts[:8]
+}
+
+// ExpireToken : 使用户当前的token失效, 模拟过期或在其他设备登录
+func (s *Server) ExpireToken(username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[username] = ""
+}
+
+func (s *Server) auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, token := r.FormValue("username"), r.FormValue("token")
+		s.mu.Lock()
+		valid := token != "" && s.tokens[username] == token
+		s.mu.Unlock()
+		if !valid {
+			writeResp(w, http.StatusOK, "Invalid Token", nil)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) signup(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.password[r.FormValue("username")] = r.FormValue("password")
+	s.files[r.FormValue("username")] = map[string]string{}
+	s.deleted[r.FormValue("username")] = map[string]string{}
+	writeResp(w, 10000, "注册成功", nil)
+}
+
+func (s *Server) signin(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	username := r.FormValue("username")
+	if pwd, ok := s.password[username]; !ok || pwd != r.FormValue("password") {
+		writeResp(w, 10005, "登录失败", nil)
+		return
+	}
+	s.Signins++
+	s.tokens[username] = genToken(username, time.Now())
+	host := strings.TrimPrefix(s.URL, "http://")
+	writeResp(w, 10000, "登陆成功", common.SigninData{
+		Location:      "/static/view/home.html",
+		Username:      username,
+		Token:         s.tokens[username],
+		UploadEntry:   host,
+		DownloadEntry: host,
+	})
+}
+
+func (s *Server) userInfo(w http.ResponseWriter, r *http.Request) {
+	writeResp(w, 0, "OK", common.UserInfoData{Username: r.FormValue("username"), SignupAt: "2020-01-01 00:00:00"})
+}
+
+func (s *Server) usage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var count, size int64
+	for _, hash := range s.files[r.FormValue("username")] {
+		count++
+		size += int64(len(s.blobs[hash]))
+	}
+	writeResp(w, 10000, "OK", common.UserUsageData{FileCount: count, TotalSize: size})
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prefix, marker := r.FormValue("prefix"), r.FormValue("marker")
+	limit, _ := strconv.Atoi(r.FormValue("limit"))
+	if limit <= 0 {
+		limit = 1000
+	}
+	names := []string{}
+	for name := range s.files[r.FormValue("username")] {
+		if strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix)) && name > marker {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	next := ""
+	if len(names) > limit {
+		names = names[:limit]
+		next = names[limit-1]
+	}
+	files := []orm.TableUserFile{}
+	for _, name := range names {
+		hash := s.files[r.FormValue("username")][name]
+		files = append(files, orm.TableUserFile{
+			UserName: r.FormValue("username"),
+			FileHash: hash,
+			FileName: name,
+			FileSize: int64(len(s.blobs[hash])),
+		})
+	}
+	data, _ := json.Marshal(files)
+	writeResp(w, 10000, "OK", common.FileListData{Files: data, NextMarker: next})
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files := s.files[r.FormValue("username")]
+	if _, ok := files[r.FormValue("filename")]; !ok {
+		writeResp(w, 10002, "文件不存在", nil)
+		return
+	}
+	name := r.FormValue("filename")
+	s.deleted[r.FormValue("username")][name] = files[name]
+	s.addEvent(r.FormValue("username"), "delete", name, "", files[name])
+	delete(files, name)
+	writeResp(w, 10000, "OK", nil)
+}
+
+func (s *Server) move(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files := s.files[r.FormValue("username")]
+	src, dest := r.FormValue("srcname"), r.FormValue("destname")
+	if _, ok := files[dest]; ok {
+		writeResp(w, 10006, "目标文件已存在", nil)
+		return
+	}
+	hash, ok := files[src]
+	if !ok {
+		writeResp(w, 10002, "文件不存在", nil)
+		return
+	}
+	delete(files, src)
+	files[dest] = hash
+	event := "move"
+	if path.Dir(src) == path.Dir(dest) {
+		event = "rename"
+	}
+	s.addEvent(r.FormValue("username"), event, dest, src, hash)
+	writeResp(w, 10000, "OK", nil)
+}
+
+func (s *Server) restore(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	username, name := r.FormValue("username"), r.FormValue("filename")
+	hash, ok := s.deleted[username][name]
+	if _, exists := s.files[username][name]; !ok || exists {
+		writeResp(w, 10001, "没有可恢复的文件", nil)
+		return
+	}
+	delete(s.deleted[username], name)
+	s.files[username][name] = hash
+	s.addEvent(username, "restore", name, "", hash)
+	writeResp(w, 10000, "OK", nil)
+}
+
+// addEvent : 记录变更事件并唤醒长轮询, 调用方持有s.mu
+func (s *Server) addEvent(username, event, filename, oldName, hash string) {
+	s.eventID++
+	s.events[username] = append(s.events[username], orm.TableUserFileEvent{
+		ID:       s.eventID,
+		UserName: username,
+		Event:    event,
+		FileName: filename,
+		OldName:  oldName,
+		FileHash: hash,
+		FileSize: int64(len(s.blobs[hash])),
+		CreateAt: time.Now().Format("2006-01-02 15:04:05"),
+	})
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// changes : 与apigw的/file/changes相同, 游标为事件ID. 不分页, HasMore始终为false
+func (s *Server) changes(w http.ResponseWriter, r *http.Request) {
+	username := r.FormValue("username")
+	wait, _ := strconv.Atoi(r.FormValue("timeout"))
+	deadline := time.After(time.Duration(wait) * time.Second)
+	cursor, err := strconv.ParseInt(r.FormValue("cursor"), 10, 64)
+	if r.FormValue("cursor") == "" {
+		s.mu.Lock()
+		cursor = s.eventID
+		s.mu.Unlock()
+	} else if err != nil || cursor < 0 {
+		writeResp(w, 10001, "invalid cursor", nil)
+		return
+	}
+	for {
+		s.mu.Lock()
+		events := []json.RawMessage{}
+		for _, ev := range s.events[username] {
+			if r.FormValue("cursor") != "" && ev.ID > cursor {
+				data, _ := json.Marshal(ev)
+				events = append(events, data)
+				cursor = ev.ID
+			}
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		if r.FormValue("cursor") == "" || len(events) > 0 || wait <= 0 {
+			writeResp(w, 10000, "OK", common.FileChangesData{
+				Events: events, Cursor: strconv.FormatInt(cursor, 10), HasMore: false,
+			})
+			return
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			wait = 0
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *Server) fastUpload(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hash := r.FormValue("filehash")
+	if _, ok := s.blobs[hash]; !ok {
+		writeResp(w, -1, "秒传失败，请访问普通上传接口", nil)
+		return
+	}
+	s.files[r.FormValue("username")][r.FormValue("filename")] = hash
+	s.addEvent(r.FormValue("username"), "upload", r.FormValue("filename"), "", hash)
+	writeResp(w, 0, "秒传成功", nil)
+}
+
+func (s *Server) upload(w http.ResponseWriter, r *http.Request) {
+	file, head, err := r.FormFile("file")
+	if err != nil {
+		writeResp(w, -1, err.Error(), nil)
+		return
+	}
+	data, _ := ioutil.ReadAll(file)
+	sum := sha1.Sum(data)
+	hash := hex.EncodeToString(sum[:])
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[hash] = data
+	s.files[r.FormValue("username")][head.Filename] = hash
+	s.addEvent(r.FormValue("username"), "upload", head.Filename, "", hash)
+	writeResp(w, 0, "OK", nil)
+}
+
+func (s *Server) mpInit(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	username, hash := r.FormValue("username"), r.FormValue("filehash")
+	filesize, _ := strconv.Atoi(r.FormValue("filesize"))
+	chunkSize := 4 << 20
+	if proposed, err := strconv.Atoi(r.FormValue("chunksize")); err == nil {
+		chunkSize = proposed
+	}
+	uploadID := md5Hex(username + hash)
+	up, ok := s.uploads[uploadID]
+	if !ok {
+		up = &fakeUpload{owner: username, filehash: hash, filesize: filesize, chunkSize: chunkSize, chunks: map[int][]byte{}}
+		s.uploads[uploadID] = up
+	}
+	exists := []int{}
+	for idx := range up.chunks {
+		exists = append(exists, idx)
+	}
+	sort.Ints(exists)
+	writeResp(w, 0, "OK", common.MultipartUploadInfo{
+		FileHash:    hash,
+		FileSize:    up.filesize,
+		UploadID:    uploadID,
+		ChunkSize:   up.chunkSize,
+		ChunkCount:  int(math.Ceil(float64(up.filesize) / float64(up.chunkSize))),
+		ChunkExists: exists,
+		MaxParallel: 3,
+	})
+}
+
+func (s *Server) mpPart(w http.ResponseWriter, r *http.Request) {
+	idx, _ := strconv.Atoi(r.FormValue("index"))
+	data, _ := ioutil.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.FailPartOnce[idx] {
+		delete(s.FailPartOnce, idx)
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	up, ok := s.uploads[r.FormValue("uploadid")]
+	if !ok || up.owner != r.FormValue("username") {
+		writeResp(w, -1, "Upload session not found", nil)
+		return
+	}
+	sum := sha1.Sum(data)
+	if hex.EncodeToString(sum[:]) != r.FormValue("chkhash") {
+		writeResp(w, -2, "Verify hash failed, chkIdx:"+r.FormValue("index"), nil)
+		return
+	}
+	up.chunks[idx] = data
+	writeResp(w, 0, "OK", nil)
+}
+
+func (s *Server) mpComplete(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	up, ok := s.uploads[r.FormValue("uploadid")]
+	if !ok {
+		writeResp(w, -1, "Upload session not found", nil)
+		return
+	}
+	buf := &bytes.Buffer{}
+	for idx := 0; idx < len(up.chunks); idx++ {
+		buf.Write(up.chunks[idx])
+	}
+	sum := sha1.Sum(buf.Bytes())
+	if buf.Len() != up.filesize || hex.EncodeToString(sum[:]) != up.filehash {
+		writeResp(w, -2, "invalid request", nil)
+		return
+	}
+	s.blobs[up.filehash] = buf.Bytes()
+	s.files[r.FormValue("username")][r.FormValue("filename")] = up.filehash
+	s.addEvent(r.FormValue("username"), "upload", r.FormValue("filename"), "", up.filehash)
+	delete(s.uploads, r.FormValue("uploadid"))
+	writeResp(w, 0, "OK", nil)
+}
+
+func (s *Server) download(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	data, ok := s.blobs[r.FormValue("filehash")]
+	noRange := s.NoRange
+	s.mu.Unlock()
+	if !ok {
+		writeResp(w, 10001, "server error", nil)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octect-stream")
+	if noRange {
+		w.Write(data)
+		return
+	}
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
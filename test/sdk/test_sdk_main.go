@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloud/sdk"
+	"github.com/cloud/test/sdk/fakeserver"
+	"github.com/cloud/test/testutil"
+)
+
+// 使用进程内的模拟服务端对sdk进行测试:
+// go run ./test/sdk
+
+func main() {
+	srv := fakeserver.New()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	tmpDir, err := ioutil.TempDir("", "sdktest")
+	testutil.Check("create temp dir", err)
+	defer os.RemoveAll(tmpDir)
+
+	// 1. 注册及登录
+	c := sdk.NewClient(srv.URL)
+	testutil.Check("SignUp", c.SignUp(ctx, "sdkuser", "sdkpass"))
+	_, err = c.Usage(ctx)
+	if err == sdk.ErrNotSignedIn {
+		err = nil
+	} else {
+		err = fmt.Errorf("expected ErrNotSignedIn, got %v", err)
+	}
+	testutil.Check("call before SignIn", err)
+	sess, err := c.SignIn(ctx, "sdkuser", "sdkpass")
+	if err == nil && time.Until(sess.ExpireAt()) < 23*time.Hour {
+		err = fmt.Errorf("unexpected token expiry %s", sess.ExpireAt())
+	}
+	testutil.Check("SignIn", err)
+	info, err := c.UserInfo(ctx)
+	if err == nil && info.Username != "sdkuser" {
+		err = fmt.Errorf("unexpected user %+v", info)
+	}
+	testutil.Check("UserInfo", err)
+
+	// 2. 分块上传: 5MB+, 每块1MB, 第3块首次上传失败后重试
+	data := make([]byte, 5<<20+4321)
+	rand.Read(data)
+	filehash := testutil.Sha1Hex(data)
+	srv.FailPartOnce[2] = true
+	var uploaded int64
+	up := sdk.NewUploader(c)
+	up.ChunkSize = 1 << 20
+	up.Progress = func(n int64) { uploaded += n }
+	res, err := up.Upload(ctx, bytes.NewReader(data), int64(len(data)), filehash, "dir/data.bin")
+	if err == nil && (res.Method != "multipart" || uploaded != int64(len(data))) {
+		err = fmt.Errorf("unexpected result %+v, progress %d", res, uploaded)
+	}
+	testutil.Check("multipart upload with chunk retry", err)
+
+	// 3. 秒传
+	res, err = up.Upload(ctx, bytes.NewReader(data), int64(len(data)), filehash, "dir/copy.bin")
+	if err == nil && res.Method != "fast" {
+		err = fmt.Errorf("expected fast upload, got %s", res.Method)
+	}
+	testutil.Check("fast upload", err)
+
+	// 4. 续传: 先上传前两块, 再由Uploader完成其余分块
+	data2 := make([]byte, 3<<20+17)
+	rand.Read(data2)
+	hash2 := testutil.Sha1Hex(data2)
+	upInfo, err := c.InitMultipartUpload(ctx, hash2, int64(len(data2)), 1<<20)
+	testutil.Check("InitMultipartUpload", err)
+	for idx := 0; idx < 2; idx++ {
+		testutil.Check(fmt.Sprintf("UploadPart %d", idx), c.UploadPart(ctx, upInfo.UploadID, idx, data2[idx<<20:(idx+1)<<20]))
+	}
+	local2 := filepath.Join(tmpDir, "data2.bin")
+	testutil.Check("write local file", ioutil.WriteFile(local2, data2, 0644))
+	res, err = up.UploadFile(ctx, local2, "dir/data2.bin")
+	if err == nil && (res.ChunksResumed != 2 || res.FileHash != hash2) {
+		err = fmt.Errorf("unexpected result %+v", res)
+	}
+	testutil.Check("resume multipart upload", err)
+
+	// 5. 空文件
+	res, err = up.Upload(ctx, bytes.NewReader(nil), 0, testutil.Sha1Hex(nil), "dir/empty.txt")
+	testutil.Check("upload empty file", err)
+
+	// 6. token失效后自动重新登录
+	signins := srv.Signins
+	srv.ExpireToken("sdkuser")
+	usage, err := c.Usage(ctx)
+	if err == nil && (srv.Signins != signins+1 || usage.FileCount != 4) {
+		err = fmt.Errorf("unexpected usage %+v, signins %d", usage, srv.Signins-signins)
+	}
+	testutil.Check("token refresh", err)
+
+	// 只有token的客户端无法重新登录
+	tokenOnly := sdk.NewClient(srv.URL, sdk.WithSession(c.Session()))
+	srv.ExpireToken("sdkuser")
+	_, err = tokenOnly.Usage(ctx)
+	if err == sdk.ErrTokenExpired {
+		err = nil
+	} else {
+		err = fmt.Errorf("expected ErrTokenExpired, got %v", err)
+	}
+	testutil.Check("expired token without password", err)
+
+	// 7. 列表, 查询, 移动, 删除
+	list, err := c.ListFiles(ctx, sdk.ListFilesRequest{Prefix: "dir/", Limit: 2})
+	if err == nil && (len(list.Files) != 2 || list.NextMarker == "") {
+		err = fmt.Errorf("unexpected page %+v", list)
+	}
+	testutil.Check("ListFiles with limit", err)
+	count := 0
+	err = c.WalkFiles(ctx, "dir/", func(f sdk.FileMeta) error {
+		count++
+		return nil
+	})
+	if err == nil && count != 4 {
+		err = fmt.Errorf("expected 4 files, got %d", count)
+	}
+	testutil.Check("WalkFiles", err)
+	testutil.Check("Move", c.Move(ctx, "dir/copy.bin", "dir/moved.bin"))
+	_, err = c.Stat(ctx, "dir/copy.bin")
+	if err == sdk.ErrNotFound {
+		err = nil
+	}
+	testutil.Check("Stat after Move", err)
+	err = c.Move(ctx, "dir/moved.bin", "dir/data.bin")
+	if sdk.IsCode(err, 10006) {
+		err = nil
+	} else {
+		err = fmt.Errorf("expected code 10006, got %v", err)
+	}
+	testutil.Check("Move onto existing file", err)
+	testutil.Check("Delete", c.Delete(ctx, "dir/moved.bin"))
+
+	// 8. 分段并发下载
+	var downloaded int64
+	down := sdk.NewDownloader(c)
+	down.PartSize = 1 << 20
+	down.Concurrency = 3
+	down.Progress = func(n int64) { downloaded += n }
+	local := filepath.Join(tmpDir, "download.bin")
+	dres, err := down.DownloadFile(ctx, "dir/data.bin", local)
+	if err == nil {
+		err = compareFile(local, data)
+	}
+	if err == nil && (!dres.Ranged || downloaded != int64(len(data))) {
+		err = fmt.Errorf("unexpected result %+v, progress %d", dres, downloaded)
+	}
+	testutil.Check("ranged download", err)
+
+	// 9. 下载续传: 模拟已下载前两段
+	os.Remove(local)
+	ioutil.WriteFile(local+sdk.PartFileSuffix, data[:2<<20], 0644)
+	state, _ := json.Marshal(map[string]interface{}{
+		"FileHash": filehash, "FileSize": len(data), "PartSize": 1 << 20, "Done": []int{0, 1},
+	})
+	ioutil.WriteFile(local+sdk.StateFileSuffix, state, 0644)
+	dres, err = down.DownloadFile(ctx, "dir/data.bin", local)
+	if err == nil {
+		err = compareFile(local, data)
+	}
+	if err == nil && !dres.Resumed {
+		err = fmt.Errorf("download was not resumed")
+	}
+	if _, serr := os.Stat(local + sdk.StateFileSuffix); err == nil && !os.IsNotExist(serr) {
+		err = fmt.Errorf("state file left behind")
+	}
+	testutil.Check("resume ranged download", err)
+
+	// 10. 服务端不支持Range时整体下载, Open按offset跳过
+	srv.NoRange = true
+	os.Remove(local)
+	dres, err = down.DownloadFile(ctx, "dir/data2.bin", local)
+	if err == nil {
+		err = compareFile(local, data2)
+	}
+	if err == nil && dres.Ranged {
+		err = fmt.Errorf("expected non-ranged download")
+	}
+	testutil.Check("download without Range support", err)
+	r, err := c.Open(ctx, hash2, 100, 50)
+	if err == nil {
+		part, _ := ioutil.ReadAll(r)
+		r.Close()
+		if !bytes.Equal(part, data2[100:150]) {
+			err = fmt.Errorf("unexpected content")
+		}
+	}
+	testutil.Check("Open with offset without Range support", err)
+
+	// 11. 空文件下载
+	dres, err = down.DownloadFile(ctx, "dir/empty.txt", filepath.Join(tmpDir, "empty.txt"))
+	if err == nil {
+		err = compareFile(filepath.Join(tmpDir, "empty.txt"), nil)
+	}
+	testutil.Check("download empty file", err)
+
+	// 12. 变更事件: 游标为空时只返回最新游标, 之后的修改按顺序返回
+	changes, err := c.Changes(ctx, "", 0)
+	if err == nil && (changes.Cursor == "" || len(changes.Events) != 0) {
+		err = fmt.Errorf("unexpected changes %+v", changes)
+	}
+	testutil.Check("Changes without cursor", err)
+	cursor := changes.Cursor
+	testutil.Check("Move for changes", c.Move(ctx, "dir/data2.bin", "other/data2.bin"))
+	testutil.Check("Delete for changes", c.Delete(ctx, "dir/empty.txt"))
+	testutil.Check("Restore", c.Restore(ctx, "dir/empty.txt"))
+	changes, err = c.Changes(ctx, cursor, 0)
+	if err == nil {
+		got := []string{}
+		for _, ev := range changes.Events {
+			got = append(got, ev.Event+" "+ev.OldName+" "+ev.FileName)
+		}
+		expect := "[move dir/data2.bin other/data2.bin delete  dir/empty.txt restore  dir/empty.txt]"
+		if fmt.Sprint(got) != expect {
+			err = fmt.Errorf("unexpected events %q", got)
+		}
+	}
+	testutil.Check("Changes after cursor", err)
+
+	// 13. 长轮询: 没有新事件时等待, 有修改后立即返回
+	cursor = changes.Cursor
+	start := time.Now()
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		c.Move(ctx, "other/data2.bin", "other/renamed.bin")
+	}()
+	changes, err = c.Changes(ctx, cursor, 10*time.Second)
+	if err == nil && (len(changes.Events) != 1 || changes.Events[0].Event != sdk.EventRename ||
+		time.Since(start) > 5*time.Second) {
+		err = fmt.Errorf("unexpected changes %+v after %s", changes, time.Since(start))
+	}
+	testutil.Check("Changes long-poll", err)
+	changes, err = c.Changes(ctx, changes.Cursor, time.Second)
+	if err == nil && len(changes.Events) != 0 {
+		err = fmt.Errorf("unexpected changes %+v", changes)
+	}
+	testutil.Check("Changes long-poll timeout", err)
+}
+
+func compareFile(path string, expect []byte) error {
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(got, expect) {
+		return fmt.Errorf("%s: content mismatch", path)
+	}
+	return nil
+}
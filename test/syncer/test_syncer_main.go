@@ -0,0 +1,404 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cloud/client/syncer"
+	"github.com/cloud/sdk"
+	"github.com/cloud/test/sdk/fakeserver"
+	"github.com/cloud/test/testutil"
+)
+
+// 使用进程内的模拟服务端及临时目录对双向同步进行测试:
+// go run ./test/syncer
+
+// deleted : 表示文件已删除的内容
+const deleted = "<deleted>"
+
+// planCase : 以base为上次同步完成时双方的内容, 修改本地及远端后, dry-run应计划的动作
+type planCase struct {
+	name   string
+	base   map[string]string
+	local  map[string]string
+	remote map[string]string
+	want   []string
+}
+
+var planCases = []planCase{
+	{
+		name:  "local new",
+		local: map[string]string{"a.txt": "a"},
+		want:  []string{"upload a.txt"},
+	},
+	{
+		name:   "remote new",
+		remote: map[string]string{"a.txt": "a"},
+		want:   []string{"download a.txt"},
+	},
+	{
+		name:   "new on both sides with same content",
+		local:  map[string]string{"a.txt": "a"},
+		remote: map[string]string{"a.txt": "a"},
+	},
+	{
+		name:  "local changed",
+		base:  map[string]string{"a.txt": "a", "b.txt": "b"},
+		local: map[string]string{"a.txt": "a2"},
+		want:  []string{"upload a.txt"},
+	},
+	{
+		name:   "remote changed",
+		base:   map[string]string{"a.txt": "a", "b.txt": "b"},
+		remote: map[string]string{"b.txt": "b2"},
+		want:   []string{"download b.txt"},
+	},
+	{
+		name:   "both changed to same content",
+		base:   map[string]string{"a.txt": "a"},
+		local:  map[string]string{"a.txt": "a2"},
+		remote: map[string]string{"a.txt": "a2"},
+	},
+	{
+		name:   "both changed: keep both",
+		base:   map[string]string{"a.txt": "a", "b.txt": "b"},
+		local:  map[string]string{"a.txt": "local"},
+		remote: map[string]string{"a.txt": "remote"},
+		want:   []string{"conflict a.txt"},
+	},
+	{
+		name:  "local deleted",
+		base:  map[string]string{"a.txt": "a", "dir/b.txt": "b"},
+		local: map[string]string{"dir/b.txt": deleted},
+		want:  []string{"delete-remote dir/b.txt"},
+	},
+	{
+		name:   "remote deleted",
+		base:   map[string]string{"a.txt": "a", "dir/b.txt": "b"},
+		remote: map[string]string{"a.txt": deleted},
+		want:   []string{"delete-local a.txt"},
+	},
+	{
+		name:   "local deleted, remote changed",
+		base:   map[string]string{"a.txt": "a"},
+		local:  map[string]string{"a.txt": deleted},
+		remote: map[string]string{"a.txt": "a2"},
+		want:   []string{"download a.txt"},
+	},
+	{
+		name:   "remote deleted, local changed",
+		base:   map[string]string{"a.txt": "a"},
+		local:  map[string]string{"a.txt": "a2"},
+		remote: map[string]string{"a.txt": deleted},
+		want:   []string{"upload a.txt"},
+	},
+	{
+		name:   "deleted on both sides",
+		base:   map[string]string{"a.txt": "a", "b.txt": "b"},
+		local:  map[string]string{"a.txt": deleted},
+		remote: map[string]string{"a.txt": deleted},
+	},
+	{
+		name: "unchanged",
+		base: map[string]string{"a.txt": "a", "dir/b.txt": "b"},
+	},
+}
+
+// env : 同步测试使用的客户端及临时目录
+type env struct {
+	ctx    context.Context
+	client *sdk.Client
+	tmpDir string
+}
+
+// writeLocal : 写入本地文件, 内容为deleted时删除
+func writeLocal(localDir, rel, content string) error {
+	path := filepath.Join(localDir, filepath.FromSlash(rel))
+	if content == deleted {
+		return os.Remove(path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(content), 0644)
+}
+
+// writeRemote : 上传远端文件, 内容为deleted时删除
+func (e *env) writeRemote(name, content string) error {
+	if content == deleted {
+		return e.client.Delete(e.ctx, name)
+	}
+	_, err := sdk.NewUploader(e.client).Upload(e.ctx, bytes.NewReader([]byte(content)),
+		int64(len(content)), testutil.Sha1Hex([]byte(content)), name)
+	return err
+}
+
+// newSyncer : 打开状态库并创建同步LocalDir与remoteDir的Syncer, logs记录输出的动作
+func (e *env) newSyncer(localDir, statePath, remoteDir string, logs *[]string) (*syncer.Syncer, error) {
+	state, err := syncer.OpenState(statePath, "test|"+remoteDir)
+	if err != nil {
+		return nil, err
+	}
+	return &syncer.Syncer{
+		Client:    e.client,
+		State:     state,
+		LocalDir:  localDir,
+		RemoteDir: remoteDir,
+		Logf: func(format string, args ...interface{}) {
+			if logs != nil {
+				*logs = append(*logs, strings.Join(strings.Fields(fmt.Sprintf(format, args...)), " "))
+			}
+		},
+	}, nil
+}
+
+// runPlanCase : 先同步base作为基准, 再修改双方并以dry-run取得计划的动作
+func (e *env) runPlanCase(i int, tc planCase) error {
+	localDir := filepath.Join(e.tmpDir, fmt.Sprintf("plan%d", i))
+	remoteDir := fmt.Sprintf("plan%d/", i)
+	if err := os.MkdirAll(localDir, 0755); err != nil {
+		return err
+	}
+	for rel, content := range tc.base {
+		if err := writeLocal(localDir, rel, content); err != nil {
+			return err
+		}
+	}
+	logs := []string{}
+	s, err := e.newSyncer(localDir, filepath.Join(e.tmpDir, fmt.Sprintf("plan%d.db", i)), remoteDir, &logs)
+	if err != nil {
+		return err
+	}
+	defer s.State.Close()
+	if report, err := s.Sync(e.ctx); err != nil {
+		return err
+	} else if report.Uploaded != len(tc.base) || len(report.Errors) > 0 {
+		return fmt.Errorf("sync base: %+v", report)
+	}
+
+	for rel, content := range tc.local {
+		if err := writeLocal(localDir, rel, content); err != nil {
+			return err
+		}
+	}
+	for rel, content := range tc.remote {
+		if err := e.writeRemote(remoteDir+rel, content); err != nil {
+			return err
+		}
+	}
+	logs = logs[:0]
+	s.DryRun = true
+	if _, err := s.Sync(e.ctx); err != nil {
+		return err
+	}
+	if fmt.Sprint(logs) != fmt.Sprint(tc.want) {
+		return fmt.Errorf("planned %q, expected %q", logs, tc.want)
+	}
+	return nil
+}
+
+// expectLocal : 检查本地目录下的全部文件(不含状态库)
+func expectLocal(localDir string, want map[string]string) error {
+	got := map[string]string{}
+	err := filepath.Walk(localDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || strings.HasPrefix(info.Name(), syncer.StateFileName) {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		rel, _ := filepath.Rel(localDir, path)
+		got[filepath.ToSlash(rel)] = string(data)
+		return err
+	})
+	if err == nil && fmt.Sprint(got) != fmt.Sprint(want) {
+		err = fmt.Errorf("local files %v, expected %v", got, want)
+	}
+	return err
+}
+
+// expectRemote : 检查远端目录下的全部文件
+func (e *env) expectRemote(remoteDir string, want map[string]string) error {
+	got := map[string]string{}
+	err := e.client.WalkFiles(e.ctx, remoteDir, func(f sdk.FileMeta) error {
+		got[strings.TrimPrefix(f.FileName, remoteDir)] = f.FileHash
+		return nil
+	})
+	hashes := map[string]string{}
+	for rel, content := range want {
+		hashes[rel] = testutil.Sha1Hex([]byte(content))
+	}
+	if err == nil && fmt.Sprint(got) != fmt.Sprint(hashes) {
+		err = fmt.Errorf("remote files %v, expected %v", got, hashes)
+	}
+	return err
+}
+
+// conflictCopy : 本地目录下文件名以prefix开头的冲突副本
+func conflictCopy(localDir, prefix string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(localDir, prefix+" (conflicted copy *)*"))
+	if err != nil {
+		return "", err
+	}
+	if len(matches) != 1 {
+		return "", fmt.Errorf("expected 1 conflicted copy, got %v", matches)
+	}
+	return filepath.Base(matches[0]), nil
+}
+
+// syncOnce : 以新打开的状态库执行一轮同步后关闭, 模拟每次同步为一次进程运行
+func (e *env) syncOnce(localDir, remoteDir string) (*syncer.Report, error) {
+	s, err := e.newSyncer(localDir, filepath.Join(localDir, syncer.StateFileName), remoteDir, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer s.State.Close()
+	report, err := s.Sync(e.ctx)
+	if err == nil && len(report.Errors) > 0 {
+		err = fmt.Errorf("sync errors: %v", report.Errors)
+	}
+	return report, err
+}
+
+// expectReport : 检查一轮同步的结果
+func expectReport(report *syncer.Report, want syncer.Report) error {
+	if fmt.Sprintf("%+v", *report) != fmt.Sprintf("%+v", want) {
+		return fmt.Errorf("report %+v, expected %+v", *report, want)
+	}
+	return nil
+}
+
+func main() {
+	srv := fakeserver.New()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	tmpDir, err := ioutil.TempDir("", "synctest")
+	testutil.Check("create temp dir", err)
+	defer os.RemoveAll(tmpDir)
+
+	client := sdk.NewClient(srv.URL)
+	testutil.Check("SignUp", client.SignUp(ctx, "syncuser", "syncpass"))
+	_, err = client.SignIn(ctx, "syncuser", "syncpass")
+	testutil.Check("SignIn", err)
+	e := &env{ctx: ctx, client: client, tmpDir: tmpDir}
+
+	// 1. 同步计划: 每种情况使用独立的本地目录、远端目录及状态库
+	for i, tc := range planCases {
+		testutil.Check("plan: "+tc.name, e.runPlanCase(i, tc))
+	}
+
+	// 2. 往返同步, 每轮重新打开状态库
+	localDir := filepath.Join(tmpDir, "sync")
+	remoteDir := "sync/"
+	testutil.Check("create local dir", os.MkdirAll(localDir, 0755))
+	err = writeLocal(localDir, "a.txt", "alpha")
+	if err == nil {
+		err = writeLocal(localDir, "sub/b.txt", "bravo")
+	}
+	if err == nil {
+		err = e.writeRemote(remoteDir+"c.txt", "charlie")
+	}
+	testutil.Check("prepare files", err)
+
+	report, err := e.syncOnce(localDir, remoteDir)
+	if err == nil {
+		err = expectReport(report, syncer.Report{Uploaded: 2, Downloaded: 1})
+	}
+	all := map[string]string{"a.txt": "alpha", "sub/b.txt": "bravo", "c.txt": "charlie"}
+	if err == nil {
+		err = expectLocal(localDir, all)
+	}
+	if err == nil {
+		err = e.expectRemote(remoteDir, all)
+	}
+	testutil.Check("first sync", err)
+
+	// 状态库保存在本地目录下, 不参与同步; 重新打开后基准及游标仍在
+	state, err := syncer.OpenState(filepath.Join(localDir, syncer.StateFileName), "test|"+remoteDir)
+	if err == nil {
+		var entries map[string]syncer.Entry
+		var cursor string
+		if entries, err = state.Entries(); err == nil {
+			cursor, err = state.Cursor()
+		}
+		if err == nil && (len(entries) != 3 || entries["sub/b.txt"].Hash != testutil.Sha1Hex([]byte("bravo")) || cursor == "") {
+			err = fmt.Errorf("unexpected state %v, cursor %q", entries, cursor)
+		}
+		state.Close()
+	}
+	testutil.Check("state persisted", err)
+	_, err = syncer.OpenState(filepath.Join(localDir, syncer.StateFileName), "test|other/")
+	if err == nil {
+		err = fmt.Errorf("state opened for another remote dir")
+	} else {
+		err = nil
+	}
+	testutil.Check("state bound to remote dir", err)
+
+	report, err = e.syncOnce(localDir, remoteDir)
+	if err == nil {
+		err = expectReport(report, syncer.Report{})
+	}
+	testutil.Check("no changes", err)
+
+	// 基准已持久化, 本地删除传播到远端, 而不是重新下载
+	err = writeLocal(localDir, "a.txt", deleted)
+	if err == nil {
+		report, err = e.syncOnce(localDir, remoteDir)
+	}
+	if err == nil {
+		err = expectReport(report, syncer.Report{DeletedRemote: 1})
+	}
+	delete(all, "a.txt")
+	if err == nil {
+		err = e.expectRemote(remoteDir, all)
+	}
+	testutil.Check("propagate local delete", err)
+
+	err = e.writeRemote(remoteDir+"sub/b.txt", deleted)
+	if err == nil {
+		report, err = e.syncOnce(localDir, remoteDir)
+	}
+	if err == nil {
+		err = expectReport(report, syncer.Report{DeletedLocal: 1})
+	}
+	delete(all, "sub/b.txt")
+	if err == nil {
+		err = expectLocal(localDir, all)
+	}
+	testutil.Check("propagate remote delete", err)
+
+	// 双方都修改: 本地文件改名为冲突副本并上传, 远端文件下载到原路径
+	err = writeLocal(localDir, "c.txt", "charlie local")
+	if err == nil {
+		err = e.writeRemote(remoteDir+"c.txt", "charlie remote")
+	}
+	if err == nil {
+		report, err = e.syncOnce(localDir, remoteDir)
+	}
+	if err == nil {
+		err = expectReport(report, syncer.Report{Conflicts: 1})
+	}
+	var copyName string
+	if err == nil {
+		copyName, err = conflictCopy(localDir, "c")
+	}
+	if err == nil {
+		all = map[string]string{"c.txt": "charlie remote", copyName: "charlie local"}
+		if err = expectLocal(localDir, all); err == nil {
+			err = e.expectRemote(remoteDir, all)
+		}
+	}
+	testutil.Check("conflict keeps both", err)
+
+	report, err = e.syncOnce(localDir, remoteDir)
+	if err == nil {
+		err = expectReport(report, syncer.Report{})
+	}
+	testutil.Check("stable after conflict", err)
+}
@@ -0,0 +1,74 @@
+package testutil
+
+import (
+	"sync"
+
+	"github.com/cloud/common"
+	"github.com/cloud/store/crypt"
+)
+
+// MemKeys : 内存中的数据密钥表, 实现crypt.KeyStore
+type MemKeys struct {
+	mu   sync.Mutex
+	keys map[crypt.KeyRef]crypt.Key
+}
+
+// NewMemKeys : 创建空的数据密钥表
+func NewMemKeys() *MemKeys {
+	return &MemKeys{keys: map[crypt.KeyRef]crypt.Key{}}
+}
+
+// Get : 实现crypt.KeyStore
+func (m *MemKeys) Get(filehash string, t common.StoreType) (*crypt.Key, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key, ok := m.keys[crypt.KeyRef{FileHash: filehash, StoreType: t}]
+	if !ok {
+		return nil, nil
+	}
+	return &key, nil
+}
+
+// Create : 实现crypt.KeyStore
+func (m *MemKeys) Create(filehash string, t common.StoreType, key crypt.Key) (*crypt.Key, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ref := crypt.KeyRef{FileHash: filehash, StoreType: t}
+	if stored, ok := m.keys[ref]; ok {
+		return &stored, nil
+	}
+	m.keys[ref] = key
+	return &key, nil
+}
+
+// Rewrap : 实现crypt.KeyStore
+func (m *MemKeys) Rewrap(filehash string, t common.StoreType, oldKeyID string, key crypt.Key) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ref := crypt.KeyRef{FileHash: filehash, StoreType: t}
+	if stored, ok := m.keys[ref]; ok && stored.KeyID == oldKeyID {
+		m.keys[ref] = key
+	}
+	return nil
+}
+
+// Remove : 实现crypt.KeyStore
+func (m *MemKeys) Remove(filehash string, t common.StoreType) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, crypt.KeyRef{FileHash: filehash, StoreType: t})
+	return nil
+}
+
+// ListStale : 实现crypt.KeyStore
+func (m *MemKeys) ListStale(keyID string, limit int) ([]crypt.KeyRef, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	refs := []crypt.KeyRef{}
+	for ref, key := range m.keys {
+		if key.KeyID != keyID && len(refs) < limit {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
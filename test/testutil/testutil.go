@@ -0,0 +1,51 @@
+// Package testutil : 各测试程序共用的检查函数及模拟实现
+package testutil
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Check : 输出步骤的结果, 失败时退出测试程序
+func Check(step string, err error) {
+	if err != nil {
+		fmt.Printf("[FAIL] %s: %s\n", step, err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("[ OK ] %s\n", step)
+}
+
+// Sha1Hex : 数据的sha1, 与文件表中的文件hash格式相同
+func Sha1Hex(data []byte) string {
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Clock : 可手动前移的时钟
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock : 从now开始的时钟
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now : 当前时间
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance : 前移d
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloud/common"
+	"github.com/cloud/config"
+	"github.com/cloud/job"
+	"github.com/cloud/service/job/tasks"
+	"github.com/cloud/store/policy"
+	"github.com/cloud/store/replica"
+	"github.com/cloud/store/tier"
+	"github.com/cloud/test/testutil"
+)
+
+// 测试冷热分层: 访问记录及解冻, 降级扫描, 降级及升级任务. 数据库及各存储使用内存实现:
+// go run ./test/tier
+
+// memStore : 内存中的文件表、副本表及层级表
+type memStore struct {
+	mu         sync.Mutex
+	location   string
+	size       int64
+	replicas   map[common.StoreType]*replica.Replica
+	tier       common.StorageTier
+	lastAccess int64
+}
+
+func (s *memStore) File(filehash string) (string, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.location, s.size, nil
+}
+
+func (s *memStore) Locations(filehash string) ([]replica.Replica, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	replicas := []replica.Replica{}
+	for _, t := range []common.StoreType{common.StoreCeph, common.StoreOSS} {
+		if rep, ok := s.replicas[t]; ok {
+			replicas = append(replicas, *rep)
+		}
+	}
+	return replicas, nil
+}
+
+func (s *memStore) AddLocation(filehash string, t common.StoreType, location string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replicas[t] = &replica.Replica{StoreType: t, Location: location, Status: common.LocationAvailable}
+	return nil
+}
+
+func (s *memStore) SetStatus(filehash string, t common.StoreType, status common.LocationStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rep, ok := s.replicas[t]; ok {
+		rep.Status = status
+	}
+	return nil
+}
+
+func (s *memStore) RemoveLocation(filehash string, t common.StoreType) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.replicas, t)
+	return nil
+}
+
+func (s *memStore) UpdateLocation(filehash, location string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.location = location
+	return nil
+}
+
+func (s *memStore) getTier(filehash string) (common.StorageTier, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tier, s.lastAccess, nil
+}
+
+func (s *memStore) setTier(filehash string, t common.StorageTier) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tier = t
+	return nil
+}
+
+// memBackend : 内存中的各存储, 记录OSS对象的存储类型及解冻状态
+type memBackend struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	classes  map[string]common.StorageTier
+	restored map[string]bool
+}
+
+func typeOf(location string) common.StoreType {
+	switch {
+	case strings.HasPrefix(location, config.MergeLocalRootDir):
+		return common.StoreLocal
+	case strings.HasPrefix(location, config.CephRootDir):
+		return common.StoreCeph
+	case strings.HasPrefix(location, config.OSSRootDir):
+		return common.StoreOSS
+	}
+	return 0
+}
+
+func (b *memBackend) open(t common.StoreType, key string, offset, length int64) (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	data, ok := b.objects[key]
+	if !ok {
+		return nil, errors.New("no such key " + key)
+	}
+	if b.classes[key] == common.TierArchive && !b.restored[key] {
+		return nil, errors.New("object is archived: " + key)
+	}
+	return ioutil.NopCloser(bytes.NewReader(data[offset : offset+length])), nil
+}
+
+func (b *memBackend) put(t common.StoreType, key string, r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.objects[key] = data
+	if t == common.StoreOSS {
+		b.classes[key] = common.TierHot
+	}
+	return nil
+}
+
+func (b *memBackend) remove(t common.StoreType, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.objects, key)
+	return nil
+}
+
+func (b *memBackend) setStorageClass(key string, t common.StorageTier) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.objects[key]; !ok {
+		return errors.New("no such key " + key)
+	}
+	if b.classes[key] == common.TierArchive && !b.restored[key] {
+		return errors.New("object is archived: " + key)
+	}
+	b.classes[key] = t
+	return nil
+}
+
+func (b *memBackend) restore(key string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.classes[key] != common.TierArchive || b.restored[key], nil
+}
+
+func (b *memBackend) has(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.objects[key]
+	return ok
+}
+
+func (b *memBackend) class(key string) common.StorageTier {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.classes[key]
+}
+
+func main() {
+	content := []byte("cold data that nobody reads")
+	sum := sha1.Sum(content)
+	filehash := hex.EncodeToString(sum[:])
+	localKey := policy.Location(common.StoreLocal, filehash)
+	cephKey := policy.Location(common.StoreCeph, filehash)
+	ossKey := policy.Location(common.StoreOSS, filehash)
+	now := time.Now()
+	idle := now.Unix() - int64(config.TierArchiveAfterDays+1)*86400
+
+	// 1. 访问记录: 热存储直接读取; 低频访问的提交升级; 归档的解冻完成前返回ErrRestoring
+	current := common.TierHot
+	ready := false
+	promoted := 0
+	tracker := &tier.Tracker{
+		Record:  func(string) (common.StorageTier, error) { return current, nil },
+		Restore: func(string) (bool, error) { return ready, nil },
+		Promote: func(string) { promoted++ },
+	}
+	err := tracker.Access(filehash)
+	if err == nil && promoted != 0 {
+		err = errors.New("hot file should not be promoted")
+	}
+	if err == nil {
+		current = common.TierCold
+		if err = tracker.Access(filehash); err == nil && promoted != 1 {
+			err = errors.New("cold file is not promoted")
+		}
+	}
+	if err == nil {
+		current = common.TierArchive
+		if err = tracker.Access(filehash); err != tier.ErrRestoring {
+			err = fmt.Errorf("expected ErrRestoring, got %v", err)
+		} else if promoted != 1 {
+			err = errors.New("archived file should not be promoted before restored")
+		} else {
+			ready = true
+			if err = tracker.Access(filehash); err == nil && promoted != 2 {
+				err = errors.New("restored file is not promoted")
+			}
+		}
+	}
+	if err == nil {
+		tracker.Record = func(string) (common.StorageTier, error) { return 0, errors.New("dbproxy down") }
+		err = tracker.Access(filehash)
+	}
+	testutil.Check("access: record, restore and promote", err)
+
+	// 2. 扫描: 按各层级的未访问天数查询并提交降级任务, 同一扫描周期内去重键相同
+	var befores []int64
+	var enqueued []job.TierPayload
+	var keys []string
+	list := func(t common.StorageTier, before int64, limit int) ([]string, error) {
+		befores = append(befores, before)
+		return []string{fmt.Sprintf("file-%d", t)}, nil
+	}
+	enqueue := func(jobType string, payload interface{}, opts job.EnqueueOptions) (*job.Job, error) {
+		enqueued = append(enqueued, payload.(job.TierPayload))
+		keys = append(keys, opts.IdempotencyKey)
+		return &job.Job{}, nil
+	}
+	count, err := tier.Scan(list, enqueue, now)
+	if err == nil && (count != 2 || len(enqueued) != 2 ||
+		enqueued[0] != job.TierPayload{FileHash: "file-1", Tier: common.TierCold} ||
+		enqueued[1] != job.TierPayload{FileHash: "file-2", Tier: common.TierArchive}) {
+		err = fmt.Errorf("enqueued %d %+v", count, enqueued)
+	}
+	if err == nil && (befores[0] != now.Unix()-int64(config.TierColdAfterDays)*86400 ||
+		befores[1] != now.Unix()-int64(config.TierArchiveAfterDays)*86400) {
+		err = fmt.Errorf("unexpected idle thresholds %v", befores)
+	}
+	if err == nil && keys[0] != tier.JobKey("file-1", common.TierCold, now.Add(time.Second)) &&
+		keys[0] != tier.JobKey("file-1", common.TierCold, now.Add(-time.Second)) {
+		err = fmt.Errorf("unstable job key %s", keys[0])
+	}
+	testutil.Check("scan idle files", err)
+
+	// ceph+oss策略的文件: 文件表指向Ceph, OSS上有副本, 本地还有上传时的文件
+	store := &memStore{
+		location: cephKey,
+		size:     int64(len(content)),
+		replicas: map[common.StoreType]*replica.Replica{
+			common.StoreCeph: {StoreType: common.StoreCeph, Location: cephKey, Status: common.LocationAvailable},
+			common.StoreOSS:  {StoreType: common.StoreOSS, Location: ossKey, Status: common.LocationAvailable},
+		},
+		tier:       common.TierHot,
+		lastAccess: now.Unix(),
+	}
+	backend := &memBackend{
+		objects:  map[string][]byte{localKey: content, cephKey: content, ossKey: content},
+		classes:  map[string]common.StorageTier{ossKey: common.TierHot},
+		restored: map[string]bool{},
+	}
+	tierer := &tasks.Tierer{
+		Store:           store,
+		Open:            backend.open,
+		Put:             backend.put,
+		TypeOf:          typeOf,
+		Remove:          backend.remove,
+		SetStorageClass: backend.setStorageClass,
+		Restore:         backend.restore,
+		GetTier:         store.getTier,
+		SetTier:         store.setTier,
+		Now:             func() time.Time { return now },
+	}
+	ctx := context.Background()
+
+	// 3. 提交后被访问过的文件不降级
+	err = tierer.Tier(ctx, &job.TierPayload{FileHash: filehash, Tier: common.TierCold})
+	if err == nil && (store.tier != common.TierHot || !backend.has(cephKey)) {
+		err = errors.New("recently accessed file was demoted")
+	}
+	testutil.Check("demote: skip recently accessed file", err)
+
+	// 4. 降级为低频访问: OSS副本改为IA, 文件表指向OSS, 删除Ceph副本及本地文件
+	store.lastAccess = idle
+	err = tierer.Tier(ctx, &job.TierPayload{FileHash: filehash, Tier: common.TierCold})
+	if err == nil {
+		switch {
+		case store.tier != common.TierCold:
+			err = fmt.Errorf("tier is %d", store.tier)
+		case store.location != ossKey:
+			err = fmt.Errorf("file location is %s", store.location)
+		case backend.class(ossKey) != common.TierCold:
+			err = errors.New("oss object is not IA")
+		case backend.has(cephKey) || backend.has(localKey):
+			err = errors.New("hot copies are not removed")
+		case store.replicas[common.StoreCeph] != nil:
+			err = errors.New("ceph location is not removed")
+		}
+	}
+	testutil.Check("demote to cold", err)
+
+	// 5. 降级为归档; 重复执行不做处理
+	err = tierer.Tier(ctx, &job.TierPayload{FileHash: filehash, Tier: common.TierArchive})
+	if err == nil {
+		err = tierer.Tier(ctx, &job.TierPayload{FileHash: filehash, Tier: common.TierArchive})
+	}
+	if err == nil && (store.tier != common.TierArchive || backend.class(ossKey) != common.TierArchive) {
+		err = errors.New("file is not archived")
+	}
+	testutil.Check("demote to archive", err)
+
+	// 6. 升级: 解冻完成前任务失败等待重试; 解冻后复制到热存储, OSS副本改为IA
+	err = tierer.Tier(ctx, &job.TierPayload{FileHash: filehash, Tier: common.TierHot})
+	if err != tier.ErrRestoring {
+		err = fmt.Errorf("expected ErrRestoring, got %v", err)
+	} else {
+		backend.restored[ossKey] = true
+		err = tierer.Tier(ctx, &job.TierPayload{FileHash: filehash, Tier: common.TierHot})
+	}
+	hotKey := policy.Location(config.TierHotStore, filehash)
+	if err == nil {
+		switch {
+		case store.tier != common.TierHot:
+			err = fmt.Errorf("tier is %d", store.tier)
+		case store.location != hotKey || !backend.has(hotKey):
+			err = fmt.Errorf("file is not copied to %s", hotKey)
+		case backend.class(ossKey) != common.TierCold:
+			err = errors.New("oss replica should be kept as IA")
+		}
+	}
+	testutil.Check("promote archived file", err)
+
+	// 7. 只在本地的文件降级时先复制到OSS
+	delete(backend.objects, ossKey)
+	store.replicas = map[common.StoreType]*replica.Replica{}
+	store.location = localKey
+	backend.objects[localKey] = content
+	store.tier = common.TierHot
+	err = tierer.Tier(ctx, &job.TierPayload{FileHash: filehash, Tier: common.TierCold})
+	if err == nil {
+		rep := store.replicas[common.StoreOSS]
+		if rep == nil || rep.Location != ossKey || !bytes.Equal(backend.objects[ossKey], content) {
+			err = errors.New("file is not copied to oss")
+		} else if store.location != ossKey || backend.has(localKey) || backend.class(ossKey) != common.TierCold {
+			err = errors.New("local file is not demoted")
+		}
+	}
+	testutil.Check("demote local-only file", err)
+
+	// 8. 任务参数
+	def, _ := job.Lookup(job.TypeTier)
+	if _, err = def.Decode([]byte(`{"FileHash":"abc","Tier":2}`)); err == nil {
+		if _, err = def.Decode([]byte(`{"FileHash":"abc","Tier":4}`)); err == nil {
+			err = errors.New("tier 4 should be rejected")
+		} else {
+			err = nil
+		}
+	}
+	testutil.Check("tier payload", err)
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cloud/cache/redis"
+	"github.com/cloud/config"
+	"github.com/cloud/job"
+	dbcli "github.com/cloud/service/dbproxy/client"
+	"github.com/cloud/service/upload/api"
+	upCfg "github.com/cloud/service/upload/config"
+	"github.com/cloud/store/layout"
+	"github.com/cloud/test/mq/memproxy"
+	"github.com/cloud/test/testutil"
+)
+
+// 使用进程内的tus接口、dbproxy及本地临时目录对tus断点续传进行测试:
+// go run ./test/tus
+// 会话保存在redis中, redis不可用时跳过
+
+// tusClient : 以username调用tus接口
+type tusClient struct {
+	server   string
+	username string
+}
+
+// do : 发送tus请求, 除非headers中指定, 总是带上Tus-Resumable
+func (tc *tusClient) do(method, path string, headers map[string]string, body []byte) (*http.Response, error) {
+	url := tc.server + path
+	if !strings.Contains(path, "?") {
+		url += "?username=" + tc.username
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Tus-Resumable", api.TusVersion)
+	for k, v := range headers {
+		if v == "" {
+			req.Header.Del(k)
+		} else {
+			req.Header.Set(k, v)
+		}
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	return resp, nil
+}
+
+// create : 创建上传会话, 返回上传地址
+func (tc *tusClient) create(size int, metadata string) (string, *http.Response, error) {
+	resp, err := tc.do(http.MethodPost, api.TusBasePath,
+		map[string]string{"Upload-Length": strconv.Itoa(size), "Upload-Metadata": metadata}, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return "", resp, fmt.Errorf("create returned %d", resp.StatusCode)
+	}
+	return resp.Header.Get("Location"), resp, nil
+}
+
+// patch : 从offset开始上传data, checksum为空时不校验
+func (tc *tusClient) patch(location string, offset int, data []byte, checksum string) (*http.Response, error) {
+	return tc.do(http.MethodPatch, location, map[string]string{
+		"Content-Type":    "application/offset+octet-stream",
+		"Upload-Offset":   strconv.Itoa(offset),
+		"Upload-Checksum": checksum,
+	}, data)
+}
+
+// slowBody : 依次返回parts, 每返回一段后调用pause(如等待或修改锁), 模拟较慢的上传
+type slowBody struct {
+	parts [][]byte
+	pause func(idx int)
+	idx   int
+}
+
+func (b *slowBody) Read(p []byte) (int, error) {
+	if b.idx >= len(b.parts) {
+		return 0, io.EOF
+	}
+	if b.idx > 0 {
+		b.pause(b.idx)
+	}
+	n := copy(p, b.parts[b.idx])
+	b.parts[b.idx] = b.parts[b.idx][n:]
+	if len(b.parts[b.idx]) == 0 {
+		b.idx++
+	}
+	return n, nil
+}
+
+// patchStream : 从offset开始上传body
+func (tc *tusClient) patchStream(location string, offset int, body io.Reader) (*http.Response, error) {
+	url := tc.server + location
+	if !strings.Contains(location, "?") {
+		url += "?username=" + tc.username
+	}
+	req, err := http.NewRequest(http.MethodPatch, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Tus-Resumable", api.TusVersion)
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+	req.Header.Set("Upload-Offset", strconv.Itoa(offset))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	return resp, nil
+}
+
+// expectOffset : HEAD查询到的偏移量
+func (tc *tusClient) expectOffset(location string, offset int) error {
+	resp, err := tc.do(http.MethodHead, location, nil, nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Upload-Offset") != strconv.Itoa(offset) {
+		return fmt.Errorf("HEAD returned %d, offset %q, expected %d", resp.StatusCode, resp.Header.Get("Upload-Offset"), offset)
+	}
+	return nil
+}
+
+// expectStatus : 检查响应状态码
+func expectStatus(resp *http.Response, err error, status int) error {
+	if err == nil && resp.StatusCode != status {
+		err = fmt.Errorf("returned %d, expected %d", resp.StatusCode, status)
+	}
+	return err
+}
+
+// expectExpires : Upload-Expires应为now+ttl(允许几秒误差)
+func expectExpires(resp *http.Response, ttl time.Duration) error {
+	expires, err := http.ParseTime(resp.Header.Get("Upload-Expires"))
+	if err != nil {
+		return fmt.Errorf("invalid Upload-Expires %q", resp.Header.Get("Upload-Expires"))
+	}
+	if d := expires.Sub(time.Now().Add(ttl)); d < -5*time.Second || d > 5*time.Second {
+		return fmt.Errorf("Upload-Expires %s, expected about %s from now", expires, ttl)
+	}
+	return nil
+}
+
+// sha1Checksum : Upload-Checksum请求头
+func sha1Checksum(data []byte) string {
+	sum := sha1.Sum(data)
+	return "sha1 " + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// uploadID : 上传地址中的uploadID
+func uploadID(location string) string {
+	return strings.SplitN(strings.TrimPrefix(location, api.TusBasePath), "?", 2)[0]
+}
+
+func main() {
+	conn := redis.Pool().Get()
+	_, err := conn.Do("PING")
+	conn.Close()
+	if err != nil {
+		fmt.Println("[SKIP] tus: redis is not available")
+		return
+	}
+
+	dir, err := ioutil.TempDir("", "tustest")
+	testutil.Check("temp dir", err)
+	defer os.RemoveAll(dir)
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	testutil.Check("enter temp dir", os.Chdir(dir))
+	testutil.Check("create temp root", os.MkdirAll(config.TempLocalRootDir, 0744))
+	proxy := memproxy.New()
+	dbcli.SetService(proxy)
+	api.Setup(job.NewClient(job.NewMemoryStore(), func(*job.Job) bool { return true }, time.Minute))
+
+	// 与upload服务相同地注册tus接口, 用户名由查询参数传入(不经过token校验)
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.OPTIONS(api.TusBasePath, api.TusOptionsHandler)
+	router.POST(api.TusBasePath, api.TusCreateHandler)
+	router.HEAD(api.TusBasePath+":id", api.TusHeadHandler)
+	router.PATCH(api.TusBasePath+":id", api.TusPatchHandler)
+	router.DELETE(api.TusBasePath+":id", api.TusDeleteHandler)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	alice := &tusClient{server: srv.URL, username: "alice"}
+	bob := &tusClient{server: srv.URL, username: "bob"}
+
+	// 1. 能力查询及协议版本
+	resp, err := alice.do(http.MethodOptions, api.TusBasePath, map[string]string{"Tus-Resumable": ""}, nil)
+	if err = expectStatus(resp, err, http.StatusNoContent); err == nil &&
+		(resp.Header.Get("Tus-Extension") != api.TusExtensions || resp.Header.Get("Tus-Checksum-Algorithm") != "sha1") {
+		err = fmt.Errorf("unexpected headers %v", resp.Header)
+	}
+	testutil.Check("OPTIONS", err)
+	resp, err = alice.do(http.MethodPost, api.TusBasePath, map[string]string{"Tus-Resumable": "0.2.2", "Upload-Length": "1"}, nil)
+	testutil.Check("reject unsupported Tus-Resumable", expectStatus(resp, err, http.StatusPreconditionFailed))
+
+	// 2. 创建: Upload-Length及Upload-Metadata
+	resp, err = alice.do(http.MethodPost, api.TusBasePath, map[string]string{"Upload-Length": "-1"}, nil)
+	testutil.Check("reject invalid Upload-Length", expectStatus(resp, err, http.StatusBadRequest))
+	data := bytes.Repeat([]byte("resumable upload "), 1000)
+	metadata := "filename " + base64.StdEncoding.EncodeToString([]byte("docs/tus.txt")) + ",is_confidential"
+	location, resp, err := alice.create(len(data), metadata)
+	if err == nil && !strings.HasPrefix(location, api.TusBasePath) {
+		err = fmt.Errorf("unexpected Location %q", location)
+	}
+	if err == nil {
+		err = expectExpires(resp, 12*time.Hour)
+	}
+	testutil.Check("create", err)
+
+	// 3. HEAD查询偏移量, 其他用户看不到会话
+	testutil.Check("HEAD offset 0", alice.expectOffset(location, 0))
+	resp, err = bob.do(http.MethodHead, api.TusBasePath+uploadID(location), nil, nil)
+	testutil.Check("HEAD by another user", expectStatus(resp, err, http.StatusNotFound))
+
+	// 4. PATCH: 校验通过后偏移量前进
+	half := len(data) / 2
+	resp, err = alice.patch(location, 0, data[:half], sha1Checksum(data[:half]))
+	if err = expectStatus(resp, err, http.StatusNoContent); err == nil && resp.Header.Get("Upload-Offset") != strconv.Itoa(half) {
+		err = fmt.Errorf("PATCH returned offset %q", resp.Header.Get("Upload-Offset"))
+	}
+	if err == nil {
+		err = alice.expectOffset(location, half)
+	}
+	testutil.Check("PATCH first half with checksum", err)
+
+	// 5. 偏移量不一致返回409, 不写入数据
+	resp, err = alice.patch(location, 0, data[:half], "")
+	err = expectStatus(resp, err, http.StatusConflict)
+	if err == nil {
+		err = alice.expectOffset(location, half)
+	}
+	testutil.Check("PATCH with wrong Upload-Offset", err)
+
+	// 6. 校验失败返回460并丢弃本次数据; 不支持的算法返回400
+	resp, err = alice.patch(location, half, data[half:], sha1Checksum([]byte("something else")))
+	err = expectStatus(resp, err, 460)
+	if err == nil {
+		err = alice.expectOffset(location, half)
+	}
+	if _, e := os.Stat(layout.ChunkDir(uploadID(location)) + strconv.Itoa(half)); err == nil && !os.IsNotExist(e) {
+		err = fmt.Errorf("chunk with checksum mismatch was kept")
+	}
+	testutil.Check("PATCH with checksum mismatch", err)
+	resp, err = alice.patch(location, half, data[half:], "md5 "+base64.StdEncoding.EncodeToString(make([]byte, 16)))
+	err = expectStatus(resp, err, http.StatusBadRequest)
+	if err == nil {
+		err = alice.expectOffset(location, half)
+	}
+	testutil.Check("PATCH with unsupported checksum algorithm", err)
+
+	// 7. 上传剩余数据后合并入库, 会话及分块删除
+	resp, err = alice.patch(location, half, data[half:], "")
+	if err = expectStatus(resp, err, http.StatusNoContent); err == nil && resp.Header.Get("Upload-Offset") != strconv.Itoa(len(data)) {
+		err = fmt.Errorf("PATCH returned offset %q", resp.Header.Get("Upload-Offset"))
+	}
+	testutil.Check("PATCH rest", err)
+	sum := sha1.Sum(data)
+	filehash := hex.EncodeToString(sum[:])
+	if f, ok := proxy.File(filehash); !ok || f.FileSize.Int64 != int64(len(data)) || f.FileAddr.String != layout.MergePath(filehash) {
+		err = fmt.Errorf("file table %+v", f)
+	} else if owners := proxy.Owners(); len(owners) != 1 || owners[0].UserName != "alice" || owners[0].FileName != "docs/tus.txt" {
+		err = fmt.Errorf("user file table %+v", owners)
+	}
+	if err == nil {
+		resp, err = alice.do(http.MethodHead, location, nil, nil)
+		err = expectStatus(resp, err, http.StatusNotFound)
+	}
+	if _, e := os.Stat(layout.ChunkDir(uploadID(location))); err == nil && !os.IsNotExist(e) {
+		err = fmt.Errorf("chunk dir was not removed")
+	}
+	testutil.Check("finish upload", err)
+
+	// 8. 终止: 删除会话及已上传的数据
+	location, _, err = alice.create(len(data), "")
+	if err == nil {
+		resp, err = alice.patch(location, 0, data[:half], "")
+		err = expectStatus(resp, err, http.StatusNoContent)
+	}
+	if err == nil {
+		resp, err = bob.do(http.MethodDelete, api.TusBasePath+uploadID(location), nil, nil)
+		err = expectStatus(resp, err, http.StatusNotFound)
+	}
+	if err == nil {
+		resp, err = alice.do(http.MethodDelete, location, nil, nil)
+		err = expectStatus(resp, err, http.StatusNoContent)
+	}
+	if err == nil {
+		resp, err = alice.do(http.MethodHead, location, nil, nil)
+		err = expectStatus(resp, err, http.StatusNotFound)
+	}
+	if _, e := os.Stat(layout.ChunkDir(uploadID(location))); err == nil && !os.IsNotExist(e) {
+		err = fmt.Errorf("chunk dir was not removed")
+	}
+	testutil.Check("terminate", err)
+
+	// 9. 过期: Upload-Expires随会话剩余时间变化, 会话过期后不能继续上传
+	location, _, err = alice.create(len(data), "")
+	conn = redis.Pool().Get()
+	defer conn.Close()
+	if err == nil {
+		_, err = conn.Do("EXPIRE", api.ChunkKeyPrefix+uploadID(location), 60)
+	}
+	if err == nil {
+		resp, err = alice.do(http.MethodHead, location, nil, nil)
+		if err = expectStatus(resp, err, http.StatusOK); err == nil {
+			err = expectExpires(resp, time.Minute)
+		}
+	}
+	testutil.Check("Upload-Expires follows session TTL", err)
+	if _, err = conn.Do("EXPIRE", api.ChunkKeyPrefix+uploadID(location), 1); err == nil {
+		time.Sleep(1500 * time.Millisecond)
+		resp, err = alice.do(http.MethodHead, location, nil, nil)
+		err = expectStatus(resp, err, http.StatusNotFound)
+	}
+	if err == nil {
+		resp, err = alice.patch(location, 0, data, "")
+		err = expectStatus(resp, err, http.StatusNotFound)
+	}
+	testutil.Check("expired session", err)
+
+	// 10. 写入锁: 上传时间超过锁有效期时续期, 锁被其他请求持有后中止写入且不前进偏移量
+	upCfg.TusLockTTL = 3
+	location, _, err = alice.create(len(data), "")
+	testutil.Check("create for lock renewal", err)
+	lockKey := "LOCK_TUS_" + uploadID(location)
+	parts := [][]byte{data[:100], data[100:200], data[200:half]}
+	resp, err = alice.patchStream(location, 0, &slowBody{parts: parts, pause: func(int) {
+		time.Sleep(2 * time.Second)
+	}})
+	if err = expectStatus(resp, err, http.StatusNoContent); err == nil {
+		err = alice.expectOffset(location, half)
+	}
+	testutil.Check("renew lock during slow PATCH", err)
+	parts = [][]byte{data[half : half+100], data[half+100:]}
+	resp, err = alice.patchStream(location, half, &slowBody{parts: parts, pause: func(int) {
+		// 模拟锁过期后被其他请求获取
+		conn.Do("SET", lockKey, "another-request", "EX", 60)
+		time.Sleep(1200 * time.Millisecond)
+	}})
+	err = expectStatus(resp, err, http.StatusConflict)
+	if err == nil {
+		err = alice.expectOffset(location, half)
+	}
+	testutil.Check("abort PATCH after losing the lock", err)
+	conn.Do("DEL", lockKey)
+	resp, err = alice.patch(location, half, data[half:], "")
+	if err = expectStatus(resp, err, http.StatusNoContent); err == nil {
+		_, err = alice.do(http.MethodHead, location, nil, nil)
+	}
+	testutil.Check("resume after the lock is released", err)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/cloud/sdk"
+	"github.com/cloud/test/testutil"
+)
+
+// 测试加密目录的客户端加密: 口令保护的密钥对, 以公钥分享目录密钥, 文件名确定性加密,
+// 分块内容加密的往返及截断/篡改/重排检测. 只使用sdk中的加密实现, 不需要服务端:
+// go run ./test/vault
+
+func encrypt(vc *sdk.VaultCipher, plain []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	n, err := vc.Encrypt(buf, bytes.NewReader(plain))
+	if err != nil {
+		return nil, err
+	}
+	if n != int64(buf.Len()) || n != sdk.VaultEncryptedSize(int64(len(plain))) {
+		return nil, fmt.Errorf("encrypted size %d, buffer %d, expected %d",
+			n, buf.Len(), sdk.VaultEncryptedSize(int64(len(plain))))
+	}
+	if sdk.VaultPlainSize(n) != int64(len(plain)) {
+		return nil, fmt.Errorf("plain size %d, expected %d", sdk.VaultPlainSize(n), len(plain))
+	}
+	return buf.Bytes(), nil
+}
+
+func decrypt(vc *sdk.VaultCipher, sealed []byte) ([]byte, error) {
+	r, err := vc.NewDecryptReader(bytes.NewReader(sealed))
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+// expectCorrupt : 解密应以ErrVaultCorrupt失败
+func expectCorrupt(vc *sdk.VaultCipher, sealed []byte) error {
+	_, err := decrypt(vc, sealed)
+	if err != sdk.ErrVaultCorrupt {
+		return fmt.Errorf("expected ErrVaultCorrupt, got %v", err)
+	}
+	return nil
+}
+
+func main() {
+	// 1 密钥对: 口令加密私钥, 正确口令解锁, 错误口令失败
+	alice, err := sdk.NewVaultKeys()
+	testutil.Check("generate key pair", err)
+	kp, err := alice.Seal("correct horse")
+	testutil.Check("seal secret key with passphrase", err)
+	if len(kp.SecretKey) > 255 || strings.Contains(kp.SecretKey, "correct") {
+		testutil.Check("sealed key pair", fmt.Errorf("unexpected sealed key %q", kp.SecretKey))
+	}
+	unlocked, err := sdk.OpenVaultKeys(kp, "correct horse")
+	testutil.Check("unlock with passphrase", err)
+	if unlocked.PublicKey() != alice.PublicKey() {
+		testutil.Check("unlocked public key", errors.New("public key changed"))
+	}
+	_, err = sdk.OpenVaultKeys(kp, "wrong horse")
+	if err != sdk.ErrVaultPassphrase {
+		err = fmt.Errorf("expected ErrVaultPassphrase, got %v", err)
+	} else {
+		err = nil
+	}
+	testutil.Check("wrong passphrase rejected", err)
+
+	// 服务端替换公钥时解锁失败
+	mallory, _ := sdk.NewVaultKeys()
+	swapped := *kp
+	swapped.PublicKey = mallory.PublicKey()
+	_, err = sdk.OpenVaultKeys(&swapped, "correct horse")
+	if err != sdk.ErrVaultCorrupt {
+		err = fmt.Errorf("expected ErrVaultCorrupt, got %v", err)
+	} else {
+		err = nil
+	}
+	testutil.Check("substituted public key rejected", err)
+
+	// 修改口令后密钥对不变
+	rekeyed, err := unlocked.Seal("battery staple")
+	testutil.Check("reseal with new passphrase", err)
+	again, err := sdk.OpenVaultKeys(rekeyed, "battery staple")
+	testutil.Check("unlock with new passphrase", err)
+	if again.PublicKey() != alice.PublicKey() {
+		testutil.Check("public key after passphrase change", errors.New("public key changed"))
+	}
+
+	// 2 目录密钥以公钥加密, 分享给bob后bob可解密, mallory不能
+	folderKey, err := sdk.NewFolderKey()
+	testutil.Check("generate folder key", err)
+	wrapped, err := sdk.WrapFolderKey(folderKey, alice.PublicKey())
+	testutil.Check("wrap folder key for owner", err)
+	ownKey, err := again.UnwrapFolderKey(wrapped)
+	testutil.Check("owner unwraps folder key", err)
+	bob, _ := sdk.NewVaultKeys()
+	bobWrapped, err := sdk.WrapFolderKey(folderKey, bob.PublicKey())
+	testutil.Check("wrap folder key for grantee", err)
+	bobKey, err := bob.UnwrapFolderKey(bobWrapped)
+	testutil.Check("grantee unwraps folder key", err)
+	if *bobKey != *ownKey || *ownKey != *folderKey {
+		testutil.Check("unwrapped folder keys", errors.New("folder keys differ"))
+	}
+	_, err = mallory.UnwrapFolderKey(bobWrapped)
+	if err != sdk.ErrVaultCorrupt {
+		err = fmt.Errorf("expected ErrVaultCorrupt, got %v", err)
+	} else {
+		err = nil
+	}
+	testutil.Check("other user cannot unwrap", err)
+
+	// 3 文件名: 确定性加密, 不含明文, 服务端比较不区分大小写也不冲突, 可逐级解密
+	vc := sdk.NewVaultCipher(folderKey)
+	bobVC := sdk.NewVaultCipher(bobKey)
+	encPath := vc.EncryptPath("tax/2025/Report.pdf")
+	if encPath != bobVC.EncryptPath("tax/2025/Report.pdf") {
+		testutil.Check("deterministic names", errors.New("same name encrypted differently"))
+	}
+	if strings.Contains(strings.ToLower(encPath), "report") || strings.Count(encPath, "/") != 2 {
+		testutil.Check("encrypted path", fmt.Errorf("unexpected encrypted path %s", encPath))
+	}
+	if encPath != strings.ToLower(encPath) ||
+		strings.ToLower(vc.EncryptName("report.pdf")) == strings.ToLower(vc.EncryptName("Report.pdf")) {
+		testutil.Check("case-insensitive names", errors.New("names collide ignoring case"))
+	}
+	plainPath, err := bobVC.DecryptPath(encPath)
+	if err == nil && plainPath != "tax/2025/Report.pdf" {
+		err = fmt.Errorf("decrypted %s", plainPath)
+	}
+	testutil.Check("grantee decrypts names", err)
+	if dir := vc.EncryptPath("tax/"); !strings.HasPrefix(encPath, dir) || !strings.HasSuffix(dir, "/") {
+		testutil.Check("directory prefix", fmt.Errorf("%s is not a prefix of %s", dir, encPath))
+	}
+	otherKey, _ := sdk.NewFolderKey()
+	_, err = sdk.NewVaultCipher(otherKey).DecryptPath(encPath)
+	if err != sdk.ErrVaultCorrupt {
+		err = fmt.Errorf("expected ErrVaultCorrupt, got %v", err)
+	} else {
+		err = nil
+	}
+	testutil.Check("names undecryptable with another folder key", err)
+
+	// 4 内容: 空文件、不足一块、恰好整块及多块的往返
+	for _, size := range []int{0, 1, 1000, sdk.VaultChunkSize, 3*sdk.VaultChunkSize + 17} {
+		plain := make([]byte, size)
+		rand.Read(plain)
+		sealed, err := encrypt(vc, plain)
+		if err == nil {
+			var got []byte
+			got, err = decrypt(bobVC, sealed)
+			if err == nil && !bytes.Equal(got, plain) {
+				err = errors.New("content mismatch")
+			}
+		}
+		testutil.Check(fmt.Sprintf("round trip %d bytes", size), err)
+	}
+
+	// 相同内容每次加密结果不同(随机salt), 不能按hash判断内容
+	plain := bytes.Repeat([]byte("vault"), sdk.VaultChunkSize/2)
+	first, _ := encrypt(vc, plain)
+	second, _ := encrypt(vc, plain)
+	err = nil
+	if bytes.Equal(first, second) {
+		err = errors.New("same plaintext produced same ciphertext")
+	}
+	testutil.Check("randomized ciphertext", err)
+
+	// 5 截断、篡改、块重排及使用其他目录密钥时解密失败
+	// 文件头为magic(4) + 版本(1) + salt(16), 每块密文为明文块加16字节认证标签
+	header, block := 21, sdk.VaultChunkSize+16
+	testutil.Check("truncated at chunk boundary", expectCorrupt(vc, first[:header+block]))
+	testutil.Check("truncated mid chunk", expectCorrupt(vc, first[:len(first)-5]))
+	testutil.Check("header only", expectCorrupt(vc, first[:header]))
+	tampered := append([]byte{}, first...)
+	tampered[header+100] ^= 1
+	testutil.Check("flipped bit", expectCorrupt(vc, tampered))
+	reordered := append([]byte{}, first[:header]...)
+	reordered = append(reordered, first[header+block:header+2*block]...)
+	reordered = append(reordered, first[header:header+block]...)
+	reordered = append(reordered, first[header+2*block:]...)
+	testutil.Check("reordered chunks", expectCorrupt(vc, reordered))
+	spliced := append(append([]byte{}, second[:header]...), first[header:]...)
+	testutil.Check("header from another file", expectCorrupt(vc, spliced))
+	testutil.Check("another folder key", expectCorrupt(sdk.NewVaultCipher(otherKey), first))
+
+	// 读取到被篡改的块之前的数据可正常返回, 之后返回错误
+	tampered = append([]byte{}, first...)
+	tampered[len(tampered)-1] ^= 1
+	r, err := vc.NewDecryptReader(bytes.NewReader(tampered))
+	testutil.Check("open tampered stream", err)
+	n, err := io.Copy(ioutil.Discard, r)
+	if err != sdk.ErrVaultCorrupt || n != int64(2*sdk.VaultChunkSize) {
+		err = fmt.Errorf("read %d bytes, err %v", n, err)
+	} else {
+		err = nil
+	}
+	testutil.Check("only authenticated chunks returned", err)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloud/service/webhook/dispatcher"
+)
+
+// memStore : 内存中的dispatcher.Store实现
+type memStore struct {
+	mu         sync.Mutex
+	hooks      []dispatcher.Hook
+	events     []dispatcher.Event
+	eventAt    map[int64]time.Time
+	initial    map[int64]int64
+	deliveries []*dispatcher.Delivery
+}
+
+func newMemStore() *memStore {
+	return &memStore{eventAt: map[int64]time.Time{}, initial: map[int64]int64{}}
+}
+
+// addEvent : 追加一个事件, at为提交时间
+func (s *memStore) addEvent(username, event, filename string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := int64(len(s.events) + 1)
+	s.events = append(s.events, dispatcher.Event{
+		ID:       id,
+		UserName: username,
+		Event:    event,
+		FileName: filename,
+		FileHash: "hash-" + filename,
+		CreateAt: at.Format("2006-01-02 15:04:05"),
+	})
+	s.eventAt[id] = at
+}
+
+// addHook : 注册webhook, 与CreateWebhook相同, 游标从当前最新的事件开始
+func (s *memStore) addHook(username, url, secret, events string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hook := dispatcher.Hook{
+		ID:       int64(len(s.hooks) + 1),
+		UserName: username,
+		URL:      url,
+		Secret:   secret,
+		Events:   events,
+	}
+	for _, ev := range s.events {
+		if username == "" || ev.UserName == username {
+			hook.Cursor = ev.ID
+		}
+	}
+	s.hooks = append(s.hooks, hook)
+	s.initial[hook.ID] = hook.Cursor
+	return hook.ID
+}
+
+// resetCursor : 将webhook游标退回注册时的位置, 模拟游标前移前进程退出
+func (s *memStore) resetCursor(hookID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks[hookID-1].Cursor = s.initial[hookID]
+}
+
+// list : 某个webhook的投递记录副本
+func (s *memStore) list(hookID int64) []dispatcher.Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []dispatcher.Delivery{}
+	for _, d := range s.deliveries {
+		if d.HookID == hookID {
+			list = append(list, *d)
+		}
+	}
+	return list
+}
+
+func (s *memStore) Hooks(username string) ([]dispatcher.Hook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hooks := []dispatcher.Hook{}
+	for _, h := range s.hooks {
+		if h.UserName == username || h.UserName == "" {
+			hooks = append(hooks, h)
+		}
+	}
+	return hooks, nil
+}
+
+func (s *memStore) Events(hook dispatcher.Hook, before time.Time, limit int) ([]dispatcher.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := []dispatcher.Event{}
+	for _, ev := range s.events {
+		if ev.ID <= hook.Cursor {
+			continue
+		}
+		if hook.UserName == "" {
+			if !s.eventAt[ev.ID].Before(before) {
+				break
+			}
+		} else if ev.UserName != hook.UserName {
+			continue
+		}
+		events = append(events, ev)
+		if len(events) >= limit {
+			break
+		}
+	}
+	return events, nil
+}
+
+func (s *memStore) AdvanceCursor(hookID, cursor int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.hooks[hookID-1].Cursor < cursor {
+		s.hooks[hookID-1].Cursor = cursor
+	}
+	return nil
+}
+
+func (s *memStore) CreateDelivery(d *dispatcher.Delivery) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, old := range s.deliveries {
+		if old.HookID == d.HookID && old.EventID == d.EventID {
+			return false, nil
+		}
+	}
+	d.ID = int64(len(s.deliveries) + 1)
+	saved := *d
+	s.deliveries = append(s.deliveries, &saved)
+	return true, nil
+}
+
+func (s *memStore) Delivery(hookID, eventID int64) (*dispatcher.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range s.deliveries {
+		if d.HookID == hookID && d.EventID == eventID {
+			found := *d
+			found.URL = s.hooks[hookID-1].URL
+			found.Secret = s.hooks[hookID-1].Secret
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memStore) UpdateDelivery(d *dispatcher.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved := *d
+	s.deliveries[d.ID-1] = &saved
+	return nil
+}
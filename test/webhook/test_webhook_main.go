@@ -0,0 +1,376 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloud/job"
+	"github.com/cloud/service/webhook/dispatcher"
+	"github.com/cloud/test/testutil"
+)
+
+// 使用内存存储、内存任务表及本地接收端对webhook投递进行测试:
+// go run ./test/webhook
+
+// received : 接收端收到的一次请求
+type received struct {
+	Path     string
+	Delivery string
+	Event    string
+	Body     string
+}
+
+// receiver : 校验签名并记录请求. /flaky对每个投递第一次返回500, /dead总是返回503,
+// /redirect重定向到/internal
+type receiver struct {
+	mu      sync.Mutex
+	secrets map[string]string
+	seen    map[string]int
+	reqs    []received
+	badSig  int
+}
+
+func (rv *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+	body, err := dispatcher.VerifyRequest(rv.secrets[r.URL.Path], r, 5*time.Minute)
+	if err != nil {
+		rv.badSig++
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	delivery := r.Header.Get(dispatcher.HeaderDelivery)
+	rv.reqs = append(rv.reqs, received{
+		Path:     r.URL.Path,
+		Delivery: delivery,
+		Event:    r.Header.Get(dispatcher.HeaderEvent),
+		Body:     string(body),
+	})
+	rv.seen[r.URL.Path+"#"+delivery]++
+	switch {
+	case r.URL.Path == "/dead":
+		w.WriteHeader(http.StatusServiceUnavailable)
+	case r.URL.Path == "/redirect":
+		http.Redirect(w, r, "/internal", http.StatusFound)
+	case r.URL.Path == "/flaky" && rv.seen[r.URL.Path+"#"+delivery] == 1:
+		w.WriteHeader(http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// requests : 发往path的请求
+func (rv *receiver) requests(path string) []received {
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+	list := []received{}
+	for _, req := range rv.reqs {
+		if req.Path == path {
+			list = append(list, req)
+		}
+	}
+	return list
+}
+
+// queue : 记录发布的任务, 代替消息队列
+type queue struct {
+	mu  sync.Mutex
+	ids []int64
+}
+
+// Publish : 实现job.Publisher
+func (q *queue) Publish(j *job.Job) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.ids = append(q.ids, j.ID)
+	return true
+}
+
+// take : 取出已发布的任务
+func (q *queue) take() []int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	ids := q.ids
+	q.ids = nil
+	return ids
+}
+
+// drain : 并发执行已发布的任务, 返回执行的任务数
+func drain(q *queue, w *job.Worker) (int, error) {
+	ids := q.take()
+	errs := make(chan error, len(ids))
+	for _, id := range ids {
+		go func(id int64) { errs <- w.Process(id) }(id)
+	}
+	var err error
+	for range ids {
+		if e := <-errs; e != nil && err == nil {
+			err = e
+		}
+	}
+	return len(ids), err
+}
+
+// expectDeliveries : 检查webhook的投递记录数量及状态
+func expectDeliveries(list []dispatcher.Delivery, n, status, attempts int) error {
+	if len(list) != n {
+		return fmt.Errorf("expected %d deliveries, got %d", n, len(list))
+	}
+	for _, d := range list {
+		if d.Status != status || d.Attempts != attempts {
+			return fmt.Errorf("delivery %d: status %d attempts %d, expected %d/%d",
+				d.ID, d.Status, d.Attempts, status, attempts)
+		}
+	}
+	return nil
+}
+
+func main() {
+	rv := &receiver{secrets: map[string]string{}, seen: map[string]int{}}
+	srv := httptest.NewServer(rv)
+	defer srv.Close()
+
+	clk := testutil.NewClock(time.Now())
+	store := newMemStore()
+	jobs := job.NewMemoryStore()
+	q := &queue{}
+	c := job.NewClient(jobs, q.Publish, time.Minute)
+	c.Now = clk.Now
+	w := job.NewWorker(jobs, q.Publish, time.Minute)
+	w.Now = clk.Now
+	d := dispatcher.New(store, c.Enqueue)
+	// 接收端在本机, 测试时允许连接任意地址, 地址限制见第7步
+	d.Client = dispatcher.NewClient(5*time.Second, func(net.IP) bool { return true })
+	d.Settle = 10 * time.Second
+	d.Now = clk.Now
+	w.Handle(job.TypeWebhook, d.Deliver)
+	def, _ := job.Lookup(job.TypeWebhook)
+
+	// 1. 签名校验
+	body := []byte(`{"ID":1}`)
+	ts := time.Now().Unix()
+	header := http.Header{}
+	header.Set(dispatcher.HeaderTimestamp, fmt.Sprint(ts))
+	header.Set(dispatcher.HeaderSignature, dispatcher.Sign("s3cret", ts, body))
+	err := dispatcher.Verify("s3cret", header, body, time.Minute)
+	if err == nil && dispatcher.Verify("other", header, body, time.Minute) != dispatcher.ErrInvalidSignature {
+		err = fmt.Errorf("wrong secret accepted")
+	}
+	if err == nil && dispatcher.Verify("s3cret", header, []byte(`{"ID":2}`), time.Minute) != dispatcher.ErrInvalidSignature {
+		err = fmt.Errorf("tampered body accepted")
+	}
+	if err == nil {
+		old := ts - 600
+		header.Set(dispatcher.HeaderTimestamp, fmt.Sprint(old))
+		header.Set(dispatcher.HeaderSignature, dispatcher.Sign("s3cret", old, body))
+		if dispatcher.Verify("s3cret", header, body, time.Minute) != dispatcher.ErrInvalidSignature {
+			err = fmt.Errorf("stale timestamp accepted")
+		}
+	}
+	testutil.Check("sign and verify", err)
+
+	// 2. 只投递订阅的事件, 第一次投递失败后按计划等待重试
+	store.addEvent("alice", "upload", "/old.txt", clk.Now())
+	rv.secrets["/flaky"] = "flaky-secret"
+	flaky := store.addHook("alice", srv.URL+"/flaky", "flaky-secret", "upload,delete")
+	store.addEvent("alice", "upload", "/a.txt", clk.Now())
+	store.addEvent("alice", "rename", "/b.txt", clk.Now())
+	store.addEvent("bob", "upload", "/bob.txt", clk.Now())
+	store.addEvent("alice", "delete", "/b.txt", clk.Now())
+	testutil.Check("HandleEvent", d.HandleEvent("alice"))
+	n, err := drain(q, w)
+	if err == nil && n != 2 {
+		err = fmt.Errorf("expected 2 jobs, got %d", n)
+	}
+	testutil.Check("run delivery jobs", err)
+	list := store.list(flaky)
+	err = expectDeliveries(list, 2, dispatcher.StatusPending, 1)
+	if err == nil && (list[0].Event != "upload" || list[1].Event != "delete") {
+		err = fmt.Errorf("unexpected events %s, %s", list[0].Event, list[1].Event)
+	}
+	if err == nil {
+		for _, del := range list {
+			if want := clk.Now().Add(def.Backoff(1)); !del.NextRetryAt.Equal(want) || del.ResponseCode != 500 {
+				err = fmt.Errorf("delivery %d: next retry %s code %d", del.ID, del.NextRetryAt, del.ResponseCode)
+			}
+		}
+	}
+	if err == nil && len(rv.requests("/flaky")) != 2 {
+		err = fmt.Errorf("expected 2 requests, got %d", len(rv.requests("/flaky")))
+	}
+	testutil.Check("filter events and schedule retry", err)
+
+	// 3. 重复通知及游标回退都不会重复生成投递记录或任务, 未到重试时间不投递
+	testutil.Check("HandleEvent again", d.HandleEvent("alice"))
+	store.resetCursor(flaky)
+	testutil.Check("HandleEvent after cursor reset", d.HandleEvent("alice"))
+	testutil.Check("Tick", w.Tick())
+	n, err = drain(q, w)
+	if err == nil && n != 0 {
+		err = fmt.Errorf("expected no jobs, got %d", n)
+	}
+	if err == nil {
+		err = expectDeliveries(store.list(flaky), 2, dispatcher.StatusPending, 1)
+	}
+	if err == nil && len(rv.requests("/flaky")) != 2 {
+		err = fmt.Errorf("expected 2 requests, got %d", len(rv.requests("/flaky")))
+	}
+	testutil.Check("no duplicate deliveries", err)
+
+	// 4. 到期后由任务框架重新入队, 重试成功, 重试请求的投递ID及请求体不变
+	clk.Advance(def.Backoff(1))
+	testutil.Check("Tick", w.Tick())
+	_, err = drain(q, w)
+	if err == nil {
+		err = expectDeliveries(store.list(flaky), 2, dispatcher.StatusSucceeded, 2)
+	}
+	reqs := rv.requests("/flaky")
+	if err == nil && len(reqs) != 4 {
+		err = fmt.Errorf("expected 4 requests, got %d", len(reqs))
+	}
+	if err == nil {
+		for _, first := range reqs[:2] {
+			n := 0
+			for _, again := range reqs[2:] {
+				if again.Delivery == first.Delivery && again.Body == first.Body {
+					n++
+				}
+			}
+			if n != 1 {
+				err = fmt.Errorf("delivery %s was not retried unchanged", first.Delivery)
+			}
+		}
+	}
+	if err == nil {
+		// 投递并发进行, 按事件类型查找请求, 不依赖到达顺序
+		bodies := map[string]string{}
+		for _, req := range reqs {
+			bodies[req.Event] = req.Body
+		}
+		ev := dispatcher.Event{}
+		if len(bodies) != 2 || bodies["upload"] == "" || bodies["delete"] == "" {
+			err = fmt.Errorf("unexpected events %v", bodies)
+		} else if err = json.Unmarshal([]byte(bodies["upload"]), &ev); err == nil &&
+			(ev.UserName != "alice" || ev.FileName != "/a.txt" || ev.Event != "upload") {
+			err = fmt.Errorf("unexpected payload %s", bodies["upload"])
+		}
+	}
+	testutil.Check("retry succeeds", err)
+
+	// 5. 重试次数用完后投递记录及任务都标记为失败
+	rv.secrets["/dead"] = "dead-secret"
+	dead := store.addHook("alice", srv.URL+"/dead", "dead-secret", "")
+	store.addEvent("alice", "share", "/a.txt", clk.Now())
+	testutil.Check("HandleEvent dead", d.HandleEvent("alice"))
+	_, err = drain(q, w)
+	for attempts := 1; err == nil && attempts < def.MaxAttempts; attempts++ {
+		clk.Advance(def.Backoff(attempts))
+		if err = w.Tick(); err == nil {
+			_, err = drain(q, w)
+		}
+	}
+	list = store.list(dead)
+	if err == nil {
+		err = expectDeliveries(list, 1, dispatcher.StatusFailed, def.MaxAttempts)
+	}
+	if err == nil && (list[0].ResponseCode != 503 || !strings.Contains(list[0].LastError, "503")) {
+		err = fmt.Errorf("unexpected result %d %q", list[0].ResponseCode, list[0].LastError)
+	}
+	if err == nil && len(rv.requests("/dead")) != def.MaxAttempts {
+		err = fmt.Errorf("expected %d requests, got %d", def.MaxAttempts, len(rv.requests("/dead")))
+	}
+	if err == nil {
+		var j *job.Job
+		if j, err = jobs.Get(3); err == nil && (j.Type != job.TypeWebhook || j.Status != job.StatusFailed) {
+			err = fmt.Errorf("job %d: type %s status %d", j.ID, j.Type, j.Status)
+		}
+	}
+	clk.Advance(24 * time.Hour)
+	if err == nil {
+		err = w.Tick()
+	}
+	if n, _ = drain(q, w); err == nil && (n != 0 || len(rv.requests("/dead")) != def.MaxAttempts) {
+		err = fmt.Errorf("failed delivery was retried")
+	}
+	testutil.Check("give up after backoff", err)
+
+	// 6. 全局webhook接收所有用户的事件, 只投递提交超过Settle的事件
+	rv.secrets["/global"] = "global-secret"
+	global := store.addHook("", srv.URL+"/global", "global-secret", "upload")
+	store.addEvent("bob", "upload", "/bob2.txt", clk.Now())
+	store.addEvent("carol", "upload", "/carol.txt", clk.Now())
+	testutil.Check("HandleEvent global", d.HandleEvent("bob"))
+	_, err = drain(q, w)
+	if err == nil {
+		err = expectDeliveries(store.list(global), 0, dispatcher.StatusSucceeded, 1)
+	}
+	if err == nil {
+		clk.Advance(d.Settle + time.Second)
+		err = d.HandleEvent("")
+	}
+	if err == nil {
+		_, err = drain(q, w)
+	}
+	if err == nil {
+		err = expectDeliveries(store.list(global), 2, dispatcher.StatusSucceeded, 1)
+	}
+	testutil.Check("global webhook", err)
+
+	// 7. 不能投递到本机及内网地址, 不跟随重定向
+	for _, rawurl := range []string{"http://127.0.0.1:28081/debug/vars", "http://localhost/", "http://10.1.2.3/",
+		"http://192.168.0.1/", "http://169.254.169.254/latest/meta-data", "http://[::1]/", "http://[::ffff:127.0.0.1]/",
+		"http://[fe80::1]/", "http://100.64.0.1/", "http://0.0.0.0/"} {
+		if err = dispatcher.CheckURL(context.Background(), rawurl); err != dispatcher.ErrForbiddenAddress {
+			err = fmt.Errorf("%s: got %v", rawurl, err)
+			break
+		}
+		err = nil
+	}
+	if err == nil && dispatcher.CheckURL(context.Background(), "ftp://example.com/") != dispatcher.ErrInvalidURL {
+		err = fmt.Errorf("non-http url accepted")
+	}
+	if err == nil {
+		err = dispatcher.CheckURL(context.Background(), "https://93.184.216.34/hook")
+	}
+	testutil.Check("reject internal webhook urls", err)
+	before := len(rv.requests("/flaky"))
+	_, err = dispatcher.NewClient(time.Second, dispatcher.PublicIP).Post(srv.URL+"/flaky", "application/json", nil)
+	if err == nil || !strings.Contains(err.Error(), dispatcher.ErrForbiddenAddress.Error()) {
+		err = fmt.Errorf("connected to %s: %v", srv.URL, err)
+	} else if len(rv.requests("/flaky")) != before {
+		err = fmt.Errorf("request reached the receiver")
+	} else {
+		err = nil
+	}
+	testutil.Check("default client refuses loopback", err)
+	rv.secrets["/redirect"] = "redirect-secret"
+	redirect := store.addHook("dave", srv.URL+"/redirect", "redirect-secret", "")
+	store.addEvent("dave", "share", "/d.txt", clk.Now())
+	testutil.Check("HandleEvent redirect", d.HandleEvent("dave"))
+	_, err = drain(q, w)
+	list = store.list(redirect)
+	if err == nil {
+		err = expectDeliveries(list, 1, dispatcher.StatusPending, 1)
+	}
+	if err == nil && list[0].ResponseCode != http.StatusFound {
+		err = fmt.Errorf("response code %d", list[0].ResponseCode)
+	}
+	if err == nil && len(rv.requests("/internal")) != 0 {
+		err = fmt.Errorf("redirect was followed")
+	}
+	testutil.Check("do not follow redirects", err)
+
+	rv.mu.Lock()
+	if rv.badSig > 0 {
+		err = fmt.Errorf("%d requests with invalid signature", rv.badSig)
+	}
+	rv.mu.Unlock()
+	testutil.Check("all requests signed", err)
+}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"crypto/md5"
+	"crypto/rand"
 	"crypto/sha1"
 	"encoding/hex"
 	"errors"
@@ -53,6 +54,15 @@ func FileMD5(file *os.File) string {
 	return hex.EncodeToString(_md5.Sum(nil))
 }
 
+// RandomHex : 生成n字节随机数据的十六进制字符串, 用于不可猜测的ID
+func RandomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 func PathExists(path string) (bool, error) {
 	_, err := os.Stat(path)
 	if err == nil {